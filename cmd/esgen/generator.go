@@ -0,0 +1,281 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// defaultTesthelperImport é o package com o fixture loader
+const defaultTesthelperImport = "github.com/viniciussantos/claude-testcontainers/test/testhelper"
+
+// Options controla a geração de código
+type Options struct {
+	Source         string // caminho do mapping (apenas para o cabeçalho)
+	Index          string
+	TypeName       string
+	Package        string
+	Register       bool
+	TesthelperPath string
+}
+
+// mappingField é uma propriedade do mapping do Elasticsearch
+type mappingField struct {
+	Type       string                  `json:"type"`
+	Format     string                  `json:"format"`
+	Properties map[string]mappingField `json:"properties"`
+}
+
+// goStruct é uma struct a ser emitida
+type goStruct struct {
+	name   string
+	fields []goField
+}
+
+// goField é um campo de struct com a tag json correspondente
+type goField struct {
+	name    string
+	goType  string
+	jsonTag string
+	comment string
+}
+
+// generator acumula as structs (raiz e objetos aninhados) e os imports necessários
+type generator struct {
+	structs   []goStruct
+	needsTime bool
+}
+
+// Generate gera o código Go formatado para o mapping informado
+// Aceita {"properties": ...}, {"mappings": {...}} ou a resposta de GET /<index>/_mapping
+func Generate(rawMapping []byte, opts Options) ([]byte, error) {
+	properties, err := extractProperties(rawMapping)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Index == "" {
+		return nil, fmt.Errorf("index name is required")
+	}
+	if opts.TypeName == "" {
+		opts.TypeName = singular(exportedName(opts.Index))
+	}
+	if opts.Package == "" {
+		opts.Package = "fixtures"
+	}
+	if opts.TesthelperPath == "" {
+		opts.TesthelperPath = defaultTesthelperImport
+	}
+
+	g := &generator{}
+	g.addStruct(opts.TypeName, properties)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by esgen from %s; DO NOT EDIT.\n\n", opts.Source)
+	fmt.Fprintf(&buf, "package %s\n\n", opts.Package)
+
+	var imports []string
+	if g.needsTime {
+		imports = append(imports, `"time"`)
+	}
+	if opts.Register {
+		imports = append(imports, fmt.Sprintf("%q", opts.TesthelperPath))
+	}
+	if len(imports) > 0 {
+		fmt.Fprintf(&buf, "import (\n%s\n)\n\n", strings.Join(imports, "\n"))
+	}
+
+	fmt.Fprintf(&buf, "// %sIndex é o índice cujo mapping originou %s\n", opts.TypeName, opts.TypeName)
+	fmt.Fprintf(&buf, "const %sIndex = %q\n\n", opts.TypeName, opts.Index)
+
+	for _, s := range g.structs {
+		fmt.Fprintf(&buf, "// %s representa um documento", s.name)
+		if s.name == opts.TypeName {
+			fmt.Fprintf(&buf, " do índice %s\n", opts.Index)
+		} else {
+			fmt.Fprintf(&buf, " aninhado de %s\n", opts.TypeName)
+		}
+		fmt.Fprintf(&buf, "type %s struct {\n", s.name)
+		for _, f := range s.fields {
+			fmt.Fprintf(&buf, "%s %s `json:\"%s\"`", f.name, f.goType, f.jsonTag)
+			if f.comment != "" {
+				fmt.Fprintf(&buf, " // %s", f.comment)
+			}
+			buf.WriteString("\n")
+		}
+		buf.WriteString("}\n\n")
+	}
+
+	if opts.Register {
+		fmt.Fprintf(&buf, "func init() {\n")
+		fmt.Fprintf(&buf, "testhelper.RegisterFixtureType(%sIndex, func() interface{} { return &%s{} })\n", opts.TypeName, opts.TypeName)
+		fmt.Fprintf(&buf, "}\n")
+	}
+
+	code, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %w", err)
+	}
+	return code, nil
+}
+
+// extractProperties localiza o bloco "properties" nos formatos de mapping aceitos
+func extractProperties(raw []byte) (map[string]mappingField, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("invalid mapping JSON: %w", err)
+	}
+
+	if props, ok := doc["properties"]; ok {
+		var properties map[string]mappingField
+		if err := json.Unmarshal(props, &properties); err != nil {
+			return nil, fmt.Errorf("invalid properties: %w", err)
+		}
+		return properties, nil
+	}
+
+	if mappings, ok := doc["mappings"]; ok {
+		return extractProperties(mappings)
+	}
+
+	// Resposta de GET /<index>/_mapping: {"<index>": {"mappings": {...}}}
+	if len(doc) == 1 {
+		for _, inner := range doc {
+			return extractProperties(inner)
+		}
+	}
+
+	return nil, fmt.Errorf("mapping has no properties")
+}
+
+// addStruct gera a struct com as propriedades em ordem alfabética (saída determinística)
+func (g *generator) addStruct(name string, properties map[string]mappingField) {
+	names := make([]string, 0, len(properties))
+	for prop := range properties {
+		names = append(names, prop)
+	}
+	sort.Strings(names)
+
+	// Reserva a posição da struct antes das aninhadas, mantendo a raiz primeiro
+	index := len(g.structs)
+	g.structs = append(g.structs, goStruct{name: name})
+
+	var fields []goField
+	for _, prop := range names {
+		field := properties[prop]
+		if field.Type == "alias" {
+			// Aliases não aparecem no _source
+			continue
+		}
+
+		fieldName := exportedName(prop)
+		goType, comment := g.goType(name+fieldName, field)
+		tag := prop
+		if strings.HasPrefix(goType, "*") || strings.HasPrefix(goType, "[]") || strings.HasPrefix(goType, "map[") || goType == "interface{}" {
+			tag += ",omitempty"
+		}
+
+		fields = append(fields, goField{name: fieldName, goType: goType, jsonTag: tag, comment: comment})
+	}
+
+	g.structs[index].fields = fields
+}
+
+// goType converte o tipo do Elasticsearch para o tipo Go correspondente
+func (g *generator) goType(nestedName string, field mappingField) (string, string) {
+	switch field.Type {
+	case "text", "keyword", "match_only_text", "wildcard", "constant_keyword", "ip", "version", "search_as_you_type":
+		return "string", ""
+	case "long":
+		return "int64", ""
+	case "integer":
+		return "int32", ""
+	case "short":
+		return "int16", ""
+	case "byte":
+		return "int8", ""
+	case "unsigned_long":
+		return "uint64", ""
+	case "double", "scaled_float":
+		return "float64", ""
+	case "float", "half_float":
+		return "float32", ""
+	case "boolean":
+		return "bool", ""
+	case "date", "date_nanos":
+		if field.Format == "" || strings.Contains(field.Format, "date_optional_time") {
+			g.needsTime = true
+			return "time.Time", ""
+		}
+		return "string", "format: " + field.Format
+	case "binary":
+		return "[]byte", ""
+	case "dense_vector":
+		return "[]float32", ""
+	case "flattened":
+		return "map[string]interface{}", ""
+	case "nested":
+		g.addStruct(nestedName, field.Properties)
+		return "[]" + nestedName, ""
+	case "object", "":
+		if len(field.Properties) == 0 {
+			return "map[string]interface{}", ""
+		}
+		g.addStruct(nestedName, field.Properties)
+		return "*" + nestedName, ""
+	default:
+		return "interface{}", "es type: " + field.Type
+	}
+}
+
+// commonInitialisms segue a convenção do Go para siglas em identificadores
+var commonInitialisms = map[string]bool{
+	"ID": true, "URL": true, "URI": true, "API": true, "HTTP": true, "IP": true,
+	"SKU": true, "JSON": true, "SQL": true, "UUID": true, "UTC": true,
+}
+
+// exportedName converte snake_case/kebab-case/dotted para CamelCase exportado
+func exportedName(name string) string {
+	parts := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var b strings.Builder
+	for _, part := range parts {
+		upper := strings.ToUpper(part)
+		if commonInitialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		runes := []rune(part)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+
+	result := b.String()
+	if result == "" {
+		return "Field"
+	}
+	if unicode.IsDigit([]rune(result)[0]) {
+		return "F" + result
+	}
+	return result
+}
+
+// singular remove o plural simples do nome do índice (products -> Product, categories -> Category)
+func singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies"):
+		return strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "sses"):
+		return strings.TrimSuffix(name, "es")
+	case strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss"):
+		return strings.TrimSuffix(name, "s")
+	default:
+		return name
+	}
+}
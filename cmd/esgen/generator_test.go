@@ -0,0 +1,60 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	mapping := `{
+		"products": {
+			"mappings": {
+				"properties": {
+					"id": {"type": "keyword"},
+					"price": {"type": "double"},
+					"stock": {"type": "integer"},
+					"created_at": {"type": "date"},
+					"released": {"type": "date", "format": "yyyy-MM-dd"},
+					"dimensions": {"properties": {"width": {"type": "float"}}},
+					"variants": {"type": "nested", "properties": {"sku": {"type": "keyword"}}},
+					"name_alias": {"type": "alias", "path": "id"}
+				}
+			}
+		}
+	}`
+
+	code, err := Generate([]byte(mapping), Options{Source: "products.json", Index: "products", Register: true})
+	require.NoError(t, err)
+
+	src := string(code)
+	assert.Contains(t, src, "// Code generated by esgen from products.json; DO NOT EDIT.")
+	assert.Contains(t, src, "type Product struct")
+	assert.Contains(t, src, "ID         string             `json:\"id\"`")
+	assert.Contains(t, src, "CreatedAt  time.Time")
+	assert.Contains(t, src, "Released   string             `json:\"released\"` // format: yyyy-MM-dd")
+	assert.Contains(t, src, "Dimensions *ProductDimensions `json:\"dimensions,omitempty\"`")
+	assert.Contains(t, src, "Variants   []ProductVariants  `json:\"variants,omitempty\"`")
+	assert.Contains(t, src, "SKU string `json:\"sku\"`")
+	assert.Contains(t, src, "Stock      int32")
+	assert.NotContains(t, src, "NameAlias")
+	assert.Contains(t, src, `testhelper.RegisterFixtureType(ProductIndex, func() interface{} { return &Product{} })`)
+
+	// A struct raiz vem antes das aninhadas
+	assert.Less(t, strings.Index(src, "type Product struct"), strings.Index(src, "type ProductDimensions struct"))
+}
+
+func TestGenerateRejectsMappingWithoutProperties(t *testing.T) {
+	_, err := Generate([]byte(`{"settings": {}, "aliases": {}}`), Options{Index: "products"})
+	assert.Error(t, err)
+}
+
+func TestExportedName(t *testing.T) {
+	assert.Equal(t, "TenantID", exportedName("tenant_id"))
+	assert.Equal(t, "ImageURL", exportedName("image-url"))
+	assert.Equal(t, "F3dModel", exportedName("3d_model"))
+	assert.Equal(t, "Category", singular("Categories"))
+	assert.Equal(t, "Address", singular("Addresses"))
+}
@@ -0,0 +1,72 @@
+// Comando esgen gera structs Go (com tags json) a partir de um mapping do Elasticsearch
+//
+// Uso:
+//
+//	go run ./cmd/esgen -mapping products.json [-index products] [-type Product] [-package fixtures] [-out products_gen.go]
+//
+// O arquivo gerado registra o tipo no fixture loader do testhelper (RegisterFixtureType),
+// mantendo os modelos de teste sincronizados com o mapping
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "esgen:", err)
+		os.Exit(1)
+	}
+}
+
+// run interpreta as flags e escreve o código gerado em -out (ou stdout)
+func run(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("esgen", flag.ContinueOnError)
+	mappingPath := fs.String("mapping", "", "arquivo JSON com o mapping do índice (obrigatório)")
+	index := fs.String("index", "", "nome do índice (padrão: nome do arquivo de mapping)")
+	typeName := fs.String("type", "", "nome da struct raiz (padrão: índice no singular em CamelCase)")
+	pkg := fs.String("package", "fixtures", "package do arquivo gerado")
+	out := fs.String("out", "", "arquivo de saída (padrão: stdout)")
+	register := fs.Bool("register", true, "gera o registro no fixture loader do testhelper")
+	helperImport := fs.String("testhelper", defaultTesthelperImport, "import path do package testhelper")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *mappingPath == "" {
+		fs.Usage()
+		return fmt.Errorf("-mapping is required")
+	}
+
+	raw, err := os.ReadFile(*mappingPath)
+	if err != nil {
+		return fmt.Errorf("failed to read mapping: %w", err)
+	}
+
+	if *index == "" {
+		*index = strings.TrimSuffix(filepath.Base(*mappingPath), filepath.Ext(*mappingPath))
+	}
+
+	code, err := Generate(raw, Options{
+		Source:         filepath.ToSlash(*mappingPath),
+		Index:          *index,
+		TypeName:       *typeName,
+		Package:        *pkg,
+		Register:       *register,
+		TesthelperPath: *helperImport,
+	})
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		_, err = stdout.Write(code)
+		return err
+	}
+	return os.WriteFile(*out, code, 0o644)
+}
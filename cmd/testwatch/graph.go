@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"sort"
+)
+
+// packageInfo são os campos do `go list -json` usados para montar o grafo
+type packageInfo struct {
+	ImportPath   string
+	Dir          string
+	Imports      []string
+	TestImports  []string
+	XTestImports []string
+	TestGoFiles  []string
+	XTestGoFiles []string
+}
+
+// hasTests indica se o pacote tem arquivos _test.go
+func (p *packageInfo) hasTests() bool {
+	return len(p.TestGoFiles) > 0 || len(p.XTestGoFiles) > 0
+}
+
+// packageGraph é o grafo reverso de imports dos pacotes do módulo
+type packageGraph struct {
+	packages map[string]*packageInfo
+	// byDir mapeia o diretório para o import path do pacote
+	byDir map[string]string
+	// importers são os pacotes que importam a chave no código de produção (propaga transitivamente)
+	importers map[string][]string
+	// testImporters são os pacotes que importam a chave apenas nos testes (não propaga)
+	testImporters map[string][]string
+}
+
+// loadPackageGraph executa `go list -json` com os padrões e monta o grafo
+func loadPackageGraph(ctx context.Context, root string, patterns []string) (*packageGraph, error) {
+	cmd := exec.CommandContext(ctx, "go", append([]string{"list", "-e", "-json"}, patterns...)...)
+	cmd.Dir = root
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list failed: %w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+
+	var packages []*packageInfo
+	decoder := json.NewDecoder(bytes.NewReader(out))
+	for {
+		var pkg packageInfo
+		if err := decoder.Decode(&pkg); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to decode go list output: %w", err)
+		}
+		packages = append(packages, &pkg)
+	}
+	return newPackageGraph(packages), nil
+}
+
+// newPackageGraph indexa os pacotes e calcula as arestas reversas
+func newPackageGraph(packages []*packageInfo) *packageGraph {
+	g := &packageGraph{
+		packages:      make(map[string]*packageInfo, len(packages)),
+		byDir:         make(map[string]string, len(packages)),
+		importers:     make(map[string][]string),
+		testImporters: make(map[string][]string),
+	}
+
+	for _, pkg := range packages {
+		g.packages[pkg.ImportPath] = pkg
+		g.byDir[filepath.Clean(pkg.Dir)] = pkg.ImportPath
+	}
+	for _, pkg := range packages {
+		for _, imported := range pkg.Imports {
+			if _, ok := g.packages[imported]; ok {
+				g.importers[imported] = append(g.importers[imported], pkg.ImportPath)
+			}
+		}
+		for _, imported := range append(append([]string{}, pkg.TestImports...), pkg.XTestImports...) {
+			if _, ok := g.packages[imported]; ok {
+				g.testImporters[imported] = append(g.testImporters[imported], pkg.ImportPath)
+			}
+		}
+	}
+	return g
+}
+
+// packageOf retorna o pacote dono do arquivo: o do diretório ou o do ancestral mais próximo
+// (ex.: testdata/fixtures.json pertence ao pacote do diretório acima de testdata)
+func (g *packageGraph) packageOf(path string) (string, bool) {
+	for dir := filepath.Dir(filepath.Clean(path)); ; dir = filepath.Dir(dir) {
+		if importPath, ok := g.byDir[dir]; ok {
+			return importPath, true
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			return "", false
+		}
+	}
+}
+
+// affected retorna os pacotes com testes afetados pelos arquivos alterados, em ordem
+func (g *packageGraph) affected(changed []string) []string {
+	visited := make(map[string]bool)
+	var queue []string
+	for _, path := range changed {
+		if importPath, ok := g.packageOf(path); ok && !visited[importPath] {
+			visited[importPath] = true
+			queue = append(queue, importPath)
+		}
+	}
+
+	result := make(map[string]bool)
+	for len(queue) > 0 {
+		importPath := queue[0]
+		queue = queue[1:]
+
+		if g.packages[importPath].hasTests() {
+			result[importPath] = true
+		}
+		for _, importer := range g.testImporters[importPath] {
+			result[importer] = true
+		}
+		for _, importer := range g.importers[importPath] {
+			if !visited[importer] {
+				visited[importer] = true
+				queue = append(queue, importer)
+			}
+		}
+	}
+
+	packages := make([]string, 0, len(result))
+	for importPath := range result {
+		packages = append(packages, importPath)
+	}
+	sort.Strings(packages)
+	return packages
+}
+
+// testPackages retorna todos os pacotes com testes
+func (g *packageGraph) testPackages() []string {
+	var packages []string
+	for importPath, pkg := range g.packages {
+		if pkg.hasTests() {
+			packages = append(packages, importPath)
+		}
+	}
+	sort.Strings(packages)
+	return packages
+}
+
+// needsReload indica se a alteração pode mudar o grafo de imports (arquivos .go, go.mod ou go.sum)
+func (g *packageGraph) needsReload(changed []string) bool {
+	for _, path := range changed {
+		switch base := filepath.Base(path); {
+		case base == "go.mod", base == "go.sum", filepath.Ext(base) == ".go":
+			return true
+		}
+	}
+	return false
+}
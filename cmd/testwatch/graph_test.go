@@ -0,0 +1,36 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackageGraphAffected(t *testing.T) {
+	graph := newPackageGraph([]*packageInfo{
+		{ImportPath: "example/internal/model", Dir: "/repo/internal/model"},
+		{ImportPath: "example/internal/repository", Dir: "/repo/internal/repository",
+			Imports: []string{"example/internal/model"}, TestImports: []string{"example/test/testhelper"},
+			TestGoFiles: []string{"repository_test.go"}},
+		{ImportPath: "example/internal/service", Dir: "/repo/internal/service",
+			Imports: []string{"example/internal/repository"}, XTestGoFiles: []string{"service_test.go"}},
+		{ImportPath: "example/test/testhelper", Dir: "/repo/test/testhelper"},
+		{ImportPath: "example/cmd/tool", Dir: "/repo/cmd/tool", TestGoFiles: []string{"tool_test.go"}},
+	})
+
+	// Mudança no modelo afeta quem importa o pacote, transitivamente
+	assert.Equal(t, []string{"example/internal/repository", "example/internal/service"},
+		graph.affected([]string{"/repo/internal/model/product.go"}))
+
+	// Import apenas nos testes afeta o importador, mas não propaga
+	assert.Equal(t, []string{"example/internal/repository"},
+		graph.affected([]string{"/repo/test/testhelper/fixtures.go"}))
+
+	// Arquivos em testdata pertencem ao pacote do diretório acima
+	assert.Equal(t, []string{"example/cmd/tool"},
+		graph.affected([]string{"/repo/cmd/tool/testdata/input.json"}))
+
+	assert.Empty(t, graph.affected([]string{"/repo/README.md"}))
+	assert.True(t, graph.needsReload([]string{"/repo/go.mod"}))
+	assert.False(t, graph.needsReload([]string{"/repo/cmd/tool/testdata/input.json"}))
+}
@@ -0,0 +1,141 @@
+// Comando testwatch reexecuta os testes afetados a cada alteração no repositório
+//
+// Uso:
+//
+//	go run ./cmd/testwatch [-warm es,postgres,mongo] [-run Regex] [-interval 300ms] [-v] [pacotes...]
+//
+// Os containers compartilhados listados em -warm são iniciados uma vez e mantidos no ar; os processos
+// de `go test` recebem USE_EXTERNAL_*/URL apontando para eles, então cada rodada não sobe nem procura
+// containers. A cada alteração são executados apenas os pacotes com testes que dependem (direta ou
+// transitivamente, inclusive pelos arquivos _test.go) do pacote alterado
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"time"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:], os.Stdout); err != nil && ctx.Err() == nil {
+		fmt.Fprintln(os.Stderr, "testwatch:", err)
+		os.Exit(1)
+	}
+}
+
+// run interpreta as flags, aquece as dependências e entra no loop de observação
+func run(ctx context.Context, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("testwatch", flag.ContinueOnError)
+	warm := fs.String("warm", "", "dependências mantidas no ar entre as rodadas: es, postgres, mongo (separadas por vírgula)")
+	runRegex := fs.String("run", "", "repassado ao go test -run")
+	interval := fs.Duration("interval", 300*time.Millisecond, "intervalo de varredura dos arquivos")
+	verbose := fs.Bool("v", false, "repassa -v ao go test")
+	initial := fs.Bool("initial", false, "executa todos os pacotes observados antes da primeira alteração")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	patterns := fs.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+
+	root, err := moduleRoot()
+	if err != nil {
+		return err
+	}
+
+	graph, err := loadPackageGraph(ctx, root, patterns)
+	if err != nil {
+		return err
+	}
+
+	env := os.Environ()
+	if *warm != "" {
+		warmEnv, release, err := warmDependencies(ctx, strings.Split(*warm, ","))
+		if err != nil {
+			return err
+		}
+		defer release()
+		env = append(env, warmEnv...)
+	}
+
+	goTest := func(packages []string) {
+		testArgs := []string{"test"}
+		if *verbose {
+			testArgs = append(testArgs, "-v")
+		}
+		if *runRegex != "" {
+			testArgs = append(testArgs, "-run", *runRegex)
+		}
+		testArgs = append(testArgs, packages...)
+
+		fmt.Fprintf(stdout, "\n▶ go %s\n", strings.Join(testArgs, " "))
+		start := time.Now()
+
+		cmd := exec.CommandContext(ctx, "go", testArgs...)
+		cmd.Dir = root
+		cmd.Env = env
+		cmd.Stdout = stdout
+		cmd.Stderr = stdout
+		status := "✅ PASS"
+		if err := cmd.Run(); err != nil {
+			status = "❌ FAIL"
+		}
+		fmt.Fprintf(stdout, "%s in %s — watching for changes...\n", status, time.Since(start).Round(time.Millisecond))
+	}
+
+	if *initial {
+		goTest(graph.testPackages())
+	}
+
+	fmt.Fprintf(stdout, "👀 Watching %s (%d packages with tests)\n", root, len(graph.testPackages()))
+
+	watcher := newWatcher(root)
+	for {
+		changed, err := watcher.waitForChanges(ctx, *interval)
+		if err != nil {
+			return err
+		}
+
+		// go.mod/go.sum ou pacotes novos alteram o grafo de imports
+		if graph.needsReload(changed) {
+			// Com erro (ex.: go.mod inválido durante a edição) mantém o grafo anterior
+			reloaded, err := loadPackageGraph(ctx, root, patterns)
+			if err != nil {
+				fmt.Fprintln(stdout, "⚠️ ", err)
+				continue
+			}
+			graph = reloaded
+		}
+
+		packages := graph.affected(changed)
+		if len(packages) == 0 {
+			continue
+		}
+		goTest(packages)
+	}
+}
+
+// moduleRoot retorna o diretório do go.mod do diretório atual
+func moduleRoot() (string, error) {
+	out, err := exec.Command("go", "env", "GOMOD").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate go.mod: %w", err)
+	}
+
+	gomod := strings.TrimSpace(string(out))
+	if gomod == "" || gomod == os.DevNull {
+		return "", fmt.Errorf("testwatch must run inside a Go module")
+	}
+	return strings.TrimSuffix(gomod, string(os.PathSeparator)+"go.mod"), nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/viniciussantos/claude-testcontainers/test/testhelper"
+)
+
+// warmDependencies inicia as dependências compartilhadas e retorna as variáveis de ambiente que fazem os
+// processos de `go test` usá-las como instâncias externas, além da função que as libera
+func warmDependencies(ctx context.Context, names []string) ([]string, func(), error) {
+	var env []string
+	var releases []func(context.Context) error
+	release := func() {
+		for _, stop := range releases {
+			stop(context.Background())
+		}
+	}
+
+	for _, name := range names {
+		switch strings.TrimSpace(strings.ToLower(name)) {
+		case "":
+			continue
+		case "es", "elasticsearch":
+			es := testhelper.GetSharedElasticsearch()
+			if err := es.Start(ctx); err != nil {
+				release()
+				return nil, nil, fmt.Errorf("failed to warm elasticsearch: %w", err)
+			}
+			releases = append(releases, es.Stop)
+			env = append(env, "USE_EXTERNAL_ES=true", "ES_URL="+es.GetURL())
+		case "pg", "postgres", "postgresql":
+			pg := testhelper.GetSharedPostgreSQL()
+			if err := pg.Start(ctx); err != nil {
+				release()
+				return nil, nil, fmt.Errorf("failed to warm postgresql: %w", err)
+			}
+			releases = append(releases, pg.Stop)
+			env = append(env, "USE_EXTERNAL_PG=true", "PG_URL="+pg.GetURL())
+		case "mongo", "mongodb":
+			mongo := testhelper.GetSharedMongoDB()
+			if err := mongo.Start(ctx); err != nil {
+				release()
+				return nil, nil, fmt.Errorf("failed to warm mongodb: %w", err)
+			}
+			releases = append(releases, mongo.Stop)
+			env = append(env, "USE_EXTERNAL_MONGO=true", "MONGO_URL="+mongo.GetURL())
+		default:
+			release()
+			return nil, nil, fmt.Errorf("unknown dependency %q (expected es, postgres or mongo)", name)
+		}
+		log.Printf("🔥 %s warm", strings.TrimSpace(name))
+	}
+	return env, release, nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// fileState identifica uma versão do arquivo
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// watcher detecta alterações por varredura periódica (sem dependências de notificação do SO)
+type watcher struct {
+	root  string
+	files map[string]fileState
+}
+
+// newWatcher cria o watcher com o estado atual da árvore
+func newWatcher(root string) *watcher {
+	return &watcher{root: root, files: scanTree(root)}
+}
+
+// waitForChanges aguarda alterações e retorna os arquivos criados, alterados ou removidos
+// Depois da primeira alteração aguarda uma varredura sem novidades (salvamentos em sequência viram uma rodada)
+func (w *watcher) waitForChanges(ctx context.Context, interval time.Duration) ([]string, error) {
+	changed := make(map[string]bool)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+
+		current := scanTree(w.root)
+		diff := diffTrees(w.files, current)
+		w.files = current
+
+		if len(diff) == 0 && len(changed) > 0 {
+			paths := make([]string, 0, len(changed))
+			for path := range changed {
+				paths = append(paths, path)
+			}
+			sort.Strings(paths)
+			return paths, nil
+		}
+		for _, path := range diff {
+			changed[path] = true
+		}
+	}
+}
+
+// scanTree lista os arquivos observados (ignora diretórios ocultos, vendor, node_modules e temporários de editores)
+func scanTree(root string) map[string]fileState {
+	files := make(map[string]fileState)
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if path != root && (strings.HasPrefix(name, ".") || name == "vendor" || name == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasPrefix(name, ".") || strings.HasSuffix(name, "~") || strings.HasSuffix(name, ".swp") {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			files[path] = fileState{modTime: info.ModTime(), size: info.Size()}
+		}
+		return nil
+	})
+	return files
+}
+
+// diffTrees retorna os caminhos que diferem entre duas varreduras
+func diffTrees(before, after map[string]fileState) []string {
+	var changed []string
+	for path, state := range after {
+		if previous, ok := before[path]; !ok || previous != state {
+			changed = append(changed, path)
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	return changed
+}
@@ -0,0 +1,53 @@
+// Package cursor codifica os valores de sort do search_after em cursores opacos, no mesmo formato
+// exposto pela API: JSON dos valores em base64 URL-safe, sem padding
+package cursor
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidCursor indica um cursor que não foi gerado por Encode (ou foi adulterado)
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Encode transforma os valores de sort do último hit de uma página no cursor da próxima
+// Sem valores (última página) retorna cursor vazio
+func Encode(sortValues []interface{}) (string, error) {
+	if len(sortValues) == 0 {
+		return "", nil
+	}
+
+	raw, err := json.Marshal(sortValues)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// Decode devolve os valores de sort para o search_after; cursor vazio (primeira página) retorna nil
+// Números são mantidos como json.Number para não perder precisão (ex.: timestamps e _shard_doc em int64)
+func Decode(c string) ([]interface{}, error) {
+	if c == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(c)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	var values []interface{}
+	if err := decoder.Decode(&values); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("%w: no sort values", ErrInvalidCursor)
+	}
+	return values, nil
+}
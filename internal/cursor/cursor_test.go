@@ -0,0 +1,53 @@
+package cursor
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor_RoundTrip(t *testing.T) {
+	t.Run("Sort values survive encoding", func(t *testing.T) {
+		c, err := Encode([]interface{}{1700000000123, "product-42", 9007199254740993})
+		require.NoError(t, err)
+		assert.NotContains(t, c, "=", "cursor must not be padded")
+
+		values, err := Decode(c)
+		require.NoError(t, err)
+		require.Len(t, values, 3)
+
+		// int64 acima de 2^53 não pode virar float64
+		assert.Equal(t, json.Number("1700000000123"), values[0])
+		assert.Equal(t, "product-42", values[1])
+		assert.Equal(t, json.Number("9007199254740993"), values[2])
+	})
+
+	t.Run("Sort values decoded as json.Number keep their precision", func(t *testing.T) {
+		// SearchPage passa os valores de sort decodificados com UseNumber
+		c, err := Encode([]interface{}{json.Number("9007199254740993"), "product-42"})
+		require.NoError(t, err)
+
+		values, err := Decode(c)
+		require.NoError(t, err)
+		assert.Equal(t, []interface{}{json.Number("9007199254740993"), "product-42"}, values)
+	})
+
+	t.Run("Empty cursor is the first page", func(t *testing.T) {
+		c, err := Encode(nil)
+		require.NoError(t, err)
+		assert.Empty(t, c)
+
+		values, err := Decode("")
+		require.NoError(t, err)
+		assert.Nil(t, values)
+	})
+
+	t.Run("Tampered cursors are rejected", func(t *testing.T) {
+		for _, c := range []string{"not base64!", "bm90IGpzb24", "W10"} {
+			_, err := Decode(c)
+			assert.ErrorIs(t, err, ErrInvalidCursor, c)
+		}
+	})
+}
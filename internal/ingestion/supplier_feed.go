@@ -0,0 +1,119 @@
+package ingestion
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/viniciussantos/claude-testcontainers/internal/repository"
+)
+
+const getProductsEnvelope = `<?xml version="1.0" encoding="UTF-8"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body>
+    <GetProducts/>
+  </soap:Body>
+</soap:Envelope>`
+
+type SupplierProduct struct {
+	Code        string  `xml:"Code"`
+	Title       string  `xml:"Title"`
+	Description string  `xml:"Description"`
+	Price       float64 `xml:"Price"`
+	Category    string  `xml:"Category"`
+}
+
+type getProductsResponse struct {
+	XMLName  xml.Name          `xml:"Envelope"`
+	Products []SupplierProduct `xml:"Body>GetProductsResponse>Product"`
+	Fault    *soapFault        `xml:"Body>Fault"`
+}
+
+type soapFault struct {
+	Code   string `xml:"faultcode"`
+	String string `xml:"faultstring"`
+}
+
+type SupplierFeedAdapter struct {
+	endpoint   string
+	httpClient *http.Client
+	repo       *repository.ProductRepository
+}
+
+func NewSupplierFeedAdapter(endpoint string, repo *repository.ProductRepository) *SupplierFeedAdapter {
+	return &SupplierFeedAdapter{
+		endpoint:   endpoint,
+		httpClient: http.DefaultClient,
+		repo:       repo,
+	}
+}
+
+func (a *SupplierFeedAdapter) FetchProducts(ctx context.Context) ([]SupplierProduct, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.endpoint, strings.NewReader(getProductsEnvelope))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build soap request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/xml; charset=utf-8")
+	req.Header.Set("SOAPAction", `"GetProducts"`)
+
+	res, err := a.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call supplier feed: %w", err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read supplier feed response: %w", err)
+	}
+
+	var envelope getProductsResponse
+	if err := xml.Unmarshal(body, &envelope); err != nil {
+		return nil, fmt.Errorf("failed to decode supplier feed response: %w", err)
+	}
+
+	// SOAP Faults podem vir com status 500 ou 200, dependendo do fornecedor
+	if envelope.Fault != nil {
+		return nil, fmt.Errorf("supplier feed fault %s: %s", envelope.Fault.Code, envelope.Fault.String)
+	}
+
+	if res.StatusCode >= 300 {
+		return nil, fmt.Errorf("supplier feed error: %s", res.Status)
+	}
+
+	return envelope.Products, nil
+}
+
+func (a *SupplierFeedAdapter) Ingest(ctx context.Context, tenantID string) (int, error) {
+	supplierProducts, err := a.FetchProducts(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, sp := range supplierProducts {
+		product := ToProduct(sp, tenantID)
+		if product.ID == "" {
+			return i, fmt.Errorf("supplier product at position %d has no code", i)
+		}
+
+		if err := a.repo.Create(ctx, product); err != nil {
+			return i, fmt.Errorf("failed to index supplier product %s: %w", product.ID, err)
+		}
+	}
+
+	return len(supplierProducts), nil
+}
+
+func ToProduct(sp SupplierProduct, tenantID string) *repository.Product {
+	return &repository.Product{
+		ID:          strings.TrimSpace(sp.Code),
+		Name:        strings.TrimSpace(sp.Title),
+		Description: strings.TrimSpace(sp.Description),
+		Price:       sp.Price,
+		Category:    strings.ToLower(strings.TrimSpace(sp.Category)),
+		TenantID:    tenantID,
+	}
+}
@@ -0,0 +1,104 @@
+package ingestion
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/viniciussantos/claude-testcontainers/internal/repository"
+	"github.com/viniciussantos/claude-testcontainers/test/testhelper"
+)
+
+// EXEMPLO DE INGESTÃO DE FEED SOAP/XML COM SERVIDOR MOCKADO
+func TestSupplierFeedAdapter(t *testing.T) {
+	suite := testhelper.NewIntegrationTestSuite(t)
+	suite.Setup()
+	defer suite.Teardown()
+
+	repo := repository.NewProductRepositoryWithIndex(suite.ES(), suite.IndexName(repository.ProductIndex))
+	ctx := context.Background()
+
+	t.Run("Ingest XML Feed", func(t *testing.T) {
+		tenantID := suite.NewTenantID()
+
+		mock := suite.MockSOAP(map[string]string{
+			"/catalog": "testdata/get_products_response.xml",
+		})
+
+		adapter := NewSupplierFeedAdapter(mock.URL()+"/catalog", repo)
+		count, err := adapter.Ingest(ctx, tenantID)
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+
+		suite.WaitForIndexing()
+
+		electronics, err := repo.SearchByCategory(ctx, "electronics", tenantID)
+		require.NoError(t, err)
+		assert.Len(t, electronics, 2)
+
+		product, err := repo.GetByID(ctx, "SUP-003", tenantID)
+		require.NoError(t, err)
+		require.NotNil(t, product)
+		assert.Equal(t, "Supplier Handbook", product.Name)
+		assert.Equal(t, 19.0, product.Price)
+
+		requests := mock.Requests()
+		require.Len(t, requests, 1)
+		assert.Equal(t, "GetProducts", requests[0].SOAPAction)
+	})
+
+	t.Run("SOAP Fault", func(t *testing.T) {
+		tenantID := suite.NewTenantID()
+
+		// Nenhum endpoint mockado: o servidor responde com um SOAP Fault
+		mock := suite.MockSOAP(map[string]string{})
+
+		adapter := NewSupplierFeedAdapter(mock.URL()+"/catalog", repo)
+		count, err := adapter.Ingest(ctx, tenantID)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "supplier feed fault")
+		assert.Equal(t, 0, count)
+	})
+
+	t.Run("Match by SOAPAction", func(t *testing.T) {
+		mock := suite.MockSOAP(map[string]string{
+			"GetProducts": `<Envelope><Body><GetProductsResponse>
+				<Product><Code>A1</Code><Title>Only</Title><Price>1.5</Price><Category>Misc</Category></Product>
+			</GetProductsResponse></Body></Envelope>`,
+		})
+
+		adapter := NewSupplierFeedAdapter(mock.URL()+"/any/path", repo)
+		products, err := adapter.FetchProducts(ctx)
+		require.NoError(t, err)
+		require.Len(t, products, 1)
+		assert.Equal(t, "A1", products[0].Code)
+		assert.Equal(t, "misc", ToProduct(products[0], "t").Category)
+	})
+	t.Run("Upstream Faults via Proxy", func(t *testing.T) {
+		tenantID := suite.NewTenantID()
+
+		mock := suite.MockSOAP(map[string]string{
+			"/catalog": "testdata/get_products_response.xml",
+		})
+		proxy := suite.FaultProxy(mock.URL())
+
+		adapter := NewSupplierFeedAdapter(proxy.URL()+"/catalog", repo)
+
+		// Primeira chamada falha com 503 injetado pelo proxy
+		proxy.FailNext(1, http.StatusServiceUnavailable)
+		_, err := adapter.Ingest(ctx, tenantID)
+		assert.Error(t, err)
+		assert.Equal(t, 1, proxy.InjectedFaults())
+
+		// Latência não impede a ingestão, apenas a atrasa
+		proxy.InjectLatency(50 * time.Millisecond)
+		count, err := adapter.Ingest(ctx, tenantID)
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+		assert.Equal(t, 2, proxy.Requests())
+		assert.Len(t, mock.Requests(), 1)
+	})
+}
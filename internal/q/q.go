@@ -0,0 +1,157 @@
+// Package q monta queries do Elasticsearch de forma fluente, no lugar de literais aninhados de
+// map[string]interface{}:
+//
+//	q.Search(q.Bool().Must(q.Term("category.keyword", category), q.Term("tenant_id.keyword", tenantID)))
+//
+// Todos os tipos são mapas: serializam direto com encoding/json e podem ser passados onde os helpers
+// esperam map[string]interface{} (ex.: suite.SearchDocuments, suite.CountDocuments)
+package q
+
+// Clause é uma cláusula de query ({"term": ...}, {"bool": ...}, {"range": ...})
+type Clause interface {
+	clause() map[string]interface{}
+}
+
+// Query é uma cláusula folha (term, match, exists...)
+type Query map[string]interface{}
+
+func (c Query) clause() map[string]interface{} { return c }
+
+// Raw usa uma cláusula montada à mão (tipos de query sem construtor aqui)
+func Raw(clause map[string]interface{}) Query {
+	return Query(clause)
+}
+
+// Term casa o valor exato do campo (use o subcampo .keyword para campos text)
+func Term(field string, value interface{}) Query {
+	return Query{"term": map[string]interface{}{field: value}}
+}
+
+// Terms casa qualquer um dos valores exatos do campo
+func Terms(field string, values ...interface{}) Query {
+	return Query{"terms": map[string]interface{}{field: values}}
+}
+
+// Match faz a busca full-text no campo
+func Match(field string, text interface{}) Query {
+	return Query{"match": map[string]interface{}{field: text}}
+}
+
+// MatchAll casa todos os documentos
+func MatchAll() Query {
+	return Query{"match_all": map[string]interface{}{}}
+}
+
+// Exists casa documentos com valor no campo
+func Exists(field string) Query {
+	return Query{"exists": map[string]interface{}{"field": field}}
+}
+
+// IDs casa documentos pelo _id
+func IDs(ids ...string) Query {
+	return Query{"ids": map[string]interface{}{"values": ids}}
+}
+
+// GeoDistance casa documentos com o geo_point do campo a até distance (ex.: "10km") de origin
+func GeoDistance(field string, origin interface{}, distance string) Query {
+	return Query{"geo_distance": map[string]interface{}{"distance": distance, field: origin}}
+}
+
+// RangeQuery é uma cláusula range; os limites são definidos com Gt, Gte, Lt e Lte
+type RangeQuery map[string]interface{}
+
+func (c RangeQuery) clause() map[string]interface{} { return c }
+
+// Range inicia uma cláusula range no campo
+func Range(field string) RangeQuery {
+	return RangeQuery{"range": map[string]interface{}{field: map[string]interface{}{}}}
+}
+
+// Gt define o limite inferior exclusivo
+func (c RangeQuery) Gt(value interface{}) RangeQuery { return c.bound("gt", value) }
+
+// Gte define o limite inferior inclusivo
+func (c RangeQuery) Gte(value interface{}) RangeQuery { return c.bound("gte", value) }
+
+// Lt define o limite superior exclusivo
+func (c RangeQuery) Lt(value interface{}) RangeQuery { return c.bound("lt", value) }
+
+// Lte define o limite superior inclusivo
+func (c RangeQuery) Lte(value interface{}) RangeQuery { return c.bound("lte", value) }
+
+func (c RangeQuery) bound(op string, value interface{}) RangeQuery {
+	for _, bounds := range c["range"].(map[string]interface{}) {
+		bounds.(map[string]interface{})[op] = value
+	}
+	return c
+}
+
+// BoolQuery é uma cláusula bool; as ocorrências vazias não são serializadas
+type BoolQuery map[string]interface{}
+
+func (c BoolQuery) clause() map[string]interface{} { return c }
+
+// Bool inicia uma cláusula bool
+func Bool() BoolQuery {
+	return BoolQuery{"bool": map[string]interface{}{}}
+}
+
+// Must adiciona cláusulas obrigatórias que contam no score
+func (c BoolQuery) Must(clauses ...Clause) BoolQuery { return c.add("must", clauses) }
+
+// Filter adiciona cláusulas obrigatórias sem score (cacheáveis)
+func (c BoolQuery) Filter(clauses ...Clause) BoolQuery { return c.add("filter", clauses) }
+
+// Should adiciona cláusulas opcionais (obrigatórias se não houver must/filter)
+func (c BoolQuery) Should(clauses ...Clause) BoolQuery { return c.add("should", clauses) }
+
+// MustNot adiciona cláusulas que excluem documentos
+func (c BoolQuery) MustNot(clauses ...Clause) BoolQuery { return c.add("must_not", clauses) }
+
+// MinimumShouldMatch define quantas cláusulas should precisam casar (ex.: 1 ou "75%")
+func (c BoolQuery) MinimumShouldMatch(value interface{}) BoolQuery {
+	c["bool"].(map[string]interface{})["minimum_should_match"] = value
+	return c
+}
+
+func (c BoolQuery) add(occur string, clauses []Clause) BoolQuery {
+	body := c["bool"].(map[string]interface{})
+	existing, _ := body[occur].([]map[string]interface{})
+	for _, clause := range clauses {
+		existing = append(existing, clause.clause())
+	}
+	body[occur] = existing
+	return c
+}
+
+// Request é o corpo de uma requisição _search
+type Request map[string]interface{}
+
+// Search monta o corpo de busca com a cláusula em "query"
+func Search(clause Clause) Request {
+	return Request{"query": clause.clause()}
+}
+
+// Size define o número máximo de hits
+func (r Request) Size(size int) Request {
+	r["size"] = size
+	return r
+}
+
+// From define o deslocamento dos hits (paginação)
+func (r Request) From(from int) Request {
+	r["from"] = from
+	return r
+}
+
+// Sort adiciona a ordenação pelo campo ("asc" ou "desc")
+func (r Request) Sort(field, order string) Request {
+	return r.SortBy(map[string]interface{}{field: map[string]interface{}{"order": order}})
+}
+
+// SortBy adiciona uma ordenação arbitrária (ex.: {"_geo_distance": {...}})
+func (r Request) SortBy(sort map[string]interface{}) Request {
+	sorts, _ := r["sort"].([]map[string]interface{})
+	r["sort"] = append(sorts, sort)
+	return r
+}
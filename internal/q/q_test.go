@@ -0,0 +1,58 @@
+package q
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuery_JSON(t *testing.T) {
+	t.Run("Bool with terms matches the hand-built body", func(t *testing.T) {
+		body := Search(Bool().Must(Term("category.keyword", "books"), Term("tenant_id.keyword", "t1")))
+
+		assertJSON(t, `{"query":{"bool":{"must":[
+			{"term":{"category.keyword":"books"}},
+			{"term":{"tenant_id.keyword":"t1"}}
+		]}}}`, body)
+	})
+
+	t.Run("Occurrences accumulate and nest", func(t *testing.T) {
+		clause := Bool().
+			Filter(Range("price").Gte(10).Lt(20)).
+			Filter(Exists("sku")).
+			Should(Match("name", "livro"), Bool().MustNot(IDs("p-1"))).
+			MinimumShouldMatch(1)
+
+		assertJSON(t, `{"bool":{
+			"filter":[{"range":{"price":{"gte":10,"lt":20}}},{"exists":{"field":"sku"}}],
+			"should":[{"match":{"name":"livro"}},{"bool":{"must_not":[{"ids":{"values":["p-1"]}}]}}],
+			"minimum_should_match":1
+		}}`, clause)
+	})
+
+	t.Run("Request options", func(t *testing.T) {
+		body := Search(MatchAll()).Size(5).From(10).Sort("price", "desc").
+			SortBy(map[string]interface{}{"_id": "asc"})
+
+		assertJSON(t, `{"query":{"match_all":{}},"size":5,"from":10,
+			"sort":[{"price":{"order":"desc"}},{"_id":"asc"}]}`, body)
+	})
+
+	t.Run("Request is usable as a plain map", func(t *testing.T) {
+		var body map[string]interface{} = Search(Terms("category.keyword", "a", "b"))
+
+		clause, ok := body["query"].(map[string]interface{})
+		require.True(t, ok, "query clause must be a plain map for helpers that inspect it")
+		assert.Contains(t, clause, "terms")
+	})
+}
+
+func assertJSON(t *testing.T, expected string, actual interface{}) {
+	t.Helper()
+
+	raw, err := json.Marshal(actual)
+	require.NoError(t, err)
+	assert.JSONEq(t, expected, string(raw))
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/viniciussantos/claude-testcontainers/test/fixtures"
+	"github.com/viniciussantos/claude-testcontainers/test/testhelper"
+)
+
+// CatalogTestSuite é uma suite de domínio construída sobre a IntegrationTestSuite
+// Todos os métodos da suite base (ES, CleanAll, TenantID...) ficam disponíveis sem delegação
+type CatalogTestSuite struct {
+	*testhelper.IntegrationTestSuite
+	Repo *ProductRepository
+}
+
+// NewCatalogTestSuite cria a suite de catálogo com o repositório já configurado
+func NewCatalogTestSuite(t *testing.T) *CatalogTestSuite {
+	base := testhelper.NewIntegrationTestSuite(t)
+	base.Setup()
+
+	return &CatalogTestSuite{
+		IntegrationTestSuite: base,
+		Repo:                 NewProductRepositoryWithIndex(base.ES(), base.IndexName(ProductIndex)),
+	}
+}
+
+// SeedProducts cria produtos no tenant da suite e remove-os ao final do teste
+// Os IDs recebem o escopo do tenant (ScopedID): o índice products é compartilhado entre testes e execuções
+func (s *CatalogTestSuite) SeedProducts(products ...*Product) {
+	s.T().Helper()
+
+	for _, p := range products {
+		p.TenantID = s.TenantID()
+		p.ID = testhelper.ScopedID(s.IntegrationTestSuite, p.ID)
+		err := s.Repo.Create(s.Context(), p)
+		require.NoError(s.T(), err)
+
+		id := p.ID
+		s.RegisterCleanup(func() {
+			s.DeleteDocument("products", id)
+		})
+	}
+
+	s.WaitForIndexing()
+}
+
+// EXEMPLO DE SUITE DE DOMÍNIO (COMPOSIÇÃO)
+func TestCatalogTestSuite(t *testing.T) {
+	suite := NewCatalogTestSuite(t)
+	defer suite.Teardown()
+
+	suite.SeedProducts(
+		&Product{ID: "catalog1", Name: "Laptop", Category: "electronics", Price: 999.99},
+		&Product{ID: "catalog2", Name: "Book", Category: "books", Price: 19.99},
+	)
+
+	t.Run("Search in Suite Tenant", func(t *testing.T) {
+		sub := suite.ForTest(t)
+
+		results, err := suite.Repo.SearchByCategory(sub.Context(), "electronics", sub.TenantID())
+		require.NoError(t, err)
+		assert.Len(t, results, 1)
+		assert.Equal(t, "catalog1", testhelper.UnscopedID(suite.IntegrationTestSuite, results[0].ID))
+	})
+
+	t.Run("Other Tenant Is Isolated", func(t *testing.T) {
+		other := suite.ForTest(t).WithTenant(suite.NewTenantID())
+
+		results, err := suite.Repo.SearchByCategory(other.Context(), "electronics", other.TenantID())
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+
+	t.Run("Helpers Accept Domain Suites", func(t *testing.T) {
+		var s testhelper.Suite = suite
+		assert.Equal(t, suite.TenantID(), s.Base().TenantID())
+	})
+}
+
+// EXEMPLO DE FIXTURES TIPADAS (geradas por cmd/esgen a partir do mapping)
+func TestCatalogFixtures(t *testing.T) {
+	suite := NewCatalogTestSuite(t)
+	defer suite.Teardown()
+
+	loaded := suite.LoadFixtures(fixtures.ProductIndex, "../../test/fixtures/testdata/products.json")
+	require.Len(t, loaded, 3)
+
+	laptop, ok := loaded[0].(*fixtures.Product)
+	require.True(t, ok, "fixtures should be decoded into the generated type")
+	assert.Equal(t, suite.TenantID(), laptop.TenantID)
+
+	results, err := suite.Repo.SearchByCategory(suite.Context(), "electronics", suite.TenantID())
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"sync"
+)
+
+// memorySearchSize replica o tamanho padrão de página do Elasticsearch (10 hits)
+const memorySearchSize = 10
+
+var _ ProductRepositoryInterface = (*MemoryProductRepository)(nil)
+
+// MemoryProductRepository é um fake em memória com a mesma semântica do ProductRepository:
+// IDs são globais (Create sobrescreve), GetByID esconde produtos de outro tenant e
+// SearchByCategory casa categoria e tenant exatos, limitado a 10 resultados
+type MemoryProductRepository struct {
+	mu       sync.RWMutex
+	products map[string]Product
+	order    []string
+}
+
+func NewMemoryProductRepository() *MemoryProductRepository {
+	return &MemoryProductRepository{
+		products: make(map[string]Product),
+	}
+}
+
+func (r *MemoryProductRepository) Create(ctx context.Context, product *Product) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.products[product.ID]; !exists {
+		r.order = append(r.order, product.ID)
+	}
+	r.products[product.ID] = *product
+	return nil
+}
+
+func (r *MemoryProductRepository) GetByID(ctx context.Context, id string, tenantID string) (*Product, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	product, found := r.products[id]
+	if !found || product.TenantID != tenantID {
+		return nil, nil
+	}
+	return &product, nil
+}
+
+func (r *MemoryProductRepository) SearchByCategory(ctx context.Context, category string, tenantID string) ([]*Product, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var products []*Product
+	for _, id := range r.order {
+		product := r.products[id]
+		if product.Category != category || product.TenantID != tenantID {
+			continue
+		}
+		products = append(products, &product)
+		if len(products) == memorySearchSize {
+			break
+		}
+	}
+	return products, nil
+}
+
+// Reset remove todos os produtos
+func (r *MemoryProductRepository) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.products = make(map[string]Product)
+	r.order = nil
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/viniciussantos/claude-testcontainers/test/testhelper"
+)
+
+// Mesmo corpo de teste para o fake e para o Elasticsearch:
+// localmente roda em memória; no CI (TEST_FAKES=false) roda contra o container
+func TestProductRepository_Contract(t *testing.T) {
+	suite, err := testhelper.NewIntegrationTestSuiteBuilder(t).
+		WithElasticsearch().
+		WithFakes().
+		Build()
+	require.NoError(t, err)
+	defer suite.Teardown()
+
+	repo := testhelper.FakeOr(suite,
+		func() ProductRepositoryInterface { return NewMemoryProductRepository() },
+		func() ProductRepositoryInterface { return NewProductRepositoryWithIndex(suite.ES(), suite.IndexName(ProductIndex)) })
+	ctx := context.Background()
+
+	t.Run("Get respects tenant", func(t *testing.T) {
+		tenantID := suite.NewTenantID()
+		product := &Product{ID: tenantID + "-1", Name: "Keyboard", Category: "electronics", Price: 49.9, TenantID: tenantID}
+
+		require.NoError(t, repo.Create(ctx, product))
+
+		retrieved, err := repo.GetByID(ctx, product.ID, tenantID)
+		require.NoError(t, err)
+		require.NotNil(t, retrieved)
+		assert.Equal(t, *product, *retrieved)
+
+		other, err := repo.GetByID(ctx, product.ID, suite.NewTenantID())
+		require.NoError(t, err)
+		assert.Nil(t, other)
+	})
+
+	t.Run("Create overwrites by ID", func(t *testing.T) {
+		tenantID := suite.NewTenantID()
+		id := tenantID + "-1"
+
+		require.NoError(t, repo.Create(ctx, &Product{ID: id, Name: "Old", Category: "books", TenantID: tenantID}))
+		require.NoError(t, repo.Create(ctx, &Product{ID: id, Name: "New", Category: "books", TenantID: tenantID}))
+
+		retrieved, err := repo.GetByID(ctx, id, tenantID)
+		require.NoError(t, err)
+		require.NotNil(t, retrieved)
+		assert.Equal(t, "New", retrieved.Name)
+
+		products, err := repo.SearchByCategory(ctx, "books", tenantID)
+		require.NoError(t, err)
+		assert.Len(t, products, 1)
+	})
+
+	t.Run("Search filters by category and tenant", func(t *testing.T) {
+		tenantID := suite.NewTenantID()
+		otherTenant := suite.NewTenantID()
+
+		require.NoError(t, repo.Create(ctx, &Product{ID: tenantID + "-1", Category: "electronics", TenantID: tenantID}))
+		require.NoError(t, repo.Create(ctx, &Product{ID: tenantID + "-2", Category: "books", TenantID: tenantID}))
+		require.NoError(t, repo.Create(ctx, &Product{ID: otherTenant + "-1", Category: "electronics", TenantID: otherTenant}))
+
+		products, err := repo.SearchByCategory(ctx, "electronics", tenantID)
+		require.NoError(t, err)
+		require.Len(t, products, 1)
+		assert.Equal(t, tenantID+"-1", products[0].ID)
+
+		none, err := repo.SearchByCategory(ctx, "toys", tenantID)
+		require.NoError(t, err)
+		assert.Empty(t, none)
+	})
+
+	t.Run("Search returns at most 10 products", func(t *testing.T) {
+		tenantID := suite.NewTenantID()
+		for i := 0; i < 12; i++ {
+			product := &Product{ID: fmt.Sprintf("%s-%d", tenantID, i), Category: "electronics", TenantID: tenantID}
+			require.NoError(t, repo.Create(ctx, product))
+		}
+
+		products, err := repo.SearchByCategory(ctx, "electronics", tenantID)
+		require.NoError(t, err)
+		assert.Len(t, products, 10)
+	})
+}
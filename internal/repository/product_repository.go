@@ -1,15 +1,23 @@
 package repository
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/elastic/go-elasticsearch/v8"
 	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"github.com/elastic/go-elasticsearch/v8/esutil"
+	"github.com/viniciussantos/claude-testcontainers/internal/q"
 )
 
+// maxBulkErrors limita quantos erros de item o CreateBulk agrega no erro retornado
+const maxBulkErrors = 10
+
 type Product struct {
 	ID          string  `json:"id"`
 	Name        string  `json:"name"`
@@ -19,13 +27,36 @@ type Product struct {
 	TenantID    string  `json:"tenant_id"`
 }
 
+//go:generate moq -out mocks/product_repository_mock.go -pkg mocks . ProductRepositoryInterface
+
+// ProductRepositoryInterface é o contrato comum às implementações de repositório de produtos
+// (Elasticsearch e o fake em memória usado por testes rápidos)
+type ProductRepositoryInterface interface {
+	Create(ctx context.Context, product *Product) error
+	GetByID(ctx context.Context, id string, tenantID string) (*Product, error)
+	SearchByCategory(ctx context.Context, category string, tenantID string) ([]*Product, error)
+}
+
+var _ ProductRepositoryInterface = (*ProductRepository)(nil)
+
+// ProductIndex é o índice padrão dos produtos
+const ProductIndex = "products"
+
 type ProductRepository struct {
 	client *elasticsearch.Client
+	index  string
 }
 
 func NewProductRepository(client *elasticsearch.Client) *ProductRepository {
+	return NewProductRepositoryWithIndex(client, ProductIndex)
+}
+
+// NewProductRepositoryWithIndex usa outro índice (ex.: nos testes, o nome com escopo da execução
+// retornado por suite.IndexName)
+func NewProductRepositoryWithIndex(client *elasticsearch.Client, index string) *ProductRepository {
 	return &ProductRepository{
 		client: client,
+		index:  index,
 	}
 }
 
@@ -36,7 +67,7 @@ func (r *ProductRepository) Create(ctx context.Context, product *Product) error
 	}
 
 	req := esapi.IndexRequest{
-		Index:      "products",
+		Index:      r.index,
 		DocumentID: product.ID,
 		Body:       strings.NewReader(string(productJSON)),
 		Refresh:    "true",
@@ -55,9 +86,85 @@ func (r *ProductRepository) Create(ctx context.Context, product *Product) error
 	return nil
 }
 
+// CreateBulk indexa os produtos com o BulkIndexer (requisições _bulk em paralelo) e faz um único
+// refresh ao final. Falhas de itens são agregadas no erro retornado
+func (r *ProductRepository) CreateBulk(ctx context.Context, products []*Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	var (
+		mu   sync.Mutex
+		errs []error
+	)
+	record := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if len(errs) < maxBulkErrors {
+			errs = append(errs, err)
+		}
+	}
+
+	indexer, err := esutil.NewBulkIndexer(esutil.BulkIndexerConfig{
+		Client: r.client,
+		Index:  r.index,
+		OnError: func(ctx context.Context, err error) {
+			record(fmt.Errorf("bulk request failed: %w", err))
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create bulk indexer: %w", err)
+	}
+
+	for _, product := range products {
+		productJSON, err := json.Marshal(product)
+		if err != nil {
+			indexer.Close(ctx)
+			return fmt.Errorf("failed to marshal product %s: %w", product.ID, err)
+		}
+
+		err = indexer.Add(ctx, esutil.BulkIndexerItem{
+			Action:     "index",
+			DocumentID: product.ID,
+			Body:       bytes.NewReader(productJSON),
+			OnFailure: func(ctx context.Context, item esutil.BulkIndexerItem, res esutil.BulkIndexerResponseItem, err error) {
+				if err != nil {
+					record(fmt.Errorf("product %s: %w", item.DocumentID, err))
+					return
+				}
+				record(fmt.Errorf("product %s: %s: %s", item.DocumentID, res.Error.Type, res.Error.Reason))
+			},
+		})
+		if err != nil {
+			indexer.Close(ctx)
+			return fmt.Errorf("failed to add product %s: %w", product.ID, err)
+		}
+	}
+
+	if err := indexer.Close(ctx); err != nil {
+		return fmt.Errorf("failed to bulk index products: %w", err)
+	}
+
+	if failed := indexer.Stats().NumFailed; failed > 0 {
+		return fmt.Errorf("failed to index %d of %d products: %w", failed, len(products), errors.Join(errs...))
+	}
+
+	res, err := esapi.IndicesRefreshRequest{Index: []string{r.index}}.Do(ctx, r.client)
+	if err != nil {
+		return fmt.Errorf("failed to refresh products: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("elasticsearch error: %s", res.Status())
+	}
+
+	return nil
+}
+
 func (r *ProductRepository) GetByID(ctx context.Context, id string, tenantID string) (*Product, error) {
 	req := esapi.GetRequest{
-		Index:      "products",
+		Index:      r.index,
 		DocumentID: id,
 	}
 
@@ -104,24 +211,10 @@ func (r *ProductRepository) GetByID(ctx context.Context, id string, tenantID str
 }
 
 func (r *ProductRepository) SearchByCategory(ctx context.Context, category string, tenantID string) ([]*Product, error) {
-	query := map[string]interface{}{
-		"query": map[string]interface{}{
-			"bool": map[string]interface{}{
-				"must": []map[string]interface{}{
-					{
-						"term": map[string]interface{}{
-							"category.keyword": category,
-						},
-					},
-					{
-						"term": map[string]interface{}{
-							"tenant_id.keyword": tenantID,
-						},
-					},
-				},
-			},
-		},
-	}
+	query := q.Search(q.Bool().Must(
+		q.Term("category.keyword", category),
+		q.Term("tenant_id.keyword", tenantID),
+	))
 
 	queryJSON, err := json.Marshal(query)
 	if err != nil {
@@ -129,7 +222,7 @@ func (r *ProductRepository) SearchByCategory(ctx context.Context, category strin
 	}
 
 	req := esapi.SearchRequest{
-		Index: []string{"products"},
+		Index: []string{r.index},
 		Body:  strings.NewReader(string(queryJSON)),
 	}
 
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -17,13 +18,13 @@ func TestProductRepository(t *testing.T) {
 	defer suite.Teardown()
 	
 	// Usa cliente compartilhado
-	repo := NewProductRepository(suite.ES())
+	repo := NewProductRepositoryWithIndex(suite.ES(), suite.IndexName(ProductIndex))
 	ctx := context.Background()
 	
 	t.Run("Create and Get Product", func(t *testing.T) {
 		tenantID := suite.NewTenantID() // Tenant único para este subteste
 		product := &Product{
-			ID:          "1", 
+			ID:          testhelper.ScopedID(suite, "1"), // evita colisão com outros pacotes no índice compartilhado
 			Name:        "Test Product",
 			Description: "A test product",
 			Price:       99.99,
@@ -34,7 +35,7 @@ func TestProductRepository(t *testing.T) {
 		err := repo.Create(ctx, product)
 		require.NoError(t, err)
 		
-		retrieved, err := repo.GetByID(ctx, "1", tenantID)
+		retrieved, err := repo.GetByID(ctx, testhelper.ScopedID(suite, "1"), tenantID)
 		require.NoError(t, err)
 		require.NotNil(t, retrieved)
 		
@@ -47,7 +48,7 @@ func TestProductRepository(t *testing.T) {
 	t.Run("Search by Category", func(t *testing.T) {
 		tenantID := suite.NewTenantID() // Tenant único para este subteste
 		product1 := &Product{
-			ID:       "2",
+			ID:       testhelper.ScopedID(suite, "2"),
 			Name:     "Electronics Product",
 			Category: "electronics", 
 			Price:    199.99,
@@ -55,7 +56,7 @@ func TestProductRepository(t *testing.T) {
 		}
 		
 		product2 := &Product{
-			ID:       "3",
+			ID:       testhelper.ScopedID(suite, "3"),
 			Name:     "Books Product",
 			Category: "books",
 			Price:    29.99,
@@ -75,7 +76,7 @@ func TestProductRepository(t *testing.T) {
 		require.NoError(t, err)
 		
 		assert.Len(t, electronics, 1)
-		assert.Equal(t, "2", electronics[0].ID)
+		assert.Equal(t, "2", testhelper.UnscopedID(suite, electronics[0].ID))
 		assert.Equal(t, "Electronics Product", electronics[0].Name)
 		assert.Equal(t, tenantID, electronics[0].TenantID)
 	})
@@ -95,7 +96,7 @@ func TestProductRepository_Multiple(t *testing.T) {
 	suite.Setup() // Estado limpo garantido
 	defer suite.Teardown()
 	
-	repo := NewProductRepository(suite.ES())
+	repo := NewProductRepositoryWithIndex(suite.ES(), suite.IndexName(ProductIndex))
 	ctx := context.Background()
 	
 	t.Run("Bulk Operations", func(t *testing.T) {
@@ -119,6 +120,29 @@ func TestProductRepository_Multiple(t *testing.T) {
 		assert.Len(t, results, 3)
 	})
 	
+	t.Run("CreateBulk", func(t *testing.T) {
+		tenantId := testhelper.GenerateTenantID()
+
+		products := make([]*Product, 0, 50)
+		for i := 0; i < 50; i++ {
+			products = append(products, &Product{
+				ID:       testhelper.ScopedIDForTenant(tenantId, fmt.Sprintf("createbulk-%d", i)),
+				Name:     fmt.Sprintf("Product %d", i),
+				Category: "createbulk",
+				Price:    float64(i),
+				TenantID: tenantId,
+			})
+		}
+
+		err := repo.CreateBulk(ctx, products)
+		require.NoError(t, err)
+
+		retrieved, err := repo.GetByID(ctx, testhelper.ScopedIDForTenant(tenantId, "createbulk-42"), tenantId)
+		require.NoError(t, err)
+		require.NotNil(t, retrieved)
+		assert.Equal(t, "Product 42", retrieved.Name)
+	})
+	
 	t.Run("Category Isolation", func(t *testing.T) {
 		tenantId := testhelper.GenerateTenantID()
 
@@ -154,7 +178,7 @@ func TestProductRepository_Suite(t *testing.T) {
 	suite.Setup()
 	defer suite.Teardown()
 	
-	repo := NewProductRepository(suite.ES())
+	repo := NewProductRepositoryWithIndex(suite.ES(), suite.IndexName(ProductIndex))
 	ctx := context.Background()
 	
 	// Setup de fixtures para toda a suite
@@ -275,7 +299,7 @@ func TestProductRepository_Parallel(t *testing.T) {
 	suite.Setup()
 	defer suite.Teardown()
 	
-	repo := NewProductRepository(suite.ES())
+	repo := NewProductRepositoryWithIndex(suite.ES(), suite.IndexName(ProductIndex))
 	ctx := context.Background()
 	
 	// Cada teste paralelo usa namespace único para evitar conflitos
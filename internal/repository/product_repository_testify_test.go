@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/viniciussantos/claude-testcontainers/test/testhelper"
+)
+
+// ProductRepositoryTestifySuite usa o ciclo de vida do testify/suite: containers compartilhados sobem no
+// SetupSuite e cada método de teste recebe uma IntegrationTestSuite com tenant próprio
+type ProductRepositoryTestifySuite struct {
+	testhelper.TestifySuite
+}
+
+func (s *ProductRepositoryTestifySuite) TestCreateAndGet() {
+	it := s.Integration()
+	repo := NewProductRepositoryWithIndex(it.ES(), it.IndexName(ProductIndex))
+
+	// ID com escopo no tenant: o índice products é compartilhado com os outros testes do pacote
+	id := testhelper.ScopedID(it, "p1")
+	product := &Product{ID: id, Name: "Laptop", Category: "electronics", Price: 999.99, TenantID: it.TenantID()}
+	s.Require().NoError(repo.Create(it.Context(), product))
+	it.RegisterCleanup(func() {
+		it.DeleteDocument("products", id)
+	})
+
+	retrieved, err := repo.GetByID(it.Context(), id, it.TenantID())
+	s.Require().NoError(err)
+	s.Equal("Laptop", retrieved.Name)
+}
+
+func (s *ProductRepositoryTestifySuite) TestTenantIsolationBetweenTests() {
+	it := s.Integration()
+	repo := NewProductRepositoryWithIndex(it.ES(), it.IndexName(ProductIndex))
+
+	// O produto do teste anterior pertence a outro tenant
+	results, err := repo.SearchByCategory(it.Context(), "electronics", it.TenantID())
+	s.Require().NoError(err)
+	s.Empty(results)
+}
+
+// EXEMPLO DE SUITE TESTIFY
+func TestProductRepository_TestifySuite(t *testing.T) {
+	suite.Run(t, &ProductRepositoryTestifySuite{
+		TestifySuite: testhelper.NewTestifySuite(func(b *testhelper.TestDependenciesBuilder) {
+			b.WithElasticsearch()
+		}),
+	})
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"github.com/viniciussantos/claude-testcontainers/internal/q"
+)
+
+// TenantIndexProductRepository é a variante com um índice por tenant ("<prefix>-<tenant>")
+// O isolamento vem do índice, então as consultas não filtram por tenant_id
+type TenantIndexProductRepository struct {
+	client *elasticsearch.Client
+	prefix string
+}
+
+func NewTenantIndexProductRepository(client *elasticsearch.Client, prefix string) *TenantIndexProductRepository {
+	return &TenantIndexProductRepository{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// IndexFor retorna o índice do tenant
+func (r *TenantIndexProductRepository) IndexFor(tenantID string) string {
+	return strings.ToLower(r.prefix + "-" + tenantID)
+}
+
+func (r *TenantIndexProductRepository) Create(ctx context.Context, product *Product) error {
+	if product.TenantID == "" {
+		return fmt.Errorf("tenant ID is required")
+	}
+
+	productJSON, err := json.Marshal(product)
+	if err != nil {
+		return fmt.Errorf("failed to marshal product: %w", err)
+	}
+
+	req := esapi.IndexRequest{
+		Index:      r.IndexFor(product.TenantID),
+		DocumentID: product.ID,
+		Body:       strings.NewReader(string(productJSON)),
+		Refresh:    "true",
+	}
+
+	res, err := req.Do(ctx, r.client)
+	if err != nil {
+		return fmt.Errorf("failed to index product: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("elasticsearch error: %s", res.Status())
+	}
+
+	return nil
+}
+
+func (r *TenantIndexProductRepository) GetByID(ctx context.Context, id string, tenantID string) (*Product, error) {
+	req := esapi.GetRequest{
+		Index:      r.IndexFor(tenantID),
+		DocumentID: id,
+	}
+
+	res, err := req.Do(ctx, r.client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+	defer res.Body.Close()
+
+	// 404 cobre tanto o documento quanto o índice do tenant inexistentes
+	if res.StatusCode == 404 {
+		return nil, nil
+	}
+
+	if res.IsError() {
+		return nil, fmt.Errorf("elasticsearch error: %s", res.Status())
+	}
+
+	var response struct {
+		Source Product `json:"_source"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &response.Source, nil
+}
+
+func (r *TenantIndexProductRepository) SearchByCategory(ctx context.Context, category string, tenantID string) ([]*Product, error) {
+	query := q.Search(q.Term("category.keyword", category))
+
+	queryJSON, err := json.Marshal(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal query: %w", err)
+	}
+
+	req := esapi.SearchRequest{
+		Index:             []string{r.IndexFor(tenantID)},
+		Body:              strings.NewReader(string(queryJSON)),
+		IgnoreUnavailable: esapi.BoolPtr(true),
+	}
+
+	res, err := req.Do(ctx, r.client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search products: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, fmt.Errorf("elasticsearch search error: %s", res.Status())
+	}
+
+	var searchResponse struct {
+		Hits struct {
+			Hits []struct {
+				Source Product `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&searchResponse); err != nil {
+		return nil, fmt.Errorf("failed to decode search response: %w", err)
+	}
+
+	products := make([]*Product, 0, len(searchResponse.Hits.Hits))
+	for i := range searchResponse.Hits.Hits {
+		products = append(products, &searchResponse.Hits.Hits[i].Source)
+	}
+
+	return products, nil
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/viniciussantos/claude-testcontainers/test/testhelper"
+)
+
+func init() {
+	// Mesmo mapping para todos os índices "products_by_tenant-*"
+	testhelper.RegisterTenantIndexTemplate("products_by_tenant", map[string]interface{}{
+		"mappings": map[string]interface{}{
+			"properties": map[string]interface{}{
+				"category": map[string]interface{}{
+					"type":   "text",
+					"fields": map[string]interface{}{"keyword": map[string]interface{}{"type": "keyword"}},
+				},
+				"price": map[string]interface{}{"type": "double"},
+			},
+		},
+	})
+}
+
+// EXEMPLO DE MULTI-TENANCY COM ÍNDICE POR TENANT
+func TestTenantIndexProductRepository(t *testing.T) {
+	suite := testhelper.NewIntegrationTestSuite(t)
+	suite.Setup()
+	defer suite.Teardown()
+
+	repo := NewTenantIndexProductRepository(suite.ES(), "products_by_tenant")
+	ctx := suite.Context()
+
+	// O helper cria o índice do tenant (com o template) e remove-o ao final do teste
+	index := suite.TenantIndex("products_by_tenant")
+	require.Equal(t, repo.IndexFor(suite.TenantID()), index)
+
+	err := repo.Create(ctx, &Product{ID: "p1", Name: "Laptop", Category: "electronics", Price: 999.99, TenantID: suite.TenantID()})
+	require.NoError(t, err)
+
+	t.Run("Search in Tenant Index", func(t *testing.T) {
+		results, err := repo.SearchByCategory(ctx, "electronics", suite.TenantID())
+		require.NoError(t, err)
+		assert.Len(t, results, 1)
+	})
+
+	t.Run("Other Tenant Has Its Own Index", func(t *testing.T) {
+		other := suite.NewTenantID()
+		suite.ForTest(t).TenantIndexFor("products_by_tenant", other)
+
+		results, err := repo.SearchByCategory(ctx, "electronics", other)
+		require.NoError(t, err)
+		assert.Empty(t, results)
+
+		product, err := repo.GetByID(ctx, "p1", other)
+		require.NoError(t, err)
+		assert.Nil(t, product)
+	})
+
+	t.Run("Missing Tenant Index Returns Nothing", func(t *testing.T) {
+		results, err := repo.SearchByCategory(ctx, "electronics", suite.NewTenantID())
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"github.com/viniciussantos/claude-testcontainers/internal/q"
+)
+
+// GeoPoint é uma localização no formato aceito por campos geo_point
+type GeoPoint struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// Warehouse é um depósito (ou loja) com localização e os produtos em estoque
+type Warehouse struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Location   GeoPoint `json:"location"`
+	ProductIDs []string `json:"product_ids"`
+	TenantID   string   `json:"tenant_id"`
+}
+
+// NearbyWarehouse é um depósito encontrado pela busca geográfica e sua distância (km) ao ponto buscado
+type NearbyWarehouse struct {
+	Warehouse  Warehouse
+	DistanceKm float64
+}
+
+// WarehouseMapping é o mapping esperado pelo índice de depósitos (location como geo_point)
+var WarehouseMapping = map[string]interface{}{
+	"properties": map[string]interface{}{
+		"location":    map[string]interface{}{"type": "geo_point"},
+		"product_ids": map[string]interface{}{"type": "keyword"},
+		"tenant_id":   map[string]interface{}{"type": "keyword"},
+		"name":        map[string]interface{}{"type": "text"},
+	},
+}
+
+// WarehouseRepository implementa o store locator ("onde encontro este produto perto de mim")
+type WarehouseRepository struct {
+	client *elasticsearch.Client
+	index  string
+}
+
+func NewWarehouseRepository(client *elasticsearch.Client, index string) *WarehouseRepository {
+	return &WarehouseRepository{
+		client: client,
+		index:  index,
+	}
+}
+
+func (r *WarehouseRepository) Create(ctx context.Context, warehouse *Warehouse) error {
+	warehouseJSON, err := json.Marshal(warehouse)
+	if err != nil {
+		return fmt.Errorf("failed to marshal warehouse: %w", err)
+	}
+
+	req := esapi.IndexRequest{
+		Index:      r.index,
+		DocumentID: warehouse.ID,
+		Body:       strings.NewReader(string(warehouseJSON)),
+		Refresh:    "true",
+	}
+
+	res, err := req.Do(ctx, r.client)
+	if err != nil {
+		return fmt.Errorf("failed to index warehouse: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("elasticsearch error: %s", res.Status())
+	}
+
+	return nil
+}
+
+// FindProductNearby retorna os depósitos do tenant que têm o produto em estoque a até distance
+// (ex.: "10km") do ponto, do mais próximo para o mais distante
+func (r *WarehouseRepository) FindProductNearby(ctx context.Context, productID string, origin GeoPoint, distance string, tenantID string) ([]NearbyWarehouse, error) {
+	query := q.Search(q.Bool().Filter(
+		q.Term("tenant_id", tenantID),
+		q.Term("product_ids", productID),
+		q.GeoDistance("location", origin, distance),
+	)).SortBy(map[string]interface{}{
+		"_geo_distance": map[string]interface{}{
+			"location": origin,
+			"order":    "asc",
+			"unit":     "km",
+		},
+	})
+	queryJSON, err := json.Marshal(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal query: %w", err)
+	}
+
+	req := esapi.SearchRequest{
+		Index: []string{r.index},
+		Body:  strings.NewReader(string(queryJSON)),
+	}
+
+	res, err := req.Do(ctx, r.client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search warehouses: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, fmt.Errorf("elasticsearch search error: %s", res.Status())
+	}
+
+	var searchResponse struct {
+		Hits struct {
+			Hits []struct {
+				Source Warehouse `json:"_source"`
+				Sort   []float64 `json:"sort"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&searchResponse); err != nil {
+		return nil, fmt.Errorf("failed to decode search response: %w", err)
+	}
+
+	warehouses := make([]NearbyWarehouse, 0, len(searchResponse.Hits.Hits))
+	for _, hit := range searchResponse.Hits.Hits {
+		nearby := NearbyWarehouse{Warehouse: hit.Source}
+		if len(hit.Sort) > 0 {
+			nearby.DistanceKm = hit.Sort[0]
+		}
+		warehouses = append(warehouses, nearby)
+	}
+
+	return warehouses, nil
+}
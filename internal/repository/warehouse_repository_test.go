@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/viniciussantos/claude-testcontainers/test/testhelper"
+)
+
+func init() {
+	testhelper.RegisterTenantIndexTemplate("warehouses", map[string]interface{}{
+		"mappings": WarehouseMapping,
+	})
+}
+
+// EXEMPLO DE BUSCA GEOGRÁFICA (STORE LOCATOR)
+func TestWarehouseRepository_FindProductNearby(t *testing.T) {
+	suite := testhelper.NewIntegrationTestSuite(t)
+	suite.Setup()
+	defer suite.Teardown()
+
+	index := suite.TenantIndex("warehouses")
+	repo := NewWarehouseRepository(suite.ES(), index)
+	ctx := suite.Context()
+
+	paulista := GeoPoint{Lat: -23.5614, Lon: -46.6559}
+	warehouses := []*Warehouse{
+		{ID: "pinheiros", Name: "Pinheiros", Location: GeoPoint{Lat: -23.5670, Lon: -46.6920}, ProductIDs: []string{"p1", "p2"}},
+		{ID: "santo-andre", Name: "Santo André", Location: GeoPoint{Lat: -23.6639, Lon: -46.5383}, ProductIDs: []string{"p1"}},
+		{ID: "campinas", Name: "Campinas", Location: GeoPoint{Lat: -22.9056, Lon: -47.0608}, ProductIDs: []string{"p1"}},
+		{ID: "consolacao", Name: "Consolação", Location: GeoPoint{Lat: -23.5535, Lon: -46.6601}, ProductIDs: []string{"p2"}},
+	}
+	for _, w := range warehouses {
+		w.TenantID = suite.TenantID()
+		require.NoError(t, repo.Create(ctx, w))
+	}
+
+	t.Run("Nearest First Within Radius", func(t *testing.T) {
+		results, err := repo.FindProductNearby(ctx, "p1", paulista, "20km", suite.TenantID())
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+
+		assert.Equal(t, "pinheiros", results[0].Warehouse.ID)
+		assert.Equal(t, "santo-andre", results[1].Warehouse.ID)
+		assert.InDelta(t, 3.7, results[0].DistanceKm, 0.5)
+		assert.Less(t, results[0].DistanceKm, results[1].DistanceKm)
+	})
+
+	t.Run("Only Warehouses With The Product", func(t *testing.T) {
+		results, err := repo.FindProductNearby(ctx, "p2", paulista, "5km", suite.TenantID())
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, "consolacao", results[0].Warehouse.ID)
+	})
+
+	t.Run("Tenant Isolation", func(t *testing.T) {
+		results, err := repo.FindProductNearby(ctx, "p1", paulista, "200km", suite.NewTenantID())
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+
+	t.Run("Suite Radius Helpers", func(t *testing.T) {
+		suite.AssertWithinRadius(index, "location", paulista.Lat, paulista.Lon, "100km",
+			"consolacao", "pinheiros", "santo-andre", "campinas")
+
+		result := suite.SearchWithinRadius(index, "location", paulista.Lat, paulista.Lon, "2km")
+		assert.Equal(t, []string{"consolacao"}, result.IDs())
+		assert.Len(t, result.GeoDistances(), 1)
+	})
+}
+
+func TestGeoShapeHelpers(t *testing.T) {
+	suite := testhelper.NewIntegrationTestSuite(t)
+	suite.Setup()
+	defer suite.Teardown()
+
+	index := strings.ToLower(suite.TenantID()) + "-delivery-zones"
+	suite.CreateIndex(index, testhelper.GeoMapping(nil, []string{"area"}, nil))
+	t.Cleanup(func() {
+		res, err := suite.ES().Indices.Delete([]string{index})
+		if err == nil {
+			res.Body.Close()
+		}
+	})
+
+	// Zona de entrega cobrindo o centro expandido de São Paulo
+	suite.IndexDocument(index, "centro", map[string]interface{}{
+		"area": testhelper.GeoPolygon(
+			testhelper.GeoCoord{Lat: -23.50, Lon: -46.70},
+			testhelper.GeoCoord{Lat: -23.50, Lon: -46.60},
+			testhelper.GeoCoord{Lat: -23.60, Lon: -46.60},
+			testhelper.GeoCoord{Lat: -23.60, Lon: -46.70},
+		),
+	})
+
+	inside := testhelper.GeoEnvelope(testhelper.GeoCoord{Lat: -23.55, Lon: -46.66}, testhelper.GeoCoord{Lat: -23.56, Lon: -46.65})
+	outside := testhelper.GeoEnvelope(testhelper.GeoCoord{Lat: -22.90, Lon: -47.07}, testhelper.GeoCoord{Lat: -22.91, Lon: -47.06})
+
+	assert.Equal(t, []string{"centro"}, suite.SearchGeoShape(index, "area", inside, "intersects").IDs())
+	assert.Empty(t, suite.SearchGeoShape(index, "area", outside, "").IDs())
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/viniciussantos/claude-testcontainers/internal/repository"
+)
+
+// Métodos do ProductServiceInterface, usados para configurar falhas por método
+const (
+	MethodCreateProduct         = "CreateProduct"
+	MethodGetProductByID        = "GetProductByID"
+	MethodGetProductsByCategory = "GetProductsByCategory"
+	MethodGetExpensiveProducts  = "GetExpensiveProducts"
+)
+
+// ErrInjectedFault é o erro padrão das falhas injetadas
+var ErrInjectedFault = errors.New("injected fault")
+
+var _ ProductServiceInterface = (*FaultyProductService)(nil)
+
+// FaultyProductService decora um ProductServiceInterface injetando falhas controladas pelo teste
+// (erro na N-ésima chamada, erro em todas as chamadas, latência aleatória), para testar retry e timeout
+// de quem consome o serviço sem depender de falhas reais do repositório
+type FaultyProductService struct {
+	next ProductServiceInterface
+
+	mu       sync.Mutex
+	faults   map[string][]fault
+	latency  map[string]latencyFault
+	calls    map[string]int
+	injected map[string]int
+	rand     *rand.Rand
+}
+
+// fault é um erro a injetar: na chamada OnCall (1-based) ou, com OnCall 0, em todas
+type fault struct {
+	onCall int
+	err    error
+}
+
+// latencyFault é a latência aleatória entre min e max aplicada antes da chamada
+type latencyFault struct {
+	min time.Duration
+	max time.Duration
+}
+
+// NewFaultyProductService cria o decorator sobre o serviço real (ou qualquer implementação da interface)
+func NewFaultyProductService(next ProductServiceInterface) *FaultyProductService {
+	return &FaultyProductService{
+		next:     next,
+		faults:   make(map[string][]fault),
+		latency:  make(map[string]latencyFault),
+		calls:    make(map[string]int),
+		injected: make(map[string]int),
+		rand:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// FailOnCall faz a n-ésima chamada (1-based) do método retornar err (nil = ErrInjectedFault)
+// Pode ser chamado várias vezes para falhar em chamadas diferentes
+func (f *FaultyProductService) FailOnCall(method string, n int, err error) *FaultyProductService {
+	return f.addFault(method, n, err)
+}
+
+// FailFirst faz as n primeiras chamadas do método falharem, cenário típico de retry que se recupera
+func (f *FaultyProductService) FailFirst(method string, n int, err error) *FaultyProductService {
+	for call := 1; call <= n; call++ {
+		f.addFault(method, call, err)
+	}
+	return f
+}
+
+// FailAlways faz todas as chamadas do método falharem
+func (f *FaultyProductService) FailAlways(method string, err error) *FaultyProductService {
+	return f.addFault(method, 0, err)
+}
+
+// WithLatency atrasa cada chamada do método por uma duração aleatória entre min e max
+// A espera respeita o contexto: com o prazo estourado a chamada retorna ctx.Err() sem chegar ao serviço
+func (f *FaultyProductService) WithLatency(method string, min, max time.Duration) *FaultyProductService {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if max < min {
+		max = min
+	}
+	f.latency[method] = latencyFault{min: min, max: max}
+	return f
+}
+
+// Reset remove as falhas configuradas e zera os contadores
+func (f *FaultyProductService) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.faults = make(map[string][]fault)
+	f.latency = make(map[string]latencyFault)
+	f.calls = make(map[string]int)
+	f.injected = make(map[string]int)
+}
+
+// Calls retorna quantas chamadas o método recebeu (incluindo as que falharam)
+func (f *FaultyProductService) Calls(method string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[method]
+}
+
+// Injected retorna quantas falhas (erros ou timeouts durante a latência) foram injetadas no método
+func (f *FaultyProductService) Injected(method string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.injected[method]
+}
+
+func (f *FaultyProductService) CreateProduct(ctx context.Context, product *repository.Product) error {
+	if err := f.before(ctx, MethodCreateProduct); err != nil {
+		return err
+	}
+	return f.next.CreateProduct(ctx, product)
+}
+
+func (f *FaultyProductService) GetProductByID(ctx context.Context, id string, tenantID string) (*repository.Product, error) {
+	if err := f.before(ctx, MethodGetProductByID); err != nil {
+		return nil, err
+	}
+	return f.next.GetProductByID(ctx, id, tenantID)
+}
+
+func (f *FaultyProductService) GetProductsByCategory(ctx context.Context, category string, tenantID string) ([]*repository.Product, error) {
+	if err := f.before(ctx, MethodGetProductsByCategory); err != nil {
+		return nil, err
+	}
+	return f.next.GetProductsByCategory(ctx, category, tenantID)
+}
+
+func (f *FaultyProductService) GetExpensiveProducts(ctx context.Context, minPrice float64, tenantID string) ([]*repository.Product, error) {
+	if err := f.before(ctx, MethodGetExpensiveProducts); err != nil {
+		return nil, err
+	}
+	return f.next.GetExpensiveProducts(ctx, minPrice, tenantID)
+}
+
+// addFault registra um erro para a chamada n do método (0 = todas)
+func (f *FaultyProductService) addFault(method string, n int, err error) *FaultyProductService {
+	if err == nil {
+		err = ErrInjectedFault
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.faults[method] = append(f.faults[method], fault{onCall: n, err: err})
+	return f
+}
+
+// before conta a chamada, aplica a latência e retorna o erro injetado, se houver
+func (f *FaultyProductService) before(ctx context.Context, method string) error {
+	f.mu.Lock()
+	f.calls[method]++
+	call := f.calls[method]
+
+	var delay time.Duration
+	if l, ok := f.latency[method]; ok {
+		delay = l.min
+		if l.max > l.min {
+			delay += time.Duration(f.rand.Int63n(int64(l.max - l.min)))
+		}
+	}
+
+	var injected error
+	for _, fl := range f.faults[method] {
+		if fl.onCall == 0 || fl.onCall == call {
+			injected = fl.err
+			break
+		}
+	}
+	f.mu.Unlock()
+
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			f.countInjected(method)
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	if injected != nil {
+		f.countInjected(method)
+	}
+	return injected
+}
+
+// countInjected incrementa o contador de falhas injetadas do método
+func (f *FaultyProductService) countInjected(method string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.injected[method]++
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/viniciussantos/claude-testcontainers/internal/repository"
+	"github.com/viniciussantos/claude-testcontainers/internal/repository/mocks"
+	"github.com/viniciussantos/claude-testcontainers/test/testhelper"
+)
+
+// getWithRetry é um consumidor de exemplo do serviço: tenta até attempts vezes, respeitando o contexto
+func getWithRetry(ctx context.Context, svc ProductServiceInterface, id, tenantID string, attempts int) (*repository.Product, error) {
+	var lastErr error
+	for attempt := 0; attempt < attempts; attempt++ {
+		product, err := svc.GetProductByID(ctx, id, tenantID)
+		if err == nil {
+			return product, nil
+		}
+		lastErr = err
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+	return nil, lastErr
+}
+
+// EXEMPLO DE TESTE DE RETRY/TIMEOUT COM INJEÇÃO DE FALHAS (sem containers)
+func TestFaultyProductService(t *testing.T) {
+	suite := testhelper.NewIntegrationTestSuite(t)
+	ctx := context.Background()
+
+	newService := func() *FaultyProductService {
+		repo := &mocks.ProductRepositoryInterfaceMock{
+			GetByIDFunc: func(ctx context.Context, id string, tenantID string) (*repository.Product, error) {
+				return &repository.Product{ID: id, TenantID: tenantID}, nil
+			},
+		}
+		return NewFaultyProductService(NewProductService(repo))
+	}
+
+	t.Run("Retry recovers from transient faults", func(t *testing.T) {
+		svc := newService().FailFirst(MethodGetProductByID, 2, nil)
+
+		product, err := getWithRetry(ctx, svc, "p1", "tenant_a", 3)
+		suite.AssertRecoveredFromFault(svc, MethodGetProductByID, err)
+		assert.Equal(t, "p1", product.ID)
+		assert.Equal(t, 3, svc.Calls(MethodGetProductByID))
+	})
+
+	t.Run("Persistent fault is surfaced after bounded retries", func(t *testing.T) {
+		svc := newService().FailAlways(MethodGetProductByID, nil)
+
+		_, err := getWithRetry(ctx, svc, "p1", "tenant_a", 3)
+		suite.AssertFaultSurfaced(svc, MethodGetProductByID, err, ErrInjectedFault, 3)
+	})
+
+	t.Run("Fault on the Nth call only", func(t *testing.T) {
+		svc := newService().FailOnCall(MethodGetProductByID, 2, nil)
+
+		_, err := svc.GetProductByID(ctx, "p1", "tenant_a")
+		require.NoError(t, err)
+		_, err = svc.GetProductByID(ctx, "p1", "tenant_a")
+		assert.ErrorIs(t, err, ErrInjectedFault)
+		_, err = svc.GetProductByID(ctx, "p1", "tenant_a")
+		require.NoError(t, err)
+		assert.Equal(t, 1, svc.Injected(MethodGetProductByID))
+	})
+
+	t.Run("Slow dependency is cut by the caller timeout", func(t *testing.T) {
+		svc := newService().WithLatency(MethodGetProductByID, time.Second, 2*time.Second)
+
+		suite.AssertRespectsTimeout(50*time.Millisecond, func(ctx context.Context) error {
+			_, err := getWithRetry(ctx, svc, "p1", "tenant_a", 3)
+			return err
+		})
+		assert.Equal(t, 1, svc.Calls(MethodGetProductByID))
+	})
+
+	t.Run("Reset clears faults and counters", func(t *testing.T) {
+		svc := newService().FailAlways(MethodCreateProduct, nil)
+		svc.Reset()
+
+		_, err := svc.GetProductByID(ctx, "p1", "tenant_a")
+		require.NoError(t, err)
+		assert.Zero(t, svc.Injected(MethodCreateProduct))
+		assert.Equal(t, 1, svc.Calls(MethodGetProductByID))
+	})
+}
@@ -7,11 +7,26 @@ import (
 	"github.com/viniciussantos/claude-testcontainers/internal/repository"
 )
 
+//go:generate moq -out mocks/product_service_mock.go -pkg mocks . ProductServiceInterface
+
+// ProductServiceInterface é o contrato do serviço de produtos, para consumidores testarem
+// a própria lógica com o mock (internal/service/mocks) em vez de containers
+type ProductServiceInterface interface {
+	CreateProduct(ctx context.Context, product *repository.Product) error
+	GetProductByID(ctx context.Context, id string, tenantID string) (*repository.Product, error)
+	GetProductsByCategory(ctx context.Context, category string, tenantID string) ([]*repository.Product, error)
+	GetExpensiveProducts(ctx context.Context, minPrice float64, tenantID string) ([]*repository.Product, error)
+}
+
+var _ ProductServiceInterface = (*ProductService)(nil)
+
 type ProductService struct {
-	repo *repository.ProductRepository
+	repo repository.ProductRepositoryInterface
 }
 
-func NewProductService(repo *repository.ProductRepository) *ProductService {
+// NewProductService cria o serviço sobre qualquer implementação do repositório
+// (Elasticsearch, fake em memória ou o mock de internal/repository/mocks)
+func NewProductService(repo repository.ProductRepositoryInterface) *ProductService {
 	return &ProductService{
 		repo: repo,
 	}
@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/viniciussantos/claude-testcontainers/internal/repository"
+	"github.com/viniciussantos/claude-testcontainers/internal/repository/mocks"
 	"github.com/viniciussantos/claude-testcontainers/test/testhelper"
 )
 
@@ -29,7 +30,7 @@ func TestProductService(t *testing.T) {
 	defer suite.Teardown()
 	
 	// Setup da cadeia de dependências
-	repo := repository.NewProductRepository(suite.ES())
+	repo := repository.NewProductRepositoryWithIndex(suite.ES(), suite.IndexName(repository.ProductIndex))
 	service := NewProductService(repo)
 	ctx := context.Background()
 	
@@ -170,7 +171,7 @@ func TestProductService_IntegratedWorkflow(t *testing.T) {
 	suite.Setup()
 	defer suite.Teardown()
 	
-	repo := repository.NewProductRepository(suite.ES())
+	repo := repository.NewProductRepositoryWithIndex(suite.ES(), suite.IndexName(repository.ProductIndex))
 	service := NewProductService(repo)
 	ctx := context.Background()
 	
@@ -237,13 +238,67 @@ func TestProductService_IntegratedWorkflow(t *testing.T) {
 	})
 }
 
+// EXEMPLO DE TESTE UNITÁRIO COM MOCK DO REPOSITÓRIO (sem containers)
+func TestProductService_WithMockRepository(t *testing.T) {
+	ctx := context.Background()
+	
+	t.Run("Validation does not reach the repository", func(t *testing.T) {
+		repo := &mocks.ProductRepositoryInterfaceMock{}
+		service := NewProductService(repo)
+		
+		err := service.CreateProduct(ctx, &repository.Product{ID: "p1", Price: 10})
+		assert.ErrorContains(t, err, "name is required")
+		
+		_, err = service.GetProductByID(ctx, "p1", "")
+		assert.ErrorContains(t, err, "tenant ID is required")
+		
+		assert.Empty(t, repo.CreateCalls())
+		assert.Empty(t, repo.GetByIDCalls())
+	})
+	
+	t.Run("Expensive products are filtered from electronics", func(t *testing.T) {
+		repo := &mocks.ProductRepositoryInterfaceMock{
+			SearchByCategoryFunc: func(ctx context.Context, category string, tenantID string) ([]*repository.Product, error) {
+				return []*repository.Product{
+					{ID: "cheap", Price: 99.99, Category: category, TenantID: tenantID},
+					{ID: "laptop", Price: 2399.99, Category: category, TenantID: tenantID},
+				}, nil
+			},
+		}
+		service := NewProductService(repo)
+		
+		expensive, err := service.GetExpensiveProducts(ctx, 500.0, "tenant_a")
+		require.NoError(t, err)
+		require.Len(t, expensive, 1)
+		assert.Equal(t, "laptop", expensive[0].ID)
+		
+		calls := repo.SearchByCategoryCalls()
+		require.Len(t, calls, 1)
+		assert.Equal(t, "electronics", calls[0].Category)
+		assert.Equal(t, "tenant_a", calls[0].TenantID)
+	})
+	
+	t.Run("Repository errors are propagated", func(t *testing.T) {
+		repo := &mocks.ProductRepositoryInterfaceMock{
+			CreateFunc: func(ctx context.Context, product *repository.Product) error {
+				return fmt.Errorf("index unavailable")
+			},
+		}
+		service := NewProductService(repo)
+		
+		err := service.CreateProduct(ctx, &repository.Product{ID: "p1", Name: "Product", Price: 10})
+		assert.ErrorContains(t, err, "index unavailable")
+		assert.Len(t, repo.CreateCalls(), 1)
+	})
+}
+
 // EXEMPLO DE BENCHMARK COMPARATIVO
 func BenchmarkProductService_CreateAndSearch(b *testing.B) {
 	suite := testhelper.NewIntegrationTestSuite(&testing.T{})
 	suite.Setup()
 	defer suite.Teardown()
 	
-	repo := repository.NewProductRepository(suite.ES())
+	repo := repository.NewProductRepositoryWithIndex(suite.ES(), suite.IndexName(repository.ProductIndex))
 	service := NewProductService(repo)
 	ctx := context.Background()
 	
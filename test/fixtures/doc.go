@@ -0,0 +1,5 @@
+// Package fixtures contém os modelos de teste gerados a partir dos mappings do Elasticsearch
+// Importe com _ (ou use os tipos diretamente) para registrá-los no fixture loader do testhelper
+package fixtures
+
+//go:generate go run ../../cmd/esgen -mapping mappings/products.json -out products_gen.go
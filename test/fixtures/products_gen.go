@@ -0,0 +1,24 @@
+// Code generated by esgen from mappings/products.json; DO NOT EDIT.
+
+package fixtures
+
+import (
+	"github.com/viniciussantos/claude-testcontainers/test/testhelper"
+)
+
+// ProductIndex é o índice cujo mapping originou Product
+const ProductIndex = "products"
+
+// Product representa um documento do índice products
+type Product struct {
+	Category    string  `json:"category"`
+	Description string  `json:"description"`
+	ID          string  `json:"id"`
+	Name        string  `json:"name"`
+	Price       float64 `json:"price"`
+	TenantID    string  `json:"tenant_id"`
+}
+
+func init() {
+	testhelper.RegisterFixtureType(ProductIndex, func() interface{} { return &Product{} })
+}
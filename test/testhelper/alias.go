@@ -0,0 +1,113 @@
+package testhelper
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"github.com/stretchr/testify/require"
+)
+
+// CreateAlias aponta o alias para os índices e retorna o nome real do alias
+// Com mais de um índice, o primeiro é marcado como write index (escritas pelo alias vão para ele)
+// O alias é removido ao final do teste (os índices permanecem)
+func (s *IntegrationTestSuite) CreateAlias(alias string, indices ...string) string {
+	s.t.Helper()
+	defer s.trackOperation("CreateAlias")()
+
+	client := s.ES()
+	require.NotNil(s.t, client, "Elasticsearch not configured")
+	require.NotEmpty(s.t, indices, "CreateAlias requires at least one index")
+
+	alias = s.RunScopedIndex(alias)
+	scoped := make([]string, len(indices))
+	actions := make([]map[string]interface{}, len(indices))
+	for i, index := range indices {
+		scoped[i] = s.RunScopedIndex(index)
+		add := map[string]interface{}{"index": scoped[i], "alias": alias}
+		if len(indices) > 1 {
+			add["is_write_index"] = i == 0
+		}
+		actions[i] = map[string]interface{}{"add": add}
+	}
+	s.updateAliases(actions...)
+
+	s.t.Cleanup(func() {
+		res, err := client.Indices.DeleteAlias(scoped, []string{alias},
+			client.Indices.DeleteAlias.WithContext(s.ctx))
+		if err == nil {
+			res.Body.Close()
+		}
+	})
+
+	return alias
+}
+
+// AliasIndices retorna os índices para os quais o alias aponta (ordenados) e o write index, se houver
+// Alias inexistente retorna lista vazia
+func (s *IntegrationTestSuite) AliasIndices(alias string) (indices []string, writeIndex string) {
+	s.t.Helper()
+
+	client := s.ES()
+	require.NotNil(s.t, client, "Elasticsearch not configured")
+
+	alias = s.RunScopedIndex(alias)
+
+	res, err := esapi.IndicesGetAliasRequest{Name: []string{alias}}.Do(s.ctx, client)
+	require.NoError(s.t, err, "Failed to get alias %s", alias)
+	defer res.Body.Close()
+
+	if res.StatusCode == 404 {
+		return nil, ""
+	}
+	if res.IsError() {
+		require.Fail(s.t, fmt.Sprintf("Failed to get alias %s: %s", alias, res.Status()))
+	}
+
+	var body map[string]struct {
+		Aliases map[string]struct {
+			IsWriteIndex *bool `json:"is_write_index"`
+		} `json:"aliases"`
+	}
+	require.NoError(s.t, json.NewDecoder(res.Body).Decode(&body), "Failed to decode alias response")
+
+	for index, entry := range body {
+		def, ok := entry.Aliases[alias]
+		if !ok {
+			continue
+		}
+		indices = append(indices, index)
+		if def.IsWriteIndex != nil && *def.IsWriteIndex {
+			writeIndex = index
+		}
+	}
+	sort.Strings(indices)
+
+	// Alias de um único índice sem is_write_index explícito escreve nesse índice
+	if writeIndex == "" && len(indices) == 1 {
+		writeIndex = indices[0]
+	}
+	return indices, writeIndex
+}
+
+// AssertAliasPointsTo verifica que o alias aponta exatamente para os índices informados (em qualquer ordem)
+func (s *IntegrationTestSuite) AssertAliasPointsTo(alias string, indices ...string) {
+	s.t.Helper()
+
+	expected := make([]string, len(indices))
+	for i, index := range indices {
+		expected[i] = s.RunScopedIndex(index)
+	}
+
+	actual, _ := s.AliasIndices(alias)
+	require.ElementsMatch(s.t, expected, actual, "Alias %s points to unexpected indices", alias)
+}
+
+// AssertWriteIndex verifica que as escritas pelo alias vão para o índice informado
+func (s *IntegrationTestSuite) AssertWriteIndex(alias, index string) {
+	s.t.Helper()
+
+	_, writeIndex := s.AliasIndices(alias)
+	require.Equal(s.t, s.RunScopedIndex(index), writeIndex, "Unexpected write index for alias %s", alias)
+}
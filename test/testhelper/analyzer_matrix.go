@@ -0,0 +1,266 @@
+package testhelper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"github.com/stretchr/testify/require"
+)
+
+// AnalyzerVariant é uma configuração de analyzer a comparar na matriz
+// Analyzer é o nome de um analyzer built-in (ex.: "brazilian") ou de um definido em Analysis
+type AnalyzerVariant struct {
+	Name     string
+	Analyzer string
+	Analysis map[string]interface{} // settings "analysis" opcionais (analyzers/filters customizados)
+}
+
+// Variantes com os analyzers de idioma built-in do Elasticsearch
+var (
+	AnalyzerPortuguese = AnalyzerVariant{Name: "pt_BR", Analyzer: "brazilian"}
+	AnalyzerEnglish    = AnalyzerVariant{Name: "en", Analyzer: "english"}
+	AnalyzerSpanish    = AnalyzerVariant{Name: "es", Analyzer: "spanish"}
+)
+
+// RelevanceQuery é uma consulta do conjunto fixo com os _id considerados relevantes
+type RelevanceQuery struct {
+	Name     string
+	Text     string
+	Relevant []string
+}
+
+// AnalyzerMetrics é o resultado de uma consulta (ou a média, com Query vazio) em uma variante
+type AnalyzerMetrics struct {
+	Variant   string
+	Query     string
+	Precision float64
+	Recall    float64
+	Retrieved []string
+}
+
+// AnalyzerMatrix mantém o mesmo índice criado uma vez por variante de analyzer, com o mesmo corpus
+type AnalyzerMatrix struct {
+	suite    *IntegrationTestSuite
+	fields   []string
+	variants []AnalyzerVariant
+	indices  map[string]string
+}
+
+// NewAnalyzerMatrix cria um índice por variante ("<base>_<variante>-<tenant>") com os campos de texto
+// usando o analyzer da variante; os índices são removidos ao final do teste
+func (s *IntegrationTestSuite) NewAnalyzerMatrix(base string, textFields []string, variants ...AnalyzerVariant) *AnalyzerMatrix {
+	s.t.Helper()
+
+	require.NotEmpty(s.t, textFields, "Analyzer matrix needs at least one text field")
+	require.NotEmpty(s.t, variants, "Analyzer matrix needs at least one variant")
+
+	m := &AnalyzerMatrix{
+		suite:    s,
+		fields:   textFields,
+		variants: variants,
+		indices:  make(map[string]string, len(variants)),
+	}
+
+	for _, variant := range variants {
+		index := s.RunScopedIndex(TenantIndexName(base+"_"+variant.Name, s.tenantID))
+		s.createAnalyzerIndex(index, textFields, variant)
+		m.indices[variant.Name] = index
+	}
+
+	return m
+}
+
+// createAnalyzerIndex cria o índice da variante e agenda a remoção ao final do teste
+func (s *IntegrationTestSuite) createAnalyzerIndex(index string, textFields []string, variant AnalyzerVariant) {
+	s.t.Helper()
+
+	properties := make(map[string]interface{}, len(textFields))
+	for _, field := range textFields {
+		properties[field] = map[string]interface{}{"type": "text", "analyzer": variant.Analyzer}
+	}
+
+	body := map[string]interface{}{
+		"mappings": map[string]interface{}{"properties": properties},
+	}
+	if variant.Analysis != nil {
+		body["settings"] = map[string]interface{}{"analysis": variant.Analysis}
+	}
+
+	raw, err := json.Marshal(body)
+	require.NoError(s.t, err, "Failed to marshal analyzer index %s", index)
+
+	res, err := esapi.IndicesCreateRequest{Index: index, Body: bytes.NewReader(raw)}.Do(s.ctx, s.ES())
+	require.NoError(s.t, err, "Failed to create analyzer index %s", index)
+	defer res.Body.Close()
+
+	if res.IsError() {
+		require.Fail(s.t, fmt.Sprintf("Failed to create analyzer index %s (%s): %s", index, variant.Name, res.String()))
+	}
+
+	client := s.ES()
+	s.t.Cleanup(func() {
+		res, err := client.Indices.Delete([]string{index}, client.Indices.Delete.WithIgnoreUnavailable(true))
+		if err == nil {
+			res.Body.Close()
+		}
+	})
+}
+
+// Index retorna o nome do índice da variante
+func (m *AnalyzerMatrix) Index(variant string) string {
+	return m.indices[variant]
+}
+
+// IndexCorpus indexa o mesmo corpus (_id -> documento) em todas as variantes e faz refresh
+func (m *AnalyzerMatrix) IndexCorpus(docs map[string]interface{}) {
+	s := m.suite
+	s.t.Helper()
+	defer s.trackOperation("IndexCorpus")()
+
+	ids := make([]string, 0, len(docs))
+	for id := range docs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, variant := range m.variants {
+		index := m.indices[variant.Name]
+
+		var body bytes.Buffer
+		for _, id := range ids {
+			meta, _ := json.Marshal(map[string]interface{}{"index": map[string]interface{}{"_index": index, "_id": id}})
+			doc, err := json.Marshal(docs[id])
+			require.NoError(s.t, err, "Failed to marshal document %s", id)
+			body.Write(meta)
+			body.WriteByte('\n')
+			body.Write(doc)
+			body.WriteByte('\n')
+		}
+
+		res, err := esapi.BulkRequest{Body: &body, Refresh: "true"}.Do(s.ctx, s.ES())
+		require.NoError(s.t, err, "Failed to index corpus into %s", index)
+
+		var response struct {
+			Errors bool `json:"errors"`
+		}
+		err = json.NewDecoder(res.Body).Decode(&response)
+		res.Body.Close()
+		require.NoError(s.t, err, "Failed to decode bulk response of %s", index)
+		require.False(s.t, res.IsError() || response.Errors, "Failed to index corpus into %s", index)
+	}
+}
+
+// Evaluate executa cada consulta (multi_match nos campos de texto, top k) em cada variante e
+// calcula precision@k e recall em relação aos _id relevantes
+func (m *AnalyzerMatrix) Evaluate(queries []RelevanceQuery, k int) []AnalyzerMetrics {
+	s := m.suite
+	s.t.Helper()
+
+	results := make([]AnalyzerMetrics, 0, len(queries)*len(m.variants))
+	for _, variant := range m.variants {
+		for _, query := range queries {
+			retrieved := s.SearchDocuments(m.indices[variant.Name], map[string]interface{}{
+				"size": k,
+				"query": map[string]interface{}{
+					"multi_match": map[string]interface{}{
+						"query":  query.Text,
+						"fields": m.fields,
+					},
+				},
+			}).IDs()
+
+			precision, recall := relevanceScores(retrieved, query.Relevant)
+			results = append(results, AnalyzerMetrics{
+				Variant:   variant.Name,
+				Query:     query.Name,
+				Precision: precision,
+				Recall:    recall,
+				Retrieved: retrieved,
+			})
+		}
+	}
+	return results
+}
+
+// Summary calcula a média de precision e recall de cada variante
+func (m *AnalyzerMatrix) Summary(results []AnalyzerMetrics) map[string]AnalyzerMetrics {
+	totals := make(map[string]AnalyzerMetrics, len(m.variants))
+	counts := make(map[string]int, len(m.variants))
+	for _, r := range results {
+		total := totals[r.Variant]
+		total.Variant = r.Variant
+		total.Precision += r.Precision
+		total.Recall += r.Recall
+		totals[r.Variant] = total
+		counts[r.Variant]++
+	}
+
+	for variant, total := range totals {
+		total.Precision /= float64(counts[variant])
+		total.Recall /= float64(counts[variant])
+		totals[variant] = total
+	}
+	return totals
+}
+
+// Report registra no log do teste a tabela consulta x variante e as médias, para comparar experimentos
+func (m *AnalyzerMatrix) Report(results []AnalyzerMetrics) {
+	s := m.suite
+	s.t.Helper()
+
+	var out strings.Builder
+	w := tabwriter.NewWriter(&out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "variant\tquery\tprecision\trecall\tretrieved")
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%s\t%.2f\t%.2f\t%s\n", r.Variant, r.Query, r.Precision, r.Recall, strings.Join(r.Retrieved, ","))
+	}
+
+	summary := m.Summary(results)
+	for _, variant := range m.variants {
+		avg := summary[variant.Name]
+		fmt.Fprintf(w, "%s\t(avg)\t%.2f\t%.2f\t\n", variant.Name, avg.Precision, avg.Recall)
+	}
+	w.Flush()
+
+	s.t.Logf("Analyzer matrix:\n%s", out.String())
+}
+
+// AssertRecallAtLeast verifica que a recall média da variante atinge o mínimo
+func (m *AnalyzerMatrix) AssertRecallAtLeast(results []AnalyzerMetrics, variant string, min float64) {
+	m.suite.t.Helper()
+
+	avg, ok := m.Summary(results)[variant]
+	require.True(m.suite.t, ok, "Unknown analyzer variant %s", variant)
+	require.GreaterOrEqual(m.suite.t, avg.Recall, min, "Average recall of %s below %.2f", variant, min)
+}
+
+// relevanceScores calcula precision (relevantes recuperados / recuperados) e recall
+// (relevantes recuperados / relevantes); sem relevantes, recall é 1 se nada foi recuperado
+func relevanceScores(retrieved, relevant []string) (precision, recall float64) {
+	relevantSet := make(map[string]bool, len(relevant))
+	for _, id := range relevant {
+		relevantSet[id] = true
+	}
+
+	hits := 0
+	for _, id := range retrieved {
+		if relevantSet[id] {
+			hits++
+		}
+	}
+
+	if len(retrieved) > 0 {
+		precision = float64(hits) / float64(len(retrieved))
+	}
+	if len(relevant) > 0 {
+		recall = float64(hits) / float64(len(relevant))
+	} else if len(retrieved) == 0 {
+		precision, recall = 1, 1
+	}
+	return precision, recall
+}
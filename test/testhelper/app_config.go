@@ -0,0 +1,202 @@
+package testhelper
+
+import (
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// AppConfig reúne os endereços das dependências da suite no formato esperado pela aplicação,
+// para passar ao construtor real ou executar o binário da aplicação (processo ou container)
+// Campos vazios indicam dependências não configuradas no builder
+type AppConfig struct {
+	ElasticsearchURL      string
+	ElasticsearchUsername string // credenciais e CA (PEM) apenas com segurança habilitada
+	ElasticsearchPassword string
+	ElasticsearchCACert   string
+	PostgresDSN           string
+	MongoURI              string
+	MongoDatabase         string
+	MemcachedAddr         string
+	TenantID              string
+}
+
+// AppConfig deriva a configuração da aplicação a partir das dependências da suite
+func (s *IntegrationTestSuite) AppConfig() AppConfig {
+	cfg := AppConfig{TenantID: s.tenantID}
+
+	if s.ES() != nil {
+		if s.builder != nil {
+			cfg.ElasticsearchURL = s.builder.GetElasticsearchURL()
+		}
+		if cfg.ElasticsearchURL == "" && s.sharedES != nil {
+			cfg.ElasticsearchURL = s.sharedES.GetURL()
+		}
+		if s.sharedES != nil {
+			cfg.ElasticsearchUsername, cfg.ElasticsearchPassword = s.sharedES.Credentials()
+			cfg.ElasticsearchCACert = string(s.sharedES.CACert())
+		}
+	}
+
+	if s.Postgres() != nil {
+		if s.builder != nil {
+			cfg.PostgresDSN = s.builder.GetPostgresURL()
+		}
+		if cfg.PostgresDSN == "" && s.sharedPG != nil {
+			cfg.PostgresDSN = s.sharedPG.GetURL()
+		}
+	}
+
+	if db := s.Mongo(); db != nil {
+		cfg.MongoDatabase = db.Name()
+		if s.builder != nil {
+			cfg.MongoURI = s.builder.GetMongoURL()
+		}
+		if cfg.MongoURI == "" && s.sharedMongo != nil {
+			cfg.MongoURI = s.sharedMongo.GetURL()
+		}
+	}
+
+	if s.builder != nil {
+		cfg.MemcachedAddr = s.builder.MemcachedAddr
+	}
+
+	return cfg
+}
+
+// Env retorna as variáveis de ambiente da aplicação (mesmos nomes usados pelos modos externos)
+// Apenas dependências configuradas entram no mapa
+func (c AppConfig) Env() map[string]string {
+	env := make(map[string]string)
+	set := func(key, value string) {
+		if value != "" {
+			env[key] = value
+		}
+	}
+
+	set("ES_URL", c.ElasticsearchURL)
+	set("ES_USERNAME", c.ElasticsearchUsername)
+	set("ES_PASSWORD", c.ElasticsearchPassword)
+	set("ES_CA_CERT", c.ElasticsearchCACert)
+	set("PG_URL", c.PostgresDSN)
+	set("MONGO_URL", c.MongoURI)
+	set("MONGO_DATABASE", c.MongoDatabase)
+	set("MEMCACHED_ADDR", c.MemcachedAddr)
+	set("TENANT_ID", c.TenantID)
+	return env
+}
+
+// Environ retorna o Env no formato KEY=VALUE ordenado, para exec.Cmd.Env (junto com os.Environ())
+func (c AppConfig) Environ() []string {
+	env := c.Env()
+	environ := make([]string, 0, len(env))
+	for key, value := range env {
+		environ = append(environ, key+"="+value)
+	}
+	sort.Strings(environ)
+	return environ
+}
+
+// ForContainer reescreve os endereços locais (localhost/127.0.0.1) para o host visto de dentro de um
+// container (host.testcontainers.internal) e retorna as portas do host que precisam ser expostas
+func (c AppConfig) ForContainer() (AppConfig, []int) {
+	var ports []int
+	addPort := func(port string) {
+		if p, err := strconv.Atoi(port); err == nil {
+			ports = append(ports, p)
+		}
+	}
+
+	c.ElasticsearchURL = rewriteURLHost(c.ElasticsearchURL, addPort)
+	c.MongoURI = rewriteURLHost(c.MongoURI, addPort)
+	c.PostgresDSN = rewriteDSNHost(c.PostgresDSN, addPort)
+	c.MemcachedAddr = rewriteAddrHost(c.MemcachedAddr, addPort)
+
+	sort.Ints(ports)
+	return c, ports
+}
+
+// AppContainerRequest completa o request do container da aplicação com o Env de ForContainer e as
+// portas das dependências em HostAccessPorts, permitindo rodar o binário como teste end-to-end
+func (s *IntegrationTestSuite) AppContainerRequest(req testcontainers.ContainerRequest) testcontainers.ContainerRequest {
+	cfg, ports := s.AppConfig().ForContainer()
+
+	env := make(map[string]string, len(req.Env))
+	for key, value := range cfg.Env() {
+		env[key] = value
+	}
+	// Variáveis definidas explicitamente no request têm precedência
+	for key, value := range req.Env {
+		env[key] = value
+	}
+	req.Env = env
+	req.HostAccessPorts = append(req.HostAccessPorts, ports...)
+	return req
+}
+
+// isLocalHost indica se o host só é acessível a partir da máquina que roda os testes
+func isLocalHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// rewriteURLHost troca o host local de uma URL (http://, mongodb://) por testcontainers.HostInternal
+func rewriteURLHost(raw string, addPort func(string)) string {
+	u, err := url.Parse(raw)
+	if err != nil || !isLocalHost(u.Hostname()) {
+		return raw
+	}
+
+	port := u.Port()
+	addPort(port)
+	if port == "" {
+		u.Host = testcontainers.HostInternal
+	} else {
+		u.Host = net.JoinHostPort(testcontainers.HostInternal, port)
+	}
+	return u.String()
+}
+
+// rewriteDSNHost troca o host local de uma DSN key=value (host=... port=...) do PostgreSQL
+func rewriteDSNHost(dsn string, addPort func(string)) string {
+	if strings.Contains(dsn, "://") {
+		return rewriteURLHost(dsn, addPort)
+	}
+
+	fields := strings.Fields(dsn)
+	local := false
+	for i, field := range fields {
+		if host, ok := strings.CutPrefix(field, "host="); ok && isLocalHost(host) {
+			fields[i] = "host=" + testcontainers.HostInternal
+			local = true
+		}
+	}
+	if !local {
+		return dsn
+	}
+
+	for _, field := range fields {
+		if port, ok := strings.CutPrefix(field, "port="); ok {
+			addPort(port)
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+// rewriteAddrHost troca o host local de um endereço host:porta
+func rewriteAddrHost(addr string, addPort func(string)) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || !isLocalHost(host) {
+		return addr
+	}
+
+	addPort(port)
+	return net.JoinHostPort(testcontainers.HostInternal, port)
+}
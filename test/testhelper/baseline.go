@@ -0,0 +1,273 @@
+package testhelper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"github.com/stretchr/testify/require"
+)
+
+// Baseline compartilhada entre pacotes
+//
+// Dados de referência idênticos (categorias, tabelas de preço, catálogos) eram semeados de novo por cada
+// pacote do monorepo. EnsureBaseline semeia o conjunto uma vez nos containers compartilhados e registra um
+// marcador (nome + versão) no próprio Elasticsearch/PostgreSQL; os pacotes seguintes, em outros processos,
+// encontram o marcador e reutilizam os dados. Um lock de arquivo garante que só um processo semeia
+
+const (
+	// BaselineIndexPrefix é o prefixo dos índices de baseline, preservados por CleanElasticsearch
+	BaselineIndexPrefix = "baseline-"
+	// BaselineTablePrefix é o prefixo das tabelas de baseline, preservadas por CleanPostgres
+	BaselineTablePrefix = "baseline_"
+
+	baselineMarkerIndex = BaselineIndexPrefix + "testhelper-markers"
+	baselineMarkerTable = BaselineTablePrefix + "testhelper_markers"
+
+	// baselineLockHeartbeat é o intervalo em que o dono do lock atualiza o arquivo; um lock sem
+	// atualização por baselineLockStale é de um processo encerrado no meio da semeadura
+	baselineLockHeartbeat = 2 * time.Second
+	baselineLockStale     = 15 * time.Second
+)
+
+var (
+	// baselinesReady guarda as baselines (nome@versão) já verificadas neste processo
+	baselinesReady sync.Map
+
+	baselineNameInvalid = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+)
+
+// EnsureBaseline garante que a baseline name na versão informada está semeada nos containers
+// compartilhados, executando seed apenas se nenhum processo a semeou antes (ou se a versão mudou)
+//
+// A baseline é somente leitura: seed deve gravar em índices com BaselineIndexPrefix e tabelas com
+// BaselineTablePrefix (preservados pelas limpezas) e ser idempotente, pois uma semeadura interrompida é
+// refeita por inteiro. Escritas dos testes continuam no escopo do tenant
+func (s *IntegrationTestSuite) EnsureBaseline(name, version string, seed func(ctx context.Context) error) {
+	s.t.Helper()
+	defer s.trackOperation("EnsureBaseline")()
+
+	key := name + "@" + version
+	if _, ok := baselinesReady.Load(key); ok {
+		return
+	}
+
+	require.True(s.t, s.ES() != nil || s.Postgres() != nil,
+		"Baseline %s needs Elasticsearch or PostgreSQL to store its marker", name)
+
+	ready, err := s.baselineMarked(name, version)
+	require.NoError(s.t, err, "Failed to read baseline marker %s", name)
+	if ready {
+		baselinesReady.Store(key, struct{}{})
+		return
+	}
+
+	unlock, err := lockBaseline(s.ctx, name)
+	require.NoError(s.t, err, "Failed to lock baseline %s", name)
+	defer unlock()
+
+	// Outro processo pode ter semeado enquanto aguardávamos o lock
+	ready, err = s.baselineMarked(name, version)
+	require.NoError(s.t, err, "Failed to read baseline marker %s", name)
+	if ready {
+		baselinesReady.Store(key, struct{}{})
+		return
+	}
+
+	if isDebugEnabled() {
+		log.Printf("🌱 Seeding baseline %s (version %s)...", name, version)
+	}
+	start := time.Now()
+
+	err = seed(s.ctx)
+	require.NoError(s.t, err, "Failed to seed baseline %s", name)
+
+	err = s.markBaseline(name, version)
+	require.NoError(s.t, err, "Failed to write baseline marker %s", name)
+
+	if isDebugEnabled() {
+		log.Printf("✅ Baseline %s seeded in %s", name, time.Since(start).Round(time.Millisecond))
+	}
+	baselinesReady.Store(key, struct{}{})
+}
+
+// baselineMarked indica se todos os stores configurados têm o marcador da baseline na versão
+func (s *IntegrationTestSuite) baselineMarked(name, version string) (bool, error) {
+	if s.ES() != nil {
+		marked, err := s.esBaselineMarked(name, version)
+		if err != nil || !marked {
+			return false, err
+		}
+	}
+
+	if db := s.Postgres(); db != nil {
+		var marked bool
+		err := db.QueryRowContext(s.ctx, fmt.Sprintf(
+			`SELECT EXISTS (SELECT 1 FROM pg_tables WHERE schemaname = 'public' AND tablename = '%s')`,
+			baselineMarkerTable)).Scan(&marked)
+		if err != nil || !marked {
+			return false, err
+		}
+
+		err = db.QueryRowContext(s.ctx, fmt.Sprintf(
+			`SELECT EXISTS (SELECT 1 FROM %s WHERE name = $1 AND version = $2)`, baselineMarkerTable),
+			name, version).Scan(&marked)
+		if err != nil || !marked {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// esBaselineMarked lê o marcador da baseline no índice de marcadores
+func (s *IntegrationTestSuite) esBaselineMarked(name, version string) (bool, error) {
+	res, err := esapi.GetRequest{Index: baselineMarkerIndex, DocumentID: name}.Do(s.ctx, s.ES())
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == 404 {
+		return false, nil
+	}
+	if res.IsError() {
+		return false, fmt.Errorf("failed to get baseline marker: %s", res.String())
+	}
+
+	var doc struct {
+		Source struct {
+			Version string `json:"version"`
+		} `json:"_source"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&doc); err != nil {
+		return false, fmt.Errorf("failed to decode baseline marker: %w", err)
+	}
+	return doc.Source.Version == version, nil
+}
+
+// markBaseline grava o marcador da baseline em todos os stores configurados
+func (s *IntegrationTestSuite) markBaseline(name, version string) error {
+	seededAt := time.Now().UTC()
+
+	if s.ES() != nil {
+		body, err := json.Marshal(map[string]interface{}{"version": version, "seeded_at": seededAt})
+		if err != nil {
+			return err
+		}
+
+		res, err := esapi.IndexRequest{
+			Index:      baselineMarkerIndex,
+			DocumentID: name,
+			Body:       strings.NewReader(string(body)),
+			Refresh:    "wait_for",
+		}.Do(s.ctx, s.ES())
+		if err != nil {
+			return err
+		}
+		defer res.Body.Close()
+
+		if res.IsError() {
+			return fmt.Errorf("failed to index baseline marker: %s", res.String())
+		}
+	}
+
+	if db := s.Postgres(); db != nil {
+		_, err := db.ExecContext(s.ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			name TEXT PRIMARY KEY,
+			version TEXT NOT NULL,
+			seeded_at TIMESTAMPTZ NOT NULL
+		)`, baselineMarkerTable))
+		if err != nil {
+			return fmt.Errorf("failed to create baseline marker table: %w", err)
+		}
+
+		_, err = db.ExecContext(s.ctx, fmt.Sprintf(`INSERT INTO %s (name, version, seeded_at) VALUES ($1, $2, $3)
+			ON CONFLICT (name) DO UPDATE SET version = EXCLUDED.version, seeded_at = EXCLUDED.seeded_at`,
+			baselineMarkerTable), name, version, seededAt)
+		if err != nil {
+			return fmt.Errorf("failed to write baseline marker: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// isBaselineIndex indica se o índice pertence a uma baseline (preservado pela limpeza)
+func isBaselineIndex(index string) bool {
+	return strings.HasPrefix(index, BaselineIndexPrefix)
+}
+
+// isBaselineTable indica se a tabela pertence a uma baseline (preservada pela limpeza)
+func isBaselineTable(table string) bool {
+	return strings.HasPrefix(table, BaselineTablePrefix)
+}
+
+// lockBaseline adquire o lock de arquivo da baseline, compartilhado pelos processos de teste da máquina
+// O lock é um arquivo criado com O_EXCL e atualizado periodicamente; um arquivo sem atualização recente
+// é de um processo encerrado e é removido
+func lockBaseline(ctx context.Context, name string) (func(), error) {
+	path := filepath.Join(os.TempDir(), "testhelper-baseline-"+baselineNameInvalid.ReplaceAllString(name, "_")+".lock")
+
+	for {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			fmt.Fprintf(file, "%d\n", os.Getpid())
+			file.Close()
+			return holdBaselineLock(path), nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to create lock file %s: %w", path, err)
+		}
+
+		if info, statErr := os.Stat(path); statErr == nil && time.Since(info.ModTime()) > baselineLockStale {
+			if isDebugEnabled() {
+				log.Printf("⚠️ Removing stale baseline lock %s", path)
+			}
+			os.Remove(path)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+}
+
+// holdBaselineLock mantém o lock atualizado até a função retornada ser chamada
+func holdBaselineLock(path string) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(baselineLockHeartbeat)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				os.Chtimes(path, now, now)
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+		os.Remove(path)
+	}
+}
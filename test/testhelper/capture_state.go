@@ -0,0 +1,461 @@
+package testhelper
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// captureManifestFile é o arquivo com os metadados da captura
+const captureManifestFile = "manifest.json"
+
+// invalidFixtureChars identifica caracteres que não podem compor nomes de diretório de fixtures
+var invalidFixtureChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// CaptureManifest descreve uma captura de estado gerada por CaptureState
+type CaptureManifest struct {
+	Test       string    `json:"test"`
+	TenantID   string    `json:"tenant_id"`
+	CapturedAt time.Time `json:"captured_at"`
+}
+
+// capturedDocument é o formato de um documento Elasticsearch capturado
+type capturedDocument struct {
+	ID     string          `json:"_id"`
+	Source json.RawMessage `json:"_source"`
+}
+
+// CaptureState registra a exportação do estado do tenant quando o teste falhar
+// Documentos do Elasticsearch, linhas do PostgreSQL e documentos do MongoDB com o tenant_id da suite
+// são gravados em dir/<nome do teste>, prontos para serem carregados com LoadCapturedState
+// A captura roda antes das limpezas da suite que removem dados (RegisterCleanup, CreateTenantIndex,
+// run scope...), mesmo as registradas depois. Limpezas registradas direto no t.Cleanup do teste
+// depois do CaptureState rodam antes dela: chame-o antes de registrá-las
+func (s *IntegrationTestSuite) CaptureState(dir string) {
+	s.t.Helper()
+
+	t := s.t
+	s.capture = &stateCapture{run: func() {
+		if !t.Failed() {
+			return
+		}
+
+		path, err := s.captureState(dir)
+		if err != nil {
+			t.Logf("⚠️  Failed to capture state: %v", err)
+			return
+		}
+		t.Logf("📦 State of tenant %s captured at %s", s.tenantID, path)
+	}}
+	t.Cleanup(s.runStateCapture)
+}
+
+// stateCapture é a captura registrada por CaptureState; roda uma única vez, na primeira limpeza após
+// a falha do teste
+type stateCapture struct {
+	mu   sync.Mutex
+	done bool
+	run  func()
+}
+
+// runStateCapture executa a captura pendente, se houver
+func (s *IntegrationTestSuite) runStateCapture() {
+	c := s.capture
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.done {
+		return
+	}
+	c.done = true
+	c.run()
+}
+
+// onCleanup registra uma limpeza que remove dados do teste: a captura do CaptureState roda antes dela
+// (t.Cleanup roda em ordem inversa, então uma limpeza registrada depois do CaptureState rodaria antes)
+func (s *IntegrationTestSuite) onCleanup(fn func()) {
+	s.t.Cleanup(func() {
+		s.runStateCapture()
+		fn()
+	})
+}
+
+// CaptureStateNow exporta imediatamente o estado do tenant e retorna o diretório gerado
+func (s *IntegrationTestSuite) CaptureStateNow(dir string) string {
+	s.t.Helper()
+
+	path, err := s.captureState(dir)
+	require.NoError(s.t, err, "Failed to capture state")
+	return path
+}
+
+// LoadCapturedState carrega uma captura gerada por CaptureState no tenant atual da suite
+// O tenant original é substituído pelo tenant da suite em todos os documentos e linhas
+// A captura é lida do fs.FS configurado com WithFixturesFS, se houver
+func (s *IntegrationTestSuite) LoadCapturedState(path string) {
+	s.t.Helper()
+	s.loadCapturedState(s.fixtures, path)
+}
+
+// LoadCapturedStateFS é LoadCapturedState lendo a captura de fsys (ex.: embed.FS)
+func (s *IntegrationTestSuite) LoadCapturedStateFS(fsys fs.FS, path string) {
+	s.t.Helper()
+	s.loadCapturedState(fsys, path)
+}
+
+// loadCapturedState carrega a captura lida de fsys (ou do disco, com fsys nil)
+func (s *IntegrationTestSuite) loadCapturedState(fsys fs.FS, path string) {
+	s.t.Helper()
+
+	raw, err := readFixtureFile(fsys, joinFixturePath(fsys, path, captureManifestFile))
+	require.NoError(s.t, err, "Failed to read capture manifest")
+
+	var manifest CaptureManifest
+	require.NoError(s.t, json.Unmarshal(raw, &manifest), "Invalid capture manifest")
+
+	rewrite := func(data []byte) []byte {
+		return bytes.ReplaceAll(data, []byte(manifest.TenantID), []byte(s.tenantID))
+	}
+
+	s.loadCapturedElasticsearch(fsys, joinFixturePath(fsys, path, "elasticsearch"), rewrite)
+
+	if db := s.Postgres(); db != nil {
+		s.loadCapturedPostgres(fsys, joinFixturePath(fsys, path, "postgres"), rewrite)
+	}
+	if db := s.Mongo(); db != nil {
+		s.loadCapturedMongo(db, fsys, joinFixturePath(fsys, path, "mongo"), rewrite)
+	}
+	if db := s.MongoDW(); db != nil {
+		s.loadCapturedMongo(db, fsys, joinFixturePath(fsys, path, "mongo_dw"), rewrite)
+	}
+}
+
+// captureState grava o estado de todas as dependências configuradas
+func (s *IntegrationTestSuite) captureState(dir string) (string, error) {
+	path := filepath.Join(dir, invalidFixtureChars.ReplaceAllString(s.t.Name(), "_"))
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create capture dir: %w", err)
+	}
+
+	manifest := CaptureManifest{
+		Test:       s.t.Name(),
+		TenantID:   s.tenantID,
+		CapturedAt: time.Now().UTC(),
+	}
+	if err := writeJSONFile(filepath.Join(path, captureManifestFile), manifest); err != nil {
+		return "", err
+	}
+
+	if s.sharedES != nil || (s.builder != nil && s.builder.ESConn != nil) {
+		if err := s.captureElasticsearch(filepath.Join(path, "elasticsearch")); err != nil {
+			return "", err
+		}
+	}
+	if db := s.Postgres(); db != nil {
+		if err := s.capturePostgres(filepath.Join(path, "postgres")); err != nil {
+			return "", err
+		}
+	}
+	if db := s.Mongo(); db != nil {
+		if err := s.captureMongo(db, filepath.Join(path, "mongo")); err != nil {
+			return "", err
+		}
+	}
+	if db := s.MongoDW(); db != nil {
+		if err := s.captureMongo(db, filepath.Join(path, "mongo_dw")); err != nil {
+			return "", err
+		}
+	}
+
+	return path, nil
+}
+
+// captureElasticsearch exporta os documentos do tenant, um arquivo por índice. Pagina com point-in-time
+// + search_after (como SearchAllDocuments) para não parar nos 10.000 do index.max_result_window
+func (s *IntegrationTestSuite) captureElasticsearch(dir string) error {
+	client := s.ES()
+
+	pit, err := client.OpenPointInTime([]string{"*"}, searchAllKeepAlive,
+		client.OpenPointInTime.WithContext(s.ctx),
+		client.OpenPointInTime.WithIgnoreUnavailable(true),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to open point in time: %w", err)
+	}
+	defer pit.Body.Close()
+
+	if pit.IsError() {
+		return fmt.Errorf("failed to open point in time: %s", pit.String())
+	}
+
+	var opened struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(pit.Body).Decode(&opened); err != nil {
+		return fmt.Errorf("failed to decode point in time response: %w", err)
+	}
+	pitID := opened.ID
+	defer func() {
+		s.closePointInTime(pitID)
+	}()
+
+	query := map[string]interface{}{
+		"size": searchAllPageSize,
+		"query": map[string]interface{}{
+			"term": map[string]interface{}{"tenant_id.keyword": s.tenantID},
+		},
+		"sort": []interface{}{"_shard_doc"},
+	}
+
+	byIndex := make(map[string][]capturedDocument)
+	for page := 1; ; page++ {
+		if page > maxCursorPages {
+			return fmt.Errorf("tenant documents did not fit in %d pages", maxCursorPages)
+		}
+
+		query["pit"] = map[string]interface{}{"id": pitID, "keep_alive": searchAllKeepAlive}
+		body, err := json.Marshal(query)
+		if err != nil {
+			return fmt.Errorf("failed to marshal capture query: %w", err)
+		}
+
+		res, err := esapi.SearchRequest{Body: bytes.NewReader(body)}.Do(s.ctx, client)
+		if err != nil {
+			return fmt.Errorf("failed to search tenant documents: %w", err)
+		}
+		if res.IsError() {
+			defer res.Body.Close()
+			return fmt.Errorf("failed to search tenant documents: %s", res.String())
+		}
+
+		var response struct {
+			PitID string `json:"pit_id"`
+			Hits  struct {
+				Hits []struct {
+					Index  string          `json:"_index"`
+					ID     string          `json:"_id"`
+					Source json.RawMessage `json:"_source"`
+					Sort   []interface{}   `json:"sort"`
+				} `json:"hits"`
+			} `json:"hits"`
+		}
+		err = json.NewDecoder(res.Body).Decode(&response)
+		res.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to decode tenant documents: %w", err)
+		}
+
+		if response.PitID != "" {
+			pitID = response.PitID
+		}
+		for _, hit := range response.Hits.Hits {
+			byIndex[hit.Index] = append(byIndex[hit.Index], capturedDocument{ID: hit.ID, Source: hit.Source})
+		}
+
+		hits := response.Hits.Hits
+		if len(hits) < searchAllPageSize {
+			break
+		}
+		query["search_after"] = hits[len(hits)-1].Sort
+	}
+
+	for index, docs := range byIndex {
+		if err := writeJSONFile(filepath.Join(dir, index+".json"), docs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// capturePostgres exporta as linhas do tenant de todas as tabelas com coluna tenant_id
+func (s *IntegrationTestSuite) capturePostgres(dir string) error {
+	db := s.Postgres()
+
+	tables, err := tenantTables(s.ctx, db)
+	if err != nil {
+		return err
+	}
+
+	for _, table := range tables {
+		var rows []byte
+		err := db.QueryRowContext(s.ctx,
+			fmt.Sprintf(`SELECT COALESCE(json_agg(t), '[]') FROM "%s" t WHERE tenant_id::text = $1`, table),
+			s.tenantID,
+		).Scan(&rows)
+		if err != nil {
+			return fmt.Errorf("failed to capture table %s: %w", table, err)
+		}
+
+		if string(rows) == "[]" {
+			continue
+		}
+		if err := writeRawFile(filepath.Join(dir, table+".json"), rows); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// captureMongo exporta os documentos do tenant de todas as coleções em Extended JSON
+func (s *IntegrationTestSuite) captureMongo(db *mongo.Database, dir string) error {
+	collections, err := db.ListCollectionNames(s.ctx, bson.M{})
+	if err != nil {
+		return fmt.Errorf("failed to list collections: %w", err)
+	}
+
+	for _, name := range collections {
+		cursor, err := db.Collection(name).Find(s.ctx, bson.M{"tenant_id": s.tenantID})
+		if err != nil {
+			return fmt.Errorf("failed to capture collection %s: %w", name, err)
+		}
+
+		var docs []json.RawMessage
+		for cursor.Next(s.ctx) {
+			doc, err := bson.MarshalExtJSON(cursor.Current, true, false)
+			if err != nil {
+				cursor.Close(s.ctx)
+				return fmt.Errorf("failed to marshal document from %s: %w", name, err)
+			}
+			docs = append(docs, doc)
+		}
+		cursor.Close(s.ctx)
+
+		if len(docs) == 0 {
+			continue
+		}
+		if err := writeJSONFile(filepath.Join(dir, name+".json"), docs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadCapturedElasticsearch indexa os documentos capturados
+func (s *IntegrationTestSuite) loadCapturedElasticsearch(fsys fs.FS, dir string, rewrite func([]byte) []byte) {
+	s.t.Helper()
+
+	forEachCaptureFile(s.t, fsys, dir, func(name string, data []byte) {
+		var docs []capturedDocument
+		require.NoError(s.t, json.Unmarshal(rewrite(data), &docs), "Invalid capture file %s", name)
+
+		for _, doc := range docs {
+			s.IndexDocument(name, doc.ID, doc.Source)
+		}
+	})
+}
+
+// loadCapturedPostgres insere as linhas capturadas usando json_populate_recordset
+func (s *IntegrationTestSuite) loadCapturedPostgres(fsys fs.FS, dir string, rewrite func([]byte) []byte) {
+	s.t.Helper()
+
+	forEachCaptureFile(s.t, fsys, dir, func(table string, data []byte) {
+		_, err := s.Postgres().ExecContext(s.ctx,
+			fmt.Sprintf(`INSERT INTO "%s" SELECT * FROM json_populate_recordset(NULL::"%s", $1)`, table, table),
+			string(rewrite(data)),
+		)
+		require.NoError(s.t, err, "Failed to load captured rows into %s", table)
+	})
+}
+
+// loadCapturedMongo insere os documentos capturados
+func (s *IntegrationTestSuite) loadCapturedMongo(db *mongo.Database, fsys fs.FS, dir string, rewrite func([]byte) []byte) {
+	s.t.Helper()
+
+	forEachCaptureFile(s.t, fsys, dir, func(collection string, data []byte) {
+		var raws []json.RawMessage
+		require.NoError(s.t, json.Unmarshal(rewrite(data), &raws), "Invalid capture file %s", collection)
+
+		docs := make([]interface{}, 0, len(raws))
+		for _, raw := range raws {
+			var doc bson.D
+			require.NoError(s.t, bson.UnmarshalExtJSON(raw, true, &doc), "Invalid document in %s", collection)
+			docs = append(docs, doc)
+		}
+
+		_, err := db.Collection(collection).InsertMany(s.ctx, docs)
+		require.NoError(s.t, err, "Failed to load captured documents into %s", collection)
+	})
+}
+
+// tenantTables retorna as tabelas do schema public que possuem coluna tenant_id
+func tenantTables(ctx context.Context, db *sql.DB) ([]string, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT table_name
+		FROM information_schema.columns
+		WHERE table_schema = 'public' AND column_name = 'tenant_id'
+		ORDER BY table_name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenant tables: %w", err)
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return nil, fmt.Errorf("failed to scan table name: %w", err)
+		}
+		tables = append(tables, table)
+	}
+	return tables, rows.Err()
+}
+
+// forEachCaptureFile chama fn para cada arquivo .json do diretório (ignorado se não existir)
+func forEachCaptureFile(t require.TestingT, fsys fs.FS, dir string, fn func(name string, data []byte)) {
+	entries, err := readFixtureDir(fsys, dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return
+	}
+	require.NoError(t, err, "Failed to read capture dir %s", dir)
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := readFixtureFile(fsys, joinFixturePath(fsys, dir, entry.Name()))
+		require.NoError(t, err, "Failed to read capture file %s", entry.Name())
+		fn(strings.TrimSuffix(entry.Name(), ".json"), data)
+	}
+}
+
+// writeJSONFile grava value como JSON indentado
+func writeJSONFile(path string, value interface{}) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", filepath.Base(path), err)
+	}
+	return writeRawFile(path, data)
+}
+
+// writeRawFile grava os bytes criando o diretório se necessário
+func writeRawFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create dir for %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
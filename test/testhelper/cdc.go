@@ -0,0 +1,100 @@
+package testhelper
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// cdcNameInvalid casa caracteres não permitidos em nomes de slot/publicação do Postgres
+var cdcNameInvalid = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// CDCStream é um conector Debezium registrado para o teste; os eventos são filtrados pelo tenant da suite
+// (linhas sem coluna tenant_id não são filtradas)
+type CDCStream struct {
+	suite  *IntegrationTestSuite
+	config DebeziumConnectorConfig
+}
+
+// StartCDC registra um conector Debezium exclusivo do teste para as tabelas informadas (vazio captura
+// todas) e aguarda ele começar a transmitir; mudanças feitas depois do retorno são capturadas.
+// O conector, o slot de replicação e a publicação são removidos ao final do teste
+func (s *IntegrationTestSuite) StartCDC(tables ...string) *CDCStream {
+	s.t.Helper()
+	defer s.trackOperation("StartCDC")()
+
+	debezium := s.Debezium()
+	require.NotNil(s.t, debezium, "Debezium not configured, use WithDebezium()")
+	require.NotNil(s.t, s.builder.sharedPG, "PostgreSQL not configured")
+
+	name := cdcNameInvalid.ReplaceAllString(strings.ToLower(s.tenantID), "_")
+	config := DebeziumConnectorConfig{
+		Name:            "testhelper-" + name,
+		TopicPrefix:     "testhelper_" + name,
+		Tables:          tables,
+		SlotName:        "dbz_" + name,
+		PublicationName: "dbz_" + name,
+	}
+
+	err := debezium.RegisterPostgresConnector(s.ctx, s.builder.sharedPG, config)
+	require.NoError(s.t, err, "Failed to start CDC connector")
+
+	pg := s.builder.sharedPG
+	s.t.Cleanup(func() {
+		if err := debezium.DeletePostgresConnector(context.Background(), pg, config); err != nil {
+			s.t.Logf("⚠️ Failed to remove CDC connector %s: %v", config.Name, err)
+		}
+	})
+
+	return &CDCStream{suite: s, config: config}
+}
+
+// Topic retorna o tópico Kafka da tabela ("schema.tabela"; sem schema usa public)
+func (c *CDCStream) Topic(table string) string {
+	return c.config.TopicPrefix + "." + qualifiedTable(table)
+}
+
+// Events retorna os eventos da tabela capturados até agora
+func (c *CDCStream) Events(table string) []DebeziumEvent {
+	c.suite.t.Helper()
+	defer c.suite.trackOperation("CDCEvents")()
+
+	events, err := c.suite.Debezium().ConsumeEvents(c.suite.ctx, c.Topic(table), 2*time.Second)
+	require.NoError(c.suite.t, err, "Failed to consume change events of %s", table)
+
+	return c.tenantEvents(events)
+}
+
+// WaitForEvents aguarda pelo menos min eventos da tabela e retorna os eventos encontrados
+func (c *CDCStream) WaitForEvents(table string, min int, timeout time.Duration) []DebeziumEvent {
+	c.suite.t.Helper()
+
+	deadline := time.Now().Add(timeout)
+	for {
+		events := c.Events(table)
+		if len(events) >= min {
+			return events
+		}
+		if time.Now().After(deadline) {
+			require.Fail(c.suite.t, fmt.Sprintf("Found %d of %d change events of %s within %s", len(events), min, table, timeout))
+			return events
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+// tenantEvents descarta eventos de linhas de outros tenants
+func (c *CDCStream) tenantEvents(events []DebeziumEvent) []DebeziumEvent {
+	var filtered []DebeziumEvent
+	for _, event := range events {
+		if tenant, ok := event.Row()["tenant_id"]; ok && fmt.Sprint(tenant) != c.suite.tenantID {
+			continue
+		}
+		filtered = append(filtered, event)
+	}
+	return filtered
+}
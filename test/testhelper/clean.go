@@ -0,0 +1,247 @@
+package testhelper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Demais dependências, usadas na limpeza seletiva (Clean)
+const (
+	DepCassandra      Dependency = "cassandra"
+	DepClickHouse     Dependency = "clickhouse"
+	DepDynamoDB       Dependency = "dynamodb"
+	DepMemcached      Dependency = "memcached"
+	DepNeo4j          Dependency = "neo4j"
+	DepTemporal       Dependency = "temporal"
+	DepVault          Dependency = "vault"
+	DepWireMock       Dependency = "wiremock"
+	DepMailHog        Dependency = "mailhog"
+	DepAzurite        Dependency = "azurite"
+	DepGCS            Dependency = "gcs"
+	DepPubSub         Dependency = "pubsub"
+	DepElasticMQ      Dependency = "elasticmq"
+	DepConsul         Dependency = "consul"
+	DepInflux         Dependency = "influxdb"
+	DepSQLServer      Dependency = "sqlserver"
+	DepCouchbase      Dependency = "couchbase"
+	DepSolr           Dependency = "solr"
+	DepMeilisearch    Dependency = "meilisearch"
+	DepTypesense      Dependency = "typesense"
+	DepKibana         Dependency = "kibana"
+	DepAPM            Dependency = "apm"
+	DepPrometheus     Dependency = "prometheus"
+	DepSchemaRegistry Dependency = "schema-registry"
+	DepArtemis        Dependency = "artemis"
+)
+
+// dependencyCleaner associa a dependência à verificação de configuração e à limpeza
+type dependencyCleaner struct {
+	dep        Dependency
+	configured func() bool
+	clean      func(ctx context.Context) error
+}
+
+// Clean limpa as dependências informadas (todas as configuradas, se nenhuma for informada) e retorna os
+// erros agregados em vez de falhar o teste, para uso em benchmarks e cenários longos
+// Uma dependência informada mas não configurada na suite é um erro; o contexto cancelado interrompe a limpeza
+func (s *IntegrationTestSuite) Clean(ctx context.Context, deps ...Dependency) error {
+	cleaners := s.cleaners()
+
+	selected := cleaners
+	if len(deps) > 0 {
+		byDep := make(map[Dependency]dependencyCleaner, len(cleaners))
+		for _, cleaner := range cleaners {
+			byDep[cleaner.dep] = cleaner
+		}
+
+		selected = make([]dependencyCleaner, 0, len(deps))
+		var errs []error
+		for _, dep := range deps {
+			cleaner, ok := byDep[dep]
+			switch {
+			case !ok:
+				errs = append(errs, fmt.Errorf("%s: unknown dependency", dep))
+			case !cleaner.configured():
+				errs = append(errs, fmt.Errorf("%s: dependency not configured", dep))
+			default:
+				selected = append(selected, cleaner)
+			}
+		}
+		if len(errs) > 0 {
+			return errors.Join(errs...)
+		}
+	}
+
+	var errs []error
+	for _, cleaner := range selected {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		if !cleaner.configured() {
+			continue
+		}
+		if err := cleaner.clean(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", cleaner.dep, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// cleanDependency limpa uma dependência se configurada, falhando o teste em caso de erro
+func (s *IntegrationTestSuite) cleanDependency(dep Dependency) {
+	s.t.Helper()
+
+	for _, cleaner := range s.cleaners() {
+		if cleaner.dep == dep {
+			if cleaner.configured() {
+				require.NoError(s.t, cleaner.clean(s.ctx))
+			}
+			return
+		}
+	}
+}
+
+// cleaners lista as limpezas de cada dependência, na ordem usada por CleanAll
+func (s *IntegrationTestSuite) cleaners() []dependencyCleaner {
+	b := s.builder
+	if b == nil {
+		b = &TestDependenciesBuilder{}
+	}
+
+	// builderClean adapta as funções de limpeza do builder, que podem não estar definidas
+	builderClean := func(fn func(ctx context.Context) error, msg string) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			if fn == nil {
+				return nil
+			}
+			if err := fn(ctx); err != nil {
+				return fmt.Errorf("%s: %w", msg, err)
+			}
+			return nil
+		}
+	}
+
+	return []dependencyCleaner{
+		{DepElasticsearch, func() bool { return s.ES() != nil }, func(ctx context.Context) error {
+			// Com prefixo por teste, só os índices do teste são removidos (testes paralelos seguem intactos)
+			if s.indexPrefix != "" {
+				return s.deletePrefixedIndices(ctx)
+			}
+			// Chama o shared container direto (e não o ESClearFunc do builder) para usar o ctx da limpeza
+			// e propagar o erro
+			shared := s.sharedES
+			if shared == nil {
+				shared = b.sharedES
+			}
+			if shared == nil {
+				return nil
+			}
+			if err := shared.CleanIndices(ctx); err != nil {
+				return fmt.Errorf("failed to clean Elasticsearch indices: %w", err)
+			}
+			return nil
+		}},
+		{DepMongo, func() bool { return s.Mongo() != nil }, func(ctx context.Context) error {
+			clean := b.MongoClearFunc
+			if clean == nil && s.sharedMongo != nil {
+				clean = s.sharedMongo.CleanDatabase
+			}
+			return builderClean(clean, "failed to clean MongoDB collections")(ctx)
+		}},
+		{DepPostgres, func() bool { return s.Postgres() != nil }, func(ctx context.Context) error {
+			clean := b.PostgresClearFunc
+			if clean == nil && s.sharedPG != nil {
+				clean = s.sharedPG.CleanDatabase
+			}
+			return builderClean(clean, "failed to clean PostgreSQL tables")(ctx)
+		}},
+		{DepCassandra, func() bool { return s.Cassandra() != nil }, func(ctx context.Context) error {
+			if s.sharedCassandra == nil {
+				return nil
+			}
+			if err := s.sharedCassandra.CleanKeyspace(ctx, s.tenantID); err != nil {
+				return fmt.Errorf("failed to clean Cassandra keyspace: %w", err)
+			}
+			return nil
+		}},
+		{DepClickHouse, func() bool { return s.ClickHouse() != nil },
+			builderClean(b.ClickHouseClearFunc, "failed to clean ClickHouse tables")},
+		{DepDynamoDB, func() bool { return s.DynamoDB() != nil },
+			builderClean(b.DynamoDBClearFunc, "failed to clean DynamoDB tables")},
+		{DepMemcached, func() bool { return s.MemcachedAddr() != "" },
+			builderClean(b.MemcachedClearFunc, "failed to flush memcached")},
+		{DepNeo4j, func() bool { return s.sharedNeo4j != nil }, func(ctx context.Context) error {
+			if err := s.sharedNeo4j.CleanTenant(ctx, s.tenantID); err != nil {
+				return fmt.Errorf("failed to clean Neo4j: %w", err)
+			}
+			return nil
+		}},
+		{DepTemporal, func() bool { return s.Temporal() != nil }, func(ctx context.Context) error {
+			if err := s.Temporal().DeleteNamespace(ctx, s.tenantID); err != nil {
+				return fmt.Errorf("failed to clean Temporal namespace: %w", err)
+			}
+			return nil
+		}},
+		{DepVault, func() bool { return s.Vault() != nil },
+			builderClean(b.VaultClearFunc, "failed to wipe Vault KV mount")},
+		{DepWireMock, func() bool { return s.WireMock() != nil },
+			builderClean(b.WireMockClearFunc, "failed to reset WireMock")},
+		{DepMailHog, func() bool { return s.MailHog() != nil },
+			builderClean(b.MailHogClearFunc, "failed to clean MailHog")},
+		{DepAzurite, func() bool { return s.Azurite() != nil },
+			builderClean(b.AzuriteClearFunc, "failed to clean Azurite")},
+		{DepGCS, func() bool { return s.GCS() != nil },
+			builderClean(b.GCSClearFunc, "failed to clean GCS")},
+		{DepPubSub, func() bool { return s.PubSub() != nil }, func(ctx context.Context) error {
+			if err := s.PubSub().DeleteByPrefix(ctx, TenantTopicID(s.tenantID, "")); err != nil {
+				return fmt.Errorf("failed to clean Pub/Sub: %w", err)
+			}
+			return nil
+		}},
+		{DepElasticMQ, func() bool { return s.ElasticMQ() != nil },
+			builderClean(b.ElasticMQPurgeFunc, "failed to purge ElasticMQ queues")},
+		{DepConsul, func() bool { return s.Consul() != nil }, func(ctx context.Context) error {
+			if err := s.Consul().DeleteKVPrefix(ctx, s.ConsulKVPrefix()); err != nil {
+				return fmt.Errorf("failed to clean Consul KV: %w", err)
+			}
+			if err := s.Consul().DeregisterServices(ctx, s.tenantID+"-"); err != nil {
+				return fmt.Errorf("failed to deregister Consul services: %w", err)
+			}
+			return nil
+		}},
+		{DepInflux, func() bool { return s.Influx() != nil },
+			builderClean(b.InfluxClearFunc, "failed to clean InfluxDB bucket")},
+		{DepSQLServer, func() bool { return b.sharedSQLServer != nil },
+			builderClean(b.SQLServerClearFunc, "failed to clean SQL Server tables")},
+		{DepCouchbase, func() bool { return s.Couchbase() != nil }, func(ctx context.Context) error {
+			if err := s.Couchbase().CleanBucket(ctx, s.tenantID); err != nil {
+				return fmt.Errorf("failed to clean Couchbase bucket: %w", err)
+			}
+			return nil
+		}},
+		{DepSolr, func() bool { return s.Solr() != nil },
+			builderClean(b.SolrClearFunc, "failed to clean Solr")},
+		{DepMeilisearch, func() bool { return s.Meilisearch() != nil },
+			builderClean(b.MeilisearchClearFunc, "failed to clean Meilisearch")},
+		{DepTypesense, func() bool { return s.Typesense() != nil },
+			builderClean(b.TypesenseClearFunc, "failed to clean Typesense")},
+		{DepKibana, func() bool { return s.Kibana() != nil },
+			builderClean(b.KibanaClearFunc, "failed to clean Kibana")},
+		{DepAPM, func() bool { return s.APMServer() != nil },
+			builderClean(b.APMClearFunc, "failed to clean APM data")},
+		{DepPrometheus, func() bool { return s.Prometheus() != nil },
+			builderClean(b.PrometheusClearFunc, "failed to clean Prometheus")},
+		{DepSchemaRegistry, func() bool { return s.SchemaRegistry() != nil }, func(ctx context.Context) error {
+			if err := s.SchemaRegistry().ResetSubjects(ctx, s.tenantID+"-"); err != nil {
+				return fmt.Errorf("failed to clean Schema Registry subjects: %w", err)
+			}
+			return nil
+		}},
+		{DepArtemis, func() bool { return s.Artemis() != nil },
+			builderClean(b.ArtemisPurgeFunc, "failed to purge Artemis addresses")},
+	}
+}
@@ -0,0 +1,185 @@
+package testhelper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+)
+
+var (
+	cleanupScheduler     *CleanupScheduler
+	cleanupSchedulerOnce sync.Once
+)
+
+// CleanupStats contabiliza as operações executadas pelo CleanupScheduler
+type CleanupStats struct {
+	Operations     int
+	Batches        int
+	BatchedTenants int
+}
+
+// CleanupScheduler serializa e agrupa operações de limpeza de todas as suites do processo
+// Limita quantas limpezas rodam ao mesmo tempo no Elasticsearch compartilhado e junta
+// deleções por tenant solicitadas em sequência em um único _delete_by_query
+type CleanupScheduler struct {
+	slots       chan struct{}
+	batchWindow time.Duration
+	maxBatch    int
+
+	mu      sync.Mutex
+	pending map[*elasticsearch.Client]*tenantDeleteBatch
+	stats   CleanupStats
+}
+
+// tenantDeleteBatch agrupa tenants cujos documentos serão removidos numa única requisição
+type tenantDeleteBatch struct {
+	tenants   []string
+	flushOnce sync.Once
+	done      chan struct{}
+	err       error
+}
+
+// GetCleanupScheduler retorna o scheduler de limpeza do processo
+// Configurável via TEST_CLEANUP_CONCURRENCY, TEST_CLEANUP_BATCH_WINDOW e TEST_CLEANUP_MAX_BATCH
+func GetCleanupScheduler() *CleanupScheduler {
+	cleanupSchedulerOnce.Do(func() {
+		concurrency := 2
+		if v, err := strconv.Atoi(os.Getenv("TEST_CLEANUP_CONCURRENCY")); err == nil && v > 0 {
+			concurrency = v
+		}
+
+		batchWindow := 50 * time.Millisecond
+		if v, err := time.ParseDuration(os.Getenv("TEST_CLEANUP_BATCH_WINDOW")); err == nil && v >= 0 {
+			batchWindow = v
+		}
+
+		maxBatch := 50
+		if v, err := strconv.Atoi(os.Getenv("TEST_CLEANUP_MAX_BATCH")); err == nil && v > 0 {
+			maxBatch = v
+		}
+
+		cleanupScheduler = &CleanupScheduler{
+			slots:       make(chan struct{}, concurrency),
+			batchWindow: batchWindow,
+			maxBatch:    maxBatch,
+			pending:     make(map[*elasticsearch.Client]*tenantDeleteBatch),
+		}
+	})
+	return cleanupScheduler
+}
+
+// Do executa fn respeitando o limite de limpezas concorrentes
+func (c *CleanupScheduler) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	select {
+	case c.slots <- struct{}{}:
+	case <-ctx.Done():
+		return fmt.Errorf("cleanup not scheduled: %w", ctx.Err())
+	}
+	defer func() { <-c.slots }()
+
+	c.mu.Lock()
+	c.stats.Operations++
+	c.mu.Unlock()
+
+	return fn(ctx)
+}
+
+// DeleteTenantDocuments remove os documentos do tenant em todos os índices (exceto os de sistema)
+// Chamadas de várias suites dentro da janela de batching viram um único _delete_by_query
+func (c *CleanupScheduler) DeleteTenantDocuments(ctx context.Context, client *elasticsearch.Client, tenantID string) error {
+	c.mu.Lock()
+	batch := c.pending[client]
+	if batch == nil {
+		batch = &tenantDeleteBatch{done: make(chan struct{})}
+		c.pending[client] = batch
+		time.AfterFunc(c.batchWindow, func() {
+			c.flush(client, batch)
+		})
+	}
+	batch.tenants = append(batch.tenants, tenantID)
+	full := len(batch.tenants) >= c.maxBatch
+	c.mu.Unlock()
+
+	if full {
+		go c.flush(client, batch)
+	}
+
+	select {
+	case <-batch.done:
+		return batch.err
+	case <-ctx.Done():
+		return fmt.Errorf("tenant cleanup canceled: %w", ctx.Err())
+	}
+}
+
+// Stats retorna os contadores de operações executadas
+func (c *CleanupScheduler) Stats() CleanupStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// flush executa o batch uma única vez, liberando todos os chamadores ao final
+func (c *CleanupScheduler) flush(client *elasticsearch.Client, batch *tenantDeleteBatch) {
+	batch.flushOnce.Do(func() {
+		c.mu.Lock()
+		if c.pending[client] == batch {
+			delete(c.pending, client)
+		}
+		tenants := append([]string(nil), batch.tenants...)
+		c.stats.Batches++
+		c.stats.BatchedTenants += len(tenants)
+		c.mu.Unlock()
+
+		batch.err = c.Do(context.Background(), func(ctx context.Context) error {
+			return deleteTenantsByQuery(ctx, client, tenants)
+		})
+		close(batch.done)
+	})
+}
+
+// deleteTenantsByQuery remove os documentos dos tenants informados com um único _delete_by_query
+func deleteTenantsByQuery(ctx context.Context, client *elasticsearch.Client, tenants []string) error {
+	query := map[string]interface{}{
+		"query": map[string]interface{}{
+			"terms": map[string]interface{}{"tenant_id.keyword": tenants},
+		},
+	}
+	body, err := json.Marshal(query)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tenant cleanup query: %w", err)
+	}
+
+	req := esapi.DeleteByQueryRequest{
+		Index:             []string{"*", "-.*"},
+		Body:              bytes.NewReader(body),
+		Conflicts:         "proceed",
+		Refresh:           esapi.BoolPtr(true),
+		IgnoreUnavailable: esapi.BoolPtr(true),
+		AllowNoIndices:    esapi.BoolPtr(true),
+	}
+
+	res, err := req.Do(ctx, client)
+	if err != nil {
+		return fmt.Errorf("failed to delete tenant documents: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("failed to delete tenant documents: %s", res.Status())
+	}
+
+	if isDebugEnabled() {
+		fmt.Printf("🧹 Deleted documents of %d tenant(s) in one batch\n", len(tenants))
+	}
+
+	return nil
+}
@@ -0,0 +1,161 @@
+package testhelper
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"testing"
+	"time"
+)
+
+// cleanupTimeout limita o tempo de cada tarefa de limpeza
+const cleanupTimeout = 30 * time.Second
+
+// cleanupTask é uma etapa de limpeza registrada no builder
+type cleanupTask struct {
+	name     string
+	critical bool
+	fn       func(ctx context.Context) error
+}
+
+// CleanupResult é o resultado de uma tarefa de limpeza executada
+type CleanupResult struct {
+	Name     string
+	Critical bool
+	Err      error
+	Duration time.Duration
+}
+
+// CleanupError agrega as tarefas de limpeza que falharam
+type CleanupError struct {
+	Failures []CleanupResult
+}
+
+// Error lista as tarefas que falharam
+func (e *CleanupError) Error() string {
+	parts := make([]string, 0, len(e.Failures))
+	for _, f := range e.Failures {
+		kind := "best-effort"
+		if f.Critical {
+			kind = "critical"
+		}
+		parts = append(parts, fmt.Sprintf("%s (%s): %v", f.Name, kind, f.Err))
+	}
+	return fmt.Sprintf("%d cleanup task(s) failed: %s", len(e.Failures), strings.Join(parts, "; "))
+}
+
+// HasCritical indica se alguma tarefa crítica falhou
+func (e *CleanupError) HasCritical() bool {
+	for _, f := range e.Failures {
+		if f.Critical {
+			return true
+		}
+	}
+	return false
+}
+
+// AddCleanup registra uma tarefa de limpeza best-effort (falhas são reportadas, mas não falham o teste)
+// As tarefas rodam em ordem inversa ao registro, como t.Cleanup
+func (b *TestDependenciesBuilder) AddCleanup(name string, fn func(ctx context.Context) error) *TestDependenciesBuilder {
+	b.addCleanupTask(cleanupTask{name: name, fn: fn})
+	return b
+}
+
+// AddCriticalCleanup registra uma tarefa de limpeza crítica (falha o teste em CleanupT)
+func (b *TestDependenciesBuilder) AddCriticalCleanup(name string, fn func(ctx context.Context) error) *TestDependenciesBuilder {
+	b.addCleanupTask(cleanupTask{name: name, critical: true, fn: fn})
+	return b
+}
+
+// addCleanupTask adiciona a tarefa (seguro para os goroutines do Build)
+func (b *TestDependenciesBuilder) addCleanupTask(task cleanupTask) {
+	b.cleanupMu.Lock()
+	defer b.cleanupMu.Unlock()
+	b.cleanupTasks = append(b.cleanupTasks, task)
+}
+
+// CleanupReport retorna o resultado de cada tarefa da última execução de Cleanup
+func (b *TestDependenciesBuilder) CleanupReport() []CleanupResult {
+	b.cleanupMu.Lock()
+	defer b.cleanupMu.Unlock()
+	return append([]CleanupResult(nil), b.cleanupResults...)
+}
+
+// CleanupT executa o Cleanup reportando no teste: falhas críticas falham o teste, as demais são logadas
+// Uso: t.Cleanup(func() { deps.CleanupT(t) })
+func (b *TestDependenciesBuilder) CleanupT(t testing.TB) {
+	t.Helper()
+
+	err := b.Cleanup()
+	if err == nil {
+		return
+	}
+
+	cleanupErr, ok := err.(*CleanupError)
+	if !ok {
+		t.Errorf("cleanup failed: %v", err)
+		return
+	}
+	for _, f := range cleanupErr.Failures {
+		if f.Critical {
+			t.Errorf("critical cleanup task %q failed: %v", f.Name, f.Err)
+		} else {
+			t.Logf("⚠️ cleanup task %q failed: %v", f.Name, f.Err)
+		}
+	}
+}
+
+// runCleanupTasks executa as tarefas registradas (da última para a primeira) uma única vez
+// Todas as tarefas rodam mesmo que alguma falhe; panics são convertidos em erro
+func (b *TestDependenciesBuilder) runCleanupTasks() error {
+	b.cleanupMu.Lock()
+	tasks := b.cleanupTasks
+	b.cleanupTasks = nil
+	b.cleanupMu.Unlock()
+
+	results := make([]CleanupResult, 0, len(tasks))
+	var failures []CleanupResult
+
+	for i := len(tasks) - 1; i >= 0; i-- {
+		task := tasks[i]
+		if task.fn == nil {
+			continue
+		}
+
+		start := time.Now()
+		err := runCleanupTask(task)
+		result := CleanupResult{Name: task.name, Critical: task.critical, Err: err, Duration: time.Since(start)}
+		results = append(results, result)
+
+		if err != nil {
+			failures = append(failures, result)
+			log.Printf("⚠️  Cleanup task %q failed: %v", task.name, err)
+		} else if isDebugEnabled() {
+			log.Printf("🧹 Cleanup task %q done in %v", task.name, result.Duration)
+		}
+	}
+
+	b.cleanupMu.Lock()
+	b.cleanupResults = results
+	b.cleanupMu.Unlock()
+
+	if len(failures) > 0 {
+		return &CleanupError{Failures: failures}
+	}
+	return nil
+}
+
+// runCleanupTask executa uma tarefa com timeout, recuperando panics
+func runCleanupTask(task cleanupTask) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return task.fn(ctx)
+}
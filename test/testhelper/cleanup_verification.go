@@ -0,0 +1,339 @@
+package testhelper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// Verificação diferencial da limpeza
+//
+// VerifyCleanup fotografa o estado compartilhado das dependências (índices e documentos fora do tenant,
+// linhas das tabelas fora do tenant e coleções do MongoDB) e, ao final do teste, depois das limpezas
+// registradas por ele, compara com o estado atual. Qualquer diferença é um vazamento do teste e falha
+// com o diff. Os dados do próprio tenant são ignorados: o isolamento por tenant é o comportamento esperado
+//
+// A comparação é global: testes paralelos (t.Parallel) de outras suites alteram o mesmo estado, então
+// a verificação só é confiável em testes sequenciais
+
+// sharedStateSnapshot é o estado compartilhado fotografado por VerifyCleanup
+type sharedStateSnapshot struct {
+	indices     map[string]int64
+	tables      map[string]int64
+	collections map[string]int64
+}
+
+// VerifyCleanup fotografa o estado agora e registra a comparação para o final do teste
+// Deve ser chamado antes dos helpers que registram limpezas, para que a comparação rode depois delas
+// ignore são globs (path.Match) de índices, tabelas ou coleções ("db.coleção") fora da comparação;
+// índices de sistema, baselines, nomes com o tenant da suite e índices do prefixo do teste já são ignorados
+func (s *IntegrationTestSuite) VerifyCleanup(ignore ...string) {
+	s.t.Helper()
+
+	before, err := s.snapshotSharedState(ignore)
+	if err != nil {
+		s.t.Errorf("Failed to snapshot state for cleanup verification: %v", err)
+		s.t.FailNow()
+	}
+
+	s.t.Cleanup(func() {
+		after, err := s.snapshotSharedState(ignore)
+		if err != nil {
+			s.t.Errorf("Failed to snapshot state for cleanup verification: %v", err)
+			return
+		}
+
+		if diff := diffSharedState(before, after); diff != "" {
+			s.t.Errorf("Test %s leaked state past its cleanup:\n%s", s.t.Name(), diff)
+		} else if isDebugEnabled() {
+			fmt.Printf("🧹 Cleanup verified for %s\n", s.t.Name())
+		}
+	})
+}
+
+// cleanupVerificationFromEnv indica se TEST_VERIFY_CLEANUP liga a verificação em todas as suites do builder
+func cleanupVerificationFromEnv() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv("TEST_VERIFY_CLEANUP"))
+	return enabled
+}
+
+// snapshotSharedState fotografa as dependências configuradas na suite
+func (s *IntegrationTestSuite) snapshotSharedState(ignore []string) (*sharedStateSnapshot, error) {
+	snapshot := &sharedStateSnapshot{}
+	skip := s.sharedStateFilter(ignore)
+
+	if s.ES() != nil {
+		indices, err := s.snapshotIndexCounts(skip)
+		if err != nil {
+			return nil, err
+		}
+		snapshot.indices = indices
+	}
+	if s.Postgres() != nil {
+		tables, err := s.snapshotTableCounts(skip)
+		if err != nil {
+			return nil, err
+		}
+		snapshot.tables = tables
+	}
+
+	for _, db := range []*mongo.Database{s.Mongo(), s.MongoDW()} {
+		if db == nil {
+			continue
+		}
+		if snapshot.collections == nil {
+			snapshot.collections = make(map[string]int64)
+		}
+		if err := s.snapshotCollections(db, skip, snapshot.collections); err != nil {
+			return nil, err
+		}
+	}
+
+	return snapshot, nil
+}
+
+// sharedStateFilter retorna o filtro dos nomes fora da comparação
+func (s *IntegrationTestSuite) sharedStateFilter(ignore []string) func(name string) bool {
+	tenant := strings.ToLower(s.tenantID)
+	return func(name string) bool {
+		switch {
+		case strings.HasPrefix(name, "."),
+			strings.HasPrefix(name, BaselineIndexPrefix),
+			strings.HasPrefix(name, BaselineTablePrefix),
+			strings.Contains(strings.ToLower(name), tenant),
+			s.indexPrefix != "" && strings.HasPrefix(name, s.indexPrefix):
+			return true
+		}
+		for _, pattern := range ignore {
+			if matched, _ := path.Match(pattern, name); matched {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// snapshotIndexCounts conta, por índice, os documentos que não pertencem ao tenant da suite
+func (s *IntegrationTestSuite) snapshotIndexCounts(skip func(string) bool) (map[string]int64, error) {
+	client := s.ES()
+
+	res, err := esapi.IndicesRefreshRequest{
+		Index:             []string{"*"},
+		IgnoreUnavailable: esapi.BoolPtr(true),
+		AllowNoIndices:    esapi.BoolPtr(true),
+	}.Do(s.ctx, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh indices: %w", err)
+	}
+	res.Body.Close()
+
+	res, err = esapi.CatIndicesRequest{Format: "json", H: []string{"index"}}.Do(s.ctx, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list indices: %w", err)
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return nil, fmt.Errorf("failed to list indices: %s", res.Status())
+	}
+
+	var listed []struct {
+		Index string `json:"index"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&listed); err != nil {
+		return nil, fmt.Errorf("failed to decode indices: %w", err)
+	}
+
+	counts := make(map[string]int64)
+	for _, entry := range listed {
+		if !skip(entry.Index) {
+			counts[entry.Index] = 0
+		}
+	}
+	if len(counts) == 0 {
+		return counts, nil
+	}
+
+	// Uma única busca agregando por _index conta os documentos fora do tenant em todos os índices
+	body, err := json.Marshal(map[string]interface{}{
+		"size":             0,
+		"track_total_hits": false,
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"must_not": []map[string]interface{}{
+					{"term": map[string]interface{}{"tenant_id.keyword": s.tenantID}},
+				},
+			},
+		},
+		"aggs": map[string]interface{}{
+			"by_index": map[string]interface{}{
+				"terms": map[string]interface{}{"field": "_index", "size": len(counts)},
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal count query: %w", err)
+	}
+
+	res, err = esapi.SearchRequest{
+		Index:             []string{"*"},
+		Body:              bytes.NewReader(body),
+		IgnoreUnavailable: esapi.BoolPtr(true),
+		AllowNoIndices:    esapi.BoolPtr(true),
+	}.Do(s.ctx, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count documents: %w", err)
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return nil, fmt.Errorf("failed to count documents: %s", res.Status())
+	}
+
+	var response struct {
+		Aggregations struct {
+			ByIndex struct {
+				Buckets []struct {
+					Key      string `json:"key"`
+					DocCount int64  `json:"doc_count"`
+				} `json:"buckets"`
+			} `json:"by_index"`
+		} `json:"aggregations"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode document counts: %w", err)
+	}
+
+	for _, bucket := range response.Aggregations.ByIndex.Buckets {
+		if _, ok := counts[bucket.Key]; ok {
+			counts[bucket.Key] = bucket.DocCount
+		}
+	}
+	return counts, nil
+}
+
+// snapshotTableCounts conta as linhas de cada tabela do schema public, sem as do tenant da suite
+func (s *IntegrationTestSuite) snapshotTableCounts(skip func(string) bool) (map[string]int64, error) {
+	db := s.Postgres()
+
+	rows, err := db.QueryContext(s.ctx, `
+		SELECT table_name
+		FROM information_schema.tables
+		WHERE table_schema = 'public' AND table_type = 'BASE TABLE'
+		ORDER BY table_name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan table name: %w", err)
+		}
+		if !skip(table) {
+			tables = append(tables, table)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	withTenant, err := tenantTables(s.ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	tenantScoped := make(map[string]bool, len(withTenant))
+	for _, table := range withTenant {
+		tenantScoped[table] = true
+	}
+
+	counts := make(map[string]int64, len(tables))
+	for _, table := range tables {
+		var count int64
+		if tenantScoped[table] {
+			err = db.QueryRowContext(s.ctx,
+				fmt.Sprintf(`SELECT count(*) FROM "%s" WHERE tenant_id::text IS DISTINCT FROM $1`, table),
+				s.tenantID,
+			).Scan(&count)
+		} else {
+			err = db.QueryRowContext(s.ctx, fmt.Sprintf(`SELECT count(*) FROM "%s"`, table)).Scan(&count)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to count rows of %s: %w", table, err)
+		}
+		counts[table] = count
+	}
+	return counts, nil
+}
+
+// snapshotCollections registra as coleções do banco ("db.coleção") com a contagem de documentos fora do tenant
+func (s *IntegrationTestSuite) snapshotCollections(db *mongo.Database, skip func(string) bool, into map[string]int64) error {
+	names, err := db.ListCollectionNames(s.ctx, bson.D{})
+	if err != nil {
+		return fmt.Errorf("failed to list collections of %s: %w", db.Name(), err)
+	}
+
+	for _, name := range names {
+		qualified := db.Name() + "." + name
+		if strings.HasPrefix(name, "system.") || skip(name) || skip(qualified) {
+			continue
+		}
+		count, err := db.Collection(name).CountDocuments(s.ctx, bson.M{"tenant_id": bson.M{"$ne": s.tenantID}})
+		if err != nil {
+			return fmt.Errorf("failed to count documents of %s: %w", qualified, err)
+		}
+		into[qualified] = count
+	}
+	return nil
+}
+
+// diffSharedState compara os dois estados e descreve cada diferença em uma linha (vazio se iguais)
+func diffSharedState(before, after *sharedStateSnapshot) string {
+	var lines []string
+	lines = append(lines, diffStateCounts("index", "documents", before.indices, after.indices)...)
+	lines = append(lines, diffStateCounts("table", "rows", before.tables, after.tables)...)
+	lines = append(lines, diffStateCounts("collection", "documents", before.collections, after.collections)...)
+	return strings.Join(lines, "\n")
+}
+
+// diffStateCounts compara contagens por nome: criados (+), removidos (-) e alterados (~)
+func diffStateCounts(kind, unit string, before, after map[string]int64) []string {
+	names := make(map[string]struct{}, len(before)+len(after))
+	for name := range before {
+		names[name] = struct{}{}
+	}
+	for name := range after {
+		names[name] = struct{}{}
+	}
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	var lines []string
+	for _, name := range sorted {
+		was, existed := before[name]
+		now, exists := after[name]
+		switch {
+		case !existed:
+			lines = append(lines, fmt.Sprintf("  + %s %s created (%d %s)", kind, name, now, unit))
+		case !exists:
+			lines = append(lines, fmt.Sprintf("  - %s %s deleted (had %d %s)", kind, name, was, unit))
+		case was != now:
+			lines = append(lines, fmt.Sprintf("  ~ %s %s: %d -> %d %s outside the test tenant", kind, name, was, now, unit))
+		}
+	}
+	return lines
+}
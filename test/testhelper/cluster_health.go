@@ -0,0 +1,141 @@
+package testhelper
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"github.com/stretchr/testify/require"
+)
+
+// WaitForGreenCluster aguarda o cluster (ou apenas os índices informados) ficar green, sem shards
+// inicializando ou realocando. Em um cluster single-node (o container padrão) as réplicas nunca são
+// alocadas, então basta yellow: todos os primários alocados
+func (s *IntegrationTestSuite) WaitForGreenCluster(timeout time.Duration, indices ...string) {
+	s.t.Helper()
+	defer s.trackOperation("WaitForGreenCluster")()
+
+	client := s.ES()
+	require.NotNil(s.t, client, "Elasticsearch not configured")
+	scoped := make([]string, len(indices))
+	for i, index := range indices {
+		scoped[i] = s.RunScopedIndex(index)
+	}
+
+	nodes, err := esapi.ClusterHealthRequest{}.Do(s.ctx, client)
+	require.NoError(s.t, err, "Failed to get cluster health")
+	defer nodes.Body.Close()
+	if nodes.IsError() {
+		require.Fail(s.t, fmt.Sprintf("Failed to get cluster health: %s", nodes.String()))
+	}
+	var topology struct {
+		NumberOfNodes int `json:"number_of_nodes"`
+	}
+	require.NoError(s.t, json.NewDecoder(nodes.Body).Decode(&topology), "Failed to decode cluster health response")
+
+	target := "green"
+	if topology.NumberOfNodes == 1 {
+		target = "yellow"
+	}
+
+	res, err := esapi.ClusterHealthRequest{
+		Index:                       scoped,
+		WaitForStatus:               target,
+		WaitForNoInitializingShards: esapi.BoolPtr(true),
+		WaitForNoRelocatingShards:   esapi.BoolPtr(true),
+		Timeout:                     timeout,
+	}.Do(s.ctx, client)
+	require.NoError(s.t, err, "Failed to wait for cluster health")
+	defer res.Body.Close()
+
+	// Timeout responde 408 com o corpo de health; os demais erros (ex.: índice inexistente) não
+	if res.IsError() && res.StatusCode != http.StatusRequestTimeout {
+		require.Fail(s.t, fmt.Sprintf("Failed to wait for cluster health: %s", res.String()))
+	}
+
+	var health struct {
+		Status             string `json:"status"`
+		TimedOut           bool   `json:"timed_out"`
+		UnassignedShards   int    `json:"unassigned_shards"`
+		InitializingShards int    `json:"initializing_shards"`
+		RelocatingShards   int    `json:"relocating_shards"`
+		NumberOfNodes      int    `json:"number_of_nodes"`
+	}
+	err = json.NewDecoder(res.Body).Decode(&health)
+	require.NoError(s.t, err, "Failed to decode cluster health response")
+
+	if health.TimedOut || (health.Status != "green" && health.Status != target) {
+		require.Fail(s.t, fmt.Sprintf("Cluster not %s within %s: status %s, %d unassigned, %d initializing, %d relocating shards on %d node(s)",
+			target, timeout, health.Status, health.UnassignedShards, health.InitializingShards, health.RelocatingShards, health.NumberOfNodes))
+	}
+}
+
+// GetIndexSettings retorna as configurações do índice com chaves planas ("index.refresh_interval"),
+// incluindo os valores padrão das chaves não definidas explicitamente
+func (s *IntegrationTestSuite) GetIndexSettings(index string) map[string]interface{} {
+	s.t.Helper()
+	defer s.trackOperation("GetIndexSettings")()
+
+	client := s.ES()
+	require.NotNil(s.t, client, "Elasticsearch not configured")
+	index = s.RunScopedIndex(index)
+
+	res, err := esapi.IndicesGetSettingsRequest{
+		Index:           []string{index},
+		FlatSettings:    esapi.BoolPtr(true),
+		IncludeDefaults: esapi.BoolPtr(true),
+	}.Do(s.ctx, client)
+	require.NoError(s.t, err, "Failed to get settings of %s", index)
+	defer res.Body.Close()
+
+	if res.IsError() {
+		require.Fail(s.t, fmt.Sprintf("Failed to get settings of %s: %s", index, res.String()))
+	}
+
+	var response map[string]struct {
+		Settings map[string]interface{} `json:"settings"`
+		Defaults map[string]interface{} `json:"defaults"`
+	}
+	require.NoError(s.t, json.NewDecoder(res.Body).Decode(&response), "Failed to decode settings of %s", index)
+
+	// Com alias ou data stream a resposta é indexada pelo índice concreto
+	require.Len(s.t, response, 1, "Expected a single index for %s", index)
+	settings := map[string]interface{}{}
+	for _, entry := range response {
+		for key, value := range entry.Defaults {
+			settings[key] = value
+		}
+		for key, value := range entry.Settings {
+			settings[key] = value
+		}
+	}
+	return settings
+}
+
+// SetIndexSettings atualiza configurações dinâmicas do índice (ex.: {"index.refresh_interval": "-1"} ou
+// {"index": {"number_of_replicas": 0}}); valores nil voltam ao padrão
+func (s *IntegrationTestSuite) SetIndexSettings(index string, settings map[string]interface{}) {
+	s.t.Helper()
+	defer s.trackOperation("SetIndexSettings")()
+
+	client := s.ES()
+	require.NotNil(s.t, client, "Elasticsearch not configured")
+	index = s.RunScopedIndex(index)
+
+	body, err := json.Marshal(flattenSettings("", settings))
+	require.NoError(s.t, err, "Failed to marshal settings of %s", index)
+
+	res, err := esapi.IndicesPutSettingsRequest{
+		Index: []string{index},
+		Body:  strings.NewReader(string(body)),
+	}.Do(s.ctx, client)
+	require.NoError(s.t, err, "Failed to update settings of %s", index)
+	defer res.Body.Close()
+
+	if res.IsError() {
+		require.Fail(s.t, fmt.Sprintf("Failed to update settings of %s: %s", index, res.String()))
+	}
+}
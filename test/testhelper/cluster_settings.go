@@ -0,0 +1,148 @@
+package testhelper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"github.com/stretchr/testify/require"
+)
+
+// clusterSettingsMu serializa a leitura+aplicação e a restauração das configurações de cluster do processo
+// Não fica preso durante o trecho de WithClusterSettings, que pode aninhar outras chamadas
+var clusterSettingsMu sync.Mutex
+
+// WithClusterSettings aplica configurações transient do cluster durante fn e restaura os valores
+// anteriores ao retornar, inclusive em panic ou t.FailNow. As chaves podem ser planas
+// ("search.max_buckets") ou aninhadas ({"search": {"max_buckets": 100}}). Como as configurações são
+// globais, testes paralelos que mudam a mesma chave restauram os valores um do outro
+func (s *IntegrationTestSuite) WithClusterSettings(settings map[string]interface{}, fn func()) {
+	s.t.Helper()
+
+	restore, err := applyTransientClusterSettings(s.ctx, s.ES(), flattenSettings("", settings))
+	require.NoError(s.t, err, "Failed to apply cluster settings")
+
+	defer func() {
+		if err := restoreTransientClusterSettings(s.ES(), restore); err != nil {
+			s.t.Errorf("❌ Failed to restore cluster settings %v: %v", restore, err)
+		}
+	}()
+
+	fn()
+}
+
+// SetClusterSetting aplica uma configuração transient do cluster até o fim do teste, quando o valor
+// anterior é restaurado. Como as configurações são globais, evite em testes paralelos que dependam
+// da mesma chave; para um trecho delimitado use WithClusterSettings
+func (s *IntegrationTestSuite) SetClusterSetting(key string, value interface{}) {
+	s.t.Helper()
+
+	// Um valor aninhado ({"max_buckets": 100} em "search") vira chaves planas, como em WithClusterSettings
+	flat := flattenSettings("", map[string]interface{}{key: value})
+
+	restore, err := applyTransientClusterSettings(s.ctx, s.ES(), flat)
+	require.NoError(s.t, err, "Failed to set cluster setting %s", key)
+
+	s.t.Cleanup(func() {
+		if err := restoreTransientClusterSettings(s.ES(), restore); err != nil {
+			s.t.Errorf("❌ Failed to restore cluster setting %s: %v", key, err)
+		}
+	})
+}
+
+// applyTransientClusterSettings aplica as configurações planas e retorna os valores anteriores para a
+// restauração (nil para as chaves sem valor transient, que são removidas)
+func applyTransientClusterSettings(ctx context.Context, client *elasticsearch.Client, flat map[string]interface{}) (map[string]interface{}, error) {
+	clusterSettingsMu.Lock()
+	defer clusterSettingsMu.Unlock()
+
+	previous, err := transientClusterSettings(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	restore := make(map[string]interface{}, len(flat))
+	for key := range flat {
+		restore[key] = previous[key]
+	}
+
+	if err := putTransientClusterSettings(ctx, client, flat); err != nil {
+		return nil, err
+	}
+	return restore, nil
+}
+
+// restoreTransientClusterSettings reaplica os valores anteriores
+// Usa um contexto próprio: o da suite pode ter sido cancelado pelo teste
+func restoreTransientClusterSettings(client *elasticsearch.Client, restore map[string]interface{}) error {
+	clusterSettingsMu.Lock()
+	defer clusterSettingsMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	return putTransientClusterSettings(ctx, client, restore)
+}
+
+// flattenSettings converte configurações aninhadas em chaves planas separadas por ponto
+func flattenSettings(prefix string, settings map[string]interface{}) map[string]interface{} {
+	flat := make(map[string]interface{})
+	for key, value := range settings {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			for nestedKey, nestedValue := range flattenSettings(key, nested) {
+				flat[nestedKey] = nestedValue
+			}
+			continue
+		}
+		flat[key] = value
+	}
+	return flat
+}
+
+// transientClusterSettings retorna as configurações transient atuais com chaves planas
+func transientClusterSettings(ctx context.Context, client *elasticsearch.Client) (map[string]interface{}, error) {
+	res, err := esapi.ClusterGetSettingsRequest{FlatSettings: esapi.BoolPtr(true)}.Do(ctx, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster settings: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, fmt.Errorf("failed to get cluster settings: %s", res.String())
+	}
+
+	var response struct {
+		Transient map[string]interface{} `json:"transient"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode cluster settings: %w", err)
+	}
+	return response.Transient, nil
+}
+
+// putTransientClusterSettings aplica as configurações transient (valores nil removem a configuração)
+func putTransientClusterSettings(ctx context.Context, client *elasticsearch.Client, settings map[string]interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{"transient": settings})
+	if err != nil {
+		return fmt.Errorf("failed to marshal cluster settings: %w", err)
+	}
+
+	res, err := esapi.ClusterPutSettingsRequest{Body: strings.NewReader(string(body))}.Do(ctx, client)
+	if err != nil {
+		return fmt.Errorf("failed to put cluster settings: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("failed to put cluster settings: %s", res.String())
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package testhelper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlattenSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		prefix   string
+		settings map[string]interface{}
+		expected map[string]interface{}
+	}{
+		{
+			name:     "Empty input",
+			settings: map[string]interface{}{},
+			expected: map[string]interface{}{},
+		},
+		{
+			name:     "Nil input",
+			settings: nil,
+			expected: map[string]interface{}{},
+		},
+		{
+			name:     "Flat keys are kept",
+			settings: map[string]interface{}{"cluster.routing.allocation.enable": "none"},
+			expected: map[string]interface{}{"cluster.routing.allocation.enable": "none"},
+		},
+		{
+			name: "Nested maps are joined with dots",
+			settings: map[string]interface{}{
+				"cluster": map[string]interface{}{
+					"routing": map[string]interface{}{
+						"allocation": map[string]interface{}{"enable": "primaries"},
+						"rebalance":  map[string]interface{}{"enable": "none"},
+					},
+				},
+				"indices.recovery.max_bytes_per_sec": "50mb",
+			},
+			expected: map[string]interface{}{
+				"cluster.routing.allocation.enable":  "primaries",
+				"cluster.routing.rebalance.enable":   "none",
+				"indices.recovery.max_bytes_per_sec": "50mb",
+			},
+		},
+		{
+			name:   "Prefix and nil values (reset) are kept",
+			prefix: "cluster",
+			settings: map[string]interface{}{
+				"max_shards_per_node": nil,
+				"routing":             map[string]interface{}{"allocation.enable": "all"},
+			},
+			expected: map[string]interface{}{
+				"cluster.max_shards_per_node":       nil,
+				"cluster.routing.allocation.enable": "all",
+			},
+		},
+		{
+			name:     "Empty nested map yields no keys",
+			settings: map[string]interface{}{"cluster": map[string]interface{}{}},
+			expected: map[string]interface{}{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, flattenSettings(tt.prefix, tt.settings))
+		})
+	}
+}
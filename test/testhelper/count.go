@@ -0,0 +1,112 @@
+package testhelper
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"github.com/stretchr/testify/require"
+)
+
+// aggregateTermsSize é o número máximo de buckets retornados por AggregateTerms
+const aggregateTermsSize = 10000
+
+// CountDocuments conta os documentos do índice com a API _count (refresh antes; índice inexistente conta zero)
+// query é a cláusula de query (ex.: {"term": {...}}) ou o corpo com "query"; nil conta todos
+func (s *IntegrationTestSuite) CountDocuments(index string, query map[string]interface{}) int {
+	s.t.Helper()
+	defer s.trackOperation("CountDocuments")()
+
+	require.NotNil(s.t, s.ES(), "Elasticsearch not configured")
+	index = s.RunScopedIndex(index)
+
+	count, err := s.countESDocuments(index, queryClause(query))
+	require.NoError(s.t, err, "Failed to count documents in %s", index)
+	return int(count)
+}
+
+// AssertDocCount verifica a quantidade de documentos do índice que casam com a query (nil = todos)
+func (s *IntegrationTestSuite) AssertDocCount(index string, query map[string]interface{}, expected int) {
+	s.t.Helper()
+
+	actual := s.CountDocuments(index, query)
+	require.Equal(s.t, expected, actual, "Unexpected document count in %s for query %s", index, formatStateFilter(queryClause(query)))
+}
+
+// AggregateTerms agrega os documentos da query (nil = todos) por field com uma terms aggregation e
+// retorna a contagem de documentos por valor. Campos text precisam do subcampo keyword (ex.: "category.keyword")
+func (s *IntegrationTestSuite) AggregateTerms(index, field string, query map[string]interface{}) map[string]int {
+	s.t.Helper()
+	defer s.trackOperation("AggregateTerms")()
+
+	client := s.ES()
+	require.NotNil(s.t, client, "Elasticsearch not configured")
+	index = s.RunScopedIndex(index)
+
+	body := map[string]interface{}{
+		"size": 0,
+		"aggs": map[string]interface{}{
+			"terms": map[string]interface{}{
+				"terms": map[string]interface{}{"field": field, "size": aggregateTermsSize},
+			},
+		},
+	}
+	if clause := queryClause(query); clause != nil {
+		body["query"] = clause
+	}
+	bodyJSON, err := json.Marshal(body)
+	require.NoError(s.t, err, "Failed to marshal aggregation")
+
+	s.refreshIndex(index)
+
+	res, err := esapi.SearchRequest{
+		Index: []string{index},
+		Body:  strings.NewReader(string(bodyJSON)),
+	}.Do(s.ctx, client)
+	require.NoError(s.t, err, "Failed to aggregate %s by %s", index, field)
+	defer res.Body.Close()
+
+	if res.IsError() {
+		require.Fail(s.t, fmt.Sprintf("Failed to aggregate %s by %s: %s", index, field, res.String()))
+	}
+
+	var response struct {
+		Aggregations struct {
+			Terms struct {
+				SumOtherDocCount int `json:"sum_other_doc_count"`
+				Buckets          []struct {
+					Key         interface{} `json:"key"`
+					KeyAsString string      `json:"key_as_string"`
+					DocCount    int         `json:"doc_count"`
+				} `json:"buckets"`
+			} `json:"terms"`
+		} `json:"aggregations"`
+	}
+	// UseNumber preserva chaves numéricas como no JSON ("1000000" em vez de "1e+06")
+	decoder := json.NewDecoder(res.Body)
+	decoder.UseNumber()
+	require.NoError(s.t, decoder.Decode(&response), "Failed to decode aggregation response")
+	require.Zero(s.t, response.Aggregations.Terms.SumOtherDocCount,
+		"More than %d distinct values of %s in %s: buckets were truncated", aggregateTermsSize, field, index)
+
+	buckets := make(map[string]int, len(response.Aggregations.Terms.Buckets))
+	for _, bucket := range response.Aggregations.Terms.Buckets {
+		key := bucket.KeyAsString
+		if key == "" {
+			key = fmt.Sprint(bucket.Key)
+		}
+		buckets[key] = bucket.DocCount
+	}
+	return buckets
+}
+
+// queryClause aceita a cláusula de query ou o corpo {"query": ...} e retorna a cláusula
+func queryClause(query map[string]interface{}) map[string]interface{} {
+	if len(query) == 1 {
+		if clause, ok := query["query"].(map[string]interface{}); ok {
+			return clause
+		}
+	}
+	return query
+}
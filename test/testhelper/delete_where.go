@@ -0,0 +1,69 @@
+package testhelper
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// DeleteMongoWhere remove os documentos da coleção que casam com o filtro e retorna quantos foram removidos
+// Filtro nil remove apenas os documentos do tenant da suite ({"tenant_id": <tenant>})
+func (s *IntegrationTestSuite) DeleteMongoWhere(collection string, filter interface{}) int64 {
+	s.t.Helper()
+	defer s.trackOperation("DeleteMongoWhere")()
+
+	db := s.Mongo()
+	require.NotNil(s.t, db, "MongoDB not configured")
+
+	if filter == nil {
+		filter = bson.M{"tenant_id": s.tenantID}
+	}
+
+	res, err := db.Collection(collection).DeleteMany(s.ctx, filter)
+	require.NoError(s.t, err, "Failed to delete documents from %s", collection)
+
+	if isDebugEnabled() {
+		fmt.Printf("🧹 Deleted %d document(s) from %s\n", res.DeletedCount, collection)
+	}
+	return res.DeletedCount
+}
+
+// DeletePostgresWhere executa DELETE na tabela com a condição informada e retorna o número de linhas removidas
+// A condição usa placeholders do PostgreSQL ($1, $2...) com os args; condição vazia remove apenas as
+// linhas do tenant da suite (coluna tenant_id)
+func (s *IntegrationTestSuite) DeletePostgresWhere(table, where string, args ...interface{}) int64 {
+	s.t.Helper()
+	defer s.trackOperation("DeletePostgresWhere")()
+
+	db := s.Postgres()
+	require.NotNil(s.t, db, "PostgreSQL not configured")
+
+	if strings.TrimSpace(where) == "" {
+		where = "tenant_id::text = $1"
+		args = []interface{}{s.tenantID}
+	}
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE %s", quoteQualifiedName(table), where)
+	res, err := db.ExecContext(s.ctx, query, args...)
+	require.NoError(s.t, err, "Failed to delete rows from %s", table)
+
+	affected, err := res.RowsAffected()
+	require.NoError(s.t, err, "Failed to get affected rows of %s", table)
+
+	if isDebugEnabled() {
+		fmt.Printf("🧹 Deleted %d row(s) from %s\n", affected, table)
+	}
+	return affected
+}
+
+// quoteQualifiedName faz o quoting de um nome de tabela, com schema opcional ("schema.tabela")
+func quoteQualifiedName(name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = pq.QuoteIdentifier(part)
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,249 @@
+package testhelper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"golang.org/x/mod/semver"
+)
+
+// Dependency identifica uma dependência compartilhada da suite
+type Dependency string
+
+// Dependências com suporte a verificação de versão
+const (
+	DepElasticsearch Dependency = "elasticsearch"
+	DepPostgres      Dependency = "postgres"
+	DepMongo         Dependency = "mongo"
+)
+
+// dependencyVersions guarda a versão reportada por cada dependência (consultada uma vez por processo)
+var dependencyVersions sync.Map
+
+// versionPattern extrai o primeiro número de versão de strings como "16.2 (Debian 16.2-1.pgdg120+2)"
+var versionPattern = regexp.MustCompile(`\d+(\.\d+){0,2}(-[0-9A-Za-z.-]+)?`)
+
+// versionConstraint é uma restrição já associada à dependência
+type versionConstraint struct {
+	dep        Dependency
+	constraint string
+}
+
+// RequireVersion faz o Build falhar se a dependência não satisfizer a restrição semver
+// Ex.: RequireVersion(DepElasticsearch, ">=8.0, <9")
+func (b *IntegrationTestSuiteBuilder) RequireVersion(dep Dependency, constraint string) *IntegrationTestSuiteBuilder {
+	b.versionConstraints = append(b.versionConstraints, versionConstraint{dep: dep, constraint: constraint})
+	return b
+}
+
+// AssertDependencyVersion verifica se a versão reportada pela dependência satisfaz a restrição
+// Aceita cláusulas separadas por vírgula com os operadores =, !=, >, >=, <, <=, ~ e ^
+// (ex.: ">=8.0, <9", "^16", "7.0"); a versão é consultada uma única vez por processo
+func (s *IntegrationTestSuite) AssertDependencyVersion(dep Dependency, constraint string) {
+	s.t.Helper()
+
+	version, err := s.DependencyVersion(dep)
+	require.NoError(s.t, err, "Failed to get %s version", dep)
+
+	ok, err := MatchVersion(version, constraint)
+	require.NoError(s.t, err, "Invalid version constraint %q", constraint)
+	require.True(s.t, ok, "%s version %s does not satisfy %q (check USE_EXTERNAL_* settings)", dep, version, constraint)
+}
+
+// DependencyVersion retorna a versão reportada pela dependência
+func (s *IntegrationTestSuite) DependencyVersion(dep Dependency) (string, error) {
+	if cached, ok := dependencyVersions.Load(dep); ok {
+		return cached.(string), nil
+	}
+
+	var (
+		version string
+		err     error
+	)
+	switch dep {
+	case DepElasticsearch:
+		version, err = s.elasticsearchVersion(s.ctx)
+	case DepPostgres:
+		version, err = s.postgresVersion(s.ctx)
+	case DepMongo:
+		version, err = s.mongoVersion(s.ctx)
+	default:
+		return "", fmt.Errorf("version check not supported for %s", dep)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	dependencyVersions.Store(dep, version)
+	return version, nil
+}
+
+// checkVersionConstraints valida as restrições registradas no builder
+func (s *IntegrationTestSuite) checkVersionConstraints(constraints []versionConstraint) error {
+	for _, c := range constraints {
+		version, err := s.DependencyVersion(c.dep)
+		if err != nil {
+			return fmt.Errorf("failed to get %s version: %w", c.dep, err)
+		}
+
+		ok, err := MatchVersion(version, c.constraint)
+		if err != nil {
+			return fmt.Errorf("invalid version constraint %q: %w", c.constraint, err)
+		}
+		if !ok {
+			return fmt.Errorf("%s version %s does not satisfy %q (check USE_EXTERNAL_* settings)", c.dep, version, c.constraint)
+		}
+	}
+	return nil
+}
+
+// elasticsearchVersion consulta version.number na raiz do cluster
+func (s *IntegrationTestSuite) elasticsearchVersion(ctx context.Context) (string, error) {
+	client := s.ES()
+	if client == nil {
+		return "", fmt.Errorf("elasticsearch not configured")
+	}
+
+	res, err := client.Info(client.Info.WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return "", fmt.Errorf("elasticsearch error: %s", res.Status())
+	}
+
+	var info struct {
+		Version struct {
+			Number string `json:"number"`
+		} `json:"version"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&info); err != nil {
+		return "", fmt.Errorf("failed to decode cluster info: %w", err)
+	}
+	return info.Version.Number, nil
+}
+
+// postgresVersion consulta server_version
+func (s *IntegrationTestSuite) postgresVersion(ctx context.Context) (string, error) {
+	db := s.Postgres()
+	if db == nil {
+		return "", fmt.Errorf("postgres not configured")
+	}
+
+	var version string
+	if err := db.QueryRowContext(ctx, "SHOW server_version").Scan(&version); err != nil {
+		return "", err
+	}
+	return version, nil
+}
+
+// mongoVersion consulta o comando buildInfo
+func (s *IntegrationTestSuite) mongoVersion(ctx context.Context) (string, error) {
+	db := s.Mongo()
+	if db == nil {
+		return "", fmt.Errorf("mongo not configured")
+	}
+
+	var info struct {
+		Version string `bson:"version"`
+	}
+	if err := db.RunCommand(ctx, bson.D{{Key: "buildInfo", Value: 1}}).Decode(&info); err != nil {
+		return "", err
+	}
+	return info.Version, nil
+}
+
+// MatchVersion verifica se version satisfaz todas as cláusulas da restrição
+func MatchVersion(version, constraint string) (bool, error) {
+	v, ok := canonicalVersion(version)
+	if !ok {
+		return false, fmt.Errorf("unparseable version %q", version)
+	}
+
+	for _, clause := range strings.Split(constraint, ",") {
+		clause = strings.TrimSpace(clause)
+		if clause == "" {
+			continue
+		}
+
+		op, operand := splitVersionOperator(clause)
+		target, ok := canonicalVersion(operand)
+		if !ok {
+			return false, fmt.Errorf("unparseable version %q in clause %q", operand, clause)
+		}
+		parts := len(strings.Split(strings.SplitN(strings.TrimPrefix(operand, "v"), "-", 2)[0], "."))
+
+		cmp := semver.Compare(v, target)
+		var matched bool
+		switch op {
+		case "=", "==":
+			// Versões parciais ("8", "8.2") casam com qualquer patch/minor
+			matched = truncateVersion(v, parts) == truncateVersion(target, parts)
+		case "!=":
+			matched = truncateVersion(v, parts) != truncateVersion(target, parts)
+		case ">":
+			matched = cmp > 0
+		case ">=":
+			matched = cmp >= 0
+		case "<":
+			matched = cmp < 0
+		case "<=":
+			matched = cmp <= 0
+		case "~":
+			// ~8.2.1 => >=8.2.1, <8.3.0
+			matched = cmp >= 0 && semver.MajorMinor(v) == semver.MajorMinor(target)
+		case "^":
+			// ^8.2 => >=8.2.0, <9.0.0
+			matched = cmp >= 0 && semver.Major(v) == semver.Major(target)
+		default:
+			return false, fmt.Errorf("unknown operator %q in clause %q", op, clause)
+		}
+
+		if !matched {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// splitVersionOperator separa o operador da versão de uma cláusula
+func splitVersionOperator(clause string) (string, string) {
+	for _, op := range []string{">=", "<=", "==", "!=", ">", "<", "=", "~", "^"} {
+		if strings.HasPrefix(clause, op) {
+			return op, strings.TrimSpace(strings.TrimPrefix(clause, op))
+		}
+	}
+	return "=", clause
+}
+
+// canonicalVersion normaliza a versão para o formato aceito por x/mod/semver (ex.: "16.2" -> "v16.2.0")
+func canonicalVersion(version string) (string, bool) {
+	match := versionPattern.FindString(version)
+	if match == "" {
+		return "", false
+	}
+
+	v := semver.Canonical("v" + match)
+	return v, v != ""
+}
+
+// truncateVersion mantém apenas os primeiros componentes (major, minor, patch) da versão canônica
+func truncateVersion(v string, parts int) string {
+	switch parts {
+	case 1:
+		return semver.Major(v)
+	case 2:
+		return semver.MajorMinor(v)
+	default:
+		return v
+	}
+}
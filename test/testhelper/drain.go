@@ -0,0 +1,163 @@
+package testhelper
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultDrainTimeout é o tempo máximo que o Teardown aguarda operações em andamento
+const defaultDrainTimeout = 5 * time.Second
+
+// operationTracker contabiliza operações de helpers e workers em andamento na suite
+// É compartilhado pelas cópias da suite (ForTest, WithTenant, WithContext)
+type operationTracker struct {
+	mu      sync.Mutex
+	nextID  uint64
+	active  map[uint64]trackedOperation
+	changed chan struct{}
+
+	// Contexto entregue aos workers iniciados com Go, cancelado ao final do Drain
+	workerCtx    context.Context
+	cancelWorker context.CancelFunc
+}
+
+// trackedOperation descreve uma operação em andamento
+type trackedOperation struct {
+	kind    string
+	name    string
+	started time.Time
+}
+
+// newOperationTracker cria o tracker de uma suite
+func newOperationTracker() *operationTracker {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &operationTracker{
+		active:       make(map[uint64]trackedOperation),
+		changed:      make(chan struct{}),
+		workerCtx:    ctx,
+		cancelWorker: cancel,
+	}
+}
+
+// begin registra uma operação e retorna a função que a encerra (idempotente)
+func (o *operationTracker) begin(kind, name string) func() {
+	o.mu.Lock()
+	o.nextID++
+	id := o.nextID
+	o.active[id] = trackedOperation{kind: kind, name: name, started: time.Now()}
+	o.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			o.mu.Lock()
+			delete(o.active, id)
+			close(o.changed)
+			o.changed = make(chan struct{})
+			o.mu.Unlock()
+		})
+	}
+}
+
+// snapshot retorna as operações em andamento e o canal sinalizado na próxima mudança
+func (o *operationTracker) snapshot() ([]trackedOperation, <-chan struct{}) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	ops := make([]trackedOperation, 0, len(o.active))
+	for _, op := range o.active {
+		ops = append(ops, op)
+	}
+	sort.Slice(ops, func(i, j int) bool { return ops[i].started.Before(ops[j].started) })
+	return ops, o.changed
+}
+
+// operations retorna o tracker da suite, criando-o se a suite foi montada sem construtor
+func (s *IntegrationTestSuite) operations() *operationTracker {
+	if s.ops == nil {
+		s.ops = newOperationTracker()
+	}
+	return s.ops
+}
+
+// trackOperation marca um helper da suite como em andamento até a função retornada ser chamada
+// Uso: defer s.trackOperation("IndexDocument")()
+func (s *IntegrationTestSuite) trackOperation(name string) func() {
+	return s.operations().begin("operation", name)
+}
+
+// RegisterWorker registra um worker (goroutine da aplicação ou do teste) que o Drain deve aguardar
+// Chame a função retornada quando o worker terminar
+func (s *IntegrationTestSuite) RegisterWorker(name string) func() {
+	return s.operations().begin("worker", name)
+}
+
+// Go executa fn numa goroutine registrada como worker
+// O contexto recebido é cancelado quando o Drain termina (inclusive por timeout)
+func (s *IntegrationTestSuite) Go(name string, fn func(ctx context.Context)) {
+	ops := s.operations()
+	done := ops.begin("worker", name)
+
+	go func() {
+		defer done()
+		fn(ops.workerCtx)
+	}()
+}
+
+// InFlight retorna quantas operações e workers estão em andamento
+func (s *IntegrationTestSuite) InFlight() int {
+	ops, _ := s.operations().snapshot()
+	return len(ops)
+}
+
+// Drain aguarda (até o timeout) as operações de helpers e os workers registrados terminarem
+// Retorna false e loga as operações pendentes se o timeout expirar; é chamado pelo Teardown
+func (s *IntegrationTestSuite) Drain(timeout time.Duration) bool {
+	s.t.Helper()
+
+	tracker := s.operations()
+	defer tracker.cancelWorker()
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	for {
+		pending, changed := tracker.snapshot()
+		if len(pending) == 0 {
+			return true
+		}
+
+		select {
+		case <-changed:
+		case <-deadline.C:
+			pending, _ = tracker.snapshot()
+			if len(pending) == 0 {
+				return true
+			}
+			s.t.Logf("⚠️ Drain timed out after %s with %d operation(s) still running:\n%s", timeout, len(pending), formatTrackedOperations(pending))
+			return false
+		}
+	}
+}
+
+// drainTimeout lê TEST_DRAIN_TIMEOUT (duração Go, ex.: "10s")
+func drainTimeout() time.Duration {
+	if v, err := time.ParseDuration(os.Getenv("TEST_DRAIN_TIMEOUT")); err == nil && v >= 0 {
+		return v
+	}
+	return defaultDrainTimeout
+}
+
+// formatTrackedOperations formata as operações pendentes para o log do Drain
+func formatTrackedOperations(ops []trackedOperation) string {
+	var b strings.Builder
+	for _, op := range ops {
+		fmt.Fprintf(&b, "  - %s %s (running for %s)\n", op.kind, op.name, time.Since(op.started).Round(time.Millisecond))
+	}
+	return b.String()
+}
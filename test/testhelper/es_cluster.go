@@ -0,0 +1,426 @@
+package testhelper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/network"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// Modo multi-node do Elasticsearch
+//
+// O container compartilhado é single-node; para testar tiering hot/warm e filtros de alocação a suite
+// sobe um cluster dedicado com um container por node, todos na mesma rede docker. Os atributos de node
+// (node.attr.*) são settings estáticas, definidas na criação do cluster por ESNodeSpec
+
+// ESNodeSpec descreve um node do cluster multi-node
+type ESNodeSpec struct {
+	Name       string
+	Attributes map[string]string // node.attr.<chave>=<valor> (ex.: {"data": "hot"})
+}
+
+// ShardAllocation é a alocação de uma cópia de shard (_cat/shards)
+type ShardAllocation struct {
+	Index   string `json:"index"`
+	Shard   string `json:"shard"`
+	Primary bool   `json:"-"`
+	PriRep  string `json:"prirep"`
+	State   string `json:"state"`
+	Node    string `json:"node"`
+}
+
+// ESCluster é um cluster Elasticsearch multi-node dedicado a um teste
+type ESCluster struct {
+	network *testcontainers.DockerNetwork
+	nodes   []testcontainers.Container
+	specs   []ESNodeSpec
+	client  *elasticsearch.Client
+	url     string
+}
+
+// StartESCluster sobe um cluster com um container por node e aguarda todos entrarem no cluster
+func StartESCluster(ctx context.Context, specs ...ESNodeSpec) (*ESCluster, error) {
+	if len(specs) < 2 {
+		return nil, fmt.Errorf("elasticsearch cluster needs at least two nodes")
+	}
+
+	if isDebugEnabled() {
+		fmt.Printf("🚀 Starting Elasticsearch cluster with %d nodes...\n", len(specs))
+	}
+
+	nw, err := network.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create elasticsearch cluster network: %w", err)
+	}
+
+	cluster := &ESCluster{network: nw, specs: specs, nodes: make([]testcontainers.Container, len(specs))}
+
+	names := make([]string, len(specs))
+	for i, spec := range specs {
+		names[i] = spec.Name
+	}
+
+	// Os nodes precisam subir juntos: nenhum conclui o bootstrap sem o quórum de initial_master_nodes
+	var wg sync.WaitGroup
+	errs := make([]error, len(specs))
+	for i, spec := range specs {
+		wg.Add(1)
+		go func(i int, spec ESNodeSpec) {
+			defer wg.Done()
+			cluster.nodes[i], errs[i] = startClusterNode(ctx, nw, spec, names)
+		}(i, spec)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			cluster.Terminate(context.Background())
+			return nil, err
+		}
+	}
+
+	endpoint, err := cluster.nodes[0].PortEndpoint(ctx, "9200/tcp", "http")
+	if err != nil {
+		cluster.Terminate(context.Background())
+		return nil, fmt.Errorf("failed to get elasticsearch cluster endpoint: %w", err)
+	}
+
+	client, err := elasticsearch.NewClient(elasticsearch.Config{Addresses: []string{endpoint}})
+	if err != nil {
+		cluster.Terminate(context.Background())
+		return nil, fmt.Errorf("failed to create elasticsearch cluster client: %w", err)
+	}
+	cluster.client = client
+	cluster.url = endpoint
+
+	res, err := client.Cluster.Health(
+		client.Cluster.Health.WithContext(ctx),
+		client.Cluster.Health.WithWaitForNodes(fmt.Sprint(len(specs))),
+		client.Cluster.Health.WithTimeout(2*time.Minute),
+	)
+	if err == nil {
+		defer res.Body.Close()
+		if res.IsError() {
+			err = fmt.Errorf("%s", res.String())
+		}
+	}
+	if err != nil {
+		cluster.Terminate(context.Background())
+		return nil, fmt.Errorf("elasticsearch cluster did not form: %w", err)
+	}
+
+	if isDebugEnabled() {
+		fmt.Printf("✅ Elasticsearch cluster started at %s (%s)\n", endpoint, strings.Join(names, ", "))
+	}
+	return cluster, nil
+}
+
+// startClusterNode inicia o container de um node do cluster
+func startClusterNode(ctx context.Context, nw *testcontainers.DockerNetwork, spec ESNodeSpec, names []string) (testcontainers.Container, error) {
+	env := map[string]string{
+		"ES_JAVA_OPTS":                 "-Xms256m -Xmx256m",
+		"node.name":                    spec.Name,
+		"cluster.name":                 "testhelper-cluster",
+		"discovery.seed_hosts":         strings.Join(names, ","),
+		"cluster.initial_master_nodes": strings.Join(names, ","),
+		"xpack.security.enabled":       "false",
+		"bootstrap.memory_lock":        "false",
+		// Evita que o watermark de disco do host bloqueie alocações durante os testes
+		"cluster.routing.allocation.disk.threshold_enabled": "false",
+	}
+	for key, value := range spec.Attributes {
+		env["node.attr."+key] = value
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:          "docker.elastic.co/elasticsearch/elasticsearch:8.2.0",
+			ExposedPorts:   []string{"9200/tcp"},
+			Env:            env,
+			Networks:       []string{nw.Name},
+			NetworkAliases: map[string][]string{nw.Name: {spec.Name}},
+			WaitingFor:     wait.ForLog("started").WithStartupTimeout(3 * time.Minute),
+		},
+		Started: true,
+	})
+	if err != nil {
+		return container, fmt.Errorf("failed to start elasticsearch node %s: %w", spec.Name, err)
+	}
+	return container, nil
+}
+
+// Client retorna o cliente do cluster (conectado ao primeiro node)
+func (c *ESCluster) Client() *elasticsearch.Client {
+	return c.client
+}
+
+// URL retorna a URL HTTP do primeiro node
+func (c *ESCluster) URL() string {
+	return c.url
+}
+
+// Nodes retorna os nomes dos nodes na ordem de criação
+func (c *ESCluster) Nodes() []string {
+	names := make([]string, len(c.specs))
+	for i, spec := range c.specs {
+		names[i] = spec.Name
+	}
+	return names
+}
+
+// Terminate remove os containers e a rede do cluster
+func (c *ESCluster) Terminate(ctx context.Context) error {
+	var errs []string
+	for _, node := range c.nodes {
+		if node == nil {
+			continue
+		}
+		if err := node.Terminate(ctx); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if c.network != nil {
+		if err := c.network.Remove(ctx); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to terminate elasticsearch cluster: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// NodeAttributes retorna os atributos de cada node (_cat/nodeattrs), sem os atributos internos do ES
+func (c *ESCluster) NodeAttributes(ctx context.Context) (map[string]map[string]string, error) {
+	var rows []struct {
+		Node  string `json:"node"`
+		Attr  string `json:"attr"`
+		Value string `json:"value"`
+	}
+	if err := c.catJSON(ctx, "/_cat/nodeattrs?format=json&h=node,attr,value", &rows); err != nil {
+		return nil, fmt.Errorf("failed to get node attributes: %w", err)
+	}
+
+	attributes := make(map[string]map[string]string)
+	for _, row := range rows {
+		if strings.HasPrefix(row.Attr, "ml.") || row.Attr == "xpack.installed" || row.Attr == "transform.node" {
+			continue
+		}
+		if attributes[row.Node] == nil {
+			attributes[row.Node] = make(map[string]string)
+		}
+		attributes[row.Node][row.Attr] = row.Value
+	}
+	return attributes, nil
+}
+
+// ShardAllocations retorna a alocação das cópias de shard do índice (_cat/shards)
+func (c *ESCluster) ShardAllocations(ctx context.Context, index string) ([]ShardAllocation, error) {
+	var shards []ShardAllocation
+	if err := c.catJSON(ctx, "/_cat/shards/"+index+"?format=json&h=index,shard,prirep,state,node", &shards); err != nil {
+		return nil, fmt.Errorf("failed to get shards of %s: %w", index, err)
+	}
+
+	for i := range shards {
+		shards[i].Primary = shards[i].PriRep == "p"
+	}
+	sort.Slice(shards, func(i, j int) bool {
+		if shards[i].Shard != shards[j].Shard {
+			return shards[i].Shard < shards[j].Shard
+		}
+		return shards[i].Primary && !shards[j].Primary
+	})
+	return shards, nil
+}
+
+// SetIndexAllocation define o filtro de alocação do índice (index.routing.allocation.<rule>.<attr>)
+// rule é "require", "include" ou "exclude"; valor vazio remove o filtro do atributo
+func (c *ESCluster) SetIndexAllocation(ctx context.Context, index, rule string, filters map[string]string) error {
+	settings := make(map[string]interface{}, len(filters))
+	for attr, value := range filters {
+		key := "index.routing.allocation." + rule + "." + attr
+		if value == "" {
+			settings[key] = nil
+		} else {
+			settings[key] = value
+		}
+	}
+
+	body, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("failed to marshal allocation settings: %w", err)
+	}
+
+	res, err := esapi.IndicesPutSettingsRequest{Index: []string{index}, Body: strings.NewReader(string(body))}.Do(ctx, c.client)
+	if err != nil {
+		return fmt.Errorf("failed to set allocation of %s: %w", index, err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("failed to set allocation of %s: %s", index, res.String())
+	}
+	return nil
+}
+
+// ExcludeNodes esvazia os nodes informados via cluster.routing.allocation.exclude._name (decommission)
+// Sem nomes, remove a exclusão
+func (c *ESCluster) ExcludeNodes(ctx context.Context, names ...string) error {
+	var value interface{}
+	if len(names) > 0 {
+		value = strings.Join(names, ",")
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"persistent": map[string]interface{}{"cluster.routing.allocation.exclude._name": value},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal cluster settings: %w", err)
+	}
+
+	res, err := esapi.ClusterPutSettingsRequest{Body: strings.NewReader(string(body))}.Do(ctx, c.client)
+	if err != nil {
+		return fmt.Errorf("failed to exclude nodes: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("failed to exclude nodes: %s", res.String())
+	}
+	return nil
+}
+
+// WaitForRelocation aguarda o índice não ter shards relocando nem inicializando
+func (c *ESCluster) WaitForRelocation(ctx context.Context, index string, timeout time.Duration) error {
+	res, err := c.client.Cluster.Health(
+		c.client.Cluster.Health.WithContext(ctx),
+		c.client.Cluster.Health.WithIndex(index),
+		c.client.Cluster.Health.WithWaitForNoRelocatingShards(true),
+		c.client.Cluster.Health.WithWaitForNoInitializingShards(true),
+		c.client.Cluster.Health.WithTimeout(timeout),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to wait for relocation of %s: %w", index, err)
+	}
+	defer res.Body.Close()
+
+	var health struct {
+		TimedOut bool `json:"timed_out"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&health); err != nil {
+		return fmt.Errorf("failed to decode cluster health: %w", err)
+	}
+	if res.IsError() || health.TimedOut {
+		return fmt.Errorf("shards of %s still relocating after %s", index, timeout)
+	}
+	return nil
+}
+
+// catJSON executa uma chamada _cat com format=json
+func (c *ESCluster) catJSON(ctx context.Context, path string, target interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.client.Perform(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("%s %s", res.Status, strings.TrimSpace(string(raw)))
+	}
+	return json.Unmarshal(raw, target)
+}
+
+// ESCluster sobe um cluster multi-node dedicado ao teste, removido ao final
+func (s *IntegrationTestSuite) ESCluster(specs ...ESNodeSpec) *ESCluster {
+	s.t.Helper()
+
+	cluster, err := StartESCluster(s.ctx, specs...)
+	require.NoError(s.t, err, "Failed to start Elasticsearch cluster")
+
+	s.t.Cleanup(func() {
+		if err := cluster.Terminate(context.Background()); err != nil && isDebugEnabled() {
+			fmt.Printf("⚠️  %v\n", err)
+		}
+	})
+	return cluster
+}
+
+// AssertShardsOnNodes aguarda a relocação e verifica que todas as cópias de shard do índice estão
+// alocadas (STARTED) apenas nos nodes informados
+func (s *IntegrationTestSuite) AssertShardsOnNodes(cluster *ESCluster, index string, nodes ...string) {
+	s.t.Helper()
+
+	allowed := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		allowed[node] = true
+	}
+
+	s.assertShardAllocation(cluster, index, func(shard ShardAllocation) bool {
+		return allowed[shard.Node]
+	}, fmt.Sprintf("nodes %v", nodes))
+}
+
+// AssertShardsOnAttribute aguarda a relocação e verifica que todas as cópias de shard do índice estão
+// em nodes com o atributo informado (ex.: "data" = "warm")
+func (s *IntegrationTestSuite) AssertShardsOnAttribute(cluster *ESCluster, index, attr, value string) {
+	s.t.Helper()
+
+	attributes, err := cluster.NodeAttributes(s.ctx)
+	require.NoError(s.t, err, "Failed to get node attributes")
+
+	s.assertShardAllocation(cluster, index, func(shard ShardAllocation) bool {
+		return attributes[shard.Node][attr] == value
+	}, fmt.Sprintf("nodes with %s=%s", attr, value))
+}
+
+// assertShardAllocation verifica a alocação até a relocação terminar ou o timeout de 1 minuto
+func (s *IntegrationTestSuite) assertShardAllocation(cluster *ESCluster, index string, ok func(ShardAllocation) bool, expected string) {
+	s.t.Helper()
+
+	var last []ShardAllocation
+	placed := func() bool {
+		shards, err := cluster.ShardAllocations(s.ctx, index)
+		if err != nil || len(shards) == 0 {
+			return false
+		}
+		last = shards
+		for _, shard := range shards {
+			if shard.State != "STARTED" || !ok(shard) {
+				return false
+			}
+		}
+		return true
+	}
+
+	deadline := time.Now().Add(time.Minute)
+	for !placed() {
+		if time.Now().After(deadline) {
+			require.Fail(s.t, fmt.Sprintf("Shards of %s not allocated on %s", index, expected), "allocation: %+v", last)
+			return
+		}
+		cluster.WaitForRelocation(s.ctx, index, 5*time.Second)
+		time.Sleep(200 * time.Millisecond)
+	}
+}
@@ -0,0 +1,151 @@
+package testhelper
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// Volume de dados do Elasticsearch
+//
+// Com TEST_ES_DATA_VOLUME=<nome>, o diretório de dados do container compartilhado é montado em um volume
+// Docker nomeado. O volume é criado direto pelo client Docker, sem os labels de sessão do testcontainers,
+// então o Ryuk não o remove e ele sobrevive à remoção do container (Ryuk, docker rm, troca de imagem):
+// corpora de fixtures caros, semeados com EnsureBaseline (índices baseline-*, preservados pela limpeza),
+// são reaproveitados entre execuções do go test. Use junto com TEST_CONTAINER_REUSE=true
+
+// ESDataPath é o diretório de dados do Elasticsearch no container
+const ESDataPath = "/usr/share/elasticsearch/data"
+
+// esDataVolume retorna o volume de dados configurado (vazio = sem volume)
+// O container seguro não usa o volume: o ES 8 só gera os certificados TLS quando o diretório de dados
+// está vazio, então um container novo sobre dados antigos subiria sem TLS
+func (s *SharedElasticsearch) esDataVolume() string {
+	if s.secure {
+		return ""
+	}
+	return strings.TrimSpace(os.Getenv("TEST_ES_DATA_VOLUME"))
+}
+
+// withDataVolume cria o volume de dados (se ainda não existe) e o monta na requisição do container
+// O nome do container inclui o volume para que um container reutilizado sem o volume (ou com outro)
+// não seja confundido com este
+func (s *SharedElasticsearch) withDataVolume(ctx context.Context, req *testcontainers.GenericContainerRequest) error {
+	name := s.esDataVolume()
+	if name == "" {
+		return nil
+	}
+
+	docker, err := testcontainers.NewDockerClientWithOpts(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create docker client: %w", err)
+	}
+	defer docker.Close()
+
+	// VolumeCreate é idempotente: um volume existente com o mesmo nome é retornado sem alteração
+	if _, err := docker.VolumeCreate(ctx, volume.CreateOptions{Name: name}); err != nil {
+		return fmt.Errorf("failed to create volume %s: %w", name, err)
+	}
+
+	// Montado pelo HostConfig, e não por req.Mounts: o testcontainers adiciona os labels de sessão aos
+	// volumes de req.Mounts, e o Ryuk remove o volume ao fim da sessão
+	req.Name = req.Name + "-" + indexNameInvalid.ReplaceAllString(name, "_")
+	req.HostConfigModifier = func(hostConfig *dockercontainer.HostConfig) {
+		hostConfig.Mounts = append(hostConfig.Mounts, mount.Mount{
+			Type:   mount.TypeVolume,
+			Source: name,
+			Target: ESDataPath,
+		})
+	}
+
+	if isDebugEnabled() {
+		fmt.Printf("💾 Elasticsearch data mounted on volume %s\n", name)
+	}
+	return nil
+}
+
+// ForceCleanVolume remove o container e o volume de dados e sobe um container novo, com o volume vazio
+// Saída de emergência para dados corrompidos ou obsoletos no volume (ex.: mapping de baseline antigo)
+// O refCount é mantido: as suites que chamaram Start continuam usando o container novo. Clients obtidos
+// antes com GetClient apontam para o container removido e devem ser obtidos de novo (clients com
+// TEST_RETRY_STALE_STATE reconectam sozinhos)
+func (s *SharedElasticsearch) ForceCleanVolume(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name := s.esDataVolume()
+	if name == "" {
+		return fmt.Errorf("no data volume configured: set TEST_ES_DATA_VOLUME")
+	}
+	if s.container == nil {
+		return fmt.Errorf("force clean not supported: elasticsearch is not managed by testcontainers")
+	}
+
+	if isDebugEnabled() {
+		fmt.Printf("🧹 Removing Elasticsearch container and data volume %s...\n", name)
+	}
+
+	if err := s.container.Terminate(ctx); err != nil {
+		return fmt.Errorf("failed to terminate elasticsearch container: %w", err)
+	}
+	s.container = nil
+	s.client = nil
+	s.started = false
+
+	docker, err := testcontainers.NewDockerClientWithOpts(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create docker client: %w", err)
+	}
+	defer docker.Close()
+
+	if err := docker.VolumeRemove(ctx, name, true); err != nil {
+		return fmt.Errorf("failed to remove volume %s: %w", name, err)
+	}
+
+	// Com o container parado, um Start concorrente não pode reaproveitar o estado antigo: o startOnce
+	// só é marcado de novo quando o container novo está de pé
+	s.startOnce = sync.Once{}
+	if err := s.setupTestcontainer(ctx); err != nil {
+		return err
+	}
+	s.started = true
+	s.startOnce.Do(func() {})
+	return nil
+}
+
+// ForceCleanVolume remove o volume de dados do Elasticsearch compartilhado e recria o container
+// (ver SharedElasticsearch.ForceCleanVolume). Afeta todas as suites do processo: chame no TestMain ou
+// em um teste não paralelo. O client desta suite é recriado; clients de outras suites do builder só
+// acompanham o container novo com WithStaleStateRetry
+func (s *IntegrationTestSuite) ForceCleanVolume() {
+	s.t.Helper()
+
+	shared := s.sharedES
+	if shared == nil && s.builder != nil {
+		shared = s.builder.sharedES
+	}
+	require.NotNil(s.t, shared, "Elasticsearch not configured")
+
+	err := shared.ForceCleanVolume(s.ctx)
+	require.NoError(s.t, err, "Failed to force clean Elasticsearch data volume")
+
+	client := shared.GetClient()
+	if s.builder != nil && s.builder.ESConn != nil {
+		s.builder.ESConn = s.builder.sharedESClient()
+		client = s.builder.ESConn
+	}
+	// O registro de requisições delega ao client anterior
+	if s.recordedES != nil {
+		if transport, ok := s.recordedES.Transport.(*recordingTransport); ok {
+			transport.base = client
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package testhelper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestESDataVolume(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      string
+		secure   bool
+		expected string
+	}{
+		{name: "Not configured", env: "", expected: ""},
+		{name: "Named volume", env: "es-data", expected: "es-data"},
+		{name: "Surrounding spaces are trimmed", env: "  es-data ", expected: "es-data"},
+		{name: "Secure container ignores the volume", env: "es-data", secure: true, expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TEST_ES_DATA_VOLUME", tt.env)
+			shared := &SharedElasticsearch{secure: tt.secure}
+
+			assert.Equal(t, tt.expected, shared.esDataVolume())
+		})
+	}
+}
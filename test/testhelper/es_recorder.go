@@ -0,0 +1,263 @@
+package testhelper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"github.com/stretchr/testify/require"
+)
+
+// maxRecordedBody limita o corpo guardado de cada requisição/resposta (bulks e buscas grandes)
+const maxRecordedBody = 64 * 1024
+
+// RawResponse é a resposta de ESRawRequest
+type RawResponse struct {
+	Status int
+	// Body é o corpo decodificado quando é um objeto JSON (nil para listas e texto, ex.: _cat)
+	Body map[string]interface{}
+	Raw  []byte
+}
+
+// Decode decodifica o corpo bruto em target
+func (r *RawResponse) Decode(target interface{}) error {
+	return json.Unmarshal(r.Raw, target)
+}
+
+// ESRawRequest envia uma requisição HTTP arbitrária ao Elasticsearch da suite, para APIs sem helper
+// body pode ser nil, string, []byte ou um valor serializado como JSON. Erros HTTP não falham o teste:
+// o status é retornado para a asserção. Nomes de índices no path não recebem o run scope (use IndexName)
+func (s *IntegrationTestSuite) ESRawRequest(method, path string, body interface{}) *RawResponse {
+	s.t.Helper()
+	defer s.trackOperation("ESRawRequest")()
+
+	client := s.ES()
+	require.NotNil(s.t, client, "Elasticsearch not configured")
+
+	var reader io.Reader
+	switch value := body.(type) {
+	case nil:
+	case string:
+		reader = strings.NewReader(value)
+	case []byte:
+		reader = bytes.NewReader(value)
+	default:
+		raw, err := json.Marshal(value)
+		require.NoError(s.t, err, "Failed to marshal body of %s %s", method, path)
+		reader = bytes.NewReader(raw)
+	}
+
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	req, err := http.NewRequestWithContext(s.ctx, method, path, reader)
+	require.NoError(s.t, err, "Invalid request %s %s", method, path)
+	if reader != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := client.Perform(req)
+	require.NoError(s.t, err, "Failed to perform %s %s", method, path)
+	defer res.Body.Close()
+
+	raw, err := io.ReadAll(res.Body)
+	require.NoError(s.t, err, "Failed to read response of %s %s", method, path)
+
+	response := &RawResponse{Status: res.StatusCode, Raw: raw}
+	var decoded map[string]interface{}
+	if json.Unmarshal(raw, &decoded) == nil {
+		response.Body = decoded
+	}
+	return response
+}
+
+// RecordedESRequest é uma requisição feita ao Elasticsearch pelo client da suite
+type RecordedESRequest struct {
+	Method       string
+	Path         string // path com query string
+	RequestBody  string
+	Status       int
+	ResponseBody string
+	Duration     time.Duration
+	Err          error
+	At           time.Time
+}
+
+// ESRequestRecorder armazena as requisições feitas pelo client da suite (RecordESRequests)
+type ESRequestRecorder struct {
+	t        TestingT
+	mu       sync.Mutex
+	requests []RecordedESRequest
+}
+
+// Requests retorna uma cópia das requisições registradas, na ordem de execução
+func (r *ESRequestRecorder) Requests() []RecordedESRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]RecordedESRequest(nil), r.requests...)
+}
+
+// Matching retorna as requisições cujo "MÉTODO path" casa com o padrão (regexp, ex.: `POST /.*/_search`)
+// Um padrão inválido falha o teste
+func (r *ESRequestRecorder) Matching(pattern string) []RecordedESRequest {
+	r.t.Helper()
+
+	re, err := regexp.Compile(pattern)
+	require.NoError(r.t, err, "Invalid request pattern %q", pattern)
+
+	var matched []RecordedESRequest
+	for _, req := range r.Requests() {
+		if re.MatchString(req.Method + " " + req.Path) {
+			matched = append(matched, req)
+		}
+	}
+	return matched
+}
+
+// Reset descarta as requisições registradas
+func (r *ESRequestRecorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = nil
+}
+
+// Dump escreve as requisições e respostas registradas em formato legível
+func (r *ESRequestRecorder) Dump(w io.Writer) error {
+	for i, req := range r.Requests() {
+		status := fmt.Sprint(req.Status)
+		if req.Err != nil {
+			status = req.Err.Error()
+		}
+		if _, err := fmt.Fprintf(w, "### %d %s %s -> %s (%s) at %s\n", i+1, req.Method, req.Path, status,
+			req.Duration.Round(time.Microsecond), req.At.Format(time.RFC3339Nano)); err != nil {
+			return err
+		}
+		if req.RequestBody != "" {
+			fmt.Fprintf(w, "%s\n", req.RequestBody)
+		}
+		if req.ResponseBody != "" {
+			fmt.Fprintf(w, "--> %s\n", req.ResponseBody)
+		}
+		fmt.Fprintln(w)
+	}
+	return nil
+}
+
+// record adiciona uma requisição ao registro
+func (r *ESRequestRecorder) record(req RecordedESRequest) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req)
+}
+
+// RecordESRequests passa a registrar todas as requisições feitas pelo client da suite (s.ES() e todos os
+// helpers). Se o teste falhar, o registro é gravado em dir/<nome do teste>.es-requests.log, ou no log do
+// teste quando dir é vazio. Clients criados pela aplicação fora da suite não são registrados
+func (s *IntegrationTestSuite) RecordESRequests(dir string) *ESRequestRecorder {
+	s.t.Helper()
+
+	if s.esRecorder != nil {
+		return s.esRecorder
+	}
+
+	base := s.ES()
+	require.NotNil(s.t, base, "Elasticsearch not configured")
+
+	recorder := &ESRequestRecorder{t: s.t}
+	transport := &recordingTransport{base: base, recorder: recorder}
+	s.recordedES = &elasticsearch.Client{
+		BaseClient: elasticsearch.BaseClient{Transport: transport},
+		API:        esapi.New(transport),
+	}
+	s.esRecorder = recorder
+
+	s.t.Cleanup(func() {
+		s.recordedES = nil
+		if !s.t.Failed() {
+			return
+		}
+
+		var dump bytes.Buffer
+		_ = recorder.Dump(&dump)
+		if dir == "" {
+			s.t.Logf("📼 Elasticsearch requests of %s:\n%s", s.t.Name(), dump.String())
+			return
+		}
+
+		path := filepath.Join(dir, invalidFixtureChars.ReplaceAllString(s.t.Name(), "_")+".es-requests.log")
+		if err := writeRawFile(path, dump.Bytes()); err != nil {
+			s.t.Logf("⚠️  Failed to write Elasticsearch requests: %v", err)
+			return
+		}
+		s.t.Logf("📼 %d Elasticsearch requests recorded at %s", len(recorder.Requests()), path)
+	})
+
+	return recorder
+}
+
+// ESRequestRecorder retorna o registro de requisições (nil se RecordESRequests não foi chamado)
+func (s *IntegrationTestSuite) ESRequestRecorder() *ESRequestRecorder {
+	return s.esRecorder
+}
+
+// recordingTransport registra as requisições e delega ao client original (que mantém o transport,
+// os headers e o product check configurados)
+type recordingTransport struct {
+	base     *elasticsearch.Client
+	recorder *ESRequestRecorder
+}
+
+// Perform implementa esapi.Transport
+func (t *recordingTransport) Perform(req *http.Request) (*http.Response, error) {
+	entry := RecordedESRequest{Method: req.Method, Path: req.URL.RequestURI(), At: time.Now()}
+
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		entry.RequestBody = truncateRecordedBody(body)
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+
+	res, err := t.base.Perform(req)
+	entry.Duration = time.Since(entry.At)
+	entry.Err = err
+
+	if err == nil {
+		entry.Status = res.StatusCode
+		body, readErr := io.ReadAll(res.Body)
+		res.Body.Close()
+		if readErr != nil {
+			entry.Err = readErr
+			t.recorder.record(entry)
+			return nil, readErr
+		}
+		entry.ResponseBody = truncateRecordedBody(body)
+		res.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	t.recorder.record(entry)
+	return res, err
+}
+
+// truncateRecordedBody limita o corpo a maxRecordedBody bytes
+func truncateRecordedBody(body []byte) string {
+	if len(body) <= maxRecordedBody {
+		return string(body)
+	}
+	return fmt.Sprintf("%s... (%d bytes truncated)", body[:maxRecordedBody], len(body)-maxRecordedBody)
+}
@@ -0,0 +1,180 @@
+package testhelper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/stretchr/testify/require"
+)
+
+// RestartOptions configura RestartElasticsearchWithOptions
+type RestartOptions struct {
+	// Indices são os índices verificados (padrão: todos os índices que não são de sistema)
+	Indices []string
+	// Hard mata o processo sem shutdown gracioso, validando que escritas confirmadas estão no translog
+	Hard bool
+	// Timeout limita a espera pela recuperação dos índices (padrão: 2 minutos)
+	Timeout time.Duration
+}
+
+// RestartElasticsearchAndWait reinicia o container do Elasticsearch, aguarda os índices voltarem a
+// green/yellow e verifica que a contagem de documentos sobreviveu ao restart
+// Retorna a contagem por índice. Afeta todos os testes que compartilham o container: não usar com t.Parallel()
+func (s *IntegrationTestSuite) RestartElasticsearchAndWait(indices ...string) map[string]int64 {
+	s.t.Helper()
+	return s.RestartElasticsearchWithOptions(RestartOptions{Indices: indices})
+}
+
+// RestartElasticsearchWithOptions é o RestartElasticsearchAndWait com restart hard e timeout configuráveis
+func (s *IntegrationTestSuite) RestartElasticsearchWithOptions(opts RestartOptions) map[string]int64 {
+	s.t.Helper()
+
+	if opts.Timeout == 0 {
+		opts.Timeout = 2 * time.Minute
+	}
+
+	shared := s.sharedES
+	if s.builder != nil && s.builder.sharedES != nil {
+		shared = s.builder.sharedES
+	}
+	require.NotNil(s.t, shared, "Elasticsearch not configured")
+
+	client := s.ES()
+	require.NotNil(s.t, client, "Elasticsearch not configured")
+
+	indices := opts.Indices
+	if len(indices) == 0 {
+		var err error
+		indices, err = userIndices(s.ctx, client)
+		require.NoError(s.t, err, "Failed to list indices before restart")
+	}
+
+	// O refresh só torna os documentos visíveis para a contagem; não faz flush nem fsync
+	before := make(map[string]int64, len(indices))
+	for _, index := range indices {
+		s.refreshIndex(index)
+		before[index] = s.countDocuments(client, index)
+	}
+
+	err := shared.Restart(s.ctx, opts.Hard)
+	require.NoError(s.t, err, "Failed to restart Elasticsearch")
+
+	client = shared.GetClient()
+	if s.builder != nil {
+		s.builder.ESConn = client
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, opts.Timeout)
+	defer cancel()
+
+	err = waitForIndicesRecovery(ctx, client, indices)
+	require.NoError(s.t, err, "Indices did not recover after restart")
+
+	after := make(map[string]int64, len(indices))
+	for _, index := range indices {
+		s.refreshIndex(index)
+		after[index] = s.countDocuments(client, index)
+		require.Equal(s.t, before[index], after[index],
+			"Document count of index %s changed across restart (before=%d, after=%d)", index, before[index], after[index])
+	}
+
+	return after
+}
+
+// countDocuments retorna o número de documentos do índice
+func (s *IntegrationTestSuite) countDocuments(client *elasticsearch.Client, index string) int64 {
+	s.t.Helper()
+
+	res, err := client.Count(client.Count.WithContext(s.ctx), client.Count.WithIndex(index))
+	require.NoError(s.t, err, "Failed to count documents of %s", index)
+	defer res.Body.Close()
+
+	require.False(s.t, res.IsError(), "Failed to count documents of %s: %s", index, res.String())
+
+	var body struct {
+		Count int64 `json:"count"`
+	}
+	require.NoError(s.t, json.NewDecoder(res.Body).Decode(&body), "Failed to decode count of %s", index)
+	return body.Count
+}
+
+// userIndices lista os índices que não são de sistema (sem prefixo ".")
+func userIndices(ctx context.Context, client *elasticsearch.Client) ([]string, error) {
+	res, err := client.Cat.Indices(
+		client.Cat.Indices.WithContext(ctx),
+		client.Cat.Indices.WithFormat("json"),
+		client.Cat.Indices.WithH("index"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, fmt.Errorf("elasticsearch error: %s", res.String())
+	}
+
+	var rows []struct {
+		Index string `json:"index"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&rows); err != nil {
+		return nil, fmt.Errorf("failed to decode indices: %w", err)
+	}
+
+	indices := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if !strings.HasPrefix(row.Index, ".") {
+			indices = append(indices, row.Index)
+		}
+	}
+	return indices, nil
+}
+
+// waitForIndicesRecovery aguarda os índices ficarem green/yellow (primários alocados e recuperados)
+func waitForIndicesRecovery(ctx context.Context, client *elasticsearch.Client, indices []string) error {
+	for {
+		status, err := indicesHealth(ctx, client, indices)
+		if err == nil && (status == "green" || status == "yellow") {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			if err == nil {
+				err = fmt.Errorf("cluster health is %s", status)
+			}
+			return fmt.Errorf("timed out waiting for index recovery: %w", err)
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+// indicesHealth retorna o status de saúde dos índices (ou do cluster se a lista for vazia)
+func indicesHealth(ctx context.Context, client *elasticsearch.Client, indices []string) (string, error) {
+	res, err := client.Cluster.Health(
+		client.Cluster.Health.WithContext(ctx),
+		client.Cluster.Health.WithIndex(indices...),
+		client.Cluster.Health.WithWaitForStatus("yellow"),
+		client.Cluster.Health.WithTimeout(5*time.Second),
+	)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.IsError() && res.StatusCode != 408 {
+		return "", fmt.Errorf("elasticsearch error: %s", res.String())
+	}
+
+	var body struct {
+		Status string `json:"status"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode cluster health: %w", err)
+	}
+	return body.Status, nil
+}
@@ -39,7 +39,7 @@ EXEMPLOS DE USO
        builder := testhelper.NewTestDependenciesBuilder()
        deps, err := builder.WithPostgres("path/to/init.sql").Build()
        require.NoError(t, err)
-       defer deps.Cleanup()
+       t.Cleanup(func() { deps.CleanupT(t) })
        
        // Use deps.PostgresConn
        db := deps.PostgresConn
@@ -68,7 +68,7 @@ EXEMPLOS DE USO
        builder := testhelper.NewTestDependenciesBuilder()
        deps, err := builder.WithMongo().WithElasticsearch().Build()
        require.NoError(t, err)
-       defer deps.Cleanup()
+       t.Cleanup(func() { deps.CleanupT(t) })
        
        // Use deps.MongoConn, deps.ESConn
        mongoDB := deps.MongoConn
@@ -125,7 +125,7 @@ EXEMPLOS DE USO
        builder := testhelper.NewTestDependenciesBuilder()
        deps, err := builder.WithMongo().WithPostgres().Build()
        require.NoError(t, err)
-       defer deps.Cleanup()
+       t.Cleanup(func() { deps.CleanupT(t) })
        
        // Reset específico de coleções MongoDB (como no builder original)
        err = deps.ResetSpecificMongoCollections(ctx) // dw_surveys, ext_tickets, ext_boards
@@ -213,6 +213,7 @@ PG_URL="host=localhost port=5432 user=test password=test sslmode=disable"
 # Debugging e Comportamento
 DEBUG_TEST_CONTAINERS=true     # Ativa logs detalhados
 TEST_CONTAINER_REUSE=true      # Reutiliza containers (padrão: true)
+TEST_ES_DATA_VOLUME=es-data    # Dados do ES em volume nomeado, mantidos entre execuções
 
 ========================================================================================
 MIGRAÇÃO DO test/builder
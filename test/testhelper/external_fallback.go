@@ -0,0 +1,124 @@
+package testhelper
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// Fallback de instâncias externas indisponíveis
+//
+// Com USE_EXTERNAL_* ligado e o endpoint inacessível, a política define o que acontece: falhar (padrão),
+// pular os testes que dependem da instância ou iniciar um container no lugar dela. A política vem de
+// TEST_EXTERNAL_FALLBACK_<DEP> (ex.: TEST_EXTERNAL_FALLBACK_ES), de TEST_EXTERNAL_FALLBACK ou do
+// WithExternalFallback do builder, nessa ordem. O modo escolhido é sempre logado
+
+// ExternalFallbackPolicy é a política aplicada quando uma instância externa está inacessível
+type ExternalFallbackPolicy string
+
+const (
+	// ExternalFallbackFail falha a inicialização das dependências (comportamento padrão)
+	ExternalFallbackFail ExternalFallbackPolicy = "fail"
+	// ExternalFallbackSkip pula o teste (t.Skip) que depende da instância
+	ExternalFallbackSkip ExternalFallbackPolicy = "skip"
+	// ExternalFallbackContainer inicia um container no lugar da instância externa
+	ExternalFallbackContainer ExternalFallbackPolicy = "container"
+)
+
+// externalFallbackOverride é a política definida pelo builder (vazia usa o ambiente)
+var externalFallbackOverride atomic.Value
+
+// ExternalUnavailableError indica que a instância externa de uma dependência está inacessível
+type ExternalUnavailableError struct {
+	Dependency string
+	Endpoint   string
+	Policy     ExternalFallbackPolicy
+	Err        error
+}
+
+// Error implementa error
+func (e *ExternalUnavailableError) Error() string {
+	return fmt.Sprintf("external %s at %s is unreachable (fallback policy %q): %v", e.Dependency, e.Endpoint, e.Policy, e.Err)
+}
+
+// Unwrap retorna o erro de conexão original
+func (e *ExternalUnavailableError) Unwrap() error {
+	return e.Err
+}
+
+// Skip indica que os testes que dependem da instância devem ser pulados
+func (e *ExternalUnavailableError) Skip() bool {
+	return e.Policy == ExternalFallbackSkip
+}
+
+// parseExternalFallbackPolicy valida o nome da política
+func parseExternalFallbackPolicy(value string) (ExternalFallbackPolicy, error) {
+	switch policy := ExternalFallbackPolicy(strings.ToLower(strings.TrimSpace(value))); policy {
+	case ExternalFallbackFail, ExternalFallbackSkip, ExternalFallbackContainer:
+		return policy, nil
+	}
+	return "", fmt.Errorf("invalid external fallback policy %q (expected fail, skip or container)", value)
+}
+
+// setExternalFallbackPolicy define a política usada quando o ambiente não define uma
+func setExternalFallbackPolicy(policy ExternalFallbackPolicy) {
+	externalFallbackOverride.Store(policy)
+}
+
+// externalFallbackPolicy resolve a política da dependência (useExternalEnv é a variável USE_EXTERNAL_*)
+func externalFallbackPolicy(useExternalEnv string) (ExternalFallbackPolicy, error) {
+	dependencyEnv := "TEST_EXTERNAL_FALLBACK_" + strings.TrimPrefix(useExternalEnv, "USE_EXTERNAL_")
+	for _, env := range []string{dependencyEnv, "TEST_EXTERNAL_FALLBACK"} {
+		if value := os.Getenv(env); value != "" {
+			policy, err := parseExternalFallbackPolicy(value)
+			if err != nil {
+				return "", fmt.Errorf("%s: %w", env, err)
+			}
+			return policy, nil
+		}
+	}
+
+	if policy, ok := externalFallbackOverride.Load().(ExternalFallbackPolicy); ok && policy != "" {
+		return policy, nil
+	}
+	return ExternalFallbackFail, nil
+}
+
+// externalFallback aplica a política quando a conexão com a instância externa falhou: retorna um
+// *ExternalUnavailableError (fail/skip) ou o resultado de launch, que inicia o container no lugar dela
+func externalFallback(dependency, useExternalEnv, endpoint string, err error, launch func() error) error {
+	policy, policyErr := externalFallbackPolicy(useExternalEnv)
+	if policyErr != nil {
+		return fmt.Errorf("failed to connect to external %s: %w (%v)", dependency, err, policyErr)
+	}
+
+	unavailable := &ExternalUnavailableError{Dependency: dependency, Endpoint: endpoint, Policy: policy, Err: err}
+	switch policy {
+	case ExternalFallbackContainer:
+		log.Printf("⚠️  External %s at %s is unreachable (%v); %s set, falling back to a container", dependency, endpoint, err, useExternalEnv)
+		if err := launch(); err != nil {
+			return fmt.Errorf("%v; container fallback failed: %w", unavailable, err)
+		}
+		return nil
+	case ExternalFallbackSkip:
+		log.Printf("⚠️  External %s at %s is unreachable (%v); skipping the tests that depend on it", dependency, endpoint, err)
+	default:
+		log.Printf("❌ External %s at %s is unreachable (%v); failing (set TEST_EXTERNAL_FALLBACK=skip or container to change this)", dependency, endpoint, err)
+	}
+	return unavailable
+}
+
+// initializationErrors agrega os erros do Build preservando-os para errors.As
+type initializationErrors []error
+
+// Error mantém a mensagem "initialization errors: [...]"
+func (e initializationErrors) Error() string {
+	return fmt.Sprintf("initialization errors: %v", []error(e))
+}
+
+// Unwrap expõe os erros agregados
+func (e initializationErrors) Unwrap() []error {
+	return e
+}
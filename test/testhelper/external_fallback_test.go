@@ -0,0 +1,124 @@
+package testhelper
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExternalFallbackPolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		dependency string
+		global     string
+		override   ExternalFallbackPolicy
+		expected   ExternalFallbackPolicy
+		wantErr    bool
+	}{
+		{
+			name:     "Defaults to fail",
+			expected: ExternalFallbackFail,
+		},
+		{
+			name:     "Builder override",
+			override: ExternalFallbackContainer,
+			expected: ExternalFallbackContainer,
+		},
+		{
+			name:     "Global env wins over the builder",
+			global:   "skip",
+			override: ExternalFallbackContainer,
+			expected: ExternalFallbackSkip,
+		},
+		{
+			name:       "Dependency env wins over the global env",
+			dependency: "container",
+			global:     "skip",
+			expected:   ExternalFallbackContainer,
+		},
+		{
+			name:       "Value is case and space insensitive",
+			dependency: " Skip ",
+			expected:   ExternalFallbackSkip,
+		},
+		{
+			name:    "Invalid value",
+			global:  "retry",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TEST_EXTERNAL_FALLBACK_ES", tt.dependency)
+			t.Setenv("TEST_EXTERNAL_FALLBACK", tt.global)
+			useExternalFallbackPolicy(t, tt.override)
+
+			policy, err := externalFallbackPolicy("USE_EXTERNAL_ES")
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "TEST_EXTERNAL_FALLBACK")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, policy)
+		})
+	}
+}
+
+func TestExternalFallback(t *testing.T) {
+	connErr := errors.New("connection refused")
+
+	tests := []struct {
+		policy    string
+		launchErr error
+		launched  bool
+		skip      bool
+		wantErr   bool
+	}{
+		{policy: "fail", wantErr: true},
+		{policy: "skip", skip: true, wantErr: true},
+		{policy: "container", launched: true},
+		{policy: "container", launchErr: errors.New("no docker"), launched: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			t.Setenv("TEST_EXTERNAL_FALLBACK_ES", "")
+			t.Setenv("TEST_EXTERNAL_FALLBACK", tt.policy)
+
+			launched := false
+			err := externalFallback("Elasticsearch", "USE_EXTERNAL_ES", "http://localhost:9200", connErr, func() error {
+				launched = true
+				return tt.launchErr
+			})
+
+			assert.Equal(t, tt.launched, launched)
+			if !tt.wantErr {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			if tt.launchErr != nil {
+				assert.ErrorIs(t, err, tt.launchErr)
+				assert.Contains(t, err.Error(), connErr.Error())
+				return
+			}
+
+			var unavailable *ExternalUnavailableError
+			require.ErrorAs(t, err, &unavailable)
+			assert.ErrorIs(t, err, connErr)
+			assert.Equal(t, tt.skip, unavailable.Skip())
+		})
+	}
+}
+
+// useExternalFallbackPolicy define a política do builder durante o teste
+func useExternalFallbackPolicy(t *testing.T, policy ExternalFallbackPolicy) {
+	t.Helper()
+
+	setExternalFallbackPolicy(policy)
+	t.Cleanup(func() { setExternalFallbackPolicy("") })
+}
@@ -0,0 +1,45 @@
+package testhelper
+
+import (
+	"os"
+	"strconv"
+)
+
+// fakesEnvVar permite desligar os fakes (ex.: no CI) sem alterar os testes: TEST_FAKES=false
+const fakesEnvVar = "TEST_FAKES"
+
+// WithFakes faz a suite rodar com implementações em memória em vez de containers
+// Os mesmos corpos de teste rodam como testes unitários rápidos localmente e como integração
+// no CI (TEST_FAKES=false). Nenhuma dependência do builder é iniciada enquanto os fakes estão ativos
+func (b *IntegrationTestSuiteBuilder) WithFakes() *IntegrationTestSuiteBuilder {
+	b.useFakes = fakesEnabled()
+	return b
+}
+
+// fakesEnabled verifica se os fakes não foram desligados pelo ambiente
+func fakesEnabled() bool {
+	value := os.Getenv(fakesEnvVar)
+	if value == "" {
+		return true
+	}
+	enabled, err := strconv.ParseBool(value)
+	return err != nil || enabled
+}
+
+// UsesFakes indica se a suite está usando implementações em memória
+func (s *IntegrationTestSuite) UsesFakes() bool {
+	return s.fakes
+}
+
+// FakeOr retorna a implementação fake quando a suite usa fakes, ou a real caso contrário
+// Uso:
+//
+//	repo := testhelper.FakeOr(suite,
+//	    func() repository.ProductRepositoryInterface { return repository.NewMemoryProductRepository() },
+//	    func() repository.ProductRepositoryInterface { return repository.NewProductRepositoryWithIndex(suite.ES(), suite.IndexName("products")) })
+func FakeOr[T any](s *IntegrationTestSuite, fake func() T, real func() T) T {
+	if s.UsesFakes() {
+		return fake()
+	}
+	return real()
+}
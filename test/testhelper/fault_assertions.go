@@ -0,0 +1,67 @@
+package testhelper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// timeoutGrace é a tolerância sobre o timeout no AssertRespectsTimeout (agendamento, limpeza do chamador)
+const timeoutGrace = 200 * time.Millisecond
+
+// FaultRecorder é implementado pelos decorators de injeção de falhas (ex.: service.FaultyProductService),
+// expondo quantas chamadas cada método recebeu e quantas falharam por injeção
+type FaultRecorder interface {
+	Calls(method string) int
+	Injected(method string) int
+}
+
+// AssertRecoveredFromFault verifica que o chamador se recuperou das falhas injetadas no método:
+// a operação terminou sem erro, houve ao menos uma falha e ao menos uma nova tentativa depois dela
+func (s *IntegrationTestSuite) AssertRecoveredFromFault(recorder FaultRecorder, method string, err error) {
+	s.t.Helper()
+
+	calls, injected := recorder.Calls(method), recorder.Injected(method)
+	require.NoError(s.t, err, "Caller did not recover from injected faults on %s (%d calls, %d injected)", method, calls, injected)
+	require.Positive(s.t, injected, "No fault was injected on %s, check the fault configuration", method)
+	require.Greater(s.t, calls, injected, "Caller did not retry %s after %d injected fault(s)", method, injected)
+}
+
+// AssertFaultSurfaced verifica que o chamador desistiu e propagou a falha: o erro retornado é (ou embrulha)
+// target e o método foi chamado no máximo maxAttempts vezes (retry limitado)
+func (s *IntegrationTestSuite) AssertFaultSurfaced(recorder FaultRecorder, method string, err, target error, maxAttempts int) {
+	s.t.Helper()
+
+	calls := recorder.Calls(method)
+	require.Error(s.t, err, "Caller swallowed the injected faults on %s (%d calls)", method, calls)
+	require.ErrorIs(s.t, err, target, "Caller did not propagate the injected fault on %s", method)
+	if maxAttempts > 0 && calls > maxAttempts {
+		require.Fail(s.t, fmt.Sprintf("Caller retried %s %d times, expected at most %d attempts", method, calls, maxAttempts))
+	}
+}
+
+// AssertRespectsTimeout executa fn com um contexto de prazo timeout e verifica que ela retorna
+// context.DeadlineExceeded sem ultrapassar o prazo (além de uma pequena tolerância), ou seja, que o
+// chamador propaga o contexto em vez de esperar a dependência lenta. Retorna o erro de fn
+func (s *IntegrationTestSuite) AssertRespectsTimeout(timeout time.Duration, fn func(ctx context.Context) error) error {
+	s.t.Helper()
+	defer s.trackOperation("AssertRespectsTimeout")()
+
+	ctx, cancel := context.WithTimeout(s.ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := fn(ctx)
+	elapsed := time.Since(start)
+
+	if elapsed > timeout+timeoutGrace {
+		require.Fail(s.t, fmt.Sprintf("Call took %s, exceeding the %s timeout", elapsed.Round(time.Millisecond), timeout))
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		require.Fail(s.t, fmt.Sprintf("Expected context.DeadlineExceeded after %s, got %v", timeout, err))
+	}
+	return err
+}
@@ -0,0 +1,176 @@
+package testhelper
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// FaultRule descreve uma falha injetada pelo FaultProxy
+// Campos vazios correspondem a qualquer requisição; Times <= 0 aplica a regra indefinidamente
+type FaultRule struct {
+	Method     string
+	PathPrefix string
+	StatusCode int
+	Body       string
+	Latency    time.Duration
+	Times      int
+}
+
+// matches verifica se a regra se aplica à requisição
+func (r *FaultRule) matches(req *http.Request) bool {
+	if r.Method != "" && !strings.EqualFold(r.Method, req.Method) {
+		return false
+	}
+	return r.PathPrefix == "" || strings.HasPrefix(req.URL.Path, r.PathPrefix)
+}
+
+// FaultProxy é um proxy reverso HTTP com injeção programável de status code e latência
+// Usado para validar a resiliência da aplicação a falhas de serviços externos
+type FaultProxy struct {
+	server *httptest.Server
+	proxy  *httputil.ReverseProxy
+	target *url.URL
+
+	mu       sync.Mutex
+	rules    []*FaultRule
+	total    int
+	injected int
+}
+
+// FaultProxy inicia um proxy para targetURL e retorna-o; a aplicação deve usar proxy.URL() no lugar do alvo
+// O proxy é encerrado automaticamente ao final do teste
+func (s *IntegrationTestSuite) FaultProxy(targetURL string) *FaultProxy {
+	s.t.Helper()
+
+	target, err := url.Parse(targetURL)
+	require.NoError(s.t, err, "Invalid fault proxy target %s", targetURL)
+
+	p := &FaultProxy{
+		target: target,
+		proxy:  httputil.NewSingleHostReverseProxy(target),
+	}
+	p.server = httptest.NewServer(http.HandlerFunc(p.handle))
+	s.t.Cleanup(p.server.Close)
+
+	return p
+}
+
+// URL retorna a URL do proxy
+func (p *FaultProxy) URL() string {
+	return p.server.URL
+}
+
+// AddRule registra uma regra de falha; regras são avaliadas na ordem de registro
+func (p *FaultProxy) AddRule(rule FaultRule) *FaultProxy {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.rules = append(p.rules, &rule)
+	return p
+}
+
+// InjectStatus faz todas as requisições retornarem o status informado
+func (p *FaultProxy) InjectStatus(statusCode int) *FaultProxy {
+	return p.AddRule(FaultRule{StatusCode: statusCode})
+}
+
+// InjectLatency adiciona latência a todas as requisições (que continuam sendo encaminhadas)
+func (p *FaultProxy) InjectLatency(latency time.Duration) *FaultProxy {
+	return p.AddRule(FaultRule{Latency: latency})
+}
+
+// FailNext faz as próximas n requisições retornarem o status informado
+func (p *FaultProxy) FailNext(n int, statusCode int) *FaultProxy {
+	return p.AddRule(FaultRule{StatusCode: statusCode, Times: n})
+}
+
+// Reset remove todas as regras e zera os contadores
+func (p *FaultProxy) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.rules = nil
+	p.total = 0
+	p.injected = 0
+}
+
+// Requests retorna o total de requisições recebidas pelo proxy
+func (p *FaultProxy) Requests() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.total
+}
+
+// InjectedFaults retorna quantas requisições receberam uma falha de status code
+func (p *FaultProxy) InjectedFaults() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.injected
+}
+
+// nextRule retorna a primeira regra aplicável, consumindo uma execução das regras limitadas
+func (p *FaultProxy) nextRule(req *http.Request) *FaultRule {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.total++
+
+	for i, rule := range p.rules {
+		if !rule.matches(req) {
+			continue
+		}
+
+		applied := *rule
+		if rule.Times > 0 {
+			rule.Times--
+			if rule.Times == 0 {
+				p.rules = append(p.rules[:i], p.rules[i+1:]...)
+			}
+		}
+		if applied.StatusCode != 0 {
+			p.injected++
+		}
+		return &applied
+	}
+
+	return nil
+}
+
+// handle aplica a regra correspondente e encaminha a requisição ao alvo quando não há falha de status
+func (p *FaultProxy) handle(w http.ResponseWriter, req *http.Request) {
+	rule := p.nextRule(req)
+	if rule == nil {
+		p.proxy.ServeHTTP(w, req)
+		return
+	}
+
+	if rule.Latency > 0 {
+		select {
+		case <-time.After(rule.Latency):
+		case <-req.Context().Done():
+			return
+		}
+	}
+
+	if rule.StatusCode == 0 {
+		p.proxy.ServeHTTP(w, req)
+		return
+	}
+
+	body := rule.Body
+	if body == "" {
+		body = fmt.Sprintf(`{"error":"fault injected by test proxy","status":%d}`, rule.StatusCode)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Fault-Injected", "true")
+	w.WriteHeader(rule.StatusCode)
+	fmt.Fprint(w, body)
+}
@@ -0,0 +1,158 @@
+package testhelper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"github.com/stretchr/testify/require"
+)
+
+// FixtureFactory cria uma nova instância (ponteiro) do tipo de fixture de um índice
+type FixtureFactory func() interface{}
+
+var (
+	fixtureTypesMu sync.RWMutex
+	fixtureTypes   = make(map[string]FixtureFactory)
+)
+
+// RegisterFixtureType associa um tipo Go aos documentos do índice
+// Normalmente chamado no init() dos arquivos gerados por cmd/esgen
+func RegisterFixtureType(indexName string, factory FixtureFactory) {
+	fixtureTypesMu.Lock()
+	defer fixtureTypesMu.Unlock()
+
+	if factory == nil {
+		panic("testhelper: RegisterFixtureType factory is nil")
+	}
+	if _, dup := fixtureTypes[indexName]; dup {
+		panic("testhelper: RegisterFixtureType called twice for index " + indexName)
+	}
+	fixtureTypes[indexName] = factory
+}
+
+// FixtureType retorna a factory registrada para o índice
+func FixtureType(indexName string) (FixtureFactory, bool) {
+	fixtureTypesMu.RLock()
+	defer fixtureTypesMu.RUnlock()
+
+	factory, ok := fixtureTypes[indexName]
+	return factory, ok
+}
+
+// RegisteredFixtureIndices retorna os índices com tipo de fixture registrado
+func RegisteredFixtureIndices() []string {
+	fixtureTypesMu.RLock()
+	defer fixtureTypesMu.RUnlock()
+
+	indices := make([]string, 0, len(fixtureTypes))
+	for index := range fixtureTypes {
+		indices = append(indices, index)
+	}
+	sort.Strings(indices)
+	return indices
+}
+
+// LoadFixtures indexa os documentos de um arquivo JSON (array) no índice, no tenant da suite
+// Se houver tipo registrado para o índice, cada documento é decodificado nele rejeitando campos
+// desconhecidos, e os valores tipados são retornados; caso contrário retorna map[string]interface{}
+// O arquivo é lido do fs.FS configurado com WithFixturesFS, se houver
+func (s *IntegrationTestSuite) LoadFixtures(indexName, path string) []interface{} {
+	s.t.Helper()
+	return s.loadFixtures(s.fixtures, indexName, path)
+}
+
+// LoadFixturesFS é LoadFixtures lendo o arquivo de fsys (ex.: embed.FS)
+func (s *IntegrationTestSuite) LoadFixturesFS(fsys fs.FS, indexName, path string) []interface{} {
+	s.t.Helper()
+	return s.loadFixtures(fsys, indexName, path)
+}
+
+// loadFixtures indexa as fixtures lidas de fsys (ou do disco, com fsys nil)
+func (s *IntegrationTestSuite) loadFixtures(fsys fs.FS, indexName, path string) []interface{} {
+	s.t.Helper()
+	defer s.trackOperation("LoadFixtures")()
+
+	raw, err := readFixtureFile(fsys, path)
+	require.NoError(s.t, err, "Failed to read fixtures %s", path)
+
+	var docs []json.RawMessage
+	require.NoError(s.t, json.Unmarshal(raw, &docs), "Fixtures %s must be a JSON array", path)
+
+	factory, typed := FixtureType(indexName)
+
+	loaded := make([]interface{}, 0, len(docs))
+	for i, doc := range docs {
+		var value interface{}
+		if typed {
+			value = factory()
+			decoder := json.NewDecoder(bytes.NewReader(doc))
+			decoder.DisallowUnknownFields()
+			err := decoder.Decode(value)
+			require.NoError(s.t, err, "Fixture %d of %s does not match the %s mapping", i, path, indexName)
+		} else {
+			var m map[string]interface{}
+			require.NoError(s.t, json.Unmarshal(doc, &m), "Invalid fixture %d of %s", i, path)
+			value = m
+		}
+
+		// Os documentos sempre pertencem ao tenant da suite
+		source, err := fixtureSource(value, s.tenantID)
+		require.NoError(s.t, err, "Failed to prepare fixture %d of %s", i, path)
+
+		docID, _ := source["id"].(string)
+		body, err := json.Marshal(source)
+		require.NoError(s.t, err, "Failed to marshal fixture %d of %s", i, path)
+
+		req := esapi.IndexRequest{
+			Index:      s.RunScopedIndex(indexName),
+			DocumentID: docID,
+			Body:       bytes.NewReader(body),
+		}
+
+		res, err := req.Do(s.ctx, s.ES())
+		require.NoError(s.t, err, "Failed to index fixture %d of %s", i, path)
+		res.Body.Close()
+
+		if res.IsError() {
+			require.Fail(s.t, fmt.Sprintf("Failed to index fixture %d of %s: %s", i, path, res.Status()))
+		}
+
+		if typed {
+			// O valor retornado reflete exatamente o documento indexado (incluindo o tenant)
+			require.NoError(s.t, json.Unmarshal(body, value), "Failed to decode fixture %d of %s", i, path)
+		}
+		loaded = append(loaded, value)
+	}
+
+	s.refreshIndex(indexName)
+
+	if isDebugEnabled() {
+		fmt.Printf("📦 Loaded %d fixtures into %s (typed: %v)\n", len(loaded), indexName, typed)
+	}
+
+	return loaded
+}
+
+// fixtureSource converte o valor para o _source do documento, forçando o tenant_id
+func fixtureSource(value interface{}, tenantID string) (map[string]interface{}, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var source map[string]interface{}
+	if err := json.Unmarshal(raw, &source); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(tenantID) != "" {
+		source["tenant_id"] = tenantID
+	}
+	return source, nil
+}
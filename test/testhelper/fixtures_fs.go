@@ -0,0 +1,59 @@
+package testhelper
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Fixtures em fs.FS
+//
+// Os loaders de fixtures (LoadFixtures, LoadCapturedState e os arquivos SQL do WithPostgres) leem de um
+// fs.FS quando configurado com WithFixturesFS, o que permite usar fixtures embutidas no binário de teste
+// (embed.FS) em ambientes como Bazel/remote-exec, onde os caminhos relativos de testdata não existem.
+// Sem fs.FS, os caminhos continuam sendo lidos do disco
+
+// readFixtureFile lê o arquivo de fsys ou, com fsys nil, do disco
+func readFixtureFile(fsys fs.FS, name string) ([]byte, error) {
+	if fsys == nil {
+		return os.ReadFile(name)
+	}
+	return fs.ReadFile(fsys, fixtureFSPath(name))
+}
+
+// openFixtureFile abre o arquivo de fsys ou, com fsys nil, do disco, para leitura incremental
+func openFixtureFile(fsys fs.FS, name string) (io.ReadCloser, error) {
+	if fsys == nil {
+		return os.Open(name)
+	}
+	return fsys.Open(fixtureFSPath(name))
+}
+
+// readFixtureDir lista o diretório de fsys ou, com fsys nil, do disco
+func readFixtureDir(fsys fs.FS, dir string) ([]fs.DirEntry, error) {
+	if fsys == nil {
+		return os.ReadDir(dir)
+	}
+	return fs.ReadDir(fsys, fixtureFSPath(dir))
+}
+
+// joinFixturePath junta os elementos do caminho no formato de fsys (sempre "/") ou do disco
+func joinFixturePath(fsys fs.FS, elem ...string) string {
+	if fsys == nil {
+		return filepath.Join(elem...)
+	}
+	return path.Join(elem...)
+}
+
+// fixtureFSPath converte o caminho para o formato aceito por fs.FS (sem "./" nem "/" inicial)
+func fixtureFSPath(name string) string {
+	name = path.Clean(filepath.ToSlash(name))
+	name = strings.TrimPrefix(name, "/")
+	if name == "" {
+		return "."
+	}
+	return name
+}
@@ -0,0 +1,366 @@
+package testhelper
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/esutil"
+	"github.com/stretchr/testify/require"
+)
+
+// StreamOptions ajusta o StreamFixtures
+type StreamOptions struct {
+	Workers          int                  // workers do BulkIndexer (padrão runtime.NumCPU())
+	FlushBytes       int                  // tamanho de cada requisição _bulk (padrão 5MB)
+	IDField          string               // campo usado como _id (padrão "id"; ausente = id gerado)
+	WithTenant       bool                 // força tenant_id = tenant da suite (reescreve cada documento)
+	MaxRetries       int                  // novas tentativas de documentos rejeitados com 429 (padrão 5)
+	Progress         func(StreamProgress) // chamado a cada ProgressInterval (padrão: log com TESTHELPER_DEBUG)
+	ProgressInterval time.Duration        // intervalo do progresso (padrão 5s)
+}
+
+// StreamProgress é o andamento (ou o resultado final) de um StreamFixtures
+type StreamProgress struct {
+	Lines   int64 // linhas lidas do arquivo
+	Indexed int64 // documentos indexados
+	Failed  int64 // documentos que falharam definitivamente
+	Retried int64 // documentos reenviados após 429
+	Elapsed time.Duration
+}
+
+// streamRetryBackoff é a espera antes de cada rodada de reenvio, multiplicada pela rodada
+const streamRetryBackoff = 500 * time.Millisecond
+
+// StreamFixtures indexa um arquivo NDJSON (um documento por linha, opcionalmente .gz) lendo-o de forma
+// incremental e alimentando um BulkIndexer, com memória limitada a Workers x FlushBytes
+// Rejeições 429 (fila de bulk cheia) são reenviadas com backoff: requisições inteiras pelo client e
+// documentos individuais em rodadas após a leitura. Falha o teste se restarem documentos com erro
+func (s *IntegrationTestSuite) StreamFixtures(index, path string, opts StreamOptions) StreamProgress {
+	s.t.Helper()
+	defer s.trackOperation("StreamFixtures")()
+
+	require.NotNil(s.t, s.ES(), "Elasticsearch not configured")
+
+	if opts.IDField == "" {
+		opts.IDField = "id"
+	}
+	if opts.MaxRetries <= 0 {
+		opts.MaxRetries = 5
+	}
+	if opts.ProgressInterval <= 0 {
+		opts.ProgressInterval = 5 * time.Second
+	}
+	if opts.Progress == nil && isDebugEnabled() {
+		opts.Progress = func(p StreamProgress) {
+			log.Printf("📦 Streaming %s: %d lines, %d indexed, %d failed, %d retried (%s)",
+				path, p.Lines, p.Indexed, p.Failed, p.Retried, p.Elapsed.Round(time.Second))
+		}
+	}
+
+	file, err := openFixtureFile(s.fixtures, path)
+	require.NoError(s.t, err, "Failed to open fixtures %s", path)
+	defer file.Close()
+
+	var reader io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(file)
+		require.NoError(s.t, err, "Failed to open gzip fixtures %s", path)
+		defer gz.Close()
+		reader = gz
+	}
+
+	stream := &fixtureStream{
+		suite:   s,
+		index:   s.RunScopedIndex(index),
+		opts:    opts,
+		client:  s.streamClient(opts.MaxRetries),
+		started: time.Now(),
+	}
+
+	stopProgress := stream.reportProgress()
+	err = stream.run(reader)
+	stopProgress()
+	require.NoError(s.t, err, "Failed to stream fixtures %s", path)
+
+	s.refreshIndex(stream.index)
+
+	progress := stream.progress()
+	if opts.Progress != nil {
+		opts.Progress(progress)
+	}
+	if progress.Failed > 0 {
+		require.Fail(s.t, fmt.Sprintf("%d of %d documents of %s failed to index", progress.Failed, progress.Lines, path),
+			"First errors:\n%s", strings.Join(stream.errorSamples(), "\n"))
+	}
+	return progress
+}
+
+// streamClient retorna um client que reenvia requisições rejeitadas com 429, com o mesmo endereço do
+// Elasticsearch compartilhado; sem ele (ex.: client configurado externamente) usa o client da suite
+func (s *IntegrationTestSuite) streamClient(maxRetries int) *elasticsearch.Client {
+	if s.sharedES == nil || s.sharedES.GetURL() == "" {
+		return s.ES()
+	}
+
+	cfg := s.sharedES.ClientConfig()
+	cfg.RetryOnStatus = []int{429, 502, 503, 504}
+	cfg.MaxRetries = maxRetries
+	cfg.RetryBackoff = func(attempt int) time.Duration { return time.Duration(attempt) * streamRetryBackoff }
+
+	client, err := elasticsearch.NewClient(cfg)
+	if err != nil {
+		return s.ES()
+	}
+	return client
+}
+
+// fixtureStream guarda o estado de um StreamFixtures
+type fixtureStream struct {
+	suite   *IntegrationTestSuite
+	index   string
+	opts    StreamOptions
+	client  *elasticsearch.Client
+	started time.Time
+
+	lines   int64
+	indexed int64
+	failed  int64
+	retried int64
+
+	mu       sync.Mutex
+	rejected []streamDoc
+	errors   []string
+}
+
+// streamDoc é um documento pronto para envio (mantido só enquanto pode ser reenviado)
+type streamDoc struct {
+	line int64
+	id   string
+	body []byte
+}
+
+// run lê o arquivo enviando os documentos e depois reenvia os rejeitados com 429
+func (st *fixtureStream) run(reader io.Reader) error {
+	indexer, err := st.newIndexer()
+	if err != nil {
+		return err
+	}
+
+	buffered := bufio.NewReaderSize(reader, 1024*1024)
+	for {
+		line, readErr := buffered.ReadBytes('\n')
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 {
+			n := atomic.AddInt64(&st.lines, 1)
+			doc, err := st.prepare(n, line)
+			if err != nil {
+				st.fail(n, err.Error())
+			} else if err := st.add(indexer, doc); err != nil {
+				indexer.Close(context.Background())
+				return err
+			}
+		}
+
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			indexer.Close(context.Background())
+			return fmt.Errorf("failed to read fixtures: %w", readErr)
+		}
+	}
+	if err := indexer.Close(st.suite.ctx); err != nil {
+		return err
+	}
+
+	for round := 1; round <= st.opts.MaxRetries; round++ {
+		st.mu.Lock()
+		pending := st.rejected
+		st.rejected = nil
+		st.mu.Unlock()
+		if len(pending) == 0 {
+			break
+		}
+
+		atomic.AddInt64(&st.retried, int64(len(pending)))
+		select {
+		case <-st.suite.ctx.Done():
+			return st.suite.ctx.Err()
+		case <-time.After(time.Duration(round) * streamRetryBackoff):
+		}
+
+		indexer, err := st.newIndexer()
+		if err != nil {
+			return err
+		}
+		for _, doc := range pending {
+			if err := st.add(indexer, doc); err != nil {
+				indexer.Close(context.Background())
+				return err
+			}
+		}
+		if err := indexer.Close(st.suite.ctx); err != nil {
+			return err
+		}
+	}
+
+	// Rejeitados após a última rodada contam como falha
+	st.mu.Lock()
+	for _, doc := range st.rejected {
+		st.errors = appendErrorSample(st.errors, fmt.Sprintf("line %d: rejected (429) after %d retries", doc.line, st.opts.MaxRetries))
+	}
+	atomic.AddInt64(&st.failed, int64(len(st.rejected)))
+	st.rejected = nil
+	st.mu.Unlock()
+	return nil
+}
+
+// newIndexer cria o BulkIndexer do índice
+func (st *fixtureStream) newIndexer() (esutil.BulkIndexer, error) {
+	indexer, err := esutil.NewBulkIndexer(esutil.BulkIndexerConfig{
+		Client:     st.client,
+		Index:      st.index,
+		NumWorkers: st.opts.Workers,
+		FlushBytes: st.opts.FlushBytes,
+		OnError: func(ctx context.Context, err error) {
+			st.mu.Lock()
+			st.errors = appendErrorSample(st.errors, err.Error())
+			st.mu.Unlock()
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bulk indexer: %w", err)
+	}
+	return indexer, nil
+}
+
+// prepare extrai o _id e, com WithTenant, reescreve o documento com o tenant da suite
+func (st *fixtureStream) prepare(line int64, raw []byte) (streamDoc, error) {
+	doc := streamDoc{line: line, body: append([]byte(nil), raw...)}
+
+	if st.opts.WithTenant {
+		var source map[string]interface{}
+		if err := json.Unmarshal(raw, &source); err != nil {
+			return doc, fmt.Errorf("line %d: invalid JSON: %v", line, err)
+		}
+		source["tenant_id"] = st.suite.tenantID
+		if id, ok := source[st.opts.IDField].(string); ok {
+			doc.id = id
+		}
+		body, err := json.Marshal(source)
+		if err != nil {
+			return doc, fmt.Errorf("line %d: %v", line, err)
+		}
+		doc.body = body
+		return doc, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return doc, fmt.Errorf("line %d: invalid JSON: %v", line, err)
+	}
+	if value, ok := fields[st.opts.IDField]; ok {
+		var id string
+		if json.Unmarshal(value, &id) == nil {
+			doc.id = id
+		}
+	}
+	return doc, nil
+}
+
+// add envia o documento ao indexer, guardando-o para reenvio se for rejeitado com 429
+func (st *fixtureStream) add(indexer esutil.BulkIndexer, doc streamDoc) error {
+	return indexer.Add(st.suite.ctx, esutil.BulkIndexerItem{
+		Action:     "index",
+		DocumentID: doc.id,
+		Body:       bytes.NewReader(doc.body),
+		OnSuccess: func(ctx context.Context, item esutil.BulkIndexerItem, res esutil.BulkIndexerResponseItem) {
+			atomic.AddInt64(&st.indexed, 1)
+		},
+		OnFailure: func(ctx context.Context, item esutil.BulkIndexerItem, res esutil.BulkIndexerResponseItem, err error) {
+			if err == nil && res.Status == 429 {
+				st.mu.Lock()
+				st.rejected = append(st.rejected, doc)
+				st.mu.Unlock()
+				return
+			}
+			if err == nil {
+				err = fmt.Errorf("%s: %s", res.Error.Type, res.Error.Reason)
+			}
+			st.fail(doc.line, err.Error())
+		},
+	})
+}
+
+// fail registra uma falha definitiva do documento
+func (st *fixtureStream) fail(line int64, reason string) {
+	atomic.AddInt64(&st.failed, 1)
+	st.mu.Lock()
+	st.errors = appendErrorSample(st.errors, fmt.Sprintf("line %d: %s", line, reason))
+	st.mu.Unlock()
+}
+
+// progress retorna o andamento atual
+func (st *fixtureStream) progress() StreamProgress {
+	return StreamProgress{
+		Lines:   atomic.LoadInt64(&st.lines),
+		Indexed: atomic.LoadInt64(&st.indexed),
+		Failed:  atomic.LoadInt64(&st.failed),
+		Retried: atomic.LoadInt64(&st.retried),
+		Elapsed: time.Since(st.started),
+	}
+}
+
+// reportProgress chama o callback de progresso periodicamente até a função retornada ser chamada
+func (st *fixtureStream) reportProgress() func() {
+	if st.opts.Progress == nil {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(st.opts.ProgressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				st.opts.Progress(st.progress())
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// errorSamples retorna as primeiras mensagens de erro
+func (st *fixtureStream) errorSamples() []string {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return append([]string(nil), st.errors...)
+}
+
+// appendErrorSample acumula até 10 mensagens de erro, suficientes para diagnosticar sem reter milhões
+func appendErrorSample(samples []string, msg string) []string {
+	if len(samples) >= 10 {
+		return samples
+	}
+	return append(samples, msg)
+}
@@ -0,0 +1,176 @@
+package testhelper
+
+import (
+	"github.com/stretchr/testify/require"
+)
+
+// GeoCoord é uma coordenada geográfica (latitude/longitude em graus)
+type GeoCoord struct {
+	Lat float64
+	Lon float64
+}
+
+// GeoPointProperty retorna a definição de mapping de um campo geo_point
+func GeoPointProperty() map[string]interface{} {
+	return map[string]interface{}{"type": "geo_point"}
+}
+
+// GeoShapeProperty retorna a definição de mapping de um campo geo_shape
+func GeoShapeProperty() map[string]interface{} {
+	return map[string]interface{}{"type": "geo_shape"}
+}
+
+// GeoMapping monta um mapping ("properties") com os campos geo_point e geo_shape informados
+// somados às propriedades extras (ex.: campos keyword do documento)
+func GeoMapping(pointFields, shapeFields []string, extra map[string]interface{}) map[string]interface{} {
+	properties := make(map[string]interface{}, len(pointFields)+len(shapeFields)+len(extra))
+	for name, definition := range extra {
+		properties[name] = definition
+	}
+	for _, field := range pointFields {
+		properties[field] = GeoPointProperty()
+	}
+	for _, field := range shapeFields {
+		properties[field] = GeoShapeProperty()
+	}
+	return map[string]interface{}{"properties": properties}
+}
+
+// GeoPoint retorna o valor de um geo_point para documentos ({"lat": ..., "lon": ...})
+func GeoPoint(lat, lon float64) map[string]interface{} {
+	return map[string]interface{}{"lat": lat, "lon": lon}
+}
+
+// GeoPolygon retorna um polígono GeoJSON para campos geo_shape (o anel é fechado automaticamente)
+func GeoPolygon(points ...GeoCoord) map[string]interface{} {
+	ring := make([][]float64, 0, len(points)+1)
+	for _, p := range points {
+		// GeoJSON usa a ordem [lon, lat]
+		ring = append(ring, []float64{p.Lon, p.Lat})
+	}
+	if len(points) > 0 && points[0] != points[len(points)-1] {
+		ring = append(ring, []float64{points[0].Lon, points[0].Lat})
+	}
+	return map[string]interface{}{
+		"type":        "Polygon",
+		"coordinates": [][][]float64{ring},
+	}
+}
+
+// GeoEnvelope retorna um retângulo (envelope) para campos geo_shape
+func GeoEnvelope(topLeft, bottomRight GeoCoord) map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "envelope",
+		"coordinates": [][]float64{{topLeft.Lon, topLeft.Lat}, {bottomRight.Lon, bottomRight.Lat}},
+	}
+}
+
+// SearchWithinRadius busca documentos cujo geo_point do campo está a até distance (ex.: "5km") do ponto
+// Os resultados vêm ordenados do mais próximo para o mais distante (distâncias em GeoDistances, em km)
+func (s *IntegrationTestSuite) SearchWithinRadius(index, field string, lat, lon float64, distance string) *SearchResult {
+	s.t.Helper()
+
+	return s.SearchDocuments(index, map[string]interface{}{
+		"size": 100,
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": map[string]interface{}{
+					"geo_distance": map[string]interface{}{
+						"distance": distance,
+						field:      GeoPoint(lat, lon),
+					},
+				},
+			},
+		},
+		"sort": []interface{}{
+			map[string]interface{}{
+				"_geo_distance": map[string]interface{}{
+					field:   GeoPoint(lat, lon),
+					"order": "asc",
+					"unit":  "km",
+				},
+			},
+		},
+	})
+}
+
+// SearchGeoShape busca documentos cujo geo_shape do campo tem a relação (intersects, within, disjoint, contains)
+// com a forma informada
+func (s *IntegrationTestSuite) SearchGeoShape(index, field string, shape map[string]interface{}, relation string) *SearchResult {
+	s.t.Helper()
+
+	if relation == "" {
+		relation = "intersects"
+	}
+
+	return s.SearchDocuments(index, map[string]interface{}{
+		"size": 100,
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": map[string]interface{}{
+					"geo_shape": map[string]interface{}{
+						field: map[string]interface{}{
+							"shape":    shape,
+							"relation": relation,
+						},
+					},
+				},
+			},
+		},
+	})
+}
+
+// AssertWithinRadius verifica que os documentos (por _id) dentro do raio são exatamente os esperados, na ordem
+func (s *IntegrationTestSuite) AssertWithinRadius(index, field string, lat, lon float64, distance string, expectedIDs ...string) {
+	s.t.Helper()
+
+	result := s.SearchWithinRadius(index, field, lat, lon, distance)
+	require.Equal(s.t, expectedIDs, result.IDs(), "Unexpected documents within %s of (%v, %v)", distance, lat, lon)
+}
+
+// IDs retorna os _id dos hits na ordem do resultado
+func (r *SearchResult) IDs() []string {
+	ids := []string{}
+	for _, hit := range r.hits() {
+		if id, ok := hit["_id"].(string); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// GeoDistances retorna a distância (km) de cada hit de SearchWithinRadius, na ordem do resultado
+func (r *SearchResult) GeoDistances() []float64 {
+	distances := []float64{}
+	for _, hit := range r.hits() {
+		sort, ok := hit["sort"].([]interface{})
+		if !ok || len(sort) == 0 {
+			continue
+		}
+		if distance, ok := sort[0].(float64); ok {
+			distances = append(distances, distance)
+		}
+	}
+	return distances
+}
+
+// hits retorna os hits brutos da resposta
+func (r *SearchResult) hits() []map[string]interface{} {
+	hits, ok := r.response["hits"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	hitsArray, ok := hits["hits"].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	result := make([]map[string]interface{}, 0, len(hitsArray))
+	for _, hit := range hitsArray {
+		if hitMap, ok := hit.(map[string]interface{}); ok {
+			result = append(result, hitMap)
+		}
+	}
+	return result
+}
@@ -0,0 +1,171 @@
+package testhelper
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/stretchr/testify/require"
+)
+
+// goldenTenantPlaceholder substitui o tenant da suite nos arquivos golden
+const goldenTenantPlaceholder = "<tenant>"
+
+// goldenVolatileKeys são campos da resposta que variam entre execuções e são sempre removidos
+var goldenVolatileKeys = []string{"took", "timed_out", "_shards", "max_score", "_score", "_seq_no", "_primary_term", "_shard", "_node", "pit_id"}
+
+// GoldenOption ajusta a normalização de AssertSearchMatchesGolden
+type GoldenOption func(*goldenOptions)
+
+type goldenOptions struct {
+	unordered bool
+	keepSort  bool
+	ignore    []string
+}
+
+// WithUnorderedHits ordena os hits por _index/_id antes de comparar (para queries sem ordem determinística)
+func WithUnorderedHits() GoldenOption {
+	return func(o *goldenOptions) {
+		o.unordered = true
+	}
+}
+
+// WithSortValues mantém os valores de sort dos hits (removidos por padrão, pois incluem _score e tiebreakers)
+func WithSortValues() GoldenOption {
+	return func(o *goldenOptions) {
+		o.keepSort = true
+	}
+}
+
+// WithGoldenIgnore remove os campos informados em qualquer nível da resposta (ex.: "updated_at")
+func WithGoldenIgnore(keys ...string) GoldenOption {
+	return func(o *goldenOptions) {
+		o.ignore = append(o.ignore, keys...)
+	}
+}
+
+// AssertSearchMatchesGolden executa a busca e compara a resposta normalizada com o arquivo golden
+// A normalização remove took, _shards, _score/max_score e sort, troca o índice real (run scope) pelo
+// nome lógico e o tenant da suite por "<tenant>". Com UPDATE_GOLDEN=true (ou -update, se o pacote de
+// teste define o flag) o arquivo é regravado
+func (s *IntegrationTestSuite) AssertSearchMatchesGolden(index string, query map[string]interface{}, goldenPath string, opts ...GoldenOption) {
+	s.t.Helper()
+	defer s.trackOperation("AssertSearchMatchesGolden")()
+
+	require.NotNil(s.t, s.ES(), "Elasticsearch not configured")
+
+	options := &goldenOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	result := s.SearchDocuments(index, query)
+	normalized := s.normalizeSearchResponse(index, result.response, options)
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	require.NoError(s.t, encoder.Encode(normalized), "Failed to marshal normalized search response")
+	actual := buf.Bytes()
+
+	if goldenUpdateEnabled() {
+		require.NoError(s.t, os.MkdirAll(filepath.Dir(goldenPath), 0o755), "Failed to create golden dir")
+		require.NoError(s.t, os.WriteFile(goldenPath, actual, 0o644), "Failed to write golden file %s", goldenPath)
+		s.t.Logf("Updated golden file %s", goldenPath)
+		return
+	}
+
+	expected, err := os.ReadFile(goldenPath)
+	if os.IsNotExist(err) {
+		require.Fail(s.t, fmt.Sprintf("Golden file %s does not exist", goldenPath), "Run the test with UPDATE_GOLDEN=true to create it")
+		return
+	}
+	require.NoError(s.t, err, "Failed to read golden file %s", goldenPath)
+
+	require.JSONEq(s.t, string(expected), string(actual),
+		"Search response for %s does not match %s (run with UPDATE_GOLDEN=true to regenerate)", index, goldenPath)
+}
+
+// goldenUpdateEnabled indica se os arquivos golden devem ser regravados: UPDATE_GOLDEN=true, ou o flag
+// -update quando o pacote de teste o define (o testhelper não registra flags globais, evitando
+// "flag redefined" no idiom golden padrão)
+func goldenUpdateEnabled() bool {
+	if f := flag.Lookup("update"); f != nil {
+		if update, err := strconv.ParseBool(f.Value.String()); err == nil && update {
+			return true
+		}
+	}
+	update, _ := strconv.ParseBool(os.Getenv("UPDATE_GOLDEN"))
+	return update
+}
+
+// normalizeSearchResponse remove os campos voláteis e os nomes específicos da execução
+func (s *IntegrationTestSuite) normalizeSearchResponse(index string, response map[string]interface{}, options *goldenOptions) interface{} {
+	ignore := make(map[string]bool, len(goldenVolatileKeys)+len(options.ignore))
+	for _, key := range goldenVolatileKeys {
+		ignore[key] = true
+	}
+	for _, key := range options.ignore {
+		ignore[key] = true
+	}
+	if !options.keepSort {
+		ignore["sort"] = true
+	}
+
+	replacer := strings.NewReplacer(s.RunScopedIndex(index), index, s.tenantID, goldenTenantPlaceholder)
+	normalized := normalizeGoldenValue(response, ignore, replacer)
+
+	if options.unordered {
+		if root, ok := normalized.(map[string]interface{}); ok {
+			if hits, ok := root["hits"].(map[string]interface{}); ok {
+				if list, ok := hits["hits"].([]interface{}); ok {
+					sort.SliceStable(list, func(i, j int) bool {
+						return goldenHitKey(list[i]) < goldenHitKey(list[j])
+					})
+				}
+			}
+		}
+	}
+	return normalized
+}
+
+// normalizeGoldenValue percorre a resposta removendo as chaves ignoradas e reescrevendo as strings
+// Um "sort" que é a opção da query (objeto) é preservado; só os valores de sort dos hits (lista) são removidos
+func normalizeGoldenValue(value interface{}, ignore map[string]bool, replacer *strings.Replacer) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			if ignore[key] {
+				if _, isList := child.([]interface{}); key != "sort" || isList {
+					continue
+				}
+			}
+			out[replacer.Replace(key)] = normalizeGoldenValue(child, ignore, replacer)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, child := range v {
+			out[i] = normalizeGoldenValue(child, ignore, replacer)
+		}
+		return out
+	case string:
+		return replacer.Replace(v)
+	default:
+		return v
+	}
+}
+
+// goldenHitKey identifica o hit para a ordenação de WithUnorderedHits
+func goldenHitKey(hit interface{}) string {
+	fields, _ := hit.(map[string]interface{})
+	return fmt.Sprintf("%v/%v", fields["_index"], fields["_id"])
+}
@@ -0,0 +1,94 @@
+package testhelper
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeGoldenValue(t *testing.T) {
+	ignore := map[string]bool{"took": true, "_score": true, "sort": true}
+	replacer := strings.NewReplacer("products-run42", "products", "tenant-a", goldenTenantPlaceholder)
+
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected interface{}
+	}{
+		{
+			name:     "Nil",
+			value:    nil,
+			expected: nil,
+		},
+		{
+			name:     "Empty map",
+			value:    map[string]interface{}{},
+			expected: map[string]interface{}{},
+		},
+		{
+			name:     "Scalars are kept",
+			value:    []interface{}{float64(1), true, nil},
+			expected: []interface{}{float64(1), true, nil},
+		},
+		{
+			name: "Ignored keys are removed at any level",
+			value: map[string]interface{}{
+				"took": float64(3),
+				"hits": map[string]interface{}{
+					"hits": []interface{}{
+						map[string]interface{}{"_id": "1", "_score": 1.2, "sort": []interface{}{float64(10), "1"}},
+					},
+				},
+			},
+			expected: map[string]interface{}{
+				"hits": map[string]interface{}{
+					"hits": []interface{}{map[string]interface{}{"_id": "1"}},
+				},
+			},
+		},
+		{
+			name:     "Sort given as an object is kept",
+			value:    map[string]interface{}{"sort": map[string]interface{}{"price": "asc"}},
+			expected: map[string]interface{}{"sort": map[string]interface{}{"price": "asc"}},
+		},
+		{
+			name: "Run-scoped index and tenant are replaced in keys and values",
+			value: map[string]interface{}{
+				"_index":          "products-run42",
+				"tenant-a-totals": map[string]interface{}{"tenant_id": "tenant-a"},
+			},
+			expected: map[string]interface{}{
+				"_index":          "products",
+				"<tenant>-totals": map[string]interface{}{"tenant_id": "<tenant>"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, normalizeGoldenValue(tt.value, ignore, replacer))
+		})
+	}
+}
+
+func TestGoldenUpdateEnabled(t *testing.T) {
+	tests := []struct {
+		env      string
+		expected bool
+	}{
+		{env: "", expected: false},
+		{env: "true", expected: true},
+		{env: "1", expected: true},
+		{env: "false", expected: false},
+		{env: "yes", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run("UPDATE_GOLDEN="+tt.env, func(t *testing.T) {
+			t.Setenv("UPDATE_GOLDEN", tt.env)
+
+			assert.Equal(t, tt.expected, goldenUpdateEnabled())
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package testhelper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"github.com/stretchr/testify/require"
+)
+
+// ILMPollInterval é o intervalo de avaliação do ILM aplicado ao container (o padrão do ES é 10 minutos)
+const ILMPollInterval = "1s"
+
+// ilmPollIntervalApplied guarda os clusters em que o poll_interval já foi reduzido
+var ilmPollIntervalApplied sync.Map
+
+// ILMStatus é o estado de um índice no ILM (_ilm/explain)
+type ILMStatus struct {
+	Managed bool
+	Policy  string
+	Phase   string
+	Action  string
+	Step    string
+	// FailedStep e StepInfo descrevem o erro quando o índice está no step ERROR
+	FailedStep string
+	StepInfo   string
+}
+
+// PutILMPolicy instala a política de ILM e retorna o nome real (com o sufixo da execução no ES externo)
+// policy é o conteúdo de "policy" ({"phases": ...}) ou o corpo completo da API. A política é removida
+// ao final do teste. No container, indices.lifecycle.poll_interval é reduzido para ILMPollInterval
+func (s *IntegrationTestSuite) PutILMPolicy(name string, policy map[string]interface{}) string {
+	s.t.Helper()
+	defer s.trackOperation("PutILMPolicy")()
+
+	client := s.ES()
+	require.NotNil(s.t, client, "Elasticsearch not configured")
+	s.ensureILMPollInterval()
+
+	name = s.RunScopedIndex(name)
+	if _, full := policy["policy"]; !full {
+		policy = map[string]interface{}{"policy": policy}
+	}
+
+	raw, err := json.Marshal(policy)
+	require.NoError(s.t, err, "Failed to marshal ILM policy %s", name)
+
+	res, err := esapi.ILMPutLifecycleRequest{Policy: name, Body: bytes.NewReader(raw)}.Do(s.ctx, client)
+	require.NoError(s.t, err, "Failed to put ILM policy %s", name)
+	defer res.Body.Close()
+
+	if res.IsError() {
+		require.Fail(s.t, fmt.Sprintf("Failed to put ILM policy %s: %s", name, res.String()))
+	}
+
+	// Registrada antes dos templates e índices que a usam, então é removida depois deles (Cleanup é LIFO)
+	s.t.Cleanup(func() {
+		res, err := esapi.ILMDeleteLifecycleRequest{Policy: name}.Do(s.ctx, client)
+		if err == nil {
+			res.Body.Close()
+		}
+	})
+
+	return name
+}
+
+// AttachILMPolicy associa a política ao index template (criado com PutIndexTemplate) definindo
+// index.lifecycle.name e, se informado, index.lifecycle.rollover_alias nos settings do template
+// Só os índices criados depois passam a usar a política
+func (s *IntegrationTestSuite) AttachILMPolicy(template, policy, rolloverAlias string) {
+	s.t.Helper()
+	defer s.trackOperation("AttachILMPolicy")()
+
+	client := s.ES()
+	require.NotNil(s.t, client, "Elasticsearch not configured")
+
+	template = s.RunScopedIndex(template)
+	policy = s.RunScopedIndex(policy)
+
+	res, err := esapi.IndicesGetIndexTemplateRequest{Name: template}.Do(s.ctx, client)
+	require.NoError(s.t, err, "Failed to get index template %s", template)
+	defer res.Body.Close()
+
+	if res.IsError() {
+		require.Fail(s.t, fmt.Sprintf("Failed to get index template %s: %s", template, res.String()))
+	}
+
+	var current struct {
+		IndexTemplates []struct {
+			IndexTemplate map[string]interface{} `json:"index_template"`
+		} `json:"index_templates"`
+	}
+	require.NoError(s.t, json.NewDecoder(res.Body).Decode(&current), "Failed to decode index template %s", template)
+	require.Len(s.t, current.IndexTemplates, 1, "Index template %s not found", template)
+
+	body := current.IndexTemplates[0].IndexTemplate
+	inner, _ := body["template"].(map[string]interface{})
+	if inner == nil {
+		inner = map[string]interface{}{}
+		body["template"] = inner
+	}
+	settings, _ := inner["settings"].(map[string]interface{})
+	if settings == nil {
+		settings = map[string]interface{}{}
+		inner["settings"] = settings
+	}
+	settings["index.lifecycle.name"] = policy
+	if rolloverAlias != "" {
+		settings["index.lifecycle.rollover_alias"] = s.RunScopedIndex(rolloverAlias)
+	}
+
+	raw, err := json.Marshal(body)
+	require.NoError(s.t, err, "Failed to marshal index template %s", template)
+
+	putRes, err := esapi.IndicesPutIndexTemplateRequest{Name: template, Body: bytes.NewReader(raw)}.Do(s.ctx, client)
+	require.NoError(s.t, err, "Failed to update index template %s", template)
+	defer putRes.Body.Close()
+
+	if putRes.IsError() {
+		require.Fail(s.t, fmt.Sprintf("Failed to attach ILM policy %s to %s: %s", policy, template, putRes.String()))
+	}
+}
+
+// ILMExplain retorna o estado do índice no ILM
+func (s *IntegrationTestSuite) ILMExplain(index string) ILMStatus {
+	s.t.Helper()
+
+	client := s.ES()
+	require.NotNil(s.t, client, "Elasticsearch not configured")
+	index = s.RunScopedIndex(index)
+
+	res, err := esapi.ILMExplainLifecycleRequest{Index: index}.Do(s.ctx, client)
+	require.NoError(s.t, err, "Failed to explain ILM of %s", index)
+	defer res.Body.Close()
+
+	if res.IsError() {
+		require.Fail(s.t, fmt.Sprintf("Failed to explain ILM of %s: %s", index, res.String()))
+		return ILMStatus{}
+	}
+
+	var body struct {
+		Indices map[string]struct {
+			Managed    bool            `json:"managed"`
+			Policy     string          `json:"policy"`
+			Phase      string          `json:"phase"`
+			Action     string          `json:"action"`
+			Step       string          `json:"step"`
+			FailedStep string          `json:"failed_step"`
+			StepInfo   json.RawMessage `json:"step_info"`
+		} `json:"indices"`
+	}
+	require.NoError(s.t, json.NewDecoder(res.Body).Decode(&body), "Failed to decode ILM explain of %s", index)
+
+	for _, entry := range body.Indices {
+		status := ILMStatus{
+			Managed:    entry.Managed,
+			Policy:     entry.Policy,
+			Phase:      entry.Phase,
+			Action:     entry.Action,
+			Step:       entry.Step,
+			FailedStep: entry.FailedStep,
+		}
+		if len(entry.StepInfo) > 0 {
+			status.StepInfo = string(entry.StepInfo)
+		}
+		return status
+	}
+	require.Fail(s.t, fmt.Sprintf("Index %s not found in ILM explain", index))
+	return ILMStatus{}
+}
+
+// AssertILMPhase verifica que o índice é gerenciado pelo ILM e está na fase informada (hot, warm, delete...)
+func (s *IntegrationTestSuite) AssertILMPhase(index, phase string) {
+	s.t.Helper()
+
+	status := s.ILMExplain(index)
+	require.True(s.t, status.Managed, "Index %s is not managed by ILM", index)
+	require.Equal(s.t, phase, status.Phase, "Unexpected ILM phase of %s (policy %s, step %s)", index, status.Policy, status.Step)
+}
+
+// WaitForILMPhase aguarda o índice chegar à fase, falhando antes se o ILM entrar no step ERROR
+func (s *IntegrationTestSuite) WaitForILMPhase(index, phase string, timeout time.Duration) ILMStatus {
+	s.t.Helper()
+
+	deadline := time.Now().Add(timeout)
+	for {
+		status := s.ILMExplain(index)
+		if status.Phase == phase {
+			return status
+		}
+		if status.Step == "ERROR" {
+			require.Fail(s.t, fmt.Sprintf("ILM of %s failed at step %s: %s", index, status.FailedStep, status.StepInfo))
+			return status
+		}
+		if time.Now().After(deadline) {
+			require.Fail(s.t, fmt.Sprintf("Index %s did not reach ILM phase %s within %s (phase %q, action %q, step %q)",
+				index, phase, timeout, status.Phase, status.Action, status.Step))
+			return status
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+// ensureILMPollInterval reduz o poll_interval do ILM uma vez por cluster (não altera clusters externos)
+func (s *IntegrationTestSuite) ensureILMPollInterval() {
+	s.t.Helper()
+
+	client := s.ES()
+	if externalES() {
+		return
+	}
+	if _, ok := ilmPollIntervalApplied.Load(client); ok {
+		return
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"persistent": map[string]interface{}{"indices.lifecycle.poll_interval": ILMPollInterval},
+	})
+	require.NoError(s.t, err, "Failed to marshal ILM poll interval")
+
+	res, err := esapi.ClusterPutSettingsRequest{Body: bytes.NewReader(body)}.Do(s.ctx, client)
+	require.NoError(s.t, err, "Failed to set ILM poll interval")
+	defer res.Body.Close()
+
+	if res.IsError() {
+		require.Fail(s.t, fmt.Sprintf("Failed to set ILM poll interval: %s", res.String()))
+	}
+	ilmPollIntervalApplied.Store(client, struct{}{})
+}
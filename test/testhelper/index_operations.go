@@ -0,0 +1,155 @@
+package testhelper
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"github.com/stretchr/testify/require"
+)
+
+// indexOperationTimeout é o tempo máximo de espera pela conclusão de shrink/split/clone
+const indexOperationTimeout = 60 * time.Second
+
+// ShrinkIndex reduz o número de shards de src criando dst com a quantidade informada
+// O índice de origem é bloqueado para escrita durante a operação e desbloqueado ao final
+func (s *IntegrationTestSuite) ShrinkIndex(src, dst string, shards int) {
+	s.t.Helper()
+
+	s.resizeIndex("shrink", src, dst, shards)
+}
+
+// SplitIndex aumenta o número de shards de src criando dst com a quantidade informada
+// A quantidade deve ser múltipla do número de shards da origem
+func (s *IntegrationTestSuite) SplitIndex(src, dst string, shards int) {
+	s.t.Helper()
+
+	s.resizeIndex("split", src, dst, shards)
+}
+
+// CloneIndex cria dst como cópia exata de src (mesmo número de shards)
+func (s *IntegrationTestSuite) CloneIndex(src, dst string) {
+	s.t.Helper()
+
+	s.resizeIndex("clone", src, dst, 0)
+}
+
+// resizeIndex executa a operação de resize e aguarda o índice de destino ficar disponível
+func (s *IntegrationTestSuite) resizeIndex(operation, src, dst string, shards int) {
+	s.t.Helper()
+	src = s.RunScopedIndex(src)
+	dst = s.RunScopedIndex(dst)
+
+	// Garante que todos os shards da origem estão ativos antes de bloquear a escrita
+	s.waitForIndexHealth(src)
+
+	s.setWriteBlock(src, true)
+	defer s.setWriteBlock(src, false)
+
+	settings := map[string]interface{}{
+		// O destino herda o bloqueio de escrita da origem, então é removido explicitamente
+		"index.blocks.write": nil,
+	}
+	if shards > 0 {
+		settings["index.number_of_shards"] = shards
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"settings": settings})
+	require.NoError(s.t, err, "Failed to marshal %s settings", operation)
+
+	// Aguarda só o primário: com "all" as réplicas herdadas da origem nunca ficam ativas em single-node
+	var res *esapi.Response
+	switch operation {
+	case "shrink":
+		res, err = esapi.IndicesShrinkRequest{
+			Index:               src,
+			Target:              dst,
+			Body:                strings.NewReader(string(body)),
+			WaitForActiveShards: "1",
+		}.Do(s.ctx, s.ES())
+	case "split":
+		res, err = esapi.IndicesSplitRequest{
+			Index:               src,
+			Target:              dst,
+			Body:                strings.NewReader(string(body)),
+			WaitForActiveShards: "1",
+		}.Do(s.ctx, s.ES())
+	case "clone":
+		res, err = esapi.IndicesCloneRequest{
+			Index:               src,
+			Target:              dst,
+			Body:                strings.NewReader(string(body)),
+			WaitForActiveShards: "1",
+		}.Do(s.ctx, s.ES())
+	default:
+		require.Fail(s.t, fmt.Sprintf("Unknown index operation %s", operation))
+		return
+	}
+	require.NoError(s.t, err, "Failed to %s index %s into %s", operation, src, dst)
+	defer res.Body.Close()
+
+	if res.IsError() {
+		raw, _ := io.ReadAll(res.Body)
+		require.Fail(s.t, fmt.Sprintf("Failed to %s index %s into %s: %s %s", operation, src, dst, res.Status(), raw))
+	}
+
+	s.waitForIndexHealth(dst)
+}
+
+// setWriteBlock ativa ou remove o bloqueio de escrita de um índice
+func (s *IntegrationTestSuite) setWriteBlock(indexName string, blocked bool) {
+	s.t.Helper()
+
+	var value interface{}
+	if blocked {
+		value = true
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"index.blocks.write": value,
+	})
+	require.NoError(s.t, err, "Failed to marshal write block setting")
+
+	req := esapi.IndicesPutSettingsRequest{
+		Index: []string{indexName},
+		Body:  strings.NewReader(string(body)),
+	}
+
+	res, err := req.Do(s.ctx, s.ES())
+	require.NoError(s.t, err, "Failed to update write block of index %s", indexName)
+	defer res.Body.Close()
+
+	if res.IsError() {
+		require.Fail(s.t, fmt.Sprintf("Failed to update write block of index %s: %s", indexName, res.Status()))
+	}
+}
+
+// waitForIndexHealth aguarda o índice ficar yellow sem shards inicializando ou realocando
+// Em um cluster single-node as réplicas nunca são alocadas, por isso yellow é suficiente
+func (s *IntegrationTestSuite) waitForIndexHealth(indexName string) {
+	s.t.Helper()
+
+	req := esapi.ClusterHealthRequest{
+		Index:                       []string{indexName},
+		WaitForStatus:               "yellow",
+		WaitForNoInitializingShards: esapi.BoolPtr(true),
+		WaitForNoRelocatingShards:   esapi.BoolPtr(true),
+		Timeout:                     indexOperationTimeout,
+	}
+
+	res, err := req.Do(s.ctx, s.ES())
+	require.NoError(s.t, err, "Failed to wait for index %s health", indexName)
+	defer res.Body.Close()
+
+	var health struct {
+		Status   string `json:"status"`
+		TimedOut bool   `json:"timed_out"`
+	}
+	err = json.NewDecoder(res.Body).Decode(&health)
+	require.NoError(s.t, err, "Failed to decode cluster health response")
+
+	require.False(s.t, health.TimedOut, "Timed out waiting for index %s (status %s)", indexName, health.Status)
+}
@@ -0,0 +1,72 @@
+package testhelper
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Isolamento por prefixo de índice
+//
+// Alternativa ao filtro por tenant_id: cada teste recebe um prefixo próprio ("t_ab12f0_") aplicado a
+// todos os índices usados pelos helpers (RunScopedIndex/IndexName). Testes paralelos não compartilham
+// índices, então as queries não precisam filtrar por tenant_id, e a limpeza remove só os índices do
+// prefixo. A aplicação deve receber os nomes via suite.IndexName("products")
+
+// WithIndexPrefixIsolation ativa o isolamento por prefixo de índice por teste
+// Os índices do prefixo são removidos ao final do teste e por CleanElasticsearch
+func (b *IntegrationTestSuiteBuilder) WithIndexPrefixIsolation() *IntegrationTestSuiteBuilder {
+	b.indexPrefix = true
+	return b
+}
+
+// IndexName retorna o nome real do índice base para este teste (com prefixo e sufixo de execução,
+// quando ativos), para configurar a aplicação com o mesmo índice usado pelos helpers
+func (s *IntegrationTestSuite) IndexName(base string) string {
+	return s.RunScopedIndex(base)
+}
+
+// IndexPrefix retorna o prefixo dos índices do teste (vazio sem WithIndexPrefixIsolation)
+func (s *IntegrationTestSuite) IndexPrefix() string {
+	return s.indexPrefix
+}
+
+// enableIndexPrefix gera o prefixo do teste e registra a remoção dos índices dele
+func (s *IntegrationTestSuite) enableIndexPrefix() {
+	b := make([]byte, 3)
+	if _, err := rand.Read(b); err != nil {
+		s.indexPrefix = fmt.Sprintf("t_%x_", time.Now().UnixNano()&0xffffff)
+	} else {
+		s.indexPrefix = "t_" + hex.EncodeToString(b) + "_"
+	}
+
+	s.onCleanup(func() {
+		if err := s.deletePrefixedIndices(context.Background()); err != nil {
+			s.t.Logf("cleanup failed: %v", err)
+		}
+	})
+}
+
+// prefixedIndex aplica o prefixo do teste ao nome (idempotente); índices de sistema não recebem prefixo
+func (s *IntegrationTestSuite) prefixedIndex(name string) string {
+	if s.indexPrefix == "" || name == "" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, s.indexPrefix) {
+		return name
+	}
+	return s.indexPrefix + name
+}
+
+// deletePrefixedIndices remove todos os índices com o prefixo do teste, listando-os e removendo pelo nome
+func (s *IntegrationTestSuite) deletePrefixedIndices(ctx context.Context) error {
+	client := s.ES()
+	if client == nil || s.indexPrefix == "" {
+		return nil
+	}
+
+	if _, err := deleteIndicesMatching(ctx, client, s.indexPrefix+"*"); err != nil {
+		return fmt.Errorf("failed to delete indices with prefix %s: %w", s.indexPrefix, err)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package testhelper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrefixedIndex(t *testing.T) {
+	tests := []struct {
+		name     string
+		prefix   string
+		index    string
+		expected string
+	}{
+		{name: "Without isolation", prefix: "", index: "products", expected: "products"},
+		{name: "Plain index", prefix: "t_ab12f0_", index: "products", expected: "t_ab12f0_products"},
+		{name: "Already prefixed index", prefix: "t_ab12f0_", index: "t_ab12f0_products", expected: "t_ab12f0_products"},
+		{name: "Empty name", prefix: "t_ab12f0_", index: "", expected: ""},
+		{name: "System index", prefix: "t_ab12f0_", index: ".kibana", expected: ".kibana"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			suite := &IntegrationTestSuite{indexPrefix: tt.prefix}
+
+			assert.Equal(t, tt.expected, suite.prefixedIndex(tt.index))
+		})
+	}
+
+	t.Run("IndexName applies the prefix without the run suffix", func(t *testing.T) {
+		t.Setenv("USE_EXTERNAL_ES", "false")
+		suite := &IntegrationTestSuite{indexPrefix: "t_ab12f0_"}
+
+		assert.Equal(t, "t_ab12f0_products", suite.IndexName("products"))
+		assert.Equal(t, "t_ab12f0_", suite.IndexPrefix())
+	})
+}
@@ -0,0 +1,109 @@
+package testhelper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"github.com/stretchr/testify/require"
+)
+
+// PutIndexTemplate registra um index template composable (_index_template) e retorna o nome real
+// body é o corpo da API: index_patterns, template (settings/mappings/aliases), composed_of, priority...
+// Com ES externo o nome, os padrões e os component templates de composed_of recebem o sufixo da execução,
+// para que o template só case com os índices desta execução. O template é removido ao final do teste
+func (s *IntegrationTestSuite) PutIndexTemplate(name string, body map[string]interface{}) string {
+	s.t.Helper()
+	defer s.trackOperation("PutIndexTemplate")()
+
+	client := s.ES()
+	require.NotNil(s.t, client, "Elasticsearch not configured")
+
+	name = s.RunScopedIndex(name)
+	body = s.runScopedTemplateBody(body)
+
+	raw, err := json.Marshal(body)
+	require.NoError(s.t, err, "Failed to marshal index template %s", name)
+
+	res, err := esapi.IndicesPutIndexTemplateRequest{Name: name, Body: bytes.NewReader(raw)}.Do(s.ctx, client)
+	require.NoError(s.t, err, "Failed to put index template %s", name)
+	defer res.Body.Close()
+
+	if res.IsError() {
+		require.Fail(s.t, fmt.Sprintf("Failed to put index template %s: %s", name, res.String()))
+	}
+
+	s.t.Cleanup(func() {
+		res, err := client.Indices.DeleteIndexTemplate(name)
+		if err == nil {
+			res.Body.Close()
+		}
+	})
+
+	return name
+}
+
+// PutComponentTemplate registra um component template (_component_template), referenciado pelos
+// index templates em composed_of, e retorna o nome real. É removido ao final do teste
+func (s *IntegrationTestSuite) PutComponentTemplate(name string, body map[string]interface{}) string {
+	s.t.Helper()
+	defer s.trackOperation("PutComponentTemplate")()
+
+	client := s.ES()
+	require.NotNil(s.t, client, "Elasticsearch not configured")
+
+	name = s.RunScopedIndex(name)
+
+	raw, err := json.Marshal(body)
+	require.NoError(s.t, err, "Failed to marshal component template %s", name)
+
+	res, err := esapi.ClusterPutComponentTemplateRequest{Name: name, Body: bytes.NewReader(raw)}.Do(s.ctx, client)
+	require.NoError(s.t, err, "Failed to put component template %s", name)
+	defer res.Body.Close()
+
+	if res.IsError() {
+		require.Fail(s.t, fmt.Sprintf("Failed to put component template %s: %s", name, res.String()))
+	}
+
+	// Registrado antes dos index templates, então é removido depois deles (Cleanup é LIFO)
+	s.t.Cleanup(func() {
+		res, err := client.Cluster.DeleteComponentTemplate(name)
+		if err == nil {
+			res.Body.Close()
+		}
+	})
+
+	return name
+}
+
+// runScopedTemplateBody aplica o sufixo da execução aos padrões e ao composed_of (só com ES externo)
+func (s *IntegrationTestSuite) runScopedTemplateBody(body map[string]interface{}) map[string]interface{} {
+	if !externalES() {
+		return body
+	}
+
+	scoped := make(map[string]interface{}, len(body))
+	for key, value := range body {
+		scoped[key] = value
+	}
+	for _, key := range []string{"index_patterns", "composed_of"} {
+		switch names := body[key].(type) {
+		case string:
+			scoped[key] = s.RunScopedIndex(names)
+		case []string:
+			out := make([]string, len(names))
+			for i, name := range names {
+				out[i] = s.RunScopedIndex(name)
+			}
+			scoped[key] = out
+		case []interface{}:
+			out := make([]interface{}, len(names))
+			for i, name := range names {
+				out[i] = s.RunScopedIndex(fmt.Sprint(name))
+			}
+			scoped[key] = out
+		}
+	}
+	return scoped
+}
@@ -0,0 +1,319 @@
+package testhelper
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+// RecordedQuery representa uma consulta executada por um *sql.DB instrumentado
+type RecordedQuery struct {
+	Query    string
+	Args     []interface{}
+	Duration time.Duration
+	Err      error
+	At       time.Time
+}
+
+// QueryRecorder armazena as consultas executadas através de uma conexão instrumentada
+type QueryRecorder struct {
+	mu      sync.Mutex
+	queries []RecordedQuery
+}
+
+// Queries retorna uma cópia das consultas registradas, na ordem de execução
+func (r *QueryRecorder) Queries() []RecordedQuery {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]RecordedQuery(nil), r.queries...)
+}
+
+// Matching retorna as consultas cujo SQL casa com o padrão (regexp, sem diferenciar maiúsculas)
+func (r *QueryRecorder) Matching(pattern string) []RecordedQuery {
+	re := regexp.MustCompile("(?i)" + pattern)
+
+	var matched []RecordedQuery
+	for _, q := range r.Queries() {
+		if re.MatchString(q.Query) {
+			matched = append(matched, q)
+		}
+	}
+	return matched
+}
+
+// Reset descarta as consultas registradas
+func (r *QueryRecorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.queries = nil
+}
+
+// record adiciona uma consulta ao registro
+func (r *QueryRecorder) record(query string, args []driver.NamedValue, start time.Time, err error) {
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.queries = append(r.queries, RecordedQuery{
+		Query:    query,
+		Args:     values,
+		Duration: time.Since(start),
+		Err:      err,
+		At:       start,
+	})
+}
+
+// OpenInstrumentedPostgres abre um *sql.DB (driver lib/pq) que registra todas as consultas no recorder
+func OpenInstrumentedPostgres(dsn string, recorder *QueryRecorder) (*sql.DB, error) {
+	connector, err := pq.NewConnector(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create postgres connector: %w", err)
+	}
+	return sql.OpenDB(&instrumentedConnector{Connector: connector, recorder: recorder}), nil
+}
+
+// PostgresInstrumented retorna um *sql.DB para o PostgreSQL da suite que registra consultas, durações e erros
+// A conexão é criada uma vez por suite e fechada ao final do teste
+func (s *IntegrationTestSuite) PostgresInstrumented() *sql.DB {
+	s.t.Helper()
+
+	if s.instrumentedPG != nil {
+		return s.instrumentedPG
+	}
+
+	pg := s.sharedPG
+	if pg == nil && s.builder != nil {
+		pg = s.builder.sharedPG
+	}
+	require.NotNil(s.t, pg, "PostgreSQL not configured, use WithPostgres()")
+
+	s.queryRecorder = &QueryRecorder{}
+	db, err := OpenInstrumentedPostgres(pg.GetURL(), s.queryRecorder)
+	require.NoError(s.t, err, "Failed to open instrumented PostgreSQL connection")
+
+	s.instrumentedPG = db
+	s.t.Cleanup(func() {
+		db.Close()
+	})
+
+	return db
+}
+
+// QueryRecorder retorna o registro de consultas da conexão instrumentada (nil se não criada)
+func (s *IntegrationTestSuite) QueryRecorder() *QueryRecorder {
+	return s.queryRecorder
+}
+
+// AssertQueryExecuted verifica se alguma consulta casando com o padrão foi executada pela conexão instrumentada
+func (s *IntegrationTestSuite) AssertQueryExecuted(pattern string) {
+	s.t.Helper()
+
+	require.NotNil(s.t, s.queryRecorder, "Instrumented connection not created, use PostgresInstrumented()")
+
+	if len(s.queryRecorder.Matching(pattern)) == 0 {
+		require.Fail(s.t, fmt.Sprintf("No query matching %q was executed", pattern),
+			"Executed queries:\n%s", formatRecordedQueries(s.queryRecorder.Queries()))
+	}
+}
+
+// AssertQueryNotExecuted verifica que nenhuma consulta casando com o padrão foi executada
+func (s *IntegrationTestSuite) AssertQueryNotExecuted(pattern string) {
+	s.t.Helper()
+
+	require.NotNil(s.t, s.queryRecorder, "Instrumented connection not created, use PostgresInstrumented()")
+
+	if matched := s.queryRecorder.Matching(pattern); len(matched) > 0 {
+		require.Fail(s.t, fmt.Sprintf("Expected no query matching %q, got %d", pattern, len(matched)),
+			"Matching queries:\n%s", formatRecordedQueries(matched))
+	}
+}
+
+// AssertQueryCount verifica quantas consultas casando com o padrão foram executadas
+func (s *IntegrationTestSuite) AssertQueryCount(pattern string, expected int) {
+	s.t.Helper()
+
+	require.NotNil(s.t, s.queryRecorder, "Instrumented connection not created, use PostgresInstrumented()")
+
+	matched := s.queryRecorder.Matching(pattern)
+	require.Len(s.t, matched, expected, "Unexpected number of queries matching %q:\n%s", pattern, formatRecordedQueries(matched))
+}
+
+// formatRecordedQueries formata as consultas para mensagens de falha
+func formatRecordedQueries(queries []RecordedQuery) string {
+	if len(queries) == 0 {
+		return "  (none)"
+	}
+
+	var b strings.Builder
+	for _, q := range queries {
+		status := "ok"
+		if q.Err != nil {
+			status = q.Err.Error()
+		}
+		fmt.Fprintf(&b, "  [%s] %s (%s)\n", q.Duration.Round(time.Microsecond), strings.Join(strings.Fields(q.Query), " "), status)
+	}
+	return b.String()
+}
+
+// instrumentedConnector envolve um driver.Connector registrando as consultas das conexões criadas
+type instrumentedConnector struct {
+	driver.Connector
+	recorder *QueryRecorder
+}
+
+// Connect abre uma conexão do driver original envolvida pelo instrumentedConn
+func (c *instrumentedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedConn{Conn: conn, recorder: c.recorder}, nil
+}
+
+// instrumentedConn registra as consultas executadas diretamente ou via prepared statements
+type instrumentedConn struct {
+	driver.Conn
+	recorder *QueryRecorder
+}
+
+// QueryContext implementa driver.QueryerContext
+func (c *instrumentedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	if err != driver.ErrSkip {
+		c.recorder.record(query, args, start, err)
+	}
+	return rows, err
+}
+
+// ExecContext implementa driver.ExecerContext
+func (c *instrumentedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	start := time.Now()
+	result, err := execer.ExecContext(ctx, query, args)
+	if err != driver.ErrSkip {
+		c.recorder.record(query, args, start, err)
+	}
+	return result, err
+}
+
+// PrepareContext implementa driver.ConnPrepareContext
+func (c *instrumentedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var (
+		stmt driver.Stmt
+		err  error
+	)
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedStmt{Stmt: stmt, query: query, recorder: c.recorder}, nil
+}
+
+// BeginTx implementa driver.ConnBeginTx
+func (c *instrumentedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+// Ping implementa driver.Pinger
+func (c *instrumentedConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+// ResetSession implementa driver.SessionResetter
+func (c *instrumentedConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+// IsValid implementa driver.Validator
+func (c *instrumentedConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+// instrumentedStmt registra as execuções de um prepared statement
+type instrumentedStmt struct {
+	driver.Stmt
+	query    string
+	recorder *QueryRecorder
+}
+
+// ExecContext implementa driver.StmtExecContext
+func (s *instrumentedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+
+	var (
+		result driver.Result
+		err    error
+	)
+	if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+		result, err = execer.ExecContext(ctx, args)
+	} else {
+		result, err = s.Stmt.Exec(namedToValues(args))
+	}
+
+	s.recorder.record(s.query, args, start, err)
+	return result, err
+}
+
+// QueryContext implementa driver.StmtQueryContext
+func (s *instrumentedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+
+	var (
+		rows driver.Rows
+		err  error
+	)
+	if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = queryer.QueryContext(ctx, args)
+	} else {
+		rows, err = s.Stmt.Query(namedToValues(args))
+	}
+
+	s.recorder.record(s.query, args, start, err)
+	return rows, err
+}
+
+// namedToValues converte argumentos nomeados para a API antiga de driver.Stmt
+func namedToValues(args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	return values
+}
@@ -6,8 +6,14 @@ import (
 	"database/sql"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -20,7 +26,7 @@ import (
 // IntegrationTestSuite fornece funcionalidades base para testes de integração
 // Agora integrada com o TestDependenciesBuilder para suporte a múltiplas dependências
 type IntegrationTestSuite struct {
-	t        *testing.T
+	t        TestingT
 	ctx      context.Context
 	tenantID string
 	
@@ -28,6 +34,35 @@ type IntegrationTestSuite struct {
 	sharedES    *SharedElasticsearch
 	sharedMongo *SharedMongoDB
 	sharedPG    *SharedPostgreSQL
+	sharedCassandra *SharedCassandra
+	sharedNeo4j *SharedNeo4j
+	
+	// Conexão PostgreSQL instrumentada (criada sob demanda)
+	instrumentedPG *sql.DB
+	queryRecorder  *QueryRecorder
+	
+	// Client Elasticsearch que registra as requisições (RecordESRequests)
+	recordedES *elasticsearch.Client
+	esRecorder *ESRequestRecorder
+	
+	// Operações e workers em andamento (aguardados pelo Drain)
+	ops *operationTracker
+	
+	// Implementações em memória em vez de containers (WithFakes)
+	fakes bool
+
+	
+	// Início da suite (traces anteriores são ignorados pelo FindTraces)
+	startedAt time.Time
+	
+	// Origem dos arquivos de fixtures (WithFixturesFS); nil lê do disco
+	fixtures fs.FS
+	
+	// Prefixo dos índices do teste (WithIndexPrefixIsolation); vazio usa os nomes sem prefixo
+	indexPrefix string
+	
+	// Captura de estado pendente (CaptureState), executada antes das limpezas que removem dados
+	capture *stateCapture
 	
 	// Builder para uso avançado
 	builder *TestDependenciesBuilder
@@ -41,140 +76,1878 @@ func NewIntegrationTestSuite(t *testing.T) *IntegrationTestSuite {
 		ctx:      context.Background(),
 		sharedES: GetSharedElasticsearch(),
 		tenantID: GenerateTenantID(),
+		ops:      newOperationTracker(),
+		startedAt: time.Now(),
 	}
 }
 
 // NewIntegrationTestSuiteWithBuilder cria uma suite usando o TestDependenciesBuilder
-func NewIntegrationTestSuiteWithBuilder(t *testing.T, builder *TestDependenciesBuilder) *IntegrationTestSuite {
+func NewIntegrationTestSuiteWithBuilder(t TestingT, builder *TestDependenciesBuilder) *IntegrationTestSuite {
 	suite := &IntegrationTestSuite{
 		t:        t,
 		ctx:      context.Background(),
 		builder:  builder,
 		tenantID: GenerateTenantID(),
+		ops:      newOperationTracker(),
+		startedAt: time.Now(),
+		fixtures: builder.fixturesFS,
 	}
 	
 	// Se o builder tem Elasticsearch, inicializa sharedES para compatibilidade
 	if builder.ESConn != nil {
-		suite.sharedES = GetSharedElasticsearch()
+		suite.sharedES = builder.sharedES
+		if suite.sharedES == nil {
+			suite.sharedES = GetSharedElasticsearch()
+		}
 	}
 	
 	// Se o builder tem MongoDB, inicializa sharedMongo
 	if builder.MongoConn != nil {
-		suite.sharedMongo = GetSharedMongoDB()
+		suite.sharedMongo = builder.sharedMongo
+		if suite.sharedMongo == nil {
+			suite.sharedMongo = GetSharedMongoDB()
+		}
 	}
 	
 	// Se o builder tem PostgreSQL, inicializa sharedPG
 	if builder.PostgresConn != nil {
-		suite.sharedPG = GetSharedPostgreSQL()
+		suite.sharedPG = builder.sharedPG
+		if suite.sharedPG == nil {
+			suite.sharedPG = GetSharedPostgreSQL()
+		}
+	}
+	
+	// Se o builder tem Cassandra, inicializa sharedCassandra
+	if builder.CassandraConn != nil {
+		suite.sharedCassandra = builder.CassandraConn
+	}
+	
+	// Se o builder tem Neo4j, inicializa sharedNeo4j
+	if builder.Neo4jConn != nil {
+		suite.sharedNeo4j = builder.Neo4jConn
+	}
+	
+	return suite
+}
+
+// NewIntegrationTestSuiteBuilder retorna um builder para configuração fluente
+func NewIntegrationTestSuiteBuilder(t *testing.T) *IntegrationTestSuiteBuilder {
+	return &IntegrationTestSuiteBuilder{
+		t:            t,
+		depBuilder:   NewTestDependenciesBuilder(),
+	}
+}
+
+// IntegrationTestSuiteBuilder permite configuração fluente da suite de testes
+type IntegrationTestSuiteBuilder struct {
+	t          *testing.T
+	depBuilder *TestDependenciesBuilder
+	versionConstraints []versionConstraint
+	useFakes   bool
+	preflight  bool
+	verifyCleanup       bool
+	verifyCleanupIgnore []string
+	indexPrefix         bool
+}
+
+// WithPreflight verifica o ambiente (Docker, memória, vm.max_map_count, disco) antes de subir os containers
+func (b *IntegrationTestSuiteBuilder) WithPreflight() *IntegrationTestSuiteBuilder {
+	b.preflight = true
+	return b
+}
+
+// WithCleanupVerification falha o teste se o estado fora do tenant não voltar ao do Build após as limpezas
+// (ver VerifyCleanup); também ligada para todas as suites com TEST_VERIFY_CLEANUP=true
+func (b *IntegrationTestSuiteBuilder) WithCleanupVerification(ignore ...string) *IntegrationTestSuiteBuilder {
+	b.verifyCleanup = true
+	b.verifyCleanupIgnore = append(b.verifyCleanupIgnore, ignore...)
+	return b
+}
+
+// WithPostgres configura PostgreSQL
+func (b *IntegrationTestSuiteBuilder) WithPostgres(sqlFilePaths ...string) *IntegrationTestSuiteBuilder {
+	b.depBuilder.WithPostgres(sqlFilePaths...)
+	return b
+}
+
+// WithPostgresImage define a imagem do PostgreSQL (ex.: TimescaleDB)
+func (b *IntegrationTestSuiteBuilder) WithPostgresImage(image string) *IntegrationTestSuiteBuilder {
+	b.depBuilder.WithPostgresImage(image)
+	return b
+}
+
+// WithMongo configura MongoDB
+func (b *IntegrationTestSuiteBuilder) WithMongo() *IntegrationTestSuiteBuilder {
+	b.depBuilder.WithMongo()
+	return b
+}
+
+// WithMongoReplicaSet configura MongoDB em replica set (transações e change streams)
+func (b *IntegrationTestSuiteBuilder) WithMongoReplicaSet() *IntegrationTestSuiteBuilder {
+	b.depBuilder.WithMongoReplicaSet()
+	return b
+}
+
+// WithElasticsearch configura Elasticsearch
+func (b *IntegrationTestSuiteBuilder) WithElasticsearch() *IntegrationTestSuiteBuilder {
+	b.depBuilder.WithElasticsearch()
+	return b
+}
+
+// WithElasticsearchSecurity configura Elasticsearch com segurança (TLS e credenciais)
+func (b *IntegrationTestSuiteBuilder) WithElasticsearchSecurity() *IntegrationTestSuiteBuilder {
+	b.depBuilder.WithElasticsearchSecurity()
+	return b
+}
+
+// WithCassandra configura Cassandra
+func (b *IntegrationTestSuiteBuilder) WithCassandra(cqlFilePaths ...string) *IntegrationTestSuiteBuilder {
+	b.depBuilder.WithCassandra(cqlFilePaths...)
+	return b
+}
+
+// WithClickHouse configura ClickHouse
+func (b *IntegrationTestSuiteBuilder) WithClickHouse(sqlFilePaths ...string) *IntegrationTestSuiteBuilder {
+	b.depBuilder.WithClickHouse(sqlFilePaths...)
+	return b
+}
+
+// WithDynamoDB configura DynamoDB Local
+func (b *IntegrationTestSuiteBuilder) WithDynamoDB() *IntegrationTestSuiteBuilder {
+	b.depBuilder.WithDynamoDB()
+	return b
+}
+
+// WithMemcached configura memcached
+func (b *IntegrationTestSuiteBuilder) WithMemcached() *IntegrationTestSuiteBuilder {
+	b.depBuilder.WithMemcached()
+	return b
+}
+
+// WithNeo4j configura Neo4j
+func (b *IntegrationTestSuiteBuilder) WithNeo4j(cypherFilePaths ...string) *IntegrationTestSuiteBuilder {
+	b.depBuilder.WithNeo4j(cypherFilePaths...)
+	return b
+}
+
+// WithTemporal configura o dev server do Temporal
+func (b *IntegrationTestSuiteBuilder) WithTemporal() *IntegrationTestSuiteBuilder {
+	b.depBuilder.WithTemporal()
+	return b
+}
+
+// WithVault configura Vault em modo dev
+func (b *IntegrationTestSuiteBuilder) WithVault() *IntegrationTestSuiteBuilder {
+	b.depBuilder.WithVault()
+	return b
+}
+
+// WithWireMock configura WireMock
+func (b *IntegrationTestSuiteBuilder) WithWireMock(mappingsDir string) *IntegrationTestSuiteBuilder {
+	b.depBuilder.WithWireMock(mappingsDir)
+	return b
+}
+
+// WithMailHog configura MailHog
+func (b *IntegrationTestSuiteBuilder) WithMailHog() *IntegrationTestSuiteBuilder {
+	b.depBuilder.WithMailHog()
+	return b
+}
+
+// WithAzurite configura Azurite
+func (b *IntegrationTestSuiteBuilder) WithAzurite() *IntegrationTestSuiteBuilder {
+	b.depBuilder.WithAzurite()
+	return b
+}
+
+// WithGCS configura o fake GCS
+func (b *IntegrationTestSuiteBuilder) WithGCS() *IntegrationTestSuiteBuilder {
+	b.depBuilder.WithGCS()
+	return b
+}
+
+// WithPubSubEmulator configura o emulador do Google Pub/Sub
+func (b *IntegrationTestSuiteBuilder) WithPubSubEmulator() *IntegrationTestSuiteBuilder {
+	b.depBuilder.WithPubSubEmulator()
+	return b
+}
+
+// WithElasticMQ configura o ElasticMQ (SQS) com as filas informadas
+func (b *IntegrationTestSuiteBuilder) WithElasticMQ(queueNames ...string) *IntegrationTestSuiteBuilder {
+	b.depBuilder.WithElasticMQ(queueNames...)
+	return b
+}
+
+// WithConsul configura o Consul em modo dev
+func (b *IntegrationTestSuiteBuilder) WithConsul() *IntegrationTestSuiteBuilder {
+	b.depBuilder.WithConsul()
+	return b
+}
+
+// WithInfluxDB configura o InfluxDB 2.x
+func (b *IntegrationTestSuiteBuilder) WithInfluxDB() *IntegrationTestSuiteBuilder {
+	b.depBuilder.WithInfluxDB()
+	return b
+}
+
+// WithSQLServer configura SQL Server
+func (b *IntegrationTestSuiteBuilder) WithSQLServer(sqlFilePaths ...string) *IntegrationTestSuiteBuilder {
+	b.depBuilder.WithSQLServer(sqlFilePaths...)
+	return b
+}
+
+// WithCouchbase configura Couchbase
+func (b *IntegrationTestSuiteBuilder) WithCouchbase() *IntegrationTestSuiteBuilder {
+	b.depBuilder.WithCouchbase()
+	return b
+}
+
+// WithSolr configura Solr com o core informado (e schema opcional)
+func (b *IntegrationTestSuiteBuilder) WithSolr(coreName string, schemaPath ...string) *IntegrationTestSuiteBuilder {
+	b.depBuilder.WithSolr(coreName, schemaPath...)
+	return b
+}
+
+// WithMeilisearch configura Meilisearch
+func (b *IntegrationTestSuiteBuilder) WithMeilisearch() *IntegrationTestSuiteBuilder {
+	b.depBuilder.WithMeilisearch()
+	return b
+}
+
+// WithTypesense configura Typesense
+func (b *IntegrationTestSuiteBuilder) WithTypesense() *IntegrationTestSuiteBuilder {
+	b.depBuilder.WithTypesense()
+	return b
+}
+
+// WithStaleStateRetry liga o retry único em conexões perdidas por recriação do shared container
+func (b *IntegrationTestSuiteBuilder) WithStaleStateRetry() *IntegrationTestSuiteBuilder {
+	b.depBuilder.WithStaleStateRetry()
+	return b
+}
+
+// WithExternalFallback define a política para dependências externas inacessíveis; com
+// ExternalFallbackSkip o Build pula o teste (t.Skip) em vez de retornar erro
+func (b *IntegrationTestSuiteBuilder) WithExternalFallback(policy ExternalFallbackPolicy) *IntegrationTestSuiteBuilder {
+	b.depBuilder.WithExternalFallback(policy)
+	return b
+}
+
+// WithQuietMode suprime banners e limita a saída de log por teste, com uma linha de resumo por suite
+func (b *IntegrationTestSuiteBuilder) WithQuietMode(maxLogKB ...int) *IntegrationTestSuiteBuilder {
+	b.depBuilder.WithQuietMode(maxLogKB...)
+	return b
+}
+
+// WithFixturesFS faz os loaders de fixtures lerem de fsys (ex.: embed.FS)
+func (b *IntegrationTestSuiteBuilder) WithFixturesFS(fsys fs.FS) *IntegrationTestSuiteBuilder {
+	b.depBuilder.WithFixturesFS(fsys)
+	return b
+}
+
+// WithKibana configura Kibana (e Elasticsearch)
+func (b *IntegrationTestSuiteBuilder) WithKibana() *IntegrationTestSuiteBuilder {
+	b.depBuilder.WithKibana()
+	return b
+}
+
+// WithLogstash configura Logstash com o pipeline informado (e Elasticsearch)
+func (b *IntegrationTestSuiteBuilder) WithLogstash(pipelineConfPath string) *IntegrationTestSuiteBuilder {
+	b.depBuilder.WithLogstash(pipelineConfPath)
+	return b
+}
+
+// WithAPMServer configura o apm-server (e Elasticsearch)
+func (b *IntegrationTestSuiteBuilder) WithAPMServer() *IntegrationTestSuiteBuilder {
+	b.depBuilder.WithAPMServer()
+	return b
+}
+
+// WithPrometheus configura Prometheus com os alvos de scrape informados
+func (b *IntegrationTestSuiteBuilder) WithPrometheus(scrapeTargets ...string) *IntegrationTestSuiteBuilder {
+	b.depBuilder.WithPrometheus(scrapeTargets...)
+	return b
+}
+
+// WithPrometheusScrapeConfigs configura Prometheus com jobs de scrape completos
+func (b *IntegrationTestSuiteBuilder) WithPrometheusScrapeConfigs(configs ...PrometheusScrapeConfig) *IntegrationTestSuiteBuilder {
+	b.depBuilder.WithPrometheusScrapeConfigs(configs...)
+	return b
+}
+
+// WithJaeger configura Jaeger all-in-one
+func (b *IntegrationTestSuiteBuilder) WithJaeger() *IntegrationTestSuiteBuilder {
+	b.depBuilder.WithJaeger()
+	return b
+}
+
+// WithOTelCollector configura o OpenTelemetry Collector com a configuração informada (vazia usa a padrão)
+func (b *IntegrationTestSuiteBuilder) WithOTelCollector(configPath string) *IntegrationTestSuiteBuilder {
+	b.depBuilder.WithOTelCollector(configPath)
+	return b
+}
+
+// WithDebezium configura Kafka Connect com o conector Debezium Postgres (e PostgreSQL)
+func (b *IntegrationTestSuiteBuilder) WithDebezium() *IntegrationTestSuiteBuilder {
+	b.depBuilder.WithDebezium()
+	return b
+}
+
+// WithKafka configura o broker Kafka compartilhado
+func (b *IntegrationTestSuiteBuilder) WithKafka() *IntegrationTestSuiteBuilder {
+	b.depBuilder.WithKafka()
+	return b
+}
+
+// WithSchemaRegistry configura o Schema Registry (e o Kafka)
+func (b *IntegrationTestSuiteBuilder) WithSchemaRegistry() *IntegrationTestSuiteBuilder {
+	b.depBuilder.WithSchemaRegistry()
+	return b
+}
+
+// WithMQTT configura o broker MQTT (Mosquitto)
+func (b *IntegrationTestSuiteBuilder) WithMQTT() *IntegrationTestSuiteBuilder {
+	b.depBuilder.WithMQTT()
+	return b
+}
+
+// WithArtemis configura o ActiveMQ Artemis
+func (b *IntegrationTestSuiteBuilder) WithArtemis() *IntegrationTestSuiteBuilder {
+	b.depBuilder.WithArtemis()
+	return b
+}
+
+// Build constrói e retorna a IntegrationTestSuite
+func (b *IntegrationTestSuiteBuilder) Build() (*IntegrationTestSuite, error) {
+	// Com fakes nenhum container é iniciado; os testes usam implementações em memória
+	if b.useFakes {
+		suite := NewIntegrationTestSuiteWithBuilder(b.t, NewTestDependenciesBuilder())
+		suite.fakes = true
+		suite.fixtures = b.depBuilder.fixturesFS
+		return suite, nil
+	}
+	
+	// Ambiente sem Docker, memória ou vm.max_map_count adequados: pula/falha antes de subir containers
+	if b.preflight {
+		Preflight(context.Background()).Apply(b.t)
+	}
+	
+	start := time.Now()
+	deps, err := b.depBuilder.Build()
+	if err != nil {
+		// Instância externa inacessível com política skip: o teste é pulado em vez de falhar
+		var unavailable *ExternalUnavailableError
+		if errors.As(err, &unavailable) && unavailable.Skip() {
+			b.t.Skipf("Skipping: %v", unavailable)
+		}
+		return nil, err
+	}
+	
+	// Tarefas de limpeza do builder (ex.: varredura dos recursos da execução em modo externo) rodam ao
+	// final do teste, depois dos t.Cleanup registrados pela suite e pelo teste
+	b.t.Cleanup(func() { deps.CleanupT(b.t) })
+	
+	suite := NewIntegrationTestSuiteWithBuilder(b.t, deps)
+	suite.startQuietSummary(time.Since(start))
+	
+	if b.indexPrefix {
+		suite.enableIndexPrefix()
+	}
+	
+	// Fotografa o estado antes de qualquer limpeza registrada pelo teste (t.Cleanup roda em ordem inversa)
+	if b.verifyCleanup || cleanupVerificationFromEnv() {
+		suite.VerifyCleanup(b.verifyCleanupIgnore...)
+	}
+	
+	// Falha cedo se alguma dependência (ex.: externa) estiver na versão errada
+	// A limpeza do builder já está registrada no t.Cleanup acima
+	if err := suite.checkVersionConstraints(b.versionConstraints); err != nil {
+		return nil, err
+	}
+	
+	return suite, nil
+}
+
+// Setup inicializa a suite e limpa o estado do Elasticsearch
+func (s *IntegrationTestSuite) Setup() {
+	s.t.Helper()
+	
+	// Inicia o container compartilhado
+	err := s.sharedES.Start(context.Background())
+	// err := s.sharedES.Start(s.ctx)
+	require.NoError(s.t, err, "Failed to start shared Elasticsearch")
+	
+	// Com tenantID, não precisamos limpar todos os índices
+	// Cada teste terá isolamento automático via tenantID
+}
+
+// Teardown limpa recursos se necessário
+func (s *IntegrationTestSuite) Teardown() {
+	s.t.Helper()
+	
+	// Aguarda helpers e workers em andamento antes de liberar as conexões
+	s.Drain(drainTimeout())
+	
+	// Com container compartilhado, não paramos a cada teste
+	// O container será limpo automaticamente pelo testcontainers no final
+}
+
+// ES retorna o cliente Elasticsearch
+func (s *IntegrationTestSuite) ES() *elasticsearch.Client {
+	if s.recordedES != nil {
+		return s.recordedES
+	}
+	if s.builder != nil && s.builder.ESConn != nil {
+		return s.builder.ESConn
+	}
+	if s.sharedES == nil {
+		return nil
+	}
+	return s.sharedES.GetClient()
+}
+
+// Postgres retorna a conexão PostgreSQL (se configurada via builder)
+func (s *IntegrationTestSuite) Postgres() *sql.DB {
+	if s.builder != nil && s.builder.PostgresConn != nil {
+		return s.builder.PostgresConn
+	}
+	if s.sharedPG != nil {
+		return s.sharedPG.GetConnection()
+	}
+	return nil
+}
+
+// Mongo retorna o database MongoDB principal (se configurado via builder)
+func (s *IntegrationTestSuite) Mongo() *mongo.Database {
+	if s.builder != nil && s.builder.MongoConn != nil {
+		return s.builder.MongoConn
+	}
+	if s.sharedMongo != nil {
+		return s.sharedMongo.GetDatabase()
+	}
+	return nil
+}
+
+// MongoDW retorna o database MongoDB DW (se configurado via builder)
+func (s *IntegrationTestSuite) MongoDW() *mongo.Database {
+	if s.builder != nil && s.builder.MongoConnDW != nil {
+		return s.builder.MongoConnDW
+	}
+	if s.sharedMongo != nil {
+		return s.sharedMongo.GetDatabaseDW()
+	}
+	return nil
+}
+
+// Cassandra retorna o Cassandra compartilhado (se configurado via builder)
+func (s *IntegrationTestSuite) Cassandra() *SharedCassandra {
+	return s.sharedCassandra
+}
+
+// CassandraKeyspace retorna o keyspace do tenant da suite, criando-o se necessário
+func (s *IntegrationTestSuite) CassandraKeyspace() string {
+	s.t.Helper()
+	
+	require.NotNil(s.t, s.sharedCassandra, "Cassandra not configured, use WithCassandra()")
+	
+	keyspace, err := s.sharedCassandra.CreateKeyspace(s.ctx, s.tenantID)
+	require.NoError(s.t, err, "Failed to create Cassandra keyspace")
+	return keyspace
+}
+
+// ClickHouse retorna a conexão ClickHouse (se configurada via builder)
+func (s *IntegrationTestSuite) ClickHouse() *sql.DB {
+	if s.builder != nil {
+		return s.builder.ClickHouseConn
+	}
+	return nil
+}
+
+// DynamoDB retorna o DynamoDB compartilhado (se configurado via builder)
+func (s *IntegrationTestSuite) DynamoDB() *SharedDynamoDB {
+	if s.builder != nil && s.builder.sharedDynamo != nil {
+		return s.builder.sharedDynamo
+	}
+	return nil
+}
+
+// CreateDynamoTable cria uma tabela a partir de um arquivo JSON de definição e retorna o nome
+func (s *IntegrationTestSuite) CreateDynamoTable(definitionPath string) string {
+	s.t.Helper()
+	
+	require.NotNil(s.t, s.DynamoDB(), "DynamoDB not configured, use WithDynamoDB()")
+	
+	name, err := s.DynamoDB().CreateTableFromFile(s.ctx, definitionPath)
+	require.NoError(s.t, err, "Failed to create DynamoDB table")
+	return name
+}
+
+// DeleteDynamoTable remove uma tabela do DynamoDB
+func (s *IntegrationTestSuite) DeleteDynamoTable(tableName string) {
+	s.t.Helper()
+	
+	require.NotNil(s.t, s.DynamoDB(), "DynamoDB not configured, use WithDynamoDB()")
+	
+	err := s.DynamoDB().DeleteTable(s.ctx, tableName)
+	require.NoError(s.t, err, "Failed to delete DynamoDB table %s", tableName)
+}
+
+// MemcachedAddr retorna o endereço host:porta do memcached (se configurado via builder)
+func (s *IntegrationTestSuite) MemcachedAddr() string {
+	if s.builder != nil {
+		return s.builder.MemcachedAddr
+	}
+	return ""
+}
+
+// Neo4j retorna o Neo4j compartilhado (se configurado via builder)
+// Use GetBoltURL/GetCredentials para configurar o driver da aplicação
+func (s *IntegrationTestSuite) Neo4j() *SharedNeo4j {
+	return s.sharedNeo4j
+}
+
+// Neo4jLabel retorna o label de tenant que deve ser aplicado aos nós criados no teste
+func (s *IntegrationTestSuite) Neo4jLabel() string {
+	return TenantLabel(s.tenantID)
+}
+
+// Temporal retorna o Temporal compartilhado (se configurado via builder)
+func (s *IntegrationTestSuite) Temporal() *SharedTemporal {
+	if s.builder != nil {
+		return s.builder.TemporalConn
+	}
+	return nil
+}
+
+// TemporalAddress retorna o endereço do frontend do Temporal (HostPort do client do SDK)
+func (s *IntegrationTestSuite) TemporalAddress() string {
+	if temporal := s.Temporal(); temporal != nil {
+		return temporal.GetAddress()
+	}
+	return ""
+}
+
+// TemporalNamespace retorna o namespace do tenant da suite, registrando-o se necessário
+// O namespace (e todos os históricos de workflow) é removido ao final do teste
+func (s *IntegrationTestSuite) TemporalNamespace() string {
+	s.t.Helper()
+	
+	temporal := s.Temporal()
+	require.NotNil(s.t, temporal, "Temporal not configured, use WithTemporal()")
+	
+	namespace, created, err := temporal.EnsureNamespace(s.ctx, s.tenantID)
+	require.NoError(s.t, err, "Failed to register Temporal namespace")
+	
+	if created {
+		tenantID := s.tenantID
+		s.t.Cleanup(func() {
+			if err := temporal.DeleteNamespace(context.Background(), tenantID); err != nil && isDebugEnabled() {
+				fmt.Printf("⚠️  Failed to delete Temporal namespace %s: %v\n", namespace, err)
+			}
+		})
+	}
+	
+	return namespace
+}
+
+// Vault retorna o Vault compartilhado (se configurado via builder)
+// Use GetAddress/GetToken para configurar o client da aplicação
+func (s *IntegrationTestSuite) Vault() *SharedVault {
+	if s.builder != nil && s.builder.sharedVault != nil {
+		return s.builder.sharedVault
+	}
+	return nil
+}
+
+// SeedVaultSecrets grava segredos no mount KV (path -> dados)
+func (s *IntegrationTestSuite) SeedVaultSecrets(secrets map[string]map[string]interface{}) {
+	s.t.Helper()
+	
+	require.NotNil(s.t, s.Vault(), "Vault not configured, use WithVault()")
+	
+	err := s.Vault().SeedSecrets(s.ctx, secrets)
+	require.NoError(s.t, err, "Failed to seed Vault secrets")
+}
+
+// WireMock retorna o WireMock compartilhado (se configurado via builder)
+func (s *IntegrationTestSuite) WireMock() *SharedWireMock {
+	if s.builder != nil && s.builder.sharedWireMock != nil {
+		return s.builder.sharedWireMock
+	}
+	return nil
+}
+
+// WireMockURL retorna a URL base do WireMock (se configurado via builder)
+func (s *IntegrationTestSuite) WireMockURL() string {
+	if s.builder != nil {
+		return s.builder.WireMockURL
+	}
+	return ""
+}
+
+// StubJSON registra um stub que responde com status e corpo JSON para method + path
+func (s *IntegrationTestSuite) StubJSON(method, path string, status int, body interface{}) {
+	s.t.Helper()
+	
+	require.NotNil(s.t, s.WireMock(), "WireMock not configured, use WithWireMock()")
+	
+	err := s.WireMock().StubJSON(s.ctx, method, path, status, body)
+	require.NoError(s.t, err, "Failed to register WireMock stub %s %s", method, path)
+}
+
+// AssertWireMockCalled verifica quantas vezes method + path foi chamado no WireMock
+func (s *IntegrationTestSuite) AssertWireMockCalled(method, path string, times int) {
+	s.t.Helper()
+	
+	require.NotNil(s.t, s.WireMock(), "WireMock not configured, use WithWireMock()")
+	
+	count, err := s.WireMock().CountRequests(s.ctx, method, path)
+	require.NoError(s.t, err, "Failed to count WireMock requests")
+	require.Equal(s.t, times, count, "Unexpected number of calls to %s %s", method, path)
+}
+
+// MailHog retorna o MailHog compartilhado (se configurado via builder)
+func (s *IntegrationTestSuite) MailHog() *SharedMailHog {
+	if s.builder != nil && s.builder.sharedMailHog != nil {
+		return s.builder.sharedMailHog
+	}
+	return nil
+}
+
+// SMTPAddr retorna o endereço host:porta do SMTP do MailHog (se configurado via builder)
+func (s *IntegrationTestSuite) SMTPAddr() string {
+	if s.builder != nil {
+		return s.builder.SMTPAddr
+	}
+	return ""
+}
+
+// AssertEmailSentTo aguarda (até 5s) um email para o destinatário e retorna as mensagens recebidas
+func (s *IntegrationTestSuite) AssertEmailSentTo(addr string) []MailHogMessage {
+	s.t.Helper()
+	
+	require.NotNil(s.t, s.MailHog(), "MailHog not configured, use WithMailHog()")
+	
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		messages, err := s.MailHog().MessagesTo(s.ctx, addr)
+		require.NoError(s.t, err, "Failed to query MailHog")
+		
+		if len(messages) > 0 {
+			return messages
+		}
+		if time.Now().After(deadline) {
+			require.Fail(s.t, fmt.Sprintf("No email sent to %s", addr))
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// AssertNoEmailSentTo verifica que nenhum email foi enviado para o destinatário
+func (s *IntegrationTestSuite) AssertNoEmailSentTo(addr string) {
+	s.t.Helper()
+	
+	require.NotNil(s.t, s.MailHog(), "MailHog not configured, use WithMailHog()")
+	
+	messages, err := s.MailHog().MessagesTo(s.ctx, addr)
+	require.NoError(s.t, err, "Failed to query MailHog")
+	require.Empty(s.t, messages, "Expected no email sent to %s", addr)
+}
+
+// Azurite retorna o Azurite compartilhado (se configurado via builder)
+func (s *IntegrationTestSuite) Azurite() *SharedAzurite {
+	if s.builder != nil && s.builder.sharedAzurite != nil {
+		return s.builder.sharedAzurite
+	}
+	return nil
+}
+
+// AzuriteConnectionString retorna a connection string do Azurite (se configurado via builder)
+func (s *IntegrationTestSuite) AzuriteConnectionString() string {
+	if s.builder != nil {
+		return s.builder.AzuriteConnString
+	}
+	return ""
+}
+
+// BlobContainerName retorna o nome do blob container do tenant da suite para o sufixo
+func (s *IntegrationTestSuite) BlobContainerName(suffix string) string {
+	return TenantContainerName(s.tenantID, suffix)
+}
+
+// CreateBlobContainer cria o blob container do tenant da suite e remove-o ao final do teste
+func (s *IntegrationTestSuite) CreateBlobContainer(suffix string) string {
+	s.t.Helper()
+	
+	require.NotNil(s.t, s.Azurite(), "Azurite not configured, use WithAzurite()")
+	
+	name := s.BlobContainerName(suffix)
+	err := s.Azurite().CreateContainer(s.ctx, name)
+	require.NoError(s.t, err, "Failed to create blob container %s", name)
+	
+	azurite := s.Azurite()
+	s.t.Cleanup(func() {
+		if err := azurite.DeleteContainer(context.Background(), name); err != nil && isDebugEnabled() {
+			fmt.Printf("⚠️ Failed to delete blob container %s: %v\n", name, err)
+		}
+	})
+	
+	return name
+}
+
+// GCS retorna o fake GCS compartilhado (se configurado via builder)
+func (s *IntegrationTestSuite) GCS() *SharedGCS {
+	if s.builder != nil && s.builder.sharedGCS != nil {
+		return s.builder.sharedGCS
+	}
+	return nil
+}
+
+// GCSEndpoint retorna a URL base do fake GCS (se configurado via builder)
+func (s *IntegrationTestSuite) GCSEndpoint() string {
+	if s.builder != nil {
+		return s.builder.GCSEndpoint
+	}
+	return ""
+}
+
+// GCSBucketName retorna o nome do bucket do tenant da suite para o sufixo
+func (s *IntegrationTestSuite) GCSBucketName(suffix string) string {
+	return TenantBucketName(s.tenantID, suffix)
+}
+
+// CreateGCSBucket cria o bucket do tenant da suite e remove-o (com os objetos) ao final do teste
+func (s *IntegrationTestSuite) CreateGCSBucket(suffix string) string {
+	s.t.Helper()
+	
+	require.NotNil(s.t, s.GCS(), "GCS not configured, use WithGCS()")
+	
+	bucket := s.GCSBucketName(suffix)
+	err := s.GCS().CreateBucket(s.ctx, bucket)
+	require.NoError(s.t, err, "Failed to create bucket %s", bucket)
+	
+	gcs := s.GCS()
+	s.t.Cleanup(func() {
+		if err := gcs.DeleteBucket(context.Background(), bucket); err != nil && isDebugEnabled() {
+			fmt.Printf("⚠️ Failed to delete bucket %s: %v\n", bucket, err)
+		}
+	})
+	
+	return bucket
+}
+
+// SeedGCSBucket cria o bucket do tenant e envia os arquivos do diretório como objetos
+func (s *IntegrationTestSuite) SeedGCSBucket(suffix, dir string) string {
+	s.t.Helper()
+	
+	bucket := s.CreateGCSBucket(suffix)
+	_, err := s.GCS().SeedBucket(s.ctx, bucket, dir)
+	require.NoError(s.t, err, "Failed to seed bucket %s", bucket)
+	
+	return bucket
+}
+
+// PubSub retorna o emulador Pub/Sub compartilhado (se configurado via builder)
+func (s *IntegrationTestSuite) PubSub() *SharedPubSub {
+	if s.builder != nil && s.builder.sharedPubSub != nil {
+		return s.builder.sharedPubSub
+	}
+	return nil
+}
+
+// PubSubEmulatorHost retorna o host:porta do emulador Pub/Sub (se configurado via builder)
+func (s *IntegrationTestSuite) PubSubEmulatorHost() string {
+	if s.builder != nil {
+		return s.builder.PubSubEmulatorHost
+	}
+	return ""
+}
+
+// SetPubSubEnv define PUBSUB_EMULATOR_HOST/PUBSUB_PROJECT_ID durante o teste (via t.Setenv)
+// Não pode ser usado em testes com t.Parallel()
+func (s *IntegrationTestSuite) SetPubSubEnv() {
+	s.t.Helper()
+	
+	require.NotNil(s.t, s.PubSub(), "Pub/Sub not configured, use WithPubSubEmulator()")
+	
+	for key, value := range s.builder.PubSubEnv() {
+		s.t.Setenv(key, value)
+	}
+}
+
+// PubSubTopicID retorna o ID do tópico (ou subscription) do tenant da suite para o nome
+func (s *IntegrationTestSuite) PubSubTopicID(name string) string {
+	return TenantTopicID(s.tenantID, name)
+}
+
+// CreatePubSubTopic cria o tópico do tenant da suite e remove-o ao final do teste
+func (s *IntegrationTestSuite) CreatePubSubTopic(name string) string {
+	s.t.Helper()
+	
+	require.NotNil(s.t, s.PubSub(), "Pub/Sub not configured, use WithPubSubEmulator()")
+	
+	topic := s.PubSubTopicID(name)
+	err := s.PubSub().CreateTopic(s.ctx, topic)
+	require.NoError(s.t, err, "Failed to create topic %s", topic)
+	
+	pubsub := s.PubSub()
+	s.t.Cleanup(func() {
+		if err := pubsub.DeleteTopic(context.Background(), topic); err != nil && isDebugEnabled() {
+			fmt.Printf("⚠️ Failed to delete topic %s: %v\n", topic, err)
+		}
+	})
+	
+	return topic
+}
+
+// CreatePubSubSubscription cria a subscription do tenant no tópico (ID retornado por CreatePubSubTopic)
+// e remove-a ao final do teste
+func (s *IntegrationTestSuite) CreatePubSubSubscription(topic, name string) string {
+	s.t.Helper()
+	
+	require.NotNil(s.t, s.PubSub(), "Pub/Sub not configured, use WithPubSubEmulator()")
+	
+	subscription := s.PubSubTopicID(name)
+	err := s.PubSub().CreateSubscription(s.ctx, topic, subscription)
+	require.NoError(s.t, err, "Failed to create subscription %s", subscription)
+	
+	pubsub := s.PubSub()
+	s.t.Cleanup(func() {
+		if err := pubsub.DeleteSubscription(context.Background(), subscription); err != nil && isDebugEnabled() {
+			fmt.Printf("⚠️ Failed to delete subscription %s: %v\n", subscription, err)
+		}
+	})
+	
+	return subscription
+}
+
+// PublishPubSub publica mensagens no tópico (ID completo) e retorna os IDs gerados
+func (s *IntegrationTestSuite) PublishPubSub(topic string, messages ...[]byte) []string {
+	s.t.Helper()
+	
+	require.NotNil(s.t, s.PubSub(), "Pub/Sub not configured, use WithPubSubEmulator()")
+	
+	ids, err := s.PubSub().Publish(s.ctx, topic, messages...)
+	require.NoError(s.t, err, "Failed to publish to %s", topic)
+	return ids
+}
+
+// DrainPubSubSubscription consome (com ack) todas as mensagens da subscription
+// Retorna quando a subscription fica vazia por 500ms ou após o timeout
+func (s *IntegrationTestSuite) DrainPubSubSubscription(subscription string, timeout time.Duration) []PubSubMessage {
+	s.t.Helper()
+	
+	require.NotNil(s.t, s.PubSub(), "Pub/Sub not configured, use WithPubSubEmulator()")
+	
+	messages, err := s.PubSub().Drain(s.ctx, subscription, 500*time.Millisecond, timeout)
+	require.NoError(s.t, err, "Failed to drain subscription %s", subscription)
+	return messages
+}
+
+// ElasticMQ retorna o ElasticMQ compartilhado (se configurado via builder)
+func (s *IntegrationTestSuite) ElasticMQ() *SharedElasticMQ {
+	if s.builder != nil && s.builder.sharedElasticMQ != nil {
+		return s.builder.sharedElasticMQ
+	}
+	return nil
+}
+
+// SQSEndpoint retorna o endpoint compatível com SQS do ElasticMQ (se configurado via builder)
+func (s *IntegrationTestSuite) SQSEndpoint() string {
+	if s.builder != nil {
+		return s.builder.ElasticMQEndpoint
+	}
+	return ""
+}
+
+// SQSQueueURL retorna a URL de uma fila criada pelo WithElasticMQ
+func (s *IntegrationTestSuite) SQSQueueURL(queue string) string {
+	s.t.Helper()
+	
+	require.NotNil(s.t, s.ElasticMQ(), "ElasticMQ not configured, use WithElasticMQ()")
+	
+	queueURL, ok := s.builder.ElasticMQQueueURLs[queue]
+	require.True(s.t, ok, "Queue %s not declared in WithElasticMQ()", queue)
+	return queueURL
+}
+
+// SendSQSMessage envia uma mensagem para a fila do ElasticMQ
+func (s *IntegrationTestSuite) SendSQSMessage(queue, body string) string {
+	s.t.Helper()
+	
+	require.NotNil(s.t, s.ElasticMQ(), "ElasticMQ not configured, use WithElasticMQ()")
+	
+	id, err := s.ElasticMQ().SendMessage(s.ctx, queue, body)
+	require.NoError(s.t, err, "Failed to send message to %s", queue)
+	return id
+}
+
+// ReceiveSQSMessages recebe (e remove) até max mensagens da fila, aguardando até wait
+func (s *IntegrationTestSuite) ReceiveSQSMessages(queue string, max int, wait time.Duration) []SQSMessage {
+	s.t.Helper()
+	
+	require.NotNil(s.t, s.ElasticMQ(), "ElasticMQ not configured, use WithElasticMQ()")
+	
+	messages, err := s.ElasticMQ().ReceiveMessages(s.ctx, queue, max, wait)
+	require.NoError(s.t, err, "Failed to receive messages from %s", queue)
+	return messages
+}
+
+// AssertSQSQueueLength verifica o número de mensagens visíveis na fila
+func (s *IntegrationTestSuite) AssertSQSQueueLength(queue string, expected int) {
+	s.t.Helper()
+	
+	require.NotNil(s.t, s.ElasticMQ(), "ElasticMQ not configured, use WithElasticMQ()")
+	
+	count, err := s.ElasticMQ().ApproximateMessageCount(s.ctx, queue)
+	require.NoError(s.t, err, "Failed to count messages of %s", queue)
+	require.Equal(s.t, expected, count, "Unexpected number of messages in queue %s", queue)
+}
+
+// Consul retorna o Consul compartilhado (se configurado via builder)
+func (s *IntegrationTestSuite) Consul() *SharedConsul {
+	if s.builder != nil && s.builder.sharedConsul != nil {
+		return s.builder.sharedConsul
+	}
+	return nil
+}
+
+// ConsulAddr retorna o endereço HTTP do Consul (se configurado via builder)
+func (s *IntegrationTestSuite) ConsulAddr() string {
+	if s.builder != nil {
+		return s.builder.ConsulAddr
+	}
+	return ""
+}
+
+// ConsulKVPrefix retorna o prefixo de chaves do tenant da suite (ex.: "test_ab12/")
+func (s *IntegrationTestSuite) ConsulKVPrefix() string {
+	return s.tenantID + "/"
+}
+
+// SeedConsulKV grava os valores sob o prefixo do tenant (chave relativa -> valor) e retorna o prefixo
+// As chaves são removidas ao final do teste
+func (s *IntegrationTestSuite) SeedConsulKV(values map[string]string) string {
+	s.t.Helper()
+	
+	require.NotNil(s.t, s.Consul(), "Consul not configured, use WithConsul()")
+	
+	prefix := s.ConsulKVPrefix()
+	prefixed := make(map[string]string, len(values))
+	for key, value := range values {
+		prefixed[prefix+strings.TrimLeft(key, "/")] = value
+	}
+	
+	err := s.Consul().SeedKV(s.ctx, prefixed)
+	require.NoError(s.t, err, "Failed to seed Consul KV")
+	
+	consul := s.Consul()
+	s.t.Cleanup(func() {
+		if err := consul.DeleteKVPrefix(context.Background(), prefix); err != nil && isDebugEnabled() {
+			fmt.Printf("⚠️ Failed to delete Consul keys %s: %v\n", prefix, err)
+		}
+	})
+	
+	return prefix
+}
+
+// RegisterConsulService registra um serviço com ID prefixado pelo tenant e retorna o ID
+// O serviço é desregistrado ao final do teste
+func (s *IntegrationTestSuite) RegisterConsulService(service ConsulService) string {
+	s.t.Helper()
+	
+	require.NotNil(s.t, s.Consul(), "Consul not configured, use WithConsul()")
+	
+	if service.ID == "" {
+		service.ID = service.Name
+	}
+	service.ID = s.tenantID + "-" + service.ID
+	
+	err := s.Consul().RegisterService(s.ctx, service)
+	require.NoError(s.t, err, "Failed to register Consul service %s", service.ID)
+	
+	consul := s.Consul()
+	id := service.ID
+	s.t.Cleanup(func() {
+		if err := consul.DeregisterService(context.Background(), id); err != nil && isDebugEnabled() {
+			fmt.Printf("⚠️ Failed to deregister Consul service %s: %v\n", id, err)
+		}
+	})
+	
+	return id
+}
+
+// Influx retorna o InfluxDB compartilhado (se configurado via builder)
+func (s *IntegrationTestSuite) Influx() *SharedInfluxDB {
+	if s.builder != nil && s.builder.sharedInfluxDB != nil {
+		return s.builder.sharedInfluxDB
+	}
+	return nil
+}
+
+// InfluxConfig retorna URL, token, org e bucket do InfluxDB (se configurado via builder)
+func (s *IntegrationTestSuite) InfluxConfig() InfluxConfig {
+	if s.builder != nil {
+		return s.builder.InfluxConfig
+	}
+	return InfluxConfig{}
+}
+
+// WriteInfluxLines grava pontos em line protocol no bucket de teste
+func (s *IntegrationTestSuite) WriteInfluxLines(lines ...string) {
+	s.t.Helper()
+	
+	require.NotNil(s.t, s.Influx(), "InfluxDB not configured, use WithInfluxDB()")
+	
+	err := s.Influx().WriteLines(s.ctx, lines...)
+	require.NoError(s.t, err, "Failed to write InfluxDB points")
+}
+
+// QueryInflux executa uma consulta Flux e retorna as linhas (coluna -> valor)
+func (s *IntegrationTestSuite) QueryInflux(flux string) []map[string]string {
+	s.t.Helper()
+	
+	require.NotNil(s.t, s.Influx(), "InfluxDB not configured, use WithInfluxDB()")
+	
+	rows, err := s.Influx().Query(s.ctx, flux)
+	require.NoError(s.t, err, "Failed to query InfluxDB")
+	return rows
+}
+
+// SQLServer retorna a conexão SQL Server (se configurada via builder e com driver registrado)
+func (s *IntegrationTestSuite) SQLServer() *sql.DB {
+	if s.builder != nil {
+		return s.builder.SQLServerConn
+	}
+	return nil
+}
+
+// SQLServerURL retorna a URL de conexão do SQL Server (se configurado via builder)
+func (s *IntegrationTestSuite) SQLServerURL() string {
+	if s.builder != nil {
+		return s.builder.SQLServerURL
+	}
+	return ""
+}
+
+// ExecSQLServer executa um batch T-SQL no database de teste via sqlcmd (não requer driver)
+func (s *IntegrationTestSuite) ExecSQLServer(statement string) string {
+	s.t.Helper()
+	
+	require.True(s.t, s.builder != nil && s.builder.sharedSQLServer != nil, "SQL Server not configured, use WithSQLServer()")
+	
+	out, err := s.builder.sharedSQLServer.ExecSQL(s.ctx, statement)
+	require.NoError(s.t, err, "Failed to execute T-SQL")
+	return out
+}
+
+// Couchbase retorna o Couchbase compartilhado (se configurado via builder)
+func (s *IntegrationTestSuite) Couchbase() *SharedCouchbase {
+	if s.builder != nil && s.builder.sharedCouchbase != nil {
+		return s.builder.sharedCouchbase
+	}
+	return nil
+}
+
+// CouchbaseConfig retorna connection string, URLs e credenciais do Couchbase (se configurado via builder)
+func (s *IntegrationTestSuite) CouchbaseConfig() CouchbaseConfig {
+	if s.builder != nil {
+		return s.builder.CouchbaseConfig
+	}
+	return CouchbaseConfig{}
+}
+
+// CouchbaseBucket retorna o bucket do tenant da suite, criando-o se necessário
+// O bucket é removido ao final do teste
+func (s *IntegrationTestSuite) CouchbaseBucket() string {
+	s.t.Helper()
+	
+	require.NotNil(s.t, s.Couchbase(), "Couchbase not configured, use WithCouchbase()")
+	
+	if s.Couchbase().HasBucket(s.tenantID) {
+		return CouchbaseBucketName(s.tenantID)
+	}
+	
+	bucket, err := s.Couchbase().CreateBucket(s.ctx, s.tenantID)
+	require.NoError(s.t, err, "Failed to create Couchbase bucket")
+	
+	s.t.Cleanup(func() {
+		if err := s.Couchbase().DropBucket(context.Background(), s.tenantID); err != nil && isDebugEnabled() {
+			fmt.Printf("⚠️  Failed to drop Couchbase bucket %s: %v\n", bucket, err)
+		}
+	})
+	return bucket
+}
+
+// UpsertCouchbase grava o documento no bucket do tenant da suite
+func (s *IntegrationTestSuite) UpsertCouchbase(id string, document interface{}) {
+	s.t.Helper()
+	
+	err := s.Couchbase().Upsert(s.ctx, s.CouchbaseBucket(), id, document)
+	require.NoError(s.t, err, "Failed to upsert Couchbase document %s", id)
+}
+
+// QueryCouchbase executa uma instrução N1QL com parâmetros posicionais ($1, $2...)
+func (s *IntegrationTestSuite) QueryCouchbase(statement string, args ...interface{}) []map[string]interface{} {
+	s.t.Helper()
+	
+	require.NotNil(s.t, s.Couchbase(), "Couchbase not configured, use WithCouchbase()")
+	
+	rows, err := s.Couchbase().Query(s.ctx, statement, args...)
+	require.NoError(s.t, err, "Failed to query Couchbase")
+	return rows
+}
+
+// Solr retorna o Solr compartilhado (se configurado via builder)
+func (s *IntegrationTestSuite) Solr() *SharedSolr {
+	if s.builder != nil && s.builder.sharedSolr != nil {
+		return s.builder.sharedSolr
+	}
+	return nil
+}
+
+// SolrURL retorna a URL do core Solr configurado (se configurado via builder)
+func (s *IntegrationTestSuite) SolrURL() string {
+	if s.builder != nil {
+		return s.builder.SolrURL
+	}
+	return ""
+}
+
+// IndexSolrDocuments indexa os documentos no core Solr com commit, como o IndexDocument faz com refresh
+func (s *IntegrationTestSuite) IndexSolrDocuments(docs ...interface{}) {
+	s.t.Helper()
+	defer s.trackOperation("IndexSolrDocuments")()
+	
+	require.NotNil(s.t, s.Solr(), "Solr not configured, use WithSolr()")
+	
+	err := s.Solr().AddDocuments(s.ctx, s.builder.SolrCore, docs...)
+	require.NoError(s.t, err, "Failed to index Solr documents")
+}
+
+// SearchSolr consulta o core Solr (q, fq, sort, rows...; sem q busca *:*)
+func (s *IntegrationTestSuite) SearchSolr(params url.Values) *SolrResult {
+	s.t.Helper()
+	defer s.trackOperation("SearchSolr")()
+	
+	require.NotNil(s.t, s.Solr(), "Solr not configured, use WithSolr()")
+	
+	result, err := s.Solr().Query(s.ctx, s.builder.SolrCore, params)
+	require.NoError(s.t, err, "Failed to search Solr")
+	return result
+}
+
+// Meilisearch retorna o Meilisearch compartilhado (se configurado via builder)
+func (s *IntegrationTestSuite) Meilisearch() *SharedMeilisearch {
+	if s.builder != nil && s.builder.sharedMeilisearch != nil {
+		return s.builder.sharedMeilisearch
+	}
+	return nil
+}
+
+// MeilisearchURL retorna a URL do Meilisearch (se configurado via builder)
+func (s *IntegrationTestSuite) MeilisearchURL() string {
+	if s.builder != nil {
+		return s.builder.MeilisearchURL
+	}
+	return ""
+}
+
+// MeilisearchKey retorna a master key do Meilisearch (se configurado via builder)
+func (s *IntegrationTestSuite) MeilisearchKey() string {
+	if s.builder != nil {
+		return s.builder.MeilisearchKey
+	}
+	return ""
+}
+
+// meilisearchIndexCleanups guarda os índices já agendados para remoção (um registro por teste e índice)
+var meilisearchIndexCleanups sync.Map
+
+// MeilisearchIndex retorna o índice do tenant da suite para a base ("<base>-<tenant>"), criando-o
+// com chave primária "id" se necessário. O índice é removido ao final do teste
+func (s *IntegrationTestSuite) MeilisearchIndex(base string) string {
+	s.t.Helper()
+	
+	require.NotNil(s.t, s.Meilisearch(), "Meilisearch not configured, use WithMeilisearch()")
+	
+	uid := TenantIndexName(base, s.tenantID)
+	err := s.Meilisearch().CreateIndex(s.ctx, uid, "id")
+	require.NoError(s.t, err, "Failed to create Meilisearch index %s", uid)
+	
+	type cleanupKey struct {
+		t   TestingT
+		uid string
+	}
+	if _, loaded := meilisearchIndexCleanups.LoadOrStore(cleanupKey{s.t, uid}, struct{}{}); !loaded {
+		meili := s.Meilisearch()
+		s.t.Cleanup(func() {
+			meilisearchIndexCleanups.Delete(cleanupKey{s.t, uid})
+			if err := meili.DeleteIndex(context.Background(), uid); err != nil && isDebugEnabled() {
+				fmt.Printf("⚠️  Failed to delete Meilisearch index %s: %v\n", uid, err)
+			}
+		})
+	}
+	return uid
+}
+
+// ConfigureMeilisearchIndex atualiza as settings do índice do tenant (ex.: filterableAttributes)
+func (s *IntegrationTestSuite) ConfigureMeilisearchIndex(base string, settings map[string]interface{}) {
+	s.t.Helper()
+	
+	uid := s.MeilisearchIndex(base)
+	err := s.Meilisearch().UpdateSettings(s.ctx, uid, settings)
+	require.NoError(s.t, err, "Failed to configure Meilisearch index %s", uid)
+}
+
+// IndexMeilisearchDocument indexa o documento no índice do tenant com o id informado, aguardando a
+// indexação (equivalente ao IndexDocument do Elasticsearch)
+func (s *IntegrationTestSuite) IndexMeilisearchDocument(base, docID string, document interface{}) {
+	s.t.Helper()
+	defer s.trackOperation("IndexMeilisearchDocument")()
+	
+	raw, err := json.Marshal(document)
+	require.NoError(s.t, err, "Failed to marshal document")
+	
+	doc := map[string]interface{}{}
+	require.NoError(s.t, json.Unmarshal(raw, &doc), "Meilisearch documents must be JSON objects")
+	doc["id"] = docID
+	
+	uid := s.MeilisearchIndex(base)
+	err = s.Meilisearch().AddDocuments(s.ctx, uid, doc)
+	require.NoError(s.t, err, "Failed to index Meilisearch document %s", docID)
+}
+
+// SearchMeilisearch busca no índice do tenant (q, filter, sort, limit...), equivalente ao SearchDocuments
+func (s *IntegrationTestSuite) SearchMeilisearch(base string, query map[string]interface{}) *MeilisearchResult {
+	s.t.Helper()
+	defer s.trackOperation("SearchMeilisearch")()
+	
+	uid := s.MeilisearchIndex(base)
+	result, err := s.Meilisearch().Search(s.ctx, uid, query)
+	require.NoError(s.t, err, "Failed to search Meilisearch index %s", uid)
+	return result
+}
+
+// Typesense retorna o Typesense compartilhado (se configurado via builder)
+func (s *IntegrationTestSuite) Typesense() *SharedTypesense {
+	if s.builder != nil && s.builder.sharedTypesense != nil {
+		return s.builder.sharedTypesense
+	}
+	return nil
+}
+
+// TypesenseURL retorna a URL do Typesense (se configurado via builder)
+func (s *IntegrationTestSuite) TypesenseURL() string {
+	if s.builder != nil {
+		return s.builder.TypesenseURL
+	}
+	return ""
+}
+
+// TypesenseAPIKey retorna a API key do Typesense (se configurado via builder)
+func (s *IntegrationTestSuite) TypesenseAPIKey() string {
+	if s.builder != nil {
+		return s.builder.TypesenseAPIKey
+	}
+	return ""
+}
+
+// CreateTypesenseCollection cria a collection do teste ("<base>-<tenant>") com o schema informado
+// (fields, default_sorting_field...) e agenda a remoção ao final do teste. Retorna o nome da collection
+func (s *IntegrationTestSuite) CreateTypesenseCollection(base string, schema map[string]interface{}) string {
+	s.t.Helper()
+	
+	require.NotNil(s.t, s.Typesense(), "Typesense not configured, use WithTypesense()")
+	
+	name := TenantIndexName(base, s.tenantID)
+	err := s.Typesense().CreateCollection(s.ctx, name, schema)
+	require.NoError(s.t, err, "Failed to create Typesense collection %s", name)
+	
+	typesense := s.Typesense()
+	s.t.Cleanup(func() {
+		if err := typesense.DeleteCollection(context.Background(), name); err != nil && isDebugEnabled() {
+			fmt.Printf("⚠️  Failed to delete Typesense collection %s: %v\n", name, err)
+		}
+	})
+	return name
+}
+
+// SeedTypesense faz upsert dos documentos na collection (cada documento deve ter "id" string)
+func (s *IntegrationTestSuite) SeedTypesense(collection string, docs ...interface{}) {
+	s.t.Helper()
+	defer s.trackOperation("SeedTypesense")()
+	
+	require.NotNil(s.t, s.Typesense(), "Typesense not configured, use WithTypesense()")
+	
+	err := s.Typesense().ImportDocuments(s.ctx, collection, docs...)
+	require.NoError(s.t, err, "Failed to seed Typesense collection %s", collection)
+}
+
+// SearchTypesense busca na collection (q, query_by, filter_by...; sem q busca "*")
+func (s *IntegrationTestSuite) SearchTypesense(collection string, params url.Values) *TypesenseResult {
+	s.t.Helper()
+	defer s.trackOperation("SearchTypesense")()
+	
+	require.NotNil(s.t, s.Typesense(), "Typesense not configured, use WithTypesense()")
+	
+	result, err := s.Typesense().Search(s.ctx, collection, params)
+	require.NoError(s.t, err, "Failed to search Typesense collection %s", collection)
+	return result
+}
+
+// Kibana retorna o Kibana compartilhado (se configurado via builder)
+func (s *IntegrationTestSuite) Kibana() *SharedKibana {
+	if s.builder != nil && s.builder.sharedKibana != nil {
+		return s.builder.sharedKibana
+	}
+	return nil
+}
+
+// KibanaURL retorna a URL do Kibana (se configurado via builder)
+func (s *IntegrationTestSuite) KibanaURL() string {
+	if s.builder != nil {
+		return s.builder.KibanaURL
+	}
+	return ""
+}
+
+// ImportKibanaSavedObjects importa um arquivo NDJSON de saved objects (export do Kibana), substituindo
+// objetos com o mesmo id
+func (s *IntegrationTestSuite) ImportKibanaSavedObjects(path string) {
+	s.t.Helper()
+	
+	require.NotNil(s.t, s.Kibana(), "Kibana not configured, use WithKibana()")
+	
+	ndjson, err := os.ReadFile(path)
+	require.NoError(s.t, err, "Failed to read saved objects %s", path)
+	
+	err = s.Kibana().ImportSavedObjects(s.ctx, ndjson, true)
+	require.NoError(s.t, err, "Failed to import saved objects %s", path)
+}
+
+// KibanaSavedObjects lista os saved objects do tipo (ex.: "dashboard", "index-pattern")
+func (s *IntegrationTestSuite) KibanaSavedObjects(objectType string) []KibanaSavedObject {
+	s.t.Helper()
+	
+	require.NotNil(s.t, s.Kibana(), "Kibana not configured, use WithKibana()")
+	
+	objects, err := s.Kibana().FindSavedObjects(s.ctx, objectType)
+	require.NoError(s.t, err, "Failed to list Kibana %s objects", objectType)
+	return objects
+}
+
+// Logstash retorna o Logstash compartilhado (se configurado via builder)
+func (s *IntegrationTestSuite) Logstash() *SharedLogstash {
+	if s.builder != nil && s.builder.sharedLogstash != nil {
+		return s.builder.sharedLogstash
+	}
+	return nil
+}
+
+// LogstashBeatsAddr retorna o endereço do input beats (para o Filebeat/libbeat da aplicação)
+func (s *IntegrationTestSuite) LogstashBeatsAddr() string {
+	if s.builder != nil {
+		return s.builder.LogstashBeatsAddr
+	}
+	return ""
+}
+
+// LogstashTCPAddr retorna o endereço do input tcp
+func (s *IntegrationTestSuite) LogstashTCPAddr() string {
+	if s.builder != nil {
+		return s.builder.LogstashTCPAddr
+	}
+	return ""
+}
+
+// SendLogstashEvents envia eventos em JSON ao input tcp do pipeline (codec json_lines)
+func (s *IntegrationTestSuite) SendLogstashEvents(events ...interface{}) {
+	s.t.Helper()
+	defer s.trackOperation("SendLogstashEvents")()
+	
+	require.NotNil(s.t, s.Logstash(), "Logstash not configured, use WithLogstash()")
+	
+	err := s.Logstash().SendEvents(s.ctx, events...)
+	require.NoError(s.t, err, "Failed to send events to Logstash")
+}
+
+// WaitForLogstashDocuments aguarda o índice (criado pelo output do pipeline) ter pelo menos expected
+// documentos e retorna a contagem. O pipeline é assíncrono, então o índice pode ainda não existir
+func (s *IntegrationTestSuite) WaitForLogstashDocuments(index string, expected int, timeout time.Duration) int {
+	s.t.Helper()
+	
+	client := s.ES()
+	require.NotNil(s.t, client, "Elasticsearch not configured")
+	
+	count := 0
+	deadline := time.Now().Add(timeout)
+	for {
+		// Refresh tolerante a índice inexistente: o output ainda pode não ter criado o índice
+		if res, err := client.Indices.Refresh(
+			client.Indices.Refresh.WithContext(s.ctx),
+			client.Indices.Refresh.WithIndex(index),
+			client.Indices.Refresh.WithIgnoreUnavailable(true),
+		); err == nil {
+			res.Body.Close()
+		}
+		
+		res, err := client.Count(client.Count.WithContext(s.ctx), client.Count.WithIndex(index))
+		if err == nil {
+			var body struct {
+				Count int `json:"count"`
+			}
+			if !res.IsError() && json.NewDecoder(res.Body).Decode(&body) == nil {
+				count = body.Count
+			}
+			res.Body.Close()
+		}
+		
+		if count >= expected {
+			return count
+		}
+		if time.Now().After(deadline) {
+			require.Fail(s.t, fmt.Sprintf("Logstash indexed %d of %d documents into %s within %s", count, expected, index, timeout))
+			return count
+		}
+		
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+// APMServer retorna o apm-server compartilhado (se configurado via builder)
+func (s *IntegrationTestSuite) APMServer() *SharedAPMServer {
+	if s.builder != nil && s.builder.sharedAPMServer != nil {
+		return s.builder.sharedAPMServer
+	}
+	return nil
+}
+
+// APMServerURL retorna a URL de intake do apm-server (ELASTIC_APM_SERVER_URL dos agentes)
+func (s *IntegrationTestSuite) APMServerURL() string {
+	if s.builder != nil {
+		return s.builder.APMServerURL
+	}
+	return ""
+}
+
+// WaitForAPMEvents aguarda pelo menos min eventos APM do tipo (processor.event: "transaction", "span",
+// "error", "metric") do serviço chegarem ao Elasticsearch e retorna os _source encontrados
+func (s *IntegrationTestSuite) WaitForAPMEvents(event, serviceName string, min int, timeout time.Duration) []map[string]interface{} {
+	s.t.Helper()
+	
+	client := s.ES()
+	require.NotNil(s.t, client, "Elasticsearch not configured")
+	
+	// Sem os templates da integração APM os campos são mapeados dinamicamente (text + .keyword)
+	termAnyMapping := func(field, value string) map[string]interface{} {
+		return map[string]interface{}{
+			"bool": map[string]interface{}{
+				"should": []interface{}{
+					map[string]interface{}{"term": map[string]interface{}{field: value}},
+					map[string]interface{}{"term": map[string]interface{}{field + ".keyword": value}},
+				},
+				"minimum_should_match": 1,
+			},
+		}
+	}
+	query, err := json.Marshal(map[string]interface{}{
+		"size": 1000,
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": []interface{}{
+					termAnyMapping("processor.event", event),
+					termAnyMapping("service.name", serviceName),
+				},
+			},
+		},
+	})
+	require.NoError(s.t, err, "Failed to marshal APM query")
+	
+	indices := strings.Join(APMIndexPatterns, ",")
+	var sources []map[string]interface{}
+	deadline := time.Now().Add(timeout)
+	for {
+		if res, err := client.Indices.Refresh(
+			client.Indices.Refresh.WithContext(s.ctx),
+			client.Indices.Refresh.WithIndex(indices),
+			client.Indices.Refresh.WithIgnoreUnavailable(true),
+			client.Indices.Refresh.WithAllowNoIndices(true),
+		); err == nil {
+			res.Body.Close()
+		}
+		
+		res, err := client.Search(
+			client.Search.WithContext(s.ctx),
+			client.Search.WithIndex(indices),
+			client.Search.WithBody(strings.NewReader(string(query))),
+			client.Search.WithIgnoreUnavailable(true),
+			client.Search.WithAllowNoIndices(true),
+		)
+		if err == nil {
+			var body struct {
+				Hits struct {
+					Hits []struct {
+						Source map[string]interface{} `json:"_source"`
+					} `json:"hits"`
+				} `json:"hits"`
+			}
+			if !res.IsError() && json.NewDecoder(res.Body).Decode(&body) == nil {
+				sources = sources[:0]
+				for _, hit := range body.Hits.Hits {
+					sources = append(sources, hit.Source)
+				}
+			}
+			res.Body.Close()
+		}
+		
+		if len(sources) >= min {
+			return sources
+		}
+		if time.Now().After(deadline) {
+			require.Fail(s.t, fmt.Sprintf("Found %d of %d APM %s events of %s within %s", len(sources), min, event, serviceName, timeout))
+			return sources
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+// Prometheus retorna o Prometheus compartilhado (se configurado via builder)
+func (s *IntegrationTestSuite) Prometheus() *SharedPrometheus {
+	if s.builder != nil && s.builder.sharedPrometheus != nil {
+		return s.builder.sharedPrometheus
+	}
+	return nil
+}
+
+// PrometheusURL retorna a URL da API do Prometheus (se configurado via builder)
+func (s *IntegrationTestSuite) PrometheusURL() string {
+	if s.builder != nil {
+		return s.builder.PrometheusURL
+	}
+	return ""
+}
+
+// QueryPromQL executa uma consulta PromQL instantânea e retorna as amostras
+func (s *IntegrationTestSuite) QueryPromQL(expr string) []PromSample {
+	s.t.Helper()
+	defer s.trackOperation("QueryPromQL")()
+	
+	require.NotNil(s.t, s.Prometheus(), "Prometheus not configured, use WithPrometheus()")
+	
+	samples, err := s.Prometheus().Query(s.ctx, expr)
+	require.NoError(s.t, err, "Failed to query PromQL %s", expr)
+	return samples
+}
+
+// WaitForPromQL repete a consulta até ela retornar amostras (o scrape é periódico) e retorna as amostras
+func (s *IntegrationTestSuite) WaitForPromQL(expr string, timeout time.Duration) []PromSample {
+	s.t.Helper()
+	
+	require.NotNil(s.t, s.Prometheus(), "Prometheus not configured, use WithPrometheus()")
+	
+	deadline := time.Now().Add(timeout)
+	for {
+		samples, err := s.Prometheus().Query(s.ctx, expr)
+		require.NoError(s.t, err, "Failed to query PromQL %s", expr)
+		if len(samples) > 0 {
+			return samples
+		}
+		if time.Now().After(deadline) {
+			require.Fail(s.t, fmt.Sprintf("PromQL %s returned no samples within %s", expr, timeout))
+			return nil
+		}
+		time.Sleep(PrometheusScrapeInterval)
+	}
+}
+
+// PromQLValue retorna a soma das amostras da consulta (0 sem amostras), para métricas escalares como
+// sum(rate(...)) ou contadores de uma única série
+func (s *IntegrationTestSuite) PromQLValue(expr string) float64 {
+	s.t.Helper()
+	
+	var total float64
+	for _, sample := range s.QueryPromQL(expr) {
+		total += sample.Value
+	}
+	return total
+}
+
+// AssertMetricIncreases lê o valor da consulta, executa fn (ex.: indexar documentos pela aplicação) e
+// aguarda o valor aumentar, respeitando o intervalo de scrape. Retorna o aumento observado
+// Garante que a operação realmente produz as métricas de que os alertas dependem
+func (s *IntegrationTestSuite) AssertMetricIncreases(expr string, timeout time.Duration, fn func()) float64 {
+	s.t.Helper()
+	
+	before := s.PromQLValue(expr)
+	fn()
+	
+	deadline := time.Now().Add(timeout)
+	for {
+		after := s.PromQLValue(expr)
+		if after > before {
+			return after - before
+		}
+		if time.Now().After(deadline) {
+			require.Fail(s.t, fmt.Sprintf("PromQL %s did not increase within %s (value %v)", expr, timeout, before))
+			return 0
+		}
+		time.Sleep(PrometheusScrapeInterval)
+	}
+}
+
+// Jaeger retorna o Jaeger compartilhado (se configurado via builder)
+func (s *IntegrationTestSuite) Jaeger() *SharedJaeger {
+	if s.builder != nil && s.builder.sharedJaeger != nil {
+		return s.builder.sharedJaeger
+	}
+	return nil
+}
+
+// JaegerOTLPEndpoint retorna o endereço host:porta do receiver OTLP gRPC (OTEL_EXPORTER_OTLP_ENDPOINT)
+func (s *IntegrationTestSuite) JaegerOTLPEndpoint() string {
+	if s.builder != nil {
+		return s.builder.JaegerOTLPGRPCAddr
+	}
+	return ""
+}
+
+// JaegerOTLPHTTPURL retorna a URL do receiver OTLP HTTP
+func (s *IntegrationTestSuite) JaegerOTLPHTTPURL() string {
+	if s.builder != nil {
+		return s.builder.JaegerOTLPHTTPURL
 	}
-	
-	return suite
+	return ""
 }
 
-// NewIntegrationTestSuiteBuilder retorna um builder para configuração fluente
-func NewIntegrationTestSuiteBuilder(t *testing.T) *IntegrationTestSuiteBuilder {
-	return &IntegrationTestSuiteBuilder{
-		t:            t,
-		depBuilder:   NewTestDependenciesBuilder(),
+// JaegerQueryURL retorna a URL da API de consulta/UI do Jaeger
+func (s *IntegrationTestSuite) JaegerQueryURL() string {
+	if s.builder != nil {
+		return s.builder.JaegerQueryURL
 	}
+	return ""
 }
 
-// IntegrationTestSuiteBuilder permite configuração fluente da suite de testes
-type IntegrationTestSuiteBuilder struct {
-	t          *testing.T
-	depBuilder *TestDependenciesBuilder
+// FindTraces retorna os traces do serviço iniciados desde a criação da suite, filtrando pela operação
+// se informada
+func (s *IntegrationTestSuite) FindTraces(service, operation string) []JaegerTrace {
+	s.t.Helper()
+	defer s.trackOperation("FindTraces")()
+	
+	require.NotNil(s.t, s.Jaeger(), "Jaeger not configured, use WithJaeger()")
+	
+	traces, err := s.Jaeger().FindTraces(s.ctx, service, operation, s.startedAt)
+	require.NoError(s.t, err, "Failed to find traces of %s", service)
+	return traces
 }
 
-// WithPostgres configura PostgreSQL
-func (b *IntegrationTestSuiteBuilder) WithPostgres(sqlFilePaths ...string) *IntegrationTestSuiteBuilder {
-	b.depBuilder.WithPostgres(sqlFilePaths...)
-	return b
+// WaitForTraces aguarda pelo menos min traces do serviço/operação (os exporters enviam em lote)
+func (s *IntegrationTestSuite) WaitForTraces(service, operation string, min int, timeout time.Duration) []JaegerTrace {
+	s.t.Helper()
+	
+	require.NotNil(s.t, s.Jaeger(), "Jaeger not configured, use WithJaeger()")
+	
+	deadline := time.Now().Add(timeout)
+	for {
+		traces, err := s.Jaeger().FindTraces(s.ctx, service, operation, s.startedAt)
+		require.NoError(s.t, err, "Failed to find traces of %s", service)
+		if len(traces) >= min {
+			return traces
+		}
+		if time.Now().After(deadline) {
+			require.Fail(s.t, fmt.Sprintf("Found %d of %d traces of %s %s within %s", len(traces), min, service, operation, timeout))
+			return traces
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
 }
 
-// WithMongo configura MongoDB
-func (b *IntegrationTestSuiteBuilder) WithMongo() *IntegrationTestSuiteBuilder {
-	b.depBuilder.WithMongo()
-	return b
+// OTelCollector retorna o OpenTelemetry Collector compartilhado (se configurado via builder)
+func (s *IntegrationTestSuite) OTelCollector() *SharedOTelCollector {
+	if s.builder != nil && s.builder.sharedOTelCollector != nil {
+		return s.builder.sharedOTelCollector
+	}
+	return nil
 }
 
-// WithElasticsearch configura Elasticsearch
-func (b *IntegrationTestSuiteBuilder) WithElasticsearch() *IntegrationTestSuiteBuilder {
-	b.depBuilder.WithElasticsearch()
-	return b
+// OTelGRPCEndpoint retorna o endereço host:porta do receiver OTLP gRPC do collector
+func (s *IntegrationTestSuite) OTelGRPCEndpoint() string {
+	if s.builder != nil {
+		return s.builder.OTelGRPCEndpoint
+	}
+	return ""
 }
 
-// Build constrói e retorna a IntegrationTestSuite
-func (b *IntegrationTestSuiteBuilder) Build() (*IntegrationTestSuite, error) {
-	deps, err := b.depBuilder.Build()
-	if err != nil {
-		return nil, err
+// OTelHTTPEndpoint retorna a URL do receiver OTLP HTTP do collector
+func (s *IntegrationTestSuite) OTelHTTPEndpoint() string {
+	if s.builder != nil {
+		return s.builder.OTelHTTPEndpoint
 	}
+	return ""
+}
+
+// OTelSpans retorna os spans exportados pelo collector desde a criação da suite, do serviço informado
+// (vazio retorna todos os serviços)
+func (s *IntegrationTestSuite) OTelSpans(service string) []OTelSpan {
+	s.t.Helper()
+	defer s.trackOperation("OTelSpans")()
+	
+	require.NotNil(s.t, s.OTelCollector(), "OTel Collector not configured, use WithOTelCollector()")
 	
-	return NewIntegrationTestSuiteWithBuilder(b.t, deps), nil
+	spans, err := s.OTelCollector().Spans(s.ctx)
+	require.NoError(s.t, err, "Failed to read exported spans")
+	
+	var filtered []OTelSpan
+	for _, span := range spans {
+		if span.StartTime.Before(s.startedAt) || (service != "" && span.ServiceName != service) {
+			continue
+		}
+		filtered = append(filtered, span)
+	}
+	return filtered
 }
 
-// Setup inicializa a suite e limpa o estado do Elasticsearch
-func (s *IntegrationTestSuite) Setup() {
+// OTelMetrics retorna os pontos da métrica exportados pelo collector desde a criação da suite
+func (s *IntegrationTestSuite) OTelMetrics(name string) []OTelMetricPoint {
 	s.t.Helper()
+	defer s.trackOperation("OTelMetrics")()
 	
-	// Inicia o container compartilhado
-	err := s.sharedES.Start(context.Background())
-	// err := s.sharedES.Start(s.ctx)
-	require.NoError(s.t, err, "Failed to start shared Elasticsearch")
+	require.NotNil(s.t, s.OTelCollector(), "OTel Collector not configured, use WithOTelCollector()")
 	
-	// Com tenantID, não precisamos limpar todos os índices
-	// Cada teste terá isolamento automático via tenantID
+	points, err := s.OTelCollector().Metrics(s.ctx)
+	require.NoError(s.t, err, "Failed to read exported metrics")
+	
+	var filtered []OTelMetricPoint
+	for _, point := range points {
+		if point.Name == name && !point.Time.Before(s.startedAt) {
+			filtered = append(filtered, point)
+		}
+	}
+	return filtered
 }
 
-// Teardown limpa recursos se necessário
-func (s *IntegrationTestSuite) Teardown() {
+// WaitForOTelSpans aguarda pelo menos min spans do serviço com o nome informado (vazio aceita qualquer
+// nome) e retorna os spans encontrados
+func (s *IntegrationTestSuite) WaitForOTelSpans(service, name string, min int, timeout time.Duration) []OTelSpan {
 	s.t.Helper()
 	
-	// Com container compartilhado, não paramos a cada teste
-	// O container será limpo automaticamente pelo testcontainers no final
+	deadline := time.Now().Add(timeout)
+	for {
+		var spans []OTelSpan
+		for _, span := range s.OTelSpans(service) {
+			if name == "" || span.Name == name {
+				spans = append(spans, span)
+			}
+		}
+		if len(spans) >= min {
+			return spans
+		}
+		if time.Now().After(deadline) {
+			require.Fail(s.t, fmt.Sprintf("Found %d of %d spans %s of %s within %s", len(spans), min, name, service, timeout))
+			return spans
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
 }
 
-// ES retorna o cliente Elasticsearch
-func (s *IntegrationTestSuite) ES() *elasticsearch.Client {
-	if s.builder != nil && s.builder.ESConn != nil {
-		return s.builder.ESConn
+// WaitForOTelMetric aguarda a métrica ser exportada e retorna os pontos encontrados
+func (s *IntegrationTestSuite) WaitForOTelMetric(name string, timeout time.Duration) []OTelMetricPoint {
+	s.t.Helper()
+	
+	deadline := time.Now().Add(timeout)
+	for {
+		if points := s.OTelMetrics(name); len(points) > 0 {
+			return points
+		}
+		if time.Now().After(deadline) {
+			require.Fail(s.t, fmt.Sprintf("Metric %s was not exported within %s", name, timeout))
+			return nil
+		}
+		time.Sleep(500 * time.Millisecond)
 	}
-	return s.sharedES.GetClient()
 }
 
-// Postgres retorna a conexão PostgreSQL (se configurada via builder)
-func (s *IntegrationTestSuite) Postgres() *sql.DB {
-	if s.builder != nil && s.builder.PostgresConn != nil {
-		return s.builder.PostgresConn
+// Debezium retorna o Kafka Connect + Debezium compartilhado (se configurado via builder)
+func (s *IntegrationTestSuite) Debezium() *SharedDebezium {
+	if s.builder != nil && s.builder.sharedDebezium != nil {
+		return s.builder.sharedDebezium
 	}
-	if s.sharedPG != nil {
-		return s.sharedPG.GetConnection()
+	return nil
+}
+
+// KafkaConnectURL retorna a URL da API REST do Kafka Connect (se configurado via builder)
+func (s *IntegrationTestSuite) KafkaConnectURL() string {
+	if s.builder != nil {
+		return s.builder.KafkaConnectURL
+	}
+	return ""
+}
+
+// Kafka retorna o Kafka compartilhado (se configurado via builder)
+func (s *IntegrationTestSuite) Kafka() *SharedKafka {
+	if s.builder != nil && s.builder.sharedKafka != nil {
+		return s.builder.sharedKafka
 	}
 	return nil
 }
 
-// Mongo retorna o database MongoDB principal (se configurado via builder)
-func (s *IntegrationTestSuite) Mongo() *mongo.Database {
-	if s.builder != nil && s.builder.MongoConn != nil {
-		return s.builder.MongoConn
+// KafkaBootstrapServers retorna o endereço do broker Kafka (se configurado via builder)
+func (s *IntegrationTestSuite) KafkaBootstrapServers() string {
+	if s.builder != nil {
+		return s.builder.KafkaBootstrapServers
 	}
-	if s.sharedMongo != nil {
-		return s.sharedMongo.GetDatabase()
+	return ""
+}
+
+// SchemaRegistry retorna o Schema Registry compartilhado (se configurado via builder)
+func (s *IntegrationTestSuite) SchemaRegistry() *SharedSchemaRegistry {
+	if s.builder != nil && s.builder.sharedSchemaRegistry != nil {
+		return s.builder.sharedSchemaRegistry
 	}
 	return nil
 }
 
-// MongoDW retorna o database MongoDB DW (se configurado via builder)
-func (s *IntegrationTestSuite) MongoDW() *mongo.Database {
-	if s.builder != nil && s.builder.MongoConnDW != nil {
-		return s.builder.MongoConnDW
+// SchemaRegistryURL retorna a URL do Schema Registry (se configurado via builder)
+func (s *IntegrationTestSuite) SchemaRegistryURL() string {
+	if s.builder != nil {
+		return s.builder.SchemaRegistryURL
 	}
-	if s.sharedMongo != nil {
-		return s.sharedMongo.GetDatabaseDW()
+	return ""
+}
+
+// MQTT retorna o broker MQTT compartilhado (se configurado via builder)
+func (s *IntegrationTestSuite) MQTT() *SharedMQTT {
+	if s.builder != nil && s.builder.sharedMQTT != nil {
+		return s.builder.sharedMQTT
+	}
+	return nil
+}
+
+// MQTTBrokerAddr retorna o endereço host:porta do broker MQTT (se configurado via builder)
+func (s *IntegrationTestSuite) MQTTBrokerAddr() string {
+	if s.builder != nil {
+		return s.builder.MQTTBrokerAddr
+	}
+	return ""
+}
+
+// Artemis retorna o ActiveMQ Artemis compartilhado (se configurado via builder)
+func (s *IntegrationTestSuite) Artemis() *SharedArtemis {
+	if s.builder != nil && s.builder.sharedArtemis != nil {
+		return s.builder.sharedArtemis
 	}
 	return nil
 }
 
+// ArtemisConfig retorna endereços (AMQP, STOMP, core) e credenciais do Artemis (se configurado via builder)
+func (s *IntegrationTestSuite) ArtemisConfig() ArtemisConfig {
+	if s.builder != nil {
+		return s.builder.ArtemisConfig
+	}
+	return ArtemisConfig{}
+}
+
 // GetElasticsearchURL retorna a URL do Elasticsearch
 func (s *IntegrationTestSuite) GetElasticsearchURL() string {
 	return s.sharedES.GetURL()
@@ -183,68 +1956,185 @@ func (s *IntegrationTestSuite) GetElasticsearchURL() string {
 // CleanElasticsearch remove todos os índices para isolamento entre testes
 func (s *IntegrationTestSuite) CleanElasticsearch() {
 	s.t.Helper()
-	
-	if s.builder != nil && s.builder.ESClearFunc != nil {
-		s.builder.ESClearFunc()
-		return
-	}
-	
-	err := s.sharedES.CleanIndices(s.ctx)
-	require.NoError(s.t, err, "Failed to clean Elasticsearch indices")
+	s.cleanDependency(DepElasticsearch)
 }
 
 // CleanMongo remove todas as coleções do MongoDB para isolamento entre testes
 func (s *IntegrationTestSuite) CleanMongo() {
 	s.t.Helper()
-	
-	if s.builder != nil && s.builder.MongoClearFunc != nil {
-		err := s.builder.MongoClearFunc(s.ctx)
-		require.NoError(s.t, err, "Failed to clean MongoDB collections")
-		return
-	}
-	
-	if s.sharedMongo != nil {
-		err := s.sharedMongo.CleanDatabase(s.ctx)
-		require.NoError(s.t, err, "Failed to clean MongoDB collections")
-	}
+	s.cleanDependency(DepMongo)
 }
 
 // CleanPostgres trunca todas as tabelas do PostgreSQL para isolamento entre testes
 func (s *IntegrationTestSuite) CleanPostgres() {
 	s.t.Helper()
-	
-	if s.builder != nil && s.builder.PostgresClearFunc != nil {
-		err := s.builder.PostgresClearFunc(s.ctx)
-		require.NoError(s.t, err, "Failed to clean PostgreSQL tables")
-		return
-	}
-	
-	if s.sharedPG != nil {
-		err := s.sharedPG.CleanDatabase(s.ctx)
-		require.NoError(s.t, err, "Failed to clean PostgreSQL tables")
-	}
+	s.cleanDependency(DepPostgres)
+}
+
+// CleanCassandra trunca todas as tabelas do keyspace do tenant da suite
+func (s *IntegrationTestSuite) CleanCassandra() {
+	s.t.Helper()
+	s.cleanDependency(DepCassandra)
+}
+
+// CleanClickHouse trunca todas as tabelas do ClickHouse para isolamento entre testes
+func (s *IntegrationTestSuite) CleanClickHouse() {
+	s.t.Helper()
+	s.cleanDependency(DepClickHouse)
+}
+
+// CleanDynamoDB remove todas as tabelas do DynamoDB para isolamento entre testes
+func (s *IntegrationTestSuite) CleanDynamoDB() {
+	s.t.Helper()
+	s.cleanDependency(DepDynamoDB)
+}
+
+// CleanMemcached remove todos os itens do memcached para isolamento entre testes
+func (s *IntegrationTestSuite) CleanMemcached() {
+	s.t.Helper()
+	s.cleanDependency(DepMemcached)
+}
+
+// CleanNeo4j remove os nós do tenant da suite (MATCH (n:Tenant_x) DETACH DELETE n)
+func (s *IntegrationTestSuite) CleanNeo4j() {
+	s.t.Helper()
+	s.cleanDependency(DepNeo4j)
+}
+
+// CleanTemporal remove o namespace do tenant da suite e os históricos de workflow
+func (s *IntegrationTestSuite) CleanTemporal() {
+	s.t.Helper()
+	s.cleanDependency(DepTemporal)
+}
+
+// CleanVault remove todos os segredos do mount KV para isolamento entre testes
+func (s *IntegrationTestSuite) CleanVault() {
+	s.t.Helper()
+	s.cleanDependency(DepVault)
+}
+
+// CleanWireMock remove os stubs registrados e o histórico de requisições do WireMock
+func (s *IntegrationTestSuite) CleanWireMock() {
+	s.t.Helper()
+	s.cleanDependency(DepWireMock)
+}
+
+// CleanMailHog esvazia a caixa de entrada do MailHog
+func (s *IntegrationTestSuite) CleanMailHog() {
+	s.t.Helper()
+	s.cleanDependency(DepMailHog)
+}
+
+// CleanAzurite remove todos os blob containers (e blobs) do Azurite
+func (s *IntegrationTestSuite) CleanAzurite() {
+	s.t.Helper()
+	s.cleanDependency(DepAzurite)
+}
+
+// CleanGCS remove todos os buckets (e objetos) do fake GCS
+func (s *IntegrationTestSuite) CleanGCS() {
+	s.t.Helper()
+	s.cleanDependency(DepGCS)
+}
+
+// CleanPubSub remove os tópicos e subscriptions do tenant da suite
+func (s *IntegrationTestSuite) CleanPubSub() {
+	s.t.Helper()
+	s.cleanDependency(DepPubSub)
+}
+
+// CleanElasticMQ remove as mensagens de todas as filas do ElasticMQ
+func (s *IntegrationTestSuite) CleanElasticMQ() {
+	s.t.Helper()
+	s.cleanDependency(DepElasticMQ)
+}
+
+// CleanConsul remove as chaves e os serviços do tenant da suite
+func (s *IntegrationTestSuite) CleanConsul() {
+	s.t.Helper()
+	s.cleanDependency(DepConsul)
+}
+
+// CleanInflux remove todas as measurements do bucket de teste do InfluxDB
+func (s *IntegrationTestSuite) CleanInflux() {
+	s.t.Helper()
+	s.cleanDependency(DepInflux)
+}
+
+// CleanSQLServer limpa todas as tabelas do SQL Server, reiniciando as colunas identity
+func (s *IntegrationTestSuite) CleanSQLServer() {
+	s.t.Helper()
+	s.cleanDependency(DepSQLServer)
+}
+
+// CleanCouchbase remove todos os documentos do bucket do tenant da suite (N1QL DELETE)
+func (s *IntegrationTestSuite) CleanCouchbase() {
+	s.t.Helper()
+	s.cleanDependency(DepCouchbase)
+}
+
+// CleanSolr remove todos os documentos do core Solr configurado
+func (s *IntegrationTestSuite) CleanSolr() {
+	s.t.Helper()
+	s.cleanDependency(DepSolr)
+}
+
+// CleanMeilisearch remove todos os índices criados pelos helpers
+func (s *IntegrationTestSuite) CleanMeilisearch() {
+	s.t.Helper()
+	s.cleanDependency(DepMeilisearch)
+}
+
+// CleanTypesense remove todas as collections criadas pelos helpers
+func (s *IntegrationTestSuite) CleanTypesense() {
+	s.t.Helper()
+	s.cleanDependency(DepTypesense)
+}
+
+// CleanKibana remove os saved objects (dashboards, visualizações, index patterns...)
+func (s *IntegrationTestSuite) CleanKibana() {
+	s.t.Helper()
+	s.cleanDependency(DepKibana)
 }
 
-// CleanAll limpa todas as dependências configuradas
+// CleanAPM remove os data streams e índices de eventos APM
+func (s *IntegrationTestSuite) CleanAPM() {
+	s.t.Helper()
+	s.cleanDependency(DepAPM)
+}
+
+// CleanPrometheus remove todas as séries gravadas pelo Prometheus
+func (s *IntegrationTestSuite) CleanPrometheus() {
+	s.t.Helper()
+	s.cleanDependency(DepPrometheus)
+}
+
+// CleanSchemaRegistry remove definitivamente os subjects do tenant da suite (ver SchemaSubject)
+func (s *IntegrationTestSuite) CleanSchemaRegistry() {
+	s.t.Helper()
+	s.cleanDependency(DepSchemaRegistry)
+}
+
+// CleanArtemis remove as mensagens de todas as filas do Artemis
+func (s *IntegrationTestSuite) CleanArtemis() {
+	s.t.Helper()
+	s.cleanDependency(DepArtemis)
+}
+
+// CleanAll limpa todas as dependências configuradas, falhando o teste com os erros agregados
+// Para limpar sem falhar o teste (benchmarks, cenários longos), use Clean(ctx, deps...)
 func (s *IntegrationTestSuite) CleanAll() {
 	s.t.Helper()
 	
-	if s.ES() != nil {
-		s.CleanElasticsearch()
-	}
-	
-	if s.Mongo() != nil {
-		s.CleanMongo()
-	}
-	
-	if s.Postgres() != nil {
-		s.CleanPostgres()
-	}
+	err := s.Clean(s.ctx)
+	require.NoError(s.t, err, "Failed to clean dependencies")
 }
 
 // CreateIndex cria um novo índice com mapping opcional
 func (s *IntegrationTestSuite) CreateIndex(indexName string, mapping map[string]interface{}) {
 	s.t.Helper()
+	defer s.trackOperation("CreateIndex")()
+	indexName = s.RunScopedIndex(indexName)
 	
 	var body strings.Builder
 	if mapping != nil {
@@ -269,18 +2159,26 @@ func (s *IntegrationTestSuite) CreateIndex(indexName string, mapping map[string]
 	}
 }
 
-// IndexDocument indexa um documento no Elasticsearch
-func (s *IntegrationTestSuite) IndexDocument(indexName, docID string, document interface{}) {
+// IndexDocument indexa um documento no Elasticsearch (refresh=wait_for, salvo WithRefresh)
+func (s *IntegrationTestSuite) IndexDocument(indexName, docID string, document interface{}, opts ...WriteOption) {
 	s.t.Helper()
+	defer s.trackOperation("IndexDocument")()
+	indexName = s.RunScopedIndex(indexName)
 	
 	docJSON, err := json.Marshal(document)
 	require.NoError(s.t, err, "Failed to marshal document")
 	
+	options := newWriteOptions(opts)
 	req := esapi.IndexRequest{
-		Index:      indexName,
-		DocumentID: docID,
-		Body:       strings.NewReader(string(docJSON)),
-		Refresh:    "wait_for",
+		Index:       indexName,
+		DocumentID:  docID,
+		Body:        strings.NewReader(string(docJSON)),
+		Refresh:     options.refresh,
+		Routing:     options.routing,
+		Pipeline:    options.pipeline,
+		OpType:      options.opType,
+		Version:     options.version,
+		VersionType: options.versionType,
 	}
 	
 	res, err := req.Do(s.ctx, s.ES())
@@ -295,6 +2193,8 @@ func (s *IntegrationTestSuite) IndexDocument(indexName, docID string, document i
 // GetDocument recupera um documento do Elasticsearch
 func (s *IntegrationTestSuite) GetDocument(indexName, docID string, target interface{}) bool {
 	s.t.Helper()
+	defer s.trackOperation("GetDocument")()
+	indexName = s.RunScopedIndex(indexName)
 	
 	req := esapi.GetRequest{
 		Index:      indexName,
@@ -328,14 +2228,21 @@ func (s *IntegrationTestSuite) GetDocument(indexName, docID string, target inter
 	return true
 }
 
-// DeleteDocument remove um documento do Elasticsearch
-func (s *IntegrationTestSuite) DeleteDocument(indexName, docID string) {
+// DeleteDocument remove um documento do Elasticsearch (refresh=wait_for, salvo WithRefresh)
+// Pipeline e op_type não se aplicam à remoção e são ignorados
+func (s *IntegrationTestSuite) DeleteDocument(indexName, docID string, opts ...WriteOption) {
 	s.t.Helper()
+	defer s.trackOperation("DeleteDocument")()
+	indexName = s.RunScopedIndex(indexName)
 	
+	options := newWriteOptions(opts)
 	req := esapi.DeleteRequest{
-		Index:      indexName,
-		DocumentID: docID,
-		Refresh:    "wait_for",
+		Index:       indexName,
+		DocumentID:  docID,
+		Refresh:     options.refresh,
+		Routing:     options.routing,
+		Version:     options.version,
+		VersionType: options.versionType,
 	}
 	
 	res, err := req.Do(s.ctx, s.ES())
@@ -348,9 +2255,23 @@ func (s *IntegrationTestSuite) DeleteDocument(indexName, docID string) {
 }
 
 // SearchDocuments executa uma busca no Elasticsearch
+// Retorna uma única página (size da query, no máximo 10.000 hits); para todos os hits use SearchAllDocuments
 func (s *IntegrationTestSuite) SearchDocuments(indexName string, query map[string]interface{}) *SearchResult {
 	s.t.Helper()
 	
+	var searchResponse map[string]interface{}
+	err := json.Unmarshal(s.searchRaw(indexName, query), &searchResponse)
+	require.NoError(s.t, err, "Failed to decode search response")
+	
+	return &SearchResult{response: searchResponse}
+}
+
+// searchRaw executa a busca e retorna o corpo da resposta sem decodificar
+func (s *IntegrationTestSuite) searchRaw(indexName string, query map[string]interface{}) []byte {
+	s.t.Helper()
+	defer s.trackOperation("SearchDocuments")()
+	indexName = s.RunScopedIndex(indexName)
+	
 	queryJSON, err := json.Marshal(query)
 	require.NoError(s.t, err, "Failed to marshal query")
 	
@@ -367,27 +2288,119 @@ func (s *IntegrationTestSuite) SearchDocuments(indexName string, query map[strin
 		require.Fail(s.t, fmt.Sprintf("Failed to search: %s", res.Status()))
 	}
 	
-	var searchResponse map[string]interface{}
-	err = json.NewDecoder(res.Body).Decode(&searchResponse)
-	require.NoError(s.t, err, "Failed to decode search response")
-	
-	return &SearchResult{response: searchResponse}
+	raw, err := io.ReadAll(res.Body)
+	require.NoError(s.t, err, "Failed to read search response")
+	return raw
 }
 
-// WaitForIndexing aguarda a indexação dos documentos
+// WaitForIndexing faz refresh de todos os índices
+//
+// Deprecated: o refresh não espera escritas assíncronas (consumers, pipelines) e o antigo sleep fixo
+// era instável em CI lento. Use EventuallySearchHits, que repete a busca até o resultado esperado
 func (s *IntegrationTestSuite) WaitForIndexing() {
 	s.t.Helper()
 	
 	err := s.sharedES.RefreshIndices(s.ctx)
 	require.NoError(s.t, err, "Failed to refresh indices")
+}
+
+// EventuallySearchHits repete refresh + busca até a query retornar exatamente expectedHits documentos
+// e retorna o resultado. Índice ainda inexistente conta como zero hits; ao estourar o timeout falha
+// com a última contagem
+func (s *IntegrationTestSuite) EventuallySearchHits(indexName string, query map[string]interface{}, expectedHits int, timeout time.Duration) *SearchResult {
+	s.t.Helper()
+	defer s.trackOperation("EventuallySearchHits")()
+	
+	require.NotNil(s.t, s.ES(), "Elasticsearch not configured")
+	indexName = s.RunScopedIndex(indexName)
+	
+	// track_total_hits garante a contagem exata acima de 10.000 documentos
+	body := make(map[string]interface{}, len(query)+1)
+	for key, value := range query {
+		body[key] = value
+	}
+	body["track_total_hits"] = true
+	queryJSON, err := json.Marshal(body)
+	require.NoError(s.t, err, "Failed to marshal query")
+	
+	var (
+		result  *SearchResult
+		lastErr error
+		hits    int
+	)
+	interval := 50 * time.Millisecond
+	deadline := time.Now().Add(timeout)
+	for {
+		result, lastErr = s.searchOnce(indexName, queryJSON)
+		hits = 0
+		if result != nil {
+			hits = result.TotalHits()
+		}
+		if lastErr == nil && hits == expectedHits {
+			return result
+		}
+		
+		if time.Now().After(deadline) {
+			message := fmt.Sprintf("Expected %d hits in %s within %s, got %d", expectedHits, indexName, timeout, hits)
+			if lastErr != nil {
+				message += fmt.Sprintf(" (last error: %v)", lastErr)
+			}
+			require.Fail(s.t, message)
+			return result
+		}
+		
+		select {
+		case <-s.ctx.Done():
+			require.Fail(s.t, fmt.Sprintf("Context canceled waiting for %d hits in %s: %v", expectedHits, indexName, s.ctx.Err()))
+			return result
+		case <-time.After(interval):
+		}
+		if interval < time.Second {
+			interval *= 2
+		}
+	}
+}
+
+// searchOnce faz refresh (tolerante a índice inexistente) e executa a busca uma vez
+// Índice inexistente retorna resultado vazio sem erro
+func (s *IntegrationTestSuite) searchOnce(indexName string, queryJSON []byte) (*SearchResult, error) {
+	client := s.ES()
+	
+	if res, err := client.Indices.Refresh(
+		client.Indices.Refresh.WithContext(s.ctx),
+		client.Indices.Refresh.WithIndex(indexName),
+		client.Indices.Refresh.WithIgnoreUnavailable(true),
+	); err == nil {
+		res.Body.Close()
+	}
+	
+	req := esapi.SearchRequest{
+		Index:             []string{indexName},
+		Body:              strings.NewReader(string(queryJSON)),
+		IgnoreUnavailable: esapi.BoolPtr(true),
+		AllowNoIndices:    esapi.BoolPtr(true),
+	}
+	res, err := req.Do(s.ctx, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute search: %w", err)
+	}
+	defer res.Body.Close()
+	
+	if res.IsError() {
+		return nil, fmt.Errorf("failed to search: %s", res.String())
+	}
 	
-	// Pequeno delay adicional para garantir consistência
-	time.Sleep(50 * time.Millisecond)
+	var searchResponse map[string]interface{}
+	if err := json.NewDecoder(res.Body).Decode(&searchResponse); err != nil {
+		return nil, fmt.Errorf("failed to decode search response: %w", err)
+	}
+	return &SearchResult{response: searchResponse}, nil
 }
 
 // AssertIndexExists verifica se um índice existe
 func (s *IntegrationTestSuite) AssertIndexExists(indexName string) {
 	s.t.Helper()
+	indexName = s.RunScopedIndex(indexName)
 	
 	req := esapi.IndicesExistsRequest{
 		Index: []string{indexName},
@@ -403,6 +2416,7 @@ func (s *IntegrationTestSuite) AssertIndexExists(indexName string) {
 // AssertIndexNotExists verifica se um índice não existe
 func (s *IntegrationTestSuite) AssertIndexNotExists(indexName string) {
 	s.t.Helper()
+	indexName = s.RunScopedIndex(indexName)
 	
 	req := esapi.IndicesExistsRequest{
 		Index: []string{indexName},
@@ -0,0 +1,306 @@
+package testhelper
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"github.com/stretchr/testify/require"
+)
+
+// mappingUpdatableParams são os parâmetros de campo que o PUT _mapping aceita alterar sem reindex
+var mappingUpdatableParams = map[string]bool{
+	"ignore_above":          true,
+	"ignore_malformed":      true,
+	"search_analyzer":       true,
+	"search_quote_analyzer": true,
+	"meta":                  true,
+}
+
+// MappingTypeChange é a troca de tipo de um campo
+type MappingTypeChange struct {
+	From string
+	To   string
+}
+
+// MappingParamChange é a alteração de um parâmetro de campo (analyzer, format, index...)
+type MappingParamChange struct {
+	Field string
+	Param string
+	From  interface{}
+	To    interface{}
+	// Updatable indica que o PUT _mapping aceita a alteração sem reindex
+	Updatable bool
+}
+
+// MappingDiff é o plano de migração entre o mapping atual do índice e o proposto
+// Campos são identificados pelo caminho completo, incluindo objetos e multi-fields (ex.: "title.keyword")
+type MappingDiff struct {
+	Added         map[string]string
+	Removed       map[string]string
+	Retyped       map[string]MappingTypeChange
+	ParamsChanged []MappingParamChange
+	// RequiresReindex indica que o mapping proposto não pode ser aplicado com PUT _mapping
+	RequiresReindex bool
+	// ReindexReasons explica por que o reindex é necessário, um item por campo/parâmetro
+	ReindexReasons []string
+}
+
+// HasChanges indica se os mappings diferem
+func (d MappingDiff) HasChanges() bool {
+	return len(d.Added) > 0 || len(d.Removed) > 0 || len(d.Retyped) > 0 || len(d.ParamsChanged) > 0
+}
+
+// String resume o plano de migração (uma linha por alteração), útil nas mensagens de asserção
+func (d MappingDiff) String() string {
+	if !d.HasChanges() {
+		return "no mapping changes"
+	}
+
+	var lines []string
+	for _, field := range sortedKeys(d.Added) {
+		lines = append(lines, fmt.Sprintf("+ %s (%s)", field, d.Added[field]))
+	}
+	for _, field := range sortedKeys(d.Removed) {
+		lines = append(lines, fmt.Sprintf("- %s (%s)", field, d.Removed[field]))
+	}
+	for _, field := range sortedKeys(d.Retyped) {
+		lines = append(lines, fmt.Sprintf("~ %s: %s -> %s", field, d.Retyped[field].From, d.Retyped[field].To))
+	}
+	for _, change := range d.ParamsChanged {
+		lines = append(lines, fmt.Sprintf("~ %s.%s: %v -> %v", change.Field, change.Param, change.From, change.To))
+	}
+	if d.RequiresReindex {
+		lines = append(lines, "reindex required: "+strings.Join(d.ReindexReasons, "; "))
+	} else {
+		lines = append(lines, "applicable with PUT _mapping")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// DiffMapping compara o mapping atual do índice com o proposto (o conteúdo de "mappings", no mesmo
+// formato de CreateIndex) sem alterar nada, retornando campos adicionados, removidos e com tipo trocado
+// e se a migração exige reindex
+func (s *IntegrationTestSuite) DiffMapping(index string, proposedMapping map[string]interface{}) MappingDiff {
+	s.t.Helper()
+	defer s.trackOperation("DiffMapping")()
+	index = s.RunScopedIndex(index)
+
+	res, err := esapi.IndicesGetMappingRequest{Index: []string{index}}.Do(s.ctx, s.ES())
+	require.NoError(s.t, err, "Failed to get mapping of %s", index)
+	defer res.Body.Close()
+
+	if res.IsError() {
+		require.Fail(s.t, fmt.Sprintf("Failed to get mapping of %s: %s", index, res.String()))
+		return MappingDiff{}
+	}
+
+	var body map[string]struct {
+		Mappings map[string]interface{} `json:"mappings"`
+	}
+	require.NoError(s.t, json.NewDecoder(res.Body).Decode(&body), "Failed to decode mapping of %s", index)
+	require.Len(s.t, body, 1, "Mapping of %s resolves to more than one index", index)
+
+	var current map[string]interface{}
+	for _, entry := range body {
+		current = entry.Mappings
+	}
+
+	// Aceita também o corpo completo de criação do índice ({"mappings": {...}})
+	if inner, ok := proposedMapping["mappings"].(map[string]interface{}); ok {
+		proposedMapping = inner
+	}
+	proposed, err := normalizeMapping(proposedMapping)
+	require.NoError(s.t, err, "Invalid proposed mapping")
+
+	return diffMappings(current, proposed)
+}
+
+// normalizeMapping converte o mapping para a forma decodificada de JSON usada na comparação
+func normalizeMapping(mapping map[string]interface{}) (map[string]interface{}, error) {
+	raw, err := json.Marshal(mapping)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal mapping: %w", err)
+	}
+
+	var out map[string]interface{}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, fmt.Errorf("failed to decode mapping: %w", err)
+	}
+	return out, nil
+}
+
+// diffMappings calcula o plano de migração entre dois mappings já normalizados
+func diffMappings(current, proposed map[string]interface{}) MappingDiff {
+	from := flattenMappingFields(current)
+	to := flattenMappingFields(proposed)
+
+	diff := MappingDiff{
+		Added:   map[string]string{},
+		Removed: map[string]string{},
+		Retyped: map[string]MappingTypeChange{},
+	}
+	reindex := func(reason string) {
+		diff.RequiresReindex = true
+		diff.ReindexReasons = append(diff.ReindexReasons, reason)
+	}
+
+	for _, field := range sortedKeys(from) {
+		before := from[field]
+		after, ok := to[field]
+		if !ok {
+			diff.Removed[field] = before.fieldType
+			reindex(fmt.Sprintf("field %s removed (mappings cannot drop fields)", field))
+			continue
+		}
+
+		if before.fieldType != after.fieldType {
+			diff.Retyped[field] = MappingTypeChange{From: before.fieldType, To: after.fieldType}
+			reindex(fmt.Sprintf("field %s retyped from %s to %s", field, before.fieldType, after.fieldType))
+			continue
+		}
+
+		params := map[string]bool{}
+		for param := range before.params {
+			params[param] = true
+		}
+		for param := range after.params {
+			params[param] = true
+		}
+		for _, param := range sortedKeys(params) {
+			// O GET _mapping omite os parâmetros com o valor padrão: um lado sem o parâmetro vale o padrão
+			// (ex.: "index": true explícito no proposto não é uma alteração)
+			if reflect.DeepEqual(effectiveMappingParam(before, param), effectiveMappingParam(after, param)) {
+				continue
+			}
+			change := MappingParamChange{
+				Field:     field,
+				Param:     param,
+				From:      before.params[param],
+				To:        after.params[param],
+				Updatable: mappingUpdatableParams[param],
+			}
+			diff.ParamsChanged = append(diff.ParamsChanged, change)
+			if !change.Updatable {
+				reindex(fmt.Sprintf("parameter %s of field %s changed", param, field))
+			}
+		}
+	}
+
+	for _, field := range sortedKeys(to) {
+		if _, ok := from[field]; !ok {
+			diff.Added[field] = to[field].fieldType
+		}
+	}
+
+	return diff
+}
+
+// mappingParamDefaults são os valores padrão do Elasticsearch para os parâmetros de campo, por tipo
+// ("" vale para todos os tipos). Parâmetros sem padrão conhecido só são iguais quando os dois lados batem
+var mappingParamDefaults = map[string]map[string]interface{}{
+	"": {
+		"index":            true,
+		"doc_values":       true,
+		"store":            false,
+		"ignore_malformed": false,
+		"coerce":           true,
+	},
+	"text": {
+		"analyzer":               "standard",
+		"norms":                  true,
+		"index_options":          "positions",
+		"eager_global_ordinals":  false,
+		"fielddata":              false,
+		"index_phrases":          false,
+		"position_increment_gap": float64(100),
+		"similarity":             "BM25",
+		"term_vector":            "no",
+	},
+	"keyword": {
+		"norms":                       false,
+		"index_options":               "docs",
+		"eager_global_ordinals":       false,
+		"similarity":                  "BM25",
+		"split_queries_on_whitespace": false,
+	},
+	"date": {
+		"format": "strict_date_optional_time||epoch_millis",
+	},
+	"object": {
+		"enabled": true,
+	},
+	"nested": {
+		"include_in_parent": false,
+		"include_in_root":   false,
+	},
+}
+
+// effectiveMappingParam retorna o valor do parâmetro no campo ou, ausente, o padrão do tipo
+func effectiveMappingParam(field mappingField, param string) interface{} {
+	if value, ok := field.params[param]; ok {
+		return value
+	}
+	if value, ok := mappingParamDefaults[field.fieldType][param]; ok {
+		return value
+	}
+	return mappingParamDefaults[""][param]
+}
+
+// mappingField é um campo do mapping achatado: tipo e parâmetros (exceto properties/fields)
+type mappingField struct {
+	fieldType string
+	params    map[string]interface{}
+}
+
+// flattenMappingFields achata properties (objetos e nested) e multi-fields em caminho -> campo
+func flattenMappingFields(mapping map[string]interface{}) map[string]mappingField {
+	fields := make(map[string]mappingField)
+
+	var walk func(prefix string, properties map[string]interface{})
+	walk = func(prefix string, properties map[string]interface{}) {
+		for name, raw := range properties {
+			def, ok := raw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			path := prefix + name
+
+			field := mappingField{fieldType: "object", params: map[string]interface{}{}}
+			if fieldType, ok := def["type"].(string); ok {
+				field.fieldType = fieldType
+			}
+			for key, value := range def {
+				if key != "type" && key != "properties" && key != "fields" {
+					field.params[key] = value
+				}
+			}
+			fields[path] = field
+
+			if children, ok := def["properties"].(map[string]interface{}); ok {
+				walk(path+".", children)
+			}
+			if multi, ok := def["fields"].(map[string]interface{}); ok {
+				walk(path+".", multi)
+			}
+		}
+	}
+
+	if properties, ok := mapping["properties"].(map[string]interface{}); ok {
+		walk("", properties)
+	}
+	return fields
+}
+
+// sortedKeys retorna as chaves do mapa em ordem
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
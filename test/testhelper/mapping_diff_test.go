@@ -0,0 +1,120 @@
+package testhelper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffMappings(t *testing.T) {
+	current := map[string]interface{}{
+		"properties": map[string]interface{}{
+			"name": map[string]interface{}{
+				"type": "text",
+				"fields": map[string]interface{}{
+					"keyword": map[string]interface{}{"type": "keyword", "ignore_above": float64(256)},
+				},
+			},
+			"price":      map[string]interface{}{"type": "float"},
+			"created_at": map[string]interface{}{"type": "date"},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		proposed     map[string]interface{}
+		changes      bool
+		reindex      bool
+		paramChanges int
+	}{
+		{
+			name:     "Same mapping",
+			proposed: current,
+		},
+		{
+			name: "Params explicitly set to the Elasticsearch default",
+			proposed: properties(map[string]interface{}{
+				"name": map[string]interface{}{
+					"type": "text", "index": true, "analyzer": "standard", "norms": true,
+					"fields": map[string]interface{}{
+						"keyword": map[string]interface{}{"type": "keyword", "ignore_above": float64(256), "doc_values": true},
+					},
+				},
+				"price":      map[string]interface{}{"type": "float", "coerce": true, "store": false},
+				"created_at": map[string]interface{}{"type": "date", "format": "strict_date_optional_time||epoch_millis"},
+			}),
+		},
+		{
+			name: "Param added with a non-default value",
+			proposed: properties(map[string]interface{}{
+				"name": map[string]interface{}{
+					"type": "text", "analyzer": "portuguese",
+					"fields": map[string]interface{}{
+						"keyword": map[string]interface{}{"type": "keyword", "ignore_above": float64(256)},
+					},
+				},
+				"price":      map[string]interface{}{"type": "float"},
+				"created_at": map[string]interface{}{"type": "date"},
+			}),
+			changes:      true,
+			reindex:      true,
+			paramChanges: 1,
+		},
+		{
+			name: "Updatable param changed",
+			proposed: properties(map[string]interface{}{
+				"name": map[string]interface{}{
+					"type": "text",
+					"fields": map[string]interface{}{
+						"keyword": map[string]interface{}{"type": "keyword", "ignore_above": float64(512)},
+					},
+				},
+				"price":      map[string]interface{}{"type": "float"},
+				"created_at": map[string]interface{}{"type": "date"},
+			}),
+			changes:      true,
+			paramChanges: 1,
+		},
+		{
+			name: "Field retyped",
+			proposed: properties(map[string]interface{}{
+				"name": map[string]interface{}{
+					"type": "text",
+					"fields": map[string]interface{}{
+						"keyword": map[string]interface{}{"type": "keyword", "ignore_above": float64(256)},
+					},
+				},
+				"price":      map[string]interface{}{"type": "scaled_float", "scaling_factor": float64(100)},
+				"created_at": map[string]interface{}{"type": "date"},
+			}),
+			changes: true,
+			reindex: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := diffMappings(current, tt.proposed)
+
+			assert.Equal(t, tt.changes, diff.HasChanges(), diff.String())
+			assert.Equal(t, tt.reindex, diff.RequiresReindex, diff.String())
+			assert.Len(t, diff.ParamsChanged, tt.paramChanges, diff.String())
+		})
+	}
+
+	t.Run("Field added and removed", func(t *testing.T) {
+		diff := diffMappings(current, properties(map[string]interface{}{
+			"name":  map[string]interface{}{"type": "text"},
+			"price": map[string]interface{}{"type": "float"},
+			"sku":   map[string]interface{}{"type": "keyword"},
+		}))
+
+		assert.Equal(t, map[string]string{"sku": "keyword"}, diff.Added)
+		assert.Equal(t, map[string]string{"created_at": "date", "name.keyword": "keyword"}, diff.Removed)
+		assert.True(t, diff.RequiresReindex)
+	})
+}
+
+func properties(fields map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"properties": fields}
+}
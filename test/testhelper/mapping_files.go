@@ -0,0 +1,90 @@
+package testhelper
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"github.com/stretchr/testify/require"
+)
+
+// CreateIndexFromFile cria o índice com o mapping versionado em path. O arquivo pode ser o corpo completo
+// de criação ({"settings": ..., "mappings": ...}) ou só o conteúdo de "mappings" ({"properties": ...})
+// O arquivo é lido do fs.FS configurado com WithFixturesFS, se houver
+func (s *IntegrationTestSuite) CreateIndexFromFile(index, path string) {
+	s.t.Helper()
+	defer s.trackOperation("CreateIndexFromFile")()
+
+	client := s.ES()
+	require.NotNil(s.t, client, "Elasticsearch not configured")
+	index = s.RunScopedIndex(index)
+
+	body := s.readMappingFile(path)
+	if _, full := body["mappings"]; !full {
+		if _, hasSettings := body["settings"]; !hasSettings {
+			body = map[string]interface{}{"mappings": body}
+		}
+	}
+
+	bodyJSON, err := json.Marshal(body)
+	require.NoError(s.t, err, "Failed to marshal index body from %s", path)
+
+	res, err := esapi.IndicesCreateRequest{
+		Index: index,
+		Body:  strings.NewReader(string(bodyJSON)),
+	}.Do(s.ctx, client)
+	require.NoError(s.t, err, "Failed to create index %s", index)
+	defer res.Body.Close()
+
+	if res.IsError() {
+		require.Fail(s.t, fmt.Sprintf("Failed to create index %s from %s: %s", index, path, res.String()))
+	}
+}
+
+// AssertMappingEquals compara o mapping atual do índice com o arquivo (mesmos formatos de
+// CreateIndexFromFile) e falha com um diff por campo. Campos criados por dynamic mapping durante o teste
+// aparecem como "not in file", apontando o documento que escapou do mapping explícito
+func (s *IntegrationTestSuite) AssertMappingEquals(index, expectedPath string) {
+	s.t.Helper()
+
+	expected := s.readMappingFile(expectedPath)
+	diff := s.DiffMapping(index, expected)
+	if !diff.HasChanges() {
+		return
+	}
+
+	require.Fail(s.t, fmt.Sprintf("Mapping of %s drifted from %s", s.RunScopedIndex(index), expectedPath),
+		"%s", formatMappingDrift(diff))
+}
+
+// readMappingFile lê e decodifica o arquivo de mapping
+func (s *IntegrationTestSuite) readMappingFile(path string) map[string]interface{} {
+	s.t.Helper()
+
+	data, err := readFixtureFile(s.fixtures, path)
+	require.NoError(s.t, err, "Failed to read mapping file %s", path)
+
+	var body map[string]interface{}
+	require.NoError(s.t, json.Unmarshal(data, &body), "Invalid JSON in mapping file %s", path)
+	return body
+}
+
+// formatMappingDrift descreve o diff do ponto de vista do arquivo esperado (o índice é o "atual")
+func formatMappingDrift(diff MappingDiff) string {
+	var lines []string
+	for _, field := range sortedKeys(diff.Removed) {
+		lines = append(lines, fmt.Sprintf("  + %s (%s): mapped in the index but not in file (dynamic mapping?)", field, diff.Removed[field]))
+	}
+	for _, field := range sortedKeys(diff.Added) {
+		lines = append(lines, fmt.Sprintf("  - %s (%s): in file but missing from the index", field, diff.Added[field]))
+	}
+	for _, field := range sortedKeys(diff.Retyped) {
+		change := diff.Retyped[field]
+		lines = append(lines, fmt.Sprintf("  ~ %s: index has %s, file expects %s", field, change.From, change.To))
+	}
+	for _, change := range diff.ParamsChanged {
+		lines = append(lines, fmt.Sprintf("  ~ %s.%s: index has %v, file expects %v", change.Field, change.Param, change.From, change.To))
+	}
+	return strings.Join(lines, "\n")
+}
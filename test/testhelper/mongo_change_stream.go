@@ -0,0 +1,247 @@
+package testhelper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// Tipos de operação dos eventos de change stream
+const (
+	ChangeInsert  = "insert"
+	ChangeUpdate  = "update"
+	ChangeReplace = "replace"
+	ChangeDelete  = "delete"
+)
+
+// defaultChangeStreamTimeout é a espera padrão do AssertEventReceived
+const defaultChangeStreamTimeout = 10 * time.Second
+
+// ChangeStreamEvent é um evento de change stream do MongoDB
+// FullDocument vem preenchido em insert/replace e, via updateLookup, em update; em delete só há DocumentKey
+type ChangeStreamEvent struct {
+	OperationType string
+	DocumentKey   bson.M
+	FullDocument  bson.M
+	UpdatedFields bson.M
+	RemovedFields []string
+}
+
+// ChangeStreamRecorder acumula os eventos de um change stream aberto para o teste
+// Eventos de documentos de outros tenants (campo tenant_id diferente) são descartados
+type ChangeStreamRecorder struct {
+	suite      *IntegrationTestSuite
+	collection string
+	timeout    time.Duration
+	mu         sync.Mutex
+	events     []ChangeStreamEvent
+	err        error
+	done       chan struct{}
+}
+
+// RecordChangeStream abre um change stream na coleção e passa a acumular os eventos; mudanças feitas
+// depois do retorno são gravadas. O stream é fechado ao final do teste
+// Change streams exigem replica set: use WithMongoReplicaSet() ou MONGO_REPLICA_SET=true
+func (s *IntegrationTestSuite) RecordChangeStream(collection string) *ChangeStreamRecorder {
+	s.t.Helper()
+	defer s.trackOperation("RecordChangeStream")()
+
+	db := s.Mongo()
+	require.NotNil(s.t, db, "MongoDB not configured")
+
+	opts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+	stream, err := db.Collection(collection).Watch(s.ctx, mongo.Pipeline{}, opts)
+
+	var cmdErr mongo.CommandError
+	if err != nil && errors.As(err, &cmdErr) && strings.Contains(cmdErr.Message, "replica set") {
+		require.Fail(s.t, "MongoDB change streams require a replica set, use WithMongoReplicaSet()", cmdErr.Message)
+	}
+	require.NoError(s.t, err, "Failed to open change stream on %s", collection)
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	r := &ChangeStreamRecorder{
+		suite:      s,
+		collection: collection,
+		timeout:    defaultChangeStreamTimeout,
+		done:       make(chan struct{}),
+	}
+
+	go func() {
+		defer close(r.done)
+		for stream.Next(ctx) {
+			var raw struct {
+				OperationType     string `bson:"operationType"`
+				DocumentKey       bson.M `bson:"documentKey"`
+				FullDocument      bson.M `bson:"fullDocument"`
+				UpdateDescription struct {
+					UpdatedFields bson.M   `bson:"updatedFields"`
+					RemovedFields []string `bson:"removedFields"`
+				} `bson:"updateDescription"`
+			}
+			if err := stream.Decode(&raw); err != nil {
+				r.setErr(fmt.Errorf("failed to decode change event: %w", err))
+				return
+			}
+
+			event := ChangeStreamEvent{
+				OperationType: raw.OperationType,
+				DocumentKey:   raw.DocumentKey,
+				FullDocument:  raw.FullDocument,
+				UpdatedFields: raw.UpdateDescription.UpdatedFields,
+				RemovedFields: raw.UpdateDescription.RemovedFields,
+			}
+			if tenant, ok := event.FullDocument["tenant_id"]; ok && fmt.Sprint(tenant) != s.tenantID {
+				continue
+			}
+
+			r.mu.Lock()
+			r.events = append(r.events, event)
+			r.mu.Unlock()
+		}
+		if err := stream.Err(); err != nil && ctx.Err() == nil {
+			r.setErr(err)
+		}
+	}()
+
+	s.t.Cleanup(func() {
+		cancel()
+		<-r.done
+		stream.Close(context.Background())
+	})
+
+	return r
+}
+
+// SetTimeout define a espera do AssertEventReceived (padrão 10s)
+func (r *ChangeStreamRecorder) SetTimeout(timeout time.Duration) *ChangeStreamRecorder {
+	r.timeout = timeout
+	return r
+}
+
+// Events retorna os eventos gravados até agora
+func (r *ChangeStreamRecorder) Events() []ChangeStreamEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]ChangeStreamEvent(nil), r.events...)
+}
+
+// AssertEventReceived aguarda um evento da operação (ChangeInsert, ChangeUpdate...; vazio aceita qualquer)
+// cujo documento case com o filtro e o retorna. As chaves do filtro aceitam caminhos com ponto
+// ("address.city") e são procuradas no FullDocument, nos campos alterados e no DocumentKey
+func (r *ChangeStreamRecorder) AssertEventReceived(op string, filter bson.M) ChangeStreamEvent {
+	r.suite.t.Helper()
+
+	deadline := time.Now().Add(r.timeout)
+	for {
+		events := r.Events()
+		for _, event := range events {
+			if (op == "" || event.OperationType == op) && event.Matches(filter) {
+				return event
+			}
+		}
+
+		r.mu.Lock()
+		err := r.err
+		r.mu.Unlock()
+		require.NoError(r.suite.t, err, "Change stream on %s failed", r.collection)
+
+		if time.Now().After(deadline) {
+			require.Fail(r.suite.t, fmt.Sprintf("No %s event matching %v on %s within %s", opLabel(op), filter, r.collection, r.timeout),
+				"Received %d event(s):\n%s", len(events), formatChangeEvents(events))
+			return ChangeStreamEvent{}
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// Matches indica se todos os campos do filtro têm o valor informado no evento
+func (e ChangeStreamEvent) Matches(filter bson.M) bool {
+	for path, expected := range filter {
+		actual, ok := bsonLookup(e.FullDocument, path)
+		if !ok {
+			actual, ok = bsonLookup(e.UpdatedFields, path)
+		}
+		if !ok {
+			actual, ok = bsonLookup(e.DocumentKey, path)
+		}
+		if !ok || fmt.Sprint(actual) != fmt.Sprint(expected) {
+			return false
+		}
+	}
+	return true
+}
+
+// setErr registra o primeiro erro do stream
+func (r *ChangeStreamRecorder) setErr(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+// bsonLookup resolve um caminho com ponto em documentos bson.M/bson.D aninhados
+// updatedFields usa o caminho completo como chave ("address.city"), então a chave inteira é tentada antes
+func bsonLookup(doc interface{}, path string) (interface{}, bool) {
+	if value, ok := bsonField(doc, path); ok {
+		return value, true
+	}
+
+	head, rest, nested := strings.Cut(path, ".")
+	if !nested {
+		return nil, false
+	}
+	child, ok := bsonField(doc, head)
+	if !ok {
+		return nil, false
+	}
+	return bsonLookup(child, rest)
+}
+
+// bsonField retorna o campo do documento, aceitando os tipos de documento decodificados pelo driver
+func bsonField(doc interface{}, key string) (interface{}, bool) {
+	switch d := doc.(type) {
+	case bson.M:
+		value, ok := d[key]
+		return value, ok
+	case map[string]interface{}:
+		value, ok := d[key]
+		return value, ok
+	case bson.D:
+		for _, elem := range d {
+			if elem.Key == key {
+				return elem.Value, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// opLabel descreve a operação esperada na mensagem de falha
+func opLabel(op string) string {
+	if op == "" {
+		return "change"
+	}
+	return op
+}
+
+// formatChangeEvents lista os eventos recebidos (operação e documento) para a mensagem de falha
+func formatChangeEvents(events []ChangeStreamEvent) string {
+	var out strings.Builder
+	for _, event := range events {
+		doc := event.FullDocument
+		if doc == nil {
+			doc = event.DocumentKey
+		}
+		fmt.Fprintf(&out, "  %s %v\n", event.OperationType, doc)
+	}
+	return out.String()
+}
@@ -0,0 +1,164 @@
+package testhelper
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// Os arquivos GridFS gravados pelos helpers levam o tenant da suite em metadata.tenant_id, como os
+// documentos das coleções; leitura, listagem e limpeza consideram apenas os arquivos do tenant
+
+// GridFSFile é um arquivo GridFS do tenant (documento da coleção <bucket>.files)
+type GridFSFile struct {
+	ID         bson.ObjectID `bson:"_id"`
+	Name       string        `bson:"filename"`
+	Length     int64         `bson:"length"`
+	UploadDate time.Time     `bson:"uploadDate"`
+	Metadata   bson.M        `bson:"metadata"`
+}
+
+// gridFSCleanups guarda os buckets já agendados para limpeza (um registro por teste e bucket)
+var gridFSCleanups sync.Map
+
+// gridFSBucket retorna o bucket GridFS no database principal do MongoDB
+func (s *IntegrationTestSuite) gridFSBucket(bucket string) *mongo.GridFSBucket {
+	s.t.Helper()
+
+	db := s.Mongo()
+	require.NotNil(s.t, db, "MongoDB not configured")
+	return db.GridFSBucket(options.GridFSBucket().SetName(bucket))
+}
+
+// PutGridFSFile grava o conteúdo no bucket com o nome informado e metadata.tenant_id do tenant da suite
+// metadata (opcional) é mesclado aos metadados. Os arquivos do tenant são removidos ao final do teste
+func (s *IntegrationTestSuite) PutGridFSFile(bucket, name string, r io.Reader, metadata ...bson.M) bson.ObjectID {
+	s.t.Helper()
+	defer s.trackOperation("PutGridFSFile")()
+
+	meta := bson.M{}
+	for _, m := range metadata {
+		for key, value := range m {
+			meta[key] = value
+		}
+	}
+	meta["tenant_id"] = s.tenantID
+
+	id, err := s.gridFSBucket(bucket).UploadFromStream(s.ctx, name, r, options.GridFSUpload().SetMetadata(meta))
+	require.NoError(s.t, err, "Failed to upload GridFS file %s to %s", name, bucket)
+
+	s.scheduleGridFSCleanup(bucket)
+	return id
+}
+
+// GetGridFSFile lê a revisão mais recente do arquivo do tenant com o nome informado
+func (s *IntegrationTestSuite) GetGridFSFile(bucket, name string) []byte {
+	s.t.Helper()
+
+	file, ok := s.findGridFSFile(bucket, name)
+	require.True(s.t, ok, "GridFS file %s not found in %s for tenant %s", name, bucket, s.tenantID)
+
+	var buf bytes.Buffer
+	_, err := s.gridFSBucket(bucket).DownloadToStream(s.ctx, file.ID, &buf)
+	require.NoError(s.t, err, "Failed to download GridFS file %s from %s", name, bucket)
+	return buf.Bytes()
+}
+
+// GridFSFileExists indica se o tenant tem um arquivo com o nome no bucket
+func (s *IntegrationTestSuite) GridFSFileExists(bucket, name string) bool {
+	s.t.Helper()
+
+	_, ok := s.findGridFSFile(bucket, name)
+	return ok
+}
+
+// GridFSFiles lista os arquivos do tenant no bucket, ordenados por nome e data de upload
+func (s *IntegrationTestSuite) GridFSFiles(bucket string) []GridFSFile {
+	s.t.Helper()
+
+	cursor, err := s.gridFSBucket(bucket).Find(s.ctx,
+		bson.M{"metadata.tenant_id": s.tenantID},
+		options.GridFSFind().SetSort(bson.D{{Key: "filename", Value: 1}, {Key: "uploadDate", Value: 1}}),
+	)
+	require.NoError(s.t, err, "Failed to list GridFS files of %s", bucket)
+
+	files := []GridFSFile{}
+	require.NoError(s.t, cursor.All(s.ctx, &files), "Failed to decode GridFS files of %s", bucket)
+	return files
+}
+
+// CleanGridFSBucket remove os arquivos (e chunks) do tenant no bucket; retorna quantos foram removidos
+func (s *IntegrationTestSuite) CleanGridFSBucket(bucket string) int {
+	s.t.Helper()
+
+	removed, err := deleteTenantGridFSFiles(s.ctx, s.gridFSBucket(bucket), s.tenantID)
+	require.NoError(s.t, err, "Failed to clean GridFS bucket %s", bucket)
+	return removed
+}
+
+// findGridFSFile busca a revisão mais recente do arquivo do tenant
+func (s *IntegrationTestSuite) findGridFSFile(bucket, name string) (GridFSFile, bool) {
+	s.t.Helper()
+
+	cursor, err := s.gridFSBucket(bucket).Find(s.ctx,
+		bson.M{"filename": name, "metadata.tenant_id": s.tenantID},
+		options.GridFSFind().SetSort(bson.D{{Key: "uploadDate", Value: -1}}).SetLimit(1),
+	)
+	require.NoError(s.t, err, "Failed to find GridFS file %s in %s", name, bucket)
+
+	var files []GridFSFile
+	require.NoError(s.t, cursor.All(s.ctx, &files), "Failed to decode GridFS file %s", name)
+	if len(files) == 0 {
+		return GridFSFile{}, false
+	}
+	return files[0], true
+}
+
+// scheduleGridFSCleanup agenda a remoção dos arquivos do tenant no bucket ao final do teste
+func (s *IntegrationTestSuite) scheduleGridFSCleanup(bucket string) {
+	type cleanupKey struct {
+		t      TestingT
+		bucket string
+	}
+	key := cleanupKey{s.t, bucket}
+	if _, loaded := gridFSCleanups.LoadOrStore(key, struct{}{}); loaded {
+		return
+	}
+
+	gridFS := s.gridFSBucket(bucket)
+	tenantID := s.tenantID
+	s.onCleanup(func() {
+		gridFSCleanups.Delete(key)
+		if _, err := deleteTenantGridFSFiles(context.Background(), gridFS, tenantID); err != nil && isDebugEnabled() {
+			fmt.Printf("⚠️  Failed to clean GridFS bucket %s: %v\n", bucket, err)
+		}
+	})
+}
+
+// deleteTenantGridFSFiles remove os arquivos do tenant, incluindo os chunks (GridFSBucket.Delete)
+func deleteTenantGridFSFiles(ctx context.Context, bucket *mongo.GridFSBucket, tenantID string) (int, error) {
+	cursor, err := bucket.Find(ctx, bson.M{"metadata.tenant_id": tenantID})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list gridfs files: %w", err)
+	}
+
+	var files []GridFSFile
+	if err := cursor.All(ctx, &files); err != nil {
+		return 0, fmt.Errorf("failed to decode gridfs files: %w", err)
+	}
+
+	for _, file := range files {
+		if err := bucket.Delete(ctx, file.ID); err != nil {
+			return 0, fmt.Errorf("failed to delete gridfs file %s: %w", file.Name, err)
+		}
+	}
+	return len(files), nil
+}
@@ -0,0 +1,36 @@
+package testhelper
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// MongoTransaction executa fn em uma transação multi-documento do MongoDB, com commit se fn retornar nil
+// e abort caso contrário; o erro de fn (ou do commit) é retornado para o teste verificar
+// Transações exigem replica set: use WithMongoReplicaSet() ou MONGO_REPLICA_SET=true
+func (s *IntegrationTestSuite) MongoTransaction(fn func(ctx context.Context) error) error {
+	s.t.Helper()
+	defer s.trackOperation("MongoTransaction")()
+
+	db := s.Mongo()
+	require.NotNil(s.t, db, "MongoDB not configured")
+
+	session, err := db.Client().StartSession()
+	require.NoError(s.t, err, "Failed to start MongoDB session")
+	defer session.EndSession(s.ctx)
+
+	_, err = session.WithTransaction(s.ctx, func(ctx context.Context) (interface{}, error) {
+		return nil, fn(ctx)
+	})
+
+	// Em um servidor standalone a transação falha no primeiro comando, com uma mensagem pouco clara
+	var cmdErr mongo.CommandError
+	if err != nil && errors.As(err, &cmdErr) && strings.Contains(cmdErr.Message, "replica set") {
+		require.Fail(s.t, "MongoDB transactions require a replica set, use WithMongoReplicaSet()", cmdErr.Message)
+	}
+	return err
+}
@@ -0,0 +1,111 @@
+package testhelper
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// MQTTSubscription é uma assinatura MQTT aberta para o teste; as mensagens são acumuladas desde a assinatura
+type MQTTSubscription struct {
+	suite    *IntegrationTestSuite
+	filter   string
+	mu       sync.Mutex
+	received []MQTTMessage
+	done     chan struct{}
+}
+
+// MQTTTopic retorna o tópico com escopo no tenant da suite ("<tenant>/<tópico>"), evitando que testes
+// paralelos recebam as mensagens uns dos outros
+func (s *IntegrationTestSuite) MQTTTopic(topic string) string {
+	return s.tenantID + "/" + topic
+}
+
+// MQTTPublish publica a mensagem com QoS 1 (aguarda a confirmação do broker)
+func (s *IntegrationTestSuite) MQTTPublish(topic string, payload []byte) {
+	s.t.Helper()
+	defer s.trackOperation("MQTTPublish")()
+
+	client := s.mqttClient("pub")
+	defer client.Close()
+
+	err := client.Publish(s.ctx, topic, payload, 1, false)
+	require.NoError(s.t, err, "Failed to publish MQTT message to %s", topic)
+}
+
+// MQTTSubscribe assina o filtro de tópico (aceita + e #) e retorna a assinatura; a conexão é fechada ao
+// final do teste. Mensagens publicadas depois do retorno são recebidas
+func (s *IntegrationTestSuite) MQTTSubscribe(filter string) *MQTTSubscription {
+	s.t.Helper()
+	defer s.trackOperation("MQTTSubscribe")()
+
+	client := s.mqttClient("sub")
+	err := client.Subscribe(s.ctx, filter, 1)
+	if err != nil {
+		client.Close()
+	}
+	require.NoError(s.t, err, "Failed to subscribe to %s", filter)
+
+	sub := &MQTTSubscription{suite: s, filter: filter, done: make(chan struct{})}
+	go func() {
+		defer close(sub.done)
+		for message := range client.Messages() {
+			sub.mu.Lock()
+			sub.received = append(sub.received, message)
+			sub.mu.Unlock()
+		}
+	}()
+
+	s.t.Cleanup(func() {
+		client.Close()
+		<-sub.done
+	})
+
+	return sub
+}
+
+// mqttClient conecta ao broker com um client ID único do tenant
+func (s *IntegrationTestSuite) mqttClient(role string) *MQTTClient {
+	s.t.Helper()
+
+	mqtt := s.MQTT()
+	require.NotNil(s.t, mqtt, "MQTT not configured, use WithMQTT()")
+
+	clientID := fmt.Sprintf("%s-%s-%d", s.tenantID, role, time.Now().UnixNano())
+	if len(clientID) > 23 {
+		// Clientes MQTT 3.1.1 devem aceitar IDs de até 23 caracteres; o Mosquitto aceita mais, mas
+		// brokers externos podem recusar
+		clientID = clientID[len(clientID)-23:]
+	}
+
+	client, err := mqtt.Connect(s.ctx, clientID)
+	require.NoError(s.t, err, "Failed to connect to MQTT broker")
+	return client
+}
+
+// Messages retorna as mensagens recebidas até agora
+func (m *MQTTSubscription) Messages() []MQTTMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]MQTTMessage(nil), m.received...)
+}
+
+// WaitForMessages aguarda pelo menos min mensagens e retorna as mensagens recebidas
+func (m *MQTTSubscription) WaitForMessages(min int, timeout time.Duration) []MQTTMessage {
+	m.suite.t.Helper()
+
+	deadline := time.Now().Add(timeout)
+	for {
+		messages := m.Messages()
+		if len(messages) >= min {
+			return messages
+		}
+		if time.Now().After(deadline) {
+			require.Fail(m.suite.t, fmt.Sprintf("Received %d of %d MQTT messages on %s within %s", len(messages), min, m.filter, timeout))
+			return messages
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
@@ -0,0 +1,358 @@
+package testhelper
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// Cliente MQTT 3.1.1 mínimo (QoS 0/1, sem sessão persistente) usado pelos helpers de publish/subscribe
+// Não depende de bibliotecas externas; cobre apenas o necessário para os testes
+
+// Tipos de pacote MQTT (4 bits mais significativos do primeiro byte)
+const (
+	mqttConnect     = 1
+	mqttConnAck     = 2
+	mqttPublish     = 3
+	mqttPubAck      = 4
+	mqttSubscribe   = 8
+	mqttSubAck      = 9
+	mqttPingReq     = 12
+	mqttPingResp    = 13
+	mqttDisconnect  = 14
+	mqttKeepAlive   = 30 * time.Second
+	mqttMaxBuffered = 10000
+)
+
+// MQTTMessage é uma mensagem recebida em uma assinatura
+type MQTTMessage struct {
+	Topic    string
+	Payload  []byte
+	QoS      byte
+	Retained bool
+}
+
+// MQTTClient é uma conexão MQTT com o broker
+type MQTTClient struct {
+	conn     net.Conn
+	writeMu  sync.Mutex
+	mu       sync.Mutex
+	nextID   uint16
+	pending  map[uint16]chan byte
+	messages chan MQTTMessage
+	done     chan struct{}
+	err      error
+}
+
+// DialMQTT conecta ao broker (host:porta) com clean session e o clientID informado
+func DialMQTT(ctx context.Context, addr, clientID string) (*MQTTClient, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial mqtt broker %s: %w", addr, err)
+	}
+
+	// CONNECT: protocolo "MQTT" nível 4, clean session, keep alive
+	var body []byte
+	body = appendMQTTString(body, "MQTT")
+	body = append(body, 4, 0x02)
+	body = binary.BigEndian.AppendUint16(body, uint16(mqttKeepAlive/time.Second))
+	body = appendMQTTString(body, clientID)
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else {
+		conn.SetDeadline(time.Now().Add(10 * time.Second))
+	}
+
+	if _, err := conn.Write(encodeMQTTPacket(mqttConnect<<4, body)); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to send mqtt connect: %w", err)
+	}
+
+	reader := bufio.NewReader(conn)
+	header, payload, err := readMQTTPacket(reader)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to read mqtt connack: %w", err)
+	}
+	if header>>4 != mqttConnAck || len(payload) < 2 {
+		conn.Close()
+		return nil, fmt.Errorf("unexpected mqtt packet %d waiting for connack", header>>4)
+	}
+	if payload[1] != 0 {
+		conn.Close()
+		return nil, fmt.Errorf("mqtt connection refused: return code %d", payload[1])
+	}
+	conn.SetDeadline(time.Time{})
+
+	c := &MQTTClient{
+		conn:     conn,
+		pending:  make(map[uint16]chan byte),
+		messages: make(chan MQTTMessage, mqttMaxBuffered),
+		done:     make(chan struct{}),
+	}
+	go c.readLoop(reader)
+	go c.keepAlive()
+	return c, nil
+}
+
+// Messages retorna o canal das mensagens recebidas; é fechado quando a conexão termina
+func (c *MQTTClient) Messages() <-chan MQTTMessage {
+	return c.messages
+}
+
+// Publish publica a mensagem; com QoS 1 aguarda o PUBACK do broker
+func (c *MQTTClient) Publish(ctx context.Context, topic string, payload []byte, qos byte, retain bool) error {
+	if qos > 1 {
+		return fmt.Errorf("mqtt qos %d not supported", qos)
+	}
+
+	header := byte(mqttPublish<<4) | qos<<1
+	if retain {
+		header |= 0x01
+	}
+
+	body := appendMQTTString(nil, topic)
+	var ack chan byte
+	if qos == 1 {
+		var id uint16
+		id, ack = c.register()
+		body = binary.BigEndian.AppendUint16(body, id)
+	}
+	body = append(body, payload...)
+
+	if err := c.write(encodeMQTTPacket(header, body)); err != nil {
+		return fmt.Errorf("failed to publish to %s: %w", topic, err)
+	}
+	if ack == nil {
+		return nil
+	}
+
+	if _, err := c.wait(ctx, ack); err != nil {
+		return fmt.Errorf("failed to publish to %s: %w", topic, err)
+	}
+	return nil
+}
+
+// Subscribe assina o filtro de tópico (aceita + e #) e aguarda o SUBACK
+func (c *MQTTClient) Subscribe(ctx context.Context, filter string, qos byte) error {
+	id, ack := c.register()
+
+	body := binary.BigEndian.AppendUint16(nil, id)
+	body = appendMQTTString(body, filter)
+	body = append(body, qos)
+
+	// SUBSCRIBE usa os flags reservados 0010
+	if err := c.write(encodeMQTTPacket(mqttSubscribe<<4|0x02, body)); err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", filter, err)
+	}
+
+	granted, err := c.wait(ctx, ack)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", filter, err)
+	}
+	if granted == 0x80 {
+		return fmt.Errorf("mqtt subscription to %s refused by broker", filter)
+	}
+	return nil
+}
+
+// Close envia DISCONNECT e encerra a conexão
+func (c *MQTTClient) Close() error {
+	c.write(encodeMQTTPacket(mqttDisconnect<<4, nil))
+	err := c.conn.Close()
+	<-c.done
+	return err
+}
+
+// register reserva um packet ID e o canal que recebe a confirmação
+func (c *MQTTClient) register() (uint16, chan byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.nextID++
+	if c.nextID == 0 {
+		c.nextID = 1
+	}
+	ack := make(chan byte, 1)
+	c.pending[c.nextID] = ack
+	return c.nextID, ack
+}
+
+// wait aguarda a confirmação (PUBACK/SUBACK), o fim da conexão ou o contexto
+func (c *MQTTClient) wait(ctx context.Context, ack chan byte) (byte, error) {
+	select {
+	case code := <-ack:
+		return code, nil
+	case <-c.done:
+		if c.err != nil {
+			return 0, c.err
+		}
+		return 0, errors.New("mqtt connection closed")
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+// write envia um pacote (escritas concorrentes são serializadas)
+func (c *MQTTClient) write(packet []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_, err := c.conn.Write(packet)
+	return err
+}
+
+// readLoop processa os pacotes recebidos até a conexão terminar
+func (c *MQTTClient) readLoop(reader *bufio.Reader) {
+	defer close(c.done)
+	defer close(c.messages)
+
+	for {
+		header, payload, err := readMQTTPacket(reader)
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				c.err = err
+			}
+			return
+		}
+
+		switch header >> 4 {
+		case mqttPublish:
+			c.handlePublish(header, payload)
+		case mqttPubAck:
+			if len(payload) >= 2 {
+				c.ack(binary.BigEndian.Uint16(payload), 0)
+			}
+		case mqttSubAck:
+			if len(payload) >= 3 {
+				c.ack(binary.BigEndian.Uint16(payload), payload[2])
+			}
+		case mqttPingResp:
+		}
+	}
+}
+
+// handlePublish entrega a mensagem recebida e confirma QoS 1
+func (c *MQTTClient) handlePublish(header byte, payload []byte) {
+	qos := (header >> 1) & 0x03
+	topic, rest, ok := readMQTTString(payload)
+	if !ok {
+		return
+	}
+
+	if qos > 0 {
+		if len(rest) < 2 {
+			return
+		}
+		id := rest[:2]
+		rest = rest[2:]
+		c.write(encodeMQTTPacket(mqttPubAck<<4, id))
+	}
+
+	message := MQTTMessage{Topic: topic, Payload: append([]byte(nil), rest...), QoS: qos, Retained: header&0x01 == 1}
+	select {
+	case c.messages <- message:
+	default:
+		// Buffer cheio: a mensagem é descartada para não bloquear as confirmações
+	}
+}
+
+// ack entrega a confirmação ao Publish/Subscribe que aguarda o packet ID
+func (c *MQTTClient) ack(id uint16, code byte) {
+	c.mu.Lock()
+	ack, ok := c.pending[id]
+	delete(c.pending, id)
+	c.mu.Unlock()
+
+	if ok {
+		ack <- code
+	}
+}
+
+// keepAlive envia PINGREQ periodicamente enquanto a conexão estiver aberta
+func (c *MQTTClient) keepAlive() {
+	ticker := time.NewTicker(mqttKeepAlive / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			c.write(encodeMQTTPacket(mqttPingReq<<4, nil))
+		}
+	}
+}
+
+// encodeMQTTPacket monta o pacote: header fixo, remaining length (varint) e corpo
+func encodeMQTTPacket(header byte, body []byte) []byte {
+	packet := []byte{header}
+	length := len(body)
+	for {
+		digit := byte(length % 128)
+		length /= 128
+		if length > 0 {
+			digit |= 0x80
+		}
+		packet = append(packet, digit)
+		if length == 0 {
+			break
+		}
+	}
+	return append(packet, body...)
+}
+
+// readMQTTPacket lê um pacote e retorna o header fixo e o corpo
+func readMQTTPacket(reader *bufio.Reader) (byte, []byte, error) {
+	header, err := reader.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		if i == 4 {
+			return 0, nil, errors.New("malformed mqtt remaining length")
+		}
+		digit, err := reader.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length += int(digit&0x7f) * multiplier
+		multiplier *= 128
+		if digit&0x80 == 0 {
+			break
+		}
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(reader, body); err != nil {
+		return 0, nil, err
+	}
+	return header, body, nil
+}
+
+// appendMQTTString acrescenta uma string UTF-8 prefixada pelo tamanho (2 bytes)
+func appendMQTTString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+// readMQTTString lê uma string prefixada pelo tamanho e retorna o restante
+func readMQTTString(b []byte) (string, []byte, bool) {
+	if len(b) < 2 {
+		return "", nil, false
+	}
+	n := int(binary.BigEndian.Uint16(b))
+	if len(b) < 2+n {
+		return "", nil, false
+	}
+	return string(b[2 : 2+n]), b[2+n:], true
+}
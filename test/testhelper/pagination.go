@@ -0,0 +1,264 @@
+package testhelper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"github.com/stretchr/testify/require"
+	"github.com/viniciussantos/claude-testcontainers/internal/cursor"
+)
+
+// maxCursorPages limita o FollowCursor, evitando loop infinito se os cursores nunca terminarem
+const maxCursorPages = 10000
+
+// Paginação do SearchAllDocuments: tamanho de cada página e keep_alive do point-in-time
+const (
+	searchAllPageSize  = 1000
+	searchAllKeepAlive = "1m"
+)
+
+// SearchPage busca uma página de até size hits após o cursor (vazio = primeira página) e retorna o
+// cursor da próxima página, vazio quando esta é a última
+// A query deve ter "sort" terminando em um campo único (ex.: id), como exige o search_after
+func (s *IntegrationTestSuite) SearchPage(index string, query map[string]interface{}, size int, after string) (*SearchResult, string) {
+	s.t.Helper()
+
+	_, sorted := query["sort"]
+	require.True(s.t, sorted, "Cursor pagination requires a sort with a unique tiebreaker")
+
+	body := make(map[string]interface{}, len(query)+2)
+	for key, value := range query {
+		body[key] = value
+	}
+	body["size"] = size
+
+	if after != "" {
+		values, err := cursor.Decode(after)
+		require.NoError(s.t, err, "Failed to decode cursor")
+		body["search_after"] = values
+	}
+
+	raw := s.searchRaw(index, body)
+
+	var response map[string]interface{}
+	require.NoError(s.t, json.Unmarshal(raw, &response), "Failed to decode search response")
+	result := &SearchResult{response: response}
+
+	hits := result.hits()
+	if len(hits) < size {
+		return result, ""
+	}
+
+	// Os valores de sort do cursor vêm de uma decodificação com UseNumber: em float64 longs e datas
+	// grandes (epoch em ms, _shard_doc) perderiam precisão e o search_after pularia ou repetiria hits
+	sortValues, err := lastHitSortValues(raw)
+	require.NoError(s.t, err, "Failed to decode sort values")
+	next, err := cursor.Encode(sortValues)
+	require.NoError(s.t, err, "Failed to encode cursor")
+	return result, next
+}
+
+// lastHitSortValues retorna os valores de sort do último hit da resposta, com números em json.Number
+func lastHitSortValues(raw []byte) ([]interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	var response struct {
+		Hits struct {
+			Hits []struct {
+				Sort []interface{} `json:"sort"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := decoder.Decode(&response); err != nil {
+		return nil, err
+	}
+
+	hits := response.Hits.Hits
+	if len(hits) == 0 {
+		return nil, nil
+	}
+	return hits[len(hits)-1].Sort, nil
+}
+
+// FollowCursor percorre todas as páginas seguindo os cursores e retorna os _id na ordem vista
+// onPage (opcional) é chamado após cada página, com o número da página (a partir de 1) e o próximo cursor
+func (s *IntegrationTestSuite) FollowCursor(index string, query map[string]interface{}, size int, onPage func(page int, next string)) []string {
+	s.t.Helper()
+
+	ids := []string{}
+	after := ""
+	for page := 1; page <= maxCursorPages; page++ {
+		result, next := s.SearchPage(index, query, size, after)
+		ids = append(ids, result.IDs()...)
+
+		if onPage != nil {
+			onPage(page, next)
+		}
+		if next == "" {
+			return ids
+		}
+		after = next
+	}
+
+	require.Fail(s.t, "Cursor pagination did not finish", "more than %d pages", maxCursorPages)
+	return ids
+}
+
+// AssertStablePagination percorre as páginas enquanto write (escritas concorrentes simuladas) roda entre
+// elas, e verifica que nenhum documento se repete e que todos os que existiam antes da primeira página
+// foram vistos. Escritas que alteram os campos do sort podem mover documentos e não são cobertas
+func (s *IntegrationTestSuite) AssertStablePagination(index string, query map[string]interface{}, size int, write func(page int)) []string {
+	s.t.Helper()
+
+	index = s.RunScopedIndex(index)
+	s.refreshIndex(index)
+	baseline := s.FollowCursor(index, query, size, nil)
+
+	seen := s.FollowCursor(index, query, size, func(page int, next string) {
+		if write != nil && next != "" {
+			write(page)
+			s.refreshIndex(index)
+		}
+	})
+
+	counts := make(map[string]int, len(seen))
+	for _, id := range seen {
+		counts[id]++
+	}
+	for id, count := range counts {
+		require.Equal(s.t, 1, count, "Document %s returned in more than one page", id)
+	}
+	for _, id := range baseline {
+		require.Contains(s.t, counts, id, "Document %s existing before pagination was skipped", id)
+	}
+	return seen
+}
+
+// SearchAllDocuments executa a busca retornando todos os hits, paginando com point-in-time + search_after
+// em vez de parar nos 10.000 do index.max_result_window. size/from da query são ignorados; sem "sort",
+// os hits vêm na ordem de _shard_doc. Aggregations são as da primeira página (calculadas sobre todos os hits)
+func (s *IntegrationTestSuite) SearchAllDocuments(index string, query map[string]interface{}) *SearchResult {
+	s.t.Helper()
+	defer s.trackOperation("SearchAllDocuments")()
+
+	client := s.ES()
+	require.NotNil(s.t, client, "Elasticsearch not configured")
+	index = s.RunScopedIndex(index)
+
+	pitID := s.openPointInTime(index)
+	defer func() {
+		s.closePointInTime(pitID)
+	}()
+
+	body := make(map[string]interface{}, len(query)+4)
+	for key, value := range query {
+		body[key] = value
+	}
+	delete(body, "from")
+	body["size"] = searchAllPageSize
+	if _, sorted := body["sort"]; !sorted {
+		body["sort"] = []interface{}{"_shard_doc"}
+	}
+
+	var (
+		first   map[string]interface{}
+		allHits []interface{}
+	)
+	for page := 1; ; page++ {
+		require.LessOrEqual(s.t, page, maxCursorPages, "Search on %s did not finish within %d pages", index, maxCursorPages)
+
+		body["pit"] = map[string]interface{}{"id": pitID, "keep_alive": searchAllKeepAlive}
+		response := s.searchPointInTime(body)
+		if first == nil {
+			first = response
+			// Total e aggregations já vieram na primeira página
+			body["track_total_hits"] = false
+			delete(body, "aggs")
+			delete(body, "aggregations")
+		}
+		if id, ok := response["pit_id"].(string); ok && id != "" {
+			pitID = id
+		}
+
+		hits, _ := response["hits"].(map[string]interface{})
+		pageHits, _ := hits["hits"].([]interface{})
+		allHits = append(allHits, pageHits...)
+		if len(pageHits) < searchAllPageSize {
+			break
+		}
+
+		last, _ := pageHits[len(pageHits)-1].(map[string]interface{})
+		body["search_after"] = last["sort"]
+	}
+
+	delete(first, "pit_id")
+	hits, _ := first["hits"].(map[string]interface{})
+	if hits == nil {
+		hits = map[string]interface{}{}
+		first["hits"] = hits
+	}
+	hits["hits"] = allHits
+	hits["total"] = map[string]interface{}{"value": float64(len(allHits)), "relation": "eq"}
+
+	return &SearchResult{response: first}
+}
+
+// openPointInTime abre um point-in-time no índice e retorna o id
+func (s *IntegrationTestSuite) openPointInTime(index string) string {
+	s.t.Helper()
+
+	client := s.ES()
+	res, err := client.OpenPointInTime([]string{index}, searchAllKeepAlive, client.OpenPointInTime.WithContext(s.ctx))
+	require.NoError(s.t, err, "Failed to open point in time on %s", index)
+	defer res.Body.Close()
+
+	if res.IsError() {
+		require.Fail(s.t, fmt.Sprintf("Failed to open point in time on %s: %s", index, res.String()))
+	}
+
+	var body struct {
+		ID string `json:"id"`
+	}
+	require.NoError(s.t, json.NewDecoder(res.Body).Decode(&body), "Failed to decode point in time response")
+	return body.ID
+}
+
+// closePointInTime libera o point-in-time (falhas são ignoradas: ele expira sozinho após o keep_alive)
+func (s *IntegrationTestSuite) closePointInTime(id string) {
+	body, err := json.Marshal(map[string]interface{}{"id": id})
+	if err != nil {
+		return
+	}
+
+	client := s.ES()
+	res, err := client.ClosePointInTime(
+		client.ClosePointInTime.WithContext(s.ctx),
+		client.ClosePointInTime.WithBody(strings.NewReader(string(body))))
+	if err == nil {
+		res.Body.Close()
+	}
+}
+
+// searchPointInTime executa uma página da busca com PIT (sem índice na URL, como exige o ES)
+func (s *IntegrationTestSuite) searchPointInTime(body map[string]interface{}) map[string]interface{} {
+	s.t.Helper()
+
+	bodyJSON, err := json.Marshal(body)
+	require.NoError(s.t, err, "Failed to marshal query")
+
+	res, err := esapi.SearchRequest{Body: strings.NewReader(string(bodyJSON))}.Do(s.ctx, s.ES())
+	require.NoError(s.t, err, "Failed to execute search")
+	defer res.Body.Close()
+
+	if res.IsError() {
+		require.Fail(s.t, fmt.Sprintf("Failed to search: %s", res.String()))
+	}
+
+	var response map[string]interface{}
+	require.NoError(s.t, json.NewDecoder(res.Body).Decode(&response), "Failed to decode search response")
+	return response
+}
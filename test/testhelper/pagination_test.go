@@ -0,0 +1,56 @@
+package testhelper
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLastHitSortValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		expected []interface{}
+		wantErr  bool
+	}{
+		{
+			name:     "No hits",
+			raw:      `{"hits":{"hits":[]}}`,
+			expected: nil,
+		},
+		{
+			name:     "Hits without sort",
+			raw:      `{"hits":{"hits":[{"_id":"1"}]}}`,
+			expected: nil,
+		},
+		{
+			name:     "Sort values of the last hit",
+			raw:      `{"hits":{"hits":[{"sort":[1,"a"]},{"sort":[2,"b"]}]}}`,
+			expected: []interface{}{json.Number("2"), "b"},
+		},
+		{
+			name:     "Large numbers keep their precision",
+			raw:      `{"hits":{"hits":[{"sort":[9007199254740993,1700000000123]}]}}`,
+			expected: []interface{}{json.Number("9007199254740993"), json.Number("1700000000123")},
+		},
+		{
+			name:    "Invalid response",
+			raw:     `{"hits":`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := lastHitSortValues([]byte(tt.raw))
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, values)
+		})
+	}
+}
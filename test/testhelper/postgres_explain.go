@@ -0,0 +1,160 @@
+package testhelper
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/stretchr/testify/require"
+)
+
+// PostgresPlanNode é um nó do plano retornado por EXPLAIN (FORMAT JSON)
+type PostgresPlanNode struct {
+	NodeType     string             `json:"Node Type"`
+	RelationName string             `json:"Relation Name"`
+	IndexName    string             `json:"Index Name"`
+	TotalCost    float64            `json:"Total Cost"`
+	PlanRows     float64            `json:"Plan Rows"`
+	Plans        []PostgresPlanNode `json:"Plans"`
+}
+
+// Indexes retorna os índices usados no plano (Index Scan, Index Only Scan e Bitmap Index Scan)
+func (n PostgresPlanNode) Indexes() []string {
+	var indexes []string
+	n.walk(func(node PostgresPlanNode) {
+		if node.IndexName != "" {
+			indexes = append(indexes, node.IndexName)
+		}
+	})
+	return indexes
+}
+
+// SeqScans retorna as tabelas lidas com Seq Scan no plano
+func (n PostgresPlanNode) SeqScans() []string {
+	var tables []string
+	n.walk(func(node PostgresPlanNode) {
+		if node.NodeType == "Seq Scan" {
+			tables = append(tables, node.RelationName)
+		}
+	})
+	return tables
+}
+
+// String resume o plano em uma linha por nó, indentada pela profundidade
+func (n PostgresPlanNode) String() string {
+	var b strings.Builder
+	n.format(&b, 0)
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// walk visita o nó e seus filhos em profundidade
+func (n PostgresPlanNode) walk(visit func(PostgresPlanNode)) {
+	visit(n)
+	for _, child := range n.Plans {
+		child.walk(visit)
+	}
+}
+
+// format escreve o nó e os filhos com indentação
+func (n PostgresPlanNode) format(b *strings.Builder, depth int) {
+	b.WriteString(strings.Repeat("  ", depth))
+	b.WriteString(n.NodeType)
+	if n.IndexName != "" {
+		fmt.Fprintf(b, " using %s", n.IndexName)
+	}
+	if n.RelationName != "" {
+		fmt.Fprintf(b, " on %s", n.RelationName)
+	}
+	fmt.Fprintf(b, " (cost=%.2f rows=%.0f)\n", n.TotalCost, n.PlanRows)
+	for _, child := range n.Plans {
+		child.format(b, depth+1)
+	}
+}
+
+// explainPostgresQuery executa EXPLAIN (FORMAT JSON) em uma transação descartada
+// Com disableSeqScan=true, aplica SET LOCAL enable_seqscan = off: com poucas linhas o planner prefere
+// Seq Scan mesmo com índice aplicável, e o que se quer verificar é se o índice pode ser usado
+func explainPostgresQuery(ctx context.Context, db *sql.DB, query string, args []interface{}, disableSeqScan bool) (PostgresPlanNode, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return PostgresPlanNode{}, fmt.Errorf("failed to begin explain transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if disableSeqScan {
+		if _, err := tx.ExecContext(ctx, "SET LOCAL enable_seqscan = off"); err != nil {
+			return PostgresPlanNode{}, fmt.Errorf("failed to disable seqscan: %w", err)
+		}
+	}
+
+	var raw string
+	if err := tx.QueryRowContext(ctx, "EXPLAIN (FORMAT JSON) "+query, args...).Scan(&raw); err != nil {
+		return PostgresPlanNode{}, fmt.Errorf("failed to explain query: %w", err)
+	}
+
+	var plans []struct {
+		Plan PostgresPlanNode `json:"Plan"`
+	}
+	if err := json.Unmarshal([]byte(raw), &plans); err != nil {
+		return PostgresPlanNode{}, fmt.Errorf("failed to decode query plan: %w", err)
+	}
+	if len(plans) == 0 {
+		return PostgresPlanNode{}, fmt.Errorf("empty query plan")
+	}
+	return plans[0].Plan, nil
+}
+
+// ExplainQuery retorna o plano da consulta no PostgreSQL com as configurações atuais do planner
+func (s *IntegrationTestSuite) ExplainQuery(query string, args ...interface{}) PostgresPlanNode {
+	s.t.Helper()
+	defer s.trackOperation("ExplainQuery")()
+
+	db := s.Postgres()
+	require.NotNil(s.t, db, "PostgreSQL not configured, use WithPostgres()")
+
+	plan, err := explainPostgresQuery(s.ctx, db, query, args, false)
+	require.NoError(s.t, err, "Failed to explain query")
+	return plan
+}
+
+// AssertUsesIndex verifica que o plano da consulta usa o índice informado
+// O Seq Scan é desabilitado durante o EXPLAIN (as tabelas dos testes têm poucas linhas); se o índice não
+// aparece mesmo assim, a consulta não consegue usá-lo (ex.: função sobre a coluna, tipo divergente)
+func (s *IntegrationTestSuite) AssertUsesIndex(query string, args []interface{}, indexName string) {
+	s.t.Helper()
+	defer s.trackOperation("AssertUsesIndex")()
+
+	db := s.Postgres()
+	require.NotNil(s.t, db, "PostgreSQL not configured, use WithPostgres()")
+
+	plan, err := explainPostgresQuery(s.ctx, db, query, args, true)
+	require.NoError(s.t, err, "Failed to explain query")
+
+	for _, index := range plan.Indexes() {
+		if index == indexName {
+			return
+		}
+	}
+	require.Fail(s.t, fmt.Sprintf("Query does not use index %s", indexName), "Plan:\n%s", plan)
+}
+
+// AssertNoSeqScan verifica que o plano da consulta não faz Seq Scan na tabela (vazio verifica todas)
+// Assim como AssertUsesIndex, o EXPLAIN roda com enable_seqscan = off: um Seq Scan restante é inevitável
+func (s *IntegrationTestSuite) AssertNoSeqScan(query string, args []interface{}, table string) {
+	s.t.Helper()
+	defer s.trackOperation("AssertNoSeqScan")()
+
+	db := s.Postgres()
+	require.NotNil(s.t, db, "PostgreSQL not configured, use WithPostgres()")
+
+	plan, err := explainPostgresQuery(s.ctx, db, query, args, true)
+	require.NoError(s.t, err, "Failed to explain query")
+
+	for _, scanned := range plan.SeqScans() {
+		if table == "" || scanned == table {
+			require.Fail(s.t, fmt.Sprintf("Query scans %s sequentially", scanned), "Plan:\n%s", plan)
+		}
+	}
+}
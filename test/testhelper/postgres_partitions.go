@@ -0,0 +1,173 @@
+package testhelper
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/stretchr/testify/require"
+)
+
+// PostgresPartition descreve uma partição de uma tabela particionada
+type PostgresPartition struct {
+	Name      string
+	Bound     string // ex.: FOR VALUES FROM ('2024-01-01') TO ('2024-02-01') ou DEFAULT
+	IsDefault bool
+}
+
+// queryer é satisfeito por *sql.DB e *sql.Conn
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// truncatableTables lista as tabelas do schema public que devem ser truncadas:
+// tabelas comuns e pais particionados, sem as partições (limpas pelo TRUNCATE do pai) e sem as de baseline
+func truncatableTables(ctx context.Context, db queryer) ([]string, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT c.relname
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = 'public'
+		  AND c.relkind IN ('r', 'p')
+		  AND NOT c.relispartition
+		ORDER BY c.relname
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get table list: %w", err)
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return nil, fmt.Errorf("failed to scan table name: %w", err)
+		}
+		if isBaselineTable(table) {
+			continue
+		}
+		tables = append(tables, table)
+	}
+	return tables, rows.Err()
+}
+
+// reattachDefaultPartitions anexa como DEFAULT as tabelas "<pai>_default" desanexadas
+// de pais particionados que estão sem partição default
+func reattachDefaultPartitions(ctx context.Context, db queryer) error {
+	rows, err := db.QueryContext(ctx, `
+		SELECT parent.relname, d.relname
+		FROM pg_partitioned_table pt
+		JOIN pg_class parent ON parent.oid = pt.partrelid
+		JOIN pg_namespace n ON n.oid = parent.relnamespace
+		JOIN pg_class d ON d.relname = parent.relname || '_default'
+		               AND d.relnamespace = parent.relnamespace
+		               AND d.relkind = 'r'
+		               AND NOT d.relispartition
+		WHERE n.nspname = 'public'
+		  AND pt.partdefid = 0
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to find detached default partitions: %w", err)
+	}
+
+	type detached struct{ parent, partition string }
+	var pending []detached
+	for rows.Next() {
+		var d detached
+		if err := rows.Scan(&d.parent, &d.partition); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan default partition: %w", err)
+		}
+		pending = append(pending, d)
+	}
+	rows.Close()
+
+	for _, d := range pending {
+		if _, err := db.ExecContext(ctx, fmt.Sprintf("ALTER TABLE \"%s\" ATTACH PARTITION \"%s\" DEFAULT", d.parent, d.partition)); err != nil {
+			return fmt.Errorf("failed to reattach default partition %s: %w", d.partition, err)
+		}
+		if isDebugEnabled() {
+			fmt.Printf("🔌 Reattached default partition %s to %s\n", d.partition, d.parent)
+		}
+	}
+	return nil
+}
+
+// postgresPartitions lista as partições diretas da tabela (schema public)
+func postgresPartitions(ctx context.Context, db queryer, table string) ([]PostgresPartition, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT child.relname, pg_get_expr(child.relpartbound, child.oid)
+		FROM pg_inherits i
+		JOIN pg_class parent ON parent.oid = i.inhparent
+		JOIN pg_class child ON child.oid = i.inhrelid
+		JOIN pg_namespace n ON n.oid = parent.relnamespace
+		WHERE n.nspname = 'public' AND parent.relname = $1
+		ORDER BY child.relname
+	`, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list partitions of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var partitions []PostgresPartition
+	for rows.Next() {
+		var p PostgresPartition
+		if err := rows.Scan(&p.Name, &p.Bound); err != nil {
+			return nil, fmt.Errorf("failed to scan partition: %w", err)
+		}
+		p.IsDefault = p.Bound == "DEFAULT"
+		partitions = append(partitions, p)
+	}
+	return partitions, rows.Err()
+}
+
+// PostgresPartitions retorna as partições da tabela particionada
+func (s *IntegrationTestSuite) PostgresPartitions(table string) []PostgresPartition {
+	s.t.Helper()
+
+	db := s.Postgres()
+	require.NotNil(s.t, db, "PostgreSQL not configured, use WithPostgres()")
+
+	partitions, err := postgresPartitions(s.ctx, db, table)
+	require.NoError(s.t, err)
+	return partitions
+}
+
+// AssertPartitionExists verifica se a partição está anexada à tabela
+func (s *IntegrationTestSuite) AssertPartitionExists(table, partition string) {
+	s.t.Helper()
+
+	partitions := s.PostgresPartitions(table)
+	names := make([]string, len(partitions))
+	for i, p := range partitions {
+		if p.Name == partition {
+			return
+		}
+		names[i] = p.Name
+	}
+	require.Fail(s.t, fmt.Sprintf("Partition %s not attached to %s", partition, table), "Attached partitions: %v", names)
+}
+
+// AssertPartitionNotExists verifica que a partição não está anexada à tabela
+func (s *IntegrationTestSuite) AssertPartitionNotExists(table, partition string) {
+	s.t.Helper()
+
+	for _, p := range s.PostgresPartitions(table) {
+		if p.Name == partition {
+			require.Fail(s.t, fmt.Sprintf("Partition %s unexpectedly attached to %s (%s)", partition, table, p.Bound))
+		}
+	}
+}
+
+// AssertDefaultPartition verifica se a tabela tem uma partição DEFAULT anexada
+func (s *IntegrationTestSuite) AssertDefaultPartition(table string) {
+	s.t.Helper()
+
+	for _, p := range s.PostgresPartitions(table) {
+		if p.IsDefault {
+			return
+		}
+	}
+	require.Fail(s.t, fmt.Sprintf("Table %s has no DEFAULT partition attached", table))
+}
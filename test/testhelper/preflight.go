@@ -0,0 +1,331 @@
+package testhelper
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// Preflight do ambiente
+//
+// Verifica, antes de subir containers, as condições do host que costumam derrubar o Elasticsearch no
+// meio da suite: Docker acessível e recente, memória disponível para o daemon, vm.max_map_count e
+// espaço em disco (acima do high watermark o ES deixa de alocar shards e no flood stage os índices
+// ficam somente leitura). TEST_PREFLIGHT=off desliga as verificações e TEST_PREFLIGHT=warn só as loga
+
+// Limites usados pelo preflight
+const (
+	preflightMinDockerVersion = "20.10"
+	preflightMinMaxMapCount   = 262144
+	preflightMaxDiskUsage     = 90.0
+	preflightDefaultMemoryMB  = 2048
+)
+
+// PreflightStatus é o resultado de uma verificação do preflight
+type PreflightStatus string
+
+const (
+	// PreflightOK indica que a verificação passou
+	PreflightOK PreflightStatus = "ok"
+	// PreflightUnknown indica que a verificação não pôde ser feita (ex.: daemon remoto); não bloqueia
+	PreflightUnknown PreflightStatus = "unknown"
+	// PreflightSkip indica um ambiente sem recursos para a suite: os testes são pulados
+	PreflightSkip PreflightStatus = "skip"
+	// PreflightFail indica um ambiente mal configurado que o usuário precisa corrigir: os testes falham
+	PreflightFail PreflightStatus = "fail"
+)
+
+// PreflightCheck é o resultado de uma verificação, com a correção sugerida quando não passou
+type PreflightCheck struct {
+	Name   string
+	Status PreflightStatus
+	Detail string
+	Hint   string
+}
+
+// String formata a verificação em uma linha
+func (c PreflightCheck) String() string {
+	line := fmt.Sprintf("%s: %s (%s)", c.Name, c.Status, c.Detail)
+	if c.Hint != "" && (c.Status == PreflightSkip || c.Status == PreflightFail) {
+		line += " — " + c.Hint
+	}
+	return line
+}
+
+// PreflightReport agrega as verificações do preflight
+type PreflightReport struct {
+	Checks []PreflightCheck
+}
+
+// preflightCache guarda o relatório: as condições do host não mudam durante o processo
+var (
+	preflightMu     sync.Mutex
+	preflightCached *PreflightReport
+)
+
+// Preflight verifica o ambiente (uma vez por processo) e retorna o relatório
+// Use Apply em um teste ou Err no TestMain:
+//
+//	func TestMain(m *testing.M) {
+//		if err := testhelper.Preflight(context.Background()).Err(); err != nil {
+//			log.Fatal(err)
+//		}
+//		os.Exit(m.Run())
+//	}
+func Preflight(ctx context.Context) *PreflightReport {
+	preflightMu.Lock()
+	defer preflightMu.Unlock()
+
+	if preflightCached == nil {
+		preflightCached = runPreflight(ctx)
+		if isDebugEnabled() {
+			for _, check := range preflightCached.Checks {
+				fmt.Printf("🩺 Preflight %s\n", check)
+			}
+		}
+	}
+	return preflightCached
+}
+
+// Apply pula (t.Skip) ou falha o teste conforme o relatório; com TEST_PREFLIGHT=warn só loga
+// TestingT sem Skip (ex.: adapters próprios) tem o teste falhado no lugar do skip
+func (r *PreflightReport) Apply(t TestingT) {
+	t.Helper()
+
+	if mode := preflightMode(); mode == "off" {
+		return
+	} else if mode == "warn" {
+		for _, check := range r.blocking() {
+			t.Logf("⚠️  Preflight %s", check)
+		}
+		return
+	}
+
+	if failed := r.withStatus(PreflightFail); len(failed) > 0 {
+		t.Errorf("Environment preflight failed:\n%s", formatPreflightChecks(failed))
+		t.FailNow()
+	}
+
+	if skipped := r.withStatus(PreflightSkip); len(skipped) > 0 {
+		message := fmt.Sprintf("Skipping: environment cannot run the integration suite:\n%s", formatPreflightChecks(skipped))
+		if skipper, ok := t.(interface{ Skip(args ...interface{}) }); ok {
+			skipper.Skip(message)
+			return
+		}
+		t.Errorf("%s", message)
+		t.FailNow()
+	}
+}
+
+// Err retorna um erro com as verificações que bloqueiam a suite (nil se nenhuma ou TEST_PREFLIGHT=off/warn)
+func (r *PreflightReport) Err() error {
+	if mode := preflightMode(); mode == "off" || mode == "warn" {
+		return nil
+	}
+
+	blocking := r.blocking()
+	if len(blocking) == 0 {
+		return nil
+	}
+	return fmt.Errorf("environment preflight failed:\n%s", formatPreflightChecks(blocking))
+}
+
+// Check retorna a verificação pelo nome (ex.: "docker", "vm.max_map_count")
+func (r *PreflightReport) Check(name string) (PreflightCheck, bool) {
+	for _, check := range r.Checks {
+		if check.Name == name {
+			return check, true
+		}
+	}
+	return PreflightCheck{}, false
+}
+
+// blocking retorna as verificações com status skip ou fail
+func (r *PreflightReport) blocking() []PreflightCheck {
+	return append(r.withStatus(PreflightFail), r.withStatus(PreflightSkip)...)
+}
+
+// withStatus filtra as verificações pelo status
+func (r *PreflightReport) withStatus(status PreflightStatus) []PreflightCheck {
+	var checks []PreflightCheck
+	for _, check := range r.Checks {
+		if check.Status == status {
+			checks = append(checks, check)
+		}
+	}
+	return checks
+}
+
+// formatPreflightChecks formata uma verificação por linha
+func formatPreflightChecks(checks []PreflightCheck) string {
+	lines := make([]string, len(checks))
+	for i, check := range checks {
+		lines[i] = "  - " + check.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// preflightMode lê TEST_PREFLIGHT (off, warn ou vazio para aplicar o relatório)
+func preflightMode() string {
+	return strings.ToLower(strings.TrimSpace(os.Getenv("TEST_PREFLIGHT")))
+}
+
+// runPreflight executa as verificações; sem daemon acessível as demais não são feitas
+func runPreflight(ctx context.Context) *PreflightReport {
+	report := &PreflightReport{}
+
+	client, err := newPreflightDockerClient(ctx)
+	if err != nil {
+		report.Checks = append(report.Checks, PreflightCheck{
+			Name:   "docker",
+			Status: PreflightSkip,
+			Detail: err.Error(),
+			Hint:   "start the Docker daemon or set DOCKER_HOST (USE_EXTERNAL_* avoids containers for that dependency)",
+		})
+		return report
+	}
+	defer client.Close()
+
+	report.Checks = append(report.Checks, checkDockerVersion(ctx, client))
+
+	info, err := client.Info(ctx)
+	if err != nil {
+		report.Checks = append(report.Checks, PreflightCheck{Name: "docker memory", Status: PreflightUnknown, Detail: err.Error()})
+		return report
+	}
+
+	report.Checks = append(report.Checks,
+		checkDockerMemory(info.MemTotal),
+		checkMaxMapCount(info.OperatingSystem),
+		checkDiskSpace(info.DockerRootDir),
+	)
+	return report
+}
+
+// newPreflightDockerClient cria o client do Docker convertendo em erro o panic do testcontainers quando
+// nenhum daemon é encontrado (ex.: "rootless Docker not found")
+func newPreflightDockerClient(ctx context.Context) (client *testcontainers.DockerClient, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("docker not found: %v", r)
+		}
+	}()
+	return testcontainers.NewDockerClientWithOpts(ctx)
+}
+
+// checkDockerVersion verifica se o daemon responde e tem a versão mínima suportada
+func checkDockerVersion(ctx context.Context, client *testcontainers.DockerClient) PreflightCheck {
+	check := PreflightCheck{Name: "docker"}
+
+	version, err := client.ServerVersion(ctx)
+	if err != nil {
+		check.Status = PreflightSkip
+		check.Detail = fmt.Sprintf("daemon unreachable: %v", err)
+		check.Hint = "start the Docker daemon or check DOCKER_HOST and its permissions"
+		return check
+	}
+
+	check.Detail = fmt.Sprintf("server %s, API %s", version.Version, version.APIVersion)
+	ok, err := MatchVersion(version.Version, ">="+preflightMinDockerVersion)
+	switch {
+	case err != nil:
+		check.Status = PreflightUnknown
+	case !ok:
+		check.Status = PreflightFail
+		check.Hint = fmt.Sprintf("upgrade Docker to %s or newer", preflightMinDockerVersion)
+	default:
+		check.Status = PreflightOK
+	}
+	return check
+}
+
+// checkDockerMemory verifica a memória disponível para o daemon (TEST_PREFLIGHT_MIN_MEMORY_MB, padrão 2048)
+func checkDockerMemory(total int64) PreflightCheck {
+	check := PreflightCheck{Name: "docker memory"}
+
+	minMB, err := strconv.ParseInt(envOrDefault("TEST_PREFLIGHT_MIN_MEMORY_MB", strconv.Itoa(preflightDefaultMemoryMB)), 10, 64)
+	if err != nil {
+		minMB = preflightDefaultMemoryMB
+	}
+
+	totalMB := total / (1024 * 1024)
+	check.Detail = fmt.Sprintf("%d MB available, %d MB required", totalMB, minMB)
+	switch {
+	case total <= 0:
+		check.Status = PreflightUnknown
+	case totalMB < minMB:
+		check.Status = PreflightSkip
+		check.Hint = "give the Docker VM more memory (Docker Desktop: Settings > Resources) or lower TEST_PREFLIGHT_MIN_MEMORY_MB"
+	default:
+		check.Status = PreflightOK
+	}
+	return check
+}
+
+// checkMaxMapCount verifica vm.max_map_count, exigido pelo Elasticsearch para mapear os segmentos
+// Só é possível quando o daemon roda no próprio host Linux (no Docker Desktop o valor é o da VM)
+func checkMaxMapCount(operatingSystem string) PreflightCheck {
+	check := PreflightCheck{Name: "vm.max_map_count", Status: PreflightUnknown}
+
+	if externalES() {
+		check.Detail = "Elasticsearch is external (USE_EXTERNAL_ES)"
+		return check
+	}
+	if runtime.GOOS != "linux" || strings.Contains(operatingSystem, "Docker Desktop") {
+		check.Detail = "daemon runs in a VM, value cannot be read from the host"
+		return check
+	}
+
+	raw, err := os.ReadFile("/proc/sys/vm/max_map_count")
+	if err != nil {
+		check.Detail = err.Error()
+		return check
+	}
+	value, err := strconv.Atoi(strings.TrimSpace(string(raw)))
+	if err != nil {
+		check.Detail = fmt.Sprintf("unparseable value %q", strings.TrimSpace(string(raw)))
+		return check
+	}
+
+	check.Detail = fmt.Sprintf("%d, %d required", value, preflightMinMaxMapCount)
+	if value < preflightMinMaxMapCount {
+		check.Status = PreflightFail
+		check.Hint = fmt.Sprintf("run `sudo sysctl -w vm.max_map_count=%d` (persist it in /etc/sysctl.conf)", preflightMinMaxMapCount)
+		return check
+	}
+	check.Status = PreflightOK
+	return check
+}
+
+// checkDiskSpace verifica o uso do disco do Docker (ou do diretório temporário, se o diretório do
+// daemon não for visível do host) contra o high watermark de disco do Elasticsearch
+func checkDiskSpace(dockerRootDir string) PreflightCheck {
+	check := PreflightCheck{Name: "disk", Status: PreflightUnknown}
+
+	path := dockerRootDir
+	if _, err := os.Stat(path); path == "" || err != nil {
+		path = os.TempDir()
+	}
+
+	free, total, ok := diskUsage(path)
+	if !ok || total == 0 {
+		check.Detail = fmt.Sprintf("usage of %s cannot be read", path)
+		return check
+	}
+
+	used := 100 * float64(total-free) / float64(total)
+	check.Detail = fmt.Sprintf("%s is %.1f%% used, %d MB free", path, used, free/(1024*1024))
+	if used >= preflightMaxDiskUsage {
+		check.Status = PreflightFail
+		check.Hint = fmt.Sprintf("free disk space below %.0f%% usage (Elasticsearch stops allocating shards at the high watermark); `docker system prune` removes unused images and volumes", preflightMaxDiskUsage)
+		return check
+	}
+	check.Status = PreflightOK
+	return check
+}
@@ -0,0 +1,14 @@
+//go:build linux || darwin
+
+package testhelper
+
+import "syscall"
+
+// diskUsage retorna os bytes livres e totais do sistema de arquivos de path
+func diskUsage(path string) (free, total uint64, ok bool) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, 0, false
+	}
+	return stat.Bavail * uint64(stat.Bsize), stat.Blocks * uint64(stat.Bsize), true
+}
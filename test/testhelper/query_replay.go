@@ -0,0 +1,375 @@
+package testhelper
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"github.com/stretchr/testify/require"
+)
+
+// slowlogShardPattern encontra o "[índice][shard]" das linhas de slowlog em texto
+var slowlogShardPattern = regexp.MustCompile(`\[([^\[\]\s]+)\]\[\d+\]`)
+
+// QueryLogEntry é uma consulta extraída de um slowlog/query log exportado de produção
+type QueryLogEntry struct {
+	Line       int                    // linha do arquivo de origem (a partir de 1)
+	Index      string                 // índice consultado em produção
+	Source     map[string]interface{} // corpo da busca
+	TookMillis float64                // latência registrada em produção (0 se ausente)
+	Err        error                  // erro de parse do source (ex.: source truncado no log)
+}
+
+// QueryReplayOptions ajusta a reexecução das consultas
+type QueryReplayOptions struct {
+	TenantField   string        // campo do filtro de tenant injetado (padrão "tenant_id")
+	OutlierFactor float64       // latência acima de OutlierFactor x mediana é outlier (padrão 5)
+	MinOutlier    time.Duration // latência abaixo deste piso nunca é outlier (padrão 50ms)
+	Limit         int           // máximo de consultas reexecutadas (0 = todas)
+}
+
+// QueryReplayResult é o resultado de uma consulta reexecutada
+type QueryReplayResult struct {
+	Entry   QueryLogEntry
+	Took    time.Duration // "took" retornado pelo Elasticsearch
+	Hits    int
+	Err     error
+	Outlier bool
+}
+
+// QueryReplayReport reúne os resultados da reexecução
+type QueryReplayReport struct {
+	Results []QueryReplayResult
+	Median  time.Duration
+}
+
+// ParseQueryLog extrai as consultas de um slowlog de busca do Elasticsearch, nos formatos texto
+// ("... took_millis[12] ... source[{...}]"), JSON do 7.x ("source"/"took_millis") e ECS do 8.x
+// ("elasticsearch.slowlog.source"/"elasticsearch.slowlog.took_millis")
+// Linhas sem source são ignoradas; sources inválidos são mantidos com Err preenchido
+func ParseQueryLog(r io.Reader) ([]QueryLogEntry, error) {
+	var entries []QueryLogEntry
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		entry, ok := parseQueryLogLine(text)
+		if !ok {
+			continue
+		}
+		entry.Line = line
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read query log: %w", err)
+	}
+	return entries, nil
+}
+
+// parseQueryLogLine interpreta uma linha do log; ok=false para linhas que não são consultas
+func parseQueryLogLine(text string) (QueryLogEntry, bool) {
+	if !strings.HasPrefix(text, "{") {
+		return parseTextSlowlog(text)
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(text), &fields); err != nil {
+		return QueryLogEntry{}, false
+	}
+
+	source, ok := firstString(fields, "elasticsearch.slowlog.source", "source")
+	if !ok {
+		// JSON com a linha de texto no campo message
+		if message, ok := fields["message"].(string); ok {
+			return parseTextSlowlog(message)
+		}
+		return QueryLogEntry{}, false
+	}
+
+	var entry QueryLogEntry
+	entry.Index, _ = firstString(fields, "elasticsearch.index.name", "index")
+	entry.Index = slowlogIndexName(entry.Index)
+	if took, ok := firstString(fields, "elasticsearch.slowlog.took_millis", "took_millis"); ok {
+		entry.TookMillis, _ = strconv.ParseFloat(took, 64)
+	}
+	entry.Source, entry.Err = decodeQuerySource(source)
+	return entry, true
+}
+
+// parseTextSlowlog interpreta o formato texto: "[index.search.slowlog.query] [node] [idx][0] took[..],
+// took_millis[12], ..., source[{...}], id[]"
+func parseTextSlowlog(text string) (QueryLogEntry, bool) {
+	start := strings.Index(text, "source[")
+	if start < 0 {
+		return QueryLogEntry{}, false
+	}
+
+	var entry QueryLogEntry
+	if took := bracketValue(text, "took_millis["); took != "" {
+		entry.TookMillis, _ = strconv.ParseFloat(took, 64)
+	}
+
+	if match := slowlogShardPattern.FindStringSubmatch(text[:start]); match != nil {
+		entry.Index = match[1]
+	}
+
+	raw := text[start+len("source["):]
+	if end := strings.LastIndex(raw, "}"); end >= 0 {
+		raw = raw[:end+1]
+	}
+	entry.Source, entry.Err = decodeQuerySource(raw)
+	return entry, true
+}
+
+// slowlogIndexName extrai o nome do índice de "[products][0]" / "products][0" (ou retorna o valor)
+func slowlogIndexName(value string) string {
+	value = strings.TrimPrefix(value, "[")
+	if i := strings.Index(value, "]["); i >= 0 {
+		return value[:i]
+	}
+	return strings.TrimSuffix(value, "]")
+}
+
+// bracketValue retorna o conteúdo entre prefix e o "]" seguinte
+func bracketValue(text, prefix string) string {
+	start := strings.Index(text, prefix)
+	if start < 0 {
+		return ""
+	}
+	rest := text[start+len(prefix):]
+	if end := strings.Index(rest, "]"); end >= 0 {
+		return rest[:end]
+	}
+	return ""
+}
+
+// firstString retorna o primeiro campo presente entre keys, como texto
+func firstString(fields map[string]interface{}, keys ...string) (string, bool) {
+	for _, key := range keys {
+		switch value := fields[key].(type) {
+		case string:
+			return value, true
+		case float64:
+			return strconv.FormatFloat(value, 'f', -1, 64), true
+		case map[string]interface{}:
+			raw, _ := json.Marshal(value)
+			return string(raw), true
+		}
+	}
+	return "", false
+}
+
+// decodeQuerySource decodifica o corpo da busca registrado no log
+func decodeQuerySource(raw string) (map[string]interface{}, error) {
+	var source map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &source); err != nil {
+		return nil, fmt.Errorf("invalid query source: %w", err)
+	}
+	return source, nil
+}
+
+// withTenantFilter envolve a query do corpo em um bool com filtro term no tenant; campos que
+// dependem do estado de produção (pit, search_after) são removidos
+func withTenantFilter(source map[string]interface{}, field, tenantID string) map[string]interface{} {
+	body := make(map[string]interface{}, len(source))
+	for key, value := range source {
+		switch key {
+		case "pit", "search_after":
+			continue
+		}
+		body[key] = value
+	}
+
+	query, ok := body["query"]
+	if !ok {
+		query = map[string]interface{}{"match_all": map[string]interface{}{}}
+	}
+	body["query"] = map[string]interface{}{
+		"bool": map[string]interface{}{
+			"must":   []interface{}{query},
+			"filter": []interface{}{map[string]interface{}{"term": map[string]interface{}{field: tenantID}}},
+		},
+	}
+	return body
+}
+
+// LoadQueryLog lê e interpreta o arquivo de slowlog exportado
+func (s *IntegrationTestSuite) LoadQueryLog(path string) []QueryLogEntry {
+	s.t.Helper()
+
+	file, err := os.Open(path)
+	require.NoError(s.t, err, "Failed to open query log %s", path)
+	defer file.Close()
+
+	entries, err := ParseQueryLog(file)
+	require.NoError(s.t, err, "Failed to parse query log %s", path)
+	return entries
+}
+
+// ReplayQueries reexecuta as consultas no índice da suite com o filtro de tenant injetado
+// Consultas que falham (source inválido, erro do Elasticsearch) não interrompem a reexecução: ficam
+// no relatório, para ver todas as regressões de uma vez
+func (s *IntegrationTestSuite) ReplayQueries(index string, entries []QueryLogEntry, opts QueryReplayOptions) *QueryReplayReport {
+	s.t.Helper()
+	defer s.trackOperation("ReplayQueries")()
+
+	if opts.TenantField == "" {
+		opts.TenantField = "tenant_id"
+	}
+	if opts.OutlierFactor <= 0 {
+		opts.OutlierFactor = 5
+	}
+	if opts.MinOutlier <= 0 {
+		opts.MinOutlier = 50 * time.Millisecond
+	}
+	if opts.Limit > 0 && len(entries) > opts.Limit {
+		entries = entries[:opts.Limit]
+	}
+
+	index = s.RunScopedIndex(index)
+	report := &QueryReplayReport{Results: make([]QueryReplayResult, 0, len(entries))}
+	for _, entry := range entries {
+		result := QueryReplayResult{Entry: entry, Err: entry.Err}
+		if result.Err == nil {
+			result.Took, result.Hits, result.Err = s.replayQuery(index, withTenantFilter(entry.Source, opts.TenantField, s.tenantID))
+		}
+		report.Results = append(report.Results, result)
+	}
+
+	report.markOutliers(opts.OutlierFactor, opts.MinOutlier)
+	return report
+}
+
+// replayQuery executa uma busca e retorna o took e o total de hits
+func (s *IntegrationTestSuite) replayQuery(index string, body map[string]interface{}) (time.Duration, int, error) {
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	res, err := esapi.SearchRequest{Index: []string{index}, Body: bytes.NewReader(raw)}.Do(s.ctx, s.ES())
+	if err != nil {
+		return 0, 0, err
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return 0, 0, fmt.Errorf("search failed: %s", res.String())
+	}
+
+	var response struct {
+		Took int64 `json:"took"`
+		Hits struct {
+			Total struct {
+				Value int `json:"value"`
+			} `json:"total"`
+		} `json:"hits"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return 0, 0, fmt.Errorf("failed to decode search response: %w", err)
+	}
+	return time.Duration(response.Took) * time.Millisecond, response.Hits.Total.Value, nil
+}
+
+// markOutliers calcula a mediana das consultas bem-sucedidas e marca as que passam de factor x mediana
+func (r *QueryReplayReport) markOutliers(factor float64, floor time.Duration) {
+	var tooks []time.Duration
+	for _, result := range r.Results {
+		if result.Err == nil {
+			tooks = append(tooks, result.Took)
+		}
+	}
+	if len(tooks) == 0 {
+		return
+	}
+
+	sort.Slice(tooks, func(i, j int) bool { return tooks[i] < tooks[j] })
+	r.Median = tooks[len(tooks)/2]
+
+	limit := time.Duration(float64(r.Median) * factor)
+	if limit < floor {
+		limit = floor
+	}
+	for i := range r.Results {
+		r.Results[i].Outlier = r.Results[i].Err == nil && r.Results[i].Took > limit
+	}
+}
+
+// Failures retorna as consultas que falharam
+func (r *QueryReplayReport) Failures() []QueryReplayResult {
+	var failures []QueryReplayResult
+	for _, result := range r.Results {
+		if result.Err != nil {
+			failures = append(failures, result)
+		}
+	}
+	return failures
+}
+
+// Outliers retorna as consultas com latência fora da curva
+func (r *QueryReplayReport) Outliers() []QueryReplayResult {
+	var outliers []QueryReplayResult
+	for _, result := range r.Results {
+		if result.Outlier {
+			outliers = append(outliers, result)
+		}
+	}
+	return outliers
+}
+
+// String resume o relatório: totais e uma linha por falha/outlier
+func (r *QueryReplayReport) String() string {
+	failures, outliers := r.Failures(), r.Outliers()
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "%d queries replayed, %d failed, %d latency outliers (median %s)\n",
+		len(r.Results), len(failures), len(outliers), r.Median)
+
+	if len(failures)+len(outliers) == 0 {
+		return out.String()
+	}
+
+	w := tabwriter.NewWriter(&out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "line\tindex\tstatus\ttook\tprod took\tdetail")
+	for _, result := range r.Results {
+		switch {
+		case result.Err != nil:
+			fmt.Fprintf(w, "%d\t%s\tFAILED\t\t%.0fms\t%v\n", result.Entry.Line, result.Entry.Index, result.Entry.TookMillis, result.Err)
+		case result.Outlier:
+			fmt.Fprintf(w, "%d\t%s\tSLOW\t%s\t%.0fms\t%d hits\n", result.Entry.Line, result.Entry.Index, result.Took, result.Entry.TookMillis, result.Hits)
+		}
+	}
+	w.Flush()
+	return out.String()
+}
+
+// AssertQueryReplay reexecuta o log de consultas no índice e falha se alguma consulta falhar
+// Outliers de latência são apenas registrados no log: a latência local não é comparável à de produção
+func (s *IntegrationTestSuite) AssertQueryReplay(index, logPath string, opts QueryReplayOptions) *QueryReplayReport {
+	s.t.Helper()
+
+	entries := s.LoadQueryLog(logPath)
+	require.NotEmpty(s.t, entries, "No queries found in %s", logPath)
+
+	report := s.ReplayQueries(index, entries, opts)
+	s.t.Logf("Query replay:\n%s", report)
+	require.Empty(s.t, report.Failures(), "Replayed queries failed:\n%s", report)
+	return report
+}
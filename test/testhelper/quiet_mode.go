@@ -0,0 +1,210 @@
+package testhelper
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/testcontainers/testcontainers-go"
+	tclog "github.com/testcontainers/testcontainers-go/log"
+)
+
+// Modo silencioso para logs de CI
+//
+// Com o modo ligado (WithQuietMode ou TEST_QUIET=true), os banners com emoji do testhelper e do
+// testcontainers são descartados (exceto avisos e erros), o debug (DEBUG_TEST_CONTAINERS) é ignorado e
+// o restante da saída do pacote log fica limitado a N KB por teste; ao final de cada suite é impressa
+// uma linha de resumo. O modo vale para o processo inteiro (os loggers são globais) e não é desligado depois de ligado
+
+// DefaultQuietLogBudgetKB é o limite de saída por teste quando TEST_QUIET_LOG_KB não é informado
+const DefaultQuietLogBudgetKB = 16
+
+var (
+	quietEnabled atomic.Bool
+	quietInstall sync.Once
+	quietLogOut  = &budgetWriter{dest: os.Stderr}
+)
+
+// quietModeFromEnv indica se o modo silencioso está ligado via TEST_QUIET
+func quietModeFromEnv() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv("TEST_QUIET"))
+	return enabled
+}
+
+// quietModeEnabled indica se o modo silencioso está ligado (builder ou ambiente)
+func quietModeEnabled() bool {
+	return quietEnabled.Load() || quietModeFromEnv()
+}
+
+// enableQuietMode liga o modo silencioso com o limite informado (0 usa TEST_QUIET_LOG_KB ou o padrão)
+func enableQuietMode(maxLogKB int) {
+	if maxLogKB <= 0 {
+		maxLogKB = DefaultQuietLogBudgetKB
+		if kb, err := strconv.Atoi(os.Getenv("TEST_QUIET_LOG_KB")); err == nil && kb > 0 {
+			maxLogKB = kb
+		}
+	}
+
+	quietEnabled.Store(true)
+	quietLogOut.setLimit(maxLogKB * 1024)
+	quietInstall.Do(func() {
+		log.SetOutput(quietLogOut)
+		tclog.SetDefault(log.New(quietLogOut, "", log.LstdFlags))
+	})
+}
+
+// budgetWriter descarta banners e limita a saída de log a um orçamento de bytes por janela (teste)
+type budgetWriter struct {
+	mu           sync.Mutex
+	dest         io.Writer
+	limit        int
+	written      int
+	dropped      int
+	droppedLines int
+	truncated    bool
+}
+
+// Write implementa io.Writer; o pacote log chama Write uma vez por linha
+func (w *budgetWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if isBannerLine(p) {
+		w.dropped += len(p)
+		w.droppedLines++
+		return len(p), nil
+	}
+
+	if w.written+len(p) > w.limit {
+		w.dropped += len(p)
+		w.droppedLines++
+		if !w.truncated {
+			w.truncated = true
+			fmt.Fprintf(w.dest, "testhelper: log output truncated (budget %d KB per test)\n", w.limit/1024)
+		}
+		return len(p), nil
+	}
+
+	w.written += len(p)
+	return w.dest.Write(p)
+}
+
+// setLimit define o orçamento em bytes
+func (w *budgetWriter) setLimit(limit int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.limit = limit
+}
+
+// limitBytes retorna o orçamento em bytes
+func (w *budgetWriter) limitBytes() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.limit
+}
+
+// reset abre uma nova janela (teste)
+func (w *budgetWriter) reset() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.written, w.dropped, w.droppedLines, w.truncated = 0, 0, 0, false
+}
+
+// stats retorna as estatísticas da janela atual
+func (w *budgetWriter) stats() (written, dropped, droppedLines int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.written, w.dropped, w.droppedLines
+}
+
+// isBannerLine indica uma linha de banner: a mensagem (após data/hora do log) começa com um emoji
+// Avisos (⚠️) e erros (❌) não são banners e continuam sujeitos apenas ao orçamento
+func isBannerLine(line []byte) bool {
+	msg := bytes.TrimLeft(line, "0123456789/:. ")
+	r, _ := utf8.DecodeRune(msg)
+	if r == '⚠' || r == '❌' {
+		return false
+	}
+	return r != utf8.RuneError && (unicode.Is(unicode.So, r) || r >= 0x1F000)
+}
+
+// startQuietSummary abre a janela de saída do teste e registra a linha de resumo da suite
+func (s *IntegrationTestSuite) startQuietSummary(setup time.Duration) {
+	if !quietModeEnabled() {
+		return
+	}
+	if !quietEnabled.Load() {
+		enableQuietMode(0)
+	}
+
+	quietLogOut.reset()
+	start := time.Now()
+	s.t.Cleanup(func() {
+		written, dropped, lines := quietLogOut.stats()
+		s.t.Logf("testhelper summary: tenant %s, setup %v, total %v, log %.1f KB written, %d lines suppressed (%.1f KB)",
+			s.tenantID, setup.Round(time.Millisecond), time.Since(start).Round(time.Millisecond),
+			float64(written)/1024, lines, float64(dropped)/1024)
+	})
+}
+
+// ContainerLogs retorna o final dos logs do container, limitado ao orçamento do modo silencioso
+// (ou a DefaultQuietLogBudgetKB fora dele)
+func (s *IntegrationTestSuite) ContainerLogs(container testcontainers.Container) string {
+	s.t.Helper()
+
+	limit := DefaultQuietLogBudgetKB * 1024
+	if current := quietLogOut.limitBytes(); quietModeEnabled() && current > 0 {
+		limit = current
+	}
+
+	logs, err := readContainerLogsTail(s.ctx, container, limit)
+	if err != nil {
+		return fmt.Sprintf("<failed to read container logs: %v>", err)
+	}
+	return logs
+}
+
+// CaptureContainerLogs registra os logs do container no teste (t.Log) se ele falhar, limitados como
+// em ContainerLogs
+func (s *IntegrationTestSuite) CaptureContainerLogs(container testcontainers.Container) {
+	s.t.Helper()
+
+	s.t.Cleanup(func() {
+		if !s.t.Failed() {
+			return
+		}
+		s.t.Logf("container %s logs:\n%s", container.GetContainerID(), s.ContainerLogs(container))
+	})
+}
+
+// readContainerLogsTail lê os logs do container mantendo apenas os últimos limit bytes
+func readContainerLogsTail(ctx context.Context, container testcontainers.Container, limit int) (string, error) {
+	rc, err := container.Logs(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to read container logs: %w", err)
+	}
+	defer rc.Close()
+
+	raw, err := io.ReadAll(rc)
+	if err != nil {
+		return "", fmt.Errorf("failed to read container logs: %w", err)
+	}
+	if len(raw) <= limit {
+		return string(raw), nil
+	}
+
+	tail := raw[len(raw)-limit:]
+	if i := bytes.IndexByte(tail, '\n'); i >= 0 {
+		tail = tail[i+1:]
+	}
+	return fmt.Sprintf("... (%d KB truncated)\n%s", (len(raw)-len(tail))/1024, tail), nil
+}
@@ -0,0 +1,128 @@
+package testhelper
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ReadinessProbe verifica se uma dependência está pronta para receber operações
+type ReadinessProbe func(ctx context.Context, s *IntegrationTestSuite) error
+
+// readinessPollInterval é o intervalo entre tentativas do WaitUntilReady
+const readinessPollInterval = 200 * time.Millisecond
+
+var (
+	readinessProbesMu sync.RWMutex
+	readinessProbes   = map[Dependency]ReadinessProbe{
+		DepElasticsearch: probeElasticsearch,
+		DepPostgres:      probePostgres,
+		DepMongo:         probeMongo,
+	}
+)
+
+// RegisterReadinessProbe registra (ou substitui) o probe de prontidão de uma dependência
+func RegisterReadinessProbe(dep Dependency, probe ReadinessProbe) {
+	readinessProbesMu.Lock()
+	defer readinessProbesMu.Unlock()
+	readinessProbes[dep] = probe
+}
+
+// readinessProbe retorna o probe registrado para a dependência
+func readinessProbe(dep Dependency) (ReadinessProbe, bool) {
+	readinessProbesMu.RLock()
+	defer readinessProbesMu.RUnlock()
+	probe, ok := readinessProbes[dep]
+	return probe, ok
+}
+
+// ProbeReady executa uma única vez o probe de prontidão da dependência
+func (s *IntegrationTestSuite) ProbeReady(dep Dependency) error {
+	probe, ok := readinessProbe(dep)
+	if !ok {
+		return fmt.Errorf("no readiness probe registered for %s", dep)
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, 5*time.Second)
+	defer cancel()
+
+	return probe(ctx, s)
+}
+
+// WaitUntilReady aguarda a dependência responder ao probe de prontidão (ES: cluster health green/yellow,
+// PG: SELECT 1, Mongo: ping), falhando o teste com o último erro se o timeout expirar
+// Útil no meio do teste após helpers de caos ou restart de container
+func (s *IntegrationTestSuite) WaitUntilReady(dep Dependency, timeout time.Duration) {
+	s.t.Helper()
+
+	probe, ok := readinessProbe(dep)
+	require.True(s.t, ok, "No readiness probe registered for %s", dep)
+
+	ctx, cancel := context.WithTimeout(s.ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	attempts := 0
+	for {
+		attempts++
+
+		attemptCtx, cancelAttempt := context.WithTimeout(ctx, 5*time.Second)
+		err := probe(attemptCtx, s)
+		cancelAttempt()
+
+		if err == nil {
+			if isDebugEnabled() && attempts > 1 {
+				fmt.Printf("✅ %s ready after %v (%d attempts)\n", dep, time.Since(start).Round(time.Millisecond), attempts)
+			}
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			require.Fail(s.t, fmt.Sprintf("%s not ready after %v (%d attempts): %v", dep, timeout, attempts, err))
+			return
+		case <-time.After(readinessPollInterval):
+		}
+	}
+}
+
+// probeElasticsearch verifica se o cluster está green ou yellow
+func probeElasticsearch(ctx context.Context, s *IntegrationTestSuite) error {
+	client := s.ES()
+	if client == nil {
+		return fmt.Errorf("elasticsearch not configured")
+	}
+
+	status, err := indicesHealth(ctx, client, nil)
+	if err != nil {
+		return err
+	}
+	if status != "green" && status != "yellow" {
+		return fmt.Errorf("cluster health is %s", status)
+	}
+	return nil
+}
+
+// probePostgres executa SELECT 1
+func probePostgres(ctx context.Context, s *IntegrationTestSuite) error {
+	db := s.Postgres()
+	if db == nil {
+		return fmt.Errorf("postgres not configured")
+	}
+
+	var one int
+	return db.QueryRowContext(ctx, "SELECT 1").Scan(&one)
+}
+
+// probeMongo executa um ping no servidor
+func probeMongo(ctx context.Context, s *IntegrationTestSuite) error {
+	db := s.Mongo()
+	if db == nil {
+		return fmt.Errorf("mongo not configured")
+	}
+
+	return db.Client().Ping(ctx, nil)
+}
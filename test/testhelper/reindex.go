@@ -0,0 +1,197 @@
+package testhelper
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"github.com/stretchr/testify/require"
+)
+
+// Reindex e update-by-query rodam como tasks (wait_for_completion=false) e a conclusão é aguardada pela
+// API de tasks: operações longas não esbarram no timeout da requisição HTTP
+
+// byQueryTaskTimeout é o tempo máximo de espera pela conclusão da task (TEST_BY_QUERY_TIMEOUT)
+const byQueryTaskTimeout = 5 * time.Minute
+
+// ByQueryResult são os contadores de uma operação _reindex/_update_by_query concluída
+type ByQueryResult struct {
+	Total            int64
+	Created          int64
+	Updated          int64
+	Deleted          int64
+	Noops            int64
+	VersionConflicts int64
+	Took             time.Duration
+}
+
+// Reindex copia os documentos de source para dest, aplicando o script painless (vazio = cópia simples),
+// e aguarda a conclusão. Falhas de documentos falham o teste; dest recebe refresh ao final
+func (s *IntegrationTestSuite) Reindex(source, dest, script string) ByQueryResult {
+	s.t.Helper()
+	defer s.trackOperation("Reindex")()
+
+	client := s.ES()
+	require.NotNil(s.t, client, "Elasticsearch not configured")
+	source = s.RunScopedIndex(source)
+	dest = s.RunScopedIndex(dest)
+
+	body := map[string]interface{}{
+		"source": map[string]interface{}{"index": source},
+		"dest":   map[string]interface{}{"index": dest},
+	}
+	if script != "" {
+		body["script"] = painlessScript(script)
+	}
+	bodyJSON, err := json.Marshal(body)
+	require.NoError(s.t, err, "Failed to marshal reindex request")
+
+	res, err := esapi.ReindexRequest{
+		Body:              strings.NewReader(string(bodyJSON)),
+		Refresh:           esapi.BoolPtr(true),
+		WaitForCompletion: esapi.BoolPtr(false),
+	}.Do(s.ctx, client)
+	require.NoError(s.t, err, "Failed to reindex %s into %s", source, dest)
+
+	return s.awaitByQueryTask(fmt.Sprintf("reindex %s -> %s", source, dest), res)
+}
+
+// UpdateByQuery aplica o script painless aos documentos do índice que casam com a query (cláusula ou
+// corpo com "query"; nil = todos) e aguarda a conclusão. Conflitos de versão falham o teste
+func (s *IntegrationTestSuite) UpdateByQuery(index string, query map[string]interface{}, script string) ByQueryResult {
+	s.t.Helper()
+	defer s.trackOperation("UpdateByQuery")()
+
+	client := s.ES()
+	require.NotNil(s.t, client, "Elasticsearch not configured")
+	index = s.RunScopedIndex(index)
+
+	body := map[string]interface{}{}
+	if clause := queryClause(query); clause != nil {
+		body["query"] = clause
+	}
+	if script != "" {
+		body["script"] = painlessScript(script)
+	}
+	bodyJSON, err := json.Marshal(body)
+	require.NoError(s.t, err, "Failed to marshal update by query request")
+
+	res, err := esapi.UpdateByQueryRequest{
+		Index:             []string{index},
+		Body:              strings.NewReader(string(bodyJSON)),
+		Refresh:           esapi.BoolPtr(true),
+		WaitForCompletion: esapi.BoolPtr(false),
+	}.Do(s.ctx, client)
+	require.NoError(s.t, err, "Failed to update by query on %s", index)
+
+	return s.awaitByQueryTask("update by query on "+index, res)
+}
+
+// painlessScript monta o objeto script com a linguagem padrão
+func painlessScript(source string) map[string]interface{} {
+	return map[string]interface{}{"source": source, "lang": "painless"}
+}
+
+// byQueryResponse é a resposta de _reindex/_update_by_query (também o campo "response" da task)
+type byQueryResponse struct {
+	Took             int64             `json:"took"`
+	Total            int64             `json:"total"`
+	Created          int64             `json:"created"`
+	Updated          int64             `json:"updated"`
+	Deleted          int64             `json:"deleted"`
+	Noops            int64             `json:"noops"`
+	VersionConflicts int64             `json:"version_conflicts"`
+	Failures         []json.RawMessage `json:"failures"`
+}
+
+// awaitByQueryTask lê o id da task iniciada em res e aguarda a conclusão, falhando com as falhas da operação
+func (s *IntegrationTestSuite) awaitByQueryTask(operation string, res *esapi.Response) ByQueryResult {
+	s.t.Helper()
+	defer res.Body.Close()
+
+	if res.IsError() {
+		require.Fail(s.t, fmt.Sprintf("Failed to start %s: %s", operation, res.String()))
+		return ByQueryResult{}
+	}
+
+	var started struct {
+		Task string `json:"task"`
+	}
+	require.NoError(s.t, json.NewDecoder(res.Body).Decode(&started), "Failed to decode %s task", operation)
+	require.NotEmpty(s.t, started.Task, "No task returned for %s", operation)
+
+	timeout := byQueryTaskTimeout
+	if value, err := time.ParseDuration(envOrDefault("TEST_BY_QUERY_TIMEOUT", "")); err == nil && value > 0 {
+		timeout = value
+	}
+
+	client := s.ES()
+	deadline := time.Now().Add(timeout)
+	for {
+		taskRes, err := client.Tasks.Get(started.Task,
+			client.Tasks.Get.WithContext(s.ctx),
+			client.Tasks.Get.WithWaitForCompletion(true),
+			client.Tasks.Get.WithTimeout(30*time.Second))
+		require.NoError(s.t, err, "Failed to get task %s", started.Task)
+
+		var task struct {
+			Completed bool            `json:"completed"`
+			Response  byQueryResponse `json:"response"`
+			Error     json.RawMessage `json:"error"`
+		}
+		// Timeout do wait_for_completion retorna erro com a task ainda em andamento: tenta de novo
+		pending := taskRes.IsError()
+		if !pending {
+			err = json.NewDecoder(taskRes.Body).Decode(&task)
+		}
+		taskRes.Body.Close()
+		require.NoError(s.t, err, "Failed to decode task %s", started.Task)
+
+		if task.Completed {
+			if len(task.Error) > 0 {
+				require.Fail(s.t, fmt.Sprintf("%s failed: %s", operation, task.Error))
+			}
+			return s.byQueryResult(operation, task.Response)
+		}
+
+		if time.Now().After(deadline) {
+			require.Fail(s.t, fmt.Sprintf("%s did not complete within %s (task %s)", operation, timeout, started.Task))
+			return ByQueryResult{}
+		}
+		if pending {
+			time.Sleep(500 * time.Millisecond)
+		}
+	}
+}
+
+// byQueryResult converte a resposta, falhando com as primeiras falhas de documentos
+func (s *IntegrationTestSuite) byQueryResult(operation string, response byQueryResponse) ByQueryResult {
+	s.t.Helper()
+
+	if len(response.Failures) > 0 || response.VersionConflicts > 0 {
+		var samples []string
+		for _, failure := range response.Failures {
+			samples = appendErrorSample(samples, string(failure))
+		}
+		require.Fail(s.t, fmt.Sprintf("%s finished with %d failure(s) and %d version conflict(s)",
+			operation, len(response.Failures), response.VersionConflicts),
+			"First failures:\n%s", strings.Join(samples, "\n"))
+	}
+
+	if isDebugEnabled() {
+		fmt.Printf("🔁 %s: %d created, %d updated, %d noops in %dms\n",
+			operation, response.Created, response.Updated, response.Noops, response.Took)
+	}
+
+	return ByQueryResult{
+		Total:            response.Total,
+		Created:          response.Created,
+		Updated:          response.Updated,
+		Deleted:          response.Deleted,
+		Noops:            response.Noops,
+		VersionConflicts: response.VersionConflicts,
+		Took:             time.Duration(response.Took) * time.Millisecond,
+	}
+}
@@ -0,0 +1,187 @@
+package testhelper
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Isolamento por execução em modo externo
+//
+// Com USE_EXTERNAL_ES/USE_EXTERNAL_MONGO apontando para infraestrutura compartilhada (ex.: staging),
+// execuções de CI simultâneas usariam os mesmos índices/databases. Nesse modo os helpers sufixam os
+// recursos que criam com o ID da execução ("products" -> "products_run_<id>") e, quando a última suite
+// que usa esses recursos termina, uma varredura remove tudo que tem o sufixo
+
+// runIDInvalid casa caracteres que não podem ser usados no sufixo
+var runIDInvalid = regexp.MustCompile(`[^a-z0-9]+`)
+
+var (
+	runIDOnce sync.Once
+	runID     string
+
+	runScope = &runScopeTracker{}
+
+	// runScopeTests guarda os testes já registrados no runScope (um registro por *testing.T)
+	runScopeTests sync.Map
+)
+
+// RunID retorna o ID desta execução de testes: TEST_RUN_ID (ex.: o ID do job de CI) seguido de uma
+// parte aleatória por processo, já que o go test roda cada pacote em um processo separado
+func RunID() string {
+	runIDOnce.Do(func() {
+		b := make([]byte, 3)
+		rand.Read(b)
+		runID = runIDInvalid.ReplaceAllString(strings.ToLower(os.Getenv("TEST_RUN_ID")), "") + hex.EncodeToString(b)
+	})
+	return runID
+}
+
+// RunSuffix retorna o sufixo aplicado aos recursos desta execução ("_run_<id>")
+func RunSuffix() string {
+	return "_run_" + RunID()
+}
+
+// RunScopedName aplica o sufixo da execução ao nome (idempotente)
+func RunScopedName(name string) string {
+	if strings.HasSuffix(name, RunSuffix()) {
+		return name
+	}
+	return name + RunSuffix()
+}
+
+// externalES indica se o Elasticsearch é externo (compartilhado com outras execuções)
+func externalES() bool {
+	external, _ := strconv.ParseBool(os.Getenv("USE_EXTERNAL_ES"))
+	return external
+}
+
+// externalMongo indica se o MongoDB é externo (compartilhado com outras execuções)
+func externalMongo() bool {
+	external, _ := strconv.ParseBool(os.Getenv("USE_EXTERNAL_MONGO"))
+	return external
+}
+
+// RunScopedIndex retorna o nome real do índice usado pelos helpers: com WithIndexPrefixIsolation recebe
+// o prefixo do teste e com ES externo o sufixo da execução; caso contrário é o próprio nome
+// Use-o (ou IndexName) para apontar a aplicação para o mesmo índice
+func (s *IntegrationTestSuite) RunScopedIndex(name string) string {
+	name = s.prefixedIndex(name)
+	if !externalES() {
+		return name
+	}
+
+	s.acquireRunScope()
+	return RunScopedName(name)
+}
+
+// acquireRunScope registra o teste da suite como usuário dos recursos da execução (uma vez por teste)
+func (s *IntegrationTestSuite) acquireRunScope() {
+	if _, loaded := runScopeTests.LoadOrStore(s.t, struct{}{}); loaded {
+		return
+	}
+
+	runScope.acquire()
+	s.onCleanup(func() {
+		runScopeTests.Delete(s.t)
+		runScope.release(context.Background())
+	})
+}
+
+// runScopeTracker conta as suites/builders em uso e varre os recursos quando o último termina
+type runScopeTracker struct {
+	mu     sync.Mutex
+	active int
+}
+
+// acquire incrementa o número de usuários ativos
+func (r *runScopeTracker) acquire() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.active++
+}
+
+// release decrementa o número de usuários e executa a varredura ao chegar a zero
+func (r *runScopeTracker) release(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.active--
+	if r.active > 0 {
+		return nil
+	}
+	r.active = 0
+
+	return SweepRunResources(ctx)
+}
+
+// SweepRunResources remove os índices e databases desta execução (sufixo RunSuffix) nas dependências
+// externas. É chamado automaticamente quando a última suite termina; pode ser chamado no TestMain
+func SweepRunResources(ctx context.Context) error {
+	var errs []string
+
+	if externalES() {
+		if n, err := sweepRunIndices(ctx); err != nil {
+			errs = append(errs, err.Error())
+		} else if n > 0 && isDebugEnabled() {
+			log.Printf("🧹 Swept %d index(es) of run %s", n, RunID())
+		}
+	}
+
+	if externalMongo() {
+		if n, err := sweepRunDatabases(ctx); err != nil {
+			errs = append(errs, err.Error())
+		} else if n > 0 && isDebugEnabled() {
+			log.Printf("🧹 Swept %d mongo database(s) of run %s", n, RunID())
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to sweep run resources: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// sweepRunIndices remove os índices com o sufixo da execução
+// Os nomes são listados e removidos explicitamente (action.destructive_requires_name bloqueia wildcards)
+func sweepRunIndices(ctx context.Context) (int, error) {
+	client := GetSharedElasticsearch().GetClient()
+	if client == nil {
+		return 0, nil
+	}
+
+	n, err := deleteIndicesMatching(ctx, client, "*"+RunSuffix())
+	if err != nil {
+		return 0, fmt.Errorf("failed to sweep run indices: %w", err)
+	}
+	return n, nil
+}
+
+// sweepRunDatabases remove os databases do MongoDB com o sufixo da execução
+func sweepRunDatabases(ctx context.Context) (int, error) {
+	client := GetSharedMongoDB().GetClient()
+	if client == nil {
+		return 0, nil
+	}
+
+	names, err := client.ListDatabaseNames(ctx, bson.M{"name": bson.M{"$regex": regexp.QuoteMeta(RunSuffix()) + "$"}})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list run databases: %w", err)
+	}
+
+	for _, name := range names {
+		if err := client.Database(name).Drop(ctx); err != nil {
+			return 0, fmt.Errorf("failed to drop database %s: %w", name, err)
+		}
+	}
+	return len(names), nil
+}
@@ -0,0 +1,327 @@
+package testhelper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// Harness de saga/compensação
+//
+// RunSaga executa a saga uma vez por ponto de falha: antes de cada cenário o estado do tenant
+// (documentos do ES, linhas do PostgreSQL, documentos do MongoDB e tamanho das filas do ElasticMQ) é
+// fotografado; os passos rodam até o ponto configurado, a falha é injetada, as compensações dos passos
+// concluídos rodam em ordem inversa e o estado resultante deve ser igual à foto
+
+// SagaFailurePoint indica em que momento de um passo a falha é injetada
+type SagaFailurePoint int
+
+const (
+	// SagaFailBefore injeta a falha antes da ação (a ação não é executada)
+	SagaFailBefore SagaFailurePoint = 1 << iota
+	// SagaFailAfter executa a ação e injeta a falha em seguida (ex.: timeout após o commit);
+	// a compensação do próprio passo também roda, então ela deve ser idempotente
+	SagaFailAfter
+	// SagaFailNone não injeta falhas no passo
+	SagaFailNone
+)
+
+// SagaStep é um passo da saga: a ação e a compensação que desfaz seus efeitos
+type SagaStep struct {
+	Name       string
+	Action     func(ctx context.Context) error
+	Compensate func(ctx context.Context) error
+	// FailAt define os pontos de falha do passo; zero equivale a SagaFailBefore|SagaFailAfter
+	FailAt SagaFailurePoint
+}
+
+// SagaScenario descreve um cenário executado por RunSaga
+type SagaScenario struct {
+	Step        string
+	Point       SagaFailurePoint
+	Compensated []string
+}
+
+// String descreve o cenário nas mensagens de asserção
+func (c SagaScenario) String() string {
+	if c.Point == SagaFailAfter {
+		return "failure after " + c.Step
+	}
+	return "failure before " + c.Step
+}
+
+// RunSaga executa um cenário por ponto de falha configurado e verifica que as compensações restauraram o
+// estado de cada store do tenant. Falhas nas ações (fora da injeção) e nas compensações falham o teste.
+// Retorna os cenários executados
+func (s *IntegrationTestSuite) RunSaga(steps ...SagaStep) []SagaScenario {
+	s.t.Helper()
+	defer s.trackOperation("RunSaga")()
+
+	require.NotEmpty(s.t, steps, "RunSaga needs at least one step")
+
+	var scenarios []SagaScenario
+	for i, step := range steps {
+		points := step.FailAt
+		if points == 0 {
+			points = SagaFailBefore | SagaFailAfter
+		}
+
+		for _, point := range []SagaFailurePoint{SagaFailBefore, SagaFailAfter} {
+			if points&SagaFailNone != 0 || points&point == 0 {
+				continue
+			}
+
+			scenario := SagaScenario{Step: sagaStepName(step, i), Point: point}
+			before := s.sagaSnapshot()
+
+			compensated, err := runSagaScenario(s.ctx, steps, i, point)
+			scenario.Compensated = compensated
+			if !assert.NoError(s.t, err, "Saga scenario %s", scenario) {
+				scenarios = append(scenarios, scenario)
+				continue
+			}
+
+			after := s.sagaSnapshot()
+			if diff := before.diff(after); len(diff) > 0 {
+				assert.Fail(s.t, "Saga compensation did not restore state",
+					"scenario %s (compensated %v):\n%s", scenario, compensated, strings.Join(diff, "\n"))
+			}
+			scenarios = append(scenarios, scenario)
+		}
+	}
+	return scenarios
+}
+
+// runSagaScenario roda os passos até failAt, injeta a falha e compensa os passos concluídos em ordem
+// inversa; retorna os nomes dos passos compensados
+func runSagaScenario(ctx context.Context, steps []SagaStep, failAt int, point SagaFailurePoint) ([]string, error) {
+	completed := make([]int, 0, failAt+1)
+
+	for i := 0; i <= failAt; i++ {
+		if i == failAt && point == SagaFailBefore {
+			break
+		}
+		if err := steps[i].Action(ctx); err != nil {
+			return nil, fmt.Errorf("step %s failed before the injected failure: %w", sagaStepName(steps[i], i), err)
+		}
+		completed = append(completed, i)
+	}
+
+	compensated := make([]string, 0, len(completed))
+	for j := len(completed) - 1; j >= 0; j-- {
+		step := steps[completed[j]]
+		name := sagaStepName(step, completed[j])
+		if step.Compensate == nil {
+			continue
+		}
+		if err := step.Compensate(ctx); err != nil {
+			return compensated, fmt.Errorf("compensation of %s failed: %w", name, err)
+		}
+		compensated = append(compensated, name)
+	}
+	return compensated, nil
+}
+
+// sagaStepName retorna o nome do passo ou "step <n>"
+func sagaStepName(step SagaStep, i int) string {
+	if step.Name != "" {
+		return step.Name
+	}
+	return fmt.Sprintf("step %d", i+1)
+}
+
+// sagaState é a foto do estado do tenant: chave do registro -> conteúdo canônico
+type sagaState map[string]string
+
+// diff lista os registros criados, removidos ou alterados em relação à foto
+func (before sagaState) diff(after sagaState) []string {
+	var diff []string
+	for key, value := range before {
+		current, ok := after[key]
+		switch {
+		case !ok:
+			diff = append(diff, "- removed "+key)
+		case current != value:
+			diff = append(diff, fmt.Sprintf("~ changed %s\n    before: %s\n    after:  %s", key, value, current))
+		}
+	}
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			diff = append(diff, "+ created "+key)
+		}
+	}
+	sort.Strings(diff)
+	return diff
+}
+
+// sagaSnapshot fotografa o estado do tenant em todas as dependências configuradas
+func (s *IntegrationTestSuite) sagaSnapshot() sagaState {
+	s.t.Helper()
+
+	state := sagaState{}
+	if s.sharedES != nil || (s.builder != nil && s.builder.ESConn != nil) {
+		require.NoError(s.t, s.snapshotElasticsearch(state), "Failed to snapshot Elasticsearch state")
+	}
+	if db := s.Postgres(); db != nil {
+		require.NoError(s.t, s.snapshotPostgres(state), "Failed to snapshot PostgreSQL state")
+	}
+	if db := s.Mongo(); db != nil {
+		require.NoError(s.t, s.snapshotMongo(db, "mongo", state), "Failed to snapshot MongoDB state")
+	}
+	if db := s.MongoDW(); db != nil {
+		require.NoError(s.t, s.snapshotMongo(db, "mongo_dw", state), "Failed to snapshot MongoDB DW state")
+	}
+	if mq := s.ElasticMQ(); mq != nil {
+		for queue := range s.builder.ElasticMQQueueURLs {
+			count, err := mq.ApproximateMessageCount(s.ctx, queue)
+			require.NoError(s.t, err, "Failed to snapshot queue %s", queue)
+			state["sqs/"+queue] = fmt.Sprintf("%d messages", count)
+		}
+	}
+	return state
+}
+
+// snapshotElasticsearch registra os documentos do tenant (após refresh) por índice e _id
+func (s *IntegrationTestSuite) snapshotElasticsearch(state sagaState) error {
+	refresh, err := s.ES().Indices.Refresh(
+		s.ES().Indices.Refresh.WithContext(s.ctx),
+		s.ES().Indices.Refresh.WithIgnoreUnavailable(true),
+		s.ES().Indices.Refresh.WithAllowNoIndices(true),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to refresh indices: %w", err)
+	}
+	refresh.Body.Close()
+
+	body, err := json.Marshal(map[string]interface{}{
+		"size": 10000,
+		"query": map[string]interface{}{
+			"term": map[string]interface{}{"tenant_id.keyword": s.tenantID},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot query: %w", err)
+	}
+
+	req := esapi.SearchRequest{
+		Index:             []string{"*"},
+		Body:              bytes.NewReader(body),
+		IgnoreUnavailable: esapi.BoolPtr(true),
+		AllowNoIndices:    esapi.BoolPtr(true),
+	}
+	res, err := req.Do(s.ctx, s.ES())
+	if err != nil {
+		return fmt.Errorf("failed to search tenant documents: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("failed to search tenant documents: %s", res.Status())
+	}
+
+	var response struct {
+		Hits struct {
+			Hits []struct {
+				Index  string          `json:"_index"`
+				ID     string          `json:"_id"`
+				Source json.RawMessage `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return fmt.Errorf("failed to decode tenant documents: %w", err)
+	}
+
+	for _, hit := range response.Hits.Hits {
+		state["elasticsearch/"+hit.Index+"/"+hit.ID] = canonicalJSON(hit.Source)
+	}
+	return nil
+}
+
+// snapshotPostgres registra as linhas do tenant de todas as tabelas com coluna tenant_id
+func (s *IntegrationTestSuite) snapshotPostgres(state sagaState) error {
+	db := s.Postgres()
+
+	tables, err := tenantTables(s.ctx, db)
+	if err != nil {
+		return err
+	}
+
+	for _, table := range tables {
+		rows, err := db.QueryContext(s.ctx,
+			fmt.Sprintf(`SELECT row_to_json(t)::text FROM "%s" t WHERE tenant_id::text = $1 ORDER BY 1`, table),
+			s.tenantID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to snapshot table %s: %w", table, err)
+		}
+
+		counts := make(map[string]int)
+		for rows.Next() {
+			var row string
+			if err := rows.Scan(&row); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan row of %s: %w", table, err)
+			}
+			// Sem chave primária conhecida, cada linha é identificada pelo conteúdo (e ocorrência)
+			row = canonicalJSON([]byte(row))
+			counts[row]++
+			state[fmt.Sprintf("postgres/%s/%s#%d", table, row, counts[row])] = row
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to read rows of %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// snapshotMongo registra os documentos do tenant de todas as coleções por _id
+func (s *IntegrationTestSuite) snapshotMongo(db *mongo.Database, prefix string, state sagaState) error {
+	collections, err := db.ListCollectionNames(s.ctx, bson.M{})
+	if err != nil {
+		return fmt.Errorf("failed to list collections: %w", err)
+	}
+
+	for _, name := range collections {
+		cursor, err := db.Collection(name).Find(s.ctx, bson.M{"tenant_id": s.tenantID},
+			options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+		if err != nil {
+			return fmt.Errorf("failed to snapshot collection %s: %w", name, err)
+		}
+
+		for cursor.Next(s.ctx) {
+			doc, err := bson.MarshalExtJSON(cursor.Current, true, false)
+			if err != nil {
+				cursor.Close(s.ctx)
+				return fmt.Errorf("failed to marshal document from %s: %w", name, err)
+			}
+			state[fmt.Sprintf("%s/%s/%s", prefix, name, cursor.Current.Lookup("_id"))] = string(doc)
+		}
+		cursor.Close(s.ctx)
+	}
+	return nil
+}
+
+// canonicalJSON normaliza o JSON (ordem das chaves) para comparação; devolve o original se for inválido
+func canonicalJSON(raw []byte) string {
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return string(raw)
+	}
+	out, err := json.Marshal(value)
+	if err != nil {
+		return string(raw)
+	}
+	return string(out)
+}
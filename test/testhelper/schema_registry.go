@@ -0,0 +1,47 @@
+package testhelper
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/require"
+)
+
+// SchemaSubject retorna o subject com escopo no tenant da suite ("<tenant>-<subject>"); subjects com esse
+// prefixo são removidos por CleanSchemaRegistry
+func (s *IntegrationTestSuite) SchemaSubject(subject string) string {
+	return s.tenantID + "-" + subject
+}
+
+// RegisterAvroSchema registra um schema Avro no subject e retorna o ID do schema
+// O subject é removido definitivamente ao final do teste
+func (s *IntegrationTestSuite) RegisterAvroSchema(subject, schema string) int {
+	s.t.Helper()
+	return s.registerSchema(subject, SchemaTypeAvro, schema)
+}
+
+// RegisterProtobufSchema registra um schema Protobuf (.proto) no subject e retorna o ID do schema
+// O subject é removido definitivamente ao final do teste
+func (s *IntegrationTestSuite) RegisterProtobufSchema(subject, schema string) int {
+	s.t.Helper()
+	return s.registerSchema(subject, SchemaTypeProtobuf, schema)
+}
+
+// registerSchema registra o schema e agenda a remoção do subject
+func (s *IntegrationTestSuite) registerSchema(subject, schemaType, schema string) int {
+	s.t.Helper()
+	defer s.trackOperation("RegisterSchema")()
+
+	registry := s.SchemaRegistry()
+	require.NotNil(s.t, registry, "Schema Registry not configured, use WithSchemaRegistry()")
+
+	id, err := registry.RegisterSchema(s.ctx, subject, schemaType, schema)
+	require.NoError(s.t, err, "Failed to register %s schema for subject %s", schemaType, subject)
+
+	s.t.Cleanup(func() {
+		if err := registry.DeleteSubject(context.Background(), subject); err != nil {
+			s.t.Logf("⚠️ Failed to delete schema subject %s: %v", subject, err)
+		}
+	})
+
+	return id
+}
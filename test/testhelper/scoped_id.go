@@ -0,0 +1,109 @@
+package testhelper
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// IDs de documentos com escopo por tenant
+//
+// Testes que usam IDs constantes ("1", "sku-1") no índice compartilhado colidem quando pacotes rodam em
+// paralelo: o segundo Create sobrescreve o documento do primeiro. ScopedID deriva um ID único por
+// tenant da suite e UnscopedID desfaz a derivação nas asserções
+
+// ScopedIDScheme define como ScopedID deriva o ID do documento
+type ScopedIDScheme int32
+
+const (
+	// ScopedIDPrefix prefixa o ID com o tenant: "<tenant>-<id>" (legível nos logs e no Kibana)
+	ScopedIDPrefix ScopedIDScheme = iota
+	// ScopedIDHash usa o hash (sha1, 20 caracteres hex) de tenant + ID: tamanho fixo e sem o tenant
+	// exposto, para aplicações que validam o formato ou o tamanho dos IDs
+	ScopedIDHash
+)
+
+var (
+	scopedIDSchemeOverride atomic.Int32
+	scopedIDSchemeSet      atomic.Bool
+
+	// scopedIDHashes guarda hash -> ID original para o UnscopedID no esquema ScopedIDHash
+	scopedIDHashes sync.Map
+)
+
+// SetScopedIDScheme define o esquema de ScopedID para o processo (padrão: TEST_SCOPED_ID_SCHEME ou prefix)
+func SetScopedIDScheme(scheme ScopedIDScheme) {
+	scopedIDSchemeOverride.Store(int32(scheme))
+	scopedIDSchemeSet.Store(true)
+}
+
+// scopedIDScheme retorna o esquema configurado (SetScopedIDScheme, TEST_SCOPED_ID_SCHEME=prefix|hash)
+func scopedIDScheme() ScopedIDScheme {
+	if scopedIDSchemeSet.Load() {
+		return ScopedIDScheme(scopedIDSchemeOverride.Load())
+	}
+	if strings.EqualFold(os.Getenv("TEST_SCOPED_ID_SCHEME"), "hash") {
+		return ScopedIDHash
+	}
+	return ScopedIDPrefix
+}
+
+// ScopedID retorna o ID do documento com escopo no tenant da suite; o mesmo rawID sempre gera o mesmo ID
+// dentro da suite e IDs diferentes entre suites
+func ScopedID(suite *IntegrationTestSuite, rawID string) string {
+	return ScopedIDForTenant(suite.TenantID(), rawID)
+}
+
+// ScopedIDs aplica ScopedID a cada ID
+func ScopedIDs(suite *IntegrationTestSuite, rawIDs ...string) []string {
+	ids := make([]string, len(rawIDs))
+	for i, rawID := range rawIDs {
+		ids[i] = ScopedID(suite, rawID)
+	}
+	return ids
+}
+
+// ScopedIDForTenant é o ScopedID para outro tenant (ex.: subtestes com suite.NewTenantID())
+func ScopedIDForTenant(tenantID, rawID string) string {
+	if scopedIDScheme() == ScopedIDHash {
+		sum := sha1.Sum([]byte(tenantID + "\x00" + rawID))
+		id := hex.EncodeToString(sum[:])[:20]
+		scopedIDHashes.Store(id, scopedIDOrigin{tenantID: tenantID, rawID: rawID})
+		return id
+	}
+	return tenantID + "-" + rawID
+}
+
+// UnscopedID desfaz o ScopedID da suite; IDs que não foram gerados por ela são retornados sem alteração
+func UnscopedID(suite *IntegrationTestSuite, id string) string {
+	return UnscopedIDForTenant(suite.TenantID(), id)
+}
+
+// UnscopedIDs aplica UnscopedID a cada ID
+func UnscopedIDs(suite *IntegrationTestSuite, ids ...string) []string {
+	rawIDs := make([]string, len(ids))
+	for i, id := range ids {
+		rawIDs[i] = UnscopedID(suite, id)
+	}
+	return rawIDs
+}
+
+// UnscopedIDForTenant desfaz o ScopedIDForTenant do tenant
+func UnscopedIDForTenant(tenantID, id string) string {
+	if origin, ok := scopedIDHashes.Load(id); ok && origin.(scopedIDOrigin).tenantID == tenantID {
+		return origin.(scopedIDOrigin).rawID
+	}
+	if rawID, ok := strings.CutPrefix(id, tenantID+"-"); ok {
+		return rawID
+	}
+	return id
+}
+
+// scopedIDOrigin é o tenant e o ID original de um ID no esquema ScopedIDHash
+type scopedIDOrigin struct {
+	tenantID string
+	rawID    string
+}
@@ -0,0 +1,73 @@
+package testhelper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScopedID(t *testing.T) {
+	suite := &IntegrationTestSuite{tenantID: "tenant-a"}
+
+	t.Run("Prefix scheme", func(t *testing.T) {
+		useScopedIDScheme(t, ScopedIDPrefix)
+
+		tests := []struct {
+			name     string
+			rawID    string
+			scopedID string
+		}{
+			{name: "Plain ID", rawID: "p1", scopedID: "tenant-a-p1"},
+			{name: "Empty ID", rawID: "", scopedID: "tenant-a-"},
+			{name: "Already scoped ID is scoped again", rawID: "tenant-a-p1", scopedID: "tenant-a-tenant-a-p1"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				id := ScopedID(suite, tt.rawID)
+
+				assert.Equal(t, tt.scopedID, id)
+				assert.Equal(t, tt.rawID, UnscopedID(suite, id), "UnscopedID must undo exactly one ScopedID")
+			})
+		}
+	})
+
+	t.Run("Hash scheme", func(t *testing.T) {
+		useScopedIDScheme(t, ScopedIDHash)
+
+		id := ScopedID(suite, "p1")
+		assert.Len(t, id, 20)
+		assert.NotContains(t, id, "tenant-a")
+		assert.Equal(t, id, ScopedID(suite, "p1"), "the same raw ID must map to the same ID")
+		assert.NotEqual(t, id, ScopedIDForTenant("tenant-b", "p1"), "tenants must not share IDs")
+		assert.Equal(t, "p1", UnscopedID(suite, id))
+		assert.Equal(t, id, UnscopedIDForTenant("tenant-b", id), "another tenant must not unscope the ID")
+
+		rescoped := ScopedID(suite, id)
+		assert.Equal(t, id, UnscopedID(suite, rescoped), "already scoped ID unscopes one level")
+	})
+
+	t.Run("IDs not generated for the tenant are returned unchanged", func(t *testing.T) {
+		useScopedIDScheme(t, ScopedIDPrefix)
+
+		for _, id := range []string{"", "p1", "tenant-b-p1", "tenant-a"} {
+			assert.Equal(t, id, UnscopedID(suite, id))
+		}
+	})
+
+	t.Run("Slices", func(t *testing.T) {
+		useScopedIDScheme(t, ScopedIDPrefix)
+
+		ids := ScopedIDs(suite, "p1", "p2")
+		assert.Equal(t, []string{"tenant-a-p1", "tenant-a-p2"}, ids)
+		assert.Equal(t, []string{"p1", "p2"}, UnscopedIDs(suite, ids...))
+		assert.Empty(t, ScopedIDs(suite))
+	})
+}
+
+// useScopedIDScheme define o esquema durante o teste e volta ao padrão do ambiente no fim
+func useScopedIDScheme(t *testing.T, scheme ScopedIDScheme) {
+	t.Helper()
+
+	SetScopedIDScheme(scheme)
+	t.Cleanup(func() { scopedIDSchemeSet.Store(false) })
+}
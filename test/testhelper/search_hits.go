@@ -0,0 +1,147 @@
+package testhelper
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"github.com/stretchr/testify/require"
+)
+
+// maxPercolateHits é o número máximo de queries retornadas por Percolate
+const maxPercolateHits = 1000
+
+// SearchHit é um hit com os metadados além do _source
+type SearchHit struct {
+	Index  string
+	ID     string
+	Score  float64 // zero quando o ES não calcula score (ex.: sort sem track_scores)
+	Sort   []interface{}
+	Source map[string]interface{}
+	// Highlight são os fragmentos por campo (query com "highlight")
+	Highlight map[string][]string
+	// InnerHits são os inner hits por nome (nested, has_child, collapse); cada um é um SearchResult
+	InnerHits map[string]*SearchResult
+	// MatchedQueries são os nomes (_name) das cláusulas que casaram com o hit
+	MatchedQueries []string
+	// PercolatorSlots são as posições dos documentos de Percolate que casaram com a query armazenada
+	PercolatorSlots []int
+}
+
+// Hits retorna os hits com metadados, na ordem do resultado
+func (r *SearchResult) Hits() []SearchHit {
+	raw := r.hits()
+	hits := make([]SearchHit, 0, len(raw))
+	for _, hit := range raw {
+		parsed := SearchHit{}
+		parsed.Index, _ = hit["_index"].(string)
+		parsed.ID, _ = hit["_id"].(string)
+		parsed.Score, _ = hit["_score"].(float64)
+		parsed.Sort, _ = hit["sort"].([]interface{})
+		parsed.Source, _ = hit["_source"].(map[string]interface{})
+
+		if highlight, ok := hit["highlight"].(map[string]interface{}); ok {
+			parsed.Highlight = make(map[string][]string, len(highlight))
+			for field, fragments := range highlight {
+				parsed.Highlight[field] = stringList(fragments)
+			}
+		}
+
+		if inner, ok := hit["inner_hits"].(map[string]interface{}); ok {
+			parsed.InnerHits = make(map[string]*SearchResult, len(inner))
+			for name, response := range inner {
+				response, _ := response.(map[string]interface{})
+				parsed.InnerHits[name] = &SearchResult{response: response}
+			}
+		}
+
+		parsed.MatchedQueries = stringList(hit["matched_queries"])
+
+		if fields, ok := hit["fields"].(map[string]interface{}); ok {
+			slots, _ := fields["_percolator_document_slot"].([]interface{})
+			for _, slot := range slots {
+				if value, ok := slot.(float64); ok {
+					parsed.PercolatorSlots = append(parsed.PercolatorSlots, int(value))
+				}
+			}
+		}
+
+		hits = append(hits, parsed)
+	}
+	return hits
+}
+
+// Highlights retorna os fragmentos destacados por _id e campo
+func (r *SearchResult) Highlights() map[string]map[string][]string {
+	highlights := map[string]map[string][]string{}
+	for _, hit := range r.Hits() {
+		if len(hit.Highlight) > 0 {
+			highlights[hit.ID] = hit.Highlight
+		}
+	}
+	return highlights
+}
+
+// Percolate busca no índice as queries armazenadas no campo percolator que casam com os documentos
+// e retorna os hits (as queries), com PercolatorSlots indicando quais documentos casaram com cada uma
+func (s *IntegrationTestSuite) Percolate(index, field string, documents ...map[string]interface{}) *SearchResult {
+	s.t.Helper()
+	defer s.trackOperation("Percolate")()
+
+	client := s.ES()
+	require.NotNil(s.t, client, "Elasticsearch not configured")
+	require.NotEmpty(s.t, documents, "Percolate requires at least one document")
+	index = s.RunScopedIndex(index)
+
+	body := map[string]interface{}{
+		"query": map[string]interface{}{
+			"percolate": map[string]interface{}{"field": field, "documents": documents},
+		},
+		"size": maxPercolateHits,
+	}
+	bodyJSON, err := json.Marshal(body)
+	require.NoError(s.t, err, "Failed to marshal percolate query")
+
+	// As queries recém-indexadas só são consideradas após o refresh
+	s.refreshIndex(index)
+
+	res, err := esapi.SearchRequest{
+		Index: []string{index},
+		Body:  strings.NewReader(string(bodyJSON)),
+	}.Do(s.ctx, client)
+	require.NoError(s.t, err, "Failed to percolate on %s", index)
+	defer res.Body.Close()
+
+	if res.IsError() {
+		require.Fail(s.t, fmt.Sprintf("Failed to percolate on %s: %s", index, res.String()))
+	}
+
+	var response map[string]interface{}
+	require.NoError(s.t, json.NewDecoder(res.Body).Decode(&response), "Failed to decode percolate response")
+	return &SearchResult{response: response}
+}
+
+// AssertPercolates verifica que as queries armazenadas (por _id) que casam com o documento são
+// exatamente as esperadas, em qualquer ordem
+func (s *IntegrationTestSuite) AssertPercolates(index, field string, document map[string]interface{}, expectedIDs ...string) {
+	s.t.Helper()
+
+	result := s.Percolate(index, field, document)
+	require.ElementsMatch(s.t, expectedIDs, result.IDs(), "Unexpected queries matching document on %s", index)
+}
+
+// stringList converte uma lista JSON de strings, ignorando valores de outros tipos
+func stringList(value interface{}) []string {
+	list, _ := value.([]interface{})
+	if len(list) == 0 {
+		return nil
+	}
+	result := make([]string, 0, len(list))
+	for _, item := range list {
+		if text, ok := item.(string); ok {
+			result = append(result, text)
+		}
+	}
+	return result
+}
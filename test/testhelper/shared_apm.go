@@ -0,0 +1,271 @@
+package testhelper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// APMIndexPatterns são os padrões dos índices/data streams em que o apm-server grava os eventos
+var APMIndexPatterns = []string{"traces-apm*", "metrics-apm*", "logs-apm*"}
+
+var (
+	sharedAPMServer *SharedAPMServer
+	apmServerOnce   sync.Once
+)
+
+// SharedAPMServer gerencia um container apm-server apontando para o Elasticsearch compartilhado
+type SharedAPMServer struct {
+	mu         sync.RWMutex
+	container  testcontainers.Container
+	url        string
+	httpClient *http.Client
+	refCount   int32
+	startOnce  sync.Once
+	startErr   error
+	started    bool
+}
+
+// GetSharedAPMServer retorna a instância singleton do apm-server compartilhado
+func GetSharedAPMServer() *SharedAPMServer {
+	apmServerOnce.Do(func() {
+		sharedAPMServer = &SharedAPMServer{
+			httpClient: &http.Client{Timeout: 10 * time.Second},
+		}
+	})
+	return sharedAPMServer
+}
+
+// Start inicializa o container apm-server apontando para o Elasticsearch compartilhado (já iniciado)
+func (s *SharedAPMServer) Start(ctx context.Context, es *SharedElasticsearch) error {
+	// Primeiro, tenta reutilizar container existente (sem lock global)
+	s.mu.RLock()
+	if s.started && s.url != "" {
+		s.mu.RUnlock()
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+	} else {
+		s.mu.RUnlock()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Double-check: outro goroutine pode ter criado enquanto aguardava lock
+	if s.started && s.url != "" {
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+		// Conexão perdida, reset para tentar novamente
+		s.started = false
+		s.startOnce = sync.Once{}
+	}
+
+	s.startOnce.Do(func() {
+		s.startErr = s.startContainer(ctx, es)
+		if s.startErr == nil {
+			s.started = true
+		}
+	})
+
+	if !s.started {
+		return fmt.Errorf("shared apm server not started: %w", s.startErr)
+	}
+
+	atomic.AddInt32(&s.refCount, 1)
+	return nil
+}
+
+// Stop decrementa o contador de referências e para o container se necessário
+func (s *SharedAPMServer) Stop(ctx context.Context) error {
+	if atomic.AddInt32(&s.refCount, -1) <= 0 {
+		return s.stopContainer(ctx)
+	}
+	return nil
+}
+
+// IntakeURL retorna a URL do apm-server (ELASTIC_APM_SERVER_URL dos agentes)
+func (s *SharedAPMServer) IntakeURL() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.url
+}
+
+// startContainer inicia o container apm-server ou usa um externo
+func (s *SharedAPMServer) startContainer(ctx context.Context, es *SharedElasticsearch) error {
+	// Verifica se deve usar apm-server externo
+	if useExternal, _ := strconv.ParseBool(os.Getenv("USE_EXTERNAL_APM_SERVER")); useExternal {
+		s.url = strings.TrimRight(envOrDefault("APM_SERVER_URL", "http://localhost:8200"), "/")
+
+		if err := s.testConnection(ctx); err != nil {
+			return externalFallback("apm server", "USE_EXTERNAL_APM_SERVER", s.url, err, func() error {
+				return s.setupTestcontainer(ctx, es)
+			})
+		}
+
+		if isDebugEnabled() {
+			fmt.Printf("✅ Using external APM Server at %s\n", s.url)
+		}
+		return nil
+	}
+
+	return s.setupTestcontainer(ctx, es)
+}
+
+// setupTestcontainer cria e inicia um container apm-server da mesma versão do Elasticsearch
+func (s *SharedAPMServer) setupTestcontainer(ctx context.Context, es *SharedElasticsearch) error {
+	if isDebugEnabled() {
+		fmt.Println("🚀 Starting shared APM Server container...")
+	}
+
+	esHost, hostPorts, err := elasticsearchHostForContainer(ctx, es)
+	if err != nil {
+		return err
+	}
+
+	req := testcontainers.ContainerRequest{
+		Image:        "docker.elastic.co/apm/apm-server:8.2.0",
+		ExposedPorts: []string{"8200/tcp"},
+		Name:         "shared-apm-server-test",
+		Cmd: []string{
+			"apm-server", "-e",
+			"-E", "apm-server.host=0.0.0.0:8200",
+			"-E", `output.elasticsearch.hosts=["` + esHost + `"]`,
+			// Sem buffer: os eventos chegam ao ES logo após o flush do agente
+			"-E", "output.elasticsearch.bulk_max_size=1",
+			"-E", "queue.mem.flush.timeout=0s",
+		},
+		HostAccessPorts: hostPorts,
+		WaitingFor:      wait.ForHTTP("/").WithPort("8200/tcp").WithStartupTimeout(2 * time.Minute),
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+		Reuse:            shouldReuseContainer(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start apm server container: %w", err)
+	}
+
+	endpoint, err := container.PortEndpoint(ctx, "8200/tcp", "http")
+	if err != nil {
+		return fmt.Errorf("failed to get apm server endpoint: %w", err)
+	}
+
+	s.container = container
+	s.url = endpoint
+
+	if isDebugEnabled() {
+		fmt.Printf("✅ Shared APM Server container started at %s (elasticsearch %s)\n", s.url, esHost)
+	}
+
+	log.Printf("✅ Shared APM Server container started at %s", s.url)
+
+	return nil
+}
+
+// stopContainer para o container se não estiver sendo reutilizado
+func (s *SharedAPMServer) stopContainer(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.container != nil && !shouldReuseContainer() {
+		if isDebugEnabled() {
+			fmt.Println("🛑 Stopping shared APM Server container...")
+		}
+		return s.container.Terminate(ctx)
+	}
+
+	return nil
+}
+
+// testConnection verifica se o apm-server responde
+func (s *SharedAPMServer) testConnection(ctx context.Context) error {
+	ctxPing, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if s.url == "" {
+		return fmt.Errorf("apm server url not available")
+	}
+
+	req, err := http.NewRequestWithContext(ctxPing, http.MethodGet, s.url+"/", nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("apm server error: %s", res.Status)
+	}
+	return nil
+}
+
+// deleteAPMData remove os data streams e índices de eventos APM (ficam fora do CleanIndices por serem
+// data streams com backing indices ".ds-*"). Os nomes são resolvidos antes: o ES 8 recusa remoções com
+// wildcard (action.destructive_requires_name)
+func deleteAPMData(ctx context.Context, client *elasticsearch.Client) error {
+	res, err := client.Indices.GetDataStream(
+		client.Indices.GetDataStream.WithContext(ctx),
+		client.Indices.GetDataStream.WithName(APMIndexPatterns...),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to list apm data streams: %w", err)
+	}
+	defer res.Body.Close()
+	if res.IsError() && res.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("failed to list apm data streams: %s", res.String())
+	}
+
+	var streams struct {
+		DataStreams []struct {
+			Name string `json:"name"`
+		} `json:"data_streams"`
+	}
+	if !res.IsError() {
+		if err := json.NewDecoder(res.Body).Decode(&streams); err != nil {
+			return fmt.Errorf("failed to decode apm data streams: %w", err)
+		}
+	}
+
+	names := make([]string, 0, len(streams.DataStreams))
+	for _, stream := range streams.DataStreams {
+		names = append(names, stream.Name)
+	}
+	if len(names) > 0 {
+		del, err := client.Indices.DeleteDataStream(names, client.Indices.DeleteDataStream.WithContext(ctx))
+		if err != nil {
+			return fmt.Errorf("failed to delete apm data streams: %w", err)
+		}
+		defer del.Body.Close()
+		if del.IsError() && del.StatusCode != http.StatusNotFound {
+			return fmt.Errorf("failed to delete apm data streams: %s", del.String())
+		}
+	}
+
+	// Índices comuns com os mesmos padrões (ex.: criados antes do index template do APM)
+	if _, err := deleteIndicesMatching(ctx, client, strings.Join(APMIndexPatterns, ",")); err != nil {
+		return fmt.Errorf("failed to delete apm indices: %w", err)
+	}
+	return nil
+}
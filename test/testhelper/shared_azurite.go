@@ -0,0 +1,520 @@
+package testhelper
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+var (
+	sharedAzurite *SharedAzurite
+	azuriteOnce   sync.Once
+)
+
+// Conta de desenvolvimento fixa do Azurite (documentada pela Microsoft)
+const (
+	azuriteAccountName = "devstoreaccount1"
+	azuriteAccountKey  = "Eby8vdM02xNOcqFlqUwJPLlmEtlCDXJ1OUzFT50uSRZ6IFsuFq2UVErCz4I6tq/K1SZFPTOtr/KBHBeksoGMGw=="
+	azuriteAPIVersion  = "2021-08-06"
+)
+
+// blobContainerNameInvalid casa caracteres não permitidos em nomes de blob containers
+var blobContainerNameInvalid = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// SharedAzurite gerencia um container Azurite (emulador do Azure Blob Storage) compartilhado entre testes
+// A comunicação usa a API REST do Blob Storage com autenticação SharedKey
+type SharedAzurite struct {
+	mu           sync.RWMutex
+	container    testcontainers.Container
+	blobEndpoint string
+	accountName  string
+	accountKey   string
+	httpClient   *http.Client
+	refCount     int32
+	startOnce    sync.Once
+	startErr     error
+	started      bool
+}
+
+// GetSharedAzurite retorna a instância singleton do Azurite compartilhado
+func GetSharedAzurite() *SharedAzurite {
+	azuriteOnce.Do(func() {
+		sharedAzurite = &SharedAzurite{
+			accountName: azuriteAccountName,
+			accountKey:  azuriteAccountKey,
+			httpClient:  &http.Client{Timeout: 30 * time.Second},
+		}
+	})
+	return sharedAzurite
+}
+
+// Start inicializa o container Azurite compartilhado
+func (s *SharedAzurite) Start(ctx context.Context) error {
+	// Primeiro, tenta reutilizar container existente (sem lock global)
+	s.mu.RLock()
+	if s.started && s.blobEndpoint != "" {
+		s.mu.RUnlock()
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+	} else {
+		s.mu.RUnlock()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Double-check: outro goroutine pode ter criado enquanto aguardava lock
+	if s.started && s.blobEndpoint != "" {
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+		// Conexão perdida, reset para tentar novamente
+		s.started = false
+		s.startOnce = sync.Once{}
+	}
+
+	s.startOnce.Do(func() {
+		s.startErr = s.startContainer(ctx)
+		if s.startErr == nil {
+			s.started = true
+		}
+	})
+
+	if !s.started {
+		return fmt.Errorf("shared azurite not started: %w", s.startErr)
+	}
+
+	atomic.AddInt32(&s.refCount, 1)
+	return nil
+}
+
+// Stop decrementa o contador de referências e para o container se necessário
+func (s *SharedAzurite) Stop(ctx context.Context) error {
+	if atomic.AddInt32(&s.refCount, -1) <= 0 {
+		return s.stopContainer(ctx)
+	}
+	return nil
+}
+
+// GetBlobEndpoint retorna o endpoint do Blob Storage (inclui o nome da conta)
+func (s *SharedAzurite) GetBlobEndpoint() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.blobEndpoint
+}
+
+// GetConnectionString retorna a connection string aceita pelos SDKs do Azure
+func (s *SharedAzurite) GetConnectionString() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	protocol := "http"
+	if strings.HasPrefix(s.blobEndpoint, "https://") {
+		protocol = "https"
+	}
+	return fmt.Sprintf("DefaultEndpointsProtocol=%s;AccountName=%s;AccountKey=%s;BlobEndpoint=%s;",
+		protocol, s.accountName, s.accountKey, s.blobEndpoint)
+}
+
+// startContainer inicia o container Azurite ou usa um externo
+func (s *SharedAzurite) startContainer(ctx context.Context) error {
+	// Verifica se deve usar Azurite externo
+	if useExternal, _ := strconv.ParseBool(os.Getenv("USE_EXTERNAL_AZURITE")); useExternal {
+		endpoint := os.Getenv("AZURITE_BLOB_ENDPOINT")
+		if endpoint == "" {
+			endpoint = "http://localhost:10000/" + azuriteAccountName
+		}
+		s.blobEndpoint = strings.TrimRight(endpoint, "/")
+
+		if err := s.testConnection(ctx); err != nil {
+			return externalFallback("azurite", "USE_EXTERNAL_AZURITE", s.blobEndpoint, err, func() error {
+				return s.setupTestcontainer(ctx)
+			})
+		}
+
+		if isDebugEnabled() {
+			fmt.Printf("✅ Using external Azurite at %s\n", s.blobEndpoint)
+		}
+		return nil
+	}
+
+	return s.setupTestcontainer(ctx)
+}
+
+// setupTestcontainer cria e inicia um container Azurite (apenas o serviço de blobs)
+func (s *SharedAzurite) setupTestcontainer(ctx context.Context) error {
+	if isDebugEnabled() {
+		fmt.Println("🚀 Starting shared Azurite container...")
+	}
+
+	req := testcontainers.ContainerRequest{
+		Image:        "mcr.microsoft.com/azure-storage/azurite:3.29.0",
+		ExposedPorts: []string{"10000/tcp"},
+		Name:         "shared-azurite-test",
+		Cmd:          []string{"azurite-blob", "--blobHost", "0.0.0.0", "--blobPort", "10000", "--skipApiVersionCheck"},
+		WaitingFor:   wait.ForListeningPort("10000/tcp").WithStartupTimeout(30 * time.Second),
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+		Reuse:            shouldReuseContainer(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start azurite container: %w", err)
+	}
+
+	endpoint, err := container.PortEndpoint(ctx, "10000/tcp", "http")
+	if err != nil {
+		return fmt.Errorf("failed to get azurite endpoint: %w", err)
+	}
+
+	s.container = container
+	s.blobEndpoint = endpoint + "/" + s.accountName
+
+	if err := s.testConnection(ctx); err != nil {
+		return fmt.Errorf("failed to connect to azurite: %w", err)
+	}
+
+	if isDebugEnabled() {
+		fmt.Printf("✅ Shared Azurite container started at %s\n", s.blobEndpoint)
+	}
+
+	log.Printf("✅ Shared Azurite container started at %s", s.blobEndpoint)
+
+	return nil
+}
+
+// stopContainer para o container se não estiver sendo reutilizado
+func (s *SharedAzurite) stopContainer(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.container != nil && !shouldReuseContainer() {
+		if isDebugEnabled() {
+			fmt.Println("🛑 Stopping shared Azurite container...")
+		}
+		return s.container.Terminate(ctx)
+	}
+
+	return nil
+}
+
+// TenantContainerName deriva um nome de blob container válido para o tenant (e sufixo opcional)
+// Ex.: ("test_ab12", "uploads") -> "test-ab12-uploads"
+func TenantContainerName(tenantID, suffix string) string {
+	name := strings.ToLower(tenantID)
+	if suffix != "" {
+		name += "-" + strings.ToLower(suffix)
+	}
+	name = blobContainerNameInvalid.ReplaceAllString(name, "-")
+	for strings.Contains(name, "--") {
+		name = strings.ReplaceAll(name, "--", "-")
+	}
+	name = strings.Trim(name, "-")
+	if len(name) > 63 {
+		name = strings.TrimRight(name[:63], "-")
+	}
+	for len(name) < 3 {
+		name += "0"
+	}
+	return name
+}
+
+// CreateContainer cria um blob container (ignora se já existir)
+func (s *SharedAzurite) CreateContainer(ctx context.Context, name string) error {
+	status, _, err := s.request(ctx, http.MethodPut, "/"+name, url.Values{"restype": {"container"}}, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create container %s: %w", name, err)
+	}
+	if status != http.StatusCreated && status != http.StatusConflict {
+		return fmt.Errorf("failed to create container %s: status %d", name, status)
+	}
+	return nil
+}
+
+// DeleteContainer remove um blob container e todos os seus blobs (ignora se não existir)
+func (s *SharedAzurite) DeleteContainer(ctx context.Context, name string) error {
+	status, _, err := s.request(ctx, http.MethodDelete, "/"+name, url.Values{"restype": {"container"}}, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete container %s: %w", name, err)
+	}
+	if status != http.StatusAccepted && status != http.StatusNotFound {
+		return fmt.Errorf("failed to delete container %s: status %d", name, status)
+	}
+	return nil
+}
+
+// ListContainers retorna os blob containers cujo nome começa com o prefixo
+func (s *SharedAzurite) ListContainers(ctx context.Context, prefix string) ([]string, error) {
+	var names []string
+	marker := ""
+	for {
+		query := url.Values{"comp": {"list"}}
+		if prefix != "" {
+			query.Set("prefix", prefix)
+		}
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+
+		status, body, err := s.request(ctx, http.MethodGet, "/", query, nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list containers: %w", err)
+		}
+		if status != http.StatusOK {
+			return nil, fmt.Errorf("failed to list containers: status %d", status)
+		}
+
+		var result struct {
+			Containers []struct {
+				Name string `xml:"Name"`
+			} `xml:"Containers>Container"`
+			NextMarker string `xml:"NextMarker"`
+		}
+		if err := xml.Unmarshal(body, &result); err != nil {
+			return nil, fmt.Errorf("failed to decode container list: %w", err)
+		}
+
+		for _, c := range result.Containers {
+			names = append(names, c.Name)
+		}
+		if result.NextMarker == "" {
+			return names, nil
+		}
+		marker = result.NextMarker
+	}
+}
+
+// PutBlob grava um block blob no container
+func (s *SharedAzurite) PutBlob(ctx context.Context, container, blob string, data []byte, contentType string) error {
+	headers := map[string]string{"x-ms-blob-type": "BlockBlob"}
+	if contentType != "" {
+		headers["Content-Type"] = contentType
+	}
+
+	status, _, err := s.request(ctx, http.MethodPut, "/"+container+"/"+blob, nil, headers, data)
+	if err != nil {
+		return fmt.Errorf("failed to put blob %s/%s: %w", container, blob, err)
+	}
+	if status != http.StatusCreated {
+		return fmt.Errorf("failed to put blob %s/%s: status %d", container, blob, status)
+	}
+	return nil
+}
+
+// GetBlob lê o conteúdo de um blob
+func (s *SharedAzurite) GetBlob(ctx context.Context, container, blob string) ([]byte, error) {
+	status, body, err := s.request(ctx, http.MethodGet, "/"+container+"/"+blob, nil, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blob %s/%s: %w", container, blob, err)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("failed to get blob %s/%s: status %d", container, blob, status)
+	}
+	return body, nil
+}
+
+// ListBlobs retorna os nomes dos blobs do container
+func (s *SharedAzurite) ListBlobs(ctx context.Context, container string) ([]string, error) {
+	query := url.Values{"restype": {"container"}, "comp": {"list"}}
+
+	status, body, err := s.request(ctx, http.MethodGet, "/"+container, query, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list blobs of %s: %w", container, err)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("failed to list blobs of %s: status %d", container, status)
+	}
+
+	var result struct {
+		Blobs []struct {
+			Name string `xml:"Name"`
+		} `xml:"Blobs>Blob"`
+	}
+	if err := xml.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode blob list: %w", err)
+	}
+
+	names := make([]string, 0, len(result.Blobs))
+	for _, b := range result.Blobs {
+		names = append(names, b.Name)
+	}
+	return names, nil
+}
+
+// DeleteContainers remove todos os blob containers com o prefixo ("" remove todos)
+func (s *SharedAzurite) DeleteContainers(ctx context.Context, prefix string) error {
+	names, err := s.ListContainers(ctx, prefix)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if err := s.DeleteContainer(ctx, name); err != nil {
+			return err
+		}
+	}
+
+	if isDebugEnabled() && len(names) > 0 {
+		fmt.Printf("🧹 Deleted %d Azurite blob container(s)\n", len(names))
+	}
+	return nil
+}
+
+// DeleteAllContainers remove todos os blob containers
+func (s *SharedAzurite) DeleteAllContainers(ctx context.Context) error {
+	return s.DeleteContainers(ctx, "")
+}
+
+// request executa uma chamada assinada à API REST do Blob Storage
+func (s *SharedAzurite) request(ctx context.Context, method, path string, query url.Values, headers map[string]string, body []byte) (int, []byte, error) {
+	s.mu.RLock()
+	endpoint, account, key := s.blobEndpoint, s.accountName, s.accountKey
+	s.mu.RUnlock()
+
+	return azuriteRequest(ctx, s.httpClient, endpoint, account, key, method, path, query, headers, body)
+}
+
+// azuriteRequest executa a chamada no endpoint informado (sem lock, usado durante o Start)
+func azuriteRequest(ctx context.Context, client *http.Client, endpoint, account, key, method, path string, query url.Values, headers map[string]string, body []byte) (int, []byte, error) {
+	if endpoint == "" {
+		return 0, nil, fmt.Errorf("azurite endpoint not available")
+	}
+
+	target := endpoint + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, err
+	}
+	req.ContentLength = int64(len(body))
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("x-ms-version", azuriteAPIVersion)
+
+	signature, err := sharedKeySignature(req, account, key)
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Authorization", "SharedKey "+account+":"+signature)
+
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer res.Body.Close()
+
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if res.StatusCode >= 400 && res.StatusCode != http.StatusNotFound && res.StatusCode != http.StatusConflict {
+		return res.StatusCode, raw, fmt.Errorf("azurite error: %s %s", res.Status, strings.TrimSpace(string(raw)))
+	}
+	return res.StatusCode, raw, nil
+}
+
+// sharedKeySignature assina a requisição no formato SharedKey do Azure Storage
+func sharedKeySignature(req *http.Request, account, key string) (string, error) {
+	contentLength := ""
+	if req.ContentLength > 0 {
+		contentLength = strconv.FormatInt(req.ContentLength, 10)
+	}
+
+	var xmsHeaders []string
+	for name := range req.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-ms-") {
+			xmsHeaders = append(xmsHeaders, lower)
+		}
+	}
+	sort.Strings(xmsHeaders)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range xmsHeaders {
+		fmt.Fprintf(&canonicalHeaders, "%s:%s\n", name, strings.TrimSpace(req.Header.Get(name)))
+	}
+
+	// No emulador o nome da conta também faz parte do path
+	canonicalResource := "/" + account + req.URL.EscapedPath()
+	query := req.URL.Query()
+	params := make([]string, 0, len(query))
+	for name := range query {
+		params = append(params, name)
+	}
+	sort.Strings(params)
+	for _, name := range params {
+		values := append([]string(nil), query[name]...)
+		sort.Strings(values)
+		canonicalResource += "\n" + strings.ToLower(name) + ":" + strings.Join(values, ",")
+	}
+
+	stringToSign := strings.Join([]string{
+		req.Method,
+		req.Header.Get("Content-Encoding"),
+		req.Header.Get("Content-Language"),
+		contentLength,
+		req.Header.Get("Content-MD5"),
+		req.Header.Get("Content-Type"),
+		"", // Date (usamos x-ms-date)
+		req.Header.Get("If-Modified-Since"),
+		req.Header.Get("If-Match"),
+		req.Header.Get("If-None-Match"),
+		req.Header.Get("If-Unmodified-Since"),
+		req.Header.Get("Range"),
+	}, "\n") + "\n" + canonicalHeaders.String() + canonicalResource
+
+	decodedKey, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return "", fmt.Errorf("invalid azurite account key: %w", err)
+	}
+
+	mac := hmac.New(sha256.New, decodedKey)
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// testConnection verifica se o serviço de blobs responde e aceita as credenciais
+func (s *SharedAzurite) testConnection(ctx context.Context) error {
+	ctxPing, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	status, _, err := azuriteRequest(ctxPing, s.httpClient, s.blobEndpoint, s.accountName, s.accountKey,
+		http.MethodGet, "/", url.Values{"comp": {"list"}, "maxresults": {"1"}}, nil, nil)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("unexpected azurite status %d", status)
+	}
+	return nil
+}
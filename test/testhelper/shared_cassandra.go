@@ -0,0 +1,427 @@
+package testhelper
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	tcexec "github.com/testcontainers/testcontainers-go/exec"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+var (
+	sharedCassandra *SharedCassandra
+	cassandraOnce   sync.Once
+)
+
+// invalidKeyspaceChars remove caracteres não aceitos em nomes de keyspace
+var invalidKeyspaceChars = regexp.MustCompile(`[^a-z0-9_]`)
+
+// SharedCassandra gerencia um container Cassandra compartilhado entre testes
+// O isolamento é feito com um keyspace por tenant ID
+type SharedCassandra struct {
+	mu           sync.RWMutex
+	container    testcontainers.Container
+	host         string
+	port         string
+	refCount     int32
+	startOnce    sync.Once
+	startErr     error
+	started      bool
+	cqlFilePaths []string
+	keyspaces    map[string]bool
+}
+
+// GetSharedCassandra retorna a instância singleton do Cassandra compartilhado
+func GetSharedCassandra() *SharedCassandra {
+	cassandraOnce.Do(func() {
+		sharedCassandra = &SharedCassandra{
+			keyspaces: make(map[string]bool),
+		}
+	})
+	return sharedCassandra
+}
+
+// Start inicializa o container Cassandra compartilhado
+// Os arquivos CQL informados são executados em cada keyspace criado via CreateKeyspace
+func (s *SharedCassandra) Start(ctx context.Context, cqlFilePaths ...string) error {
+	// Primeiro, tenta reutilizar container existente (sem lock global)
+	s.mu.RLock()
+	if s.started {
+		s.mu.RUnlock()
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+	} else {
+		s.mu.RUnlock()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Double-check: outro goroutine pode ter criado enquanto aguardava lock
+	if s.started {
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+		// Conexão perdida, reset para tentar novamente
+		s.started = false
+		s.startOnce = sync.Once{}
+		s.keyspaces = make(map[string]bool)
+	}
+
+	s.cqlFilePaths = cqlFilePaths
+
+	s.startOnce.Do(func() {
+		s.startErr = s.startContainer(ctx)
+		if s.startErr == nil {
+			s.started = true
+		}
+	})
+
+	if !s.started {
+		return fmt.Errorf("shared cassandra not started: %w", s.startErr)
+	}
+
+	atomic.AddInt32(&s.refCount, 1)
+	return nil
+}
+
+// Stop decrementa o contador de referências e para o container se necessário
+func (s *SharedCassandra) Stop(ctx context.Context) error {
+	if atomic.AddInt32(&s.refCount, -1) <= 0 {
+		return s.stopContainer(ctx)
+	}
+	return nil
+}
+
+// GetContactPoint retorna o endereço host:porta para drivers CQL
+func (s *SharedCassandra) GetContactPoint() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return fmt.Sprintf("%s:%s", s.host, s.port)
+}
+
+// startContainer inicia o container Cassandra ou usa um externo
+func (s *SharedCassandra) startContainer(ctx context.Context) error {
+	// Verifica se deve usar Cassandra externo
+	if useExternal, _ := strconv.ParseBool(os.Getenv("USE_EXTERNAL_CASSANDRA")); useExternal {
+		return s.setupExternalCassandra(ctx)
+	}
+
+	return s.setupTestcontainer(ctx)
+}
+
+// setupExternalCassandra configura o acesso a um Cassandra externo
+// Nesse modo os comandos CQL são executados pelo cqlsh local
+func (s *SharedCassandra) setupExternalCassandra(ctx context.Context) error {
+	hostPort := os.Getenv("CASSANDRA_HOST")
+	if hostPort == "" {
+		hostPort = "localhost:9042"
+	}
+
+	host, port, found := strings.Cut(hostPort, ":")
+	if !found {
+		port = "9042"
+	}
+
+	if _, err := exec.LookPath("cqlsh"); err != nil {
+		return fmt.Errorf("cqlsh is required to use an external cassandra: %w", err)
+	}
+
+	s.host = host
+	s.port = port
+
+	if err := s.testConnection(ctx); err != nil {
+		return externalFallback("cassandra", "USE_EXTERNAL_CASSANDRA", hostPort, err, func() error {
+			return s.setupTestcontainer(ctx)
+		})
+	}
+
+	if isDebugEnabled() {
+		fmt.Printf("✅ Using external Cassandra at %s\n", hostPort)
+	}
+
+	return nil
+}
+
+// setupTestcontainer cria e inicia um container Cassandra
+func (s *SharedCassandra) setupTestcontainer(ctx context.Context) error {
+	if isDebugEnabled() {
+		fmt.Println("🚀 Starting shared Cassandra container...")
+	}
+
+	req := testcontainers.ContainerRequest{
+		Image:        "cassandra:4.1",
+		ExposedPorts: []string{"9042/tcp"},
+		Name:         "shared-cassandra-test",
+		Env: map[string]string{
+			"MAX_HEAP_SIZE":        "512M",
+			"HEAP_NEWSIZE":         "128M",
+			"CASSANDRA_SNITCH":     "GossipingPropertyFileSnitch",
+			"CASSANDRA_DC":         "datacenter1",
+			"CASSANDRA_NUM_TOKENS": "1",
+			"JVM_EXTRA_OPTS":       "-Dcassandra.skip_wait_for_gossip_to_settle=0 -Dcassandra.initial_token=0",
+		},
+		WaitingFor: wait.ForAll(
+			wait.ForLog("Starting listening for CQL clients"),
+			wait.ForListeningPort("9042/tcp"),
+		).WithStartupTimeout(3 * time.Minute),
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+		Reuse:            shouldReuseContainer(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start cassandra container: %w", err)
+	}
+
+	host, err := container.Host(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get container host: %w", err)
+	}
+
+	port, err := container.MappedPort(ctx, "9042/tcp")
+	if err != nil {
+		return fmt.Errorf("failed to get mapped port: %w", err)
+	}
+
+	s.container = container
+	s.host = host
+	s.port = port.Port()
+
+	// O log de CQL pode aparecer antes do cqlsh conseguir autenticar
+	for i := 0; i < 30; i++ {
+		err = s.testConnection(ctx)
+		if err == nil {
+			break
+		}
+		if isDebugEnabled() {
+			log.Printf("Waiting for cassandra to be ready... attempt %d/30", i+1)
+		}
+		time.Sleep(1 * time.Second)
+	}
+	if err != nil {
+		return fmt.Errorf("cassandra not ready after 30 attempts: %w", err)
+	}
+
+	if isDebugEnabled() {
+		fmt.Printf("✅ Shared Cassandra container started at %s:%s\n", host, port.Port())
+	}
+
+	log.Printf("✅ Shared Cassandra container started at %s:%s", host, port.Port())
+
+	return nil
+}
+
+// stopContainer para o container se não estiver sendo reutilizado
+func (s *SharedCassandra) stopContainer(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.container != nil && !shouldReuseContainer() {
+		if isDebugEnabled() {
+			fmt.Println("🛑 Stopping shared Cassandra container...")
+		}
+		return s.container.Terminate(ctx)
+	}
+
+	return nil
+}
+
+// KeyspaceName deriva o nome do keyspace a partir do tenant ID
+func KeyspaceName(tenantID string) string {
+	name := invalidKeyspaceChars.ReplaceAllString(strings.ToLower(tenantID), "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "ks_" + name
+	}
+	// Cassandra limita nomes de keyspace a 48 caracteres
+	if len(name) > 48 {
+		name = name[:48]
+	}
+	return name
+}
+
+// CreateKeyspace cria (se necessário) o keyspace do tenant e executa os arquivos CQL iniciais nele
+func (s *SharedCassandra) CreateKeyspace(ctx context.Context, tenantID string) (string, error) {
+	keyspace := KeyspaceName(tenantID)
+
+	s.mu.RLock()
+	exists := s.keyspaces[keyspace]
+	cqlFilePaths := s.cqlFilePaths
+	s.mu.RUnlock()
+
+	if exists {
+		return keyspace, nil
+	}
+
+	stmt := fmt.Sprintf(
+		"CREATE KEYSPACE IF NOT EXISTS %s WITH replication = {'class': 'SimpleStrategy', 'replication_factor': 1};",
+		keyspace,
+	)
+	if _, err := s.ExecCQL(ctx, "", stmt); err != nil {
+		return "", fmt.Errorf("failed to create keyspace %s: %w", keyspace, err)
+	}
+
+	for _, path := range cqlFilePaths {
+		if isDebugEnabled() {
+			log.Printf("Executing CQL file %s on keyspace %s", path, keyspace)
+		}
+		if err := s.execCQLFile(ctx, keyspace, path); err != nil {
+			return "", fmt.Errorf("failed to execute CQL from %s: %w", path, err)
+		}
+	}
+
+	s.mu.Lock()
+	s.keyspaces[keyspace] = true
+	s.mu.Unlock()
+
+	return keyspace, nil
+}
+
+// DropKeyspace remove o keyspace do tenant
+func (s *SharedCassandra) DropKeyspace(ctx context.Context, tenantID string) error {
+	keyspace := KeyspaceName(tenantID)
+
+	if _, err := s.ExecCQL(ctx, "", fmt.Sprintf("DROP KEYSPACE IF EXISTS %s;", keyspace)); err != nil {
+		return fmt.Errorf("failed to drop keyspace %s: %w", keyspace, err)
+	}
+
+	s.mu.Lock()
+	delete(s.keyspaces, keyspace)
+	s.mu.Unlock()
+
+	return nil
+}
+
+// CleanKeyspace executa TRUNCATE em todas as tabelas do keyspace do tenant
+func (s *SharedCassandra) CleanKeyspace(ctx context.Context, tenantID string) error {
+	keyspace := KeyspaceName(tenantID)
+
+	out, err := s.ExecCQL(ctx, "", fmt.Sprintf(
+		"SELECT table_name FROM system_schema.tables WHERE keyspace_name = '%s';", keyspace,
+	))
+	if err != nil {
+		return fmt.Errorf("failed to list tables of keyspace %s: %w", keyspace, err)
+	}
+
+	for _, table := range parseCqlshColumn(out) {
+		_, err := s.ExecCQL(ctx, keyspace, fmt.Sprintf("TRUNCATE %s;", table))
+		if err != nil && isDebugEnabled() {
+			fmt.Printf("⚠️  Failed to truncate table %s.%s: %v\n", keyspace, table, err)
+		}
+	}
+
+	return nil
+}
+
+// ExecCQL executa um comando CQL via cqlsh, opcionalmente dentro de um keyspace
+func (s *SharedCassandra) ExecCQL(ctx context.Context, keyspace, statement string) (string, error) {
+	args := []string{"-e", statement}
+	if keyspace != "" {
+		args = append([]string{"-k", keyspace}, args...)
+	}
+	return s.runCqlsh(ctx, args)
+}
+
+// execCQLFile executa um arquivo CQL dentro do keyspace informado
+func (s *SharedCassandra) execCQLFile(ctx context.Context, keyspace, path string) error {
+	s.mu.RLock()
+	container := s.container
+	s.mu.RUnlock()
+
+	target := path
+	if container != nil {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read CQL file %s: %w", path, err)
+		}
+		target = "/tmp/" + filepath.Base(path)
+		if err := container.CopyToContainer(ctx, content, target, 0o644); err != nil {
+			return fmt.Errorf("failed to copy CQL file to container: %w", err)
+		}
+	}
+
+	_, err := s.runCqlsh(ctx, []string{"-k", keyspace, "-f", target})
+	return err
+}
+
+// runCqlsh executa o cqlsh com o estado atual do container
+func (s *SharedCassandra) runCqlsh(ctx context.Context, args []string) (string, error) {
+	s.mu.RLock()
+	container := s.container
+	host := s.host
+	port := s.port
+	s.mu.RUnlock()
+
+	return cqlsh(ctx, container, host, port, args)
+}
+
+// cqlsh executa o cqlsh dentro do container ou localmente (modo externo)
+func cqlsh(ctx context.Context, container testcontainers.Container, host, port string, args []string) (string, error) {
+	if container != nil {
+		cmd := append([]string{"cqlsh"}, args...)
+		code, reader, err := container.Exec(ctx, cmd, tcexec.Multiplexed())
+		if err != nil {
+			return "", err
+		}
+		output, _ := io.ReadAll(reader)
+		if code != 0 {
+			return "", fmt.Errorf("cqlsh exited with code %d: %s", code, strings.TrimSpace(string(output)))
+		}
+		return string(output), nil
+	}
+
+	cmd := exec.CommandContext(ctx, "cqlsh", append([]string{host, port}, args...)...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("cqlsh failed: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return string(output), nil
+}
+
+// testConnection testa se o Cassandra está aceitando comandos CQL
+func (s *SharedCassandra) testConnection(ctx context.Context) error {
+	ctxPing, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	// Chamado com o lock já adquirido em Start, por isso não usa runCqlsh
+	_, err := cqlsh(ctxPing, s.container, s.host, s.port, []string{"-e", "SELECT release_version FROM system.local;"})
+	return err
+}
+
+// parseCqlshColumn extrai os valores de uma consulta de coluna única do output tabular do cqlsh
+func parseCqlshColumn(output string) []string {
+	var values []string
+	headerSeen := false
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "---") {
+			headerSeen = true
+			continue
+		}
+		if !headerSeen || strings.HasPrefix(line, "(") {
+			continue
+		}
+		values = append(values, line)
+	}
+	return values
+}
@@ -0,0 +1,357 @@
+package testhelper
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+var (
+	sharedClickHouse *SharedClickHouse
+	clickHouseOnce   sync.Once
+)
+
+// SharedClickHouse gerencia um container ClickHouse compartilhado entre testes
+// A conexão usa a interface compatível com o protocolo PostgreSQL do ClickHouse (porta 9005),
+// por isso o *sql.DB é aberto com o driver lib/pq já usado pelo SharedPostgreSQL.
+// Essa interface não suporta prepared statements: prefira consultas sem argumentos posicionais.
+type SharedClickHouse struct {
+	mu           sync.RWMutex
+	container    testcontainers.Container
+	connection   *sql.DB
+	url          string
+	refCount     int32
+	startOnce    sync.Once
+	startErr     error
+	started      bool
+	dbName       string
+	sqlFilePaths []string
+}
+
+// GetSharedClickHouse retorna a instância singleton do ClickHouse compartilhado
+func GetSharedClickHouse() *SharedClickHouse {
+	clickHouseOnce.Do(func() {
+		sharedClickHouse = &SharedClickHouse{}
+	})
+	return sharedClickHouse
+}
+
+// Start inicializa o container ClickHouse compartilhado
+func (s *SharedClickHouse) Start(ctx context.Context, sqlFilePaths ...string) error {
+	// Primeiro, tenta reutilizar container existente (sem lock global)
+	s.mu.RLock()
+	if s.started && s.connection != nil {
+		s.mu.RUnlock()
+		// Testa conexão sem lock para permitir paralelismo
+		if err := s.testConnection(); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+		// Conexão perdida, precisa reinicializar
+	} else {
+		s.mu.RUnlock()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Double-check: outro goroutine pode ter criado enquanto aguardava lock
+	if s.started && s.connection != nil {
+		if err := s.testConnection(); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+		// Conexão perdida, reset para tentar novamente
+		s.started = false
+		s.startOnce = sync.Once{}
+	}
+
+	// Armazena os SQL paths para este container
+	s.sqlFilePaths = sqlFilePaths
+
+	s.startOnce.Do(func() {
+		s.startErr = s.startContainer(ctx)
+		if s.startErr == nil {
+			s.started = true
+		}
+	})
+
+	if !s.started {
+		return fmt.Errorf("shared clickhouse not started: %w", s.startErr)
+	}
+
+	atomic.AddInt32(&s.refCount, 1)
+	return nil
+}
+
+// Stop decrementa o contador de referências e para o container se necessário
+func (s *SharedClickHouse) Stop(ctx context.Context) error {
+	if atomic.AddInt32(&s.refCount, -1) <= 0 {
+		return s.stopContainer(ctx)
+	}
+	return nil
+}
+
+// GetConnection retorna a conexão ClickHouse
+func (s *SharedClickHouse) GetConnection() *sql.DB {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.connection
+}
+
+// GetURL retorna a DSN de conexão do ClickHouse
+func (s *SharedClickHouse) GetURL() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.url
+}
+
+// startContainer inicia o container ClickHouse ou usa um externo
+func (s *SharedClickHouse) startContainer(ctx context.Context) error {
+	// Verifica se deve usar ClickHouse externo
+	if useExternal, _ := strconv.ParseBool(os.Getenv("USE_EXTERNAL_CLICKHOUSE")); useExternal {
+		return s.setupExternalClickHouse(ctx)
+	}
+
+	return s.setupTestcontainer(ctx)
+}
+
+// setupExternalClickHouse configura conexão para ClickHouse externo
+func (s *SharedClickHouse) setupExternalClickHouse(ctx context.Context) error {
+	chURL := os.Getenv("CLICKHOUSE_URL")
+	if chURL == "" {
+		chURL = "host=localhost port=9005 user=test password=test dbname=default sslmode=disable"
+	}
+
+	conn, err := sql.Open("postgres", chURL)
+	if err != nil {
+		return fmt.Errorf("failed to create clickhouse connection: %w", err)
+	}
+
+	// Testa conectividade
+	if err := conn.Ping(); err != nil {
+		conn.Close()
+		// CLICKHOUSE_URL pode conter a senha, então o log usa apenas o nome da variável
+		return externalFallback("clickhouse", "USE_EXTERNAL_CLICKHOUSE", "CLICKHOUSE_URL", err, func() error {
+			return s.setupTestcontainer(ctx)
+		})
+	}
+
+	s.connection = conn
+	s.url = chURL
+
+	// Executa SQL files se fornecidos
+	if err := s.executeInitialSQL(); err != nil {
+		return fmt.Errorf("failed to execute initial SQL: %w", err)
+	}
+
+	if isDebugEnabled() {
+		fmt.Printf("✅ Using external ClickHouse\n")
+	}
+
+	return nil
+}
+
+// setupTestcontainer cria e inicia um container ClickHouse
+func (s *SharedClickHouse) setupTestcontainer(ctx context.Context) error {
+	if isDebugEnabled() {
+		fmt.Println("🚀 Starting shared ClickHouse container...")
+	}
+
+	s.dbName = "testdb"
+
+	req := testcontainers.ContainerRequest{
+		Image:        "clickhouse/clickhouse-server:24.3",
+		ExposedPorts: []string{"8123/tcp", "9005/tcp"},
+		Name:         "shared-clickhouse-test",
+		Env: map[string]string{
+			"CLICKHOUSE_USER":                      "test",
+			"CLICKHOUSE_PASSWORD":                  "test",
+			"CLICKHOUSE_DB":                        s.dbName,
+			"CLICKHOUSE_DEFAULT_ACCESS_MANAGEMENT": "1",
+		},
+		WaitingFor: wait.ForAll(
+			wait.ForHTTP("/ping").WithPort("8123/tcp"),
+			wait.ForListeningPort("9005/tcp"),
+		).WithStartupTimeout(60 * time.Second),
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+		Reuse:            shouldReuseContainer(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start clickhouse container: %w", err)
+	}
+
+	port, err := container.MappedPort(ctx, "9005")
+	if err != nil {
+		return fmt.Errorf("failed to get mapped port: %w", err)
+	}
+
+	host, err := container.Host(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get container host: %w", err)
+	}
+
+	dsn := fmt.Sprintf("host=%s port=%s user=test password=test dbname=%s sslmode=disable",
+		host, port.Port(), s.dbName)
+
+	dbConn, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return fmt.Errorf("failed to open clickhouse connection: %w", err)
+	}
+
+	// Aguarda database estar pronto com retry
+	for i := 0; i < 50; i++ {
+		err = dbConn.Ping()
+		if err == nil {
+			break
+		}
+		if isDebugEnabled() {
+			log.Printf("Waiting for clickhouse to be ready... attempt %d/50", i+1)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if err != nil {
+		return fmt.Errorf("clickhouse not ready after 50 attempts: %w", err)
+	}
+
+	s.container = container
+	s.connection = dbConn
+	s.url = dsn
+
+	// Executa SQL files se fornecidos
+	if err := s.executeInitialSQL(); err != nil {
+		return fmt.Errorf("failed to execute initial SQL: %w", err)
+	}
+
+	if isDebugEnabled() {
+		fmt.Printf("✅ Shared ClickHouse container started at %s:%s\n", host, port.Port())
+	}
+
+	log.Printf("✅ Shared ClickHouse container started at %s:%s", host, port.Port())
+
+	return nil
+}
+
+// executeInitialSQL executa os arquivos SQL iniciais
+// O ClickHouse não aceita múltiplos comandos por query, então cada arquivo é dividido por ';'
+func (s *SharedClickHouse) executeInitialSQL() error {
+	for _, path := range s.sqlFilePaths {
+		if isDebugEnabled() {
+			log.Printf("Executing ClickHouse SQL file: %s", path)
+		}
+
+		initSQL, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read SQL file %s: %w", path, err)
+		}
+
+		for _, stmt := range splitSQLStatements(string(initSQL)) {
+			if _, err := s.connection.Exec(stmt); err != nil {
+				return fmt.Errorf("failed to execute SQL from %s: %w", path, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// stopContainer para o container se não estiver sendo reutilizado
+func (s *SharedClickHouse) stopContainer(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.connection != nil {
+		if isDebugEnabled() {
+			fmt.Println("🔌 Closing ClickHouse connection...")
+		}
+		if err := s.connection.Close(); err != nil {
+			log.Printf("Warning: failed to close ClickHouse connection: %v", err)
+		}
+	}
+
+	if s.container != nil && !shouldReuseContainer() {
+		if isDebugEnabled() {
+			fmt.Println("🛑 Stopping shared ClickHouse container...")
+		}
+		return s.container.Terminate(ctx)
+	}
+
+	return nil
+}
+
+// CleanDatabase executa TRUNCATE em todas as tabelas do database atual
+// Views, materialized views e dicionários são ignorados
+func (s *SharedClickHouse) CleanDatabase(ctx context.Context) error {
+	s.mu.RLock()
+	connection := s.connection
+	s.mu.RUnlock()
+
+	if connection == nil {
+		return fmt.Errorf("clickhouse connection not available")
+	}
+
+	rows, err := connection.QueryContext(ctx, `
+		SELECT name
+		FROM system.tables
+		WHERE database = currentDatabase()
+		  AND engine NOT IN ('View', 'MaterializedView', 'Dictionary', 'LiveView')
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to get table list: %w", err)
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			continue
+		}
+		tables = append(tables, table)
+	}
+
+	for _, table := range tables {
+		_, err = connection.ExecContext(ctx, fmt.Sprintf("TRUNCATE TABLE IF EXISTS `%s`", table))
+		if err != nil && isDebugEnabled() {
+			fmt.Printf("⚠️  Failed to truncate ClickHouse table %s: %v\n", table, err)
+		}
+	}
+
+	return nil
+}
+
+// testConnection testa se a conexão com ClickHouse está funcionando
+func (s *SharedClickHouse) testConnection() error {
+	if s.connection == nil {
+		return fmt.Errorf("connection is nil")
+	}
+
+	return s.connection.Ping()
+}
+
+// splitSQLStatements divide um script SQL em comandos individuais
+func splitSQLStatements(script string) []string {
+	var statements []string
+	for _, stmt := range strings.Split(script, ";") {
+		stmt = strings.TrimSpace(stmt)
+		if stmt == "" || strings.HasPrefix(stmt, "--") && !strings.Contains(stmt, "\n") {
+			continue
+		}
+		statements = append(statements, stmt)
+	}
+	return statements
+}
@@ -0,0 +1,388 @@
+package testhelper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+var (
+	sharedConsul *SharedConsul
+	consulOnce   sync.Once
+)
+
+// ConsulService é o registro de um serviço no agent do Consul
+type ConsulService struct {
+	ID      string            `json:"ID"`
+	Name    string            `json:"Name"`
+	Address string            `json:"Address,omitempty"`
+	Port    int               `json:"Port,omitempty"`
+	Tags    []string          `json:"Tags,omitempty"`
+	Meta    map[string]string `json:"Meta,omitempty"`
+}
+
+// SharedConsul gerencia um container Consul (modo dev) compartilhado entre testes
+// A comunicação usa a API HTTP do agent (sem ACLs no modo dev)
+type SharedConsul struct {
+	mu         sync.RWMutex
+	container  testcontainers.Container
+	addr       string
+	httpClient *http.Client
+	refCount   int32
+	startOnce  sync.Once
+	startErr   error
+	started    bool
+}
+
+// GetSharedConsul retorna a instância singleton do Consul compartilhado
+func GetSharedConsul() *SharedConsul {
+	consulOnce.Do(func() {
+		sharedConsul = &SharedConsul{
+			httpClient: &http.Client{Timeout: 30 * time.Second},
+		}
+	})
+	return sharedConsul
+}
+
+// Start inicializa o container Consul compartilhado
+func (s *SharedConsul) Start(ctx context.Context) error {
+	// Primeiro, tenta reutilizar container existente (sem lock global)
+	s.mu.RLock()
+	if s.started && s.addr != "" {
+		s.mu.RUnlock()
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+	} else {
+		s.mu.RUnlock()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Double-check: outro goroutine pode ter criado enquanto aguardava lock
+	if s.started && s.addr != "" {
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+		// Conexão perdida, reset para tentar novamente
+		s.started = false
+		s.startOnce = sync.Once{}
+	}
+
+	s.startOnce.Do(func() {
+		s.startErr = s.startContainer(ctx)
+		if s.startErr == nil {
+			s.started = true
+		}
+	})
+
+	if !s.started {
+		return fmt.Errorf("shared consul not started: %w", s.startErr)
+	}
+
+	atomic.AddInt32(&s.refCount, 1)
+	return nil
+}
+
+// Stop decrementa o contador de referências e para o container se necessário
+func (s *SharedConsul) Stop(ctx context.Context) error {
+	if atomic.AddInt32(&s.refCount, -1) <= 0 {
+		return s.stopContainer(ctx)
+	}
+	return nil
+}
+
+// GetAddress retorna o endereço HTTP do Consul (ex.: "http://localhost:32768")
+func (s *SharedConsul) GetAddress() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.addr
+}
+
+// GetHTTPAddr retorna o endereço no formato de CONSUL_HTTP_ADDR (host:porta)
+func (s *SharedConsul) GetHTTPAddr() string {
+	addr := s.GetAddress()
+	addr = strings.TrimPrefix(addr, "http://")
+	return strings.TrimPrefix(addr, "https://")
+}
+
+// startContainer inicia o container Consul ou usa um externo
+func (s *SharedConsul) startContainer(ctx context.Context) error {
+	// Verifica se deve usar Consul externo
+	if useExternal, _ := strconv.ParseBool(os.Getenv("USE_EXTERNAL_CONSUL")); useExternal {
+		addr := os.Getenv("CONSUL_HTTP_ADDR")
+		if addr == "" {
+			addr = "localhost:8500"
+		}
+		if !strings.HasPrefix(addr, "http://") && !strings.HasPrefix(addr, "https://") {
+			addr = "http://" + addr
+		}
+		s.addr = strings.TrimRight(addr, "/")
+
+		if err := s.testConnection(ctx); err != nil {
+			return externalFallback("consul", "USE_EXTERNAL_CONSUL", s.addr, err, func() error {
+				return s.setupTestcontainer(ctx)
+			})
+		}
+
+		if isDebugEnabled() {
+			fmt.Printf("✅ Using external Consul at %s\n", s.addr)
+		}
+		return nil
+	}
+
+	return s.setupTestcontainer(ctx)
+}
+
+// setupTestcontainer cria e inicia um container Consul em modo dev
+func (s *SharedConsul) setupTestcontainer(ctx context.Context) error {
+	if isDebugEnabled() {
+		fmt.Println("🚀 Starting shared Consul container...")
+	}
+
+	req := testcontainers.ContainerRequest{
+		Image:        "hashicorp/consul:1.17",
+		ExposedPorts: []string{"8500/tcp"},
+		Name:         "shared-consul-test",
+		Cmd:          []string{"agent", "-dev", "-client=0.0.0.0", "-log-level=warn"},
+		WaitingFor:   wait.ForHTTP("/v1/status/leader").WithPort("8500/tcp").WithStartupTimeout(30 * time.Second),
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+		Reuse:            shouldReuseContainer(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start consul container: %w", err)
+	}
+
+	addr, err := container.PortEndpoint(ctx, "8500/tcp", "http")
+	if err != nil {
+		return fmt.Errorf("failed to get consul endpoint: %w", err)
+	}
+
+	s.container = container
+	s.addr = addr
+
+	if err := s.testConnection(ctx); err != nil {
+		return fmt.Errorf("failed to connect to consul: %w", err)
+	}
+
+	if isDebugEnabled() {
+		fmt.Printf("✅ Shared Consul container started at %s\n", addr)
+	}
+
+	log.Printf("✅ Shared Consul container started at %s", addr)
+
+	return nil
+}
+
+// stopContainer para o container se não estiver sendo reutilizado
+func (s *SharedConsul) stopContainer(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.container != nil && !shouldReuseContainer() {
+		if isDebugEnabled() {
+			fmt.Println("🛑 Stopping shared Consul container...")
+		}
+		return s.container.Terminate(ctx)
+	}
+
+	return nil
+}
+
+// PutKV grava um valor no KV store
+func (s *SharedConsul) PutKV(ctx context.Context, key string, value []byte) error {
+	var ok bool
+	if _, err := s.request(ctx, http.MethodPut, "/v1/kv/"+strings.TrimLeft(key, "/"), value, &ok); err != nil {
+		return fmt.Errorf("failed to put key %s: %w", key, err)
+	}
+	if !ok {
+		return fmt.Errorf("failed to put key %s: consul returned false", key)
+	}
+	return nil
+}
+
+// GetKV lê um valor do KV store (found=false se a chave não existir)
+func (s *SharedConsul) GetKV(ctx context.Context, key string) ([]byte, bool, error) {
+	status, raw, err := s.rawRequest(ctx, http.MethodGet, "/v1/kv/"+strings.TrimLeft(key, "/")+"?raw", nil)
+	if status == http.StatusNotFound {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get key %s: %w", key, err)
+	}
+	return raw, true, nil
+}
+
+// SeedKV grava vários valores de uma vez (chave -> valor)
+func (s *SharedConsul) SeedKV(ctx context.Context, values map[string]string) error {
+	for key, value := range values {
+		if err := s.PutKV(ctx, key, []byte(value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListKeys retorna as chaves com o prefixo
+func (s *SharedConsul) ListKeys(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	status, err := s.request(ctx, http.MethodGet, "/v1/kv/"+strings.TrimLeft(prefix, "/")+"?keys", nil, &keys)
+	if status == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list keys %s: %w", prefix, err)
+	}
+	return keys, nil
+}
+
+// DeleteKVPrefix remove todas as chaves com o prefixo ("" remove todas)
+func (s *SharedConsul) DeleteKVPrefix(ctx context.Context, prefix string) error {
+	if _, err := s.request(ctx, http.MethodDelete, "/v1/kv/"+strings.TrimLeft(prefix, "/")+"?recurse", nil, nil); err != nil {
+		return fmt.Errorf("failed to delete keys %s: %w", prefix, err)
+	}
+	return nil
+}
+
+// RegisterService registra um serviço no agent
+func (s *SharedConsul) RegisterService(ctx context.Context, service ConsulService) error {
+	raw, err := json.Marshal(service)
+	if err != nil {
+		return fmt.Errorf("failed to marshal service %s: %w", service.ID, err)
+	}
+	if _, err := s.request(ctx, http.MethodPut, "/v1/agent/service/register", raw, nil); err != nil {
+		return fmt.Errorf("failed to register service %s: %w", service.ID, err)
+	}
+	return nil
+}
+
+// DeregisterService remove o registro de um serviço do agent
+func (s *SharedConsul) DeregisterService(ctx context.Context, id string) error {
+	if _, err := s.request(ctx, http.MethodPut, "/v1/agent/service/deregister/"+url.PathEscape(id), nil, nil); err != nil {
+		return fmt.Errorf("failed to deregister service %s: %w", id, err)
+	}
+	return nil
+}
+
+// Services retorna os serviços registrados no agent (ID -> serviço)
+func (s *SharedConsul) Services(ctx context.Context) (map[string]ConsulService, error) {
+	services := make(map[string]ConsulService)
+	if _, err := s.request(ctx, http.MethodGet, "/v1/agent/services", nil, &services); err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+	return services, nil
+}
+
+// DeregisterServices remove os serviços cujo ID começa com o prefixo ("" remove todos)
+func (s *SharedConsul) DeregisterServices(ctx context.Context, prefix string) error {
+	services, err := s.Services(ctx)
+	if err != nil {
+		return err
+	}
+
+	for id := range services {
+		if strings.HasPrefix(id, prefix) {
+			if err := s.DeregisterService(ctx, id); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Reset remove todas as chaves do KV e todos os serviços registrados
+func (s *SharedConsul) Reset(ctx context.Context) error {
+	if err := s.DeleteKVPrefix(ctx, ""); err != nil {
+		return err
+	}
+	return s.DeregisterServices(ctx, "")
+}
+
+// request executa uma chamada à API HTTP do Consul decodificando a resposta JSON
+func (s *SharedConsul) request(ctx context.Context, method, path string, body []byte, output interface{}) (int, error) {
+	status, raw, err := s.rawRequest(ctx, method, path, body)
+	if err != nil {
+		return status, err
+	}
+
+	if output != nil && len(raw) > 0 {
+		if err := json.Unmarshal(raw, output); err != nil {
+			return status, fmt.Errorf("failed to decode consul response: %w", err)
+		}
+	}
+	return status, nil
+}
+
+// rawRequest executa uma chamada à API HTTP do Consul
+func (s *SharedConsul) rawRequest(ctx context.Context, method, path string, body []byte) (int, []byte, error) {
+	return consulRequest(ctx, s.httpClient, s.GetAddress(), method, path, body)
+}
+
+// consulRequest executa a chamada no endereço informado (sem lock, usado durante o Start)
+func consulRequest(ctx context.Context, client *http.Client, addr, method, path string, body []byte) (int, []byte, error) {
+	if addr == "" {
+		return 0, nil, fmt.Errorf("consul address not available")
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, addr+path, reader)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to build consul request: %w", err)
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer res.Body.Close()
+
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		return res.StatusCode, nil, fmt.Errorf("failed to read consul response: %w", err)
+	}
+
+	if res.StatusCode >= 300 {
+		return res.StatusCode, raw, fmt.Errorf("consul error: %s %s", res.Status, strings.TrimSpace(string(raw)))
+	}
+	return res.StatusCode, raw, nil
+}
+
+// testConnection verifica se o agent responde e o cluster tem líder eleito
+func (s *SharedConsul) testConnection(ctx context.Context) error {
+	ctxPing, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, raw, err := consulRequest(ctxPing, s.httpClient, s.addr, http.MethodGet, "/v1/status/leader", nil)
+	if err != nil {
+		return err
+	}
+	if leader := strings.Trim(strings.TrimSpace(string(raw)), `"`); leader == "" {
+		return fmt.Errorf("consul has no leader yet")
+	}
+	return nil
+}
@@ -2,9 +2,12 @@ package testhelper
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"strconv"
 	"strings"
@@ -21,8 +24,14 @@ import (
 var (
 	sharedES   *SharedElasticsearch
 	esOnce     sync.Once
+
+	sharedESSecure *SharedElasticsearch
+	esSecureOnce   sync.Once
 )
 
+// esSecurePassword é a senha do usuário elastic no container com segurança habilitada
+const esSecurePassword = "testhelper-elastic"
+
 // SharedElasticsearch gerencia um container Elasticsearch compartilhado entre testes
 type SharedElasticsearch struct {
 	mu        sync.RWMutex
@@ -31,7 +40,12 @@ type SharedElasticsearch struct {
 	url       string
 	refCount  int32
 	startOnce sync.Once
+	startErr  error
 	started   bool
+	secure    bool
+	username  string
+	password  string
+	caCert    []byte
 }
 
 // GetSharedElasticsearch retorna a instância singleton do Elasticsearch compartilhado
@@ -42,6 +56,86 @@ func GetSharedElasticsearch() *SharedElasticsearch {
 	return sharedES
 }
 
+// GetSharedElasticsearchSecure retorna o Elasticsearch compartilhado com xpack.security habilitado (TLS na
+// API HTTP e autenticação do usuário elastic); usa um container separado do inseguro
+func GetSharedElasticsearchSecure() *SharedElasticsearch {
+	esSecureOnce.Do(func() {
+		sharedESSecure = &SharedElasticsearch{secure: true}
+	})
+	return sharedESSecure
+}
+
+// elasticsearchSecurityFromEnv indica se ES_SECURITY=true pede o modo seguro para todas as suites
+func elasticsearchSecurityFromEnv() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv("ES_SECURITY"))
+	return enabled
+}
+
+// sharedElasticsearchFor retorna o Elasticsearch compartilhado do modo pedido
+// Com Elasticsearch externo a segurança é definida pelo cluster externo (ES_USERNAME, ES_PASSWORD e
+// ES_CA_CERT), então sempre retorna o padrão
+func sharedElasticsearchFor(secure bool) *SharedElasticsearch {
+	if external, _ := strconv.ParseBool(os.Getenv("USE_EXTERNAL_ES")); external {
+		return GetSharedElasticsearch()
+	}
+	if secure || elasticsearchSecurityFromEnv() {
+		return GetSharedElasticsearchSecure()
+	}
+	return GetSharedElasticsearch()
+}
+
+// Secure indica se o container roda com xpack.security habilitado
+func (s *SharedElasticsearch) Secure() bool {
+	return s.secure
+}
+
+// Credentials retorna usuário e senha do Elasticsearch (vazios sem autenticação)
+func (s *SharedElasticsearch) Credentials() (username, password string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.username, s.password
+}
+
+// CACert retorna o certificado da CA (PEM) que assina o certificado HTTP do Elasticsearch (nil sem TLS)
+func (s *SharedElasticsearch) CACert() []byte {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.caCert
+}
+
+// ClientConfig retorna a configuração de client para o Elasticsearch atual, com credenciais e CA quando
+// a segurança está habilitada; usada por quem precisa de um client com opções próprias (retry, transport)
+func (s *SharedElasticsearch) ClientConfig() elasticsearch.Config {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.clientConfig(s.url)
+}
+
+// clientConfig monta a configuração para o endereço (o chamador detém o lock)
+func (s *SharedElasticsearch) clientConfig(address string) elasticsearch.Config {
+	return elasticsearch.Config{
+		Addresses: []string{address},
+		Username:  s.username,
+		Password:  s.password,
+		CACert:    s.caCert,
+	}
+}
+
+// HTTPTransport retorna um transport HTTP que confia na CA do Elasticsearch, para clients com transport
+// customizado (o client só aplica CACert ao transport padrão)
+func (s *SharedElasticsearch) HTTPTransport() http.RoundTripper {
+	caCert := s.CACert()
+	if caCert == nil {
+		return http.DefaultTransport
+	}
+
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(caCert)
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	return transport
+}
+
 // Start inicializa o container Elasticsearch compartilhado
 func (s *SharedElasticsearch) Start(ctx context.Context) error {
 	// Primeiro, tenta reutilizar container existente (sem lock global)
@@ -74,16 +168,17 @@ func (s *SharedElasticsearch) Start(ctx context.Context) error {
 		s.startOnce = sync.Once{}
 	}
 	
-	var err error
 	s.startOnce.Do(func() {
-		err = s.startContainer(ctx)
-		if err == nil {
+		s.startErr = s.startContainer(ctx)
+		if s.startErr == nil {
 			s.started = true
 		}
 	})
 	
 	if !s.started {
-		return fmt.Errorf("shared elasticsearch not started: %w", err)
+		// O erro da primeira tentativa é mantido: as chamadas seguintes recebem o mesmo erro tipado
+		// (ex.: *ExternalUnavailableError, que o builder converte em skip) e não um erro vazio
+		return fmt.Errorf("shared elasticsearch not started: %w", s.startErr)
 	}
 	
 	atomic.AddInt32(&s.refCount, 1)
@@ -116,24 +211,28 @@ func (s *SharedElasticsearch) GetURL() string {
 func (s *SharedElasticsearch) startContainer(ctx context.Context) error {
 	// Verifica se deve usar Elasticsearch externo
 	if useExternal, _ := strconv.ParseBool(os.Getenv("USE_EXTERNAL_ES")); useExternal {
-		return s.setupExternalElasticsearch()
+		return s.setupExternalElasticsearch(ctx)
 	}
 	
 	return s.setupTestcontainer(ctx)
 }
 
 // setupExternalElasticsearch configura cliente para ES externo
-func (s *SharedElasticsearch) setupExternalElasticsearch() error {
+func (s *SharedElasticsearch) setupExternalElasticsearch(ctx context.Context) error {
 	esURL := os.Getenv("ES_URL")
 	if esURL == "" {
 		esURL = "http://localhost:9209"
 	}
 	
-	cfg := elasticsearch.Config{
-		Addresses: []string{esURL},
+	caCert, err := externalCACert(os.Getenv("ES_CA_CERT"))
+	if err != nil {
+		return err
 	}
+	s.username = os.Getenv("ES_USERNAME")
+	s.password = os.Getenv("ES_PASSWORD")
+	s.caCert = caCert
 	
-	client, err := elasticsearch.NewClient(cfg)
+	client, err := elasticsearch.NewClient(s.clientConfig(esURL))
 	if err != nil {
 		return fmt.Errorf("failed to create elasticsearch client: %w", err)
 	}
@@ -141,7 +240,11 @@ func (s *SharedElasticsearch) setupExternalElasticsearch() error {
 	// Testa conectividade
 	res, err := client.Info()
 	if err != nil {
-		return fmt.Errorf("failed to connect to external elasticsearch: %w", err)
+		return externalFallback("elasticsearch", "USE_EXTERNAL_ES", esURL, err, func() error {
+			// As credenciais são do ES externo: o container local usa as próprias (modo seguro) ou nenhuma
+			s.username, s.password, s.caCert = "", "", nil
+			return s.setupTestcontainer(ctx)
+		})
 	}
 	res.Body.Close()
 	
@@ -171,7 +274,10 @@ func (s *SharedElasticsearch) setupTestcontainer(ctx context.Context) error {
 				"discovery.type": "single-node",
 				"xpack.security.enabled": "false",
 				"bootstrap.memory_lock": "false",
+				"path.repo": ESSnapshotPath,
 			},
+			// Repositório de snapshots em tmpfs (usuário elasticsearch), usado por SnapshotState/RestoreState
+			Tmpfs: map[string]string{ESSnapshotPath: "rw,uid=1000,gid=0,mode=0770"},
 		},
 		Started:      false,
 		Reuse:        true,
@@ -179,23 +285,37 @@ func (s *SharedElasticsearch) setupTestcontainer(ctx context.Context) error {
 
 	}
 
+	opts := []testcontainers.ContainerCustomizer{
+		testcontainers.WithImage("docker.elastic.co/elasticsearch/elasticsearch:8.2.0"),
+	}
+
+	// Modo seguro: o 8.x habilita segurança por padrão, gerando a CA (lida pelo módulo) e usando ELASTIC_PASSWORD
+	if s.secure {
+		genericContainerRequest.Name = "shared-elasticsearch-secure-test"
+		delete(genericContainerRequest.Env, "xpack.security.enabled")
+		opts = append(opts, elasticsearchTestContainer.WithPassword(esSecurePassword))
+	}
+
+	if err := s.withDataVolume(ctx, genericContainerRequest); err != nil {
+		return err
+	}
+
 	container, err := elasticsearchTestContainer.RunContainer(
 		ctx,
-		testcontainers.WithImage("docker.elastic.co/elasticsearch/elasticsearch:8.2.0"),
-		testcontainers.CustomizeRequest(*genericContainerRequest),
+		append(opts, testcontainers.CustomizeRequest(*genericContainerRequest))...,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to start elasticsearch container: %w", err)
 	}
 
-
-	cfg := elasticsearch.Config{
-		Logger: nil,
-		Addresses: []string{
-			container.Settings.Address,
-		},
+	if s.secure {
+		s.username = container.Settings.Username
+		s.password = container.Settings.Password
+		s.caCert = container.Settings.CACert
 	}
 
+	cfg := s.clientConfig(container.Settings.Address)
+
 	esClient, err := elasticsearch.NewClient(cfg)
 	if err != nil {
 		panic(err)
@@ -240,7 +360,13 @@ func (s *SharedElasticsearch) stopContainer(ctx context.Context) error {
 }
 
 // CleanIndices remove todos os índices para limpeza entre testes
+// Passa pelo CleanupScheduler para não sobrecarregar o node com limpezas paralelas
 func (s *SharedElasticsearch) CleanIndices(ctx context.Context) error {
+	return GetCleanupScheduler().Do(ctx, s.cleanIndices)
+}
+
+// cleanIndices deleta os índices que não são de sistema
+func (s *SharedElasticsearch) cleanIndices(ctx context.Context) error {
 	client := s.GetClient()
 	if client == nil {
 		return fmt.Errorf("elasticsearch client not available")
@@ -267,14 +393,23 @@ func (s *SharedElasticsearch) CleanIndices(ctx context.Context) error {
 		return fmt.Errorf("failed to decode indices response: %w", err)
 	}
 	
-	// Deleta índices (exceto os do sistema)
+	// Com ES externo o cluster é compartilhado com outras execuções: só remove os índices desta (RunSuffix)
+	external := externalES()
+	
+	// Deleta índices (exceto os do sistema e os de baseline)
+	var toDelete []string
 	for _, index := range indices {
 		indexName := index["index"].(string)
-		if !strings.HasPrefix(indexName, ".") { // Não deleta índices do sistema
-			_, err := client.Indices.Delete([]string{indexName})
-			if err != nil && isDebugEnabled() {
-				fmt.Printf("⚠️  Failed to delete index %s: %v\n", indexName, err)
-			}
+		if external && !strings.HasSuffix(indexName, RunSuffix()) {
+			continue
+		}
+		if !strings.HasPrefix(indexName, ".") && !isBaselineIndex(indexName) { // Não deleta índices do sistema
+			toDelete = append(toDelete, indexName)
+		}
+	}
+	for _, batch := range indexDeleteBatches(toDelete) {
+		if err := deleteIndexBatch(ctx, client, batch); err != nil {
+			return err
 		}
 	}
 	
@@ -284,6 +419,84 @@ func (s *SharedElasticsearch) CleanIndices(ctx context.Context) error {
 	return nil
 }
 
+// maxDeletePathLength limita o tamanho da lista de índices no path de cada DELETE: o ES recusa linhas
+// de requisição acima de 4KB (http.max_initial_line_length)
+const maxDeletePathLength = 3072
+
+// indexDeleteBatches divide os índices em lotes cuja lista separada por vírgula cabe no path
+func indexDeleteBatches(indices []string) [][]string {
+	var batches [][]string
+	var batch []string
+	length := 0
+	for _, index := range indices {
+		if len(batch) > 0 && length+len(index)+1 > maxDeletePathLength {
+			batches = append(batches, batch)
+			batch, length = nil, 0
+		}
+		batch = append(batch, index)
+		length += len(index) + 1
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+// deleteIndexBatch deleta um lote de índices; índices já removidos (ex.: por outro processo) são ignorados
+func deleteIndexBatch(ctx context.Context, client *elasticsearch.Client, batch []string) error {
+	res, err := client.Indices.Delete(batch,
+		client.Indices.Delete.WithContext(ctx),
+		client.Indices.Delete.WithIgnoreUnavailable(true),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete indices %v: %w", batch, err)
+	}
+	defer res.Body.Close()
+	
+	if res.IsError() {
+		return fmt.Errorf("failed to delete indices %v: %s", batch, res.String())
+	}
+	return nil
+}
+
+// deleteIndicesMatching lista os índices que casam com o padrão e os remove pelo nome, em lotes
+// (action.destructive_requires_name, padrão no ES 8, recusa DELETE com wildcard). Retorna quantos removeu
+func deleteIndicesMatching(ctx context.Context, client *elasticsearch.Client, pattern string) (int, error) {
+	res, err := client.Cat.Indices(
+		client.Cat.Indices.WithContext(ctx),
+		client.Cat.Indices.WithIndex(pattern),
+		client.Cat.Indices.WithFormat("json"),
+		client.Cat.Indices.WithH("index"),
+		client.Cat.Indices.WithExpandWildcards("open,closed"),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list indices %s: %w", pattern, err)
+	}
+	defer res.Body.Close()
+	
+	if res.IsError() {
+		return 0, fmt.Errorf("failed to list indices %s: %s", pattern, res.String())
+	}
+	
+	var rows []struct {
+		Index string `json:"index"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&rows); err != nil {
+		return 0, fmt.Errorf("failed to decode indices %s: %w", pattern, err)
+	}
+	
+	names := make([]string, 0, len(rows))
+	for _, row := range rows {
+		names = append(names, row.Index)
+	}
+	for _, batch := range indexDeleteBatches(names) {
+		if err := deleteIndexBatch(ctx, client, batch); err != nil {
+			return 0, err
+		}
+	}
+	return len(names), nil
+}
+
 // RefreshIndices força refresh de todos os índices
 func (s *SharedElasticsearch) RefreshIndices(ctx context.Context) error {
 	client := s.GetClient()
@@ -303,12 +516,98 @@ func (s *SharedElasticsearch) RefreshIndices(ctx context.Context) error {
 	if res.IsError() {
 		return fmt.Errorf("elasticsearch refresh error: %s", res.Status())
 	}
-	
+
+	return nil
+}
+
+// Restart para e inicia novamente o container e recria o client (a porta mapeada pode mudar)
+// Com hard=true o processo é morto sem shutdown gracioso (sem flush), exercitando o replay do translog
+// Clients obtidos antes do restart ficam apontando para o endereço antigo
+func (s *SharedElasticsearch) Restart(ctx context.Context, hard bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.container == nil {
+		return fmt.Errorf("restart not supported: elasticsearch is not managed by testcontainers")
+	}
+
+	stopTimeout := 30 * time.Second
+	if hard {
+		stopTimeout = 0
+	}
+
+	if isDebugEnabled() {
+		fmt.Printf("🔄 Restarting shared Elasticsearch container (hard=%v)...\n", hard)
+	}
+
+	if err := s.container.Stop(ctx, &stopTimeout); err != nil {
+		return fmt.Errorf("failed to stop elasticsearch container: %w", err)
+	}
+	if err := s.container.Start(ctx); err != nil {
+		return fmt.Errorf("failed to start elasticsearch container: %w", err)
+	}
+
+	proto := "http"
+	if s.caCert != nil {
+		proto = "https"
+	}
+	esURL, err := s.container.PortEndpoint(ctx, "9200/tcp", proto)
+	if err != nil {
+		return fmt.Errorf("failed to get elasticsearch endpoint: %w", err)
+	}
+
+	client, err := elasticsearch.NewClient(s.clientConfig(esURL))
+	if err != nil {
+		return fmt.Errorf("failed to create elasticsearch client: %w", err)
+	}
+
+	s.client = client
+	s.url = esURL
+
+	// O log "started" do boot anterior satisfaz a estratégia de espera, então aguarda a API responder
+	deadline := time.Now().Add(2 * time.Minute)
+	for {
+		err = s.testConnection()
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("elasticsearch not reachable after restart: %w", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+
+	if isDebugEnabled() {
+		fmt.Printf("✅ Shared Elasticsearch container restarted at %s\n", esURL)
+	}
+
 	return nil
 }
 
-// isDebugEnabled verifica se o debug está habilitado
+// externalCACert lê o ES_CA_CERT do modo externo: conteúdo PEM ou caminho do arquivo
+func externalCACert(value string) ([]byte, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if strings.HasPrefix(strings.TrimSpace(value), "-----BEGIN") {
+		return []byte(value), nil
+	}
+	caCert, err := os.ReadFile(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ES_CA_CERT: %w", err)
+	}
+	return caCert, nil
+}
+
+// isDebugEnabled verifica se o debug está habilitado (o modo silencioso tem precedência)
 func isDebugEnabled() bool {
+	if quietModeEnabled() {
+		return false
+	}
 	debug, _ := strconv.ParseBool(os.Getenv("DEBUG_TEST_CONTAINERS"))
 	return debug
 }
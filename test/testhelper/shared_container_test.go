@@ -0,0 +1,66 @@
+package testhelper
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndexDeleteBatches(t *testing.T) {
+	// name retorna um nome de índice com n caracteres; cada um ocupa n+1 no path (vírgula)
+	name := func(prefix string, n int) string {
+		return prefix + strings.Repeat("x", n-len(prefix))
+	}
+	limit := maxDeletePathLength / 3
+
+	tests := []struct {
+		name    string
+		indices []string
+		sizes   []int
+	}{
+		{
+			name:    "Empty input",
+			indices: nil,
+			sizes:   nil,
+		},
+		{
+			name:    "Single index",
+			indices: []string{"products"},
+			sizes:   []int{1},
+		},
+		{
+			name:    "Exactly at the path limit",
+			indices: []string{name("a", limit-1), name("b", limit-1), name("c", limit-1)},
+			sizes:   []int{3},
+		},
+		{
+			name:    "One character over the path limit",
+			indices: []string{name("a", limit-1), name("b", limit-1), name("c", limit)},
+			sizes:   []int{2, 1},
+		},
+		{
+			name:    "Index longer than the limit gets its own batch",
+			indices: []string{"products", name("a", maxDeletePathLength+10), "orders"},
+			sizes:   []int{1, 1, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batches := indexDeleteBatches(tt.indices)
+
+			var sizes []int
+			var flat []string
+			for _, batch := range batches {
+				sizes = append(sizes, len(batch))
+				flat = append(flat, batch...)
+				if len(batch) > 1 {
+					assert.LessOrEqual(t, len(strings.Join(batch, ","))+1, maxDeletePathLength)
+				}
+			}
+			assert.Equal(t, tt.sizes, sizes)
+			assert.Equal(t, tt.indices, flat, "batches must keep every index, in order")
+		})
+	}
+}
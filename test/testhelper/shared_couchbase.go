@@ -0,0 +1,503 @@
+package testhelper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+var (
+	sharedCouchbase *SharedCouchbase
+	couchbaseOnce   sync.Once
+)
+
+// invalidBucketChars casa caracteres não aceitos em nomes de bucket
+var invalidBucketChars = regexp.MustCompile(`[^a-z0-9_.%-]`)
+
+// couchbaseBucketRAMQuota é a cota de memória (MB) de cada bucket de tenant
+// A cota do serviço de dados (1024 MB) comporta 10 buckets simultâneos
+const couchbaseBucketRAMQuota = 100
+
+// CouchbaseConfig reúne o necessário para configurar o SDK ou chamar as APIs HTTP do Couchbase
+type CouchbaseConfig struct {
+	ConnectionString string // couchbase://host:porta?network=external (para o SDK)
+	RESTURL          string // API de gerenciamento (8091)
+	QueryURL         string // serviço N1QL (8093)
+	Username         string
+	Password         string
+}
+
+// SharedCouchbase gerencia um container Couchbase compartilhado entre testes
+// O isolamento é feito com um bucket por tenant ID; setup e consultas usam as APIs HTTP (REST e N1QL)
+type SharedCouchbase struct {
+	mu         sync.RWMutex
+	container  testcontainers.Container
+	config     CouchbaseConfig
+	httpClient *http.Client
+	refCount   int32
+	startOnce  sync.Once
+	startErr   error
+	started    bool
+	buckets    map[string]bool
+}
+
+// GetSharedCouchbase retorna a instância singleton do Couchbase compartilhado
+func GetSharedCouchbase() *SharedCouchbase {
+	couchbaseOnce.Do(func() {
+		sharedCouchbase = &SharedCouchbase{
+			config: CouchbaseConfig{
+				Username: "Administrator",
+				Password: "password",
+			},
+			httpClient: &http.Client{Timeout: 30 * time.Second},
+			buckets:    make(map[string]bool),
+		}
+	})
+	return sharedCouchbase
+}
+
+// Start inicializa o container Couchbase compartilhado
+func (s *SharedCouchbase) Start(ctx context.Context) error {
+	// Primeiro, tenta reutilizar container existente (sem lock global)
+	s.mu.RLock()
+	if s.started && s.config.RESTURL != "" {
+		s.mu.RUnlock()
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+	} else {
+		s.mu.RUnlock()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Double-check: outro goroutine pode ter criado enquanto aguardava lock
+	if s.started && s.config.RESTURL != "" {
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+		// Conexão perdida, reset para tentar novamente
+		s.started = false
+		s.startOnce = sync.Once{}
+		s.buckets = make(map[string]bool)
+	}
+
+	s.startOnce.Do(func() {
+		s.startErr = s.startContainer(ctx)
+		if s.startErr == nil {
+			s.started = true
+		}
+	})
+
+	if !s.started {
+		return fmt.Errorf("shared couchbase not started: %w", s.startErr)
+	}
+
+	atomic.AddInt32(&s.refCount, 1)
+	return nil
+}
+
+// Stop decrementa o contador de referências e para o container se necessário
+func (s *SharedCouchbase) Stop(ctx context.Context) error {
+	if atomic.AddInt32(&s.refCount, -1) <= 0 {
+		return s.stopContainer(ctx)
+	}
+	return nil
+}
+
+// Config retorna connection string, URLs e credenciais para configurar a aplicação
+func (s *SharedCouchbase) Config() CouchbaseConfig {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.config
+}
+
+// startContainer inicia o container Couchbase ou usa um externo
+func (s *SharedCouchbase) startContainer(ctx context.Context) error {
+	// Verifica se deve usar Couchbase externo
+	if useExternal, _ := strconv.ParseBool(os.Getenv("USE_EXTERNAL_COUCHBASE")); useExternal {
+		s.config.RESTURL = strings.TrimRight(envOrDefault("COUCHBASE_URL", "http://localhost:8091"), "/")
+		s.config.QueryURL = strings.TrimRight(envOrDefault("COUCHBASE_QUERY_URL", "http://localhost:8093"), "/")
+		s.config.ConnectionString = envOrDefault("COUCHBASE_CONNECTION_STRING", "couchbase://localhost")
+		s.config.Username = envOrDefault("COUCHBASE_USERNAME", s.config.Username)
+		s.config.Password = envOrDefault("COUCHBASE_PASSWORD", s.config.Password)
+
+		if err := s.testConnection(ctx); err != nil {
+			return externalFallback("couchbase", "USE_EXTERNAL_COUCHBASE", s.config.RESTURL, err, func() error {
+				return s.setupTestcontainer(ctx)
+			})
+		}
+
+		if isDebugEnabled() {
+			fmt.Printf("✅ Using external Couchbase at %s\n", s.config.RESTURL)
+		}
+		return nil
+	}
+
+	return s.setupTestcontainer(ctx)
+}
+
+// envOrDefault retorna a variável de ambiente ou o valor padrão
+func envOrDefault(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// setupTestcontainer cria e inicia um container Couchbase (community) com os serviços kv, n1ql e index
+func (s *SharedCouchbase) setupTestcontainer(ctx context.Context) error {
+	if isDebugEnabled() {
+		fmt.Println("🚀 Starting shared Couchbase container...")
+	}
+
+	req := testcontainers.ContainerRequest{
+		Image:        "couchbase:community-7.2.4",
+		ExposedPorts: []string{"8091/tcp", "8093/tcp", "11210/tcp"},
+		Name:         "shared-couchbase-test",
+		WaitingFor:   wait.ForHTTP("/pools").WithPort("8091/tcp").WithStartupTimeout(3 * time.Minute),
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+		Reuse:            shouldReuseContainer(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start couchbase container: %w", err)
+	}
+
+	restURL, err := container.PortEndpoint(ctx, "8091/tcp", "http")
+	if err != nil {
+		return fmt.Errorf("failed to get couchbase rest endpoint: %w", err)
+	}
+
+	queryURL, err := container.PortEndpoint(ctx, "8093/tcp", "http")
+	if err != nil {
+		return fmt.Errorf("failed to get couchbase query endpoint: %w", err)
+	}
+
+	kvEndpoint, err := container.PortEndpoint(ctx, "11210/tcp", "")
+	if err != nil {
+		return fmt.Errorf("failed to get couchbase kv endpoint: %w", err)
+	}
+
+	s.container = container
+	s.config.RESTURL = restURL
+	s.config.QueryURL = queryURL
+	s.config.ConnectionString = "couchbase://" + kvEndpoint + "?network=external"
+
+	// Container reutilizado já está inicializado
+	if err := s.testConnection(ctx); err != nil {
+		if err := s.initCluster(ctx, kvEndpoint); err != nil {
+			return err
+		}
+	}
+
+	if isDebugEnabled() {
+		fmt.Printf("✅ Shared Couchbase container started at %s\n", restURL)
+	}
+
+	log.Printf("✅ Shared Couchbase container started at %s", restURL)
+
+	return nil
+}
+
+// initCluster inicializa o nó (serviços, cotas, credenciais) e publica as portas mapeadas como
+// endereço alternativo "external", usado pelo SDK com network=external
+func (s *SharedCouchbase) initCluster(ctx context.Context, kvEndpoint string) error {
+	cfg := s.config
+
+	_, err := couchbaseRequest(ctx, s.httpClient, cfg.RESTURL, "", "", http.MethodPost, "/clusterInit", url.Values{
+		"hostname":           {"127.0.0.1"},
+		"services":           {"kv,n1ql,index"},
+		"memoryQuota":        {"1024"},
+		"indexMemoryQuota":   {"256"},
+		"indexerStorageMode": {"forestdb"},
+		"username":           {cfg.Username},
+		"password":           {cfg.Password},
+		"port":               {"SAME"},
+		"sendStats":          {"false"},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize couchbase cluster: %w", err)
+	}
+
+	host, kvPort, _ := strings.Cut(kvEndpoint, ":")
+	restPort := cfg.RESTURL[strings.LastIndex(cfg.RESTURL, ":")+1:]
+	queryPort := cfg.QueryURL[strings.LastIndex(cfg.QueryURL, ":")+1:]
+
+	_, err = couchbaseRequest(ctx, s.httpClient, cfg.RESTURL, cfg.Username, cfg.Password,
+		http.MethodPut, "/node/controller/setupAlternateAddresses/external", url.Values{
+			"hostname": {host},
+			"mgmt":     {restPort},
+			"kv":       {kvPort},
+			"n1ql":     {queryPort},
+		})
+	if err != nil {
+		return fmt.Errorf("failed to configure couchbase alternate addresses: %w", err)
+	}
+
+	// O serviço de consulta sobe alguns segundos depois do clusterInit
+	deadline := time.Now().Add(60 * time.Second)
+	for {
+		_, err = couchbaseQuery(ctx, s.httpClient, cfg, "SELECT 1", nil)
+		if err == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("couchbase query service not ready: %w", err)
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+// stopContainer para o container se não estiver sendo reutilizado
+func (s *SharedCouchbase) stopContainer(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.container != nil && !shouldReuseContainer() {
+		if isDebugEnabled() {
+			fmt.Println("🛑 Stopping shared Couchbase container...")
+		}
+		return s.container.Terminate(ctx)
+	}
+
+	return nil
+}
+
+// CouchbaseBucketName deriva o nome do bucket a partir do tenant ID
+func CouchbaseBucketName(tenantID string) string {
+	name := invalidBucketChars.ReplaceAllString(strings.ToLower(tenantID), "_")
+	// Couchbase limita nomes de bucket a 100 caracteres
+	if len(name) > 100 {
+		name = name[:100]
+	}
+	return name
+}
+
+// HasBucket indica se o bucket do tenant já foi criado pelos helpers
+func (s *SharedCouchbase) HasBucket(tenantID string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.buckets[CouchbaseBucketName(tenantID)]
+}
+
+// CreateBucket cria (se necessário) o bucket do tenant com índice primário, pronto para N1QL
+func (s *SharedCouchbase) CreateBucket(ctx context.Context, tenantID string) (string, error) {
+	bucket := CouchbaseBucketName(tenantID)
+
+	s.mu.RLock()
+	exists := s.buckets[bucket]
+	s.mu.RUnlock()
+
+	if exists {
+		return bucket, nil
+	}
+
+	cfg := s.Config()
+	_, err := couchbaseRequest(ctx, s.httpClient, cfg.RESTURL, cfg.Username, cfg.Password,
+		http.MethodPost, "/pools/default/buckets", url.Values{
+			"name":          {bucket},
+			"bucketType":    {"couchbase"},
+			"ramQuota":      {strconv.Itoa(couchbaseBucketRAMQuota)},
+			"replicaNumber": {"0"},
+			"flushEnabled":  {"1"},
+		})
+	if err != nil && !strings.Contains(err.Error(), "already exists") {
+		return "", fmt.Errorf("failed to create bucket %s: %w", bucket, err)
+	}
+
+	// A criação é assíncrona: o índice primário falha até o bucket ficar disponível no serviço de consulta
+	deadline := time.Now().Add(60 * time.Second)
+	for {
+		_, err = s.Query(ctx, fmt.Sprintf("CREATE PRIMARY INDEX IF NOT EXISTS ON `%s`", bucket))
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			return "", fmt.Errorf("failed to create primary index on bucket %s: %w", bucket, err)
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+
+	s.mu.Lock()
+	s.buckets[bucket] = true
+	s.mu.Unlock()
+
+	if isDebugEnabled() {
+		fmt.Printf("🪣 Couchbase bucket %s created\n", bucket)
+	}
+	return bucket, nil
+}
+
+// DropBucket remove o bucket do tenant (libera a cota de memória para outros tenants)
+func (s *SharedCouchbase) DropBucket(ctx context.Context, tenantID string) error {
+	bucket := CouchbaseBucketName(tenantID)
+
+	cfg := s.Config()
+	_, err := couchbaseRequest(ctx, s.httpClient, cfg.RESTURL, cfg.Username, cfg.Password,
+		http.MethodDelete, "/pools/default/buckets/"+url.PathEscape(bucket), nil)
+	if err != nil && !strings.Contains(err.Error(), "404") {
+		return fmt.Errorf("failed to drop bucket %s: %w", bucket, err)
+	}
+
+	s.mu.Lock()
+	delete(s.buckets, bucket)
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Upsert grava o documento no bucket via N1QL (sem depender do SDK)
+func (s *SharedCouchbase) Upsert(ctx context.Context, bucket, id string, document interface{}) error {
+	_, err := s.Query(ctx, fmt.Sprintf("UPSERT INTO `%s` (KEY, VALUE) VALUES ($1, $2)", bucket), id, document)
+	if err != nil {
+		return fmt.Errorf("failed to upsert document %s: %w", id, err)
+	}
+	return nil
+}
+
+// Query executa uma instrução N1QL com parâmetros posicionais ($1, $2...) e retorna os resultados
+// Consultas usam scan_consistency request_plus, enxergando todas as escritas anteriores
+func (s *SharedCouchbase) Query(ctx context.Context, statement string, args ...interface{}) ([]map[string]interface{}, error) {
+	return couchbaseQuery(ctx, s.httpClient, s.Config(), statement, args)
+}
+
+// CleanBucket remove todos os documentos do bucket do tenant (se ele foi criado)
+func (s *SharedCouchbase) CleanBucket(ctx context.Context, tenantID string) error {
+	if !s.HasBucket(tenantID) {
+		return nil
+	}
+
+	bucket := CouchbaseBucketName(tenantID)
+	if _, err := s.Query(ctx, fmt.Sprintf("DELETE FROM `%s`", bucket)); err != nil {
+		return fmt.Errorf("failed to clean bucket %s: %w", bucket, err)
+	}
+	return nil
+}
+
+// DeleteAll remove os documentos de todos os buckets criados pelos helpers
+func (s *SharedCouchbase) DeleteAll(ctx context.Context) error {
+	s.mu.RLock()
+	buckets := make([]string, 0, len(s.buckets))
+	for bucket := range s.buckets {
+		buckets = append(buckets, bucket)
+	}
+	s.mu.RUnlock()
+
+	for _, bucket := range buckets {
+		if _, err := s.Query(ctx, fmt.Sprintf("DELETE FROM `%s`", bucket)); err != nil {
+			return fmt.Errorf("failed to clean bucket %s: %w", bucket, err)
+		}
+	}
+
+	if isDebugEnabled() && len(buckets) > 0 {
+		fmt.Printf("🧹 Cleaned %d Couchbase bucket(s)\n", len(buckets))
+	}
+	return nil
+}
+
+// couchbaseQuery executa uma instrução no serviço N1QL (sem lock, usado durante o Start)
+func couchbaseQuery(ctx context.Context, client *http.Client, cfg CouchbaseConfig, statement string, args []interface{}) ([]map[string]interface{}, error) {
+	form := url.Values{
+		"statement":        {statement},
+		"scan_consistency": {"request_plus"},
+	}
+	if len(args) > 0 {
+		encoded, err := json.Marshal(args)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal query args: %w", err)
+		}
+		form.Set("args", string(encoded))
+	}
+
+	raw, err := couchbaseRequest(ctx, client, cfg.QueryURL, cfg.Username, cfg.Password, http.MethodPost, "/query/service", form)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Status  string                   `json:"status"`
+		Results []map[string]interface{} `json:"results"`
+		Errors  []struct {
+			Code int    `json:"code"`
+			Msg  string `json:"msg"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(raw, &response); err != nil {
+		return nil, fmt.Errorf("failed to decode couchbase query response: %w", err)
+	}
+	if len(response.Errors) > 0 {
+		return nil, fmt.Errorf("couchbase query error %d: %s", response.Errors[0].Code, response.Errors[0].Msg)
+	}
+	return response.Results, nil
+}
+
+// couchbaseRequest executa uma chamada form-urlencoded às APIs HTTP (sem lock, usado durante o Start)
+func couchbaseRequest(ctx context.Context, client *http.Client, baseURL, username, password, method, path string, form url.Values) ([]byte, error) {
+	if baseURL == "" {
+		return nil, fmt.Errorf("couchbase url not available")
+	}
+
+	var reader io.Reader
+	if form != nil {
+		reader = strings.NewReader(form.Encode())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, baseURL+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build couchbase request: %w", err)
+	}
+	if username != "" {
+		req.SetBasicAuth(username, password)
+	}
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read couchbase response: %w", err)
+	}
+
+	// O serviço N1QL responde erros de consulta com status HTTP != 2xx e corpo JSON com "errors"
+	if res.StatusCode >= 300 && !(strings.HasSuffix(path, "/query/service") && json.Valid(raw)) {
+		return nil, fmt.Errorf("couchbase error: %s %s", res.Status, strings.TrimSpace(string(raw)))
+	}
+	return raw, nil
+}
+
+// testConnection verifica se o cluster está inicializado e aceita as credenciais
+func (s *SharedCouchbase) testConnection(ctx context.Context) error {
+	ctxPing, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := couchbaseRequest(ctxPing, s.httpClient, s.config.RESTURL, s.config.Username, s.config.Password,
+		http.MethodGet, "/pools/default", nil)
+	return err
+}
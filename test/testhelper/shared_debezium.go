@@ -0,0 +1,574 @@
+package testhelper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// debeziumVersion é a versão das imagens Kafka/Kafka Connect do Debezium
+const debeziumVersion = "2.7"
+
+var (
+	sharedDebezium *SharedDebezium
+	debeziumOnce   sync.Once
+)
+
+// DebeziumEvent é um evento de mudança do conector Postgres (JSON sem schema)
+type DebeziumEvent struct {
+	// Op é a operação: c (insert), u (update), d (delete), r (snapshot) ou t (truncate)
+	Op     string                 `json:"op"`
+	Before map[string]interface{} `json:"before"`
+	After  map[string]interface{} `json:"after"`
+	Source map[string]interface{} `json:"source"`
+	TsMs   int64                  `json:"ts_ms"`
+}
+
+// Table retorna a tabela de origem do evento
+func (e DebeziumEvent) Table() string {
+	table, _ := e.Source["table"].(string)
+	return table
+}
+
+// Row retorna o estado da linha após a mudança (antes dela, em deletes)
+func (e DebeziumEvent) Row() map[string]interface{} {
+	if e.Op == "d" {
+		return e.Before
+	}
+	return e.After
+}
+
+// DebeziumConnectorConfig descreve um conector Postgres registrado no Kafka Connect
+type DebeziumConnectorConfig struct {
+	// Name é o nome do conector no Kafka Connect
+	Name string
+	// TopicPrefix prefixa os tópicos: <prefixo>.<schema>.<tabela>
+	TopicPrefix string
+	// Tables são as tabelas capturadas ("schema.tabela"; sem schema usa public). Vazio captura todas
+	Tables []string
+	// SlotName e PublicationName identificam o slot de replicação e a publicação no Postgres
+	SlotName        string
+	PublicationName string
+	// Extra sobrescreve/complementa a configuração gerada (ex.: "snapshot.mode": "initial")
+	Extra map[string]string
+}
+
+// SharedDebezium gerencia um Kafka Connect com o conector Debezium para Postgres, ligado ao Kafka compartilhado
+type SharedDebezium struct {
+	mu         sync.RWMutex
+	connect    testcontainers.Container
+	kafka      *SharedKafka
+	connectURL string
+	pgHost     string
+	pgPort     string
+	httpClient *http.Client
+	refCount   int32
+	startOnce  sync.Once
+	startErr   error
+	started    bool
+}
+
+// GetSharedDebezium retorna a instância singleton do Debezium compartilhado
+func GetSharedDebezium() *SharedDebezium {
+	debeziumOnce.Do(func() {
+		sharedDebezium = &SharedDebezium{
+			httpClient: &http.Client{Timeout: 30 * time.Second},
+		}
+	})
+	return sharedDebezium
+}
+
+// Start inicializa o Kafka Connect com acesso ao PostgreSQL e ao Kafka compartilhados (já iniciados)
+func (s *SharedDebezium) Start(ctx context.Context, pg *SharedPostgreSQL, kafka *SharedKafka) error {
+	// Primeiro, tenta reutilizar container existente (sem lock global)
+	s.mu.RLock()
+	if s.started && s.connectURL != "" {
+		s.mu.RUnlock()
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+	} else {
+		s.mu.RUnlock()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Double-check: outro goroutine pode ter criado enquanto aguardava lock
+	if s.started && s.connectURL != "" {
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+		// Conexão perdida, reset para tentar novamente
+		s.started = false
+		s.startOnce = sync.Once{}
+	}
+
+	s.startOnce.Do(func() {
+		s.startErr = s.startContainer(ctx, pg, kafka)
+		if s.startErr == nil {
+			s.started = true
+		}
+	})
+
+	if !s.started {
+		return fmt.Errorf("shared debezium not started: %w", s.startErr)
+	}
+
+	atomic.AddInt32(&s.refCount, 1)
+	return nil
+}
+
+// Stop decrementa o contador de referências e para os containers se necessário
+func (s *SharedDebezium) Stop(ctx context.Context) error {
+	if atomic.AddInt32(&s.refCount, -1) <= 0 {
+		return s.stopContainer(ctx)
+	}
+	return nil
+}
+
+// ConnectURL retorna a URL da API REST do Kafka Connect
+func (s *SharedDebezium) ConnectURL() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.connectURL
+}
+
+// Kafka retorna o Kafka compartilhado em que o Kafka Connect publica os eventos
+func (s *SharedDebezium) Kafka() *SharedKafka {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.kafka
+}
+
+// startContainer inicia o Kafka Connect ou usa um Kafka Connect externo
+func (s *SharedDebezium) startContainer(ctx context.Context, pg *SharedPostgreSQL, kafka *SharedKafka) error {
+	if err := checkLogicalReplication(ctx, pg); err != nil {
+		return err
+	}
+
+	s.kafka = kafka
+
+	// Verifica se deve usar Kafka Connect externo
+	// O Kafka Connect externo deve publicar no mesmo broker do Kafka compartilhado (USE_EXTERNAL_KAFKA)
+	if useExternal, _ := strconv.ParseBool(os.Getenv("USE_EXTERNAL_DEBEZIUM")); useExternal {
+		s.connectURL = strings.TrimRight(envOrDefault("KAFKA_CONNECT_URL", "http://localhost:8083"), "/")
+
+		dsn := parsePostgresDSN(pg.GetURL())
+		s.pgHost = envOrDefault("DEBEZIUM_DATABASE_HOSTNAME", dsn["host"])
+		s.pgPort = envOrDefault("DEBEZIUM_DATABASE_PORT", dsn["port"])
+
+		if err := s.testConnection(ctx); err != nil {
+			return externalFallback("kafka connect", "USE_EXTERNAL_DEBEZIUM", s.connectURL, err, func() error {
+				return s.setupTestcontainer(ctx, pg)
+			})
+		}
+
+		if isDebugEnabled() {
+			fmt.Printf("✅ Using external Kafka Connect at %s\n", s.connectURL)
+		}
+		return nil
+	}
+
+	return s.setupTestcontainer(ctx, pg)
+}
+
+// setupTestcontainer cria o Kafka Connect do Debezium apontando para o Kafka compartilhado
+func (s *SharedDebezium) setupTestcontainer(ctx context.Context, pg *SharedPostgreSQL) error {
+	if isDebugEnabled() {
+		fmt.Println("🚀 Starting shared Debezium Connect container...")
+	}
+
+	pgHost, pgPort, hostPorts, err := postgresHostForContainer(ctx, pg)
+	if err != nil {
+		return err
+	}
+
+	bootstrap, extraHosts, kafkaPorts, err := KafkaHostForContainer(ctx, s.kafka)
+	if err != nil {
+		return err
+	}
+
+	connect, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "quay.io/debezium/connect:" + debeziumVersion,
+			ExposedPorts: []string{"8083/tcp"},
+			Name:         "shared-debezium-connect-test",
+			Env: map[string]string{
+				"BOOTSTRAP_SERVERS":    bootstrap,
+				"GROUP_ID":             "testhelper",
+				"CONFIG_STORAGE_TOPIC": "testhelper_connect_configs",
+				"OFFSET_STORAGE_TOPIC": "testhelper_connect_offsets",
+				"STATUS_STORAGE_TOPIC": "testhelper_connect_statuses",
+			},
+			HostConfigModifier: func(hostConfig *dockercontainer.HostConfig) {
+				hostConfig.ExtraHosts = append(hostConfig.ExtraHosts, extraHosts...)
+			},
+			HostAccessPorts: append(hostPorts, kafkaPorts...),
+			WaitingFor:      wait.ForHTTP("/connectors").WithPort("8083/tcp").WithStartupTimeout(2 * time.Minute),
+		},
+		Started: true,
+		Reuse:   shouldReuseContainer(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start kafka connect container: %w", err)
+	}
+
+	connectURL, err := connect.PortEndpoint(ctx, "8083/tcp", "http")
+	if err != nil {
+		return fmt.Errorf("failed to get kafka connect endpoint: %w", err)
+	}
+
+	s.connect = connect
+	s.connectURL = connectURL
+	s.pgHost = pgHost
+	s.pgPort = pgPort
+
+	if isDebugEnabled() {
+		fmt.Printf("✅ Shared Debezium started (connect %s, postgres %s:%s)\n", connectURL, pgHost, pgPort)
+	}
+
+	log.Printf("✅ Shared Debezium Connect container started at %s", connectURL)
+
+	return nil
+}
+
+// postgresHostForContainer retorna host/porta do PostgreSQL acessíveis de outro container
+// Com o container compartilhado usa o IP na rede padrão; com PG externo em localhost usa host.testcontainers.internal
+func postgresHostForContainer(ctx context.Context, pg *SharedPostgreSQL) (string, string, []int, error) {
+	pg.mu.RLock()
+	container := pg.container
+	dsn := pg.url
+	pg.mu.RUnlock()
+
+	if container != nil {
+		ip, err := container.ContainerIP(ctx)
+		if err != nil {
+			return "", "", nil, fmt.Errorf("failed to get postgresql container ip: %w", err)
+		}
+		return ip, "5432", nil, nil
+	}
+
+	fields := parsePostgresDSN(dsn)
+	addr := rewriteAddrHost(net.JoinHostPort(fields["host"], fields["port"]), func(string) {})
+	host, port, _ := net.SplitHostPort(addr)
+
+	var ports []int
+	if host != fields["host"] {
+		if p, err := strconv.Atoi(port); err == nil {
+			ports = append(ports, p)
+		}
+	}
+	return host, port, ports, nil
+}
+
+// parsePostgresDSN extrai host, port, user, password e dbname de uma DSN key=value ou URL postgres://
+func parsePostgresDSN(dsn string) map[string]string {
+	fields := map[string]string{"host": "localhost", "port": "5432"}
+
+	if strings.Contains(dsn, "://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return fields
+		}
+		if host := u.Hostname(); host != "" {
+			fields["host"] = host
+		}
+		if port := u.Port(); port != "" {
+			fields["port"] = port
+		}
+		if u.User != nil {
+			fields["user"] = u.User.Username()
+			fields["password"], _ = u.User.Password()
+		}
+		fields["dbname"] = strings.TrimPrefix(u.Path, "/")
+		return fields
+	}
+
+	for _, field := range strings.Fields(dsn) {
+		if key, value, ok := strings.Cut(field, "="); ok {
+			fields[key] = value
+		}
+	}
+	return fields
+}
+
+// checkLogicalReplication garante wal_level=logical, exigido pelo conector Postgres do Debezium
+func checkLogicalReplication(ctx context.Context, pg *SharedPostgreSQL) error {
+	var walLevel string
+	if err := pg.GetConnection().QueryRowContext(ctx, "SHOW wal_level").Scan(&walLevel); err != nil {
+		return fmt.Errorf("failed to read postgresql wal_level: %w", err)
+	}
+	if walLevel != "logical" {
+		return fmt.Errorf("debezium needs postgresql with wal_level=logical (current: %s); recreate the shared postgres container or configure the external server", walLevel)
+	}
+	return nil
+}
+
+// stopContainer para o container se não estiver sendo reutilizado
+func (s *SharedDebezium) stopContainer(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.connect != nil && !shouldReuseContainer() {
+		if isDebugEnabled() {
+			fmt.Println("🛑 Stopping shared Debezium Connect container...")
+		}
+		return s.connect.Terminate(ctx)
+	}
+
+	return nil
+}
+
+// RegisterPostgresConnector registra um conector Debezium Postgres para o banco do PostgreSQL compartilhado
+// e aguarda a task ficar RUNNING e o slot de replicação ativo (mudanças posteriores são capturadas)
+func (s *SharedDebezium) RegisterPostgresConnector(ctx context.Context, pg *SharedPostgreSQL, cfg DebeziumConnectorConfig) error {
+	conn := pg.GetConnection()
+
+	var database, user string
+	if err := conn.QueryRowContext(ctx, "SELECT current_database(), current_user").Scan(&database, &user); err != nil {
+		return fmt.Errorf("failed to read postgresql database: %w", err)
+	}
+
+	s.mu.RLock()
+	pgHost, pgPort := s.pgHost, s.pgPort
+	s.mu.RUnlock()
+
+	config := map[string]string{
+		"connector.class":                "io.debezium.connector.postgresql.PostgresConnector",
+		"plugin.name":                    "pgoutput",
+		"database.hostname":              pgHost,
+		"database.port":                  pgPort,
+		"database.user":                  user,
+		"database.password":              parsePostgresDSN(pg.GetURL())["password"],
+		"database.dbname":                database,
+		"topic.prefix":                   cfg.TopicPrefix,
+		"slot.name":                      cfg.SlotName,
+		"publication.name":               cfg.PublicationName,
+		"publication.autocreate.mode":    "filtered",
+		"snapshot.mode":                  "no_data",
+		"decimal.handling.mode":          "string",
+		"tombstones.on.delete":           "false",
+		"key.converter":                  "org.apache.kafka.connect.json.JsonConverter",
+		"key.converter.schemas.enable":   "false",
+		"value.converter":                "org.apache.kafka.connect.json.JsonConverter",
+		"value.converter.schemas.enable": "false",
+	}
+	if len(cfg.Tables) > 0 {
+		tables := make([]string, len(cfg.Tables))
+		for i, table := range cfg.Tables {
+			tables[i] = qualifiedTable(table)
+		}
+		config["table.include.list"] = strings.Join(tables, ",")
+	} else {
+		config["publication.autocreate.mode"] = "all_tables"
+	}
+	for key, value := range cfg.Extra {
+		config[key] = value
+	}
+
+	body, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal connector config: %w", err)
+	}
+	if _, err := s.request(ctx, http.MethodPut, "/connectors/"+url.PathEscape(cfg.Name)+"/config", body); err != nil {
+		return fmt.Errorf("failed to register connector %s: %w", cfg.Name, err)
+	}
+
+	return s.waitConnectorStreaming(ctx, pg, cfg)
+}
+
+// waitConnectorStreaming aguarda a task do conector RUNNING e o slot de replicação ativo
+func (s *SharedDebezium) waitConnectorStreaming(ctx context.Context, pg *SharedPostgreSQL, cfg DebeziumConnectorConfig) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	for {
+		state, trace, err := s.connectorState(ctx, cfg.Name)
+		if err == nil && state == "FAILED" {
+			return fmt.Errorf("connector %s failed: %s", cfg.Name, trace)
+		}
+		if err == nil && state == "RUNNING" {
+			var active bool
+			err = pg.GetConnection().QueryRowContext(ctx,
+				"SELECT active FROM pg_replication_slots WHERE slot_name = $1", cfg.SlotName).Scan(&active)
+			if err == nil && active {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("connector %s not streaming (state %q): %w", cfg.Name, state, ctx.Err())
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+// connectorState retorna o estado da primeira task do conector (e o stack trace se FAILED)
+func (s *SharedDebezium) connectorState(ctx context.Context, name string) (string, string, error) {
+	raw, err := s.request(ctx, http.MethodGet, "/connectors/"+url.PathEscape(name)+"/status", nil)
+	if err != nil {
+		return "", "", err
+	}
+
+	var status struct {
+		Connector struct {
+			State string `json:"state"`
+			Trace string `json:"trace"`
+		} `json:"connector"`
+		Tasks []struct {
+			State string `json:"state"`
+			Trace string `json:"trace"`
+		} `json:"tasks"`
+	}
+	if err := json.Unmarshal(raw, &status); err != nil {
+		return "", "", fmt.Errorf("failed to decode connector status: %w", err)
+	}
+
+	if status.Connector.State == "FAILED" {
+		return "FAILED", status.Connector.Trace, nil
+	}
+	if len(status.Tasks) == 0 {
+		return status.Connector.State, "", nil
+	}
+	return status.Tasks[0].State, status.Tasks[0].Trace, nil
+}
+
+// DeletePostgresConnector remove o conector e, depois que o slot fica inativo, o slot e a publicação
+func (s *SharedDebezium) DeletePostgresConnector(ctx context.Context, pg *SharedPostgreSQL, cfg DebeziumConnectorConfig) error {
+	if _, err := s.request(ctx, http.MethodDelete, "/connectors/"+url.PathEscape(cfg.Name), nil); err != nil {
+		return fmt.Errorf("failed to delete connector %s: %w", cfg.Name, err)
+	}
+
+	conn := pg.GetConnection()
+	deadline := time.Now().Add(30 * time.Second)
+	for {
+		_, err := conn.ExecContext(ctx,
+			"SELECT pg_drop_replication_slot(slot_name) FROM pg_replication_slots WHERE slot_name = $1", cfg.SlotName)
+		if err == nil {
+			break
+		}
+		// O slot continua ativo até a task do conector parar
+		if time.Now().After(deadline) {
+			return fmt.Errorf("failed to drop replication slot %s: %w", cfg.SlotName, err)
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf(`DROP PUBLICATION IF EXISTS "%s"`, cfg.PublicationName)); err != nil {
+		return fmt.Errorf("failed to drop publication %s: %w", cfg.PublicationName, err)
+	}
+	return nil
+}
+
+// ConsumeEvents lê os eventos do tópico desde o início, aguardando novas mensagens por até idle
+func (s *SharedDebezium) ConsumeEvents(ctx context.Context, topic string, idle time.Duration) ([]DebeziumEvent, error) {
+	messages, err := s.Kafka().ConsumeMessages(ctx, topic, idle)
+	if err != nil {
+		return nil, err
+	}
+	return parseDebeziumEvents(messages)
+}
+
+// parseDebeziumEvents decodifica as mensagens JSON do tópico (mensagens que não são objetos são ignoradas)
+func parseDebeziumEvents(messages []string) ([]DebeziumEvent, error) {
+	var events []DebeziumEvent
+	for _, message := range messages {
+		message = strings.TrimSpace(message)
+		if !strings.HasPrefix(message, "{") {
+			continue
+		}
+		var event DebeziumEvent
+		if err := json.Unmarshal([]byte(message), &event); err != nil {
+			return nil, fmt.Errorf("failed to decode change event: %w", err)
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// qualifiedTable acrescenta o schema public quando a tabela não informa schema
+func qualifiedTable(table string) string {
+	if strings.Contains(table, ".") {
+		return table
+	}
+	return "public." + table
+}
+
+// request executa uma chamada à API REST do Kafka Connect
+func (s *SharedDebezium) request(ctx context.Context, method, path string, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, s.ConnectURL()+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kafka connect request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("kafka connect request %s failed: %w", path, err)
+	}
+	defer res.Body.Close()
+
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kafka connect response: %w", err)
+	}
+	if res.StatusCode >= 300 {
+		return nil, fmt.Errorf("kafka connect request %s failed: %s: %s", path, res.Status, raw)
+	}
+	return raw, nil
+}
+
+// testConnection verifica se a API do Kafka Connect responde e o plugin do Debezium está instalado
+func (s *SharedDebezium) testConnection(ctx context.Context) error {
+	ctxPing, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if s.connectURL == "" {
+		return fmt.Errorf("kafka connect url not available")
+	}
+
+	req, err := http.NewRequestWithContext(ctxPing, http.MethodGet, s.connectURL+"/connector-plugins", nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("kafka connect error: %s", res.Status)
+	}
+	if !bytes.Contains(raw, []byte("io.debezium.connector.postgresql.PostgresConnector")) {
+		return fmt.Errorf("debezium postgres connector plugin not installed in kafka connect")
+	}
+	return nil
+}
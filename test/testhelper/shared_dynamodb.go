@@ -0,0 +1,372 @@
+package testhelper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+var (
+	sharedDynamo *SharedDynamoDB
+	dynamoOnce   sync.Once
+)
+
+// dynamoAuthorization é um header de autenticação fixo; o DynamoDB Local com -sharedDb não valida a assinatura
+const dynamoAuthorization = "AWS4-HMAC-SHA256 Credential=test/20240101/us-east-1/dynamodb/aws4_request, SignedHeaders=host, Signature=0"
+
+// SharedDynamoDB gerencia um container DynamoDB Local compartilhado entre testes
+// A comunicação é feita diretamente pela API JSON do DynamoDB, sem depender do AWS SDK
+type SharedDynamoDB struct {
+	mu         sync.RWMutex
+	container  testcontainers.Container
+	endpoint   string
+	httpClient *http.Client
+	refCount   int32
+	startOnce  sync.Once
+	startErr   error
+	started    bool
+}
+
+// GetSharedDynamoDB retorna a instância singleton do DynamoDB compartilhado
+func GetSharedDynamoDB() *SharedDynamoDB {
+	dynamoOnce.Do(func() {
+		sharedDynamo = &SharedDynamoDB{
+			httpClient: &http.Client{Timeout: 30 * time.Second},
+		}
+	})
+	return sharedDynamo
+}
+
+// Start inicializa o container DynamoDB Local compartilhado
+func (s *SharedDynamoDB) Start(ctx context.Context) error {
+	// Primeiro, tenta reutilizar container existente (sem lock global)
+	s.mu.RLock()
+	if s.started && s.endpoint != "" {
+		s.mu.RUnlock()
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+	} else {
+		s.mu.RUnlock()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Double-check: outro goroutine pode ter criado enquanto aguardava lock
+	if s.started && s.endpoint != "" {
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+		// Conexão perdida, reset para tentar novamente
+		s.started = false
+		s.startOnce = sync.Once{}
+	}
+
+	s.startOnce.Do(func() {
+		s.startErr = s.startContainer(ctx)
+		if s.startErr == nil {
+			s.started = true
+		}
+	})
+
+	if !s.started {
+		return fmt.Errorf("shared dynamodb not started: %w", s.startErr)
+	}
+
+	atomic.AddInt32(&s.refCount, 1)
+	return nil
+}
+
+// Stop decrementa o contador de referências e para o container se necessário
+func (s *SharedDynamoDB) Stop(ctx context.Context) error {
+	if atomic.AddInt32(&s.refCount, -1) <= 0 {
+		return s.stopContainer(ctx)
+	}
+	return nil
+}
+
+// GetEndpoint retorna o endpoint HTTP do DynamoDB (para configurar o AWS SDK da aplicação)
+func (s *SharedDynamoDB) GetEndpoint() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.endpoint
+}
+
+// startContainer inicia o container DynamoDB Local ou usa um externo
+func (s *SharedDynamoDB) startContainer(ctx context.Context) error {
+	// Verifica se deve usar DynamoDB externo
+	if useExternal, _ := strconv.ParseBool(os.Getenv("USE_EXTERNAL_DYNAMODB")); useExternal {
+		return s.setupExternalDynamoDB(ctx)
+	}
+
+	return s.setupTestcontainer(ctx)
+}
+
+// setupExternalDynamoDB configura o endpoint de um DynamoDB externo
+func (s *SharedDynamoDB) setupExternalDynamoDB(ctx context.Context) error {
+	endpoint := os.Getenv("DYNAMODB_URL")
+	if endpoint == "" {
+		endpoint = "http://localhost:8000"
+	}
+
+	s.endpoint = strings.TrimRight(endpoint, "/")
+
+	// Testa conectividade
+	if err := s.testConnection(ctx); err != nil {
+		return externalFallback("dynamodb", "USE_EXTERNAL_DYNAMODB", endpoint, err, func() error {
+			return s.setupTestcontainer(ctx)
+		})
+	}
+
+	if isDebugEnabled() {
+		fmt.Printf("✅ Using external DynamoDB at %s\n", endpoint)
+	}
+
+	return nil
+}
+
+// setupTestcontainer cria e inicia um container DynamoDB Local
+func (s *SharedDynamoDB) setupTestcontainer(ctx context.Context) error {
+	if isDebugEnabled() {
+		fmt.Println("🚀 Starting shared DynamoDB Local container...")
+	}
+
+	req := testcontainers.ContainerRequest{
+		Image:        "amazon/dynamodb-local:2.5.2",
+		ExposedPorts: []string{"8000/tcp"},
+		Name:         "shared-dynamodb-test",
+		Cmd:          []string{"-jar", "DynamoDBLocal.jar", "-inMemory", "-sharedDb"},
+		WaitingFor:   wait.ForListeningPort("8000/tcp").WithStartupTimeout(60 * time.Second),
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+		Reuse:            shouldReuseContainer(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start dynamodb container: %w", err)
+	}
+
+	endpoint, err := container.PortEndpoint(ctx, "8000/tcp", "http")
+	if err != nil {
+		return fmt.Errorf("failed to get dynamodb endpoint: %w", err)
+	}
+
+	s.container = container
+	s.endpoint = endpoint
+
+	if err := s.testConnection(ctx); err != nil {
+		return fmt.Errorf("failed to connect to dynamodb: %w", err)
+	}
+
+	if isDebugEnabled() {
+		fmt.Printf("✅ Shared DynamoDB Local container started at %s\n", endpoint)
+	}
+
+	log.Printf("✅ Shared DynamoDB Local container started at %s", endpoint)
+
+	return nil
+}
+
+// stopContainer para o container se não estiver sendo reutilizado
+func (s *SharedDynamoDB) stopContainer(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.container != nil && !shouldReuseContainer() {
+		if isDebugEnabled() {
+			fmt.Println("🛑 Stopping shared DynamoDB Local container...")
+		}
+		return s.container.Terminate(ctx)
+	}
+
+	return nil
+}
+
+// Do executa uma operação da API do DynamoDB (ex.: "CreateTable", "PutItem")
+// input e output são serializados como JSON no formato da API
+func (s *SharedDynamoDB) Do(ctx context.Context, operation string, input interface{}, output interface{}) error {
+	s.mu.RLock()
+	endpoint := s.endpoint
+	s.mu.RUnlock()
+
+	return s.do(ctx, endpoint, operation, input, output)
+}
+
+// do executa a operação no endpoint informado (sem lock, usado durante o Start)
+func (s *SharedDynamoDB) do(ctx context.Context, endpoint, operation string, input interface{}, output interface{}) error {
+	if endpoint == "" {
+		return fmt.Errorf("dynamodb endpoint not available")
+	}
+
+	var body []byte
+	switch v := input.(type) {
+	case nil:
+		body = []byte("{}")
+	case []byte:
+		body = v
+	case json.RawMessage:
+		body = v
+	default:
+		var err error
+		body, err = json.Marshal(input)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s input: %w", operation, err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build %s request: %w", operation, err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.0")
+	req.Header.Set("X-Amz-Target", "DynamoDB_20120810."+operation)
+	req.Header.Set("Authorization", dynamoAuthorization)
+
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute %s: %w", operation, err)
+	}
+	defer res.Body.Close()
+
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read %s response: %w", operation, err)
+	}
+
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("dynamodb %s error: %s %s", operation, res.Status, strings.TrimSpace(string(raw)))
+	}
+
+	if output != nil && len(raw) > 0 {
+		if err := json.Unmarshal(raw, output); err != nil {
+			return fmt.Errorf("failed to decode %s response: %w", operation, err)
+		}
+	}
+
+	return nil
+}
+
+// CreateTable cria uma tabela a partir de uma definição no formato JSON do CreateTable
+// Aguarda a tabela ficar ACTIVE antes de retornar
+func (s *SharedDynamoDB) CreateTable(ctx context.Context, definition []byte) (string, error) {
+	var table struct {
+		TableName string `json:"TableName"`
+	}
+	if err := json.Unmarshal(definition, &table); err != nil {
+		return "", fmt.Errorf("invalid table definition: %w", err)
+	}
+	if table.TableName == "" {
+		return "", fmt.Errorf("table definition without TableName")
+	}
+
+	if err := s.Do(ctx, "CreateTable", definition, nil); err != nil {
+		return "", err
+	}
+
+	return table.TableName, s.waitForTableStatus(ctx, table.TableName, "ACTIVE")
+}
+
+// CreateTableFromFile cria uma tabela a partir de um arquivo JSON de definição
+func (s *SharedDynamoDB) CreateTableFromFile(ctx context.Context, path string) (string, error) {
+	definition, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read table definition %s: %w", path, err)
+	}
+
+	name, err := s.CreateTable(ctx, definition)
+	if err != nil {
+		return "", fmt.Errorf("failed to create table from %s: %w", path, err)
+	}
+
+	return name, nil
+}
+
+// DeleteTable remove uma tabela
+func (s *SharedDynamoDB) DeleteTable(ctx context.Context, tableName string) error {
+	return s.Do(ctx, "DeleteTable", map[string]string{"TableName": tableName}, nil)
+}
+
+// ListTables retorna o nome de todas as tabelas
+func (s *SharedDynamoDB) ListTables(ctx context.Context) ([]string, error) {
+	var tables []string
+	input := map[string]interface{}{}
+
+	for {
+		var output struct {
+			TableNames             []string `json:"TableNames"`
+			LastEvaluatedTableName string   `json:"LastEvaluatedTableName"`
+		}
+		if err := s.Do(ctx, "ListTables", input, &output); err != nil {
+			return nil, err
+		}
+
+		tables = append(tables, output.TableNames...)
+		if output.LastEvaluatedTableName == "" {
+			return tables, nil
+		}
+		input["ExclusiveStartTableName"] = output.LastEvaluatedTableName
+	}
+}
+
+// CleanTables remove todas as tabelas que não são de sistema (prefixo "_")
+func (s *SharedDynamoDB) CleanTables(ctx context.Context) error {
+	tables, err := s.ListTables(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	for _, table := range tables {
+		if strings.HasPrefix(table, "_") {
+			continue
+		}
+		if err := s.DeleteTable(ctx, table); err != nil && isDebugEnabled() {
+			fmt.Printf("⚠️  Failed to delete DynamoDB table %s: %v\n", table, err)
+		}
+	}
+
+	return nil
+}
+
+// waitForTableStatus aguarda a tabela atingir o status informado
+func (s *SharedDynamoDB) waitForTableStatus(ctx context.Context, tableName, status string) error {
+	for i := 0; i < 50; i++ {
+		var output struct {
+			Table struct {
+				TableStatus string `json:"TableStatus"`
+			} `json:"Table"`
+		}
+		err := s.Do(ctx, "DescribeTable", map[string]string{"TableName": tableName}, &output)
+		if err == nil && output.Table.TableStatus == status {
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	return fmt.Errorf("table %s did not reach status %s", tableName, status)
+}
+
+// testConnection testa se o DynamoDB está respondendo
+func (s *SharedDynamoDB) testConnection(ctx context.Context) error {
+	ctxPing, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return s.do(ctxPing, s.endpoint, "ListTables", map[string]int{"Limit": 1}, nil)
+}
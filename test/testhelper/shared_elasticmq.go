@@ -0,0 +1,421 @@
+package testhelper
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+var (
+	sharedElasticMQ *SharedElasticMQ
+	elasticMQOnce   sync.Once
+)
+
+// elasticMQAccountID é o account ID fixo usado pelo ElasticMQ nas URLs de fila
+const elasticMQAccountID = "000000000000"
+
+// SQSMessage é uma mensagem recebida de uma fila do ElasticMQ
+type SQSMessage struct {
+	ID            string
+	ReceiptHandle string
+	Body          string
+}
+
+// SharedElasticMQ gerencia um container ElasticMQ (fila compatível com SQS) compartilhado entre testes
+// A comunicação usa a Query API do SQS (form POST com respostas XML)
+type SharedElasticMQ struct {
+	mu         sync.RWMutex
+	container  testcontainers.Container
+	endpoint   string
+	httpClient *http.Client
+	refCount   int32
+	startOnce  sync.Once
+	startErr   error
+	started    bool
+}
+
+// GetSharedElasticMQ retorna a instância singleton do ElasticMQ compartilhado
+func GetSharedElasticMQ() *SharedElasticMQ {
+	elasticMQOnce.Do(func() {
+		sharedElasticMQ = &SharedElasticMQ{
+			httpClient: &http.Client{Timeout: 30 * time.Second},
+		}
+	})
+	return sharedElasticMQ
+}
+
+// Start inicializa o container ElasticMQ compartilhado
+func (s *SharedElasticMQ) Start(ctx context.Context) error {
+	// Primeiro, tenta reutilizar container existente (sem lock global)
+	s.mu.RLock()
+	if s.started && s.endpoint != "" {
+		s.mu.RUnlock()
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+	} else {
+		s.mu.RUnlock()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Double-check: outro goroutine pode ter criado enquanto aguardava lock
+	if s.started && s.endpoint != "" {
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+		// Conexão perdida, reset para tentar novamente
+		s.started = false
+		s.startOnce = sync.Once{}
+	}
+
+	s.startOnce.Do(func() {
+		s.startErr = s.startContainer(ctx)
+		if s.startErr == nil {
+			s.started = true
+		}
+	})
+
+	if !s.started {
+		return fmt.Errorf("shared elasticmq not started: %w", s.startErr)
+	}
+
+	atomic.AddInt32(&s.refCount, 1)
+	return nil
+}
+
+// Stop decrementa o contador de referências e para o container se necessário
+func (s *SharedElasticMQ) Stop(ctx context.Context) error {
+	if atomic.AddInt32(&s.refCount, -1) <= 0 {
+		return s.stopContainer(ctx)
+	}
+	return nil
+}
+
+// GetEndpoint retorna o endpoint compatível com SQS (usar como endpoint customizado do SDK da AWS)
+func (s *SharedElasticMQ) GetEndpoint() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.endpoint
+}
+
+// QueueURL retorna a URL da fila no endpoint exposto
+func (s *SharedElasticMQ) QueueURL(name string) string {
+	return s.GetEndpoint() + "/" + elasticMQAccountID + "/" + name
+}
+
+// startContainer inicia o container ElasticMQ ou usa um externo
+func (s *SharedElasticMQ) startContainer(ctx context.Context) error {
+	// Verifica se deve usar ElasticMQ externo
+	if useExternal, _ := strconv.ParseBool(os.Getenv("USE_EXTERNAL_ELASTICMQ")); useExternal {
+		endpoint := os.Getenv("ELASTICMQ_ENDPOINT")
+		if endpoint == "" {
+			endpoint = "http://localhost:9324"
+		}
+		s.endpoint = strings.TrimRight(endpoint, "/")
+
+		if err := s.testConnection(ctx); err != nil {
+			return externalFallback("elasticmq", "USE_EXTERNAL_ELASTICMQ", s.endpoint, err, func() error {
+				return s.setupTestcontainer(ctx)
+			})
+		}
+
+		if isDebugEnabled() {
+			fmt.Printf("✅ Using external ElasticMQ at %s\n", s.endpoint)
+		}
+		return nil
+	}
+
+	return s.setupTestcontainer(ctx)
+}
+
+// setupTestcontainer cria e inicia um container ElasticMQ
+func (s *SharedElasticMQ) setupTestcontainer(ctx context.Context) error {
+	if isDebugEnabled() {
+		fmt.Println("🚀 Starting shared ElasticMQ container...")
+	}
+
+	req := testcontainers.ContainerRequest{
+		Image:        "softwaremill/elasticmq-native:1.5.7",
+		ExposedPorts: []string{"9324/tcp"},
+		Name:         "shared-elasticmq-test",
+		WaitingFor:   wait.ForListeningPort("9324/tcp").WithStartupTimeout(30 * time.Second),
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+		Reuse:            shouldReuseContainer(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start elasticmq container: %w", err)
+	}
+
+	endpoint, err := container.PortEndpoint(ctx, "9324/tcp", "http")
+	if err != nil {
+		return fmt.Errorf("failed to get elasticmq endpoint: %w", err)
+	}
+
+	s.container = container
+	s.endpoint = endpoint
+
+	if err := s.testConnection(ctx); err != nil {
+		return fmt.Errorf("failed to connect to elasticmq: %w", err)
+	}
+
+	if isDebugEnabled() {
+		fmt.Printf("✅ Shared ElasticMQ container started at %s\n", endpoint)
+	}
+
+	log.Printf("✅ Shared ElasticMQ container started at %s", endpoint)
+
+	return nil
+}
+
+// stopContainer para o container se não estiver sendo reutilizado
+func (s *SharedElasticMQ) stopContainer(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.container != nil && !shouldReuseContainer() {
+		if isDebugEnabled() {
+			fmt.Println("🛑 Stopping shared ElasticMQ container...")
+		}
+		return s.container.Terminate(ctx)
+	}
+
+	return nil
+}
+
+// CreateQueue cria a fila (idempotente) e retorna a URL
+func (s *SharedElasticMQ) CreateQueue(ctx context.Context, name string) (string, error) {
+	params := url.Values{"QueueName": {name}}
+	if strings.HasSuffix(name, ".fifo") {
+		params.Set("Attribute.1.Name", "FifoQueue")
+		params.Set("Attribute.1.Value", "true")
+	}
+
+	if err := s.action(ctx, "CreateQueue", params, nil); err != nil {
+		return "", fmt.Errorf("failed to create queue %s: %w", name, err)
+	}
+	return s.QueueURL(name), nil
+}
+
+// CreateQueues cria as filas informadas e retorna nome -> URL
+func (s *SharedElasticMQ) CreateQueues(ctx context.Context, names ...string) (map[string]string, error) {
+	urls := make(map[string]string, len(names))
+	for _, name := range names {
+		queueURL, err := s.CreateQueue(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		urls[name] = queueURL
+	}
+	return urls, nil
+}
+
+// ListQueues retorna os nomes das filas com o prefixo ("" lista todas)
+func (s *SharedElasticMQ) ListQueues(ctx context.Context, prefix string) ([]string, error) {
+	params := url.Values{}
+	if prefix != "" {
+		params.Set("QueueNamePrefix", prefix)
+	}
+
+	var response struct {
+		QueueURLs []string `xml:"ListQueuesResult>QueueUrl"`
+	}
+	if err := s.action(ctx, "ListQueues", params, &response); err != nil {
+		return nil, fmt.Errorf("failed to list queues: %w", err)
+	}
+
+	names := make([]string, 0, len(response.QueueURLs))
+	for _, queueURL := range response.QueueURLs {
+		names = append(names, queueURL[strings.LastIndex(queueURL, "/")+1:])
+	}
+	return names, nil
+}
+
+// SendMessage envia uma mensagem para a fila e retorna o ID
+func (s *SharedElasticMQ) SendMessage(ctx context.Context, queue, body string) (string, error) {
+	params := url.Values{"QueueUrl": {s.QueueURL(queue)}, "MessageBody": {body}}
+	if strings.HasSuffix(queue, ".fifo") {
+		params.Set("MessageGroupId", "default")
+		params.Set("MessageDeduplicationId", strconv.FormatInt(time.Now().UnixNano(), 10))
+	}
+
+	var response struct {
+		MessageID string `xml:"SendMessageResult>MessageId"`
+	}
+	if err := s.action(ctx, "SendMessage", params, &response); err != nil {
+		return "", fmt.Errorf("failed to send message to %s: %w", queue, err)
+	}
+	return response.MessageID, nil
+}
+
+// ReceiveMessages recebe até max mensagens (long polling de até wait) e remove as recebidas da fila
+func (s *SharedElasticMQ) ReceiveMessages(ctx context.Context, queue string, max int, waitTime time.Duration) ([]SQSMessage, error) {
+	params := url.Values{
+		"QueueUrl":            {s.QueueURL(queue)},
+		"MaxNumberOfMessages": {strconv.Itoa(max)},
+		"WaitTimeSeconds":     {strconv.Itoa(int(waitTime / time.Second))},
+	}
+
+	var response struct {
+		Messages []struct {
+			MessageID     string `xml:"MessageId"`
+			ReceiptHandle string `xml:"ReceiptHandle"`
+			Body          string `xml:"Body"`
+		} `xml:"ReceiveMessageResult>Message"`
+	}
+	if err := s.action(ctx, "ReceiveMessage", params, &response); err != nil {
+		return nil, fmt.Errorf("failed to receive messages from %s: %w", queue, err)
+	}
+
+	messages := make([]SQSMessage, 0, len(response.Messages))
+	for _, m := range response.Messages {
+		deleteParams := url.Values{"QueueUrl": {s.QueueURL(queue)}, "ReceiptHandle": {m.ReceiptHandle}}
+		if err := s.action(ctx, "DeleteMessage", deleteParams, nil); err != nil {
+			return nil, fmt.Errorf("failed to delete message %s: %w", m.MessageID, err)
+		}
+		messages = append(messages, SQSMessage{ID: m.MessageID, ReceiptHandle: m.ReceiptHandle, Body: m.Body})
+	}
+	return messages, nil
+}
+
+// ApproximateMessageCount retorna o número aproximado de mensagens visíveis na fila
+func (s *SharedElasticMQ) ApproximateMessageCount(ctx context.Context, queue string) (int, error) {
+	params := url.Values{
+		"QueueUrl":        {s.QueueURL(queue)},
+		"AttributeName.1": {"ApproximateNumberOfMessages"},
+	}
+
+	var response struct {
+		Attributes []struct {
+			Name  string `xml:"Name"`
+			Value string `xml:"Value"`
+		} `xml:"GetQueueAttributesResult>Attribute"`
+	}
+	if err := s.action(ctx, "GetQueueAttributes", params, &response); err != nil {
+		return 0, fmt.Errorf("failed to get attributes of %s: %w", queue, err)
+	}
+
+	for _, attr := range response.Attributes {
+		if attr.Name == "ApproximateNumberOfMessages" {
+			return strconv.Atoi(attr.Value)
+		}
+	}
+	return 0, nil
+}
+
+// PurgeQueue remove todas as mensagens da fila
+func (s *SharedElasticMQ) PurgeQueue(ctx context.Context, queue string) error {
+	if err := s.action(ctx, "PurgeQueue", url.Values{"QueueUrl": {s.QueueURL(queue)}}, nil); err != nil {
+		return fmt.Errorf("failed to purge queue %s: %w", queue, err)
+	}
+	return nil
+}
+
+// PurgeAll remove as mensagens de todas as filas (as filas são mantidas)
+func (s *SharedElasticMQ) PurgeAll(ctx context.Context) error {
+	names, err := s.ListQueues(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if err := s.PurgeQueue(ctx, name); err != nil {
+			return err
+		}
+	}
+
+	if isDebugEnabled() && len(names) > 0 {
+		fmt.Printf("🧹 Purged %d ElasticMQ queue(s)\n", len(names))
+	}
+	return nil
+}
+
+// DeleteQueue remove a fila
+func (s *SharedElasticMQ) DeleteQueue(ctx context.Context, queue string) error {
+	if err := s.action(ctx, "DeleteQueue", url.Values{"QueueUrl": {s.QueueURL(queue)}}, nil); err != nil {
+		return fmt.Errorf("failed to delete queue %s: %w", queue, err)
+	}
+	return nil
+}
+
+// action executa uma ação da Query API do SQS
+func (s *SharedElasticMQ) action(ctx context.Context, action string, params url.Values, output interface{}) error {
+	return elasticMQAction(ctx, s.httpClient, s.GetEndpoint(), action, params, output)
+}
+
+// elasticMQAction executa a ação no endpoint informado (sem lock, usado durante o Start)
+func elasticMQAction(ctx context.Context, client *http.Client, endpoint, action string, params url.Values, output interface{}) error {
+	if endpoint == "" {
+		return fmt.Errorf("elasticmq endpoint not available")
+	}
+
+	form := url.Values{}
+	for key, values := range params {
+		form[key] = values
+	}
+	form.Set("Action", action)
+	form.Set("Version", "2012-11-05")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/", strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode >= 300 {
+		var errResponse struct {
+			Code    string `xml:"Error>Code"`
+			Message string `xml:"Error>Message"`
+		}
+		if xml.Unmarshal(raw, &errResponse) == nil && errResponse.Code != "" {
+			return fmt.Errorf("elasticmq error: %s: %s", errResponse.Code, errResponse.Message)
+		}
+		return fmt.Errorf("elasticmq error: %s %s", res.Status, strings.TrimSpace(string(raw)))
+	}
+
+	if output != nil {
+		if err := xml.Unmarshal(raw, output); err != nil {
+			return fmt.Errorf("failed to decode elasticmq response: %w", err)
+		}
+	}
+	return nil
+}
+
+// testConnection verifica se o ElasticMQ responde
+func (s *SharedElasticMQ) testConnection(ctx context.Context) error {
+	ctxPing, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return elasticMQAction(ctxPing, s.httpClient, s.endpoint, "ListQueues", nil, nil)
+}
@@ -0,0 +1,488 @@
+package testhelper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+var (
+	sharedGCS *SharedGCS
+	gcsOnce   sync.Once
+)
+
+// gcsProject é o projeto usado nas chamadas que exigem project (o fake não valida)
+const gcsProject = "test-project"
+
+// bucketNameInvalid casa caracteres não permitidos em nomes de buckets
+var bucketNameInvalid = regexp.MustCompile(`[^a-z0-9_.-]+`)
+
+// SharedGCS gerencia um container fake-gcs-server compartilhado entre testes
+// A comunicação usa a API JSON do Cloud Storage exposta pelo fake
+type SharedGCS struct {
+	mu         sync.RWMutex
+	container  testcontainers.Container
+	endpoint   string
+	httpClient *http.Client
+	refCount   int32
+	startOnce  sync.Once
+	startErr   error
+	started    bool
+}
+
+// GetSharedGCS retorna a instância singleton do fake GCS compartilhado
+func GetSharedGCS() *SharedGCS {
+	gcsOnce.Do(func() {
+		sharedGCS = &SharedGCS{
+			httpClient: &http.Client{Timeout: 30 * time.Second},
+		}
+	})
+	return sharedGCS
+}
+
+// Start inicializa o container fake-gcs-server compartilhado
+func (s *SharedGCS) Start(ctx context.Context) error {
+	// Primeiro, tenta reutilizar container existente (sem lock global)
+	s.mu.RLock()
+	if s.started && s.endpoint != "" {
+		s.mu.RUnlock()
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+	} else {
+		s.mu.RUnlock()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Double-check: outro goroutine pode ter criado enquanto aguardava lock
+	if s.started && s.endpoint != "" {
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+		// Conexão perdida, reset para tentar novamente
+		s.started = false
+		s.startOnce = sync.Once{}
+	}
+
+	s.startOnce.Do(func() {
+		s.startErr = s.startContainer(ctx)
+		if s.startErr == nil {
+			s.started = true
+		}
+	})
+
+	if !s.started {
+		return fmt.Errorf("shared gcs not started: %w", s.startErr)
+	}
+
+	atomic.AddInt32(&s.refCount, 1)
+	return nil
+}
+
+// Stop decrementa o contador de referências e para o container se necessário
+func (s *SharedGCS) Stop(ctx context.Context) error {
+	if atomic.AddInt32(&s.refCount, -1) <= 0 {
+		return s.stopContainer(ctx)
+	}
+	return nil
+}
+
+// GetEndpoint retorna a URL base do fake (ex.: http://localhost:32789)
+func (s *SharedGCS) GetEndpoint() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.endpoint
+}
+
+// GetClientEndpoint retorna o endpoint para option.WithEndpoint do cloud.google.com/go/storage
+func (s *SharedGCS) GetClientEndpoint() string {
+	return s.GetEndpoint() + "/storage/v1/"
+}
+
+// GetEmulatorHost retorna o valor para STORAGE_EMULATOR_HOST (host:porta)
+func (s *SharedGCS) GetEmulatorHost() string {
+	return strings.TrimPrefix(strings.TrimPrefix(s.GetEndpoint(), "http://"), "https://")
+}
+
+// startContainer inicia o container fake-gcs-server ou usa um externo
+func (s *SharedGCS) startContainer(ctx context.Context) error {
+	// Verifica se deve usar fake GCS externo
+	if useExternal, _ := strconv.ParseBool(os.Getenv("USE_EXTERNAL_GCS")); useExternal {
+		endpoint := os.Getenv("GCS_ENDPOINT")
+		if endpoint == "" {
+			endpoint = "http://localhost:4443"
+		}
+		s.endpoint = strings.TrimRight(endpoint, "/")
+
+		if err := s.testConnection(ctx); err != nil {
+			return externalFallback("gcs", "USE_EXTERNAL_GCS", s.endpoint, err, func() error {
+				return s.setupTestcontainer(ctx)
+			})
+		}
+
+		if isDebugEnabled() {
+			fmt.Printf("✅ Using external fake GCS at %s\n", s.endpoint)
+		}
+		return nil
+	}
+
+	return s.setupTestcontainer(ctx)
+}
+
+// setupTestcontainer cria e inicia um container fake-gcs-server
+func (s *SharedGCS) setupTestcontainer(ctx context.Context) error {
+	if isDebugEnabled() {
+		fmt.Println("🚀 Starting shared fake GCS container...")
+	}
+
+	req := testcontainers.ContainerRequest{
+		Image:        "fsouza/fake-gcs-server:1.47.8",
+		ExposedPorts: []string{"4443/tcp"},
+		Name:         "shared-gcs-test",
+		Cmd:          []string{"-scheme", "http", "-port", "4443", "-backend", "memory"},
+		WaitingFor:   wait.ForHTTP("/storage/v1/b").WithPort("4443/tcp").WithStartupTimeout(30 * time.Second),
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+		Reuse:            shouldReuseContainer(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start gcs container: %w", err)
+	}
+
+	endpoint, err := container.PortEndpoint(ctx, "4443/tcp", "http")
+	if err != nil {
+		return fmt.Errorf("failed to get gcs endpoint: %w", err)
+	}
+
+	s.container = container
+	s.endpoint = endpoint
+
+	if err := s.testConnection(ctx); err != nil {
+		return fmt.Errorf("failed to connect to gcs: %w", err)
+	}
+
+	// Links de download/upload retornados pelo fake devem usar a porta mapeada
+	config := map[string]string{"externalUrl": endpoint}
+	if _, err := gcsRequest(ctx, s.httpClient, http.MethodPut, endpoint+"/_internal/config", config, "", nil); err != nil {
+		return fmt.Errorf("failed to configure gcs external url: %w", err)
+	}
+
+	if isDebugEnabled() {
+		fmt.Printf("✅ Shared fake GCS container started at %s\n", endpoint)
+	}
+
+	log.Printf("✅ Shared fake GCS container started at %s", endpoint)
+
+	return nil
+}
+
+// stopContainer para o container se não estiver sendo reutilizado
+func (s *SharedGCS) stopContainer(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.container != nil && !shouldReuseContainer() {
+		if isDebugEnabled() {
+			fmt.Println("🛑 Stopping shared fake GCS container...")
+		}
+		return s.container.Terminate(ctx)
+	}
+
+	return nil
+}
+
+// TenantBucketName deriva um nome de bucket válido para o tenant (e sufixo opcional)
+func TenantBucketName(tenantID, suffix string) string {
+	name := strings.ToLower(tenantID)
+	if suffix != "" {
+		name += "-" + strings.ToLower(suffix)
+	}
+	name = strings.Trim(bucketNameInvalid.ReplaceAllString(name, "-"), "-_.")
+	if len(name) > 63 {
+		name = strings.TrimRight(name[:63], "-_.")
+	}
+	for len(name) < 3 {
+		name += "0"
+	}
+	return name
+}
+
+// CreateBucket cria um bucket (ignora se já existir)
+func (s *SharedGCS) CreateBucket(ctx context.Context, bucket string) error {
+	status, err := s.request(ctx, http.MethodPost, "/storage/v1/b?project="+gcsProject, map[string]string{"name": bucket}, nil)
+	if err != nil && status != http.StatusConflict {
+		return fmt.Errorf("failed to create bucket %s: %w", bucket, err)
+	}
+	return nil
+}
+
+// DeleteBucket remove o bucket e todos os seus objetos (ignora se não existir)
+func (s *SharedGCS) DeleteBucket(ctx context.Context, bucket string) error {
+	objects, err := s.ListObjects(ctx, bucket)
+	if err != nil {
+		return err
+	}
+	for _, object := range objects {
+		if err := s.DeleteObject(ctx, bucket, object); err != nil {
+			return err
+		}
+	}
+
+	status, err := s.request(ctx, http.MethodDelete, "/storage/v1/b/"+url.PathEscape(bucket), nil, nil)
+	if err != nil && status != http.StatusNotFound {
+		return fmt.Errorf("failed to delete bucket %s: %w", bucket, err)
+	}
+	return nil
+}
+
+// ListBuckets retorna os nomes dos buckets
+func (s *SharedGCS) ListBuckets(ctx context.Context) ([]string, error) {
+	var response struct {
+		Items []struct {
+			Name string `json:"name"`
+		} `json:"items"`
+	}
+	if _, err := s.request(ctx, http.MethodGet, "/storage/v1/b?project="+gcsProject, nil, &response); err != nil {
+		return nil, fmt.Errorf("failed to list buckets: %w", err)
+	}
+
+	names := make([]string, 0, len(response.Items))
+	for _, item := range response.Items {
+		names = append(names, item.Name)
+	}
+	return names, nil
+}
+
+// UploadObject grava um objeto no bucket
+func (s *SharedGCS) UploadObject(ctx context.Context, bucket, object string, data []byte, contentType string) error {
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	s.mu.RLock()
+	endpoint := s.endpoint
+	s.mu.RUnlock()
+
+	target := fmt.Sprintf("%s/upload/storage/v1/b/%s/o?uploadType=media&name=%s", endpoint, url.PathEscape(bucket), url.QueryEscape(object))
+	if _, err := gcsRequest(ctx, s.httpClient, http.MethodPost, target, data, contentType, nil); err != nil {
+		return fmt.Errorf("failed to upload %s/%s: %w", bucket, object, err)
+	}
+	return nil
+}
+
+// DownloadObject lê o conteúdo de um objeto
+func (s *SharedGCS) DownloadObject(ctx context.Context, bucket, object string) ([]byte, error) {
+	s.mu.RLock()
+	endpoint := s.endpoint
+	s.mu.RUnlock()
+
+	var data []byte
+	target := fmt.Sprintf("%s/storage/v1/b/%s/o/%s?alt=media", endpoint, url.PathEscape(bucket), url.PathEscape(object))
+	if _, err := gcsRequest(ctx, s.httpClient, http.MethodGet, target, nil, "", &data); err != nil {
+		return nil, fmt.Errorf("failed to download %s/%s: %w", bucket, object, err)
+	}
+	return data, nil
+}
+
+// ListObjects retorna os nomes dos objetos do bucket
+func (s *SharedGCS) ListObjects(ctx context.Context, bucket string) ([]string, error) {
+	var names []string
+	pageToken := ""
+	for {
+		path := "/storage/v1/b/" + url.PathEscape(bucket) + "/o"
+		if pageToken != "" {
+			path += "?pageToken=" + url.QueryEscape(pageToken)
+		}
+
+		var response struct {
+			Items []struct {
+				Name string `json:"name"`
+			} `json:"items"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		status, err := s.request(ctx, http.MethodGet, path, nil, &response)
+		if status == http.StatusNotFound {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects of %s: %w", bucket, err)
+		}
+
+		for _, item := range response.Items {
+			names = append(names, item.Name)
+		}
+		if response.NextPageToken == "" {
+			return names, nil
+		}
+		pageToken = response.NextPageToken
+	}
+}
+
+// DeleteObject remove um objeto do bucket (ignora se não existir)
+func (s *SharedGCS) DeleteObject(ctx context.Context, bucket, object string) error {
+	path := "/storage/v1/b/" + url.PathEscape(bucket) + "/o/" + url.PathEscape(object)
+	status, err := s.request(ctx, http.MethodDelete, path, nil, nil)
+	if err != nil && status != http.StatusNotFound {
+		return fmt.Errorf("failed to delete %s/%s: %w", bucket, object, err)
+	}
+	return nil
+}
+
+// SeedBucket cria o bucket e envia os arquivos do diretório (o caminho relativo vira o nome do objeto)
+func (s *SharedGCS) SeedBucket(ctx context.Context, bucket, dir string) (int, error) {
+	if err := s.CreateBucket(ctx, bucket); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		if err := s.UploadObject(ctx, bucket, filepath.ToSlash(rel), data, mime.TypeByExtension(filepath.Ext(path))); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		return count, fmt.Errorf("failed to seed bucket %s: %w", bucket, err)
+	}
+
+	if isDebugEnabled() {
+		fmt.Printf("📦 Seeded %d object(s) into bucket %s\n", count, bucket)
+	}
+	return count, nil
+}
+
+// DeleteAllBuckets remove todos os buckets (e objetos)
+func (s *SharedGCS) DeleteAllBuckets(ctx context.Context) error {
+	buckets, err := s.ListBuckets(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, bucket := range buckets {
+		if err := s.DeleteBucket(ctx, bucket); err != nil {
+			return err
+		}
+	}
+
+	if isDebugEnabled() && len(buckets) > 0 {
+		fmt.Printf("🧹 Deleted %d GCS bucket(s)\n", len(buckets))
+	}
+	return nil
+}
+
+// request executa uma chamada JSON à API do fake
+func (s *SharedGCS) request(ctx context.Context, method, path string, input interface{}, output interface{}) (int, error) {
+	s.mu.RLock()
+	endpoint := s.endpoint
+	s.mu.RUnlock()
+
+	return gcsRequest(ctx, s.httpClient, method, endpoint+path, input, "", output)
+}
+
+// gcsRequest executa a chamada (sem lock, usado durante o Start)
+// input []byte é enviado como está com o contentType informado; outros valores viram JSON
+// output *[]byte recebe o corpo bruto; outros valores são decodificados como JSON
+func gcsRequest(ctx context.Context, client *http.Client, method, target string, input interface{}, contentType string, output interface{}) (int, error) {
+	if target == "" || strings.HasPrefix(target, "/") {
+		return 0, fmt.Errorf("gcs endpoint not available")
+	}
+
+	var body io.Reader
+	switch v := input.(type) {
+	case nil:
+	case []byte:
+		body = bytes.NewReader(v)
+	default:
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return 0, fmt.Errorf("failed to marshal gcs request: %w", err)
+		}
+		body = bytes.NewReader(raw)
+		contentType = "application/json"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return 0, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		return res.StatusCode, err
+	}
+
+	if res.StatusCode >= 300 {
+		return res.StatusCode, fmt.Errorf("gcs error: %s %s", res.Status, strings.TrimSpace(string(raw)))
+	}
+
+	switch out := output.(type) {
+	case nil:
+	case *[]byte:
+		*out = raw
+	default:
+		if len(raw) > 0 {
+			if err := json.Unmarshal(raw, out); err != nil {
+				return res.StatusCode, fmt.Errorf("failed to decode gcs response: %w", err)
+			}
+		}
+	}
+	return res.StatusCode, nil
+}
+
+// testConnection verifica se a API JSON está respondendo
+func (s *SharedGCS) testConnection(ctx context.Context) error {
+	ctxPing, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := gcsRequest(ctxPing, s.httpClient, http.MethodGet, s.endpoint+"/storage/v1/b?project="+gcsProject, nil, "", nil)
+	return err
+}
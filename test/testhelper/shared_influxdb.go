@@ -0,0 +1,408 @@
+package testhelper
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+var (
+	sharedInfluxDB *SharedInfluxDB
+	influxDBOnce   sync.Once
+)
+
+// InfluxConfig reúne o necessário para configurar um client do InfluxDB 2.x
+type InfluxConfig struct {
+	URL    string
+	Token  string
+	Org    string
+	Bucket string
+}
+
+// SharedInfluxDB gerencia um container InfluxDB 2.x compartilhado entre testes
+// O container é inicializado (setup) com org, bucket e token fixos; a comunicação usa a API HTTP v2
+type SharedInfluxDB struct {
+	mu         sync.RWMutex
+	container  testcontainers.Container
+	config     InfluxConfig
+	httpClient *http.Client
+	refCount   int32
+	startOnce  sync.Once
+	startErr   error
+	started    bool
+}
+
+// GetSharedInfluxDB retorna a instância singleton do InfluxDB compartilhado
+func GetSharedInfluxDB() *SharedInfluxDB {
+	influxDBOnce.Do(func() {
+		sharedInfluxDB = &SharedInfluxDB{
+			config: InfluxConfig{
+				Token:  "test-admin-token",
+				Org:    "test-org",
+				Bucket: "test-bucket",
+			},
+			httpClient: &http.Client{Timeout: 30 * time.Second},
+		}
+	})
+	return sharedInfluxDB
+}
+
+// Start inicializa o container InfluxDB compartilhado
+func (s *SharedInfluxDB) Start(ctx context.Context) error {
+	// Primeiro, tenta reutilizar container existente (sem lock global)
+	s.mu.RLock()
+	if s.started && s.config.URL != "" {
+		s.mu.RUnlock()
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+	} else {
+		s.mu.RUnlock()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Double-check: outro goroutine pode ter criado enquanto aguardava lock
+	if s.started && s.config.URL != "" {
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+		// Conexão perdida, reset para tentar novamente
+		s.started = false
+		s.startOnce = sync.Once{}
+	}
+
+	s.startOnce.Do(func() {
+		s.startErr = s.startContainer(ctx)
+		if s.startErr == nil {
+			s.started = true
+		}
+	})
+
+	if !s.started {
+		return fmt.Errorf("shared influxdb not started: %w", s.startErr)
+	}
+
+	atomic.AddInt32(&s.refCount, 1)
+	return nil
+}
+
+// Stop decrementa o contador de referências e para o container se necessário
+func (s *SharedInfluxDB) Stop(ctx context.Context) error {
+	if atomic.AddInt32(&s.refCount, -1) <= 0 {
+		return s.stopContainer(ctx)
+	}
+	return nil
+}
+
+// Config retorna URL, token, org e bucket para configurar o client da aplicação
+func (s *SharedInfluxDB) Config() InfluxConfig {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.config
+}
+
+// GetURL retorna a URL HTTP do InfluxDB
+func (s *SharedInfluxDB) GetURL() string {
+	return s.Config().URL
+}
+
+// startContainer inicia o container InfluxDB ou usa um externo
+func (s *SharedInfluxDB) startContainer(ctx context.Context) error {
+	// Verifica se deve usar InfluxDB externo
+	if useExternal, _ := strconv.ParseBool(os.Getenv("USE_EXTERNAL_INFLUXDB")); useExternal {
+		influxURL := os.Getenv("INFLUXDB_URL")
+		if influxURL == "" {
+			influxURL = "http://localhost:8086"
+		}
+		s.config.URL = strings.TrimRight(influxURL, "/")
+		if token := os.Getenv("INFLUXDB_TOKEN"); token != "" {
+			s.config.Token = token
+		}
+		if org := os.Getenv("INFLUXDB_ORG"); org != "" {
+			s.config.Org = org
+		}
+		if bucket := os.Getenv("INFLUXDB_BUCKET"); bucket != "" {
+			s.config.Bucket = bucket
+		}
+
+		if err := s.testConnection(ctx); err != nil {
+			return externalFallback("influxdb", "USE_EXTERNAL_INFLUXDB", s.config.URL, err, func() error {
+				return s.setupTestcontainer(ctx)
+			})
+		}
+
+		if isDebugEnabled() {
+			fmt.Printf("✅ Using external InfluxDB at %s\n", s.config.URL)
+		}
+		return nil
+	}
+
+	return s.setupTestcontainer(ctx)
+}
+
+// setupTestcontainer cria e inicia um container InfluxDB 2.x já inicializado (org, bucket e token)
+func (s *SharedInfluxDB) setupTestcontainer(ctx context.Context) error {
+	if isDebugEnabled() {
+		fmt.Println("🚀 Starting shared InfluxDB container...")
+	}
+
+	req := testcontainers.ContainerRequest{
+		Image:        "influxdb:2.7",
+		ExposedPorts: []string{"8086/tcp"},
+		Name:         "shared-influxdb-test",
+		Env: map[string]string{
+			"DOCKER_INFLUXDB_INIT_MODE":        "setup",
+			"DOCKER_INFLUXDB_INIT_USERNAME":    "admin",
+			"DOCKER_INFLUXDB_INIT_PASSWORD":    "admin-password",
+			"DOCKER_INFLUXDB_INIT_ORG":         s.config.Org,
+			"DOCKER_INFLUXDB_INIT_BUCKET":      s.config.Bucket,
+			"DOCKER_INFLUXDB_INIT_ADMIN_TOKEN": s.config.Token,
+		},
+		WaitingFor: wait.ForHTTP("/health").WithPort("8086/tcp").WithStartupTimeout(60 * time.Second),
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+		Reuse:            shouldReuseContainer(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start influxdb container: %w", err)
+	}
+
+	influxURL, err := container.PortEndpoint(ctx, "8086/tcp", "http")
+	if err != nil {
+		return fmt.Errorf("failed to get influxdb endpoint: %w", err)
+	}
+
+	s.container = container
+	s.config.URL = influxURL
+
+	// O setup inicial reinicia o influxd depois que /health já respondeu
+	deadline := time.Now().Add(30 * time.Second)
+	for {
+		err = s.testConnection(ctx)
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("failed to connect to influxdb: %w", err)
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+
+	if isDebugEnabled() {
+		fmt.Printf("✅ Shared InfluxDB container started at %s (org %s, bucket %s)\n", influxURL, s.config.Org, s.config.Bucket)
+	}
+
+	log.Printf("✅ Shared InfluxDB container started at %s", influxURL)
+
+	return nil
+}
+
+// stopContainer para o container se não estiver sendo reutilizado
+func (s *SharedInfluxDB) stopContainer(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.container != nil && !shouldReuseContainer() {
+		if isDebugEnabled() {
+			fmt.Println("🛑 Stopping shared InfluxDB container...")
+		}
+		return s.container.Terminate(ctx)
+	}
+
+	return nil
+}
+
+// WriteLines grava pontos em line protocol no bucket de teste (precisão em nanossegundos)
+func (s *SharedInfluxDB) WriteLines(ctx context.Context, lines ...string) error {
+	cfg := s.Config()
+	query := url.Values{"org": {cfg.Org}, "bucket": {cfg.Bucket}, "precision": {"ns"}}
+
+	_, err := s.request(ctx, http.MethodPost, "/api/v2/write?"+query.Encode(), "text/plain; charset=utf-8", []byte(strings.Join(lines, "\n")))
+	if err != nil {
+		return fmt.Errorf("failed to write points: %w", err)
+	}
+	return nil
+}
+
+// Query executa uma consulta Flux e retorna as linhas como coluna -> valor
+func (s *SharedInfluxDB) Query(ctx context.Context, flux string) ([]map[string]string, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"query":   flux,
+		"type":    "flux",
+		"dialect": map[string]interface{}{"header": true, "annotations": []string{}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal flux query: %w", err)
+	}
+
+	raw, err := s.request(ctx, http.MethodPost, "/api/v2/query?"+url.Values{"org": {s.Config().Org}}.Encode(), "application/json", body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query influxdb: %w", err)
+	}
+
+	return parseFluxCSV(raw)
+}
+
+// DeleteAll remove todos os pontos (de todas as measurements) do bucket de teste
+func (s *SharedInfluxDB) DeleteAll(ctx context.Context) error {
+	return s.deletePoints(ctx, "")
+}
+
+// DeleteMeasurement remove todos os pontos de uma measurement do bucket de teste
+func (s *SharedInfluxDB) DeleteMeasurement(ctx context.Context, measurement string) error {
+	return s.deletePoints(ctx, fmt.Sprintf(`_measurement="%s"`, measurement))
+}
+
+// deletePoints chama a API de delete para todo o intervalo de tempo com o predicado opcional
+func (s *SharedInfluxDB) deletePoints(ctx context.Context, predicate string) error {
+	cfg := s.Config()
+	payload := map[string]interface{}{
+		"start": "1970-01-01T00:00:00Z",
+		"stop":  "2200-01-01T00:00:00Z",
+	}
+	if predicate != "" {
+		payload["predicate"] = predicate
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal delete request: %w", err)
+	}
+
+	query := url.Values{"org": {cfg.Org}, "bucket": {cfg.Bucket}}
+	if _, err := s.request(ctx, http.MethodPost, "/api/v2/delete?"+query.Encode(), "application/json", body); err != nil {
+		return fmt.Errorf("failed to delete points: %w", err)
+	}
+
+	if isDebugEnabled() {
+		fmt.Printf("🧹 Deleted InfluxDB points from bucket %s\n", cfg.Bucket)
+	}
+	return nil
+}
+
+// request executa uma chamada autenticada à API HTTP v2
+func (s *SharedInfluxDB) request(ctx context.Context, method, path, contentType string, body []byte) ([]byte, error) {
+	cfg := s.Config()
+	return influxRequest(ctx, s.httpClient, cfg.URL, cfg.Token, method, path, contentType, body)
+}
+
+// influxRequest executa a chamada no endereço informado (sem lock, usado durante o Start)
+func influxRequest(ctx context.Context, client *http.Client, baseURL, token, method, path, contentType string, body []byte) ([]byte, error) {
+	if baseURL == "" {
+		return nil, fmt.Errorf("influxdb url not available")
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, baseURL+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build influxdb request: %w", err)
+	}
+	req.Header.Set("Authorization", "Token "+token)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read influxdb response: %w", err)
+	}
+
+	if res.StatusCode >= 300 {
+		return nil, fmt.Errorf("influxdb error: %s %s", res.Status, strings.TrimSpace(string(raw)))
+	}
+	return raw, nil
+}
+
+// parseFluxCSV converte a resposta CSV (sem anotações) de uma consulta Flux em linhas coluna -> valor
+// Cada tabela do resultado repete o cabeçalho; linhas vazias separam as tabelas
+func parseFluxCSV(raw []byte) ([]map[string]string, error) {
+	reader := csv.NewReader(bytes.NewReader(raw))
+	reader.FieldsPerRecord = -1
+
+	var header []string
+	var rows []map[string]string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse flux csv: %w", err)
+		}
+
+		if header == nil || isFluxHeader(record) {
+			header = record
+			continue
+		}
+
+		row := make(map[string]string, len(record))
+		for i, value := range record {
+			if i < len(header) && header[i] != "" {
+				row[header[i]] = value
+			}
+		}
+		rows = append(rows, row)
+	}
+}
+
+// isFluxHeader identifica o cabeçalho repetido no início de cada tabela
+func isFluxHeader(record []string) bool {
+	return len(record) > 2 && record[1] == "result" && record[2] == "table"
+}
+
+// testConnection verifica se a API responde e o bucket de teste existe
+func (s *SharedInfluxDB) testConnection(ctx context.Context) error {
+	ctxPing, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	raw, err := influxRequest(ctxPing, s.httpClient, s.config.URL, s.config.Token,
+		http.MethodGet, "/api/v2/buckets?"+url.Values{"name": {s.config.Bucket}}.Encode(), "", nil)
+	if err != nil {
+		return err
+	}
+
+	var response struct {
+		Buckets []struct {
+			Name string `json:"name"`
+		} `json:"buckets"`
+	}
+	if err := json.Unmarshal(raw, &response); err != nil {
+		return fmt.Errorf("failed to decode influxdb buckets: %w", err)
+	}
+	if len(response.Buckets) == 0 {
+		return fmt.Errorf("influxdb bucket %s not found", s.config.Bucket)
+	}
+	return nil
+}
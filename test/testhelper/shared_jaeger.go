@@ -0,0 +1,387 @@
+package testhelper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+var (
+	sharedJaeger *SharedJaeger
+	jaegerOnce   sync.Once
+)
+
+// JaegerSpan é um span retornado pela API de consulta do Jaeger
+type JaegerSpan struct {
+	TraceID       string
+	SpanID        string
+	ParentSpanID  string
+	OperationName string
+	ServiceName   string
+	StartTime     time.Time
+	Duration      time.Duration
+	Tags          map[string]interface{}
+}
+
+// JaegerTrace é um trace com seus spans
+type JaegerTrace struct {
+	TraceID string
+	Spans   []JaegerSpan
+}
+
+// FindSpans retorna os spans do trace com a operação informada
+func (t JaegerTrace) FindSpans(operation string) []JaegerSpan {
+	var spans []JaegerSpan
+	for _, span := range t.Spans {
+		if span.OperationName == operation {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+// SharedJaeger gerencia um container Jaeger all-in-one (armazenamento em memória)
+type SharedJaeger struct {
+	mu           sync.RWMutex
+	container    testcontainers.Container
+	queryURL     string
+	otlpGRPCAddr string
+	otlpHTTPURL  string
+	collectorURL string
+	httpClient   *http.Client
+	refCount     int32
+	startOnce    sync.Once
+	startErr     error
+	started      bool
+}
+
+// GetSharedJaeger retorna a instância singleton do Jaeger compartilhado
+func GetSharedJaeger() *SharedJaeger {
+	jaegerOnce.Do(func() {
+		sharedJaeger = &SharedJaeger{
+			httpClient: &http.Client{Timeout: 30 * time.Second},
+		}
+	})
+	return sharedJaeger
+}
+
+// Start inicializa o container Jaeger se ainda não estiver rodando
+func (s *SharedJaeger) Start(ctx context.Context) error {
+	// Primeiro, tenta reutilizar container existente (sem lock global)
+	s.mu.RLock()
+	if s.started && s.queryURL != "" {
+		s.mu.RUnlock()
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+	} else {
+		s.mu.RUnlock()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Double-check: outro goroutine pode ter criado enquanto aguardava lock
+	if s.started && s.queryURL != "" {
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+		// Conexão perdida, reset para tentar novamente
+		s.started = false
+		s.startOnce = sync.Once{}
+	}
+
+	s.startOnce.Do(func() {
+		s.startErr = s.startContainer(ctx)
+		if s.startErr == nil {
+			s.started = true
+		}
+	})
+
+	if !s.started {
+		return fmt.Errorf("shared jaeger not started: %w", s.startErr)
+	}
+
+	atomic.AddInt32(&s.refCount, 1)
+	return nil
+}
+
+// Stop decrementa o contador de referências e para o container se necessário
+func (s *SharedJaeger) Stop(ctx context.Context) error {
+	if atomic.AddInt32(&s.refCount, -1) <= 0 {
+		return s.stopContainer(ctx)
+	}
+	return nil
+}
+
+// QueryURL retorna a URL da API de consulta/UI do Jaeger (porta 16686)
+func (s *SharedJaeger) QueryURL() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.queryURL
+}
+
+// OTLPGRPCAddr retorna o endereço host:porta do receiver OTLP gRPC (porta 4317)
+func (s *SharedJaeger) OTLPGRPCAddr() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.otlpGRPCAddr
+}
+
+// OTLPHTTPURL retorna a URL do receiver OTLP HTTP (porta 4318; os exporters acrescentam /v1/traces)
+func (s *SharedJaeger) OTLPHTTPURL() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.otlpHTTPURL
+}
+
+// CollectorURL retorna a URL do collector HTTP (Thrift, porta 14268) usado por clientes Jaeger legados
+func (s *SharedJaeger) CollectorURL() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.collectorURL
+}
+
+// startContainer inicia o container Jaeger ou usa um externo
+func (s *SharedJaeger) startContainer(ctx context.Context) error {
+	// Verifica se deve usar Jaeger externo
+	if useExternal, _ := strconv.ParseBool(os.Getenv("USE_EXTERNAL_JAEGER")); useExternal {
+		s.queryURL = strings.TrimRight(envOrDefault("JAEGER_QUERY_URL", "http://localhost:16686"), "/")
+		s.otlpGRPCAddr = envOrDefault("JAEGER_OTLP_GRPC_ADDR", "localhost:4317")
+		s.otlpHTTPURL = strings.TrimRight(envOrDefault("JAEGER_OTLP_HTTP_URL", "http://localhost:4318"), "/")
+		s.collectorURL = strings.TrimRight(envOrDefault("JAEGER_COLLECTOR_URL", "http://localhost:14268"), "/")
+
+		if err := s.testConnection(ctx); err != nil {
+			return externalFallback("jaeger", "USE_EXTERNAL_JAEGER", s.queryURL, err, func() error {
+				return s.setupTestcontainer(ctx)
+			})
+		}
+
+		if isDebugEnabled() {
+			fmt.Printf("✅ Using external Jaeger at %s\n", s.queryURL)
+		}
+		return nil
+	}
+
+	return s.setupTestcontainer(ctx)
+}
+
+// setupTestcontainer cria e inicia um container Jaeger all-in-one com OTLP habilitado
+func (s *SharedJaeger) setupTestcontainer(ctx context.Context) error {
+	if isDebugEnabled() {
+		fmt.Println("🚀 Starting shared Jaeger container...")
+	}
+
+	req := testcontainers.ContainerRequest{
+		Image:        "jaegertracing/all-in-one:1.57",
+		ExposedPorts: []string{"16686/tcp", "4317/tcp", "4318/tcp", "14268/tcp"},
+		Name:         "shared-jaeger-test",
+		Env: map[string]string{
+			"COLLECTOR_OTLP_ENABLED": "true",
+		},
+		WaitingFor: wait.ForHTTP("/api/services").WithPort("16686/tcp").WithStartupTimeout(time.Minute),
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+		Reuse:            shouldReuseContainer(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start jaeger container: %w", err)
+	}
+
+	queryURL, err := container.PortEndpoint(ctx, "16686/tcp", "http")
+	if err != nil {
+		return fmt.Errorf("failed to get jaeger query endpoint: %w", err)
+	}
+	otlpGRPCAddr, err := container.PortEndpoint(ctx, "4317/tcp", "")
+	if err != nil {
+		return fmt.Errorf("failed to get jaeger otlp grpc endpoint: %w", err)
+	}
+	otlpHTTPURL, err := container.PortEndpoint(ctx, "4318/tcp", "http")
+	if err != nil {
+		return fmt.Errorf("failed to get jaeger otlp http endpoint: %w", err)
+	}
+	collectorURL, err := container.PortEndpoint(ctx, "14268/tcp", "http")
+	if err != nil {
+		return fmt.Errorf("failed to get jaeger collector endpoint: %w", err)
+	}
+
+	s.container = container
+	s.queryURL = queryURL
+	s.otlpGRPCAddr = otlpGRPCAddr
+	s.otlpHTTPURL = otlpHTTPURL
+	s.collectorURL = collectorURL
+
+	if isDebugEnabled() {
+		fmt.Printf("✅ Shared Jaeger container started (query %s, otlp grpc %s, otlp http %s)\n", queryURL, otlpGRPCAddr, otlpHTTPURL)
+	}
+
+	log.Printf("✅ Shared Jaeger container started at %s", queryURL)
+
+	return nil
+}
+
+// stopContainer para o container se não estiver sendo reutilizado
+func (s *SharedJaeger) stopContainer(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.container != nil && !shouldReuseContainer() {
+		if isDebugEnabled() {
+			fmt.Println("🛑 Stopping shared Jaeger container...")
+		}
+		return s.container.Terminate(ctx)
+	}
+
+	return nil
+}
+
+// FindTraces consulta os traces do serviço (GET /api/traces), filtrando pela operação se informada
+// Considera apenas traces iniciados desde since
+func (s *SharedJaeger) FindTraces(ctx context.Context, service, operation string, since time.Time) ([]JaegerTrace, error) {
+	params := url.Values{
+		"service": {service},
+		"limit":   {"1000"},
+		"start":   {strconv.FormatInt(since.UnixMicro(), 10)},
+		"end":     {strconv.FormatInt(time.Now().Add(time.Minute).UnixMicro(), 10)},
+	}
+	if operation != "" {
+		params.Set("operation", operation)
+	}
+
+	var response struct {
+		Data []struct {
+			TraceID string `json:"traceID"`
+			Spans   []struct {
+				TraceID       string `json:"traceID"`
+				SpanID        string `json:"spanID"`
+				OperationName string `json:"operationName"`
+				References    []struct {
+					RefType string `json:"refType"`
+					SpanID  string `json:"spanID"`
+				} `json:"references"`
+				StartTime int64 `json:"startTime"`
+				Duration  int64 `json:"duration"`
+				Tags      []struct {
+					Key   string      `json:"key"`
+					Value interface{} `json:"value"`
+				} `json:"tags"`
+				ProcessID string `json:"processID"`
+			} `json:"spans"`
+			Processes map[string]struct {
+				ServiceName string `json:"serviceName"`
+			} `json:"processes"`
+		} `json:"data"`
+	}
+	if err := s.request(ctx, "/api/traces?"+params.Encode(), &response); err != nil {
+		return nil, err
+	}
+
+	traces := make([]JaegerTrace, 0, len(response.Data))
+	for _, data := range response.Data {
+		trace := JaegerTrace{TraceID: data.TraceID}
+		for _, raw := range data.Spans {
+			span := JaegerSpan{
+				TraceID:       raw.TraceID,
+				SpanID:        raw.SpanID,
+				OperationName: raw.OperationName,
+				ServiceName:   data.Processes[raw.ProcessID].ServiceName,
+				StartTime:     time.UnixMicro(raw.StartTime),
+				Duration:      time.Duration(raw.Duration) * time.Microsecond,
+				Tags:          make(map[string]interface{}, len(raw.Tags)),
+			}
+			for _, ref := range raw.References {
+				if ref.RefType == "CHILD_OF" {
+					span.ParentSpanID = ref.SpanID
+				}
+			}
+			for _, tag := range raw.Tags {
+				span.Tags[tag.Key] = tag.Value
+			}
+			trace.Spans = append(trace.Spans, span)
+		}
+		traces = append(traces, trace)
+	}
+	return traces, nil
+}
+
+// Services lista os serviços que já enviaram spans (GET /api/services)
+func (s *SharedJaeger) Services(ctx context.Context) ([]string, error) {
+	var response struct {
+		Data []string `json:"data"`
+	}
+	if err := s.request(ctx, "/api/services", &response); err != nil {
+		return nil, err
+	}
+	return response.Data, nil
+}
+
+// request executa um GET na API de consulta do Jaeger e decodifica a resposta em output
+func (s *SharedJaeger) request(ctx context.Context, path string, output interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.QueryURL()+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create jaeger request: %w", err)
+	}
+
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("jaeger request %s failed: %w", path, err)
+	}
+	defer res.Body.Close()
+
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read jaeger response: %w", err)
+	}
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("jaeger request %s failed: %s: %s", path, res.Status, raw)
+	}
+
+	if err := json.Unmarshal(raw, output); err != nil {
+		return fmt.Errorf("failed to decode jaeger response: %w", err)
+	}
+	return nil
+}
+
+// testConnection verifica se a API de consulta do Jaeger responde
+func (s *SharedJaeger) testConnection(ctx context.Context) error {
+	ctxPing, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if s.queryURL == "" {
+		return fmt.Errorf("jaeger url not available")
+	}
+
+	req, err := http.NewRequestWithContext(ctxPing, http.MethodGet, s.queryURL+"/api/services", nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("jaeger error: %s", res.Status)
+	}
+	return nil
+}
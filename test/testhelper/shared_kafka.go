@@ -0,0 +1,318 @@
+package testhelper
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/testcontainers/testcontainers-go"
+	tcexec "github.com/testcontainers/testcontainers-go/exec"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// kafkaContainerHost é o hostname anunciado pelo broker; os containers que usam o Kafka compartilhado
+// o resolvem via extra host com o IP do broker
+const kafkaContainerHost = "kafka"
+
+var (
+	sharedKafka *SharedKafka
+	kafkaOnce   sync.Once
+)
+
+// SharedKafka gerencia um broker Kafka (KRaft, nó único) usado por Kafka Connect e Schema Registry
+type SharedKafka struct {
+	mu               sync.RWMutex
+	container        testcontainers.Container
+	bootstrapServers string
+	refCount         int32
+	startOnce        sync.Once
+	startErr         error
+	started          bool
+}
+
+// GetSharedKafka retorna a instância singleton do Kafka compartilhado
+func GetSharedKafka() *SharedKafka {
+	kafkaOnce.Do(func() {
+		sharedKafka = &SharedKafka{}
+	})
+	return sharedKafka
+}
+
+// Start inicializa o broker Kafka se ainda não estiver rodando
+func (s *SharedKafka) Start(ctx context.Context) error {
+	// Primeiro, tenta reutilizar container existente (sem lock global)
+	s.mu.RLock()
+	if s.started && s.bootstrapServers != "" {
+		s.mu.RUnlock()
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+	} else {
+		s.mu.RUnlock()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Double-check: outro goroutine pode ter criado enquanto aguardava lock
+	if s.started && s.bootstrapServers != "" {
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+		// Conexão perdida, reset para tentar novamente
+		s.started = false
+		s.startOnce = sync.Once{}
+	}
+
+	s.startOnce.Do(func() {
+		s.startErr = s.startContainer(ctx)
+		if s.startErr == nil {
+			s.started = true
+		}
+	})
+
+	if !s.started {
+		return fmt.Errorf("shared kafka not started: %w", s.startErr)
+	}
+
+	atomic.AddInt32(&s.refCount, 1)
+	return nil
+}
+
+// Stop decrementa o contador de referências e para o container se necessário
+func (s *SharedKafka) Stop(ctx context.Context) error {
+	if atomic.AddInt32(&s.refCount, -1) <= 0 {
+		return s.stopContainer(ctx)
+	}
+	return nil
+}
+
+// BootstrapServers retorna o endereço do broker; com o container compartilhado é "kafka:9092", acessível
+// apenas por outros containers (ver KafkaHostForContainer)
+func (s *SharedKafka) BootstrapServers() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.bootstrapServers
+}
+
+// startContainer inicia o container Kafka ou usa um externo
+func (s *SharedKafka) startContainer(ctx context.Context) error {
+	// Verifica se deve usar Kafka externo
+	// Nesse modo as mensagens são lidas pelo kafka-console-consumer local
+	if useExternal, _ := strconv.ParseBool(os.Getenv("USE_EXTERNAL_KAFKA")); useExternal {
+		s.bootstrapServers = envOrDefault("KAFKA_BOOTSTRAP_SERVERS", "localhost:9092")
+
+		if _, err := kafkaConsoleConsumer(); err != nil {
+			return err
+		}
+
+		if err := s.testConnection(ctx); err != nil {
+			return externalFallback("kafka", "USE_EXTERNAL_KAFKA", s.bootstrapServers, err, func() error {
+				return s.setupTestcontainer(ctx)
+			})
+		}
+
+		if isDebugEnabled() {
+			fmt.Printf("✅ Using external Kafka at %s\n", s.bootstrapServers)
+		}
+		return nil
+	}
+
+	return s.setupTestcontainer(ctx)
+}
+
+// setupTestcontainer cria e inicia um broker Kafka em modo KRaft anunciando o hostname "kafka"
+func (s *SharedKafka) setupTestcontainer(ctx context.Context) error {
+	if isDebugEnabled() {
+		fmt.Println("🚀 Starting shared Kafka container...")
+	}
+
+	req := testcontainers.ContainerRequest{
+		Image:        "quay.io/debezium/kafka:" + debeziumVersion,
+		ExposedPorts: []string{"9092/tcp"},
+		Name:         "shared-kafka-test",
+		Env: map[string]string{
+			"CLUSTER_ID":                     "5Yr1SIgYQz-b-dgRabWx4g",
+			"NODE_ID":                        "1",
+			"NODE_ROLE":                      "combined",
+			"KAFKA_CONTROLLER_QUORUM_VOTERS": "1@" + kafkaContainerHost + ":9093",
+			"KAFKA_LISTENERS":                "PLAINTEXT://0.0.0.0:9092,CONTROLLER://0.0.0.0:9093",
+			"KAFKA_ADVERTISED_LISTENERS":     "PLAINTEXT://" + kafkaContainerHost + ":9092",
+		},
+		ConfigModifier: func(config *dockercontainer.Config) {
+			config.Hostname = kafkaContainerHost
+		},
+		WaitingFor: wait.ForLog("Kafka Server started").WithStartupTimeout(2 * time.Minute),
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+		Reuse:            shouldReuseContainer(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start kafka container: %w", err)
+	}
+
+	s.container = container
+	s.bootstrapServers = kafkaContainerHost + ":9092"
+
+	if isDebugEnabled() {
+		fmt.Printf("✅ Shared Kafka container started (%s)\n", s.bootstrapServers)
+	}
+
+	log.Printf("✅ Shared Kafka container started at %s", s.bootstrapServers)
+
+	return nil
+}
+
+// stopContainer para o container se não estiver sendo reutilizado
+func (s *SharedKafka) stopContainer(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.container != nil && !shouldReuseContainer() {
+		if isDebugEnabled() {
+			fmt.Println("🛑 Stopping shared Kafka container...")
+		}
+		return s.container.Terminate(ctx)
+	}
+
+	return nil
+}
+
+// KafkaHostForContainer retorna o bootstrap do Kafka para outro container e o extra host que resolve o
+// broker compartilhado; com Kafka externo em localhost usa host.testcontainers.internal
+func KafkaHostForContainer(ctx context.Context, kafka *SharedKafka) (string, []string, []int, error) {
+	kafka.mu.RLock()
+	container := kafka.container
+	bootstrap := kafka.bootstrapServers
+	kafka.mu.RUnlock()
+
+	if container != nil {
+		ip, err := container.ContainerIP(ctx)
+		if err != nil {
+			return "", nil, nil, fmt.Errorf("failed to get kafka container ip: %w", err)
+		}
+		return bootstrap, []string{kafkaContainerHost + ":" + ip}, nil, nil
+	}
+
+	var ports []int
+	servers := strings.Split(bootstrap, ",")
+	for i, server := range servers {
+		servers[i] = rewriteAddrHost(server, func(port string) {
+			if p, err := strconv.Atoi(port); err == nil {
+				ports = append(ports, p)
+			}
+		})
+	}
+	return strings.Join(servers, ","), nil, ports, nil
+}
+
+// ConsumeMessages lê as mensagens do tópico desde o início, aguardando novas mensagens por até idle
+// Retorna o valor de cada mensagem (uma por linha do kafka-console-consumer)
+func (s *SharedKafka) ConsumeMessages(ctx context.Context, topic string, idle time.Duration) ([]string, error) {
+	s.mu.RLock()
+	container := s.container
+	bootstrap := s.bootstrapServers
+	s.mu.RUnlock()
+
+	// Dentro do container do broker o listener é acessado por localhost
+	if container != nil {
+		bootstrap = "localhost:9092"
+	}
+
+	args := []string{
+		"--bootstrap-server", bootstrap,
+		"--topic", topic,
+		"--from-beginning",
+		"--timeout-ms", strconv.FormatInt(idle.Milliseconds(), 10),
+	}
+
+	var output []byte
+	if container != nil {
+		code, reader, err := container.Exec(ctx, append([]string{"/kafka/bin/kafka-console-consumer.sh"}, args...), tcexec.Multiplexed())
+		if err != nil {
+			return nil, fmt.Errorf("failed to consume topic %s: %w", topic, err)
+		}
+		output, _ = io.ReadAll(reader)
+		if code != 0 && !bytes.Contains(output, []byte("Processed a total of")) {
+			return nil, fmt.Errorf("kafka-console-consumer exited with code %d: %s", code, strings.TrimSpace(string(output)))
+		}
+	} else {
+		consumer, err := kafkaConsoleConsumer()
+		if err != nil {
+			return nil, err
+		}
+		// O consumidor termina com erro no timeout; a saída com as mensagens continua válida
+		output, err = exec.CommandContext(ctx, consumer, args...).CombinedOutput()
+		if err != nil && !bytes.Contains(output, []byte("Processed a total of")) {
+			return nil, fmt.Errorf("kafka-console-consumer failed: %w: %s", err, strings.TrimSpace(string(output)))
+		}
+	}
+
+	return parseConsoleMessages(output), nil
+}
+
+// parseConsoleMessages separa as mensagens da saída do consumidor, descartando os logs do próprio
+// kafka-console-consumer (misturados pelo exec multiplexado)
+func parseConsoleMessages(output []byte) []string {
+	var messages []string
+	for _, line := range strings.Split(string(output), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" || strings.HasPrefix(line, "Processed a total of") || strings.Contains(line, "] WARN ") ||
+			strings.Contains(line, "] ERROR ") || strings.HasPrefix(line, "WARNING:") {
+			continue
+		}
+		messages = append(messages, line)
+	}
+	return messages
+}
+
+// kafkaConsoleConsumer localiza o kafka-console-consumer local (modo externo)
+func kafkaConsoleConsumer() (string, error) {
+	for _, name := range []string{"kafka-console-consumer", "kafka-console-consumer.sh"} {
+		if path, err := exec.LookPath(name); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("kafka-console-consumer is required to use an external kafka")
+}
+
+// testConnection testa se o broker aceita conexões
+func (s *SharedKafka) testConnection(ctx context.Context) error {
+	if s.container != nil {
+		state, err := s.container.State(ctx)
+		if err != nil {
+			return err
+		}
+		if !state.Running {
+			return fmt.Errorf("kafka container is not running")
+		}
+		return nil
+	}
+
+	if s.bootstrapServers == "" {
+		return fmt.Errorf("kafka bootstrap servers not available")
+	}
+
+	dialer := net.Dialer{Timeout: 5 * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", strings.Split(s.bootstrapServers, ",")[0])
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
@@ -0,0 +1,391 @@
+package testhelper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// KibanaSavedObjectTypes são os tipos de saved object removidos pela limpeza do Kibana
+var KibanaSavedObjectTypes = []string{"dashboard", "visualization", "lens", "search", "index-pattern", "tag", "map"}
+
+var (
+	sharedKibana *SharedKibana
+	kibanaOnce   sync.Once
+)
+
+// KibanaSavedObject é um saved object retornado pela API do Kibana
+type KibanaSavedObject struct {
+	ID         string                 `json:"id"`
+	Type       string                 `json:"type"`
+	Attributes map[string]interface{} `json:"attributes"`
+}
+
+// Title retorna o atributo title do saved object
+func (o KibanaSavedObject) Title() string {
+	title, _ := o.Attributes["title"].(string)
+	return title
+}
+
+// SharedKibana gerencia um container Kibana ligado ao Elasticsearch compartilhado
+type SharedKibana struct {
+	mu         sync.RWMutex
+	container  testcontainers.Container
+	url        string
+	httpClient *http.Client
+	refCount   int32
+	startOnce  sync.Once
+	startErr   error
+	started    bool
+}
+
+// GetSharedKibana retorna a instância singleton do Kibana compartilhado
+func GetSharedKibana() *SharedKibana {
+	kibanaOnce.Do(func() {
+		sharedKibana = &SharedKibana{
+			httpClient: &http.Client{Timeout: 60 * time.Second},
+		}
+	})
+	return sharedKibana
+}
+
+// Start inicializa o container Kibana apontando para o Elasticsearch compartilhado (já iniciado)
+func (s *SharedKibana) Start(ctx context.Context, es *SharedElasticsearch) error {
+	// Primeiro, tenta reutilizar container existente (sem lock global)
+	s.mu.RLock()
+	if s.started && s.url != "" {
+		s.mu.RUnlock()
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+	} else {
+		s.mu.RUnlock()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Double-check: outro goroutine pode ter criado enquanto aguardava lock
+	if s.started && s.url != "" {
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+		// Conexão perdida, reset para tentar novamente
+		s.started = false
+		s.startOnce = sync.Once{}
+	}
+
+	s.startOnce.Do(func() {
+		s.startErr = s.startContainer(ctx, es)
+		if s.startErr == nil {
+			s.started = true
+		}
+	})
+
+	if !s.started {
+		return fmt.Errorf("shared kibana not started: %w", s.startErr)
+	}
+
+	atomic.AddInt32(&s.refCount, 1)
+	return nil
+}
+
+// Stop decrementa o contador de referências e para o container se necessário
+func (s *SharedKibana) Stop(ctx context.Context) error {
+	if atomic.AddInt32(&s.refCount, -1) <= 0 {
+		return s.stopContainer(ctx)
+	}
+	return nil
+}
+
+// GetURL retorna a URL do Kibana
+func (s *SharedKibana) GetURL() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.url
+}
+
+// startContainer inicia o container Kibana ou usa um externo
+func (s *SharedKibana) startContainer(ctx context.Context, es *SharedElasticsearch) error {
+	// Verifica se deve usar Kibana externo
+	if useExternal, _ := strconv.ParseBool(os.Getenv("USE_EXTERNAL_KIBANA")); useExternal {
+		s.url = strings.TrimRight(envOrDefault("KIBANA_URL", "http://localhost:5601"), "/")
+
+		if err := s.testConnection(ctx); err != nil {
+			return externalFallback("kibana", "USE_EXTERNAL_KIBANA", s.url, err, func() error {
+				return s.setupTestcontainer(ctx, es)
+			})
+		}
+
+		if isDebugEnabled() {
+			fmt.Printf("✅ Using external Kibana at %s\n", s.url)
+		}
+		return nil
+	}
+
+	return s.setupTestcontainer(ctx, es)
+}
+
+// elasticsearchHostForContainer retorna o endereço do ES visto de dentro de outro container (Kibana, Logstash):
+// o IP do container do ES na rede bridge do docker ou, com ES externo local, o host via HostAccessPorts
+func elasticsearchHostForContainer(ctx context.Context, es *SharedElasticsearch) (string, []int, error) {
+	es.mu.RLock()
+	container := es.container
+	esURL := es.url
+	es.mu.RUnlock()
+
+	if container != nil {
+		ip, err := container.ContainerIP(ctx)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to get elasticsearch container ip: %w", err)
+		}
+		return "http://" + ip + ":9200", nil, nil
+	}
+
+	var ports []int
+	host := rewriteURLHost(esURL, func(port string) {
+		if p, err := strconv.Atoi(port); err == nil {
+			ports = append(ports, p)
+		}
+	})
+	return host, ports, nil
+}
+
+// setupTestcontainer cria e inicia um container Kibana da mesma versão do Elasticsearch
+func (s *SharedKibana) setupTestcontainer(ctx context.Context, es *SharedElasticsearch) error {
+	if isDebugEnabled() {
+		fmt.Println("🚀 Starting shared Kibana container...")
+	}
+
+	esHost, hostPorts, err := elasticsearchHostForContainer(ctx, es)
+	if err != nil {
+		return err
+	}
+
+	req := testcontainers.ContainerRequest{
+		Image:        "docker.elastic.co/kibana/kibana:8.2.0",
+		ExposedPorts: []string{"5601/tcp"},
+		Name:         "shared-kibana-test",
+		Env: map[string]string{
+			"ELASTICSEARCH_HOSTS": esHost,
+			"TELEMETRY_ENABLED":   "false",
+			"NEWSFEED_ENABLED":    "false",
+		},
+		HostAccessPorts: hostPorts,
+		WaitingFor: wait.ForHTTP("/api/status").WithPort("5601/tcp").
+			WithStatusCodeMatcher(func(status int) bool { return status == http.StatusOK }).
+			WithStartupTimeout(3 * time.Minute),
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+		Reuse:            shouldReuseContainer(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start kibana container: %w", err)
+	}
+
+	endpoint, err := container.PortEndpoint(ctx, "5601/tcp", "http")
+	if err != nil {
+		return fmt.Errorf("failed to get kibana endpoint: %w", err)
+	}
+
+	s.container = container
+	s.url = endpoint
+
+	if err := s.waitAvailable(ctx); err != nil {
+		return err
+	}
+
+	if isDebugEnabled() {
+		fmt.Printf("✅ Shared Kibana container started at %s (elasticsearch %s)\n", s.url, esHost)
+	}
+
+	log.Printf("✅ Shared Kibana container started at %s", s.url)
+
+	return nil
+}
+
+// waitAvailable aguarda o status geral do Kibana ficar "available" (a API responde antes das migrações)
+func (s *SharedKibana) waitAvailable(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Minute)
+	defer cancel()
+
+	for {
+		raw, err := s.request(ctx, http.MethodGet, "/api/status", "", nil)
+		if err == nil {
+			var status struct {
+				Status struct {
+					Overall struct {
+						Level string `json:"level"`
+					} `json:"overall"`
+				} `json:"status"`
+			}
+			if json.Unmarshal(raw, &status) == nil && status.Status.Overall.Level == "available" {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("kibana not available: %w", ctx.Err())
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// stopContainer para o container se não estiver sendo reutilizado
+func (s *SharedKibana) stopContainer(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.container != nil && !shouldReuseContainer() {
+		if isDebugEnabled() {
+			fmt.Println("🛑 Stopping shared Kibana container...")
+		}
+		return s.container.Terminate(ctx)
+	}
+
+	return nil
+}
+
+// Request executa uma chamada à API do Kibana (com o header kbn-xsrf exigido em escritas)
+func (s *SharedKibana) Request(ctx context.Context, method, path string, body interface{}) ([]byte, error) {
+	var raw []byte
+	if body != nil {
+		var err error
+		if raw, err = json.Marshal(body); err != nil {
+			return nil, fmt.Errorf("failed to marshal kibana request: %w", err)
+		}
+	}
+	return s.request(ctx, method, path, "application/json", raw)
+}
+
+// ImportSavedObjects importa um export NDJSON de saved objects (dashboards, index patterns...)
+// Com overwrite, objetos com o mesmo id são substituídos
+func (s *SharedKibana) ImportSavedObjects(ctx context.Context, ndjson []byte, overwrite bool) error {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", "export.ndjson")
+	if err != nil {
+		return fmt.Errorf("failed to build kibana import: %w", err)
+	}
+	part.Write(ndjson)
+	writer.Close()
+
+	raw, err := s.request(ctx, http.MethodPost, "/api/saved_objects/_import?overwrite="+strconv.FormatBool(overwrite), writer.FormDataContentType(), body.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to import kibana saved objects: %w", err)
+	}
+
+	var result struct {
+		Success bool              `json:"success"`
+		Errors  []json.RawMessage `json:"errors"`
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return fmt.Errorf("failed to decode kibana import response: %w", err)
+	}
+	if !result.Success {
+		return fmt.Errorf("kibana import failed: %d errors: %s", len(result.Errors), string(raw))
+	}
+	return nil
+}
+
+// FindSavedObjects lista os saved objects do tipo (até 10000)
+func (s *SharedKibana) FindSavedObjects(ctx context.Context, objectType string) ([]KibanaSavedObject, error) {
+	query := url.Values{"type": {objectType}, "per_page": {"10000"}}
+	raw, err := s.request(ctx, http.MethodGet, "/api/saved_objects/_find?"+query.Encode(), "", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find kibana saved objects: %w", err)
+	}
+
+	var result struct {
+		SavedObjects []KibanaSavedObject `json:"saved_objects"`
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode kibana saved objects: %w", err)
+	}
+	return result.SavedObjects, nil
+}
+
+// DeleteSavedObjects remove os saved objects dos tipos em KibanaSavedObjectTypes
+// Tipos que não existem na versão do Kibana são ignorados
+func (s *SharedKibana) DeleteSavedObjects(ctx context.Context) error {
+	for _, objectType := range KibanaSavedObjectTypes {
+		objects, err := s.FindSavedObjects(ctx, objectType)
+		if err != nil {
+			continue
+		}
+		for _, object := range objects {
+			path := "/api/saved_objects/" + url.PathEscape(object.Type) + "/" + url.PathEscape(object.ID) + "?force=true"
+			if _, err := s.request(ctx, http.MethodDelete, path, "", nil); err != nil && !strings.Contains(err.Error(), "404") {
+				return fmt.Errorf("failed to delete kibana %s %s: %w", object.Type, object.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// request executa a chamada HTTP com o header kbn-xsrf
+func (s *SharedKibana) request(ctx context.Context, method, path, contentType string, body []byte) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.url+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build kibana request: %w", err)
+	}
+	req.Header.Set("kbn-xsrf", "true")
+	if contentType != "" && body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kibana response: %w", err)
+	}
+
+	if res.StatusCode >= 300 {
+		return nil, fmt.Errorf("kibana error: %s %s", res.Status, strings.TrimSpace(string(raw)))
+	}
+	return raw, nil
+}
+
+// testConnection verifica se o Kibana responde
+func (s *SharedKibana) testConnection(ctx context.Context) error {
+	ctxPing, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if s.url == "" {
+		return fmt.Errorf("kibana url not available")
+	}
+
+	_, err := s.request(ctxPing, http.MethodGet, "/api/status", "", nil)
+	return err
+}
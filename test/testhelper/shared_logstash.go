@@ -0,0 +1,281 @@
+package testhelper
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// Portas dos inputs expostos pelo container Logstash; o pipeline deve usar estas portas
+const (
+	LogstashBeatsPort = "5044/tcp"
+	LogstashTCPPort   = "5000/tcp"
+)
+
+var (
+	sharedLogstashByPipeline   = make(map[string]*SharedLogstash)
+	sharedLogstashByPipelineMu sync.Mutex
+)
+
+// SharedLogstash gerencia um container Logstash por arquivo de pipeline, ligado ao Elasticsearch compartilhado
+type SharedLogstash struct {
+	mu        sync.RWMutex
+	container testcontainers.Container
+	pipeline  string
+	beatsAddr string
+	tcpAddr   string
+	refCount  int32
+	startOnce sync.Once
+	startErr  error
+	started   bool
+}
+
+// GetSharedLogstash retorna o Logstash compartilhado do arquivo de pipeline (um container por pipeline)
+func GetSharedLogstash(pipelineConfPath string) *SharedLogstash {
+	path, err := filepath.Abs(pipelineConfPath)
+	if err != nil {
+		path = pipelineConfPath
+	}
+
+	sharedLogstashByPipelineMu.Lock()
+	defer sharedLogstashByPipelineMu.Unlock()
+
+	ls, ok := sharedLogstashByPipeline[path]
+	if !ok {
+		ls = &SharedLogstash{pipeline: path}
+		sharedLogstashByPipeline[path] = ls
+	}
+	return ls
+}
+
+// Start inicializa o container Logstash com o pipeline, apontando para o Elasticsearch compartilhado
+func (s *SharedLogstash) Start(ctx context.Context, es *SharedElasticsearch) error {
+	// Primeiro, tenta reutilizar container existente (sem lock global)
+	s.mu.RLock()
+	if s.started && s.tcpAddr != "" {
+		s.mu.RUnlock()
+		if err := s.testConnection(); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+	} else {
+		s.mu.RUnlock()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Double-check: outro goroutine pode ter criado enquanto aguardava lock
+	if s.started && s.tcpAddr != "" {
+		if err := s.testConnection(); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+		// Conexão perdida, reset para tentar novamente
+		s.started = false
+		s.startOnce = sync.Once{}
+	}
+
+	s.startOnce.Do(func() {
+		s.startErr = s.startContainer(ctx, es)
+		if s.startErr == nil {
+			s.started = true
+		}
+	})
+
+	if !s.started {
+		return fmt.Errorf("shared logstash not started: %w", s.startErr)
+	}
+
+	atomic.AddInt32(&s.refCount, 1)
+	return nil
+}
+
+// Stop decrementa o contador de referências e para o container se necessário
+func (s *SharedLogstash) Stop(ctx context.Context) error {
+	if atomic.AddInt32(&s.refCount, -1) <= 0 {
+		return s.stopContainer(ctx)
+	}
+	return nil
+}
+
+// BeatsAddr retorna o endereço host:porta do input beats (5044)
+func (s *SharedLogstash) BeatsAddr() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.beatsAddr
+}
+
+// TCPAddr retorna o endereço host:porta do input tcp (5000)
+func (s *SharedLogstash) TCPAddr() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tcpAddr
+}
+
+// containerName gera o nome do container a partir do conteúdo do pipeline, para que o reuse não
+// devolva um container com outro pipeline
+func (s *SharedLogstash) containerName(conf []byte) string {
+	sum := sha1.Sum(conf)
+	return "shared-logstash-test-" + hex.EncodeToString(sum[:4])
+}
+
+// startContainer inicia o container Logstash ou usa um externo
+func (s *SharedLogstash) startContainer(ctx context.Context, es *SharedElasticsearch) error {
+	// Verifica se deve usar Logstash externo (já configurado com o pipeline)
+	if useExternal, _ := strconv.ParseBool(os.Getenv("USE_EXTERNAL_LOGSTASH")); useExternal {
+		s.tcpAddr = envOrDefault("LOGSTASH_TCP_ADDR", "localhost:5000")
+		s.beatsAddr = envOrDefault("LOGSTASH_BEATS_ADDR", "localhost:5044")
+
+		if err := s.testConnection(); err != nil {
+			return externalFallback("logstash", "USE_EXTERNAL_LOGSTASH", s.tcpAddr, err, func() error {
+				return s.setupTestcontainer(ctx, es)
+			})
+		}
+
+		if isDebugEnabled() {
+			fmt.Printf("✅ Using external Logstash at %s\n", s.tcpAddr)
+		}
+		return nil
+	}
+
+	return s.setupTestcontainer(ctx, es)
+}
+
+// setupTestcontainer cria e inicia um container Logstash com o pipeline montado
+// O endereço do ES fica disponível para o pipeline em ${ELASTICSEARCH_HOSTS}
+func (s *SharedLogstash) setupTestcontainer(ctx context.Context, es *SharedElasticsearch) error {
+	if isDebugEnabled() {
+		fmt.Printf("🚀 Starting shared Logstash container (%s)...\n", filepath.Base(s.pipeline))
+	}
+
+	conf, err := os.ReadFile(s.pipeline)
+	if err != nil {
+		return fmt.Errorf("failed to read logstash pipeline %s: %w", s.pipeline, err)
+	}
+
+	esHost, hostPorts, err := elasticsearchHostForContainer(ctx, es)
+	if err != nil {
+		return err
+	}
+
+	req := testcontainers.ContainerRequest{
+		Image:        "docker.elastic.co/logstash/logstash:8.2.0",
+		ExposedPorts: []string{LogstashBeatsPort, LogstashTCPPort},
+		Name:         s.containerName(conf),
+		Env: map[string]string{
+			"ELASTICSEARCH_HOSTS":      esHost,
+			"XPACK_MONITORING_ENABLED": "false",
+			"LS_JAVA_OPTS":             "-Xms256m -Xmx256m",
+			"PIPELINE_BATCH_DELAY":     "5",
+		},
+		Files: []testcontainers.ContainerFile{{
+			HostFilePath:      s.pipeline,
+			ContainerFilePath: "/usr/share/logstash/pipeline/logstash.conf",
+			FileMode:          0o644,
+		}},
+		HostAccessPorts: hostPorts,
+		WaitingFor:      wait.ForLog("Pipelines running").WithStartupTimeout(3 * time.Minute),
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+		Reuse:            shouldReuseContainer(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start logstash container: %w", err)
+	}
+
+	tcpAddr, err := container.PortEndpoint(ctx, LogstashTCPPort, "")
+	if err != nil {
+		return fmt.Errorf("failed to get logstash tcp endpoint: %w", err)
+	}
+	beatsAddr, err := container.PortEndpoint(ctx, LogstashBeatsPort, "")
+	if err != nil {
+		return fmt.Errorf("failed to get logstash beats endpoint: %w", err)
+	}
+
+	s.container = container
+	s.tcpAddr = tcpAddr
+	s.beatsAddr = beatsAddr
+
+	if isDebugEnabled() {
+		fmt.Printf("✅ Shared Logstash container started (tcp %s, beats %s, elasticsearch %s)\n", tcpAddr, beatsAddr, esHost)
+	}
+
+	log.Printf("✅ Shared Logstash container started at %s", tcpAddr)
+
+	return nil
+}
+
+// stopContainer para o container se não estiver sendo reutilizado
+func (s *SharedLogstash) stopContainer(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.container != nil && !shouldReuseContainer() {
+		if isDebugEnabled() {
+			fmt.Println("🛑 Stopping shared Logstash container...")
+		}
+		return s.container.Terminate(ctx)
+	}
+
+	return nil
+}
+
+// SendLines envia linhas ao input tcp (uma mensagem por linha, ex.: codec json_lines)
+func (s *SharedLogstash) SendLines(ctx context.Context, lines ...string) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", s.TCPAddr())
+	if err != nil {
+		return fmt.Errorf("failed to connect to logstash tcp input: %w", err)
+	}
+	defer conn.Close()
+
+	for _, line := range lines {
+		if _, err := conn.Write([]byte(line + "\n")); err != nil {
+			return fmt.Errorf("failed to send line to logstash: %w", err)
+		}
+	}
+	return nil
+}
+
+// SendEvents envia os eventos em JSON ao input tcp (um por linha, para o codec json_lines)
+func (s *SharedLogstash) SendEvents(ctx context.Context, events ...interface{}) error {
+	lines := make([]string, 0, len(events))
+	for _, event := range events {
+		raw, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to marshal logstash event: %w", err)
+		}
+		lines = append(lines, string(raw))
+	}
+	return s.SendLines(ctx, lines...)
+}
+
+// testConnection verifica se o input tcp aceita conexões
+func (s *SharedLogstash) testConnection() error {
+	if s.tcpAddr == "" {
+		return fmt.Errorf("logstash address not available")
+	}
+
+	conn, err := net.DialTimeout("tcp", s.tcpAddr, 5*time.Second)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
@@ -0,0 +1,340 @@
+package testhelper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+var (
+	sharedMailHog *SharedMailHog
+	mailHogOnce   sync.Once
+)
+
+// MailHogMessage é um email capturado pelo MailHog
+type MailHogMessage struct {
+	ID      string
+	From    string
+	To      []string
+	Subject string
+	Body    string
+	Created time.Time
+}
+
+// SharedMailHog gerencia um container MailHog (servidor SMTP de captura) compartilhado entre testes
+type SharedMailHog struct {
+	mu         sync.RWMutex
+	container  testcontainers.Container
+	smtpAddr   string
+	apiURL     string
+	httpClient *http.Client
+	refCount   int32
+	startOnce  sync.Once
+	startErr   error
+	started    bool
+}
+
+// GetSharedMailHog retorna a instância singleton do MailHog compartilhado
+func GetSharedMailHog() *SharedMailHog {
+	mailHogOnce.Do(func() {
+		sharedMailHog = &SharedMailHog{
+			httpClient: &http.Client{Timeout: 30 * time.Second},
+		}
+	})
+	return sharedMailHog
+}
+
+// Start inicializa o container MailHog compartilhado
+func (s *SharedMailHog) Start(ctx context.Context) error {
+	// Primeiro, tenta reutilizar container existente (sem lock global)
+	s.mu.RLock()
+	if s.started && s.apiURL != "" {
+		s.mu.RUnlock()
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+	} else {
+		s.mu.RUnlock()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Double-check: outro goroutine pode ter criado enquanto aguardava lock
+	if s.started && s.apiURL != "" {
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+		// Conexão perdida, reset para tentar novamente
+		s.started = false
+		s.startOnce = sync.Once{}
+	}
+
+	s.startOnce.Do(func() {
+		s.startErr = s.startContainer(ctx)
+		if s.startErr == nil {
+			s.started = true
+		}
+	})
+
+	if !s.started {
+		return fmt.Errorf("shared mailhog not started: %w", s.startErr)
+	}
+
+	atomic.AddInt32(&s.refCount, 1)
+	return nil
+}
+
+// Stop decrementa o contador de referências e para o container se necessário
+func (s *SharedMailHog) Stop(ctx context.Context) error {
+	if atomic.AddInt32(&s.refCount, -1) <= 0 {
+		return s.stopContainer(ctx)
+	}
+	return nil
+}
+
+// GetSMTPAddr retorna o endereço host:porta do servidor SMTP
+func (s *SharedMailHog) GetSMTPAddr() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.smtpAddr
+}
+
+// GetAPIURL retorna a URL da API HTTP (e da interface web) do MailHog
+func (s *SharedMailHog) GetAPIURL() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.apiURL
+}
+
+// startContainer inicia o container MailHog ou usa um externo
+func (s *SharedMailHog) startContainer(ctx context.Context) error {
+	// Verifica se deve usar MailHog externo
+	if useExternal, _ := strconv.ParseBool(os.Getenv("USE_EXTERNAL_MAILHOG")); useExternal {
+		smtpAddr := os.Getenv("MAILHOG_SMTP_ADDR")
+		if smtpAddr == "" {
+			smtpAddr = "localhost:1025"
+		}
+		apiURL := os.Getenv("MAILHOG_API_URL")
+		if apiURL == "" {
+			apiURL = "http://localhost:8025"
+		}
+		s.smtpAddr = smtpAddr
+		s.apiURL = strings.TrimRight(apiURL, "/")
+
+		if err := s.testConnection(ctx); err != nil {
+			return externalFallback("mailhog", "USE_EXTERNAL_MAILHOG", s.apiURL, err, func() error {
+				return s.setupTestcontainer(ctx)
+			})
+		}
+
+		if isDebugEnabled() {
+			fmt.Printf("✅ Using external MailHog at %s (SMTP %s)\n", s.apiURL, s.smtpAddr)
+		}
+		return nil
+	}
+
+	return s.setupTestcontainer(ctx)
+}
+
+// setupTestcontainer cria e inicia um container MailHog
+func (s *SharedMailHog) setupTestcontainer(ctx context.Context) error {
+	if isDebugEnabled() {
+		fmt.Println("🚀 Starting shared MailHog container...")
+	}
+
+	req := testcontainers.ContainerRequest{
+		Image:        "mailhog/mailhog:v1.0.1",
+		ExposedPorts: []string{"1025/tcp", "8025/tcp"},
+		Name:         "shared-mailhog-test",
+		WaitingFor: wait.ForAll(
+			wait.ForListeningPort("1025/tcp"),
+			wait.ForHTTP("/api/v2/messages").WithPort("8025/tcp"),
+		).WithDeadline(30 * time.Second),
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+		Reuse:            shouldReuseContainer(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start mailhog container: %w", err)
+	}
+
+	host, err := container.Host(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get mailhog host: %w", err)
+	}
+
+	smtpPort, err := container.MappedPort(ctx, "1025/tcp")
+	if err != nil {
+		return fmt.Errorf("failed to get mailhog smtp port: %w", err)
+	}
+
+	apiURL, err := container.PortEndpoint(ctx, "8025/tcp", "http")
+	if err != nil {
+		return fmt.Errorf("failed to get mailhog endpoint: %w", err)
+	}
+
+	s.container = container
+	s.smtpAddr = net.JoinHostPort(host, smtpPort.Port())
+	s.apiURL = apiURL
+
+	if err := s.testConnection(ctx); err != nil {
+		return fmt.Errorf("failed to connect to mailhog: %w", err)
+	}
+
+	if isDebugEnabled() {
+		fmt.Printf("✅ Shared MailHog container started (SMTP %s, API %s)\n", s.smtpAddr, apiURL)
+	}
+
+	log.Printf("✅ Shared MailHog container started at %s", s.smtpAddr)
+
+	return nil
+}
+
+// stopContainer para o container se não estiver sendo reutilizado
+func (s *SharedMailHog) stopContainer(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.container != nil && !shouldReuseContainer() {
+		if isDebugEnabled() {
+			fmt.Println("🛑 Stopping shared MailHog container...")
+		}
+		return s.container.Terminate(ctx)
+	}
+
+	return nil
+}
+
+// Messages retorna todos os emails capturados (mais recentes primeiro)
+func (s *SharedMailHog) Messages(ctx context.Context) ([]MailHogMessage, error) {
+	return s.search(ctx, "/api/v2/messages?limit=1000")
+}
+
+// MessagesTo retorna os emails enviados para o destinatário
+func (s *SharedMailHog) MessagesTo(ctx context.Context, addr string) ([]MailHogMessage, error) {
+	return s.search(ctx, "/api/v2/search?kind=to&limit=1000&query="+url.QueryEscape(addr))
+}
+
+// DeleteAll esvazia a caixa de entrada
+func (s *SharedMailHog) DeleteAll(ctx context.Context) error {
+	s.mu.RLock()
+	apiURL := s.apiURL
+	s.mu.RUnlock()
+
+	if err := mailHogRequest(ctx, s.httpClient, http.MethodDelete, apiURL+"/api/v1/messages", nil); err != nil {
+		return fmt.Errorf("failed to delete mailhog messages: %w", err)
+	}
+	return nil
+}
+
+// search consulta a API v2 e converte os itens para MailHogMessage
+func (s *SharedMailHog) search(ctx context.Context, path string) ([]MailHogMessage, error) {
+	s.mu.RLock()
+	apiURL := s.apiURL
+	s.mu.RUnlock()
+
+	var response struct {
+		Items []struct {
+			ID   string `json:"ID"`
+			From struct {
+				Mailbox string `json:"Mailbox"`
+				Domain  string `json:"Domain"`
+			} `json:"From"`
+			To []struct {
+				Mailbox string `json:"Mailbox"`
+				Domain  string `json:"Domain"`
+			} `json:"To"`
+			Content struct {
+				Headers map[string][]string `json:"Headers"`
+				Body    string              `json:"Body"`
+			} `json:"Content"`
+			Created time.Time `json:"Created"`
+		} `json:"items"`
+	}
+	if err := mailHogRequest(ctx, s.httpClient, http.MethodGet, apiURL+path, &response); err != nil {
+		return nil, fmt.Errorf("failed to query mailhog messages: %w", err)
+	}
+
+	messages := make([]MailHogMessage, 0, len(response.Items))
+	for _, item := range response.Items {
+		msg := MailHogMessage{
+			ID:      item.ID,
+			From:    item.From.Mailbox + "@" + item.From.Domain,
+			Body:    item.Content.Body,
+			Created: item.Created,
+		}
+		for _, to := range item.To {
+			msg.To = append(msg.To, to.Mailbox+"@"+to.Domain)
+		}
+		if subject := item.Content.Headers["Subject"]; len(subject) > 0 {
+			msg.Subject = subject[0]
+		}
+		messages = append(messages, msg)
+	}
+	return messages, nil
+}
+
+// mailHogRequest executa uma chamada à API HTTP do MailHog (sem lock, usado durante o Start)
+func mailHogRequest(ctx context.Context, client *http.Client, method, endpoint string, output interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("mailhog error: %s %s", res.Status, strings.TrimSpace(string(raw)))
+	}
+
+	if output != nil && len(raw) > 0 {
+		return json.Unmarshal(raw, output)
+	}
+	return nil
+}
+
+// testConnection verifica se a API HTTP e a porta SMTP estão respondendo
+func (s *SharedMailHog) testConnection(ctx context.Context) error {
+	ctxPing, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := mailHogRequest(ctxPing, s.httpClient, http.MethodGet, s.apiURL+"/api/v2/messages?limit=1", nil); err != nil {
+		return err
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctxPing, "tcp", s.smtpAddr)
+	if err != nil {
+		return fmt.Errorf("mailhog smtp not reachable: %w", err)
+	}
+	return conn.Close()
+}
@@ -0,0 +1,431 @@
+package testhelper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// DefaultMeilisearchMasterKey é a master key do container Meilisearch dos testes
+const DefaultMeilisearchMasterKey = "test-master-key-0123456789"
+
+var (
+	sharedMeilisearch *SharedMeilisearch
+	meilisearchOnce   sync.Once
+)
+
+// MeilisearchResult é a resposta de uma busca no Meilisearch
+type MeilisearchResult struct {
+	Hits               []map[string]interface{} `json:"hits"`
+	EstimatedTotalHits int                      `json:"estimatedTotalHits"`
+	TotalHits          int                      `json:"totalHits"`
+}
+
+// IDs retorna o campo id dos hits na ordem do resultado
+func (r *MeilisearchResult) IDs() []string {
+	ids := []string{}
+	for _, hit := range r.Hits {
+		switch id := hit["id"].(type) {
+		case string:
+			ids = append(ids, id)
+		case float64:
+			ids = append(ids, strconv.FormatFloat(id, 'f', -1, 64))
+		}
+	}
+	return ids
+}
+
+// Total retorna o total de hits (exato com paginação por page/hitsPerPage, estimado com limit/offset)
+func (r *MeilisearchResult) Total() int {
+	if r.TotalHits > 0 {
+		return r.TotalHits
+	}
+	return r.EstimatedTotalHits
+}
+
+// SharedMeilisearch gerencia um container Meilisearch compartilhado entre testes
+type SharedMeilisearch struct {
+	mu         sync.RWMutex
+	container  testcontainers.Container
+	url        string
+	masterKey  string
+	httpClient *http.Client
+	refCount   int32
+	startOnce  sync.Once
+	startErr   error
+	started    bool
+	indexes    map[string]bool
+}
+
+// GetSharedMeilisearch retorna a instância singleton do Meilisearch compartilhado
+func GetSharedMeilisearch() *SharedMeilisearch {
+	meilisearchOnce.Do(func() {
+		sharedMeilisearch = &SharedMeilisearch{
+			httpClient: &http.Client{Timeout: 30 * time.Second},
+			indexes:    make(map[string]bool),
+		}
+	})
+	return sharedMeilisearch
+}
+
+// Start inicializa o container Meilisearch compartilhado
+func (s *SharedMeilisearch) Start(ctx context.Context) error {
+	// Primeiro, tenta reutilizar container existente (sem lock global)
+	s.mu.RLock()
+	if s.started && s.url != "" {
+		s.mu.RUnlock()
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+	} else {
+		s.mu.RUnlock()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Double-check: outro goroutine pode ter criado enquanto aguardava lock
+	if s.started && s.url != "" {
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+		// Conexão perdida, reset para tentar novamente
+		s.started = false
+		s.startOnce = sync.Once{}
+		s.indexes = make(map[string]bool)
+	}
+
+	s.startOnce.Do(func() {
+		s.startErr = s.startContainer(ctx)
+		if s.startErr == nil {
+			s.started = true
+		}
+	})
+
+	if !s.started {
+		return fmt.Errorf("shared meilisearch not started: %w", s.startErr)
+	}
+
+	atomic.AddInt32(&s.refCount, 1)
+	return nil
+}
+
+// Stop decrementa o contador de referências e para o container se necessário
+func (s *SharedMeilisearch) Stop(ctx context.Context) error {
+	if atomic.AddInt32(&s.refCount, -1) <= 0 {
+		return s.stopContainer(ctx)
+	}
+	return nil
+}
+
+// GetURL retorna a URL do Meilisearch
+func (s *SharedMeilisearch) GetURL() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.url
+}
+
+// MasterKey retorna a master key usada nas chamadas (Authorization: Bearer)
+func (s *SharedMeilisearch) MasterKey() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.masterKey
+}
+
+// startContainer inicia o container Meilisearch ou usa um externo
+func (s *SharedMeilisearch) startContainer(ctx context.Context) error {
+	// Verifica se deve usar Meilisearch externo
+	if useExternal, _ := strconv.ParseBool(os.Getenv("USE_EXTERNAL_MEILISEARCH")); useExternal {
+		s.url = strings.TrimRight(envOrDefault("MEILISEARCH_URL", "http://localhost:7700"), "/")
+		s.masterKey = os.Getenv("MEILISEARCH_MASTER_KEY")
+
+		if err := s.testConnection(ctx); err != nil {
+			return externalFallback("meilisearch", "USE_EXTERNAL_MEILISEARCH", s.url, err, func() error {
+				return s.setupTestcontainer(ctx)
+			})
+		}
+
+		if isDebugEnabled() {
+			fmt.Printf("✅ Using external Meilisearch at %s\n", s.url)
+		}
+		return nil
+	}
+
+	return s.setupTestcontainer(ctx)
+}
+
+// setupTestcontainer cria e inicia um container Meilisearch com master key
+func (s *SharedMeilisearch) setupTestcontainer(ctx context.Context) error {
+	if isDebugEnabled() {
+		fmt.Println("🚀 Starting shared Meilisearch container...")
+	}
+
+	req := testcontainers.ContainerRequest{
+		Image:        "getmeili/meilisearch:v1.6",
+		ExposedPorts: []string{"7700/tcp"},
+		Name:         "shared-meilisearch-test",
+		Env: map[string]string{
+			"MEILI_MASTER_KEY":   DefaultMeilisearchMasterKey,
+			"MEILI_ENV":          "development",
+			"MEILI_NO_ANALYTICS": "true",
+			"MEILI_LOG_LEVEL":    "WARN",
+		},
+		WaitingFor: wait.ForHTTP("/health").WithPort("7700/tcp").WithStartupTimeout(time.Minute),
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+		Reuse:            shouldReuseContainer(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start meilisearch container: %w", err)
+	}
+
+	endpoint, err := container.PortEndpoint(ctx, "7700/tcp", "http")
+	if err != nil {
+		return fmt.Errorf("failed to get meilisearch endpoint: %w", err)
+	}
+
+	s.container = container
+	s.url = endpoint
+	s.masterKey = DefaultMeilisearchMasterKey
+
+	if isDebugEnabled() {
+		fmt.Printf("✅ Shared Meilisearch container started at %s\n", s.url)
+	}
+
+	log.Printf("✅ Shared Meilisearch container started at %s", s.url)
+
+	return nil
+}
+
+// stopContainer para o container se não estiver sendo reutilizado
+func (s *SharedMeilisearch) stopContainer(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.container != nil && !shouldReuseContainer() {
+		if isDebugEnabled() {
+			fmt.Println("🛑 Stopping shared Meilisearch container...")
+		}
+		return s.container.Terminate(ctx)
+	}
+
+	return nil
+}
+
+// CreateIndex cria o índice com a chave primária informada e aguarda a task (idempotente)
+func (s *SharedMeilisearch) CreateIndex(ctx context.Context, uid, primaryKey string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.indexes[uid] {
+		return nil
+	}
+
+	body := map[string]interface{}{"uid": uid}
+	if primaryKey != "" {
+		body["primaryKey"] = primaryKey
+	}
+
+	if err := s.runTask(ctx, http.MethodPost, "/indexes", body); err != nil && !strings.Contains(err.Error(), "index_already_exists") {
+		return fmt.Errorf("failed to create meilisearch index %s: %w", uid, err)
+	}
+
+	s.indexes[uid] = true
+	return nil
+}
+
+// UpdateSettings atualiza as settings do índice (filterableAttributes, sortableAttributes...)
+func (s *SharedMeilisearch) UpdateSettings(ctx context.Context, uid string, settings map[string]interface{}) error {
+	if err := s.runTask(ctx, http.MethodPatch, "/indexes/"+url.PathEscape(uid)+"/settings", settings); err != nil {
+		return fmt.Errorf("failed to update meilisearch settings of %s: %w", uid, err)
+	}
+	return nil
+}
+
+// AddDocuments adiciona (ou substitui) documentos e aguarda a indexação, deixando-os visíveis para a busca
+func (s *SharedMeilisearch) AddDocuments(ctx context.Context, uid string, docs ...interface{}) error {
+	if err := s.runTask(ctx, http.MethodPost, "/indexes/"+url.PathEscape(uid)+"/documents", docs); err != nil {
+		return fmt.Errorf("failed to add meilisearch documents to %s: %w", uid, err)
+	}
+	return nil
+}
+
+// Search executa uma busca no índice (q, filter, sort, limit...)
+func (s *SharedMeilisearch) Search(ctx context.Context, uid string, query map[string]interface{}) (*MeilisearchResult, error) {
+	raw, err := s.request(ctx, http.MethodPost, "/indexes/"+url.PathEscape(uid)+"/search", query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search meilisearch index %s: %w", uid, err)
+	}
+
+	var result MeilisearchResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode meilisearch response: %w", err)
+	}
+	return &result, nil
+}
+
+// DeleteDocuments remove todos os documentos do índice, mantendo as settings
+func (s *SharedMeilisearch) DeleteDocuments(ctx context.Context, uid string) error {
+	if err := s.runTask(ctx, http.MethodDelete, "/indexes/"+url.PathEscape(uid)+"/documents", nil); err != nil {
+		return fmt.Errorf("failed to delete meilisearch documents of %s: %w", uid, err)
+	}
+	return nil
+}
+
+// DeleteIndex remove o índice (ignora índice inexistente)
+func (s *SharedMeilisearch) DeleteIndex(ctx context.Context, uid string) error {
+	s.mu.Lock()
+	delete(s.indexes, uid)
+	s.mu.Unlock()
+
+	if err := s.runTask(ctx, http.MethodDelete, "/indexes/"+url.PathEscape(uid), nil); err != nil && !strings.Contains(err.Error(), "index_not_found") {
+		return fmt.Errorf("failed to delete meilisearch index %s: %w", uid, err)
+	}
+	return nil
+}
+
+// DeleteAll remove todos os índices criados pelos helpers
+func (s *SharedMeilisearch) DeleteAll(ctx context.Context) error {
+	s.mu.RLock()
+	uids := make([]string, 0, len(s.indexes))
+	for uid := range s.indexes {
+		uids = append(uids, uid)
+	}
+	s.mu.RUnlock()
+
+	for _, uid := range uids {
+		if err := s.DeleteIndex(ctx, uid); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runTask executa uma operação assíncrona e aguarda a task terminar
+// Falhas da task (ex.: documento sem chave primária) são retornadas como erro
+func (s *SharedMeilisearch) runTask(ctx context.Context, method, path string, body interface{}) error {
+	raw, err := s.request(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+
+	var task struct {
+		TaskUID int64 `json:"taskUid"`
+	}
+	if err := json.Unmarshal(raw, &task); err != nil {
+		return fmt.Errorf("failed to decode meilisearch task: %w", err)
+	}
+
+	return s.waitTask(ctx, task.TaskUID)
+}
+
+// waitTask faz polling da task até succeeded/failed
+func (s *SharedMeilisearch) waitTask(ctx context.Context, taskUID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	for {
+		raw, err := s.request(ctx, http.MethodGet, fmt.Sprintf("/tasks/%d", taskUID), nil)
+		if err != nil {
+			return err
+		}
+
+		var task struct {
+			Status string `json:"status"`
+			Error  *struct {
+				Message string `json:"message"`
+				Code    string `json:"code"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(raw, &task); err != nil {
+			return fmt.Errorf("failed to decode meilisearch task: %w", err)
+		}
+
+		switch task.Status {
+		case "succeeded":
+			return nil
+		case "failed", "canceled":
+			if task.Error != nil {
+				return fmt.Errorf("meilisearch task %d %s: %s (%s)", taskUID, task.Status, task.Error.Message, task.Error.Code)
+			}
+			return fmt.Errorf("meilisearch task %d %s", taskUID, task.Status)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("meilisearch task %d not finished: %w", taskUID, ctx.Err())
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+// request executa a chamada HTTP autenticada com a master key
+func (s *SharedMeilisearch) request(ctx context.Context, method, path string, body interface{}) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal meilisearch request: %w", err)
+		}
+		reader = bytes.NewReader(raw)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.url+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build meilisearch request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if s.masterKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.masterKey)
+	}
+
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read meilisearch response: %w", err)
+	}
+
+	if res.StatusCode >= 300 {
+		return nil, fmt.Errorf("meilisearch error: %s %s", res.Status, strings.TrimSpace(string(raw)))
+	}
+	return raw, nil
+}
+
+// testConnection verifica se o Meilisearch responde e se a master key é aceita
+func (s *SharedMeilisearch) testConnection(ctx context.Context) error {
+	ctxPing, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if s.url == "" {
+		return fmt.Errorf("meilisearch url not available")
+	}
+
+	_, err := s.request(ctxPing, http.MethodGet, "/indexes?limit=1", nil)
+	return err
+}
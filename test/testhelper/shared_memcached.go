@@ -0,0 +1,259 @@
+package testhelper
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+var (
+	sharedMemcached *SharedMemcached
+	memcachedOnce   sync.Once
+)
+
+// Opcodes do protocolo binário do memcached usados pelo helper
+const (
+	memcachedOpFlush byte = 0x08
+	memcachedOpNoop  byte = 0x0a
+)
+
+// SharedMemcached gerencia um container memcached compartilhado entre testes
+type SharedMemcached struct {
+	mu        sync.RWMutex
+	container testcontainers.Container
+	addr      string
+	refCount  int32
+	startOnce sync.Once
+	startErr  error
+	started   bool
+}
+
+// GetSharedMemcached retorna a instância singleton do memcached compartilhado
+func GetSharedMemcached() *SharedMemcached {
+	memcachedOnce.Do(func() {
+		sharedMemcached = &SharedMemcached{}
+	})
+	return sharedMemcached
+}
+
+// Start inicializa o container memcached compartilhado
+func (s *SharedMemcached) Start(ctx context.Context) error {
+	// Primeiro, tenta reutilizar container existente (sem lock global)
+	s.mu.RLock()
+	if s.started && s.addr != "" {
+		s.mu.RUnlock()
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+	} else {
+		s.mu.RUnlock()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Double-check: outro goroutine pode ter criado enquanto aguardava lock
+	if s.started && s.addr != "" {
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+		// Conexão perdida, reset para tentar novamente
+		s.started = false
+		s.startOnce = sync.Once{}
+	}
+
+	s.startOnce.Do(func() {
+		s.startErr = s.startContainer(ctx)
+		if s.startErr == nil {
+			s.started = true
+		}
+	})
+
+	if !s.started {
+		return fmt.Errorf("shared memcached not started: %w", s.startErr)
+	}
+
+	atomic.AddInt32(&s.refCount, 1)
+	return nil
+}
+
+// Stop decrementa o contador de referências e para o container se necessário
+func (s *SharedMemcached) Stop(ctx context.Context) error {
+	if atomic.AddInt32(&s.refCount, -1) <= 0 {
+		return s.stopContainer(ctx)
+	}
+	return nil
+}
+
+// GetAddress retorna o endereço host:porta do memcached
+func (s *SharedMemcached) GetAddress() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.addr
+}
+
+// startContainer inicia o container memcached ou usa um externo
+func (s *SharedMemcached) startContainer(ctx context.Context) error {
+	// Verifica se deve usar memcached externo
+	if useExternal, _ := strconv.ParseBool(os.Getenv("USE_EXTERNAL_MEMCACHED")); useExternal {
+		addr := os.Getenv("MEMCACHED_ADDR")
+		if addr == "" {
+			addr = "localhost:11211"
+		}
+		s.addr = addr
+
+		if err := s.testConnection(ctx); err != nil {
+			return externalFallback("memcached", "USE_EXTERNAL_MEMCACHED", addr, err, func() error {
+				return s.setupTestcontainer(ctx)
+			})
+		}
+
+		if isDebugEnabled() {
+			fmt.Printf("✅ Using external memcached at %s\n", addr)
+		}
+		return nil
+	}
+
+	return s.setupTestcontainer(ctx)
+}
+
+// setupTestcontainer cria e inicia um container memcached
+func (s *SharedMemcached) setupTestcontainer(ctx context.Context) error {
+	if isDebugEnabled() {
+		fmt.Println("🚀 Starting shared memcached container...")
+	}
+
+	req := testcontainers.ContainerRequest{
+		Image:        "memcached:1.6-alpine",
+		ExposedPorts: []string{"11211/tcp"},
+		Name:         "shared-memcached-test",
+		Cmd:          []string{"memcached", "-m", "64"},
+		WaitingFor:   wait.ForListeningPort("11211/tcp").WithStartupTimeout(30 * time.Second),
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+		Reuse:            shouldReuseContainer(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start memcached container: %w", err)
+	}
+
+	host, err := container.Host(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get container host: %w", err)
+	}
+
+	port, err := container.MappedPort(ctx, "11211/tcp")
+	if err != nil {
+		return fmt.Errorf("failed to get mapped port: %w", err)
+	}
+
+	s.container = container
+	s.addr = net.JoinHostPort(host, port.Port())
+
+	if err := s.testConnection(ctx); err != nil {
+		return fmt.Errorf("failed to connect to memcached: %w", err)
+	}
+
+	if isDebugEnabled() {
+		fmt.Printf("✅ Shared memcached container started at %s\n", s.addr)
+	}
+
+	log.Printf("✅ Shared memcached container started at %s", s.addr)
+
+	return nil
+}
+
+// stopContainer para o container se não estiver sendo reutilizado
+func (s *SharedMemcached) stopContainer(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.container != nil && !shouldReuseContainer() {
+		if isDebugEnabled() {
+			fmt.Println("🛑 Stopping shared memcached container...")
+		}
+		return s.container.Terminate(ctx)
+	}
+
+	return nil
+}
+
+// FlushAll remove todos os itens do memcached usando o comando flush do protocolo binário
+func (s *SharedMemcached) FlushAll(ctx context.Context) error {
+	if err := memcachedBinaryCommand(ctx, s.GetAddress(), memcachedOpFlush); err != nil {
+		return fmt.Errorf("failed to flush memcached: %w", err)
+	}
+	return nil
+}
+
+// testConnection envia um NOOP pelo protocolo binário
+func (s *SharedMemcached) testConnection(ctx context.Context) error {
+	return memcachedBinaryCommand(ctx, s.addr, memcachedOpNoop)
+}
+
+// memcachedBinaryCommand envia um comando sem key/extras/valor e valida o status da resposta
+func memcachedBinaryCommand(ctx context.Context, addr string, opcode byte) error {
+	if addr == "" {
+		return fmt.Errorf("memcached address not available")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	// Header de requisição: magic, opcode, key length, extras length, data type, vbucket, body length, opaque, CAS
+	request := make([]byte, 24)
+	request[0] = 0x80
+	request[1] = opcode
+	if _, err := conn.Write(request); err != nil {
+		return err
+	}
+
+	response := make([]byte, 24)
+	if _, err := io.ReadFull(conn, response); err != nil {
+		return err
+	}
+
+	if response[0] != 0x81 || response[1] != opcode {
+		return fmt.Errorf("unexpected memcached response header %x", response[:2])
+	}
+
+	// Descarta o corpo da resposta (mensagem de erro, se houver)
+	bodyLen := binary.BigEndian.Uint32(response[8:12])
+	body := make([]byte, bodyLen)
+	if _, err := io.ReadFull(conn, body); err != nil {
+		return err
+	}
+
+	if status := binary.BigEndian.Uint16(response[6:8]); status != 0 {
+		return fmt.Errorf("memcached returned status 0x%04x: %s", status, body)
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package testhelper
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/wait"
+	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
@@ -19,8 +21,14 @@ import (
 var (
 	sharedMongo *SharedMongoDB
 	mongoOnce   sync.Once
+
+	sharedMongoRS *SharedMongoDB
+	mongoRSOnce   sync.Once
 )
 
+// mongoReplicaSetName é o nome do replica set de um membro iniciado por WithMongoReplicaSet
+const mongoReplicaSetName = "rs0"
+
 // SharedMongoDB gerencia um container MongoDB compartilhado entre testes
 type SharedMongoDB struct {
 	mu           sync.RWMutex
@@ -31,9 +39,11 @@ type SharedMongoDB struct {
 	url          string
 	refCount     int32
 	startOnce    sync.Once
+	startErr     error
 	started      bool
 	dbName       string
 	dbNameDW     string
+	replicaSet   bool
 }
 
 // GetSharedMongoDB retorna a instância singleton do MongoDB compartilhado
@@ -44,6 +54,35 @@ func GetSharedMongoDB() *SharedMongoDB {
 	return sharedMongo
 }
 
+// GetSharedMongoDBReplicaSet retorna o MongoDB compartilhado em modo replica set (um membro), necessário
+// para transações multi-documento e change streams; usa um container separado do standalone
+func GetSharedMongoDBReplicaSet() *SharedMongoDB {
+	mongoRSOnce.Do(func() {
+		sharedMongoRS = &SharedMongoDB{replicaSet: true}
+	})
+	return sharedMongoRS
+}
+
+// mongoReplicaSetFromEnv indica se MONGO_REPLICA_SET=true pede o modo replica set para todas as suites
+func mongoReplicaSetFromEnv() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv("MONGO_REPLICA_SET"))
+	return enabled
+}
+
+// sharedMongoFor retorna o MongoDB compartilhado do modo pedido
+// Com MongoDB externo o modo é definido pelo servidor externo, então sempre retorna o padrão
+func sharedMongoFor(replicaSet bool) *SharedMongoDB {
+	if (replicaSet || mongoReplicaSetFromEnv()) && !externalMongo() {
+		return GetSharedMongoDBReplicaSet()
+	}
+	return GetSharedMongoDB()
+}
+
+// ReplicaSet indica se o container roda como replica set
+func (s *SharedMongoDB) ReplicaSet() bool {
+	return s.replicaSet
+}
+
 // Start inicializa o container MongoDB compartilhado
 func (s *SharedMongoDB) Start(ctx context.Context) error {
 	// Primeiro, tenta reutilizar container existente (sem lock global)
@@ -76,16 +115,15 @@ func (s *SharedMongoDB) Start(ctx context.Context) error {
 		s.startOnce = sync.Once{}
 	}
 	
-	var err error
 	s.startOnce.Do(func() {
-		err = s.startContainer(ctx)
-		if err == nil {
+		s.startErr = s.startContainer(ctx)
+		if s.startErr == nil {
 			s.started = true
 		}
 	})
 	
 	if !s.started {
-		return fmt.Errorf("shared mongodb not started: %w", err)
+		return fmt.Errorf("shared mongodb not started: %w", s.startErr)
 	}
 	
 	atomic.AddInt32(&s.refCount, 1)
@@ -132,20 +170,20 @@ func (s *SharedMongoDB) GetURL() string {
 func (s *SharedMongoDB) startContainer(ctx context.Context) error {
 	// Verifica se deve usar MongoDB externo
 	if useExternal, _ := strconv.ParseBool(os.Getenv("USE_EXTERNAL_MONGO")); useExternal {
-		return s.setupExternalMongoDB()
+		return s.setupExternalMongoDB(ctx)
 	}
 	
 	return s.setupTestcontainer(ctx)
 }
 
 // setupExternalMongoDB configura cliente para MongoDB externo
-func (s *SharedMongoDB) setupExternalMongoDB() error {
+func (s *SharedMongoDB) setupExternalMongoDB(ctx context.Context) error {
 	mongoURL := os.Getenv("MONGO_URL")
 	if mongoURL == "" {
 		mongoURL = "mongodb://localhost:27017"
 	}
 	
-	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	pingCtx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
 	defer cancel()
 	
 	clientOpts := options.Client().
@@ -158,14 +196,17 @@ func (s *SharedMongoDB) setupExternalMongoDB() error {
 	}
 	
 	// Testa conectividade
-	err = client.Ping(ctx, nil)
+	err = client.Ping(pingCtx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to connect to external mongodb: %w", err)
+		client.Disconnect(context.Background())
+		return externalFallback("mongodb", "USE_EXTERNAL_MONGO", mongoURL, err, func() error {
+			return s.setupTestcontainer(ctx)
+		})
 	}
 	
-	// Gera nomes únicos para databases
-	s.dbName = fmt.Sprintf("testdb_%d_%d", os.Getpid(), time.Now().UnixNano())
-	s.dbNameDW = fmt.Sprintf("testdb_%d_%d_dw", os.Getpid(), time.Now().UnixNano())
+	// Gera nomes únicos por execução para databases (removidos pelo SweepRunResources)
+	s.dbName = RunScopedName("testdb")
+	s.dbNameDW = RunScopedName("testdb_dw")
 	
 	s.client = client
 	s.database = client.Database(s.dbName)
@@ -203,6 +244,13 @@ func (s *SharedMongoDB) setupTestcontainer(ctx context.Context) error {
 		).WithStartupTimeout(60 * time.Second),
 	}
 	
+	// Replica set com autenticação exige keyFile; o membro único roda sem autenticação
+	if s.replicaSet {
+		req.Name = "shared-mongodb-rs-test"
+		req.Env = nil
+		req.Cmd = []string{"--replSet", mongoReplicaSetName, "--bind_ip_all"}
+	}
+	
 	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
 		ContainerRequest: req,
 		Started:          true,
@@ -234,6 +282,14 @@ func (s *SharedMongoDB) setupTestcontainer(ctx context.Context) error {
 	// Monte a URI com authSource=admin
 	uri := fmt.Sprintf("mongodb://%s:%s@%s:%s/%s?authSource=admin",
 		user, pass, host, mappedPort.Port(), s.dbName)
+	url := fmt.Sprintf("mongodb://%s:%s@%s:%s", user, pass, host, mappedPort.Port())
+	
+	// O membro do replica set se anuncia como localhost:27017, inacessível do host; directConnection
+	// evita a descoberta de topologia e mantém o client na porta mapeada
+	if s.replicaSet {
+		uri = fmt.Sprintf("mongodb://%s:%s/%s?directConnection=true", host, mappedPort.Port(), s.dbName)
+		url = fmt.Sprintf("mongodb://%s:%s/?directConnection=true", host, mappedPort.Port())
+	}
 	
 	// Opções do client com timeout de seleção de servidor
 	clientOpts := options.Client().
@@ -254,11 +310,18 @@ func (s *SharedMongoDB) setupTestcontainer(ctx context.Context) error {
 		return fmt.Errorf("failed to ping mongodb: %w", err)
 	}
 	
+	if s.replicaSet {
+		if err := initiateMongoReplicaSet(ctx, client); err != nil {
+			client.Disconnect(context.Background())
+			return err
+		}
+	}
+	
 	s.container = container
 	s.client = client
 	s.database = client.Database(s.dbName)
 	s.databaseDW = client.Database(s.dbNameDW)
-	s.url = fmt.Sprintf("mongodb://%s:%s@%s:%s", user, pass, host, mappedPort.Port())
+	s.url = url
 	
 	if isDebugEnabled() {
 		fmt.Printf("✅ Shared MongoDB container started at %s:%s\n", host, mappedPort.Port())
@@ -269,6 +332,42 @@ func (s *SharedMongoDB) setupTestcontainer(ctx context.Context) error {
 	return nil
 }
 
+// initiateMongoReplicaSet executa replSetInitiate (se ainda não iniciado, ex.: container reutilizado) e
+// aguarda o membro se tornar primário
+func initiateMongoReplicaSet(ctx context.Context, client *mongo.Client) error {
+	admin := client.Database("admin")
+	
+	config := bson.D{
+		{Key: "_id", Value: mongoReplicaSetName},
+		{Key: "members", Value: bson.A{bson.D{{Key: "_id", Value: 0}, {Key: "host", Value: "localhost:27017"}}}},
+	}
+	err := admin.RunCommand(ctx, bson.D{{Key: "replSetInitiate", Value: config}}).Err()
+	var cmdErr mongo.CommandError
+	if err != nil && !(errors.As(err, &cmdErr) && cmdErr.Name == "AlreadyInitialized") {
+		return fmt.Errorf("failed to initiate mongodb replica set: %w", err)
+	}
+	
+	deadline := time.Now().Add(30 * time.Second)
+	for {
+		var hello struct {
+			IsWritablePrimary bool `bson:"isWritablePrimary"`
+		}
+		err := admin.RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello)
+		if err == nil && hello.IsWritablePrimary {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("mongodb replica set member did not become primary: %v", err)
+		}
+		
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+}
+
 // stopContainer para o container se não estiver sendo reutilizado
 func (s *SharedMongoDB) stopContainer(ctx context.Context) error {
 	s.mu.Lock()
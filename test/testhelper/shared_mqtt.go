@@ -0,0 +1,218 @@
+package testhelper
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// mosquittoConfig habilita o listener 1883 em todas as interfaces com acesso anônimo
+// (o Mosquitto 2.x só escuta em localhost sem configuração)
+const mosquittoConfig = "listener 1883 0.0.0.0\nallow_anonymous true\npersistence false\n"
+
+var (
+	sharedMQTT *SharedMQTT
+	mqttOnce   sync.Once
+)
+
+// SharedMQTT gerencia um broker MQTT (eclipse-mosquitto) compartilhado entre testes
+type SharedMQTT struct {
+	mu        sync.RWMutex
+	container testcontainers.Container
+	addr      string
+	refCount  int32
+	startOnce sync.Once
+	startErr  error
+	started   bool
+}
+
+// GetSharedMQTT retorna a instância singleton do broker MQTT compartilhado
+func GetSharedMQTT() *SharedMQTT {
+	mqttOnce.Do(func() {
+		sharedMQTT = &SharedMQTT{}
+	})
+	return sharedMQTT
+}
+
+// Start inicializa o broker MQTT se ainda não estiver rodando
+func (s *SharedMQTT) Start(ctx context.Context) error {
+	// Primeiro, tenta reutilizar container existente (sem lock global)
+	s.mu.RLock()
+	if s.started && s.addr != "" {
+		s.mu.RUnlock()
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+	} else {
+		s.mu.RUnlock()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Double-check: outro goroutine pode ter criado enquanto aguardava lock
+	if s.started && s.addr != "" {
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+		// Conexão perdida, reset para tentar novamente
+		s.started = false
+		s.startOnce = sync.Once{}
+	}
+
+	s.startOnce.Do(func() {
+		s.startErr = s.startContainer(ctx)
+		if s.startErr == nil {
+			s.started = true
+		}
+	})
+
+	if !s.started {
+		return fmt.Errorf("shared mqtt not started: %w", s.startErr)
+	}
+
+	atomic.AddInt32(&s.refCount, 1)
+	return nil
+}
+
+// Stop decrementa o contador de referências e para o container se necessário
+func (s *SharedMQTT) Stop(ctx context.Context) error {
+	if atomic.AddInt32(&s.refCount, -1) <= 0 {
+		return s.stopContainer(ctx)
+	}
+	return nil
+}
+
+// GetAddr retorna o endereço host:porta do broker
+func (s *SharedMQTT) GetAddr() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.addr
+}
+
+// GetBrokerURL retorna a URL do broker no formato dos clientes MQTT (tcp://host:porta)
+func (s *SharedMQTT) GetBrokerURL() string {
+	return "tcp://" + s.GetAddr()
+}
+
+// Connect abre uma conexão MQTT com o broker
+func (s *SharedMQTT) Connect(ctx context.Context, clientID string) (*MQTTClient, error) {
+	return DialMQTT(ctx, s.GetAddr(), clientID)
+}
+
+// startContainer inicia o container Mosquitto ou usa um broker externo
+func (s *SharedMQTT) startContainer(ctx context.Context) error {
+	// Verifica se deve usar broker MQTT externo
+	if useExternal, _ := strconv.ParseBool(os.Getenv("USE_EXTERNAL_MQTT")); useExternal {
+		s.addr = strings.TrimPrefix(envOrDefault("MQTT_BROKER_ADDR", "localhost:1883"), "tcp://")
+
+		if err := s.testConnection(ctx); err != nil {
+			return externalFallback("mqtt", "USE_EXTERNAL_MQTT", s.addr, err, func() error {
+				return s.setupTestcontainer(ctx)
+			})
+		}
+
+		if isDebugEnabled() {
+			fmt.Printf("✅ Using external MQTT broker at %s\n", s.addr)
+		}
+		return nil
+	}
+
+	return s.setupTestcontainer(ctx)
+}
+
+// setupTestcontainer cria e inicia um container eclipse-mosquitto com acesso anônimo
+func (s *SharedMQTT) setupTestcontainer(ctx context.Context) error {
+	if isDebugEnabled() {
+		fmt.Println("🚀 Starting shared Mosquitto container...")
+	}
+
+	req := testcontainers.ContainerRequest{
+		Image:        "eclipse-mosquitto:2.0",
+		ExposedPorts: []string{"1883/tcp"},
+		Name:         "shared-mqtt-test",
+		Files: []testcontainers.ContainerFile{{
+			Reader:            strings.NewReader(mosquittoConfig),
+			ContainerFilePath: "/mosquitto/config/mosquitto.conf",
+			FileMode:          0o644,
+		}},
+		WaitingFor: wait.ForListeningPort("1883/tcp").WithStartupTimeout(30 * time.Second),
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+		Reuse:            shouldReuseContainer(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start mqtt container: %w", err)
+	}
+
+	host, err := container.Host(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get mqtt host: %w", err)
+	}
+
+	port, err := container.MappedPort(ctx, "1883/tcp")
+	if err != nil {
+		return fmt.Errorf("failed to get mqtt port: %w", err)
+	}
+
+	s.container = container
+	s.addr = net.JoinHostPort(host, port.Port())
+
+	if err := s.testConnection(ctx); err != nil {
+		return fmt.Errorf("failed to connect to mqtt: %w", err)
+	}
+
+	if isDebugEnabled() {
+		fmt.Printf("✅ Shared Mosquitto container started (%s)\n", s.addr)
+	}
+
+	log.Printf("✅ Shared MQTT container started at %s", s.addr)
+
+	return nil
+}
+
+// stopContainer para o container se não estiver sendo reutilizado
+func (s *SharedMQTT) stopContainer(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.container != nil && !shouldReuseContainer() {
+		if isDebugEnabled() {
+			fmt.Println("🛑 Stopping shared Mosquitto container...")
+		}
+		return s.container.Terminate(ctx)
+	}
+
+	return nil
+}
+
+// testConnection abre e fecha uma sessão MQTT com o broker
+func (s *SharedMQTT) testConnection(ctx context.Context) error {
+	if s.addr == "" {
+		return fmt.Errorf("mqtt address not available")
+	}
+
+	ctxPing, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	client, err := DialMQTT(ctxPing, s.addr, fmt.Sprintf("testhelper-ping-%d", time.Now().UnixNano()))
+	if err != nil {
+		return err
+	}
+	return client.Close()
+}
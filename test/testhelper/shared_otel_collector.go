@@ -0,0 +1,551 @@
+package testhelper
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// Arquivos gravados pelo file exporter dentro do container; configurações próprias (WithOTelCollector)
+// devem exportar para estes caminhos para que os helpers de leitura funcionem
+const (
+	OTelCollectorTracesFile  = "/tmp/otel-traces.json"
+	OTelCollectorMetricsFile = "/tmp/otel-metrics.json"
+)
+
+// defaultOTelCollectorConfig recebe OTLP gRPC/HTTP e exporta traces e métricas para os arquivos
+const defaultOTelCollectorConfig = `receivers:
+  otlp:
+    protocols:
+      grpc:
+        endpoint: 0.0.0.0:4317
+      http:
+        endpoint: 0.0.0.0:4318
+
+exporters:
+  file/traces:
+    path: ` + OTelCollectorTracesFile + `
+    flush_interval: 100ms
+  file/metrics:
+    path: ` + OTelCollectorMetricsFile + `
+    flush_interval: 100ms
+
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      exporters: [file/traces]
+    metrics:
+      receivers: [otlp]
+      exporters: [file/metrics]
+`
+
+var (
+	sharedOTelByConfig   = make(map[string]*SharedOTelCollector)
+	sharedOTelByConfigMu sync.Mutex
+)
+
+// OTelSpan é um span exportado pelo collector
+type OTelSpan struct {
+	TraceID      string
+	SpanID       string
+	ParentSpanID string
+	Name         string
+	ServiceName  string
+	Kind         int
+	StartTime    time.Time
+	EndTime      time.Time
+	Attributes   map[string]interface{}
+	StatusCode   int
+}
+
+// OTelMetricPoint é um ponto de métrica exportado pelo collector (sum, gauge ou histogram)
+type OTelMetricPoint struct {
+	Name        string
+	ServiceName string
+	Type        string
+	Unit        string
+	Attributes  map[string]interface{}
+	// Value é o valor do ponto (sum/gauge) ou a soma do histograma
+	Value float64
+	// Count é a quantidade de observações (histogram)
+	Count uint64
+	Time  time.Time
+}
+
+// SharedOTelCollector gerencia um container OpenTelemetry Collector por arquivo de configuração
+type SharedOTelCollector struct {
+	mu          sync.RWMutex
+	container   testcontainers.Container
+	configPath  string
+	grpcAddr    string
+	httpURL     string
+	externalDir string
+	refCount    int32
+	startOnce   sync.Once
+	startErr    error
+	started     bool
+}
+
+// GetSharedOTelCollector retorna o collector compartilhado da configuração (um container por arquivo)
+// Com configPath vazio, usa a configuração padrão: OTLP gRPC/HTTP exportando traces e métricas para
+// OTelCollectorTracesFile e OTelCollectorMetricsFile
+func GetSharedOTelCollector(configPath string) *SharedOTelCollector {
+	path := configPath
+	if path != "" {
+		if abs, err := filepath.Abs(configPath); err == nil {
+			path = abs
+		}
+	}
+
+	sharedOTelByConfigMu.Lock()
+	defer sharedOTelByConfigMu.Unlock()
+
+	c, ok := sharedOTelByConfig[path]
+	if !ok {
+		c = &SharedOTelCollector{configPath: path}
+		sharedOTelByConfig[path] = c
+	}
+	return c
+}
+
+// Start inicializa o container do collector com a configuração
+func (s *SharedOTelCollector) Start(ctx context.Context) error {
+	// Primeiro, tenta reutilizar container existente (sem lock global)
+	s.mu.RLock()
+	if s.started && s.grpcAddr != "" {
+		s.mu.RUnlock()
+		if err := s.testConnection(); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+	} else {
+		s.mu.RUnlock()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Double-check: outro goroutine pode ter criado enquanto aguardava lock
+	if s.started && s.grpcAddr != "" {
+		if err := s.testConnection(); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+		// Conexão perdida, reset para tentar novamente
+		s.started = false
+		s.startOnce = sync.Once{}
+	}
+
+	s.startOnce.Do(func() {
+		s.startErr = s.startContainer(ctx)
+		if s.startErr == nil {
+			s.started = true
+		}
+	})
+
+	if !s.started {
+		return fmt.Errorf("shared otel collector not started: %w", s.startErr)
+	}
+
+	atomic.AddInt32(&s.refCount, 1)
+	return nil
+}
+
+// Stop decrementa o contador de referências e para o container se necessário
+func (s *SharedOTelCollector) Stop(ctx context.Context) error {
+	if atomic.AddInt32(&s.refCount, -1) <= 0 {
+		return s.stopContainer(ctx)
+	}
+	return nil
+}
+
+// GRPCEndpoint retorna o endereço host:porta do receiver OTLP gRPC (4317)
+func (s *SharedOTelCollector) GRPCEndpoint() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.grpcAddr
+}
+
+// HTTPEndpoint retorna a URL do receiver OTLP HTTP (4318; os exporters acrescentam /v1/traces)
+func (s *SharedOTelCollector) HTTPEndpoint() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.httpURL
+}
+
+// startContainer inicia o container do collector ou usa um externo
+func (s *SharedOTelCollector) startContainer(ctx context.Context) error {
+	// Verifica se deve usar collector externo; os arquivos do file exporter são lidos de
+	// OTEL_COLLECTOR_OUTPUT_DIR (diretório do host montado no collector)
+	if useExternal, _ := strconv.ParseBool(os.Getenv("USE_EXTERNAL_OTEL_COLLECTOR")); useExternal {
+		s.grpcAddr = envOrDefault("OTEL_COLLECTOR_GRPC_ADDR", "localhost:4317")
+		s.httpURL = strings.TrimRight(envOrDefault("OTEL_COLLECTOR_HTTP_URL", "http://localhost:4318"), "/")
+		s.externalDir = os.Getenv("OTEL_COLLECTOR_OUTPUT_DIR")
+
+		if err := s.testConnection(); err != nil {
+			return externalFallback("otel collector", "USE_EXTERNAL_OTEL_COLLECTOR", s.grpcAddr, err, func() error {
+				return s.setupTestcontainer(ctx)
+			})
+		}
+
+		if isDebugEnabled() {
+			fmt.Printf("✅ Using external OpenTelemetry Collector at %s\n", s.grpcAddr)
+		}
+		return nil
+	}
+
+	return s.setupTestcontainer(ctx)
+}
+
+// setupTestcontainer cria e inicia um container otel-collector-contrib (que inclui o file exporter)
+func (s *SharedOTelCollector) setupTestcontainer(ctx context.Context) error {
+	if isDebugEnabled() {
+		fmt.Println("🚀 Starting shared OpenTelemetry Collector container...")
+	}
+
+	conf := []byte(defaultOTelCollectorConfig)
+	if s.configPath != "" {
+		var err error
+		if conf, err = os.ReadFile(s.configPath); err != nil {
+			return fmt.Errorf("failed to read otel collector config %s: %w", s.configPath, err)
+		}
+	}
+
+	sum := sha1.Sum(conf)
+	req := testcontainers.ContainerRequest{
+		Image:        "otel/opentelemetry-collector-contrib:0.100.0",
+		ExposedPorts: []string{"4317/tcp", "4318/tcp"},
+		// O nome deriva da configuração para que o reuse não devolva um collector com outra configuração
+		Name: "shared-otel-collector-test-" + hex.EncodeToString(sum[:4]),
+		Files: []testcontainers.ContainerFile{{
+			Reader:            bytes.NewReader(conf),
+			ContainerFilePath: "/etc/otelcol-contrib/config.yaml",
+			FileMode:          0o644,
+		}},
+		WaitingFor: wait.ForLog("Everything is ready").WithStartupTimeout(time.Minute),
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+		Reuse:            shouldReuseContainer(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start otel collector container: %w", err)
+	}
+
+	grpcAddr, err := container.PortEndpoint(ctx, "4317/tcp", "")
+	if err != nil {
+		return fmt.Errorf("failed to get otel collector grpc endpoint: %w", err)
+	}
+	httpURL, err := container.PortEndpoint(ctx, "4318/tcp", "http")
+	if err != nil {
+		return fmt.Errorf("failed to get otel collector http endpoint: %w", err)
+	}
+
+	s.container = container
+	s.grpcAddr = grpcAddr
+	s.httpURL = httpURL
+
+	if isDebugEnabled() {
+		fmt.Printf("✅ Shared OpenTelemetry Collector container started (grpc %s, http %s)\n", grpcAddr, httpURL)
+	}
+
+	log.Printf("✅ Shared OpenTelemetry Collector container started at %s", grpcAddr)
+
+	return nil
+}
+
+// stopContainer para o container se não estiver sendo reutilizado
+func (s *SharedOTelCollector) stopContainer(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.container != nil && !shouldReuseContainer() {
+		if isDebugEnabled() {
+			fmt.Println("🛑 Stopping shared OpenTelemetry Collector container...")
+		}
+		return s.container.Terminate(ctx)
+	}
+
+	return nil
+}
+
+// Spans lê os spans exportados para OTelCollectorTracesFile
+func (s *SharedOTelCollector) Spans(ctx context.Context) ([]OTelSpan, error) {
+	var spans []OTelSpan
+	err := s.readExport(ctx, OTelCollectorTracesFile, func(line []byte) error {
+		var export struct {
+			ResourceSpans []struct {
+				Resource   otelResource `json:"resource"`
+				ScopeSpans []struct {
+					Spans []struct {
+						TraceID           string          `json:"traceId"`
+						SpanID            string          `json:"spanId"`
+						ParentSpanID      string          `json:"parentSpanId"`
+						Name              string          `json:"name"`
+						Kind              int             `json:"kind"`
+						StartTimeUnixNano string          `json:"startTimeUnixNano"`
+						EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+						Attributes        []otelAttribute `json:"attributes"`
+						Status            struct {
+							Code int `json:"code"`
+						} `json:"status"`
+					} `json:"spans"`
+				} `json:"scopeSpans"`
+			} `json:"resourceSpans"`
+		}
+		if err := json.Unmarshal(line, &export); err != nil {
+			return fmt.Errorf("failed to decode exported spans: %w", err)
+		}
+
+		for _, rs := range export.ResourceSpans {
+			service := rs.Resource.serviceName()
+			for _, ss := range rs.ScopeSpans {
+				for _, raw := range ss.Spans {
+					spans = append(spans, OTelSpan{
+						TraceID:      raw.TraceID,
+						SpanID:       raw.SpanID,
+						ParentSpanID: raw.ParentSpanID,
+						Name:         raw.Name,
+						ServiceName:  service,
+						Kind:         raw.Kind,
+						StartTime:    otelTime(raw.StartTimeUnixNano),
+						EndTime:      otelTime(raw.EndTimeUnixNano),
+						Attributes:   otelAttributes(raw.Attributes),
+						StatusCode:   raw.Status.Code,
+					})
+				}
+			}
+		}
+		return nil
+	})
+	return spans, err
+}
+
+// Metrics lê os pontos de métricas exportados para OTelCollectorMetricsFile
+func (s *SharedOTelCollector) Metrics(ctx context.Context) ([]OTelMetricPoint, error) {
+	type dataPoint struct {
+		Attributes   []otelAttribute `json:"attributes"`
+		TimeUnixNano string          `json:"timeUnixNano"`
+		AsInt        *json.Number    `json:"asInt"`
+		AsDouble     *float64        `json:"asDouble"`
+		Count        json.Number     `json:"count"`
+		Sum          *float64        `json:"sum"`
+	}
+	type dataPoints struct {
+		DataPoints []dataPoint `json:"dataPoints"`
+	}
+
+	var points []OTelMetricPoint
+	err := s.readExport(ctx, OTelCollectorMetricsFile, func(line []byte) error {
+		var export struct {
+			ResourceMetrics []struct {
+				Resource     otelResource `json:"resource"`
+				ScopeMetrics []struct {
+					Metrics []struct {
+						Name      string      `json:"name"`
+						Unit      string      `json:"unit"`
+						Sum       *dataPoints `json:"sum"`
+						Gauge     *dataPoints `json:"gauge"`
+						Histogram *dataPoints `json:"histogram"`
+					} `json:"metrics"`
+				} `json:"scopeMetrics"`
+			} `json:"resourceMetrics"`
+		}
+		if err := json.Unmarshal(line, &export); err != nil {
+			return fmt.Errorf("failed to decode exported metrics: %w", err)
+		}
+
+		for _, rm := range export.ResourceMetrics {
+			service := rm.Resource.serviceName()
+			for _, sm := range rm.ScopeMetrics {
+				for _, metric := range sm.Metrics {
+					kind, data := "sum", metric.Sum
+					if metric.Gauge != nil {
+						kind, data = "gauge", metric.Gauge
+					} else if metric.Histogram != nil {
+						kind, data = "histogram", metric.Histogram
+					}
+					if data == nil {
+						continue
+					}
+
+					for _, dp := range data.DataPoints {
+						point := OTelMetricPoint{
+							Name:        metric.Name,
+							ServiceName: service,
+							Type:        kind,
+							Unit:        metric.Unit,
+							Attributes:  otelAttributes(dp.Attributes),
+							Time:        otelTime(dp.TimeUnixNano),
+						}
+						switch {
+						case dp.AsInt != nil:
+							point.Value, _ = dp.AsInt.Float64()
+						case dp.AsDouble != nil:
+							point.Value = *dp.AsDouble
+						case dp.Sum != nil:
+							point.Value = *dp.Sum
+						}
+						if count, err := strconv.ParseUint(dp.Count.String(), 10, 64); err == nil {
+							point.Count = count
+						}
+						points = append(points, point)
+					}
+				}
+			}
+		}
+		return nil
+	})
+	return points, err
+}
+
+// readExport lê o arquivo do file exporter (uma requisição OTLP JSON por linha) e chama fn por linha
+// Arquivo ainda inexistente (nada exportado) não é erro
+func (s *SharedOTelCollector) readExport(ctx context.Context, path string, fn func(line []byte) error) error {
+	var rc io.ReadCloser
+	var err error
+
+	s.mu.RLock()
+	container, externalDir := s.container, s.externalDir
+	s.mu.RUnlock()
+
+	switch {
+	case container != nil:
+		rc, err = container.CopyFileFromContainer(ctx, path)
+		if err != nil && (strings.Contains(err.Error(), "No such") || strings.Contains(err.Error(), "Could not find")) {
+			return nil
+		}
+	case externalDir != "":
+		rc, err = os.Open(filepath.Join(externalDir, filepath.Base(path)))
+		if os.IsNotExist(err) {
+			return nil
+		}
+	default:
+		return fmt.Errorf("otel collector exports not available (set OTEL_COLLECTOR_OUTPUT_DIR for an external collector)")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read otel collector export %s: %w", path, err)
+	}
+	defer rc.Close()
+
+	scanner := bufio.NewScanner(rc)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if err := fn(line); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read otel collector export %s: %w", path, err)
+	}
+	return nil
+}
+
+// testConnection verifica se o receiver OTLP gRPC aceita conexões
+func (s *SharedOTelCollector) testConnection() error {
+	if s.grpcAddr == "" {
+		return fmt.Errorf("otel collector address not available")
+	}
+
+	conn, err := net.DialTimeout("tcp", s.grpcAddr, 5*time.Second)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// otelResource é o resource de um lote OTLP JSON
+type otelResource struct {
+	Attributes []otelAttribute `json:"attributes"`
+}
+
+// serviceName retorna o atributo service.name do resource
+func (r otelResource) serviceName() string {
+	name, _ := otelAttributes(r.Attributes)["service.name"].(string)
+	return name
+}
+
+// otelAttribute é um atributo OTLP JSON (chave e AnyValue)
+type otelAttribute struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+// otelAttributes converte os atributos OTLP para valores Go (string, int64, float64, bool ou slices)
+func otelAttributes(attrs []otelAttribute) map[string]interface{} {
+	out := make(map[string]interface{}, len(attrs))
+	for _, attr := range attrs {
+		out[attr.Key] = otelAnyValue(attr.Value)
+	}
+	return out
+}
+
+// otelAnyValue converte um AnyValue OTLP JSON; intValue vem como string no JSON
+func otelAnyValue(raw json.RawMessage) interface{} {
+	var value struct {
+		StringValue *string  `json:"stringValue"`
+		IntValue    *string  `json:"intValue"`
+		DoubleValue *float64 `json:"doubleValue"`
+		BoolValue   *bool    `json:"boolValue"`
+		ArrayValue  *struct {
+			Values []json.RawMessage `json:"values"`
+		} `json:"arrayValue"`
+	}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return string(raw)
+	}
+
+	switch {
+	case value.StringValue != nil:
+		return *value.StringValue
+	case value.IntValue != nil:
+		if n, err := strconv.ParseInt(*value.IntValue, 10, 64); err == nil {
+			return n
+		}
+		return *value.IntValue
+	case value.DoubleValue != nil:
+		return *value.DoubleValue
+	case value.BoolValue != nil:
+		return *value.BoolValue
+	case value.ArrayValue != nil:
+		values := make([]interface{}, 0, len(value.ArrayValue.Values))
+		for _, v := range value.ArrayValue.Values {
+			values = append(values, otelAnyValue(v))
+		}
+		return values
+	}
+	return nil
+}
+
+// otelTime converte o timestamp em nanossegundos (string no OTLP JSON)
+func otelTime(nanos string) time.Time {
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(0, n)
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"io/fs"
 	"log"
 	"os"
+	"regexp"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -16,9 +19,19 @@ import (
 	"github.com/testcontainers/testcontainers-go/wait"
 )
 
+// DefaultPostgresImage é a imagem usada pelo WithPostgres quando nenhuma outra é configurada
+const DefaultPostgresImage = "postgres:15"
+
+// pgImageInvalid casa caracteres da imagem que não podem ser usados no nome do container
+var pgImageInvalid = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
 var (
 	sharedPG *SharedPostgreSQL
 	pgOnce   sync.Once
+	
+	// Containers compartilhados de imagens alternativas (ex.: TimescaleDB), um por imagem
+	sharedPGByImage   = make(map[string]*SharedPostgreSQL)
+	sharedPGByImageMu sync.Mutex
 )
 
 // SharedPostgreSQL gerencia um container PostgreSQL compartilhado entre testes
@@ -29,9 +42,12 @@ type SharedPostgreSQL struct {
 	url          string
 	refCount     int32
 	startOnce    sync.Once
+	startErr     error
 	started      bool
 	dbName       string
 	sqlFilePaths []string
+	sqlFS        fs.FS
+	image        string
 }
 
 // GetSharedPostgreSQL retorna a instância singleton do PostgreSQL compartilhado
@@ -42,8 +58,61 @@ func GetSharedPostgreSQL() *SharedPostgreSQL {
 	return sharedPG
 }
 
+// GetSharedPostgreSQLImage retorna o PostgreSQL compartilhado da imagem informada
+// Cada imagem alternativa tem o próprio container; vazio ou DefaultPostgresImage retorna o padrão
+func GetSharedPostgreSQLImage(image string) *SharedPostgreSQL {
+	if image == "" || image == DefaultPostgresImage {
+		return GetSharedPostgreSQL()
+	}
+	
+	sharedPGByImageMu.Lock()
+	defer sharedPGByImageMu.Unlock()
+	
+	pg, ok := sharedPGByImage[image]
+	if !ok {
+		pg = &SharedPostgreSQL{image: image}
+		sharedPGByImage[image] = pg
+	}
+	return pg
+}
+
+// isTimescaleImage indica se a imagem é do TimescaleDB
+func isTimescaleImage(image string) bool {
+	return strings.Contains(strings.ToLower(image), "timescaledb")
+}
+
+// Image retorna a imagem do container
+func (s *SharedPostgreSQL) Image() string {
+	if s.image == "" {
+		return DefaultPostgresImage
+	}
+	return s.image
+}
+
+// containerName retorna o nome do container compartilhado da imagem
+func (s *SharedPostgreSQL) containerName() string {
+	if s.Image() == DefaultPostgresImage {
+		return "shared-postgres-test"
+	}
+	return "shared-postgres-" + strings.Trim(strings.ToLower(pgImageInvalid.ReplaceAllString(s.Image(), "-")), "-") + "-test"
+}
+
+// extensions retorna as extensões criadas antes dos arquivos SQL (timescaledb nas imagens TimescaleDB)
+func (s *SharedPostgreSQL) extensions() []string {
+	if isTimescaleImage(s.Image()) {
+		return []string{"timescaledb"}
+	}
+	return nil
+}
+
 // Start inicializa o container PostgreSQL compartilhado
 func (s *SharedPostgreSQL) Start(ctx context.Context, sqlFilePaths ...string) error {
+	return s.StartFS(ctx, nil, sqlFilePaths...)
+}
+
+// StartFS inicializa o container lendo os arquivos SQL de fsys (ex.: embed.FS); com fsys nil os caminhos
+// são lidos do disco, como em Start
+func (s *SharedPostgreSQL) StartFS(ctx context.Context, fsys fs.FS, sqlFilePaths ...string) error {
 	// Primeiro, tenta reutilizar container existente (sem lock global)
 	s.mu.RLock()
 	if s.started && s.connection != nil {
@@ -76,17 +145,17 @@ func (s *SharedPostgreSQL) Start(ctx context.Context, sqlFilePaths ...string) er
 	
 	// Armazena os SQL paths para este container
 	s.sqlFilePaths = sqlFilePaths
+	s.sqlFS = fsys
 	
-	var err error
 	s.startOnce.Do(func() {
-		err = s.startContainer(ctx)
-		if err == nil {
+		s.startErr = s.startContainer(ctx)
+		if s.startErr == nil {
 			s.started = true
 		}
 	})
 	
 	if !s.started {
-		return fmt.Errorf("shared postgresql not started: %w", err)
+		return fmt.Errorf("shared postgresql not started: %w", s.startErr)
 	}
 	
 	atomic.AddInt32(&s.refCount, 1)
@@ -119,14 +188,14 @@ func (s *SharedPostgreSQL) GetURL() string {
 func (s *SharedPostgreSQL) startContainer(ctx context.Context) error {
 	// Verifica se deve usar PostgreSQL externo
 	if useExternal, _ := strconv.ParseBool(os.Getenv("USE_EXTERNAL_PG")); useExternal {
-		return s.setupExternalPostgreSQL()
+		return s.setupExternalPostgreSQL(ctx)
 	}
 	
 	return s.setupTestcontainer(ctx)
 }
 
 // setupExternalPostgreSQL configura conexão para PostgreSQL externo
-func (s *SharedPostgreSQL) setupExternalPostgreSQL() error {
+func (s *SharedPostgreSQL) setupExternalPostgreSQL(ctx context.Context) error {
 	pgURL := os.Getenv("PG_URL")
 	if pgURL == "" {
 		pgURL = "host=localhost port=5432 user=test password=test sslmode=disable"
@@ -139,12 +208,20 @@ func (s *SharedPostgreSQL) setupExternalPostgreSQL() error {
 	
 	// Testa conectividade
 	if err := conn.Ping(); err != nil {
-		return fmt.Errorf("failed to connect to external postgresql: %w", err)
+		conn.Close()
+		// PG_URL pode conter a senha, então o log usa apenas o nome da variável
+		return externalFallback("postgresql", "USE_EXTERNAL_PG", "PG_URL", err, func() error {
+			return s.setupTestcontainer(ctx)
+		})
 	}
 	
 	s.connection = conn
 	s.url = pgURL
 	
+	if err := s.createExtensions(); err != nil {
+		return err
+	}
+	
 	// Executa SQL files se fornecidos
 	if err := s.executeInitialSQL(); err != nil {
 		return fmt.Errorf("failed to execute initial SQL: %w", err)
@@ -160,23 +237,32 @@ func (s *SharedPostgreSQL) setupExternalPostgreSQL() error {
 // setupTestcontainer cria e inicia um container PostgreSQL
 func (s *SharedPostgreSQL) setupTestcontainer(ctx context.Context) error {
 	if isDebugEnabled() {
-		fmt.Println("🚀 Starting shared PostgreSQL container...")
+		fmt.Printf("🚀 Starting shared PostgreSQL container (%s)...\n", s.Image())
 	}
 	
 	// Gera nome único do database
 	s.dbName = fmt.Sprintf("testdb_%d_%d", os.Getpid(), time.Now().UnixNano())
 	
+	env := map[string]string{
+		"POSTGRES_USER":     "test",
+		"POSTGRES_PASSWORD": "test",
+		"POSTGRES_DB":       s.dbName,
+		"POSTGRES_HOST":     "localhost",
+		"POSTGRES_PORT":     "5432",
+	}
+	if isTimescaleImage(s.Image()) {
+		// Sem telemetria e sem o timescaledb-tune no init (acelera a subida)
+		env["TIMESCALEDB_TELEMETRY"] = "off"
+		env["NO_TS_TUNE"] = "true"
+	}
+	
 	req := testcontainers.ContainerRequest{
-		Image:        "postgres:15",
+		Image:        s.Image(),
 		ExposedPorts: []string{"5432/tcp"},
-		Name:         "shared-postgres-test",
-		Env: map[string]string{
-			"POSTGRES_USER":     "test",
-			"POSTGRES_PASSWORD": "test",
-			"POSTGRES_DB":       s.dbName,
-			"POSTGRES_HOST":     "localhost",
-			"POSTGRES_PORT":     "5432",
-		},
+		Name:         s.containerName(),
+		Env:          env,
+		// Replicação lógica habilitada para CDC (WithDebezium)
+		Cmd:          []string{"postgres", "-c", "wal_level=logical"},
 		WaitingFor: wait.ForLog("database system is ready to accept connections").
 			WithPollInterval(1 * time.Second).
 			WithStartupTimeout(60 * time.Second),
@@ -228,6 +314,10 @@ func (s *SharedPostgreSQL) setupTestcontainer(ctx context.Context) error {
 	s.connection = dbConn
 	s.url = dsn
 	
+	if err := s.createExtensions(); err != nil {
+		return err
+	}
+	
 	// Executa SQL files se fornecidos
 	if err := s.executeInitialSQL(); err != nil {
 		return fmt.Errorf("failed to execute initial SQL: %w", err)
@@ -242,6 +332,21 @@ func (s *SharedPostgreSQL) setupTestcontainer(ctx context.Context) error {
 	return nil
 }
 
+// createExtensions cria as extensões da imagem (ex.: timescaledb) antes dos arquivos SQL
+func (s *SharedPostgreSQL) createExtensions() error {
+	for _, extension := range s.extensions() {
+		if _, err := s.connection.Exec(fmt.Sprintf("CREATE EXTENSION IF NOT EXISTS %s CASCADE", extension)); err != nil {
+			return fmt.Errorf("failed to create extension %s: %w", extension, err)
+		}
+		
+		if isDebugEnabled() {
+			log.Printf("Extension %s created", extension)
+		}
+	}
+	
+	return nil
+}
+
 // executeInitialSQL executa os arquivos SQL iniciais
 func (s *SharedPostgreSQL) executeInitialSQL() error {
 	if len(s.sqlFilePaths) == 0 {
@@ -253,7 +358,7 @@ func (s *SharedPostgreSQL) executeInitialSQL() error {
 			log.Printf("Executing SQL file: %s", path)
 		}
 		
-		initSQL, err := os.ReadFile(path)
+		initSQL, err := readFixtureFile(s.sqlFS, path)
 		if err != nil {
 			return fmt.Errorf("failed to read SQL file %s: %w", path, err)
 		}
@@ -301,46 +406,47 @@ func (s *SharedPostgreSQL) CleanDatabase(ctx context.Context) error {
 		return fmt.Errorf("postgresql connection not available")
 	}
 	
-	// Obtém lista de todas as tabelas do usuário
-	rows, err := connection.QueryContext(ctx, `
-		SELECT tablename 
-		FROM pg_tables 
-		WHERE schemaname = 'public'
-	`)
+	// Fixa uma conexão: session_replication_role vale apenas para a sessão
+	conn, err := connection.Conn(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to get table list: %w", err)
+		return fmt.Errorf("failed to get connection: %w", err)
 	}
-	defer rows.Close()
+	defer conn.Close()
 	
-	var tables []string
-	for rows.Next() {
-		var table string
-		if err := rows.Scan(&table); err != nil {
-			continue
-		}
-		tables = append(tables, table)
+	// Tabelas do usuário, ignorando partições: o TRUNCATE do pai já limpa todas elas
+	tables, err := truncatableTables(ctx, conn)
+	if err != nil {
+		return err
 	}
 	
 	// Desabilita temporarily foreign key checks
 	if len(tables) > 0 {
-		_, err = connection.ExecContext(ctx, "SET session_replication_role = replica;")
+		_, err = conn.ExecContext(ctx, "SET session_replication_role = replica;")
 		if err != nil {
 			return fmt.Errorf("failed to disable foreign keys: %w", err)
 		}
 		
-		// Truncate todas as tabelas
-		for _, table := range tables {
-			_, err = connection.ExecContext(ctx, fmt.Sprintf("TRUNCATE TABLE \"%s\" CASCADE", table))
-			if err != nil && isDebugEnabled() {
-				fmt.Printf("⚠️  Failed to truncate table %s: %v\n", table, err)
-			}
+		// Truncate de todas as tabelas numa única instrução
+		quoted := make([]string, len(tables))
+		for i, table := range tables {
+			quoted[i] = fmt.Sprintf("\"%s\"", table)
 		}
+		_, truncateErr := conn.ExecContext(ctx, fmt.Sprintf("TRUNCATE TABLE %s CASCADE", strings.Join(quoted, ", ")))
 		
 		// Reabilita foreign key checks
-		_, err = connection.ExecContext(ctx, "SET session_replication_role = DEFAULT;")
+		_, err = conn.ExecContext(ctx, "SET session_replication_role = DEFAULT;")
 		if err != nil && isDebugEnabled() {
 			fmt.Printf("⚠️  Failed to re-enable foreign keys: %v\n", err)
 		}
+		
+		if truncateErr != nil {
+			return fmt.Errorf("failed to truncate tables: %w", truncateErr)
+		}
+	}
+	
+	// Partições default desanexadas (ex.: por manutenção do pg_partman) voltam para o pai
+	if err := reattachDefaultPartitions(ctx, conn); err != nil {
+		return err
 	}
 	
 	return nil
@@ -0,0 +1,479 @@
+package testhelper
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// PrometheusScrapeInterval é o intervalo de scrape dos alvos registrados
+const PrometheusScrapeInterval = time.Second
+
+var (
+	sharedPrometheusByTargets   = make(map[string]*SharedPrometheus)
+	sharedPrometheusByTargetsMu sync.Mutex
+)
+
+// PromSample é uma amostra do resultado de uma consulta PromQL (vetor instantâneo ou escalar)
+type PromSample struct {
+	Metric map[string]string
+	Value  float64
+}
+
+// PrometheusScrapeConfig é um job de scrape do Prometheus
+type PrometheusScrapeConfig struct {
+	JobName     string            // padrão "job-<n>"
+	Targets     []string          // "host:porta" ou URLs com o caminho das métricas
+	MetricsPath string            // padrão: caminho da URL do primeiro alvo ou /metrics
+	Interval    time.Duration     // padrão PrometheusScrapeInterval
+	Labels      map[string]string // rótulos adicionados às séries do job
+	Network     string            // rede docker dos alvos (ex.: aplicação em container); o Prometheus entra nela
+}
+
+// SharedPrometheus gerencia um container Prometheus por conjunto de jobs de scrape
+type SharedPrometheus struct {
+	mu         sync.RWMutex
+	container  testcontainers.Container
+	configs    []PrometheusScrapeConfig
+	url        string
+	httpClient *http.Client
+	refCount   int32
+	startOnce  sync.Once
+	startErr   error
+	started    bool
+}
+
+// GetSharedPrometheus retorna o Prometheus compartilhado que faz scrape dos alvos (um container por
+// conjunto de alvos). Alvos são "host:porta" ou URLs com o caminho das métricas
+// (ex.: "http://localhost:9102/custom/metrics"); o caminho padrão é /metrics
+func GetSharedPrometheus(scrapeTargets ...string) *SharedPrometheus {
+	targets := append([]string(nil), scrapeTargets...)
+	sort.Strings(targets)
+
+	configs := make([]PrometheusScrapeConfig, len(targets))
+	for i, target := range targets {
+		configs[i] = PrometheusScrapeConfig{
+			JobName: fmt.Sprintf("target-%d", i+1),
+			Targets: []string{target},
+			Labels:  map[string]string{"target": target},
+		}
+	}
+	return GetSharedPrometheusWithConfigs(configs...)
+}
+
+// GetSharedPrometheusWithConfigs retorna o Prometheus compartilhado com os jobs de scrape informados
+// (um container por conjunto de jobs)
+func GetSharedPrometheusWithConfigs(configs ...PrometheusScrapeConfig) *SharedPrometheus {
+	configs = append([]PrometheusScrapeConfig(nil), configs...)
+	for i := range configs {
+		if configs[i].JobName == "" {
+			configs[i].JobName = fmt.Sprintf("job-%d", i+1)
+		}
+	}
+
+	raw, _ := json.Marshal(configs)
+	key := string(raw)
+
+	sharedPrometheusByTargetsMu.Lock()
+	defer sharedPrometheusByTargetsMu.Unlock()
+
+	p, ok := sharedPrometheusByTargets[key]
+	if !ok {
+		p = &SharedPrometheus{
+			configs:    configs,
+			httpClient: &http.Client{Timeout: 30 * time.Second},
+		}
+		sharedPrometheusByTargets[key] = p
+	}
+	return p
+}
+
+// Start inicializa o container Prometheus com a configuração de scrape dos alvos
+func (s *SharedPrometheus) Start(ctx context.Context) error {
+	// Primeiro, tenta reutilizar container existente (sem lock global)
+	s.mu.RLock()
+	if s.started && s.url != "" {
+		s.mu.RUnlock()
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+	} else {
+		s.mu.RUnlock()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Double-check: outro goroutine pode ter criado enquanto aguardava lock
+	if s.started && s.url != "" {
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+		// Conexão perdida, reset para tentar novamente
+		s.started = false
+		s.startOnce = sync.Once{}
+	}
+
+	s.startOnce.Do(func() {
+		s.startErr = s.startContainer(ctx)
+		if s.startErr == nil {
+			s.started = true
+		}
+	})
+
+	if !s.started {
+		return fmt.Errorf("shared prometheus not started: %w", s.startErr)
+	}
+
+	atomic.AddInt32(&s.refCount, 1)
+	return nil
+}
+
+// Stop decrementa o contador de referências e para o container se necessário
+func (s *SharedPrometheus) Stop(ctx context.Context) error {
+	if atomic.AddInt32(&s.refCount, -1) <= 0 {
+		return s.stopContainer(ctx)
+	}
+	return nil
+}
+
+// GetURL retorna a URL da API do Prometheus
+func (s *SharedPrometheus) GetURL() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.url
+}
+
+// startContainer inicia o container Prometheus ou usa um externo
+func (s *SharedPrometheus) startContainer(ctx context.Context) error {
+	// Verifica se deve usar Prometheus externo (já configurado com os alvos)
+	if useExternal, _ := strconv.ParseBool(os.Getenv("USE_EXTERNAL_PROMETHEUS")); useExternal {
+		s.url = strings.TrimRight(envOrDefault("PROMETHEUS_URL", "http://localhost:9090"), "/")
+
+		if err := s.testConnection(ctx); err != nil {
+			return externalFallback("prometheus", "USE_EXTERNAL_PROMETHEUS", s.url, err, func() error {
+				return s.setupTestcontainer(ctx)
+			})
+		}
+
+		if isDebugEnabled() {
+			fmt.Printf("✅ Using external Prometheus at %s\n", s.url)
+		}
+		return nil
+	}
+
+	return s.setupTestcontainer(ctx)
+}
+
+// setupTestcontainer cria e inicia um container Prometheus com o prometheus.yml renderizado
+func (s *SharedPrometheus) setupTestcontainer(ctx context.Context) error {
+	if isDebugEnabled() {
+		fmt.Printf("🚀 Starting shared Prometheus container (%d jobs)...\n", len(s.configs))
+	}
+
+	conf, hostPorts, err := renderPrometheusConfig(s.configs)
+	if err != nil {
+		return err
+	}
+
+	var networks []string
+	for _, config := range s.configs {
+		if config.Network != "" && !slices.Contains(networks, config.Network) {
+			networks = append(networks, config.Network)
+		}
+	}
+
+	sum := sha1.Sum(append(conf, strings.Join(networks, ",")...))
+	req := testcontainers.ContainerRequest{
+		Image:        "prom/prometheus:v2.45.0",
+		ExposedPorts: []string{"9090/tcp"},
+		// O nome deriva da configuração para que o reuse não devolva um container com outros alvos
+		Name: "shared-prometheus-test-" + hex.EncodeToString(sum[:4]),
+		Cmd: []string{
+			"--config.file=/etc/prometheus/prometheus.yml",
+			"--storage.tsdb.path=/prometheus",
+			"--web.enable-admin-api",
+		},
+		Files: []testcontainers.ContainerFile{{
+			Reader:            bytes.NewReader(conf),
+			ContainerFilePath: "/etc/prometheus/prometheus.yml",
+			FileMode:          0o644,
+		}},
+		HostAccessPorts: hostPorts,
+		Networks:        networks,
+		WaitingFor:      wait.ForHTTP("/-/ready").WithPort("9090/tcp").WithStartupTimeout(time.Minute),
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+		Reuse:            shouldReuseContainer(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start prometheus container: %w", err)
+	}
+
+	endpoint, err := container.PortEndpoint(ctx, "9090/tcp", "http")
+	if err != nil {
+		return fmt.Errorf("failed to get prometheus endpoint: %w", err)
+	}
+
+	s.container = container
+	s.url = endpoint
+
+	if isDebugEnabled() {
+		fmt.Printf("✅ Shared Prometheus container started at %s (%d jobs)\n", s.url, len(s.configs))
+	}
+
+	log.Printf("✅ Shared Prometheus container started at %s", s.url)
+
+	return nil
+}
+
+// stopContainer para o container se não estiver sendo reutilizado
+func (s *SharedPrometheus) stopContainer(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.container != nil && !shouldReuseContainer() {
+		if isDebugEnabled() {
+			fmt.Println("🛑 Stopping shared Prometheus container...")
+		}
+		return s.container.Terminate(ctx)
+	}
+
+	return nil
+}
+
+// renderPrometheusConfig gera o prometheus.yml com um job por config; alvos locais (localhost/127.0.0.1)
+// são reescritos para testcontainers.HostInternal e suas portas retornadas para HostAccessPorts
+func renderPrometheusConfig(configs []PrometheusScrapeConfig) ([]byte, []int, error) {
+	var ports []int
+	addPort := func(port string) {
+		if p, err := strconv.Atoi(port); err == nil {
+			ports = append(ports, p)
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "global:\n  scrape_interval: %s\n  evaluation_interval: %s\n\nscrape_configs:\n",
+		PrometheusScrapeInterval, PrometheusScrapeInterval)
+
+	for _, config := range configs {
+		scheme, metricsPath := "http", config.MetricsPath
+		addrs := make([]string, 0, len(config.Targets))
+		for _, target := range config.Targets {
+			addr := target
+			if strings.Contains(target, "://") {
+				u, err := url.Parse(target)
+				if err != nil || u.Host == "" {
+					return nil, nil, fmt.Errorf("invalid prometheus scrape target %q", target)
+				}
+				scheme, addr = u.Scheme, u.Host
+				if metricsPath == "" && u.Path != "" {
+					metricsPath = u.Path
+				}
+			}
+			addrs = append(addrs, fmt.Sprintf("%q", rewriteAddrHost(addr, addPort)))
+		}
+		if metricsPath == "" {
+			metricsPath = "/metrics"
+		}
+
+		fmt.Fprintf(&buf, "  - job_name: %q\n    scheme: %s\n    metrics_path: %q\n", config.JobName, scheme, metricsPath)
+		if config.Interval > 0 {
+			fmt.Fprintf(&buf, "    scrape_interval: %s\n", config.Interval)
+		}
+		fmt.Fprintf(&buf, "    static_configs:\n      - targets: [%s]\n", strings.Join(addrs, ", "))
+
+		if len(config.Labels) > 0 {
+			names := make([]string, 0, len(config.Labels))
+			for name := range config.Labels {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			buf.WriteString("        labels:\n")
+			for _, name := range names {
+				fmt.Fprintf(&buf, "          %s: %q\n", name, config.Labels[name])
+			}
+		}
+	}
+
+	sort.Ints(ports)
+	return buf.Bytes(), ports, nil
+}
+
+// Query executa uma consulta PromQL instantânea (GET /api/v1/query)
+func (s *SharedPrometheus) Query(ctx context.Context, expr string) ([]PromSample, error) {
+	var response struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
+		Data   struct {
+			ResultType string          `json:"resultType"`
+			Result     json.RawMessage `json:"result"`
+		} `json:"data"`
+	}
+	if err := s.request(ctx, http.MethodGet, "/api/v1/query?"+url.Values{"query": {expr}}.Encode(), &response); err != nil {
+		return nil, err
+	}
+	if response.Status != "success" {
+		return nil, fmt.Errorf("promql query %q failed: %s", expr, response.Error)
+	}
+
+	switch response.Data.ResultType {
+	case "vector":
+		var vector []struct {
+			Metric map[string]string `json:"metric"`
+			Value  [2]interface{}    `json:"value"`
+		}
+		if err := json.Unmarshal(response.Data.Result, &vector); err != nil {
+			return nil, fmt.Errorf("failed to decode promql vector: %w", err)
+		}
+
+		samples := make([]PromSample, 0, len(vector))
+		for _, v := range vector {
+			value, err := parsePromValue(v.Value)
+			if err != nil {
+				return nil, err
+			}
+			samples = append(samples, PromSample{Metric: v.Metric, Value: value})
+		}
+		return samples, nil
+	case "scalar":
+		var scalar [2]interface{}
+		if err := json.Unmarshal(response.Data.Result, &scalar); err != nil {
+			return nil, fmt.Errorf("failed to decode promql scalar: %w", err)
+		}
+		value, err := parsePromValue(scalar)
+		if err != nil {
+			return nil, err
+		}
+		return []PromSample{{Metric: map[string]string{}, Value: value}}, nil
+	default:
+		return nil, fmt.Errorf("unsupported promql result type %q (use an instant vector or scalar)", response.Data.ResultType)
+	}
+}
+
+// TargetsUp indica se todos os alvos registrados já tiveram um scrape bem-sucedido
+func (s *SharedPrometheus) TargetsUp(ctx context.Context) (bool, error) {
+	jobs := make([]string, len(s.configs))
+	targets := 0
+	for i, config := range s.configs {
+		jobs[i] = regexp.QuoteMeta(config.JobName)
+		targets += len(config.Targets)
+	}
+
+	samples, err := s.Query(ctx, fmt.Sprintf(`up{job=~%q}`, strings.Join(jobs, "|")))
+	if err != nil {
+		return false, err
+	}
+	if len(samples) < targets {
+		return false, nil
+	}
+	for _, sample := range samples {
+		if sample.Value != 1 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// DeleteAll remove todas as séries gravadas (admin API) para que o próximo teste comece sem histórico
+func (s *SharedPrometheus) DeleteAll(ctx context.Context) error {
+	params := url.Values{"match[]": {`{__name__=~".+"}`}}
+	if err := s.request(ctx, http.MethodPost, "/api/v1/admin/tsdb/delete_series?"+params.Encode(), nil); err != nil {
+		return err
+	}
+	return s.request(ctx, http.MethodPost, "/api/v1/admin/tsdb/clean_tombstones", nil)
+}
+
+// request executa uma chamada à API do Prometheus e decodifica a resposta em output (se informado)
+func (s *SharedPrometheus) request(ctx context.Context, method, path string, output interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, s.GetURL()+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create prometheus request: %w", err)
+	}
+
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("prometheus request %s %s failed: %w", method, path, err)
+	}
+	defer res.Body.Close()
+
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read prometheus response: %w", err)
+	}
+	// A API de consulta devolve 400/422 com o erro no corpo JSON
+	if res.StatusCode >= 300 && (output == nil || res.StatusCode >= 500) {
+		return fmt.Errorf("prometheus request %s %s failed: %s: %s", method, path, res.Status, raw)
+	}
+
+	if output != nil {
+		if err := json.Unmarshal(raw, output); err != nil {
+			return fmt.Errorf("failed to decode prometheus response: %w", err)
+		}
+	}
+	return nil
+}
+
+// testConnection verifica se o Prometheus está pronto
+func (s *SharedPrometheus) testConnection(ctx context.Context) error {
+	ctxPing, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if s.url == "" {
+		return fmt.Errorf("prometheus url not available")
+	}
+
+	req, err := http.NewRequestWithContext(ctxPing, http.MethodGet, s.url+"/-/ready", nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("prometheus not ready: %s", res.Status)
+	}
+	return nil
+}
+
+// parsePromValue converte o par [timestamp, "valor"] da API em float64
+func parsePromValue(pair [2]interface{}) (float64, error) {
+	raw, ok := pair[1].(string)
+	if !ok {
+		return 0, fmt.Errorf("unexpected promql value %v", pair[1])
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid promql value %q: %w", raw, err)
+	}
+	return value, nil
+}
@@ -0,0 +1,461 @@
+package testhelper
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+var (
+	sharedPubSub *SharedPubSub
+	pubSubOnce   sync.Once
+)
+
+// PubSubMessage é uma mensagem recebida de uma subscription do emulador
+type PubSubMessage struct {
+	ID          string
+	Data        []byte
+	Attributes  map[string]string
+	OrderingKey string
+	PublishTime time.Time
+}
+
+// SharedPubSub gerencia um container do emulador do Google Pub/Sub compartilhado entre testes
+// A administração usa a API REST do emulador (mesma porta do gRPC)
+type SharedPubSub struct {
+	mu         sync.RWMutex
+	container  testcontainers.Container
+	host       string // host:porta (PUBSUB_EMULATOR_HOST)
+	projectID  string
+	httpClient *http.Client
+	refCount   int32
+	startOnce  sync.Once
+	startErr   error
+	started    bool
+}
+
+// GetSharedPubSub retorna a instância singleton do emulador Pub/Sub compartilhado
+func GetSharedPubSub() *SharedPubSub {
+	pubSubOnce.Do(func() {
+		sharedPubSub = &SharedPubSub{
+			projectID:  "test-project",
+			httpClient: &http.Client{Timeout: 30 * time.Second},
+		}
+	})
+	return sharedPubSub
+}
+
+// Start inicializa o container do emulador Pub/Sub compartilhado
+func (s *SharedPubSub) Start(ctx context.Context) error {
+	// Primeiro, tenta reutilizar container existente (sem lock global)
+	s.mu.RLock()
+	if s.started && s.host != "" {
+		s.mu.RUnlock()
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+	} else {
+		s.mu.RUnlock()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Double-check: outro goroutine pode ter criado enquanto aguardava lock
+	if s.started && s.host != "" {
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+		// Conexão perdida, reset para tentar novamente
+		s.started = false
+		s.startOnce = sync.Once{}
+	}
+
+	s.startOnce.Do(func() {
+		s.startErr = s.startContainer(ctx)
+		if s.startErr == nil {
+			s.started = true
+		}
+	})
+
+	if !s.started {
+		return fmt.Errorf("shared pubsub not started: %w", s.startErr)
+	}
+
+	atomic.AddInt32(&s.refCount, 1)
+	return nil
+}
+
+// Stop decrementa o contador de referências e para o container se necessário
+func (s *SharedPubSub) Stop(ctx context.Context) error {
+	if atomic.AddInt32(&s.refCount, -1) <= 0 {
+		return s.stopContainer(ctx)
+	}
+	return nil
+}
+
+// GetEmulatorHost retorna o valor para PUBSUB_EMULATOR_HOST (host:porta)
+func (s *SharedPubSub) GetEmulatorHost() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.host
+}
+
+// GetProjectID retorna o projeto usado no emulador
+func (s *SharedPubSub) GetProjectID() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.projectID
+}
+
+// startContainer inicia o container do emulador ou usa um externo
+func (s *SharedPubSub) startContainer(ctx context.Context) error {
+	if projectID := os.Getenv("PUBSUB_PROJECT_ID"); projectID != "" {
+		s.projectID = projectID
+	}
+
+	// Verifica se deve usar emulador externo
+	if useExternal, _ := strconv.ParseBool(os.Getenv("USE_EXTERNAL_PUBSUB")); useExternal {
+		host := os.Getenv("PUBSUB_EMULATOR_HOST")
+		if host == "" {
+			host = "localhost:8085"
+		}
+		s.host = host
+
+		if err := s.testConnection(ctx); err != nil {
+			return externalFallback("pubsub emulator", "USE_EXTERNAL_PUBSUB", s.host, err, func() error {
+				return s.setupTestcontainer(ctx)
+			})
+		}
+
+		if isDebugEnabled() {
+			fmt.Printf("✅ Using external Pub/Sub emulator at %s\n", s.host)
+		}
+		return nil
+	}
+
+	return s.setupTestcontainer(ctx)
+}
+
+// setupTestcontainer cria e inicia o container do emulador
+func (s *SharedPubSub) setupTestcontainer(ctx context.Context) error {
+	if isDebugEnabled() {
+		fmt.Println("🚀 Starting shared Pub/Sub emulator container...")
+	}
+
+	req := testcontainers.ContainerRequest{
+		Image:        "gcr.io/google.com/cloudsdktool/google-cloud-cli:467.0.0-emulators",
+		ExposedPorts: []string{"8085/tcp"},
+		Name:         "shared-pubsub-test",
+		Cmd: []string{
+			"gcloud", "beta", "emulators", "pubsub", "start",
+			"--host-port=0.0.0.0:8085", "--project=" + s.projectID,
+		},
+		WaitingFor: wait.ForLog("Server started").WithStartupTimeout(60 * time.Second),
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+		Reuse:            shouldReuseContainer(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start pubsub container: %w", err)
+	}
+
+	host, err := container.PortEndpoint(ctx, "8085/tcp", "")
+	if err != nil {
+		return fmt.Errorf("failed to get pubsub endpoint: %w", err)
+	}
+
+	s.container = container
+	s.host = host
+
+	if err := s.testConnection(ctx); err != nil {
+		return fmt.Errorf("failed to connect to pubsub emulator: %w", err)
+	}
+
+	if isDebugEnabled() {
+		fmt.Printf("✅ Shared Pub/Sub emulator started at %s (project %s)\n", host, s.projectID)
+	}
+
+	log.Printf("✅ Shared Pub/Sub emulator started at %s", host)
+
+	return nil
+}
+
+// stopContainer para o container se não estiver sendo reutilizado
+func (s *SharedPubSub) stopContainer(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.container != nil && !shouldReuseContainer() {
+		if isDebugEnabled() {
+			fmt.Println("🛑 Stopping shared Pub/Sub emulator container...")
+		}
+		return s.container.Terminate(ctx)
+	}
+
+	return nil
+}
+
+// TenantTopicID deriva o ID de tópico/subscription do tenant (ex.: "test_ab12-orders")
+func TenantTopicID(tenantID, name string) string {
+	return tenantID + "-" + name
+}
+
+// CreateTopic cria o tópico (ignora se já existir)
+func (s *SharedPubSub) CreateTopic(ctx context.Context, topic string) error {
+	status, err := s.request(ctx, http.MethodPut, s.topicPath(topic), map[string]interface{}{}, nil)
+	if err != nil && status != http.StatusConflict {
+		return fmt.Errorf("failed to create topic %s: %w", topic, err)
+	}
+	return nil
+}
+
+// CreateSubscription cria a subscription no tópico (ignora se já existir)
+func (s *SharedPubSub) CreateSubscription(ctx context.Context, topic, subscription string) error {
+	body := map[string]interface{}{
+		"topic":              s.topicPath(topic)[len("/v1/"):],
+		"ackDeadlineSeconds": 10,
+	}
+	status, err := s.request(ctx, http.MethodPut, s.subscriptionPath(subscription), body, nil)
+	if err != nil && status != http.StatusConflict {
+		return fmt.Errorf("failed to create subscription %s: %w", subscription, err)
+	}
+	return nil
+}
+
+// Publish publica mensagens no tópico e retorna os IDs gerados
+func (s *SharedPubSub) Publish(ctx context.Context, topic string, messages ...[]byte) ([]string, error) {
+	payload := make([]map[string]interface{}, len(messages))
+	for i, data := range messages {
+		payload[i] = map[string]interface{}{"data": base64.StdEncoding.EncodeToString(data)}
+	}
+
+	var response struct {
+		MessageIDs []string `json:"messageIds"`
+	}
+	if _, err := s.request(ctx, http.MethodPost, s.topicPath(topic)+":publish", map[string]interface{}{"messages": payload}, &response); err != nil {
+		return nil, fmt.Errorf("failed to publish to %s: %w", topic, err)
+	}
+	return response.MessageIDs, nil
+}
+
+// Pull busca até max mensagens da subscription e confirma (ack) as recebidas
+func (s *SharedPubSub) Pull(ctx context.Context, subscription string, max int) ([]PubSubMessage, error) {
+	var response struct {
+		ReceivedMessages []struct {
+			AckID   string `json:"ackId"`
+			Message struct {
+				Data        string            `json:"data"`
+				Attributes  map[string]string `json:"attributes"`
+				MessageID   string            `json:"messageId"`
+				PublishTime time.Time         `json:"publishTime"`
+				OrderingKey string            `json:"orderingKey"`
+			} `json:"message"`
+		} `json:"receivedMessages"`
+	}
+	body := map[string]interface{}{"maxMessages": max, "returnImmediately": true}
+	if _, err := s.request(ctx, http.MethodPost, s.subscriptionPath(subscription)+":pull", body, &response); err != nil {
+		return nil, fmt.Errorf("failed to pull from %s: %w", subscription, err)
+	}
+
+	messages := make([]PubSubMessage, 0, len(response.ReceivedMessages))
+	ackIDs := make([]string, 0, len(response.ReceivedMessages))
+	for _, received := range response.ReceivedMessages {
+		data, err := base64.StdEncoding.DecodeString(received.Message.Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode message %s: %w", received.Message.MessageID, err)
+		}
+		messages = append(messages, PubSubMessage{
+			ID:          received.Message.MessageID,
+			Data:        data,
+			Attributes:  received.Message.Attributes,
+			OrderingKey: received.Message.OrderingKey,
+			PublishTime: received.Message.PublishTime,
+		})
+		ackIDs = append(ackIDs, received.AckID)
+	}
+
+	if len(ackIDs) > 0 {
+		if _, err := s.request(ctx, http.MethodPost, s.subscriptionPath(subscription)+":acknowledge", map[string]interface{}{"ackIds": ackIDs}, nil); err != nil {
+			return nil, fmt.Errorf("failed to acknowledge messages of %s: %w", subscription, err)
+		}
+	}
+	return messages, nil
+}
+
+// Drain consome a subscription até ficar vazia por idle (ou até o timeout) e retorna as mensagens
+func (s *SharedPubSub) Drain(ctx context.Context, subscription string, idle, timeout time.Duration) ([]PubSubMessage, error) {
+	deadline := time.Now().Add(timeout)
+	lastMessage := time.Now()
+
+	var drained []PubSubMessage
+	for time.Now().Before(deadline) {
+		messages, err := s.Pull(ctx, subscription, 100)
+		if err != nil {
+			return drained, err
+		}
+
+		if len(messages) > 0 {
+			drained = append(drained, messages...)
+			lastMessage = time.Now()
+			continue
+		}
+		if time.Since(lastMessage) >= idle {
+			return drained, nil
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return drained, nil
+}
+
+// DeleteTopic remove o tópico (ignora se não existir)
+func (s *SharedPubSub) DeleteTopic(ctx context.Context, topic string) error {
+	status, err := s.request(ctx, http.MethodDelete, s.topicPath(topic), nil, nil)
+	if err != nil && status != http.StatusNotFound {
+		return fmt.Errorf("failed to delete topic %s: %w", topic, err)
+	}
+	return nil
+}
+
+// DeleteSubscription remove a subscription (ignora se não existir)
+func (s *SharedPubSub) DeleteSubscription(ctx context.Context, subscription string) error {
+	status, err := s.request(ctx, http.MethodDelete, s.subscriptionPath(subscription), nil, nil)
+	if err != nil && status != http.StatusNotFound {
+		return fmt.Errorf("failed to delete subscription %s: %w", subscription, err)
+	}
+	return nil
+}
+
+// DeleteByPrefix remove subscriptions e tópicos cujo ID começa com o prefixo ("" remove todos)
+func (s *SharedPubSub) DeleteByPrefix(ctx context.Context, prefix string) error {
+	projectPath := "/v1/projects/" + s.GetProjectID()
+
+	var subs struct {
+		Subscriptions []struct {
+			Name string `json:"name"`
+		} `json:"subscriptions"`
+	}
+	if _, err := s.request(ctx, http.MethodGet, projectPath+"/subscriptions?pageSize=1000", nil, &subs); err != nil {
+		return fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+	for _, sub := range subs.Subscriptions {
+		id := sub.Name[strings.LastIndex(sub.Name, "/")+1:]
+		if strings.HasPrefix(id, prefix) {
+			if err := s.DeleteSubscription(ctx, id); err != nil {
+				return err
+			}
+		}
+	}
+
+	var topics struct {
+		Topics []struct {
+			Name string `json:"name"`
+		} `json:"topics"`
+	}
+	if _, err := s.request(ctx, http.MethodGet, projectPath+"/topics?pageSize=1000", nil, &topics); err != nil {
+		return fmt.Errorf("failed to list topics: %w", err)
+	}
+	for _, topic := range topics.Topics {
+		id := topic.Name[strings.LastIndex(topic.Name, "/")+1:]
+		if strings.HasPrefix(id, prefix) {
+			if err := s.DeleteTopic(ctx, id); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// DeleteAll remove todos os tópicos e subscriptions do projeto
+func (s *SharedPubSub) DeleteAll(ctx context.Context) error {
+	return s.DeleteByPrefix(ctx, "")
+}
+
+// topicPath retorna o path REST do tópico
+func (s *SharedPubSub) topicPath(topic string) string {
+	return "/v1/projects/" + s.GetProjectID() + "/topics/" + topic
+}
+
+// subscriptionPath retorna o path REST da subscription
+func (s *SharedPubSub) subscriptionPath(subscription string) string {
+	return "/v1/projects/" + s.GetProjectID() + "/subscriptions/" + subscription
+}
+
+// request executa uma chamada à API REST do emulador
+func (s *SharedPubSub) request(ctx context.Context, method, path string, input interface{}, output interface{}) (int, error) {
+	return pubSubRequest(ctx, s.httpClient, s.GetEmulatorHost(), method, path, input, output)
+}
+
+// pubSubRequest executa a chamada no host informado (sem lock, usado durante o Start)
+func pubSubRequest(ctx context.Context, client *http.Client, host, method, path string, input interface{}, output interface{}) (int, error) {
+	if host == "" {
+		return 0, fmt.Errorf("pubsub emulator host not available")
+	}
+
+	var body io.Reader
+	if input != nil {
+		raw, err := json.Marshal(input)
+		if err != nil {
+			return 0, fmt.Errorf("failed to marshal pubsub request: %w", err)
+		}
+		body = bytes.NewReader(raw)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, "http://"+host+path, body)
+	if err != nil {
+		return 0, err
+	}
+	if input != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		return res.StatusCode, err
+	}
+
+	if res.StatusCode >= 300 {
+		return res.StatusCode, fmt.Errorf("pubsub error: %s %s", res.Status, strings.TrimSpace(string(raw)))
+	}
+
+	if output != nil && len(raw) > 0 {
+		if err := json.Unmarshal(raw, output); err != nil {
+			return res.StatusCode, fmt.Errorf("failed to decode pubsub response: %w", err)
+		}
+	}
+	return res.StatusCode, nil
+}
+
+// testConnection verifica se o emulador responde
+func (s *SharedPubSub) testConnection(ctx context.Context) error {
+	ctxPing, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := pubSubRequest(ctxPing, s.httpClient, s.host, http.MethodGet, "/v1/projects/"+s.projectID+"/topics", nil, nil)
+	return err
+}
@@ -0,0 +1,371 @@
+package testhelper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// Tipos de schema aceitos pelo Schema Registry
+const (
+	SchemaTypeAvro     = "AVRO"
+	SchemaTypeProtobuf = "PROTOBUF"
+	SchemaTypeJSON     = "JSON"
+)
+
+var (
+	sharedSchemaRegistry *SharedSchemaRegistry
+	schemaRegistryOnce   sync.Once
+)
+
+// SharedSchemaRegistry gerencia um Confluent Schema Registry que armazena os schemas no Kafka compartilhado
+type SharedSchemaRegistry struct {
+	mu         sync.RWMutex
+	container  testcontainers.Container
+	url        string
+	httpClient *http.Client
+	refCount   int32
+	startOnce  sync.Once
+	startErr   error
+	started    bool
+}
+
+// GetSharedSchemaRegistry retorna a instância singleton do Schema Registry compartilhado
+func GetSharedSchemaRegistry() *SharedSchemaRegistry {
+	schemaRegistryOnce.Do(func() {
+		sharedSchemaRegistry = &SharedSchemaRegistry{
+			httpClient: &http.Client{Timeout: 30 * time.Second},
+		}
+	})
+	return sharedSchemaRegistry
+}
+
+// Start inicializa o Schema Registry ligado ao Kafka compartilhado (já iniciado)
+func (s *SharedSchemaRegistry) Start(ctx context.Context, kafka *SharedKafka) error {
+	// Primeiro, tenta reutilizar container existente (sem lock global)
+	s.mu.RLock()
+	if s.started && s.url != "" {
+		s.mu.RUnlock()
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+	} else {
+		s.mu.RUnlock()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Double-check: outro goroutine pode ter criado enquanto aguardava lock
+	if s.started && s.url != "" {
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+		// Conexão perdida, reset para tentar novamente
+		s.started = false
+		s.startOnce = sync.Once{}
+	}
+
+	s.startOnce.Do(func() {
+		s.startErr = s.startContainer(ctx, kafka)
+		if s.startErr == nil {
+			s.started = true
+		}
+	})
+
+	if !s.started {
+		return fmt.Errorf("shared schema registry not started: %w", s.startErr)
+	}
+
+	atomic.AddInt32(&s.refCount, 1)
+	return nil
+}
+
+// Stop decrementa o contador de referências e para o container se necessário
+func (s *SharedSchemaRegistry) Stop(ctx context.Context) error {
+	if atomic.AddInt32(&s.refCount, -1) <= 0 {
+		return s.stopContainer(ctx)
+	}
+	return nil
+}
+
+// URL retorna a URL da API REST do Schema Registry
+func (s *SharedSchemaRegistry) URL() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.url
+}
+
+// startContainer inicia o container do Schema Registry ou usa um externo
+func (s *SharedSchemaRegistry) startContainer(ctx context.Context, kafka *SharedKafka) error {
+	// Verifica se deve usar Schema Registry externo
+	if useExternal, _ := strconv.ParseBool(os.Getenv("USE_EXTERNAL_SCHEMA_REGISTRY")); useExternal {
+		s.url = strings.TrimRight(envOrDefault("SCHEMA_REGISTRY_URL", "http://localhost:8081"), "/")
+
+		if err := s.testConnection(ctx); err != nil {
+			return externalFallback("schema registry", "USE_EXTERNAL_SCHEMA_REGISTRY", s.url, err, func() error {
+				return s.setupTestcontainer(ctx, kafka)
+			})
+		}
+
+		if isDebugEnabled() {
+			fmt.Printf("✅ Using external Schema Registry at %s\n", s.url)
+		}
+		return nil
+	}
+
+	return s.setupTestcontainer(ctx, kafka)
+}
+
+// setupTestcontainer cria o Schema Registry com o tópico _schemas no Kafka compartilhado
+func (s *SharedSchemaRegistry) setupTestcontainer(ctx context.Context, kafka *SharedKafka) error {
+	if isDebugEnabled() {
+		fmt.Println("🚀 Starting shared Schema Registry container...")
+	}
+
+	bootstrap, extraHosts, hostPorts, err := KafkaHostForContainer(ctx, kafka)
+	if err != nil {
+		return err
+	}
+
+	servers := strings.Split(bootstrap, ",")
+	for i, server := range servers {
+		servers[i] = "PLAINTEXT://" + server
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "confluentinc/cp-schema-registry:7.6.1",
+			ExposedPorts: []string{"8081/tcp"},
+			Name:         "shared-schema-registry-test",
+			Env: map[string]string{
+				"SCHEMA_REGISTRY_HOST_NAME":                           "schema-registry",
+				"SCHEMA_REGISTRY_LISTENERS":                           "http://0.0.0.0:8081",
+				"SCHEMA_REGISTRY_KAFKASTORE_BOOTSTRAP_SERVERS":        strings.Join(servers, ","),
+				"SCHEMA_REGISTRY_KAFKASTORE_TOPIC_REPLICATION_FACTOR": "1",
+			},
+			HostConfigModifier: func(hostConfig *dockercontainer.HostConfig) {
+				hostConfig.ExtraHosts = append(hostConfig.ExtraHosts, extraHosts...)
+			},
+			HostAccessPorts: hostPorts,
+			WaitingFor:      wait.ForHTTP("/subjects").WithPort("8081/tcp").WithStartupTimeout(2 * time.Minute),
+		},
+		Started: true,
+		Reuse:   shouldReuseContainer(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start schema registry container: %w", err)
+	}
+
+	registryURL, err := container.PortEndpoint(ctx, "8081/tcp", "http")
+	if err != nil {
+		return fmt.Errorf("failed to get schema registry endpoint: %w", err)
+	}
+
+	s.container = container
+	s.url = registryURL
+
+	if isDebugEnabled() {
+		fmt.Printf("✅ Shared Schema Registry started (%s, kafka %s)\n", registryURL, bootstrap)
+	}
+
+	log.Printf("✅ Shared Schema Registry container started at %s", registryURL)
+
+	return nil
+}
+
+// stopContainer para o container se não estiver sendo reutilizado
+func (s *SharedSchemaRegistry) stopContainer(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.container != nil && !shouldReuseContainer() {
+		if isDebugEnabled() {
+			fmt.Println("🛑 Stopping shared Schema Registry container...")
+		}
+		return s.container.Terminate(ctx)
+	}
+
+	return nil
+}
+
+// RegisterSchema registra o schema no subject e retorna o ID global do schema
+// schemaType é SchemaTypeAvro, SchemaTypeProtobuf ou SchemaTypeJSON (vazio equivale a Avro)
+func (s *SharedSchemaRegistry) RegisterSchema(ctx context.Context, subject, schemaType, schema string) (int, error) {
+	payload := map[string]string{"schema": schema}
+	if schemaType != "" && schemaType != SchemaTypeAvro {
+		payload["schemaType"] = schemaType
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal schema: %w", err)
+	}
+
+	raw, err := s.request(ctx, http.MethodPost, "/subjects/"+url.PathEscape(subject)+"/versions", body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to register schema for subject %s: %w", subject, err)
+	}
+
+	var res struct {
+		ID int `json:"id"`
+	}
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return 0, fmt.Errorf("failed to decode schema registry response: %w", err)
+	}
+	return res.ID, nil
+}
+
+// SetCompatibility define o nível de compatibilidade do subject (ex.: BACKWARD, FULL, NONE)
+func (s *SharedSchemaRegistry) SetCompatibility(ctx context.Context, subject, level string) error {
+	body, err := json.Marshal(map[string]string{"compatibility": level})
+	if err != nil {
+		return fmt.Errorf("failed to marshal compatibility: %w", err)
+	}
+
+	if _, err := s.request(ctx, http.MethodPut, "/config/"+url.PathEscape(subject), body); err != nil {
+		return fmt.Errorf("failed to set compatibility of subject %s: %w", subject, err)
+	}
+	return nil
+}
+
+// Subjects retorna os subjects registrados, inclusive os removidos com soft delete
+func (s *SharedSchemaRegistry) Subjects(ctx context.Context) ([]string, error) {
+	raw, err := s.request(ctx, http.MethodGet, "/subjects?deleted=true", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subjects: %w", err)
+	}
+
+	var subjects []string
+	if err := json.Unmarshal(raw, &subjects); err != nil {
+		return nil, fmt.Errorf("failed to decode subjects: %w", err)
+	}
+	return subjects, nil
+}
+
+// DeleteSubject remove o subject definitivamente (soft delete seguido do hard delete exigido pela API)
+// Subjects inexistentes não são erro
+func (s *SharedSchemaRegistry) DeleteSubject(ctx context.Context, subject string) error {
+	path := "/subjects/" + url.PathEscape(subject)
+
+	// O soft delete falha se o subject já foi removido com soft delete; o hard delete resolve os dois casos
+	_, _ = s.request(ctx, http.MethodDelete, path, nil)
+
+	if _, err := s.request(ctx, http.MethodDelete, path+"?permanent=true", nil); err != nil && !isSchemaRegistryNotFound(err) {
+		return fmt.Errorf("failed to delete subject %s: %w", subject, err)
+	}
+
+	// Remove também a configuração de compatibilidade do subject (SetCompatibility)
+	if _, err := s.request(ctx, http.MethodDelete, "/config/"+url.PathEscape(subject), nil); err != nil && !isSchemaRegistryNotFound(err) {
+		return fmt.Errorf("failed to delete compatibility of subject %s: %w", subject, err)
+	}
+	return nil
+}
+
+// ResetSubjects remove definitivamente os subjects com o prefixo informado (vazio remove todos)
+func (s *SharedSchemaRegistry) ResetSubjects(ctx context.Context, prefix string) error {
+	subjects, err := s.Subjects(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, subject := range subjects {
+		if !strings.HasPrefix(subject, prefix) {
+			continue
+		}
+		if err := s.DeleteSubject(ctx, subject); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// schemaRegistryError é uma resposta de erro da API do Schema Registry
+type schemaRegistryError struct {
+	Path      string
+	Status    int
+	ErrorCode int    `json:"error_code"`
+	Message   string `json:"message"`
+}
+
+func (e *schemaRegistryError) Error() string {
+	return fmt.Sprintf("schema registry request %s failed: %d (%d): %s", e.Path, e.Status, e.ErrorCode, e.Message)
+}
+
+// isSchemaRegistryNotFound indica subject/versão/configuração inexistente
+func isSchemaRegistryNotFound(err error) bool {
+	var regErr *schemaRegistryError
+	return errors.As(err, &regErr) && regErr.Status == http.StatusNotFound
+}
+
+// request executa uma chamada à API REST do Schema Registry
+func (s *SharedSchemaRegistry) request(ctx context.Context, method, path string, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, s.URL()+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create schema registry request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	}
+
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("schema registry request %s failed: %w", path, err)
+	}
+	defer res.Body.Close()
+
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema registry response: %w", err)
+	}
+	if res.StatusCode >= 300 {
+		regErr := &schemaRegistryError{Path: path, Status: res.StatusCode, Message: string(raw)}
+		_ = json.Unmarshal(raw, regErr)
+		return nil, regErr
+	}
+	return raw, nil
+}
+
+// testConnection verifica se a API do Schema Registry responde
+func (s *SharedSchemaRegistry) testConnection(ctx context.Context) error {
+	ctxPing, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if s.url == "" {
+		return fmt.Errorf("schema registry url not available")
+	}
+
+	req, err := http.NewRequestWithContext(ctxPing, http.MethodGet, s.url+"/subjects", nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("schema registry error: %s", res.Status)
+	}
+	return nil
+}
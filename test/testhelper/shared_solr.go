@@ -0,0 +1,437 @@
+package testhelper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	tcexec "github.com/testcontainers/testcontainers-go/exec"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+var (
+	sharedSolr *SharedSolr
+	solrOnce   sync.Once
+)
+
+// SolrResult é a resposta de uma consulta ao Solr
+type SolrResult struct {
+	NumFound int                      `json:"numFound"`
+	Docs     []map[string]interface{} `json:"docs"`
+}
+
+// IDs retorna o campo id dos documentos na ordem do resultado
+func (r *SolrResult) IDs() []string {
+	ids := []string{}
+	for _, doc := range r.Docs {
+		if id, ok := doc["id"].(string); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// SharedSolr gerencia um container Solr (standalone) compartilhado entre testes
+// Cada builder informa o core que usa; os cores são criados sob demanda a partir do configset _default
+type SharedSolr struct {
+	mu         sync.RWMutex
+	container  testcontainers.Container
+	url        string
+	httpClient *http.Client
+	refCount   int32
+	startOnce  sync.Once
+	startErr   error
+	started    bool
+	cores      map[string]bool
+}
+
+// GetSharedSolr retorna a instância singleton do Solr compartilhado
+func GetSharedSolr() *SharedSolr {
+	solrOnce.Do(func() {
+		sharedSolr = &SharedSolr{
+			httpClient: &http.Client{Timeout: 30 * time.Second},
+			cores:      make(map[string]bool),
+		}
+	})
+	return sharedSolr
+}
+
+// Start inicializa o container Solr compartilhado
+func (s *SharedSolr) Start(ctx context.Context) error {
+	// Primeiro, tenta reutilizar container existente (sem lock global)
+	s.mu.RLock()
+	if s.started && s.url != "" {
+		s.mu.RUnlock()
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+	} else {
+		s.mu.RUnlock()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Double-check: outro goroutine pode ter criado enquanto aguardava lock
+	if s.started && s.url != "" {
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+		// Conexão perdida, reset para tentar novamente
+		s.started = false
+		s.startOnce = sync.Once{}
+		s.cores = make(map[string]bool)
+	}
+
+	s.startOnce.Do(func() {
+		s.startErr = s.startContainer(ctx)
+		if s.startErr == nil {
+			s.started = true
+		}
+	})
+
+	if !s.started {
+		return fmt.Errorf("shared solr not started: %w", s.startErr)
+	}
+
+	atomic.AddInt32(&s.refCount, 1)
+	return nil
+}
+
+// Stop decrementa o contador de referências e para o container se necessário
+func (s *SharedSolr) Stop(ctx context.Context) error {
+	if atomic.AddInt32(&s.refCount, -1) <= 0 {
+		return s.stopContainer(ctx)
+	}
+	return nil
+}
+
+// GetURL retorna a URL base do Solr (http://host:porta/solr)
+func (s *SharedSolr) GetURL() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.url
+}
+
+// CoreURL retorna a URL do core (http://host:porta/solr/<core>)
+func (s *SharedSolr) CoreURL(core string) string {
+	return s.GetURL() + "/" + core
+}
+
+// startContainer inicia o container Solr ou usa um externo
+func (s *SharedSolr) startContainer(ctx context.Context) error {
+	// Verifica se deve usar Solr externo
+	if useExternal, _ := strconv.ParseBool(os.Getenv("USE_EXTERNAL_SOLR")); useExternal {
+		s.url = strings.TrimRight(envOrDefault("SOLR_URL", "http://localhost:8983/solr"), "/")
+
+		if err := s.testConnection(ctx); err != nil {
+			return externalFallback("solr", "USE_EXTERNAL_SOLR", s.url, err, func() error {
+				return s.setupTestcontainer(ctx)
+			})
+		}
+
+		if isDebugEnabled() {
+			fmt.Printf("✅ Using external Solr at %s\n", s.url)
+		}
+		return nil
+	}
+
+	return s.setupTestcontainer(ctx)
+}
+
+// setupTestcontainer cria e inicia um container Solr standalone
+func (s *SharedSolr) setupTestcontainer(ctx context.Context) error {
+	if isDebugEnabled() {
+		fmt.Println("🚀 Starting shared Solr container...")
+	}
+
+	req := testcontainers.ContainerRequest{
+		Image:        "solr:9.4",
+		ExposedPorts: []string{"8983/tcp"},
+		Name:         "shared-solr-test",
+		Env: map[string]string{
+			"SOLR_HEAP": "512m",
+		},
+		WaitingFor: wait.ForHTTP("/solr/admin/info/system").WithPort("8983/tcp").WithStartupTimeout(2 * time.Minute),
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+		Reuse:            shouldReuseContainer(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start solr container: %w", err)
+	}
+
+	endpoint, err := container.PortEndpoint(ctx, "8983/tcp", "http")
+	if err != nil {
+		return fmt.Errorf("failed to get solr endpoint: %w", err)
+	}
+
+	s.container = container
+	s.url = endpoint + "/solr"
+
+	if isDebugEnabled() {
+		fmt.Printf("✅ Shared Solr container started at %s\n", s.url)
+	}
+
+	log.Printf("✅ Shared Solr container started at %s", s.url)
+
+	return nil
+}
+
+// stopContainer para o container se não estiver sendo reutilizado
+func (s *SharedSolr) stopContainer(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.container != nil && !shouldReuseContainer() {
+		if isDebugEnabled() {
+			fmt.Println("🛑 Stopping shared Solr container...")
+		}
+		return s.container.Terminate(ctx)
+	}
+
+	return nil
+}
+
+// EnsureCore cria o core (se ainda não existir) e aplica o schema opcional:
+//   - .json: comandos da Schema API (add-field, add-field-type...) enviados ao core
+//   - .xml: substitui o managed-schema.xml do core (apenas com container) e recarrega o core
+func (s *SharedSolr) EnsureCore(ctx context.Context, core, schemaPath string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cores[core] {
+		return nil
+	}
+
+	exists, err := s.coreExists(ctx, core)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		if err := s.createCore(ctx, core); err != nil {
+			return err
+		}
+		if schemaPath != "" {
+			if err := s.applySchema(ctx, core, schemaPath); err != nil {
+				return err
+			}
+		}
+	}
+
+	s.cores[core] = true
+
+	if isDebugEnabled() {
+		fmt.Printf("📚 Solr core %s ready\n", core)
+	}
+	return nil
+}
+
+// coreExists consulta o status do core na CoreAdmin API
+func (s *SharedSolr) coreExists(ctx context.Context, core string) (bool, error) {
+	raw, err := solrRequest(ctx, s.httpClient, http.MethodGet,
+		s.url+"/admin/cores?"+url.Values{"action": {"STATUS"}, "core": {core}, "wt": {"json"}}.Encode(), "", nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to get status of solr core %s: %w", core, err)
+	}
+
+	var response struct {
+		Status map[string]map[string]interface{} `json:"status"`
+	}
+	if err := json.Unmarshal(raw, &response); err != nil {
+		return false, fmt.Errorf("failed to decode solr core status: %w", err)
+	}
+
+	_, exists := response.Status[core]["name"]
+	return exists, nil
+}
+
+// createCore cria o core a partir do configset _default
+// No container o "solr create_core" copia o configset, isolando o schema de cada core;
+// no modo externo é usada a CoreAdmin API com configSet=_default
+func (s *SharedSolr) createCore(ctx context.Context, core string) error {
+	if s.container != nil {
+		code, reader, err := s.container.Exec(ctx, []string{"solr", "create_core", "-c", core}, tcexec.Multiplexed())
+		if err != nil {
+			return fmt.Errorf("failed to create solr core %s: %w", core, err)
+		}
+		output, _ := io.ReadAll(reader)
+		if code != 0 {
+			return fmt.Errorf("failed to create solr core %s: exit code %d: %s", core, code, strings.TrimSpace(string(output)))
+		}
+		return nil
+	}
+
+	query := url.Values{"action": {"CREATE"}, "name": {core}, "configSet": {"_default"}, "wt": {"json"}}
+	if _, err := solrRequest(ctx, s.httpClient, http.MethodGet, s.url+"/admin/cores?"+query.Encode(), "", nil); err != nil {
+		return fmt.Errorf("failed to create solr core %s: %w", core, err)
+	}
+	return nil
+}
+
+// applySchema aplica o arquivo de schema ao core recém-criado
+func (s *SharedSolr) applySchema(ctx context.Context, core, schemaPath string) error {
+	content, err := os.ReadFile(schemaPath)
+	if err != nil {
+		return fmt.Errorf("failed to read solr schema %s: %w", schemaPath, err)
+	}
+
+	if strings.EqualFold(filepath.Ext(schemaPath), ".json") {
+		if _, err := solrRequest(ctx, s.httpClient, http.MethodPost, s.url+"/"+core+"/schema", "application/json", content); err != nil {
+			return fmt.Errorf("failed to apply solr schema %s: %w", schemaPath, err)
+		}
+		return nil
+	}
+
+	if s.container == nil {
+		return fmt.Errorf("xml schema %s requires the solr container, use a schema api .json file with an external solr", schemaPath)
+	}
+
+	target := "/var/solr/data/" + core + "/conf/managed-schema.xml"
+	if err := s.container.CopyToContainer(ctx, content, target, 0o644); err != nil {
+		return fmt.Errorf("failed to copy solr schema to container: %w", err)
+	}
+
+	query := url.Values{"action": {"RELOAD"}, "core": {core}, "wt": {"json"}}
+	if _, err := solrRequest(ctx, s.httpClient, http.MethodGet, s.url+"/admin/cores?"+query.Encode(), "", nil); err != nil {
+		return fmt.Errorf("failed to reload solr core %s: %w", core, err)
+	}
+	return nil
+}
+
+// AddDocuments indexa os documentos no core com commit imediato (visíveis para a próxima consulta)
+func (s *SharedSolr) AddDocuments(ctx context.Context, core string, docs ...interface{}) error {
+	body, err := json.Marshal(docs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal solr documents: %w", err)
+	}
+
+	if _, err := s.update(ctx, core, body); err != nil {
+		return fmt.Errorf("failed to add solr documents: %w", err)
+	}
+	return nil
+}
+
+// Query executa uma consulta no handler /select do core (q, fq, sort, rows...)
+func (s *SharedSolr) Query(ctx context.Context, core string, params url.Values) (*SolrResult, error) {
+	query := url.Values{}
+	for key, values := range params {
+		query[key] = values
+	}
+	if query.Get("q") == "" {
+		query.Set("q", "*:*")
+	}
+	query.Set("wt", "json")
+
+	raw, err := solrRequest(ctx, s.httpClient, http.MethodGet, s.CoreURL(core)+"/select?"+query.Encode(), "", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query solr core %s: %w", core, err)
+	}
+
+	var response struct {
+		Response SolrResult `json:"response"`
+	}
+	if err := json.Unmarshal(raw, &response); err != nil {
+		return nil, fmt.Errorf("failed to decode solr response: %w", err)
+	}
+	return &response.Response, nil
+}
+
+// DeleteByQuery remove os documentos do core que casam com a consulta
+func (s *SharedSolr) DeleteByQuery(ctx context.Context, core, query string) error {
+	body, err := json.Marshal(map[string]interface{}{"delete": map[string]interface{}{"query": query}})
+	if err != nil {
+		return fmt.Errorf("failed to marshal solr delete: %w", err)
+	}
+
+	if _, err := s.update(ctx, core, body); err != nil {
+		return fmt.Errorf("failed to delete solr documents: %w", err)
+	}
+	return nil
+}
+
+// DeleteAll remove todos os documentos de todos os cores criados pelos helpers
+func (s *SharedSolr) DeleteAll(ctx context.Context) error {
+	s.mu.RLock()
+	cores := make([]string, 0, len(s.cores))
+	for core := range s.cores {
+		cores = append(cores, core)
+	}
+	s.mu.RUnlock()
+
+	for _, core := range cores {
+		if err := s.DeleteByQuery(ctx, core, "*:*"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// update envia um comando JSON ao handler /update do core com commit
+func (s *SharedSolr) update(ctx context.Context, core string, body []byte) ([]byte, error) {
+	return solrRequest(ctx, s.httpClient, http.MethodPost, s.CoreURL(core)+"/update?commit=true&wt=json", "application/json", body)
+}
+
+// solrRequest executa a chamada HTTP (sem lock, usado durante o Start e o EnsureCore)
+func solrRequest(ctx context.Context, client *http.Client, method, target, contentType string, body []byte) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build solr request: %w", err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read solr response: %w", err)
+	}
+
+	if res.StatusCode >= 300 {
+		return nil, fmt.Errorf("solr error: %s %s", res.Status, strings.TrimSpace(string(raw)))
+	}
+	return raw, nil
+}
+
+// testConnection verifica se o Solr responde
+func (s *SharedSolr) testConnection(ctx context.Context) error {
+	ctxPing, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if s.url == "" {
+		return fmt.Errorf("solr url not available")
+	}
+
+	_, err := solrRequest(ctxPing, s.httpClient, http.MethodGet, s.url+"/admin/info/system?wt=json", "", nil)
+	return err
+}
@@ -0,0 +1,303 @@
+package testhelper
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	tcexec "github.com/testcontainers/testcontainers-go/exec"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+var (
+	sharedTemporal *SharedTemporal
+	temporalOnce   sync.Once
+)
+
+// SharedTemporal gerencia um dev server do Temporal compartilhado entre testes
+// A administração de namespaces usa o CLI `temporal` (dentro do container ou local no modo externo)
+type SharedTemporal struct {
+	mu        sync.RWMutex
+	container testcontainers.Container
+	address   string
+	uiURL     string
+	refCount  int32
+	startOnce sync.Once
+	startErr  error
+	started   bool
+}
+
+// GetSharedTemporal retorna a instância singleton do Temporal compartilhado
+func GetSharedTemporal() *SharedTemporal {
+	temporalOnce.Do(func() {
+		sharedTemporal = &SharedTemporal{}
+	})
+	return sharedTemporal
+}
+
+// Start inicializa o dev server do Temporal compartilhado
+func (s *SharedTemporal) Start(ctx context.Context) error {
+	// Primeiro, tenta reutilizar container existente (sem lock global)
+	s.mu.RLock()
+	if s.started && s.address != "" {
+		s.mu.RUnlock()
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+	} else {
+		s.mu.RUnlock()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Double-check: outro goroutine pode ter criado enquanto aguardava lock
+	if s.started && s.address != "" {
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+		// Conexão perdida, reset para tentar novamente
+		s.started = false
+		s.startOnce = sync.Once{}
+	}
+
+	s.startOnce.Do(func() {
+		s.startErr = s.startContainer(ctx)
+		if s.startErr == nil {
+			s.started = true
+		}
+	})
+
+	if !s.started {
+		return fmt.Errorf("shared temporal not started: %w", s.startErr)
+	}
+
+	atomic.AddInt32(&s.refCount, 1)
+	return nil
+}
+
+// Stop decrementa o contador de referências e para o container se necessário
+func (s *SharedTemporal) Stop(ctx context.Context) error {
+	if atomic.AddInt32(&s.refCount, -1) <= 0 {
+		return s.stopContainer(ctx)
+	}
+	return nil
+}
+
+// GetAddress retorna o endereço host:porta do frontend gRPC (HostPort do client do SDK)
+func (s *SharedTemporal) GetAddress() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.address
+}
+
+// GetUIURL retorna a URL da Web UI do dev server (vazia no modo externo)
+func (s *SharedTemporal) GetUIURL() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.uiURL
+}
+
+// startContainer inicia o dev server do Temporal ou usa um externo
+func (s *SharedTemporal) startContainer(ctx context.Context) error {
+	// Verifica se deve usar Temporal externo
+	if useExternal, _ := strconv.ParseBool(os.Getenv("USE_EXTERNAL_TEMPORAL")); useExternal {
+		address := os.Getenv("TEMPORAL_ADDRESS")
+		if address == "" {
+			address = "localhost:7233"
+		}
+		s.address = address
+
+		if err := s.testConnection(ctx); err != nil {
+			return externalFallback("temporal", "USE_EXTERNAL_TEMPORAL", address, err, func() error {
+				return s.setupTestcontainer(ctx)
+			})
+		}
+
+		if isDebugEnabled() {
+			fmt.Printf("✅ Using external Temporal at %s\n", address)
+		}
+		return nil
+	}
+
+	return s.setupTestcontainer(ctx)
+}
+
+// setupTestcontainer cria e inicia um container com o dev server do Temporal
+func (s *SharedTemporal) setupTestcontainer(ctx context.Context) error {
+	if isDebugEnabled() {
+		fmt.Println("🚀 Starting shared Temporal dev server container...")
+	}
+
+	req := testcontainers.ContainerRequest{
+		Image:        "temporalio/temporal:1.2.0",
+		ExposedPorts: []string{"7233/tcp", "8233/tcp"},
+		Name:         "shared-temporal-test",
+		Cmd:          []string{"server", "start-dev", "--ip", "0.0.0.0"},
+		WaitingFor: wait.ForAll(
+			wait.ForListeningPort("7233/tcp"),
+			wait.ForHTTP("/").WithPort("8233/tcp"),
+		).WithStartupTimeout(60 * time.Second),
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+		Reuse:            shouldReuseContainer(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start temporal container: %w", err)
+	}
+
+	address, err := container.PortEndpoint(ctx, "7233/tcp", "")
+	if err != nil {
+		return fmt.Errorf("failed to get temporal frontend address: %w", err)
+	}
+
+	uiURL, err := container.PortEndpoint(ctx, "8233/tcp", "http")
+	if err != nil {
+		return fmt.Errorf("failed to get temporal ui url: %w", err)
+	}
+
+	s.container = container
+	s.address = address
+	s.uiURL = uiURL
+
+	// Aguarda o namespace default estar disponível com retry
+	for i := 0; i < 50; i++ {
+		err = s.testConnection(ctx)
+		if err == nil {
+			break
+		}
+		if isDebugEnabled() {
+			log.Printf("Waiting for temporal to be ready... attempt %d/50", i+1)
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	if err != nil {
+		return fmt.Errorf("temporal not ready after 50 attempts: %w", err)
+	}
+
+	if isDebugEnabled() {
+		fmt.Printf("✅ Shared Temporal dev server started at %s (UI %s)\n", address, uiURL)
+	}
+
+	log.Printf("✅ Shared Temporal dev server started at %s", address)
+
+	return nil
+}
+
+// stopContainer para o container se não estiver sendo reutilizado
+func (s *SharedTemporal) stopContainer(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.container != nil && !shouldReuseContainer() {
+		if isDebugEnabled() {
+			fmt.Println("🛑 Stopping shared Temporal container...")
+		}
+		return s.container.Terminate(ctx)
+	}
+
+	return nil
+}
+
+// NamespaceName retorna o namespace do Temporal usado por um tenant
+func NamespaceName(tenantID string) string {
+	return tenantID
+}
+
+// EnsureNamespace registra o namespace do tenant se ainda não existir
+// Retorna o nome do namespace e se ele foi criado nesta chamada
+func (s *SharedTemporal) EnsureNamespace(ctx context.Context, tenantID string) (string, bool, error) {
+	namespace := NamespaceName(tenantID)
+
+	if _, err := s.runCLI(ctx, "operator", "namespace", "describe", "--namespace", namespace); err == nil {
+		return namespace, false, nil
+	}
+
+	_, err := s.runCLI(ctx, "operator", "namespace", "create", "--namespace", namespace, "--retention", "24h")
+	if err != nil {
+		return "", false, fmt.Errorf("failed to register temporal namespace %s: %w", namespace, err)
+	}
+
+	// O namespace recém-criado leva alguns instantes para ficar visível no frontend
+	for i := 0; i < 50; i++ {
+		if _, err = s.runCLI(ctx, "operator", "namespace", "describe", "--namespace", namespace); err == nil {
+			return namespace, true, nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	return "", false, fmt.Errorf("temporal namespace %s not available: %w", namespace, err)
+}
+
+// DeleteNamespace remove o namespace do tenant, apagando todos os históricos de workflow
+func (s *SharedTemporal) DeleteNamespace(ctx context.Context, tenantID string) error {
+	namespace := NamespaceName(tenantID)
+
+	if _, err := s.runCLI(ctx, "operator", "namespace", "describe", "--namespace", namespace); err != nil {
+		// Namespace inexistente: nada a limpar
+		return nil
+	}
+
+	if _, err := s.runCLI(ctx, "operator", "namespace", "delete", "--namespace", namespace, "--yes"); err != nil {
+		return fmt.Errorf("failed to delete temporal namespace %s: %w", namespace, err)
+	}
+
+	return nil
+}
+
+// runCLI executa o CLI do Temporal apontando para o frontend compartilhado
+func (s *SharedTemporal) runCLI(ctx context.Context, args ...string) (string, error) {
+	s.mu.RLock()
+	container, address := s.container, s.address
+	s.mu.RUnlock()
+
+	return temporalCLI(ctx, container, address, args)
+}
+
+// temporalCLI executa o CLI dentro do container ou localmente (modo externo)
+func temporalCLI(ctx context.Context, container testcontainers.Container, address string, args []string) (string, error) {
+	if container != nil {
+		cmd := append([]string{"temporal"}, args...)
+		cmd = append(cmd, "--address", "localhost:7233")
+		code, reader, err := container.Exec(ctx, cmd, tcexec.Multiplexed())
+		if err != nil {
+			return "", err
+		}
+		output, _ := io.ReadAll(reader)
+		if code != 0 {
+			return "", fmt.Errorf("temporal exited with code %d: %s", code, strings.TrimSpace(string(output)))
+		}
+		return string(output), nil
+	}
+
+	cmd := exec.CommandContext(ctx, "temporal", append(args, "--address", address)...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("temporal failed: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return string(output), nil
+}
+
+// testConnection testa se o frontend responde consultando o namespace default
+func (s *SharedTemporal) testConnection(ctx context.Context) error {
+	ctxPing, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	// Chamado com o lock já adquirido em Start, por isso não usa runCLI
+	_, err := temporalCLI(ctxPing, s.container, s.address, []string{"operator", "namespace", "describe", "--namespace", "default"})
+	return err
+}
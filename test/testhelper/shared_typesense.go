@@ -0,0 +1,406 @@
+package testhelper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// DefaultTypesenseAPIKey é a API key (admin) do container Typesense dos testes
+const DefaultTypesenseAPIKey = "test-typesense-key"
+
+var (
+	sharedTypesense *SharedTypesense
+	typesenseOnce   sync.Once
+)
+
+// TypesenseResult é a resposta de uma busca no Typesense
+type TypesenseResult struct {
+	Found int `json:"found"`
+	Hits  []struct {
+		Document map[string]interface{} `json:"document"`
+	} `json:"hits"`
+}
+
+// IDs retorna o campo id dos documentos na ordem do resultado
+func (r *TypesenseResult) IDs() []string {
+	ids := []string{}
+	for _, hit := range r.Hits {
+		if id, ok := hit.Document["id"].(string); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// SharedTypesense gerencia um container Typesense compartilhado entre testes
+type SharedTypesense struct {
+	mu          sync.RWMutex
+	container   testcontainers.Container
+	url         string
+	apiKey      string
+	httpClient  *http.Client
+	refCount    int32
+	startOnce   sync.Once
+	startErr    error
+	started     bool
+	collections map[string]bool
+}
+
+// GetSharedTypesense retorna a instância singleton do Typesense compartilhado
+func GetSharedTypesense() *SharedTypesense {
+	typesenseOnce.Do(func() {
+		sharedTypesense = &SharedTypesense{
+			httpClient:  &http.Client{Timeout: 30 * time.Second},
+			collections: make(map[string]bool),
+		}
+	})
+	return sharedTypesense
+}
+
+// Start inicializa o container Typesense compartilhado
+func (s *SharedTypesense) Start(ctx context.Context) error {
+	// Primeiro, tenta reutilizar container existente (sem lock global)
+	s.mu.RLock()
+	if s.started && s.url != "" {
+		s.mu.RUnlock()
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+	} else {
+		s.mu.RUnlock()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Double-check: outro goroutine pode ter criado enquanto aguardava lock
+	if s.started && s.url != "" {
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+		// Conexão perdida, reset para tentar novamente
+		s.started = false
+		s.startOnce = sync.Once{}
+		s.collections = make(map[string]bool)
+	}
+
+	s.startOnce.Do(func() {
+		s.startErr = s.startContainer(ctx)
+		if s.startErr == nil {
+			s.started = true
+		}
+	})
+
+	if !s.started {
+		return fmt.Errorf("shared typesense not started: %w", s.startErr)
+	}
+
+	atomic.AddInt32(&s.refCount, 1)
+	return nil
+}
+
+// Stop decrementa o contador de referências e para o container se necessário
+func (s *SharedTypesense) Stop(ctx context.Context) error {
+	if atomic.AddInt32(&s.refCount, -1) <= 0 {
+		return s.stopContainer(ctx)
+	}
+	return nil
+}
+
+// GetURL retorna a URL do Typesense
+func (s *SharedTypesense) GetURL() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.url
+}
+
+// APIKey retorna a API key usada nas chamadas (X-TYPESENSE-API-KEY)
+func (s *SharedTypesense) APIKey() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.apiKey
+}
+
+// startContainer inicia o container Typesense ou usa um externo
+func (s *SharedTypesense) startContainer(ctx context.Context) error {
+	// Verifica se deve usar Typesense externo
+	if useExternal, _ := strconv.ParseBool(os.Getenv("USE_EXTERNAL_TYPESENSE")); useExternal {
+		s.url = strings.TrimRight(envOrDefault("TYPESENSE_URL", "http://localhost:8108"), "/")
+		s.apiKey = envOrDefault("TYPESENSE_API_KEY", DefaultTypesenseAPIKey)
+
+		if err := s.testConnection(ctx); err != nil {
+			return externalFallback("typesense", "USE_EXTERNAL_TYPESENSE", s.url, err, func() error {
+				return s.setupTestcontainer(ctx)
+			})
+		}
+
+		if isDebugEnabled() {
+			fmt.Printf("✅ Using external Typesense at %s\n", s.url)
+		}
+		return nil
+	}
+
+	return s.setupTestcontainer(ctx)
+}
+
+// setupTestcontainer cria e inicia um container Typesense com API key
+func (s *SharedTypesense) setupTestcontainer(ctx context.Context) error {
+	if isDebugEnabled() {
+		fmt.Println("🚀 Starting shared Typesense container...")
+	}
+
+	req := testcontainers.ContainerRequest{
+		Image:        "typesense/typesense:0.25.2",
+		ExposedPorts: []string{"8108/tcp"},
+		Name:         "shared-typesense-test",
+		Env: map[string]string{
+			"TYPESENSE_API_KEY":  DefaultTypesenseAPIKey,
+			"TYPESENSE_DATA_DIR": "/tmp",
+		},
+		WaitingFor: wait.ForHTTP("/health").WithPort("8108/tcp").WithStartupTimeout(time.Minute),
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+		Reuse:            shouldReuseContainer(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start typesense container: %w", err)
+	}
+
+	endpoint, err := container.PortEndpoint(ctx, "8108/tcp", "http")
+	if err != nil {
+		return fmt.Errorf("failed to get typesense endpoint: %w", err)
+	}
+
+	s.container = container
+	s.url = endpoint
+	s.apiKey = DefaultTypesenseAPIKey
+
+	if isDebugEnabled() {
+		fmt.Printf("✅ Shared Typesense container started at %s\n", s.url)
+	}
+
+	log.Printf("✅ Shared Typesense container started at %s", s.url)
+
+	return nil
+}
+
+// stopContainer para o container se não estiver sendo reutilizado
+func (s *SharedTypesense) stopContainer(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.container != nil && !shouldReuseContainer() {
+		if isDebugEnabled() {
+			fmt.Println("🛑 Stopping shared Typesense container...")
+		}
+		return s.container.Terminate(ctx)
+	}
+
+	return nil
+}
+
+// CreateCollection cria a collection com o schema informado ("name" é sobrescrito pelo nome)
+// Se a collection já existir ela é recriada, garantindo o schema atual
+func (s *SharedTypesense) CreateCollection(ctx context.Context, name string, schema map[string]interface{}) error {
+	body := make(map[string]interface{}, len(schema)+1)
+	for key, value := range schema {
+		body[key] = value
+	}
+	body["name"] = name
+
+	status, raw, err := s.request(ctx, http.MethodPost, "/collections", "application/json", body)
+	if err == nil && status == http.StatusConflict {
+		if err = s.DeleteCollection(ctx, name); err == nil {
+			status, raw, err = s.request(ctx, http.MethodPost, "/collections", "application/json", body)
+		}
+	}
+	if err == nil && status >= 300 {
+		err = fmt.Errorf("typesense error: %d %s", status, strings.TrimSpace(string(raw)))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create typesense collection %s: %w", name, err)
+	}
+
+	s.mu.Lock()
+	s.collections[name] = true
+	s.mu.Unlock()
+	return nil
+}
+
+// ImportDocuments faz upsert dos documentos (endpoint de import em JSONL)
+// Documentos rejeitados (ex.: campo obrigatório ausente) são retornados como erro
+func (s *SharedTypesense) ImportDocuments(ctx context.Context, collection string, docs ...interface{}) error {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	var body bytes.Buffer
+	for _, doc := range docs {
+		line, err := json.Marshal(doc)
+		if err != nil {
+			return fmt.Errorf("failed to marshal typesense document: %w", err)
+		}
+		body.Write(line)
+		body.WriteByte('\n')
+	}
+
+	path := "/collections/" + url.PathEscape(collection) + "/documents/import?action=upsert"
+	status, raw, err := s.request(ctx, http.MethodPost, path, "text/plain", body.Bytes())
+	if err == nil && status >= 300 {
+		err = fmt.Errorf("typesense error: %d %s", status, strings.TrimSpace(string(raw)))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to import typesense documents into %s: %w", collection, err)
+	}
+
+	// A resposta tem uma linha por documento: {"success": true} ou {"success": false, "error": "..."}
+	var failures []string
+	for i, line := range strings.Split(strings.TrimSpace(string(raw)), "\n") {
+		var result struct {
+			Success bool   `json:"success"`
+			Error   string `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(line), &result); err != nil || !result.Success {
+			failures = append(failures, fmt.Sprintf("document %d: %s", i, result.Error))
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to import typesense documents into %s: %s", collection, strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// Search busca na collection (q, query_by, filter_by, sort_by, per_page...)
+func (s *SharedTypesense) Search(ctx context.Context, collection string, params url.Values) (*TypesenseResult, error) {
+	query := url.Values{}
+	for key, values := range params {
+		query[key] = values
+	}
+	if query.Get("q") == "" {
+		query.Set("q", "*")
+	}
+
+	path := "/collections/" + url.PathEscape(collection) + "/documents/search?" + query.Encode()
+	status, raw, err := s.request(ctx, http.MethodGet, path, "", nil)
+	if err == nil && status >= 300 {
+		err = fmt.Errorf("typesense error: %d %s", status, strings.TrimSpace(string(raw)))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to search typesense collection %s: %w", collection, err)
+	}
+
+	var result TypesenseResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode typesense response: %w", err)
+	}
+	return &result, nil
+}
+
+// DeleteCollection remove a collection (ignora collection inexistente)
+func (s *SharedTypesense) DeleteCollection(ctx context.Context, name string) error {
+	s.mu.Lock()
+	delete(s.collections, name)
+	s.mu.Unlock()
+
+	status, raw, err := s.request(ctx, http.MethodDelete, "/collections/"+url.PathEscape(name), "", nil)
+	if err == nil && status >= 300 && status != http.StatusNotFound {
+		err = fmt.Errorf("typesense error: %d %s", status, strings.TrimSpace(string(raw)))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete typesense collection %s: %w", name, err)
+	}
+	return nil
+}
+
+// DeleteAll remove todas as collections criadas pelos helpers
+func (s *SharedTypesense) DeleteAll(ctx context.Context) error {
+	s.mu.RLock()
+	names := make([]string, 0, len(s.collections))
+	for name := range s.collections {
+		names = append(names, name)
+	}
+	s.mu.RUnlock()
+
+	for _, name := range names {
+		if err := s.DeleteCollection(ctx, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// request executa a chamada HTTP autenticada e retorna status e corpo da resposta
+func (s *SharedTypesense) request(ctx context.Context, method, path, contentType string, body interface{}) (int, []byte, error) {
+	var reader io.Reader
+	switch b := body.(type) {
+	case nil:
+	case []byte:
+		reader = bytes.NewReader(b)
+	default:
+		raw, err := json.Marshal(b)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to marshal typesense request: %w", err)
+		}
+		reader = bytes.NewReader(raw)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.url+path, reader)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to build typesense request: %w", err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("X-TYPESENSE-API-KEY", s.apiKey)
+
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer res.Body.Close()
+
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to read typesense response: %w", err)
+	}
+	return res.StatusCode, raw, nil
+}
+
+// testConnection verifica se o Typesense responde e se a API key é aceita
+func (s *SharedTypesense) testConnection(ctx context.Context) error {
+	ctxPing, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if s.url == "" {
+		return fmt.Errorf("typesense url not available")
+	}
+
+	status, raw, err := s.request(ctxPing, http.MethodGet, "/collections", "", nil)
+	if err != nil {
+		return err
+	}
+	if status >= 300 {
+		return fmt.Errorf("typesense error: %d %s", status, strings.TrimSpace(string(raw)))
+	}
+	return nil
+}
@@ -0,0 +1,334 @@
+package testhelper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+var (
+	sharedVault *SharedVault
+	vaultOnce   sync.Once
+)
+
+// vaultKVMount é o mount KV v2 criado pelo Vault em modo dev
+const vaultKVMount = "secret"
+
+// SharedVault gerencia um container Vault (modo dev) compartilhado entre testes
+// A comunicação usa a API HTTP do Vault com o root token fixo do modo dev
+type SharedVault struct {
+	mu         sync.RWMutex
+	container  testcontainers.Container
+	addr       string
+	token      string
+	httpClient *http.Client
+	refCount   int32
+	startOnce  sync.Once
+	startErr   error
+	started    bool
+}
+
+// GetSharedVault retorna a instância singleton do Vault compartilhado
+func GetSharedVault() *SharedVault {
+	vaultOnce.Do(func() {
+		sharedVault = &SharedVault{
+			httpClient: &http.Client{Timeout: 30 * time.Second},
+		}
+	})
+	return sharedVault
+}
+
+// Start inicializa o container Vault compartilhado
+func (s *SharedVault) Start(ctx context.Context) error {
+	// Primeiro, tenta reutilizar container existente (sem lock global)
+	s.mu.RLock()
+	if s.started && s.addr != "" {
+		s.mu.RUnlock()
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+	} else {
+		s.mu.RUnlock()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Double-check: outro goroutine pode ter criado enquanto aguardava lock
+	if s.started && s.addr != "" {
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+		// Conexão perdida, reset para tentar novamente
+		s.started = false
+		s.startOnce = sync.Once{}
+	}
+
+	s.startOnce.Do(func() {
+		s.startErr = s.startContainer(ctx)
+		if s.startErr == nil {
+			s.started = true
+		}
+	})
+
+	if !s.started {
+		return fmt.Errorf("shared vault not started: %w", s.startErr)
+	}
+
+	atomic.AddInt32(&s.refCount, 1)
+	return nil
+}
+
+// Stop decrementa o contador de referências e para o container se necessário
+func (s *SharedVault) Stop(ctx context.Context) error {
+	if atomic.AddInt32(&s.refCount, -1) <= 0 {
+		return s.stopContainer(ctx)
+	}
+	return nil
+}
+
+// GetAddress retorna o endereço HTTP do Vault (VAULT_ADDR da aplicação)
+func (s *SharedVault) GetAddress() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.addr
+}
+
+// GetToken retorna o token de acesso (root token do modo dev)
+func (s *SharedVault) GetToken() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.token
+}
+
+// startContainer inicia o container Vault ou usa um externo
+func (s *SharedVault) startContainer(ctx context.Context) error {
+	// Verifica se deve usar Vault externo
+	if useExternal, _ := strconv.ParseBool(os.Getenv("USE_EXTERNAL_VAULT")); useExternal {
+		addr := os.Getenv("VAULT_ADDR")
+		if addr == "" {
+			addr = "http://localhost:8200"
+		}
+		token := os.Getenv("VAULT_TOKEN")
+		if token == "" {
+			token = "root-token"
+		}
+		s.addr = strings.TrimRight(addr, "/")
+		s.token = token
+
+		if err := s.testConnection(ctx); err != nil {
+			return externalFallback("vault", "USE_EXTERNAL_VAULT", s.addr, err, func() error {
+				return s.setupTestcontainer(ctx)
+			})
+		}
+
+		if isDebugEnabled() {
+			fmt.Printf("✅ Using external Vault at %s\n", s.addr)
+		}
+		return nil
+	}
+
+	return s.setupTestcontainer(ctx)
+}
+
+// setupTestcontainer cria e inicia um container Vault em modo dev
+func (s *SharedVault) setupTestcontainer(ctx context.Context) error {
+	if isDebugEnabled() {
+		fmt.Println("🚀 Starting shared Vault container...")
+	}
+
+	s.token = "root-token"
+
+	req := testcontainers.ContainerRequest{
+		Image:        "hashicorp/vault:1.15",
+		ExposedPorts: []string{"8200/tcp"},
+		Name:         "shared-vault-test",
+		Env: map[string]string{
+			"VAULT_DEV_ROOT_TOKEN_ID":  s.token,
+			"VAULT_DEV_LISTEN_ADDRESS": "0.0.0.0:8200",
+		},
+		CapAdd:     []string{"IPC_LOCK"},
+		WaitingFor: wait.ForHTTP("/v1/sys/health").WithPort("8200/tcp").WithStartupTimeout(30 * time.Second),
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+		Reuse:            shouldReuseContainer(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start vault container: %w", err)
+	}
+
+	addr, err := container.PortEndpoint(ctx, "8200/tcp", "http")
+	if err != nil {
+		return fmt.Errorf("failed to get vault endpoint: %w", err)
+	}
+
+	s.container = container
+	s.addr = addr
+
+	if err := s.testConnection(ctx); err != nil {
+		return fmt.Errorf("failed to connect to vault: %w", err)
+	}
+
+	if isDebugEnabled() {
+		fmt.Printf("✅ Shared Vault container started at %s\n", addr)
+	}
+
+	log.Printf("✅ Shared Vault container started at %s", addr)
+
+	return nil
+}
+
+// stopContainer para o container se não estiver sendo reutilizado
+func (s *SharedVault) stopContainer(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.container != nil && !shouldReuseContainer() {
+		if isDebugEnabled() {
+			fmt.Println("🛑 Stopping shared Vault container...")
+		}
+		return s.container.Terminate(ctx)
+	}
+
+	return nil
+}
+
+// WriteSecret grava um segredo no mount KV v2 (ex.: path "app/database")
+func (s *SharedVault) WriteSecret(ctx context.Context, path string, data map[string]interface{}) error {
+	body := map[string]interface{}{"data": data}
+	if err := s.request(ctx, http.MethodPost, "/v1/"+vaultKVMount+"/data/"+strings.TrimLeft(path, "/"), body, nil); err != nil {
+		return fmt.Errorf("failed to write secret %s: %w", path, err)
+	}
+	return nil
+}
+
+// ReadSecret lê a versão mais recente de um segredo do mount KV v2
+func (s *SharedVault) ReadSecret(ctx context.Context, path string) (map[string]interface{}, error) {
+	var response struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := s.request(ctx, http.MethodGet, "/v1/"+vaultKVMount+"/data/"+strings.TrimLeft(path, "/"), nil, &response); err != nil {
+		return nil, fmt.Errorf("failed to read secret %s: %w", path, err)
+	}
+	return response.Data.Data, nil
+}
+
+// SeedSecrets grava vários segredos de uma vez (path -> dados)
+func (s *SharedVault) SeedSecrets(ctx context.Context, secrets map[string]map[string]interface{}) error {
+	for path, data := range secrets {
+		if err := s.WriteSecret(ctx, path, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WipeKV remove todos os segredos recriando o mount KV v2
+func (s *SharedVault) WipeKV(ctx context.Context) error {
+	if err := s.request(ctx, http.MethodDelete, "/v1/sys/mounts/"+vaultKVMount, nil, nil); err != nil {
+		return fmt.Errorf("failed to disable kv mount: %w", err)
+	}
+
+	mount := map[string]interface{}{
+		"type":    "kv",
+		"options": map[string]string{"version": "2"},
+	}
+	if err := s.request(ctx, http.MethodPost, "/v1/sys/mounts/"+vaultKVMount, mount, nil); err != nil {
+		return fmt.Errorf("failed to enable kv mount: %w", err)
+	}
+
+	// O KV v2 recém-montado passa por um upgrade interno antes de aceitar escritas
+	for i := 0; i < 50; i++ {
+		if err := s.request(ctx, http.MethodGet, "/v1/"+vaultKVMount+"/config", nil, nil); err == nil {
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	return fmt.Errorf("kv mount %s not ready after wipe", vaultKVMount)
+}
+
+// request executa uma chamada à API HTTP do Vault
+func (s *SharedVault) request(ctx context.Context, method, path string, input interface{}, output interface{}) error {
+	s.mu.RLock()
+	addr, token := s.addr, s.token
+	s.mu.RUnlock()
+
+	return vaultRequest(ctx, s.httpClient, addr, token, method, path, input, output)
+}
+
+// vaultRequest executa a chamada no endereço informado (sem lock, usado durante o Start)
+func vaultRequest(ctx context.Context, client *http.Client, addr, token, method, path string, input interface{}, output interface{}) error {
+	if addr == "" {
+		return fmt.Errorf("vault address not available")
+	}
+
+	var body io.Reader
+	if input != nil {
+		raw, err := json.Marshal(input)
+		if err != nil {
+			return fmt.Errorf("failed to marshal vault request: %w", err)
+		}
+		body = bytes.NewReader(raw)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, addr+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to build vault request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if input != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read vault response: %w", err)
+	}
+
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("vault error: %s %s", res.Status, strings.TrimSpace(string(raw)))
+	}
+
+	if output != nil && len(raw) > 0 {
+		if err := json.Unmarshal(raw, output); err != nil {
+			return fmt.Errorf("failed to decode vault response: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// testConnection verifica se o Vault está inicializado e com o token válido
+func (s *SharedVault) testConnection(ctx context.Context) error {
+	ctxPing, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return vaultRequest(ctxPing, s.httpClient, s.addr, s.token, http.MethodGet, "/v1/auth/token/lookup-self", nil, nil)
+}
@@ -0,0 +1,358 @@
+package testhelper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+var (
+	sharedWireMock *SharedWireMock
+	wireMockOnce   sync.Once
+)
+
+// SharedWireMock gerencia um container WireMock compartilhado entre testes
+// Os mappings do diretório informado são importados via API de admin e restaurados a cada reset
+type SharedWireMock struct {
+	mu          sync.RWMutex
+	container   testcontainers.Container
+	baseURL     string
+	httpClient  *http.Client
+	refCount    int32
+	startOnce   sync.Once
+	startErr    error
+	started     bool
+	mappingsDir string
+}
+
+// GetSharedWireMock retorna a instância singleton do WireMock compartilhado
+func GetSharedWireMock() *SharedWireMock {
+	wireMockOnce.Do(func() {
+		sharedWireMock = &SharedWireMock{
+			httpClient: &http.Client{Timeout: 30 * time.Second},
+		}
+	})
+	return sharedWireMock
+}
+
+// Start inicializa o container WireMock compartilhado
+func (s *SharedWireMock) Start(ctx context.Context, mappingsDir string) error {
+	// Primeiro, tenta reutilizar container existente (sem lock global)
+	s.mu.RLock()
+	if s.started && s.baseURL != "" {
+		s.mu.RUnlock()
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+	} else {
+		s.mu.RUnlock()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Double-check: outro goroutine pode ter criado enquanto aguardava lock
+	if s.started && s.baseURL != "" {
+		if err := s.testConnection(ctx); err == nil {
+			atomic.AddInt32(&s.refCount, 1)
+			return nil
+		}
+		// Conexão perdida, reset para tentar novamente
+		s.started = false
+		s.startOnce = sync.Once{}
+	}
+
+	// Armazena o diretório de mappings para este container
+	s.mappingsDir = mappingsDir
+
+	s.startOnce.Do(func() {
+		s.startErr = s.startContainer(ctx)
+		if s.startErr == nil {
+			s.started = true
+		}
+	})
+
+	if !s.started {
+		return fmt.Errorf("shared wiremock not started: %w", s.startErr)
+	}
+
+	atomic.AddInt32(&s.refCount, 1)
+	return nil
+}
+
+// Stop decrementa o contador de referências e para o container se necessário
+func (s *SharedWireMock) Stop(ctx context.Context) error {
+	if atomic.AddInt32(&s.refCount, -1) <= 0 {
+		return s.stopContainer(ctx)
+	}
+	return nil
+}
+
+// GetURL retorna a URL base do WireMock (a aplicação deve usá-la no lugar do serviço externo)
+func (s *SharedWireMock) GetURL() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.baseURL
+}
+
+// startContainer inicia o container WireMock ou usa um externo
+func (s *SharedWireMock) startContainer(ctx context.Context) error {
+	// Verifica se deve usar WireMock externo
+	if useExternal, _ := strconv.ParseBool(os.Getenv("USE_EXTERNAL_WIREMOCK")); useExternal {
+		baseURL := os.Getenv("WIREMOCK_URL")
+		if baseURL == "" {
+			baseURL = "http://localhost:8080"
+		}
+		s.baseURL = strings.TrimRight(baseURL, "/")
+
+		if err := s.testConnection(ctx); err != nil {
+			return externalFallback("wiremock", "USE_EXTERNAL_WIREMOCK", s.baseURL, err, func() error {
+				return s.setupTestcontainer(ctx)
+			})
+		}
+		if err := s.reset(ctx); err != nil {
+			return err
+		}
+
+		if isDebugEnabled() {
+			fmt.Printf("✅ Using external WireMock at %s\n", s.baseURL)
+		}
+		return nil
+	}
+
+	return s.setupTestcontainer(ctx)
+}
+
+// setupTestcontainer cria e inicia um container WireMock
+func (s *SharedWireMock) setupTestcontainer(ctx context.Context) error {
+	if isDebugEnabled() {
+		fmt.Println("🚀 Starting shared WireMock container...")
+	}
+
+	req := testcontainers.ContainerRequest{
+		Image:        "wiremock/wiremock:3.3.1",
+		ExposedPorts: []string{"8080/tcp"},
+		Name:         "shared-wiremock-test",
+		Cmd:          []string{"--disable-banner"},
+		WaitingFor:   wait.ForHTTP("/__admin/health").WithPort("8080/tcp").WithStartupTimeout(60 * time.Second),
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+		Reuse:            shouldReuseContainer(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start wiremock container: %w", err)
+	}
+
+	baseURL, err := container.PortEndpoint(ctx, "8080/tcp", "http")
+	if err != nil {
+		return fmt.Errorf("failed to get wiremock endpoint: %w", err)
+	}
+
+	s.container = container
+	s.baseURL = baseURL
+
+	if err := s.testConnection(ctx); err != nil {
+		return fmt.Errorf("failed to connect to wiremock: %w", err)
+	}
+
+	// Container reutilizado pode ter stubs de execuções anteriores
+	if err := s.reset(ctx); err != nil {
+		return err
+	}
+
+	if isDebugEnabled() {
+		fmt.Printf("✅ Shared WireMock container started at %s\n", baseURL)
+	}
+
+	log.Printf("✅ Shared WireMock container started at %s", baseURL)
+
+	return nil
+}
+
+// stopContainer para o container se não estiver sendo reutilizado
+func (s *SharedWireMock) stopContainer(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.container != nil && !shouldReuseContainer() {
+		if isDebugEnabled() {
+			fmt.Println("🛑 Stopping shared WireMock container...")
+		}
+		return s.container.Terminate(ctx)
+	}
+
+	return nil
+}
+
+// AddStub registra um stub no formato de mapping do WireMock ({"request": ..., "response": ...})
+func (s *SharedWireMock) AddStub(ctx context.Context, mapping map[string]interface{}) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if err := s.admin(ctx, http.MethodPost, "/__admin/mappings", mapping, nil); err != nil {
+		return fmt.Errorf("failed to register stub: %w", err)
+	}
+	return nil
+}
+
+// StubJSON registra um stub que responde com status e corpo JSON para method + path exatos
+func (s *SharedWireMock) StubJSON(ctx context.Context, method, path string, status int, body interface{}) error {
+	return s.AddStub(ctx, map[string]interface{}{
+		"request": map[string]interface{}{
+			"method": strings.ToUpper(method),
+			"url":    path,
+		},
+		"response": map[string]interface{}{
+			"status":   status,
+			"jsonBody": body,
+			"headers":  map[string]string{"Content-Type": "application/json"},
+		},
+	})
+}
+
+// CountRequests retorna quantas requisições com method + path o WireMock recebeu
+func (s *SharedWireMock) CountRequests(ctx context.Context, method, path string) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var response struct {
+		Count int `json:"count"`
+	}
+	pattern := map[string]interface{}{"method": strings.ToUpper(method), "url": path}
+	if err := s.admin(ctx, http.MethodPost, "/__admin/requests/count", pattern, &response); err != nil {
+		return 0, fmt.Errorf("failed to count requests: %w", err)
+	}
+	return response.Count, nil
+}
+
+// Reset remove stubs e requisições registradas, restaurando os mappings do diretório configurado
+func (s *SharedWireMock) Reset(ctx context.Context) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.reset(ctx)
+}
+
+// reset executa o reset sem lock (usado durante o Start)
+func (s *SharedWireMock) reset(ctx context.Context) error {
+	if err := s.admin(ctx, http.MethodPost, "/__admin/reset", nil, nil); err != nil {
+		return fmt.Errorf("failed to reset wiremock: %w", err)
+	}
+	if err := s.importMappings(ctx); err != nil {
+		return fmt.Errorf("failed to import wiremock mappings: %w", err)
+	}
+	return nil
+}
+
+// importMappings importa os arquivos .json do diretório de mappings
+// Cada arquivo pode conter um único mapping ou {"mappings": [...]}
+func (s *SharedWireMock) importMappings(ctx context.Context) error {
+	if s.mappingsDir == "" {
+		return nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(s.mappingsDir, "*.json"))
+	if err != nil {
+		return err
+	}
+
+	var mappings []json.RawMessage
+	for _, file := range files {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read mapping %s: %w", file, err)
+		}
+
+		var multi struct {
+			Mappings []json.RawMessage `json:"mappings"`
+		}
+		if err := json.Unmarshal(raw, &multi); err != nil {
+			return fmt.Errorf("invalid mapping %s: %w", file, err)
+		}
+		if len(multi.Mappings) > 0 {
+			mappings = append(mappings, multi.Mappings...)
+		} else {
+			mappings = append(mappings, raw)
+		}
+	}
+
+	if len(mappings) == 0 {
+		return nil
+	}
+
+	if isDebugEnabled() {
+		log.Printf("Importing %d WireMock mappings from %s", len(mappings), s.mappingsDir)
+	}
+
+	return s.admin(ctx, http.MethodPost, "/__admin/mappings/import", map[string]interface{}{"mappings": mappings}, nil)
+}
+
+// admin executa uma chamada à API de administração do WireMock
+func (s *SharedWireMock) admin(ctx context.Context, method, path string, input interface{}, output interface{}) error {
+	if s.baseURL == "" {
+		return fmt.Errorf("wiremock endpoint not available")
+	}
+
+	var body io.Reader
+	if input != nil {
+		raw, err := json.Marshal(input)
+		if err != nil {
+			return fmt.Errorf("failed to marshal admin request: %w", err)
+		}
+		body = bytes.NewReader(raw)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	if input != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("wiremock admin error: %s %s", res.Status, strings.TrimSpace(string(raw)))
+	}
+
+	if output != nil && len(raw) > 0 {
+		return json.Unmarshal(raw, output)
+	}
+	return nil
+}
+
+// testConnection testa se a API de admin está respondendo
+func (s *SharedWireMock) testConnection(ctx context.Context) error {
+	ctxPing, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return s.admin(ctxPing, http.MethodGet, "/__admin/health", nil, nil)
+}
@@ -0,0 +1,256 @@
+package testhelper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"github.com/stretchr/testify/require"
+)
+
+// ESSnapshotPath é o diretório do container (path.repo) montado para o repositório de snapshots
+const ESSnapshotPath = "/usr/share/elasticsearch/snapshots"
+
+// esSnapshotRepository é o repositório fs registrado pelos helpers de snapshot
+const esSnapshotRepository = "testhelper-snapshots"
+
+// registeredSnapshotRepos guarda os clusters em que o repositório já foi registrado
+var registeredSnapshotRepos sync.Map
+
+// SnapshotState grava um snapshot dos índices (padrão: todos os índices que não são de sistema) no
+// repositório do container, para restaurar com RestoreState em vez de recarregar as fixtures
+// Um snapshot com o mesmo nome é substituído
+//
+//	if !suite.HasSnapshot("catalog-1m") {
+//		suite.StreamFixtures("products", "testdata/products.ndjson.gz", testhelper.StreamOptions{})
+//		suite.SnapshotState("catalog-1m", "products")
+//	}
+//	suite.RestoreState("catalog-1m")
+func (s *IntegrationTestSuite) SnapshotState(name string, indices ...string) {
+	s.t.Helper()
+	defer s.trackOperation("SnapshotState")()
+
+	client := s.ES()
+	require.NotNil(s.t, client, "Elasticsearch not configured")
+	s.ensureSnapshotRepository(client)
+
+	snapshot := s.snapshotName(name)
+	s.deleteSnapshot(client, snapshot)
+
+	targets := "*,-.*"
+	if len(indices) > 0 {
+		scoped := make([]string, len(indices))
+		for i, index := range indices {
+			scoped[i] = s.RunScopedIndex(index)
+		}
+		targets = strings.Join(scoped, ",")
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"indices":              targets,
+		"include_global_state": false,
+		"ignore_unavailable":   false,
+	})
+	require.NoError(s.t, err, "Failed to marshal snapshot request")
+
+	wait := true
+	res, err := esapi.SnapshotCreateRequest{
+		Repository:        esSnapshotRepository,
+		Snapshot:          snapshot,
+		Body:              bytes.NewReader(body),
+		WaitForCompletion: &wait,
+	}.Do(s.ctx, client)
+	require.NoError(s.t, err, "Failed to create snapshot %s", snapshot)
+	defer res.Body.Close()
+
+	if res.IsError() {
+		require.Fail(s.t, fmt.Sprintf("Failed to create snapshot %s: %s", snapshot, res.String()))
+	}
+
+	var result struct {
+		Snapshot struct {
+			State   string   `json:"state"`
+			Indices []string `json:"indices"`
+		} `json:"snapshot"`
+	}
+	require.NoError(s.t, json.NewDecoder(res.Body).Decode(&result), "Failed to decode snapshot response")
+	require.Equal(s.t, "SUCCESS", result.Snapshot.State, "Snapshot %s did not complete", snapshot)
+
+	if isDebugEnabled() {
+		fmt.Printf("📸 Snapshot %s created with %d index(es)\n", snapshot, len(result.Snapshot.Indices))
+	}
+}
+
+// RestoreState restaura os índices do snapshot, removendo antes as versões atuais deles
+// Os índices restaurados são compartilhados: não use com testes paralelos que escrevem neles
+func (s *IntegrationTestSuite) RestoreState(name string) {
+	s.t.Helper()
+	defer s.trackOperation("RestoreState")()
+
+	client := s.ES()
+	require.NotNil(s.t, client, "Elasticsearch not configured")
+	s.ensureSnapshotRepository(client)
+
+	snapshot := s.snapshotName(name)
+	indices, found := s.snapshotIndices(client, snapshot)
+	require.True(s.t, found, "Snapshot %s not found, create it with SnapshotState", snapshot)
+
+	if len(indices) > 0 {
+		res, err := client.Indices.Delete(indices,
+			client.Indices.Delete.WithContext(s.ctx),
+			client.Indices.Delete.WithIgnoreUnavailable(true))
+		require.NoError(s.t, err, "Failed to delete indices before restore")
+		res.Body.Close()
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"indices":              strings.Join(indices, ","),
+		"include_global_state": false,
+		"include_aliases":      true,
+	})
+	require.NoError(s.t, err, "Failed to marshal restore request")
+
+	wait := true
+	res, err := esapi.SnapshotRestoreRequest{
+		Repository:        esSnapshotRepository,
+		Snapshot:          snapshot,
+		Body:              bytes.NewReader(body),
+		WaitForCompletion: &wait,
+	}.Do(s.ctx, client)
+	require.NoError(s.t, err, "Failed to restore snapshot %s", snapshot)
+	defer res.Body.Close()
+
+	if res.IsError() {
+		require.Fail(s.t, fmt.Sprintf("Failed to restore snapshot %s: %s", snapshot, res.String()))
+	}
+}
+
+// HasSnapshot indica se o snapshot já existe no repositório (ex.: gravado por um teste anterior)
+func (s *IntegrationTestSuite) HasSnapshot(name string) bool {
+	s.t.Helper()
+
+	client := s.ES()
+	require.NotNil(s.t, client, "Elasticsearch not configured")
+	s.ensureSnapshotRepository(client)
+
+	_, found := s.snapshotIndices(client, s.snapshotName(name))
+	return found
+}
+
+// DeleteSnapshot remove o snapshot do repositório (inexistente não é erro)
+func (s *IntegrationTestSuite) DeleteSnapshot(name string) {
+	s.t.Helper()
+
+	client := s.ES()
+	require.NotNil(s.t, client, "Elasticsearch not configured")
+	s.ensureSnapshotRepository(client)
+
+	s.deleteSnapshot(client, s.snapshotName(name))
+}
+
+// snapshotName normaliza o nome (snapshots aceitam só minúsculas) e aplica o sufixo da execução
+// O prefixo de índice do teste não é aplicado: o snapshot é reaproveitado pelos testes seguintes
+func (s *IntegrationTestSuite) snapshotName(name string) string {
+	name = strings.ToLower(indexNameInvalid.ReplaceAllString(name, "_"))
+	if !externalES() {
+		return name
+	}
+
+	s.acquireRunScope()
+	return RunScopedName(name)
+}
+
+// snapshotIndices retorna os índices do snapshot e se ele existe
+func (s *IntegrationTestSuite) snapshotIndices(client *elasticsearch.Client, snapshot string) ([]string, bool) {
+	s.t.Helper()
+
+	ignore := true
+	res, err := esapi.SnapshotGetRequest{
+		Repository:        esSnapshotRepository,
+		Snapshot:          []string{snapshot},
+		IgnoreUnavailable: &ignore,
+	}.Do(s.ctx, client)
+	require.NoError(s.t, err, "Failed to get snapshot %s", snapshot)
+	defer res.Body.Close()
+
+	if res.StatusCode == 404 {
+		return nil, false
+	}
+	if res.IsError() {
+		require.Fail(s.t, fmt.Sprintf("Failed to get snapshot %s: %s", snapshot, res.String()))
+	}
+
+	var body struct {
+		Snapshots []struct {
+			Indices []string `json:"indices"`
+			State   string   `json:"state"`
+		} `json:"snapshots"`
+	}
+	require.NoError(s.t, json.NewDecoder(res.Body).Decode(&body), "Failed to decode snapshot response")
+
+	for _, snap := range body.Snapshots {
+		if snap.State == "SUCCESS" {
+			return snap.Indices, true
+		}
+	}
+	return nil, false
+}
+
+// deleteSnapshot remove o snapshot, ignorando snapshot inexistente
+func (s *IntegrationTestSuite) deleteSnapshot(client *elasticsearch.Client, snapshot string) {
+	s.t.Helper()
+
+	res, err := esapi.SnapshotDeleteRequest{
+		Repository: esSnapshotRepository,
+		Snapshot:   []string{snapshot},
+	}.Do(s.ctx, client)
+	require.NoError(s.t, err, "Failed to delete snapshot %s", snapshot)
+	defer res.Body.Close()
+
+	if res.IsError() && res.StatusCode != 404 {
+		require.Fail(s.t, fmt.Sprintf("Failed to delete snapshot %s: %s", snapshot, res.String()))
+	}
+}
+
+// ensureSnapshotRepository registra o repositório fs no cluster (uma vez por client)
+// No modo externo a localização vem de ES_SNAPSHOT_PATH, que precisa estar em path.repo do cluster
+func (s *IntegrationTestSuite) ensureSnapshotRepository(client *elasticsearch.Client) {
+	s.t.Helper()
+
+	if _, ok := registeredSnapshotRepos.Load(client); ok {
+		return
+	}
+
+	location := envOrDefault("ES_SNAPSHOT_PATH", ESSnapshotPath)
+	body, err := json.Marshal(map[string]interface{}{
+		"type":     "fs",
+		"settings": map[string]interface{}{"location": location},
+	})
+	require.NoError(s.t, err, "Failed to marshal snapshot repository")
+
+	res, err := esapi.SnapshotCreateRepositoryRequest{
+		Repository: esSnapshotRepository,
+		Body:       bytes.NewReader(body),
+	}.Do(s.ctx, client)
+	require.NoError(s.t, err, "Failed to register snapshot repository")
+	defer res.Body.Close()
+
+	if res.IsError() {
+		hint := ""
+		if strings.Contains(res.String(), "path.repo") {
+			hint = fmt.Sprintf(" (the container was created without path.repo=%s: remove the reused container", ESSnapshotPath)
+			if os.Getenv("ES_SNAPSHOT_PATH") != "" || externalES() {
+				hint = " (ES_SNAPSHOT_PATH must be listed in path.repo of the external cluster"
+			}
+			hint += ")"
+		}
+		require.Fail(s.t, fmt.Sprintf("Failed to register snapshot repository at %s: %s%s", location, res.String(), hint))
+	}
+
+	registeredSnapshotRepos.Store(client, struct{}{})
+}
@@ -0,0 +1,103 @@
+package testhelper
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/stretchr/testify/require"
+)
+
+// soapFault é a resposta devolvida quando nenhum endpoint mockado corresponde à requisição
+const soapFault = `<?xml version="1.0" encoding="UTF-8"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body>
+    <soap:Fault>
+      <faultcode>soap:Client</faultcode>
+      <faultstring>%s</faultstring>
+    </soap:Fault>
+  </soap:Body>
+</soap:Envelope>`
+
+// SOAPMock é um servidor HTTP que devolve respostas XML pré-definidas
+type SOAPMock struct {
+	server    *httptest.Server
+	endpoints map[string]string
+
+	mu       sync.Mutex
+	requests []SOAPRequest
+}
+
+// SOAPRequest registra uma requisição recebida pelo mock
+type SOAPRequest struct {
+	Path       string
+	SOAPAction string
+	Body       string
+}
+
+// MockSOAP inicia um servidor SOAP fake com respostas XML pré-definidas
+// As chaves do mapa são comparadas com o path da requisição e, em seguida, com o header SOAPAction
+// Os valores podem ser o XML da resposta ou o caminho de um arquivo .xml
+func (s *IntegrationTestSuite) MockSOAP(endpointMap map[string]string) *SOAPMock {
+	s.t.Helper()
+
+	endpoints := make(map[string]string, len(endpointMap))
+	for key, response := range endpointMap {
+		if strings.HasSuffix(response, ".xml") && !strings.HasPrefix(strings.TrimSpace(response), "<") {
+			content, err := os.ReadFile(response)
+			require.NoError(s.t, err, "Failed to read SOAP response file %s", response)
+			response = string(content)
+		}
+		endpoints[key] = response
+	}
+
+	mock := &SOAPMock{endpoints: endpoints}
+	mock.server = httptest.NewServer(http.HandlerFunc(mock.handle))
+	s.t.Cleanup(mock.server.Close)
+
+	return mock
+}
+
+// URL retorna a URL base do servidor mockado
+func (m *SOAPMock) URL() string {
+	return m.server.URL
+}
+
+// Requests retorna as requisições recebidas até o momento
+func (m *SOAPMock) Requests() []SOAPRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]SOAPRequest(nil), m.requests...)
+}
+
+// handle responde com o XML do endpoint correspondente ou com um SOAP Fault
+func (m *SOAPMock) handle(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	action := strings.Trim(r.Header.Get("SOAPAction"), `"`)
+
+	m.mu.Lock()
+	m.requests = append(m.requests, SOAPRequest{Path: r.URL.Path, SOAPAction: action, Body: string(body)})
+	m.mu.Unlock()
+
+	response, found := m.endpoints[r.URL.Path]
+	if !found && action != "" {
+		response, found = m.endpoints[action]
+	}
+
+	w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+	if !found {
+		w.WriteHeader(http.StatusInternalServerError)
+		// Path e SOAPAction vêm do cliente: escapados para manter o envelope XML válido
+		var fault strings.Builder
+		xml.EscapeText(&fault, []byte(fmt.Sprintf("no mocked response for %s (SOAPAction %q)", r.URL.Path, action)))
+		fmt.Fprintf(w, soapFault, fault.String())
+		return
+	}
+
+	io.WriteString(w, response)
+}
@@ -0,0 +1,144 @@
+package testhelper
+
+import (
+	"sync"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// SuiteAdapter liga a IntegrationTestSuite ao ciclo de vida de frameworks de teste (testify/suite, Ginkgo)
+// As dependências sobem uma vez por suite (SetupSuite/BeforeSuite) e cada teste recebe uma
+// IntegrationTestSuite própria, com tenant novo, sobre as mesmas conexões
+//
+// Com Ginkgo, passe o GinkgoT() de cada nó:
+//
+//	var env = testhelper.NewSuiteAdapter(func(b *testhelper.TestDependenciesBuilder) { b.WithElasticsearch() })
+//	var suite *testhelper.IntegrationTestSuite
+//
+//	var _ = BeforeSuite(func() { env.SetupSuite(GinkgoT()) })
+//	var _ = AfterSuite(func() { env.TearDownSuite() })
+//	var _ = BeforeEach(func() { suite = env.SetupTest(GinkgoT()) })
+//	var _ = AfterEach(func() { env.TearDownTest() })
+type SuiteAdapter struct {
+	configure func(*TestDependenciesBuilder)
+
+	mu      sync.Mutex
+	deps    *TestDependenciesBuilder
+	current *IntegrationTestSuite
+}
+
+// NewSuiteAdapter cria o adapter; configure escolhe as dependências no builder (WithElasticsearch()...)
+func NewSuiteAdapter(configure func(*TestDependenciesBuilder)) *SuiteAdapter {
+	return &SuiteAdapter{configure: configure}
+}
+
+// SetupSuite sobe as dependências da suite; falhas de inicialização são reportadas em t
+func (a *SuiteAdapter) SetupSuite(t TestingT) {
+	t.Helper()
+
+	builder := NewTestDependenciesBuilder()
+	if a.configure != nil {
+		a.configure(builder)
+	}
+
+	deps, err := builder.Build()
+	require.NoError(t, err, "Failed to build test dependencies")
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.deps = deps
+}
+
+// TearDownSuite libera as dependências (containers compartilhados seguem a política de reuso)
+func (a *SuiteAdapter) TearDownSuite() error {
+	a.mu.Lock()
+	deps := a.deps
+	a.deps = nil
+	a.mu.Unlock()
+
+	if deps == nil {
+		return nil
+	}
+	return deps.Cleanup()
+}
+
+// SetupTest cria a suite do teste atual, com tenant próprio; cleanups registrados pelos helpers rodam
+// ao final do teste de t
+func (a *SuiteAdapter) SetupTest(t TestingT) *IntegrationTestSuite {
+	t.Helper()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	require.NotNil(t, a.deps, "SetupSuite was not called before SetupTest")
+	a.current = NewIntegrationTestSuiteWithBuilder(t, a.deps)
+	return a.current
+}
+
+// TearDownTest aguarda as operações em andamento da suite do teste atual
+func (a *SuiteAdapter) TearDownTest() {
+	a.mu.Lock()
+	current := a.current
+	a.current = nil
+	a.mu.Unlock()
+
+	if current != nil {
+		current.Teardown()
+	}
+}
+
+// Suite retorna a suite do teste atual (nil fora de um teste)
+func (a *SuiteAdapter) Suite() *IntegrationTestSuite {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.current
+}
+
+// TestifySuite integra a IntegrationTestSuite ao testify/suite: embuta-a na suite do teste e use
+// Integration() nos métodos de teste. Suites que definem os próprios SetupSuite/SetupTest/TearDown*
+// devem chamar os desta struct
+//
+//	type ProductSuite struct{ testhelper.TestifySuite }
+//
+//	func TestProductSuite(t *testing.T) {
+//		suite.Run(t, &ProductSuite{testhelper.NewTestifySuite(func(b *testhelper.TestDependenciesBuilder) {
+//			b.WithElasticsearch()
+//		})})
+//	}
+type TestifySuite struct {
+	suite.Suite
+	adapter *SuiteAdapter
+}
+
+// NewTestifySuite cria a base testify com as dependências escolhidas em configure
+func NewTestifySuite(configure func(*TestDependenciesBuilder)) TestifySuite {
+	return TestifySuite{adapter: NewSuiteAdapter(configure)}
+}
+
+// SetupSuite implementa suite.SetupAllSuite
+func (s *TestifySuite) SetupSuite() {
+	s.adapter.SetupSuite(s.T())
+}
+
+// TearDownSuite implementa suite.TearDownAllSuite
+func (s *TestifySuite) TearDownSuite() {
+	if err := s.adapter.TearDownSuite(); err != nil {
+		s.T().Logf("cleanup failed: %v", err)
+	}
+}
+
+// SetupTest implementa suite.SetupTestSuite
+func (s *TestifySuite) SetupTest() {
+	s.adapter.SetupTest(s.T())
+}
+
+// TearDownTest implementa suite.TearDownTestSuite
+func (s *TestifySuite) TearDownTest() {
+	s.adapter.TearDownTest()
+}
+
+// Integration retorna a IntegrationTestSuite do teste atual
+func (s *TestifySuite) Integration() *IntegrationTestSuite {
+	return s.adapter.Suite()
+}
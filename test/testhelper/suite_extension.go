@@ -0,0 +1,85 @@
+package testhelper
+
+import (
+	"context"
+	"testing"
+)
+
+// Suite é implementado pela IntegrationTestSuite e por qualquer struct que a embuta
+// Permite que helpers recebam suites de domínio (ex.: CatalogTestSuite) sem conhecer o tipo concreto
+type Suite interface {
+	Base() *IntegrationTestSuite
+}
+
+// Base retorna a própria suite; suites de domínio que embutem *IntegrationTestSuite herdam este método
+func (s *IntegrationTestSuite) Base() *IntegrationTestSuite {
+	return s
+}
+
+// TestingT é o subconjunto de *testing.T usado pela suite, para aceitar também o GinkgoT() do Ginkgo
+type TestingT interface {
+	Helper()
+	Cleanup(func())
+	Name() string
+	Logf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+	FailNow()
+	Failed() bool
+	Setenv(key, value string)
+}
+
+// T retorna o *testing.T associado à suite (nil quando a suite roda com outro TestingT, ex.: Ginkgo)
+func (s *IntegrationTestSuite) T() *testing.T {
+	t, _ := s.t.(*testing.T)
+	return t
+}
+
+// TB retorna o TestingT associado à suite, qualquer que seja o framework
+func (s *IntegrationTestSuite) TB() TestingT {
+	return s.t
+}
+
+// Context retorna o contexto usado pelos helpers da suite
+func (s *IntegrationTestSuite) Context() context.Context {
+	return s.ctx
+}
+
+// TenantID retorna o tenant ID que isola os dados da suite
+func (s *IntegrationTestSuite) TenantID() string {
+	return s.tenantID
+}
+
+// Dependencies retorna o builder de dependências (nil quando a suite foi criada sem builder)
+func (s *IntegrationTestSuite) Dependencies() *TestDependenciesBuilder {
+	return s.builder
+}
+
+// RegisterCleanup registra uma função executada ao final do teste atual da suite
+// Suites de domínio usam este hook para desfazer os recursos que criam; com CaptureState, a captura
+// roda antes
+func (s *IntegrationTestSuite) RegisterCleanup(fn func()) {
+	s.onCleanup(fn)
+}
+
+// ForTest retorna uma cópia da suite associada a outro *testing.T (ex.: dentro de t.Run)
+// As dependências e o tenant são compartilhados com a suite original
+func (s *IntegrationTestSuite) ForTest(t *testing.T) *IntegrationTestSuite {
+	clone := *s
+	clone.t = t
+	return &clone
+}
+
+// WithTenant retorna uma cópia da suite com escopo em outro tenant
+// Útil para subtests que precisam de isolamento sem recriar a suite
+func (s *IntegrationTestSuite) WithTenant(tenantID string) *IntegrationTestSuite {
+	clone := *s
+	clone.tenantID = tenantID
+	return &clone
+}
+
+// WithContext retorna uma cópia da suite que usa o contexto informado nos helpers
+func (s *IntegrationTestSuite) WithContext(ctx context.Context) *IntegrationTestSuite {
+	clone := *s
+	clone.ctx = ctx
+	return &clone
+}
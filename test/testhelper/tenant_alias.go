@@ -0,0 +1,145 @@
+package testhelper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"github.com/stretchr/testify/require"
+)
+
+// AliasDefinition é a definição de um alias: filtro e roteamento opcionais
+type AliasDefinition struct {
+	Filter        map[string]interface{} `json:"filter,omitempty"`
+	IndexRouting  string                 `json:"index_routing,omitempty"`
+	SearchRouting string                 `json:"search_routing,omitempty"`
+	IsWriteIndex  bool                   `json:"is_write_index,omitempty"`
+}
+
+// TenantAliasFilter é o filtro do alias de um tenant: term no campo tenant_id
+func TenantAliasFilter(tenantID string) map[string]interface{} {
+	return map[string]interface{}{"term": map[string]interface{}{"tenant_id": tenantID}}
+}
+
+// CreateFilteredAlias cria o alias no índice com a definição informada e retorna o nome real do alias
+// O alias é removido ao final do teste (o índice permanece)
+func (s *IntegrationTestSuite) CreateFilteredAlias(index, alias string, def AliasDefinition) string {
+	s.t.Helper()
+	defer s.trackOperation("CreateFilteredAlias")()
+
+	client := s.ES()
+	require.NotNil(s.t, client, "Elasticsearch not configured")
+
+	index = s.RunScopedIndex(index)
+	alias = s.RunScopedIndex(alias)
+
+	add := map[string]interface{}{"index": index, "alias": alias}
+	if def.Filter != nil {
+		add["filter"] = def.Filter
+	}
+	if def.IndexRouting != "" {
+		add["index_routing"] = def.IndexRouting
+	}
+	if def.SearchRouting != "" {
+		add["search_routing"] = def.SearchRouting
+	}
+	if def.IsWriteIndex {
+		add["is_write_index"] = true
+	}
+	s.updateAliases(map[string]interface{}{"add": add})
+
+	s.t.Cleanup(func() {
+		res, err := client.Indices.DeleteAlias([]string{index}, []string{alias})
+		if err == nil {
+			res.Body.Close()
+		}
+	})
+
+	return alias
+}
+
+// TenantAlias cria no índice compartilhado o alias filtrado do tenant da suite ("<índice>-<tenant>",
+// mesma convenção de TenantIndexName) com filtro term em tenant_id e roteamento pelo tenant
+// Buscas pelo alias (SearchDocuments(alias, ...)) só enxergam os documentos do tenant
+func (s *IntegrationTestSuite) TenantAlias(index string) string {
+	s.t.Helper()
+
+	return s.TenantAliasFor(index, s.tenantID)
+}
+
+// TenantAliasFor cria o alias filtrado de outro tenant (ex.: testes de isolamento entre tenants)
+func (s *IntegrationTestSuite) TenantAliasFor(index, tenantID string) string {
+	s.t.Helper()
+
+	return s.CreateFilteredAlias(index, TenantIndexName(index, tenantID), AliasDefinition{
+		Filter:        TenantAliasFilter(tenantID),
+		IndexRouting:  tenantID,
+		SearchRouting: tenantID,
+	})
+}
+
+// GetAliasDefinition retorna a definição do alias no índice, como armazenada pelo Elasticsearch
+func (s *IntegrationTestSuite) GetAliasDefinition(index, alias string) AliasDefinition {
+	s.t.Helper()
+
+	client := s.ES()
+	require.NotNil(s.t, client, "Elasticsearch not configured")
+
+	index = s.RunScopedIndex(index)
+	alias = s.RunScopedIndex(alias)
+
+	res, err := esapi.IndicesGetAliasRequest{Index: []string{index}, Name: []string{alias}}.Do(s.ctx, client)
+	require.NoError(s.t, err, "Failed to get alias %s", alias)
+	defer res.Body.Close()
+
+	if res.IsError() {
+		require.Fail(s.t, fmt.Sprintf("Failed to get alias %s on %s: %s", alias, index, res.Status()))
+	}
+
+	var body map[string]struct {
+		Aliases map[string]AliasDefinition `json:"aliases"`
+	}
+	require.NoError(s.t, json.NewDecoder(res.Body).Decode(&body), "Failed to decode alias response")
+
+	def, ok := body[index].Aliases[alias]
+	require.True(s.t, ok, "Alias %s not found on %s", alias, index)
+	return def
+}
+
+// AssertAliasFilter verifica que o filtro do alias é equivalente (JSON) ao esperado
+func (s *IntegrationTestSuite) AssertAliasFilter(index, alias string, expected map[string]interface{}) {
+	s.t.Helper()
+
+	def := s.GetAliasDefinition(index, alias)
+
+	expectedJSON, err := json.Marshal(expected)
+	require.NoError(s.t, err, "Failed to marshal expected filter")
+	actualJSON, err := json.Marshal(def.Filter)
+	require.NoError(s.t, err, "Failed to marshal alias filter")
+
+	require.JSONEq(s.t, string(expectedJSON), string(actualJSON), "Unexpected filter on alias %s", alias)
+}
+
+// AssertTenantAlias verifica que o alias filtra pelo tenant informado (term em tenant_id)
+func (s *IntegrationTestSuite) AssertTenantAlias(index, alias, tenantID string) {
+	s.t.Helper()
+
+	s.AssertAliasFilter(index, alias, TenantAliasFilter(tenantID))
+}
+
+// updateAliases envia as ações ao endpoint _aliases
+func (s *IntegrationTestSuite) updateAliases(actions ...map[string]interface{}) {
+	s.t.Helper()
+
+	body, err := json.Marshal(map[string]interface{}{"actions": actions})
+	require.NoError(s.t, err, "Failed to marshal alias actions")
+
+	res, err := esapi.IndicesUpdateAliasesRequest{Body: bytes.NewReader(body)}.Do(s.ctx, s.ES())
+	require.NoError(s.t, err, "Failed to update aliases")
+	defer res.Body.Close()
+
+	if res.IsError() {
+		require.Fail(s.t, fmt.Sprintf("Failed to update aliases: %s", res.String()))
+	}
+}
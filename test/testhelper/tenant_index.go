@@ -0,0 +1,179 @@
+package testhelper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"github.com/stretchr/testify/require"
+)
+
+// Estratégias de multi-tenancy suportadas pelos helpers
+//
+//   - filtro: todos os tenants no mesmo índice, isolados pelo campo tenant_id (padrão)
+//   - índice por tenant: cada tenant tem o próprio índice "<base>-<tenant>", criado sob demanda
+//     a partir de um template registrado com RegisterTenantIndexTemplate
+//   - alias filtrado: índice compartilhado acessado pelo alias "<base>-<tenant>" com filtro term em
+//     tenant_id (TenantAlias)
+//   - prefixo por teste: cada teste usa índices próprios "t_<id>_<base>" (WithIndexPrefixIsolation),
+//     sem filtro por tenant_id
+
+// indexNameInvalid casa caracteres não permitidos em nomes de índices
+var indexNameInvalid = regexp.MustCompile(`[\\/*?"<>| ,#:]+`)
+
+var (
+	tenantTemplatesMu sync.RWMutex
+	tenantTemplates   = make(map[string]map[string]interface{})
+
+	// appliedTenantTemplates guarda os templates já enviados a cada cluster (client -> base -> corpo)
+	appliedTenantTemplates sync.Map
+)
+
+// TenantIndexName deriva o nome do índice de um tenant: "<base>-<tenant>" em minúsculas
+// Deve seguir a mesma convenção usada pela aplicação (ex.: TenantIndexProductRepository)
+func TenantIndexName(base, tenantID string) string {
+	return strings.ToLower(base + "-" + indexNameInvalid.ReplaceAllString(tenantID, "_"))
+}
+
+// RegisterTenantIndexTemplate registra o template (settings/mappings) dos índices por tenant da base
+// O template é aplicado como index template "<base>-*" no cluster antes da primeira criação
+func RegisterTenantIndexTemplate(base string, template map[string]interface{}) {
+	tenantTemplatesMu.Lock()
+	defer tenantTemplatesMu.Unlock()
+	tenantTemplates[base] = template
+}
+
+// tenantIndexTemplate retorna o template registrado para a base
+func tenantIndexTemplate(base string) (map[string]interface{}, bool) {
+	tenantTemplatesMu.RLock()
+	defer tenantTemplatesMu.RUnlock()
+	template, ok := tenantTemplates[base]
+	return template, ok
+}
+
+// TenantIndex retorna o índice da base para o tenant da suite, criando-o se necessário
+// O índice é removido ao final do teste
+func (s *IntegrationTestSuite) TenantIndex(base string) string {
+	s.t.Helper()
+	defer s.trackOperation("TenantIndex")()
+
+	return s.ensureTenantIndex(base, s.tenantID)
+}
+
+// TenantIndexFor retorna o índice da base para outro tenant (ex.: testes de isolamento entre tenants)
+func (s *IntegrationTestSuite) TenantIndexFor(base, tenantID string) string {
+	s.t.Helper()
+	defer s.trackOperation("TenantIndex")()
+
+	return s.ensureTenantIndex(base, tenantID)
+}
+
+// ensureTenantIndex aplica o template da base e cria o índice do tenant
+func (s *IntegrationTestSuite) ensureTenantIndex(base, tenantID string) string {
+	s.t.Helper()
+
+	client := s.ES()
+	require.NotNil(s.t, client, "Elasticsearch not configured")
+
+	err := ensureTenantTemplate(s.ctx, client, base)
+	require.NoError(s.t, err, "Failed to apply tenant index template for %s", base)
+
+	indexName := s.RunScopedIndex(TenantIndexName(base, tenantID))
+
+	res, err := client.Indices.Create(indexName, client.Indices.Create.WithContext(s.ctx))
+	require.NoError(s.t, err, "Failed to create tenant index %s", indexName)
+	defer res.Body.Close()
+
+	// Já existir não é erro: outro helper (ou a aplicação) pode ter criado o índice
+	if res.IsError() && !strings.Contains(res.String(), "resource_already_exists_exception") {
+		require.Fail(s.t, fmt.Sprintf("Failed to create tenant index %s: %s", indexName, res.Status()))
+	}
+
+	s.onCleanup(func() {
+		res, err := client.Indices.Delete([]string{indexName},
+			client.Indices.Delete.WithIgnoreUnavailable(true))
+		if err == nil {
+			res.Body.Close()
+		}
+	})
+
+	return indexName
+}
+
+// CleanTenantIndices remove todos os índices por tenant do tenant da suite
+// Os índices são listados e removidos pelo nome: o ES 8 recusa DELETE com wildcard
+func (s *IntegrationTestSuite) CleanTenantIndices() {
+	s.t.Helper()
+
+	client := s.ES()
+	require.NotNil(s.t, client, "Elasticsearch not configured")
+
+	pattern := s.RunScopedIndex(TenantIndexName("*", s.tenantID))
+	_, err := deleteIndicesMatching(s.ctx, client, pattern)
+	require.NoError(s.t, err, "Failed to delete tenant indices %s", pattern)
+}
+
+// CleanTenant remove os documentos do tenant (vazio = tenant da suite) em todos os índices com um
+// _delete_by_query em tenant_id.keyword, sem apagar índices: testes paralelos que compartilham os
+// índices limpam só os próprios dados. Chamadas simultâneas de várias suites são agrupadas pelo
+// CleanupScheduler em uma única requisição
+func (s *IntegrationTestSuite) CleanTenant(tenantID string) {
+	s.t.Helper()
+	defer s.trackOperation("CleanTenant")()
+
+	client := s.ES()
+	require.NotNil(s.t, client, "Elasticsearch not configured")
+
+	if tenantID == "" {
+		tenantID = s.tenantID
+	}
+
+	err := GetCleanupScheduler().DeleteTenantDocuments(s.ctx, client, tenantID)
+	require.NoError(s.t, err, "Failed to clean documents of tenant %s", tenantID)
+}
+
+// ensureTenantTemplate envia o index template da base se ainda não foi aplicado neste cluster
+func ensureTenantTemplate(ctx context.Context, client *elasticsearch.Client, base string) error {
+	template, ok := tenantIndexTemplate(base)
+	if !ok {
+		return nil
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"index_patterns": []string{strings.ToLower(base) + "-*"},
+		"priority":       100,
+		"template":       template,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal index template: %w", err)
+	}
+
+	applied, _ := appliedTenantTemplates.LoadOrStore(client, &sync.Map{})
+	if previous, found := applied.(*sync.Map).Load(base); found && bytes.Equal(previous.([]byte), body) {
+		return nil
+	}
+
+	req := esapi.IndicesPutIndexTemplateRequest{
+		Name: strings.ToLower(base) + "-tenant-template",
+		Body: bytes.NewReader(body),
+	}
+
+	res, err := req.Do(ctx, client)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("elasticsearch error: %s", res.String())
+	}
+
+	applied.(*sync.Map).Store(base, body)
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"io/fs"
 	"log"
 	"sync"
 	"time"
@@ -20,159 +21,1487 @@ type TestDependenciesBuilder struct {
 	MongoConn    *mongo.Database
 	MongoConnDW  *mongo.Database
 	ESConn       *elasticsearch.Client
+	CassandraConn *SharedCassandra
+	ClickHouseConn *sql.DB
+	DynamoDBEndpoint string
+	MemcachedAddr  string
+	Neo4jConn      *SharedNeo4j
+	TemporalConn   *SharedTemporal
+	VaultAddr      string
+	WireMockURL    string
+	SMTPAddr       string
+	MailHogAPIURL  string
+	AzuriteConnString string
+	GCSEndpoint    string
+	PubSubEmulatorHost string
+	PubSubProjectID    string
+	ElasticMQEndpoint  string
+	ElasticMQQueueURLs map[string]string
+	ConsulAddr         string
+	InfluxConfig       InfluxConfig
+	SQLServerConn      *sql.DB
+	SQLServerURL       string
+	CouchbaseConfig    CouchbaseConfig
+	SolrURL            string
+	SolrCore           string
+	MeilisearchURL     string
+	MeilisearchKey     string
+	TypesenseURL       string
+	TypesenseAPIKey    string
+	KibanaURL          string
+	LogstashTCPAddr    string
+	LogstashBeatsAddr  string
+	APMServerURL       string
+	PrometheusURL      string
+	JaegerQueryURL     string
+	JaegerOTLPGRPCAddr string
+	JaegerOTLPHTTPURL  string
+	OTelGRPCEndpoint   string
+	OTelHTTPEndpoint   string
+	KafkaConnectURL    string
+	KafkaBootstrapServers string
+	SchemaRegistryURL     string
+	MQTTBrokerAddr     string
+	ArtemisConfig      ArtemisConfig
 	
 	// Funções de limpeza individuais
 	ESClearFunc    func()
 	MongoClearFunc func(ctx context.Context) error
 	PostgresClearFunc func(ctx context.Context) error
+	ClickHouseClearFunc func(ctx context.Context) error
+	DynamoDBClearFunc func(ctx context.Context) error
+	MemcachedClearFunc func(ctx context.Context) error
+	VaultClearFunc func(ctx context.Context) error
+	WireMockClearFunc func(ctx context.Context) error
+	MailHogClearFunc func(ctx context.Context) error
+	AzuriteClearFunc func(ctx context.Context) error
+	GCSClearFunc func(ctx context.Context) error
+	PubSubClearFunc func(ctx context.Context) error
+	ElasticMQPurgeFunc func(ctx context.Context) error
+	ConsulClearFunc func(ctx context.Context) error
+	InfluxClearFunc func(ctx context.Context) error
+	SQLServerClearFunc func(ctx context.Context) error
+	CouchbaseClearFunc func(ctx context.Context) error
+	SolrClearFunc      func(ctx context.Context) error
+	MeilisearchClearFunc func(ctx context.Context) error
+	TypesenseClearFunc func(ctx context.Context) error
+	KibanaClearFunc    func(ctx context.Context) error
+	APMClearFunc       func(ctx context.Context) error
+	PrometheusClearFunc func(ctx context.Context) error
+	ArtemisPurgeFunc   func(ctx context.Context) error
 	
 	// Referências para os shared containers
 	sharedES    *SharedElasticsearch
 	sharedMongo *SharedMongoDB
 	sharedPG    *SharedPostgreSQL
+	sharedCassandra *SharedCassandra
+	sharedClickHouse *SharedClickHouse
+	sharedDynamo *SharedDynamoDB
+	sharedMemcached *SharedMemcached
+	sharedNeo4j *SharedNeo4j
+	sharedTemporal *SharedTemporal
+	sharedVault *SharedVault
+	sharedWireMock *SharedWireMock
+	sharedMailHog *SharedMailHog
+	sharedAzurite *SharedAzurite
+	sharedGCS *SharedGCS
+	sharedPubSub *SharedPubSub
+	sharedElasticMQ *SharedElasticMQ
+	sharedConsul *SharedConsul
+	sharedInfluxDB *SharedInfluxDB
+	sharedSQLServer *SharedSQLServer
+	sharedCouchbase *SharedCouchbase
+	sharedSolr      *SharedSolr
+	sharedMeilisearch *SharedMeilisearch
+	sharedTypesense *SharedTypesense
+	sharedKibana    *SharedKibana
+	sharedLogstash  *SharedLogstash
+	sharedAPMServer *SharedAPMServer
+	sharedPrometheus *SharedPrometheus
+	sharedJaeger    *SharedJaeger
+	sharedOTelCollector *SharedOTelCollector
+	sharedDebezium  *SharedDebezium
+	sharedKafka          *SharedKafka
+	sharedSchemaRegistry *SharedSchemaRegistry
+	sharedMQTT      *SharedMQTT
+	sharedArtemis   *SharedArtemis
 	
 	// Configuração
 	needsPostgres     bool
+	postgresImage     string
 	needsMongo        bool
+	mongoReplicaSet   bool
 	needsElasticsearch bool
+	elasticsearchSecurity bool
 	sqlFilePaths      []string
+	needsCassandra    bool
+	cqlFilePaths      []string
+	needsClickHouse   bool
+	clickHouseSQLPaths []string
+	needsDynamoDB     bool
+	needsMemcached    bool
+	needsNeo4j        bool
+	cypherFilePaths   []string
+	needsTemporal     bool
+	needsVault        bool
+	needsWireMock     bool
+	wireMockMappingsDir string
+	needsMailHog      bool
+	needsAzurite      bool
+	needsGCS          bool
+	needsPubSub       bool
+	needsElasticMQ    bool
+	elasticMQQueues   []string
+	needsConsul       bool
+	needsInfluxDB     bool
+	needsSQLServer    bool
+	sqlServerPaths    []string
+	needsCouchbase    bool
+	needsSolr         bool
+	solrCore          string
+	solrSchema        string
+	needsMeilisearch  bool
+	needsTypesense    bool
+	retryStaleState   bool
+	needsKibana       bool
+	logstashPipeline  string
+	needsAPMServer    bool
+	quietMode         bool
+	quietLogKB        int
+	needsPrometheus   bool
+	fixturesFS        fs.FS
+	prometheusTargets []string
+	prometheusConfigs []PrometheusScrapeConfig
+	needsJaeger       bool
+	needsOTelCollector bool
+	otelCollectorConfig string
+	externalFallback  ExternalFallbackPolicy
+	needsDebezium     bool
+	needsKafka          bool
+	needsSchemaRegistry bool
+	needsMQTT         bool
+	needsArtemis      bool
 	
 	// Controle interno
-	cleanupFuncs []func()
-	built        bool
-	mu           sync.RWMutex
+	cleanupTasks   []cleanupTask
+	cleanupResults []CleanupResult
+	cleanupMu      sync.Mutex
+	built          bool
+	mu             sync.RWMutex
 }
 
 // NewTestDependenciesBuilder cria uma nova instância do builder
 func NewTestDependenciesBuilder() *TestDependenciesBuilder {
-	return &TestDependenciesBuilder{
-		cleanupFuncs: make([]func(), 0),
+	return &TestDependenciesBuilder{}
+}
+
+// WithPostgres configura o builder para usar PostgreSQL com arquivos SQL opcionais
+func (b *TestDependenciesBuilder) WithPostgres(sqlFilePaths ...string) *TestDependenciesBuilder {
+	b.needsPostgres = true
+	b.sqlFilePaths = sqlFilePaths
+	return b
+}
+
+// WithPostgresImage define a imagem do PostgreSQL (ex.: "timescale/timescaledb:latest-pg15")
+// Imagens TimescaleDB têm a extensão timescaledb criada antes dos arquivos SQL do WithPostgres
+func (b *TestDependenciesBuilder) WithPostgresImage(image string) *TestDependenciesBuilder {
+	b.postgresImage = image
+	return b
+}
+
+// WithMongo configura o builder para usar MongoDB
+func (b *TestDependenciesBuilder) WithMongo() *TestDependenciesBuilder {
+	b.needsMongo = true
+	return b
+}
+
+// WithMongoReplicaSet configura o MongoDB como replica set de um membro (--replSet + rs.initiate()),
+// necessário para transações multi-documento e change streams; também ativado por MONGO_REPLICA_SET=true
+func (b *TestDependenciesBuilder) WithMongoReplicaSet() *TestDependenciesBuilder {
+	b.needsMongo = true
+	b.mongoReplicaSet = true
+	return b
+}
+
+// WithElasticsearch configura o builder para usar Elasticsearch
+func (b *TestDependenciesBuilder) WithElasticsearch() *TestDependenciesBuilder {
+	b.needsElasticsearch = true
+	return b
+}
+
+// WithElasticsearchSecurity configura o Elasticsearch com xpack.security habilitado: TLS na API HTTP com a CA
+// gerada pelo container e autenticação do usuário elastic, já configuradas no client; também ativado por
+// ES_SECURITY=true. Kibana, Logstash e APM Server continuam usando o Elasticsearch sem segurança
+func (b *TestDependenciesBuilder) WithElasticsearchSecurity() *TestDependenciesBuilder {
+	b.needsElasticsearch = true
+	b.elasticsearchSecurity = true
+	return b
+}
+
+// WithCassandra configura o builder para usar Cassandra com arquivos CQL opcionais
+// Os arquivos são executados em cada keyspace de tenant criado
+func (b *TestDependenciesBuilder) WithCassandra(cqlFilePaths ...string) *TestDependenciesBuilder {
+	b.needsCassandra = true
+	b.cqlFilePaths = cqlFilePaths
+	return b
+}
+
+// WithClickHouse configura o builder para usar ClickHouse com arquivos SQL opcionais
+func (b *TestDependenciesBuilder) WithClickHouse(sqlFilePaths ...string) *TestDependenciesBuilder {
+	b.needsClickHouse = true
+	b.clickHouseSQLPaths = sqlFilePaths
+	return b
+}
+
+// WithDynamoDB configura o builder para usar DynamoDB Local
+func (b *TestDependenciesBuilder) WithDynamoDB() *TestDependenciesBuilder {
+	b.needsDynamoDB = true
+	return b
+}
+
+// WithMemcached configura o builder para usar memcached
+func (b *TestDependenciesBuilder) WithMemcached() *TestDependenciesBuilder {
+	b.needsMemcached = true
+	return b
+}
+
+// WithNeo4j configura o builder para usar Neo4j com scripts Cypher opcionais
+func (b *TestDependenciesBuilder) WithNeo4j(cypherFilePaths ...string) *TestDependenciesBuilder {
+	b.needsNeo4j = true
+	b.cypherFilePaths = cypherFilePaths
+	return b
+}
+
+// WithTemporal configura o builder para usar o dev server do Temporal
+func (b *TestDependenciesBuilder) WithTemporal() *TestDependenciesBuilder {
+	b.needsTemporal = true
+	return b
+}
+
+// WithVault configura o builder para usar Vault em modo dev
+func (b *TestDependenciesBuilder) WithVault() *TestDependenciesBuilder {
+	b.needsVault = true
+	return b
+}
+
+// WithWireMock configura o builder para usar WireMock com um diretório de mappings opcional
+func (b *TestDependenciesBuilder) WithWireMock(mappingsDir string) *TestDependenciesBuilder {
+	b.needsWireMock = true
+	b.wireMockMappingsDir = mappingsDir
+	return b
+}
+
+// WithMailHog configura o builder para usar MailHog (captura de emails via SMTP)
+func (b *TestDependenciesBuilder) WithMailHog() *TestDependenciesBuilder {
+	b.needsMailHog = true
+	return b
+}
+
+// WithAzurite configura o builder para usar Azurite (Azure Blob Storage)
+func (b *TestDependenciesBuilder) WithAzurite() *TestDependenciesBuilder {
+	b.needsAzurite = true
+	return b
+}
+
+// WithGCS configura o builder para usar o fake-gcs-server (Google Cloud Storage)
+func (b *TestDependenciesBuilder) WithGCS() *TestDependenciesBuilder {
+	b.needsGCS = true
+	return b
+}
+
+// WithPubSubEmulator configura o builder para usar o emulador do Google Pub/Sub
+func (b *TestDependenciesBuilder) WithPubSubEmulator() *TestDependenciesBuilder {
+	b.needsPubSub = true
+	return b
+}
+
+// WithElasticMQ configura o builder para usar o ElasticMQ (SQS) criando as filas informadas
+// Nomes terminados em ".fifo" criam filas FIFO
+func (b *TestDependenciesBuilder) WithElasticMQ(queueNames ...string) *TestDependenciesBuilder {
+	b.needsElasticMQ = true
+	b.elasticMQQueues = append(b.elasticMQQueues, queueNames...)
+	return b
+}
+
+// WithConsul configura o builder para usar o Consul em modo dev
+func (b *TestDependenciesBuilder) WithConsul() *TestDependenciesBuilder {
+	b.needsConsul = true
+	return b
+}
+
+// WithInfluxDB configura o builder para usar o InfluxDB 2.x (org, bucket e token de teste)
+func (b *TestDependenciesBuilder) WithInfluxDB() *TestDependenciesBuilder {
+	b.needsInfluxDB = true
+	return b
+}
+
+// WithSQLServer configura o builder para usar SQL Server com arquivos SQL opcionais
+// O *sql.DB só é aberto se o teste registrar um driver (ex.: _ "github.com/microsoft/go-mssqldb")
+func (b *TestDependenciesBuilder) WithSQLServer(sqlFilePaths ...string) *TestDependenciesBuilder {
+	b.needsSQLServer = true
+	b.sqlServerPaths = sqlFilePaths
+	return b
+}
+
+// WithCouchbase configura o builder para usar Couchbase (um bucket por tenant)
+func (b *TestDependenciesBuilder) WithCouchbase() *TestDependenciesBuilder {
+	b.needsCouchbase = true
+	return b
+}
+
+// WithSolr configura o builder para usar Solr com o core informado
+// schemaPath (opcional) é aplicado ao criar o core: .json com comandos da Schema API ou .xml (managed-schema)
+func (b *TestDependenciesBuilder) WithSolr(coreName string, schemaPath ...string) *TestDependenciesBuilder {
+	b.needsSolr = true
+	b.solrCore = coreName
+	if len(schemaPath) > 0 {
+		b.solrSchema = schemaPath[0]
+	}
+	return b
+}
+
+// WithMeilisearch configura o builder para usar Meilisearch (um índice por tenant)
+func (b *TestDependenciesBuilder) WithMeilisearch() *TestDependenciesBuilder {
+	b.needsMeilisearch = true
+	return b
+}
+
+// WithTypesense configura o builder para usar Typesense (collections por teste)
+func (b *TestDependenciesBuilder) WithTypesense() *TestDependenciesBuilder {
+	b.needsTypesense = true
+	return b
+}
+
+// WithStaleStateRetry liga a política de retry único quando uma chamada ao Elasticsearch falha porque o
+// shared container foi recriado (conexão recusada): o container é reconectado e a chamada repetida
+// Também pode ser ligada para todas as suites com TEST_RETRY_STALE_STATE=true
+func (b *TestDependenciesBuilder) WithStaleStateRetry() *TestDependenciesBuilder {
+	b.retryStaleState = true
+	return b
+}
+
+// sharedESClient cria o client da suite sobre o Elasticsearch compartilhado, com a política de retry
+// quando ligada
+func (b *TestDependenciesBuilder) sharedESClient() *elasticsearch.Client {
+	if b.retryStaleState || staleStateRetryFromEnv() {
+		if client, err := newStaleRetryESClient(b.sharedES); err == nil {
+			return client
+		}
+	}
+	return b.sharedES.GetClient()
+}
+
+// WithQuietMode suprime os banners e limita a saída de log a maxLogKB KB por teste (padrão
+// TEST_QUIET_LOG_KB ou DefaultQuietLogBudgetKB). Vale para o processo inteiro; também pode ser ligado
+// com TEST_QUIET=true
+func (b *TestDependenciesBuilder) WithQuietMode(maxLogKB ...int) *TestDependenciesBuilder {
+	b.quietMode = true
+	if len(maxLogKB) > 0 {
+		b.quietLogKB = maxLogKB[0]
+	}
+	return b
+}
+
+// WithFixturesFS faz os loaders de fixtures (arquivos SQL do WithPostgres, LoadFixtures e
+// LoadCapturedState) lerem os caminhos de fsys, por exemplo um embed.FS com o testdata
+func (b *TestDependenciesBuilder) WithFixturesFS(fsys fs.FS) *TestDependenciesBuilder {
+	b.fixturesFS = fsys
+	return b
+}
+
+// WithExternalFallback define o que fazer quando uma dependência com USE_EXTERNAL_* ligado está
+// inacessível: falhar, pular o teste ou iniciar um container. TEST_EXTERNAL_FALLBACK e
+// TEST_EXTERNAL_FALLBACK_<DEP> têm precedência. Vale para o processo inteiro
+func (b *TestDependenciesBuilder) WithExternalFallback(policy ExternalFallbackPolicy) *TestDependenciesBuilder {
+	b.externalFallback = policy
+	return b
+}
+
+// WithKibana configura o builder para usar Kibana ligado ao Elasticsearch compartilhado
+// Implica WithElasticsearch
+func (b *TestDependenciesBuilder) WithKibana() *TestDependenciesBuilder {
+	b.needsElasticsearch = true
+	b.needsKibana = true
+	return b
+}
+
+// WithLogstash configura o builder para usar Logstash com o pipeline informado, ligado ao Elasticsearch
+// compartilhado (disponível no pipeline como ${ELASTICSEARCH_HOSTS}). Implica WithElasticsearch
+func (b *TestDependenciesBuilder) WithLogstash(pipelineConfPath string) *TestDependenciesBuilder {
+	b.needsElasticsearch = true
+	b.logstashPipeline = pipelineConfPath
+	return b
+}
+
+// WithAPMServer configura o builder para usar o apm-server gravando no Elasticsearch compartilhado
+// Implica WithElasticsearch
+func (b *TestDependenciesBuilder) WithAPMServer() *TestDependenciesBuilder {
+	b.needsElasticsearch = true
+	b.needsAPMServer = true
+	return b
+}
+
+// WithPrometheus configura o builder para usar Prometheus fazendo scrape dos alvos informados
+// ("host:porta" ou URL com o caminho das métricas); alvos em localhost são acessados pelo container via
+// host.testcontainers.internal
+func (b *TestDependenciesBuilder) WithPrometheus(scrapeTargets ...string) *TestDependenciesBuilder {
+	b.needsPrometheus = true
+	b.prometheusTargets = scrapeTargets
+	return b
+}
+
+// WithPrometheusScrapeConfigs configura o Prometheus com jobs de scrape completos (caminho, intervalo,
+// rótulos, vários alvos por job) em vez dos alvos simples de WithPrometheus. Jobs com Network fazem o
+// Prometheus entrar na rede docker da aplicação em container, acessada pelo alias ("app:8080")
+func (b *TestDependenciesBuilder) WithPrometheusScrapeConfigs(configs ...PrometheusScrapeConfig) *TestDependenciesBuilder {
+	b.needsPrometheus = true
+	b.prometheusConfigs = append(b.prometheusConfigs, configs...)
+	return b
+}
+
+// WithJaeger configura o builder para usar Jaeger all-in-one (OTLP gRPC/HTTP e API de consulta)
+func (b *TestDependenciesBuilder) WithJaeger() *TestDependenciesBuilder {
+	b.needsJaeger = true
+	return b
+}
+
+// WithOTelCollector configura o builder para usar um OpenTelemetry Collector com a configuração
+// informada (vazia usa a padrão: OTLP gRPC/HTTP exportando para o file exporter). Configurações próprias
+// devem exportar para OTelCollectorTracesFile/OTelCollectorMetricsFile para os helpers de leitura
+func (b *TestDependenciesBuilder) WithOTelCollector(configPath string) *TestDependenciesBuilder {
+	b.needsOTelCollector = true
+	b.otelCollectorConfig = configPath
+	return b
+}
+
+// WithDebezium configura o builder para usar Kafka Connect com o conector Debezium Postgres capturando
+// o PostgreSQL compartilhado (que passa a rodar com wal_level=logical). Implica WithPostgres e WithKafka
+func (b *TestDependenciesBuilder) WithDebezium() *TestDependenciesBuilder {
+	b.needsPostgres = true
+	b.needsKafka = true
+	b.needsDebezium = true
+	return b
+}
+
+// WithKafka configura o builder para usar o broker Kafka compartilhado (KRaft, nó único)
+func (b *TestDependenciesBuilder) WithKafka() *TestDependenciesBuilder {
+	b.needsKafka = true
+	return b
+}
+
+// WithSchemaRegistry configura o builder para usar um Confluent Schema Registry ligado ao Kafka
+// compartilhado. Implica WithKafka
+func (b *TestDependenciesBuilder) WithSchemaRegistry() *TestDependenciesBuilder {
+	b.needsKafka = true
+	b.needsSchemaRegistry = true
+	return b
+}
+
+// WithMQTT configura o builder para usar um broker MQTT (eclipse-mosquitto com acesso anônimo)
+func (b *TestDependenciesBuilder) WithMQTT() *TestDependenciesBuilder {
+	b.needsMQTT = true
+	return b
+}
+
+// WithArtemis configura o builder para usar ActiveMQ Artemis (AMQP, STOMP e core)
+func (b *TestDependenciesBuilder) WithArtemis() *TestDependenciesBuilder {
+	b.needsArtemis = true
+	return b
+}
+
+// Build cria e inicializa as dependências configuradas em paralelo
+func (b *TestDependenciesBuilder) Build() (*TestDependenciesBuilder, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	if b.built {
+		return b, nil // Já foi construído
+	}
+	
+	if b.quietMode || quietModeFromEnv() {
+		enableQuietMode(b.quietLogKB)
+	}
+	
+	if b.externalFallback != "" {
+		policy, err := parseExternalFallbackPolicy(string(b.externalFallback))
+		if err != nil {
+			return nil, err
+		}
+		setExternalFallbackPolicy(policy)
+	}
+	
+	if isDebugEnabled() {
+		log.Println("🚀 Building test dependencies...")
+	}
+	start := time.Now()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var errors []error
+	
+	ctx := context.Background()
+	
+	// Setup PostgreSQL se necessário
+	if b.needsPostgres {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if isDebugEnabled() {
+				log.Println("📦 Initializing PostgreSQL...")
+			}
+			
+			b.sharedPG = GetSharedPostgreSQLImage(b.postgresImage)
+			err := b.sharedPG.StartFS(ctx, b.fixturesFS, b.sqlFilePaths...)
+			
+			mu.Lock()
+			if err != nil {
+				errors = append(errors, fmt.Errorf("postgres setup failed: %w", err))
+			} else {
+				b.PostgresConn = b.sharedPG.GetConnection()
+				b.PostgresClearFunc = b.sharedPG.CleanDatabase
+				b.AddCleanup("stop postgres", b.sharedPG.Stop)
+				if isDebugEnabled() {
+					log.Println("✅ PostgreSQL initialized successfully")
+				}
+			}
+			mu.Unlock()
+		}()
+	}
+	
+	// Setup MongoDB se necessário
+	if b.needsMongo {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if isDebugEnabled() {
+				log.Println("📦 Initializing MongoDB...")
+			}
+			
+			b.sharedMongo = sharedMongoFor(b.mongoReplicaSet)
+			err := b.sharedMongo.Start(ctx)
+			
+			mu.Lock()
+			if err != nil {
+				errors = append(errors, fmt.Errorf("mongo setup failed: %w", err))
+			} else {
+				b.MongoConn = b.sharedMongo.GetDatabase()
+				b.MongoConnDW = b.sharedMongo.GetDatabaseDW()
+				b.MongoClearFunc = b.sharedMongo.CleanDatabase
+				b.AddCleanup("stop mongo", b.sharedMongo.Stop)
+				if isDebugEnabled() {
+					log.Println("✅ MongoDB initialized successfully")
+				}
+			}
+			mu.Unlock()
+		}()
+	}
+	
+	// Setup Elasticsearch se necessário
+	if b.needsElasticsearch {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if isDebugEnabled() {
+				log.Println("📦 Initializing Elasticsearch...")
+			}
+			
+			b.sharedES = sharedElasticsearchFor(b.elasticsearchSecurity)
+			err := b.sharedES.Start(ctx)
+			
+			mu.Lock()
+			if err != nil {
+				errors = append(errors, fmt.Errorf("elasticsearch setup failed: %w", err))
+			} else {
+				b.ESConn = b.sharedESClient()
+				b.ESClearFunc = func() {
+					b.sharedES.CleanIndices(ctx)
+				}
+				b.AddCleanup("stop elasticsearch", b.sharedES.Stop)
+				if isDebugEnabled() {
+					log.Println("✅ Elasticsearch initialized successfully")
+				}
+			}
+			mu.Unlock()
+		}()
+	}
+	
+	// Setup Cassandra se necessário
+	if b.needsCassandra {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if isDebugEnabled() {
+				log.Println("📦 Initializing Cassandra...")
+			}
+			
+			b.sharedCassandra = GetSharedCassandra()
+			err := b.sharedCassandra.Start(ctx, b.cqlFilePaths...)
+			
+			mu.Lock()
+			if err != nil {
+				errors = append(errors, fmt.Errorf("cassandra setup failed: %w", err))
+			} else {
+				b.CassandraConn = b.sharedCassandra
+				b.AddCleanup("stop cassandra", b.sharedCassandra.Stop)
+				if isDebugEnabled() {
+					log.Println("✅ Cassandra initialized successfully")
+				}
+			}
+			mu.Unlock()
+		}()
+	}
+	
+	// Setup ClickHouse se necessário
+	if b.needsClickHouse {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if isDebugEnabled() {
+				log.Println("📦 Initializing ClickHouse...")
+			}
+			
+			b.sharedClickHouse = GetSharedClickHouse()
+			err := b.sharedClickHouse.Start(ctx, b.clickHouseSQLPaths...)
+			
+			mu.Lock()
+			if err != nil {
+				errors = append(errors, fmt.Errorf("clickhouse setup failed: %w", err))
+			} else {
+				b.ClickHouseConn = b.sharedClickHouse.GetConnection()
+				b.ClickHouseClearFunc = b.sharedClickHouse.CleanDatabase
+				b.AddCleanup("stop clickhouse", b.sharedClickHouse.Stop)
+				if isDebugEnabled() {
+					log.Println("✅ ClickHouse initialized successfully")
+				}
+			}
+			mu.Unlock()
+		}()
+	}
+	
+	// Setup DynamoDB se necessário
+	if b.needsDynamoDB {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if isDebugEnabled() {
+				log.Println("📦 Initializing DynamoDB Local...")
+			}
+			
+			b.sharedDynamo = GetSharedDynamoDB()
+			err := b.sharedDynamo.Start(ctx)
+			
+			mu.Lock()
+			if err != nil {
+				errors = append(errors, fmt.Errorf("dynamodb setup failed: %w", err))
+			} else {
+				b.DynamoDBEndpoint = b.sharedDynamo.GetEndpoint()
+				b.DynamoDBClearFunc = b.sharedDynamo.CleanTables
+				b.AddCleanup("stop dynamodb", b.sharedDynamo.Stop)
+				if isDebugEnabled() {
+					log.Println("✅ DynamoDB Local initialized successfully")
+				}
+			}
+			mu.Unlock()
+		}()
+	}
+	
+	// Setup memcached se necessário
+	if b.needsMemcached {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if isDebugEnabled() {
+				log.Println("📦 Initializing memcached...")
+			}
+			
+			b.sharedMemcached = GetSharedMemcached()
+			err := b.sharedMemcached.Start(ctx)
+			
+			mu.Lock()
+			if err != nil {
+				errors = append(errors, fmt.Errorf("memcached setup failed: %w", err))
+			} else {
+				b.MemcachedAddr = b.sharedMemcached.GetAddress()
+				b.MemcachedClearFunc = b.sharedMemcached.FlushAll
+				b.AddCleanup("stop memcached", b.sharedMemcached.Stop)
+				if isDebugEnabled() {
+					log.Println("✅ memcached initialized successfully")
+				}
+			}
+			mu.Unlock()
+		}()
+	}
+	
+	// Setup Neo4j se necessário
+	if b.needsNeo4j {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if isDebugEnabled() {
+				log.Println("📦 Initializing Neo4j...")
+			}
+			
+			b.sharedNeo4j = GetSharedNeo4j()
+			err := b.sharedNeo4j.Start(ctx, b.cypherFilePaths...)
+			
+			mu.Lock()
+			if err != nil {
+				errors = append(errors, fmt.Errorf("neo4j setup failed: %w", err))
+			} else {
+				b.Neo4jConn = b.sharedNeo4j
+				b.AddCleanup("stop neo4j", b.sharedNeo4j.Stop)
+				if isDebugEnabled() {
+					log.Println("✅ Neo4j initialized successfully")
+				}
+			}
+			mu.Unlock()
+		}()
+	}
+	
+	// Setup Temporal se necessário
+	if b.needsTemporal {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if isDebugEnabled() {
+				log.Println("📦 Initializing Temporal...")
+			}
+			
+			b.sharedTemporal = GetSharedTemporal()
+			err := b.sharedTemporal.Start(ctx)
+			
+			mu.Lock()
+			if err != nil {
+				errors = append(errors, fmt.Errorf("temporal setup failed: %w", err))
+			} else {
+				b.TemporalConn = b.sharedTemporal
+				b.AddCleanup("stop temporal", b.sharedTemporal.Stop)
+				if isDebugEnabled() {
+					log.Println("✅ Temporal initialized successfully")
+				}
+			}
+			mu.Unlock()
+		}()
+	}
+	
+	// Setup Vault se necessário
+	if b.needsVault {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if isDebugEnabled() {
+				log.Println("📦 Initializing Vault...")
+			}
+			
+			b.sharedVault = GetSharedVault()
+			err := b.sharedVault.Start(ctx)
+			
+			mu.Lock()
+			if err != nil {
+				errors = append(errors, fmt.Errorf("vault setup failed: %w", err))
+			} else {
+				b.VaultAddr = b.sharedVault.GetAddress()
+				b.VaultClearFunc = b.sharedVault.WipeKV
+				b.AddCleanup("stop vault", b.sharedVault.Stop)
+				if isDebugEnabled() {
+					log.Println("✅ Vault initialized successfully")
+				}
+			}
+			mu.Unlock()
+		}()
+	}
+	
+	// Setup WireMock se necessário
+	if b.needsWireMock {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if isDebugEnabled() {
+				log.Println("📦 Initializing WireMock...")
+			}
+			
+			b.sharedWireMock = GetSharedWireMock()
+			err := b.sharedWireMock.Start(ctx, b.wireMockMappingsDir)
+			
+			mu.Lock()
+			if err != nil {
+				errors = append(errors, fmt.Errorf("wiremock setup failed: %w", err))
+			} else {
+				b.WireMockURL = b.sharedWireMock.GetURL()
+				b.WireMockClearFunc = b.sharedWireMock.Reset
+				b.AddCleanup("stop wiremock", b.sharedWireMock.Stop)
+				if isDebugEnabled() {
+					log.Println("✅ WireMock initialized successfully")
+				}
+			}
+			mu.Unlock()
+		}()
+	}
+	
+	// Setup MailHog se necessário
+	if b.needsMailHog {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if isDebugEnabled() {
+				log.Println("📦 Initializing MailHog...")
+			}
+			
+			b.sharedMailHog = GetSharedMailHog()
+			err := b.sharedMailHog.Start(ctx)
+			
+			mu.Lock()
+			if err != nil {
+				errors = append(errors, fmt.Errorf("mailhog setup failed: %w", err))
+			} else {
+				b.SMTPAddr = b.sharedMailHog.GetSMTPAddr()
+				b.MailHogAPIURL = b.sharedMailHog.GetAPIURL()
+				b.MailHogClearFunc = b.sharedMailHog.DeleteAll
+				b.AddCleanup("stop mailhog", b.sharedMailHog.Stop)
+				if isDebugEnabled() {
+					log.Println("✅ MailHog initialized successfully")
+				}
+			}
+			mu.Unlock()
+		}()
+	}
+	
+	// Setup Azurite se necessário
+	if b.needsAzurite {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if isDebugEnabled() {
+				log.Println("📦 Initializing Azurite...")
+			}
+			
+			b.sharedAzurite = GetSharedAzurite()
+			err := b.sharedAzurite.Start(ctx)
+			
+			mu.Lock()
+			if err != nil {
+				errors = append(errors, fmt.Errorf("azurite setup failed: %w", err))
+			} else {
+				b.AzuriteConnString = b.sharedAzurite.GetConnectionString()
+				b.AzuriteClearFunc = b.sharedAzurite.DeleteAllContainers
+				b.AddCleanup("stop azurite", b.sharedAzurite.Stop)
+				if isDebugEnabled() {
+					log.Println("✅ Azurite initialized successfully")
+				}
+			}
+			mu.Unlock()
+		}()
+	}
+	
+	// Setup fake GCS se necessário
+	if b.needsGCS {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if isDebugEnabled() {
+				log.Println("📦 Initializing fake GCS...")
+			}
+			
+			b.sharedGCS = GetSharedGCS()
+			err := b.sharedGCS.Start(ctx)
+			
+			mu.Lock()
+			if err != nil {
+				errors = append(errors, fmt.Errorf("gcs setup failed: %w", err))
+			} else {
+				b.GCSEndpoint = b.sharedGCS.GetEndpoint()
+				b.GCSClearFunc = b.sharedGCS.DeleteAllBuckets
+				b.AddCleanup("stop gcs", b.sharedGCS.Stop)
+				if isDebugEnabled() {
+					log.Println("✅ Fake GCS initialized successfully")
+				}
+			}
+			mu.Unlock()
+		}()
+	}
+	
+	// Setup Pub/Sub emulator se necessário
+	if b.needsPubSub {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if isDebugEnabled() {
+				log.Println("📨 Initializing Pub/Sub emulator...")
+			}
+			
+			b.sharedPubSub = GetSharedPubSub()
+			err := b.sharedPubSub.Start(ctx)
+			
+			mu.Lock()
+			if err != nil {
+				errors = append(errors, fmt.Errorf("pubsub setup failed: %w", err))
+			} else {
+				b.PubSubEmulatorHost = b.sharedPubSub.GetEmulatorHost()
+				b.PubSubProjectID = b.sharedPubSub.GetProjectID()
+				b.PubSubClearFunc = b.sharedPubSub.DeleteAll
+				b.AddCleanup("stop pubsub", b.sharedPubSub.Stop)
+				if isDebugEnabled() {
+					log.Println("✅ Pub/Sub emulator initialized successfully")
+				}
+			}
+			mu.Unlock()
+		}()
+	}
+	
+	// Setup ElasticMQ se necessário
+	if b.needsElasticMQ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if isDebugEnabled() {
+				log.Println("📬 Initializing ElasticMQ...")
+			}
+			
+			b.sharedElasticMQ = GetSharedElasticMQ()
+			err := b.sharedElasticMQ.Start(ctx)
+			
+			var queueURLs map[string]string
+			if err == nil {
+				queueURLs, err = b.sharedElasticMQ.CreateQueues(ctx, b.elasticMQQueues...)
+				if err != nil {
+					b.sharedElasticMQ.Stop(ctx)
+				}
+			}
+			
+			mu.Lock()
+			if err != nil {
+				errors = append(errors, fmt.Errorf("elasticmq setup failed: %w", err))
+			} else {
+				b.ElasticMQEndpoint = b.sharedElasticMQ.GetEndpoint()
+				b.ElasticMQQueueURLs = queueURLs
+				b.ElasticMQPurgeFunc = b.sharedElasticMQ.PurgeAll
+				b.AddCleanup("stop elasticmq", b.sharedElasticMQ.Stop)
+				if isDebugEnabled() {
+					log.Println("✅ ElasticMQ initialized successfully")
+				}
+			}
+			mu.Unlock()
+		}()
+	}
+	
+	// Setup Consul se necessário
+	if b.needsConsul {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if isDebugEnabled() {
+				log.Println("🧭 Initializing Consul...")
+			}
+			
+			b.sharedConsul = GetSharedConsul()
+			err := b.sharedConsul.Start(ctx)
+			
+			mu.Lock()
+			if err != nil {
+				errors = append(errors, fmt.Errorf("consul setup failed: %w", err))
+			} else {
+				b.ConsulAddr = b.sharedConsul.GetAddress()
+				b.ConsulClearFunc = b.sharedConsul.Reset
+				b.AddCleanup("stop consul", b.sharedConsul.Stop)
+				if isDebugEnabled() {
+					log.Println("✅ Consul initialized successfully")
+				}
+			}
+			mu.Unlock()
+		}()
+	}
+	
+	// Setup InfluxDB se necessário
+	if b.needsInfluxDB {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if isDebugEnabled() {
+				log.Println("📈 Initializing InfluxDB...")
+			}
+			
+			b.sharedInfluxDB = GetSharedInfluxDB()
+			err := b.sharedInfluxDB.Start(ctx)
+			
+			mu.Lock()
+			if err != nil {
+				errors = append(errors, fmt.Errorf("influxdb setup failed: %w", err))
+			} else {
+				b.InfluxConfig = b.sharedInfluxDB.Config()
+				b.InfluxClearFunc = b.sharedInfluxDB.DeleteAll
+				b.AddCleanup("stop influxdb", b.sharedInfluxDB.Stop)
+				if isDebugEnabled() {
+					log.Println("✅ InfluxDB initialized successfully")
+				}
+			}
+			mu.Unlock()
+		}()
+	}
+	
+	// Setup SQL Server se necessário
+	if b.needsSQLServer {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if isDebugEnabled() {
+				log.Println("🗄️ Initializing SQL Server...")
+			}
+			
+			b.sharedSQLServer = GetSharedSQLServer()
+			err := b.sharedSQLServer.Start(ctx, b.sqlServerPaths...)
+			
+			mu.Lock()
+			if err != nil {
+				errors = append(errors, fmt.Errorf("sql server setup failed: %w", err))
+			} else {
+				b.SQLServerConn = b.sharedSQLServer.GetConnection()
+				b.SQLServerURL = b.sharedSQLServer.GetURL()
+				b.SQLServerClearFunc = b.sharedSQLServer.CleanDatabase
+				b.AddCleanup("stop sql server", b.sharedSQLServer.Stop)
+				if isDebugEnabled() {
+					log.Println("✅ SQL Server initialized successfully")
+				}
+			}
+			mu.Unlock()
+		}()
+	}
+	
+	// Setup Couchbase se necessário
+	if b.needsCouchbase {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if isDebugEnabled() {
+				log.Println("🛋️ Initializing Couchbase...")
+			}
+			
+			b.sharedCouchbase = GetSharedCouchbase()
+			err := b.sharedCouchbase.Start(ctx)
+			
+			mu.Lock()
+			if err != nil {
+				errors = append(errors, fmt.Errorf("couchbase setup failed: %w", err))
+			} else {
+				b.CouchbaseConfig = b.sharedCouchbase.Config()
+				b.CouchbaseClearFunc = b.sharedCouchbase.DeleteAll
+				b.AddCleanup("stop couchbase", b.sharedCouchbase.Stop)
+				if isDebugEnabled() {
+					log.Println("✅ Couchbase initialized successfully")
+				}
+			}
+			mu.Unlock()
+		}()
+	}
+	
+	// Setup Solr se necessário
+	if b.needsSolr {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if isDebugEnabled() {
+				log.Println("☀️ Initializing Solr...")
+			}
+			
+			b.sharedSolr = GetSharedSolr()
+			err := b.sharedSolr.Start(ctx)
+			if err == nil {
+				err = b.sharedSolr.EnsureCore(ctx, b.solrCore, b.solrSchema)
+			}
+			
+			mu.Lock()
+			if err != nil {
+				errors = append(errors, fmt.Errorf("solr setup failed: %w", err))
+			} else {
+				b.SolrURL = b.sharedSolr.CoreURL(b.solrCore)
+				b.SolrCore = b.solrCore
+				b.SolrClearFunc = func(ctx context.Context) error {
+					return b.sharedSolr.DeleteByQuery(ctx, b.solrCore, "*:*")
+				}
+				b.AddCleanup("stop solr", b.sharedSolr.Stop)
+				if isDebugEnabled() {
+					log.Println("✅ Solr initialized successfully")
+				}
+			}
+			mu.Unlock()
+		}()
+	}
+	
+	// Setup Meilisearch se necessário
+	if b.needsMeilisearch {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if isDebugEnabled() {
+				log.Println("🔍 Initializing Meilisearch...")
+			}
+			
+			b.sharedMeilisearch = GetSharedMeilisearch()
+			err := b.sharedMeilisearch.Start(ctx)
+			
+			mu.Lock()
+			if err != nil {
+				errors = append(errors, fmt.Errorf("meilisearch setup failed: %w", err))
+			} else {
+				b.MeilisearchURL = b.sharedMeilisearch.GetURL()
+				b.MeilisearchKey = b.sharedMeilisearch.MasterKey()
+				b.MeilisearchClearFunc = b.sharedMeilisearch.DeleteAll
+				b.AddCleanup("stop meilisearch", b.sharedMeilisearch.Stop)
+				if isDebugEnabled() {
+					log.Println("✅ Meilisearch initialized successfully")
+				}
+			}
+			mu.Unlock()
+		}()
+	}
+	
+	// Setup Typesense se necessário
+	if b.needsTypesense {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if isDebugEnabled() {
+				log.Println("⚡ Initializing Typesense...")
+			}
+			
+			b.sharedTypesense = GetSharedTypesense()
+			err := b.sharedTypesense.Start(ctx)
+			
+			mu.Lock()
+			if err != nil {
+				errors = append(errors, fmt.Errorf("typesense setup failed: %w", err))
+			} else {
+				b.TypesenseURL = b.sharedTypesense.GetURL()
+				b.TypesenseAPIKey = b.sharedTypesense.APIKey()
+				b.TypesenseClearFunc = b.sharedTypesense.DeleteAll
+				b.AddCleanup("stop typesense", b.sharedTypesense.Stop)
+				if isDebugEnabled() {
+					log.Println("✅ Typesense initialized successfully")
+				}
+			}
+			mu.Unlock()
+		}()
+	}
+	
+	// Setup Kibana se necessário (inicia o Elasticsearch compartilhado antes, se ainda não estiver no ar)
+	if b.needsKibana {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if isDebugEnabled() {
+				log.Println("📊 Initializing Kibana...")
+			}
+			
+			es := GetSharedElasticsearch()
+			err := es.Start(ctx)
+			if err == nil {
+				b.sharedKibana = GetSharedKibana()
+				if err = b.sharedKibana.Start(ctx, es); err != nil {
+					es.Stop(ctx)
+				}
+			}
+			
+			mu.Lock()
+			if err != nil {
+				errors = append(errors, fmt.Errorf("kibana setup failed: %w", err))
+			} else {
+				b.KibanaURL = b.sharedKibana.GetURL()
+				b.KibanaClearFunc = b.sharedKibana.DeleteSavedObjects
+				b.AddCleanup("stop kibana", b.sharedKibana.Stop)
+				b.AddCleanup("release elasticsearch (kibana)", es.Stop)
+				if isDebugEnabled() {
+					log.Println("✅ Kibana initialized successfully")
+				}
+			}
+			mu.Unlock()
+		}()
 	}
-}
-
-// WithPostgres configura o builder para usar PostgreSQL com arquivos SQL opcionais
-func (b *TestDependenciesBuilder) WithPostgres(sqlFilePaths ...string) *TestDependenciesBuilder {
-	b.needsPostgres = true
-	b.sqlFilePaths = sqlFilePaths
-	return b
-}
-
-// WithMongo configura o builder para usar MongoDB
-func (b *TestDependenciesBuilder) WithMongo() *TestDependenciesBuilder {
-	b.needsMongo = true
-	return b
-}
-
-// WithElasticsearch configura o builder para usar Elasticsearch
-func (b *TestDependenciesBuilder) WithElasticsearch() *TestDependenciesBuilder {
-	b.needsElasticsearch = true
-	return b
-}
-
-// Build cria e inicializa as dependências configuradas em paralelo
-func (b *TestDependenciesBuilder) Build() (*TestDependenciesBuilder, error) {
-	b.mu.Lock()
-	defer b.mu.Unlock()
 	
-	if b.built {
-		return b, nil // Já foi construído
+	// Setup Logstash se necessário (inicia o Elasticsearch compartilhado antes, se ainda não estiver no ar)
+	if b.logstashPipeline != "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if isDebugEnabled() {
+				log.Println("🪵 Initializing Logstash...")
+			}
+			
+			es := GetSharedElasticsearch()
+			err := es.Start(ctx)
+			if err == nil {
+				b.sharedLogstash = GetSharedLogstash(b.logstashPipeline)
+				if err = b.sharedLogstash.Start(ctx, es); err != nil {
+					es.Stop(ctx)
+				}
+			}
+			
+			mu.Lock()
+			if err != nil {
+				errors = append(errors, fmt.Errorf("logstash setup failed: %w", err))
+			} else {
+				b.LogstashTCPAddr = b.sharedLogstash.TCPAddr()
+				b.LogstashBeatsAddr = b.sharedLogstash.BeatsAddr()
+				b.AddCleanup("stop logstash", b.sharedLogstash.Stop)
+				b.AddCleanup("release elasticsearch (logstash)", es.Stop)
+				if isDebugEnabled() {
+					log.Println("✅ Logstash initialized successfully")
+				}
+			}
+			mu.Unlock()
+		}()
 	}
 	
-	if isDebugEnabled() {
-		log.Println("🚀 Building test dependencies...")
+	// Setup APM Server se necessário (inicia o Elasticsearch compartilhado antes, se ainda não estiver no ar)
+	if b.needsAPMServer {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if isDebugEnabled() {
+				log.Println("📡 Initializing APM Server...")
+			}
+			
+			es := GetSharedElasticsearch()
+			err := es.Start(ctx)
+			if err == nil {
+				b.sharedAPMServer = GetSharedAPMServer()
+				if err = b.sharedAPMServer.Start(ctx, es); err != nil {
+					es.Stop(ctx)
+				}
+			}
+			
+			mu.Lock()
+			if err != nil {
+				errors = append(errors, fmt.Errorf("apm server setup failed: %w", err))
+			} else {
+				b.APMServerURL = b.sharedAPMServer.IntakeURL()
+				b.APMClearFunc = func(ctx context.Context) error {
+					return deleteAPMData(ctx, es.GetClient())
+				}
+				b.AddCleanup("stop apm server", b.sharedAPMServer.Stop)
+				b.AddCleanup("release elasticsearch (apm server)", es.Stop)
+				if isDebugEnabled() {
+					log.Println("✅ APM Server initialized successfully")
+				}
+			}
+			mu.Unlock()
+		}()
 	}
-	start := time.Now()
-
-	var wg sync.WaitGroup
-	var mu sync.Mutex
-	var errors []error
 	
-	ctx := context.Background()
+	// Setup Prometheus se necessário
+	if b.needsPrometheus {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if isDebugEnabled() {
+				log.Println("📈 Initializing Prometheus...")
+			}
+			
+			if len(b.prometheusConfigs) > 0 {
+				b.sharedPrometheus = GetSharedPrometheusWithConfigs(b.prometheusConfigs...)
+			} else {
+				b.sharedPrometheus = GetSharedPrometheus(b.prometheusTargets...)
+			}
+			err := b.sharedPrometheus.Start(ctx)
+			
+			mu.Lock()
+			if err != nil {
+				errors = append(errors, fmt.Errorf("prometheus setup failed: %w", err))
+			} else {
+				b.PrometheusURL = b.sharedPrometheus.GetURL()
+				b.PrometheusClearFunc = b.sharedPrometheus.DeleteAll
+				b.AddCleanup("stop prometheus", b.sharedPrometheus.Stop)
+				if isDebugEnabled() {
+					log.Println("✅ Prometheus initialized successfully")
+				}
+			}
+			mu.Unlock()
+		}()
+	}
 	
-	// Setup PostgreSQL se necessário
-	if b.needsPostgres {
+	// Setup Jaeger se necessário
+	if b.needsJaeger {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
 			if isDebugEnabled() {
-				log.Println("📦 Initializing PostgreSQL...")
+				log.Println("🔭 Initializing Jaeger...")
 			}
 			
-			b.sharedPG = GetSharedPostgreSQL()
-			err := b.sharedPG.Start(ctx, b.sqlFilePaths...)
+			b.sharedJaeger = GetSharedJaeger()
+			err := b.sharedJaeger.Start(ctx)
 			
 			mu.Lock()
 			if err != nil {
-				errors = append(errors, fmt.Errorf("postgres setup failed: %w", err))
+				errors = append(errors, fmt.Errorf("jaeger setup failed: %w", err))
 			} else {
-				b.PostgresConn = b.sharedPG.GetConnection()
-				b.PostgresClearFunc = b.sharedPG.CleanDatabase
-				b.cleanupFuncs = append(b.cleanupFuncs, func() {
-					b.sharedPG.Stop(ctx)
-				})
+				b.JaegerQueryURL = b.sharedJaeger.QueryURL()
+				b.JaegerOTLPGRPCAddr = b.sharedJaeger.OTLPGRPCAddr()
+				b.JaegerOTLPHTTPURL = b.sharedJaeger.OTLPHTTPURL()
+				b.AddCleanup("stop jaeger", b.sharedJaeger.Stop)
 				if isDebugEnabled() {
-					log.Println("✅ PostgreSQL initialized successfully")
+					log.Println("✅ Jaeger initialized successfully")
 				}
 			}
 			mu.Unlock()
 		}()
 	}
 	
-	// Setup MongoDB se necessário
-	if b.needsMongo {
+	// Setup OpenTelemetry Collector se necessário
+	if b.needsOTelCollector {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
 			if isDebugEnabled() {
-				log.Println("📦 Initializing MongoDB...")
+				log.Println("🛰️ Initializing OpenTelemetry Collector...")
 			}
 			
-			b.sharedMongo = GetSharedMongoDB()
-			err := b.sharedMongo.Start(ctx)
+			b.sharedOTelCollector = GetSharedOTelCollector(b.otelCollectorConfig)
+			err := b.sharedOTelCollector.Start(ctx)
 			
 			mu.Lock()
 			if err != nil {
-				errors = append(errors, fmt.Errorf("mongo setup failed: %w", err))
+				errors = append(errors, fmt.Errorf("otel collector setup failed: %w", err))
 			} else {
-				b.MongoConn = b.sharedMongo.GetDatabase()
-				b.MongoConnDW = b.sharedMongo.GetDatabaseDW()
-				b.MongoClearFunc = b.sharedMongo.CleanDatabase
-				b.cleanupFuncs = append(b.cleanupFuncs, func() {
-					b.sharedMongo.Stop(ctx)
-				})
+				b.OTelGRPCEndpoint = b.sharedOTelCollector.GRPCEndpoint()
+				b.OTelHTTPEndpoint = b.sharedOTelCollector.HTTPEndpoint()
+				b.AddCleanup("stop otel collector", b.sharedOTelCollector.Stop)
 				if isDebugEnabled() {
-					log.Println("✅ MongoDB initialized successfully")
+					log.Println("✅ OpenTelemetry Collector initialized successfully")
 				}
 			}
 			mu.Unlock()
 		}()
 	}
 	
-	// Setup Elasticsearch se necessário
-	if b.needsElasticsearch {
+	// Setup Debezium se necessário (inicia o PostgreSQL compartilhado antes, se ainda não estiver no ar)
+	if b.needsDebezium {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
 			if isDebugEnabled() {
-				log.Println("📦 Initializing Elasticsearch...")
+				log.Println("🔁 Initializing Kafka Connect + Debezium...")
 			}
 			
-			b.sharedES = GetSharedElasticsearch()
-			err := b.sharedES.Start(ctx)
+			pg := GetSharedPostgreSQLImage(b.postgresImage)
+			kafka := GetSharedKafka()
+			err := pg.StartFS(ctx, b.fixturesFS, b.sqlFilePaths...)
+			if err == nil {
+				if err = kafka.Start(ctx); err != nil {
+					pg.Stop(ctx)
+				}
+			}
+			if err == nil {
+				b.sharedDebezium = GetSharedDebezium()
+				if err = b.sharedDebezium.Start(ctx, pg, kafka); err != nil {
+					kafka.Stop(ctx)
+					pg.Stop(ctx)
+				}
+			}
 			
 			mu.Lock()
 			if err != nil {
-				errors = append(errors, fmt.Errorf("elasticsearch setup failed: %w", err))
+				errors = append(errors, fmt.Errorf("debezium setup failed: %w", err))
 			} else {
-				b.ESConn = b.sharedES.GetClient()
-				b.ESClearFunc = func() {
-					b.sharedES.CleanIndices(ctx)
+				b.KafkaConnectURL = b.sharedDebezium.ConnectURL()
+				// Limpeza roda da última para a primeira: o Connect para antes do Kafka e do PostgreSQL
+				b.AddCleanup("release postgres (debezium)", pg.Stop)
+				b.AddCleanup("release kafka (debezium)", kafka.Stop)
+				b.AddCleanup("stop debezium", b.sharedDebezium.Stop)
+				if isDebugEnabled() {
+					log.Println("✅ Kafka Connect + Debezium initialized successfully")
+				}
+			}
+			mu.Unlock()
+		}()
+	}
+	
+	// Setup Kafka (e Schema Registry) se necessário
+	if b.needsKafka {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if isDebugEnabled() {
+				log.Println("📨 Initializing Kafka...")
+			}
+			
+			kafka := GetSharedKafka()
+			err := kafka.Start(ctx)
+			
+			var registry *SharedSchemaRegistry
+			if err == nil && b.needsSchemaRegistry {
+				registry = GetSharedSchemaRegistry()
+				if err = registry.Start(ctx, kafka); err != nil {
+					kafka.Stop(ctx)
+					err = fmt.Errorf("schema registry: %w", err)
+				}
+			}
+			
+			mu.Lock()
+			if err != nil {
+				errors = append(errors, fmt.Errorf("kafka setup failed: %w", err))
+			} else {
+				b.sharedKafka = kafka
+				b.KafkaBootstrapServers = kafka.BootstrapServers()
+				b.AddCleanup("stop kafka", kafka.Stop)
+				if registry != nil {
+					b.sharedSchemaRegistry = registry
+					b.SchemaRegistryURL = registry.URL()
+					b.AddCleanup("stop schema registry", registry.Stop)
 				}
-				b.cleanupFuncs = append(b.cleanupFuncs, func() {
-					b.sharedES.Stop(ctx)
-				})
 				if isDebugEnabled() {
-					log.Println("✅ Elasticsearch initialized successfully")
+					log.Println("✅ Kafka initialized successfully")
+				}
+			}
+			mu.Unlock()
+		}()
+	}
+	
+	// Setup MQTT se necessário
+	if b.needsMQTT {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if isDebugEnabled() {
+				log.Println("📡 Initializing MQTT...")
+			}
+			
+			b.sharedMQTT = GetSharedMQTT()
+			err := b.sharedMQTT.Start(ctx)
+			
+			mu.Lock()
+			if err != nil {
+				errors = append(errors, fmt.Errorf("mqtt setup failed: %w", err))
+			} else {
+				b.MQTTBrokerAddr = b.sharedMQTT.GetAddr()
+				b.AddCleanup("stop mqtt", b.sharedMQTT.Stop)
+				if isDebugEnabled() {
+					log.Println("✅ MQTT initialized successfully")
+				}
+			}
+			mu.Unlock()
+		}()
+	}
+	
+	// Setup Artemis se necessário
+	if b.needsArtemis {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if isDebugEnabled() {
+				log.Println("📦 Initializing ActiveMQ Artemis...")
+			}
+			
+			b.sharedArtemis = GetSharedArtemis()
+			err := b.sharedArtemis.Start(ctx)
+			
+			mu.Lock()
+			if err != nil {
+				errors = append(errors, fmt.Errorf("artemis setup failed: %w", err))
+			} else {
+				b.ArtemisConfig = b.sharedArtemis.Config()
+				b.ArtemisPurgeFunc = b.sharedArtemis.PurgeAll
+				b.AddCleanup("stop artemis", b.sharedArtemis.Stop)
+				if isDebugEnabled() {
+					log.Println("✅ ActiveMQ Artemis initialized successfully")
 				}
 			}
 			mu.Unlock()
@@ -183,8 +1512,14 @@ func (b *TestDependenciesBuilder) Build() (*TestDependenciesBuilder, error) {
 	wg.Wait()
 	
 	if len(errors) > 0 {
-		b.cleanup()
-		return nil, fmt.Errorf("initialization errors: %v", errors)
+		b.runCleanupTasks()
+		return nil, initializationErrors(errors)
+	}
+
+	// Em modo externo, os recursos desta execução são varridos quando o último usuário termina
+	if (b.needsElasticsearch && externalES()) || (b.needsMongo && externalMongo()) {
+		runScope.acquire()
+		b.AddCleanup("sweep run-scoped resources", runScope.release)
 	}
 
 	elapsed := time.Since(start)
@@ -200,40 +1535,129 @@ func (b *TestDependenciesBuilder) Build() (*TestDependenciesBuilder, error) {
 		MongoConn:         b.MongoConn,
 		MongoConnDW:       b.MongoConnDW,
 		ESConn:            b.ESConn,
+		CassandraConn:     b.CassandraConn,
 		ESClearFunc:       b.ESClearFunc,
 		MongoClearFunc:    b.MongoClearFunc,
 		PostgresClearFunc: b.PostgresClearFunc,
+		ClickHouseConn:    b.ClickHouseConn,
+		ClickHouseClearFunc: b.ClickHouseClearFunc,
+		DynamoDBEndpoint:  b.DynamoDBEndpoint,
+		DynamoDBClearFunc: b.DynamoDBClearFunc,
+		MemcachedAddr:     b.MemcachedAddr,
+		MemcachedClearFunc: b.MemcachedClearFunc,
+		Neo4jConn:         b.Neo4jConn,
+		TemporalConn:      b.TemporalConn,
+		VaultAddr:         b.VaultAddr,
+		VaultClearFunc:    b.VaultClearFunc,
+		WireMockURL:       b.WireMockURL,
+		WireMockClearFunc: b.WireMockClearFunc,
+		SMTPAddr:          b.SMTPAddr,
+		MailHogAPIURL:     b.MailHogAPIURL,
+		MailHogClearFunc:  b.MailHogClearFunc,
+		AzuriteConnString: b.AzuriteConnString,
+		AzuriteClearFunc:  b.AzuriteClearFunc,
+		GCSEndpoint:       b.GCSEndpoint,
+		GCSClearFunc:      b.GCSClearFunc,
+		PubSubEmulatorHost: b.PubSubEmulatorHost,
+		PubSubProjectID:    b.PubSubProjectID,
+		PubSubClearFunc:    b.PubSubClearFunc,
+		ElasticMQEndpoint:  b.ElasticMQEndpoint,
+		ElasticMQQueueURLs: b.ElasticMQQueueURLs,
+		ElasticMQPurgeFunc: b.ElasticMQPurgeFunc,
+		ConsulAddr:         b.ConsulAddr,
+		ConsulClearFunc:    b.ConsulClearFunc,
+		InfluxConfig:       b.InfluxConfig,
+		InfluxClearFunc:    b.InfluxClearFunc,
+		SQLServerConn:      b.SQLServerConn,
+		SQLServerURL:       b.SQLServerURL,
+		SQLServerClearFunc: b.SQLServerClearFunc,
+		CouchbaseConfig:    b.CouchbaseConfig,
+		CouchbaseClearFunc: b.CouchbaseClearFunc,
+		SolrURL:            b.SolrURL,
+		SolrCore:           b.SolrCore,
+		SolrClearFunc:      b.SolrClearFunc,
+		MeilisearchURL:     b.MeilisearchURL,
+		MeilisearchKey:     b.MeilisearchKey,
+		MeilisearchClearFunc: b.MeilisearchClearFunc,
+		TypesenseURL:       b.TypesenseURL,
+		TypesenseAPIKey:    b.TypesenseAPIKey,
+		TypesenseClearFunc: b.TypesenseClearFunc,
+		KibanaURL:          b.KibanaURL,
+		KibanaClearFunc:    b.KibanaClearFunc,
+		LogstashTCPAddr:    b.LogstashTCPAddr,
+		LogstashBeatsAddr:  b.LogstashBeatsAddr,
+		APMServerURL:       b.APMServerURL,
+		APMClearFunc:       b.APMClearFunc,
+		PrometheusURL:      b.PrometheusURL,
+		PrometheusClearFunc: b.PrometheusClearFunc,
+		JaegerQueryURL:     b.JaegerQueryURL,
+		JaegerOTLPGRPCAddr: b.JaegerOTLPGRPCAddr,
+		JaegerOTLPHTTPURL:  b.JaegerOTLPHTTPURL,
+		OTelGRPCEndpoint:   b.OTelGRPCEndpoint,
+		OTelHTTPEndpoint:   b.OTelHTTPEndpoint,
+		KafkaConnectURL:    b.KafkaConnectURL,
+		KafkaBootstrapServers: b.KafkaBootstrapServers,
+		SchemaRegistryURL:     b.SchemaRegistryURL,
+		MQTTBrokerAddr:     b.MQTTBrokerAddr,
+		ArtemisConfig:      b.ArtemisConfig,
+		ArtemisPurgeFunc:   b.ArtemisPurgeFunc,
 		
 		// Mantém referências para limpeza
 		sharedES:     b.sharedES,
 		sharedMongo:  b.sharedMongo,
 		sharedPG:     b.sharedPG,
-		cleanupFuncs: b.cleanupFuncs,
+		sharedCassandra: b.sharedCassandra,
+		sharedClickHouse: b.sharedClickHouse,
+		sharedDynamo: b.sharedDynamo,
+		sharedMemcached: b.sharedMemcached,
+		sharedNeo4j: b.sharedNeo4j,
+		sharedTemporal: b.sharedTemporal,
+		sharedVault: b.sharedVault,
+		sharedWireMock: b.sharedWireMock,
+		sharedMailHog: b.sharedMailHog,
+		sharedAzurite: b.sharedAzurite,
+		sharedGCS: b.sharedGCS,
+		sharedPubSub: b.sharedPubSub,
+		sharedElasticMQ: b.sharedElasticMQ,
+		sharedConsul: b.sharedConsul,
+		sharedInfluxDB: b.sharedInfluxDB,
+		sharedSQLServer: b.sharedSQLServer,
+		sharedCouchbase: b.sharedCouchbase,
+		sharedSolr: b.sharedSolr,
+		sharedMeilisearch: b.sharedMeilisearch,
+		sharedTypesense: b.sharedTypesense,
+		sharedKibana: b.sharedKibana,
+		sharedLogstash: b.sharedLogstash,
+		sharedAPMServer: b.sharedAPMServer,
+		sharedPrometheus: b.sharedPrometheus,
+		sharedJaeger: b.sharedJaeger,
+		sharedOTelCollector: b.sharedOTelCollector,
+		sharedDebezium: b.sharedDebezium,
+		sharedKafka:          b.sharedKafka,
+		sharedSchemaRegistry: b.sharedSchemaRegistry,
+		sharedMQTT: b.sharedMQTT,
+		sharedArtemis: b.sharedArtemis,
+		fixturesFS: b.fixturesFS,
+		retryStaleState: b.retryStaleState,
+		cleanupTasks: b.cleanupTasks,
 		built:        true,
 	}, nil
 }
 
-// cleanup executa todas as funções de limpeza registradas
-func (b *TestDependenciesBuilder) cleanup() {
-	for i := len(b.cleanupFuncs) - 1; i >= 0; i-- {
-		if b.cleanupFuncs[i] != nil {
-			b.cleanupFuncs[i]()
-		}
-	}
-}
-
-// Cleanup limpa todos os recursos
-func (b *TestDependenciesBuilder) Cleanup() {
+// Cleanup executa as tarefas de limpeza registradas (da última para a primeira)
+// Todas as tarefas rodam; as falhas são logadas e retornadas como *CleanupError
+func (b *TestDependenciesBuilder) Cleanup() error {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 	
 	if isDebugEnabled() {
 		log.Println("🧹 Cleaning up test dependencies...")
 	}
-	b.cleanup()
-	if isDebugEnabled() {
+	err := b.runCleanupTasks()
+	if isDebugEnabled() && err == nil {
 		log.Println("✅ Cleanup completed")
 	}
+	return err
 }
 
 // ResetElasticsearch limpa todos os índices do Elasticsearch
@@ -304,4 +1728,176 @@ func (b *TestDependenciesBuilder) IsBuilt() bool {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
 	return b.built
-}
\ No newline at end of file
+}
+
+// ResetClickHouse trunca todas as tabelas do ClickHouse
+func (b *TestDependenciesBuilder) ResetClickHouse(ctx context.Context) error {
+	if b.ClickHouseClearFunc != nil {
+		return b.ClickHouseClearFunc(ctx)
+	}
+	return fmt.Errorf("clickhouse connection not initialized")
+}
+
+// ResetDynamoDB remove todas as tabelas do DynamoDB
+func (b *TestDependenciesBuilder) ResetDynamoDB(ctx context.Context) error {
+	if b.DynamoDBClearFunc != nil {
+		return b.DynamoDBClearFunc(ctx)
+	}
+	return fmt.Errorf("dynamodb connection not initialized")
+}
+
+// ResetMemcached remove todos os itens do memcached
+func (b *TestDependenciesBuilder) ResetMemcached(ctx context.Context) error {
+	if b.MemcachedClearFunc != nil {
+		return b.MemcachedClearFunc(ctx)
+	}
+	return fmt.Errorf("memcached connection not initialized")
+}
+
+// ResetVault remove todos os segredos do mount KV
+func (b *TestDependenciesBuilder) ResetVault(ctx context.Context) error {
+	if b.VaultClearFunc != nil {
+		return b.VaultClearFunc(ctx)
+	}
+	return fmt.Errorf("vault connection not initialized")
+}
+
+// ResetWireMock remove os stubs registrados e restaura os mappings do diretório
+func (b *TestDependenciesBuilder) ResetWireMock(ctx context.Context) error {
+	if b.WireMockClearFunc != nil {
+		return b.WireMockClearFunc(ctx)
+	}
+	return fmt.Errorf("wiremock connection not initialized")
+}
+
+// ClearMailHog esvazia a caixa de entrada do MailHog
+func (b *TestDependenciesBuilder) ClearMailHog(ctx context.Context) error {
+	if b.MailHogClearFunc != nil {
+		return b.MailHogClearFunc(ctx)
+	}
+	return fmt.Errorf("mailhog connection not initialized")
+}
+
+// ClearAzurite remove todos os blob containers do Azurite
+func (b *TestDependenciesBuilder) ClearAzurite(ctx context.Context) error {
+	if b.AzuriteClearFunc != nil {
+		return b.AzuriteClearFunc(ctx)
+	}
+	return fmt.Errorf("azurite connection not initialized")
+}
+
+// ClearGCS remove todos os buckets do fake GCS
+func (b *TestDependenciesBuilder) ClearGCS(ctx context.Context) error {
+	if b.GCSClearFunc != nil {
+		return b.GCSClearFunc(ctx)
+	}
+	return fmt.Errorf("gcs connection not initialized")
+}
+
+// PubSubEnv retorna as variáveis de ambiente que apontam os clientes do Pub/Sub para o emulador
+// (PUBSUB_EMULATOR_HOST e PUBSUB_PROJECT_ID)
+func (b *TestDependenciesBuilder) PubSubEnv() map[string]string {
+	if b.PubSubEmulatorHost == "" {
+		return nil
+	}
+	return map[string]string{
+		"PUBSUB_EMULATOR_HOST": b.PubSubEmulatorHost,
+		"PUBSUB_PROJECT_ID":    b.PubSubProjectID,
+	}
+}
+
+// ClearPubSub remove todos os tópicos e subscriptions do emulador
+func (b *TestDependenciesBuilder) ClearPubSub(ctx context.Context) error {
+	if b.PubSubClearFunc != nil {
+		return b.PubSubClearFunc(ctx)
+	}
+	return fmt.Errorf("pubsub connection not initialized")
+}
+
+// PurgeElasticMQ remove as mensagens de todas as filas do ElasticMQ
+func (b *TestDependenciesBuilder) PurgeElasticMQ(ctx context.Context) error {
+	if b.ElasticMQPurgeFunc != nil {
+		return b.ElasticMQPurgeFunc(ctx)
+	}
+	return fmt.Errorf("elasticmq connection not initialized")
+}
+
+// ResetConsul remove todas as chaves do KV e todos os serviços registrados no Consul
+func (b *TestDependenciesBuilder) ResetConsul(ctx context.Context) error {
+	if b.ConsulClearFunc != nil {
+		return b.ConsulClearFunc(ctx)
+	}
+	return fmt.Errorf("consul connection not initialized")
+}
+
+// ClearInflux remove todos os pontos do bucket de teste do InfluxDB
+func (b *TestDependenciesBuilder) ClearInflux(ctx context.Context) error {
+	if b.InfluxClearFunc != nil {
+		return b.InfluxClearFunc(ctx)
+	}
+	return fmt.Errorf("influxdb connection not initialized")
+}
+
+// ResetSQLServer limpa todas as tabelas do SQL Server
+func (b *TestDependenciesBuilder) ResetSQLServer(ctx context.Context) error {
+	if b.SQLServerClearFunc != nil {
+		return b.SQLServerClearFunc(ctx)
+	}
+	return fmt.Errorf("sql server connection not initialized")
+}
+
+// ClearCouchbase remove os documentos de todos os buckets criados pelos helpers
+func (b *TestDependenciesBuilder) ClearCouchbase(ctx context.Context) error {
+	if b.CouchbaseClearFunc != nil {
+		return b.CouchbaseClearFunc(ctx)
+	}
+	return fmt.Errorf("couchbase connection not initialized")
+}
+
+// ClearSolr remove todos os documentos do core configurado
+func (b *TestDependenciesBuilder) ClearSolr(ctx context.Context) error {
+	if b.SolrClearFunc != nil {
+		return b.SolrClearFunc(ctx)
+	}
+	return fmt.Errorf("solr connection not initialized")
+}
+
+// ClearMeilisearch remove todos os índices criados pelos helpers
+func (b *TestDependenciesBuilder) ClearMeilisearch(ctx context.Context) error {
+	if b.MeilisearchClearFunc != nil {
+		return b.MeilisearchClearFunc(ctx)
+	}
+	return fmt.Errorf("meilisearch connection not initialized")
+}
+
+// ClearTypesense remove todas as collections criadas pelos helpers
+func (b *TestDependenciesBuilder) ClearTypesense(ctx context.Context) error {
+	if b.TypesenseClearFunc != nil {
+		return b.TypesenseClearFunc(ctx)
+	}
+	return fmt.Errorf("typesense connection not initialized")
+}
+
+// ClearKibana remove os saved objects (dashboards, visualizações, index patterns...)
+func (b *TestDependenciesBuilder) ClearKibana(ctx context.Context) error {
+	if b.KibanaClearFunc != nil {
+		return b.KibanaClearFunc(ctx)
+	}
+	return fmt.Errorf("kibana connection not initialized")
+}
+
+// ClearAPM remove os data streams e índices de eventos APM
+func (b *TestDependenciesBuilder) ClearAPM(ctx context.Context) error {
+	if b.APMClearFunc != nil {
+		return b.APMClearFunc(ctx)
+	}
+	return fmt.Errorf("apm server not initialized")
+}
+
+// ClearPrometheus remove todas as séries gravadas pelo Prometheus
+func (b *TestDependenciesBuilder) ClearPrometheus(ctx context.Context) error {
+	if b.PrometheusClearFunc != nil {
+		return b.PrometheusClearFunc(ctx)
+	}
+	return fmt.Errorf("prometheus not initialized")
+}
@@ -0,0 +1,347 @@
+package testhelper
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"github.com/stretchr/testify/require"
+)
+
+// TransformStats resume o estado de um transform retornado pela API _stats
+type TransformStats struct {
+	ID                 string
+	State              string
+	LastCheckpoint     int
+	DocumentsProcessed int
+	DocumentsIndexed   int
+	Reason             string
+}
+
+// CreatePivotTransform cria um transform do tipo pivot de source para dest
+// Com continuous=true o transform usa sync por timeField e roda a cada segundo
+func (s *IntegrationTestSuite) CreatePivotTransform(id, source, dest string, groupBy, aggregations map[string]interface{}, continuous bool, timeField string) {
+	s.t.Helper()
+
+	body := map[string]interface{}{
+		"source": map[string]interface{}{"index": []string{source}},
+		"dest":   map[string]interface{}{"index": dest},
+		"pivot": map[string]interface{}{
+			"group_by":     groupBy,
+			"aggregations": aggregations,
+		},
+	}
+	if continuous {
+		body["frequency"] = "1s"
+		body["sync"] = transformSync(timeField)
+	}
+
+	s.PutTransform(id, body)
+}
+
+// CreateLatestTransform cria um transform do tipo latest que mantém o documento mais recente por uniqueKey
+func (s *IntegrationTestSuite) CreateLatestTransform(id, source, dest string, uniqueKey []string, sortField string, continuous bool) {
+	s.t.Helper()
+
+	body := map[string]interface{}{
+		"source": map[string]interface{}{"index": []string{source}},
+		"dest":   map[string]interface{}{"index": dest},
+		"latest": map[string]interface{}{
+			"unique_key": uniqueKey,
+			"sort":       sortField,
+		},
+	}
+	if continuous {
+		body["frequency"] = "1s"
+		body["sync"] = transformSync(sortField)
+	}
+
+	s.PutTransform(id, body)
+}
+
+// PutTransform cria um transform com a definição informada
+// Os índices de source e dest recebem o run scope (RunScopedIndex) como nos demais helpers
+// O transform é parado e removido (junto com o índice de destino) ao final do teste
+func (s *IntegrationTestSuite) PutTransform(id string, body map[string]interface{}) {
+	s.t.Helper()
+
+	body = s.runScopedTransformBody(body)
+	bodyJSON, err := json.Marshal(body)
+	require.NoError(s.t, err, "Failed to marshal transform %s", id)
+
+	req := esapi.TransformPutTransformRequest{
+		TransformID: id,
+		Body:        strings.NewReader(string(bodyJSON)),
+	}
+
+	res, err := req.Do(s.ctx, s.ES())
+	require.NoError(s.t, err, "Failed to create transform %s", id)
+	defer res.Body.Close()
+
+	if res.IsError() {
+		raw, _ := io.ReadAll(res.Body)
+		require.Fail(s.t, fmt.Sprintf("Failed to create transform %s: %s %s", id, res.Status(), raw))
+	}
+
+	s.onCleanup(func() {
+		s.deleteTransform(id)
+	})
+}
+
+// StartTransform inicia o transform
+func (s *IntegrationTestSuite) StartTransform(id string) {
+	s.t.Helper()
+
+	req := esapi.TransformStartTransformRequest{TransformID: id}
+
+	res, err := req.Do(s.ctx, s.ES())
+	require.NoError(s.t, err, "Failed to start transform %s", id)
+	defer res.Body.Close()
+
+	if res.IsError() {
+		raw, _ := io.ReadAll(res.Body)
+		require.Fail(s.t, fmt.Sprintf("Failed to start transform %s: %s %s", id, res.Status(), raw))
+	}
+}
+
+// StopTransform para o transform aguardando o checkpoint em andamento terminar
+func (s *IntegrationTestSuite) StopTransform(id string) {
+	s.t.Helper()
+
+	req := esapi.TransformStopTransformRequest{
+		TransformID:       id,
+		WaitForCheckpoint: esapi.BoolPtr(true),
+		WaitForCompletion: esapi.BoolPtr(true),
+		Timeout:           30 * time.Second,
+	}
+
+	res, err := req.Do(s.ctx, s.ES())
+	require.NoError(s.t, err, "Failed to stop transform %s", id)
+	defer res.Body.Close()
+
+	if res.IsError() {
+		require.Fail(s.t, fmt.Sprintf("Failed to stop transform %s: %s", id, res.Status()))
+	}
+}
+
+// TriggerTransform força um novo checkpoint imediatamente (API _schedule_now, ES 8.7+)
+// Em versões anteriores, transforms contínuos criados pelos helpers já rodam a cada segundo
+func (s *IntegrationTestSuite) TriggerTransform(id string) {
+	s.t.Helper()
+
+	req := esapi.TransformScheduleNowTransformRequest{TransformID: id}
+
+	res, err := req.Do(s.ctx, s.ES())
+	require.NoError(s.t, err, "Failed to schedule transform %s", id)
+	defer res.Body.Close()
+
+	if res.IsError() {
+		require.Fail(s.t, fmt.Sprintf("Failed to schedule transform %s: %s", id, res.Status()))
+	}
+}
+
+// GetTransformStats retorna o estado atual do transform
+func (s *IntegrationTestSuite) GetTransformStats(id string) TransformStats {
+	s.t.Helper()
+
+	req := esapi.TransformGetTransformStatsRequest{TransformID: id}
+
+	res, err := req.Do(s.ctx, s.ES())
+	require.NoError(s.t, err, "Failed to get transform %s stats", id)
+	defer res.Body.Close()
+
+	if res.IsError() {
+		require.Fail(s.t, fmt.Sprintf("Failed to get transform %s stats: %s", id, res.Status()))
+	}
+
+	var response struct {
+		Transforms []struct {
+			ID     string `json:"id"`
+			State  string `json:"state"`
+			Reason string `json:"reason"`
+			Stats  struct {
+				DocumentsProcessed int `json:"documents_processed"`
+				DocumentsIndexed   int `json:"documents_indexed"`
+			} `json:"stats"`
+			Checkpointing struct {
+				Last struct {
+					Checkpoint int `json:"checkpoint"`
+				} `json:"last"`
+			} `json:"checkpointing"`
+		} `json:"transforms"`
+	}
+	err = json.NewDecoder(res.Body).Decode(&response)
+	require.NoError(s.t, err, "Failed to decode transform stats")
+	require.Len(s.t, response.Transforms, 1, "Transform %s not found", id)
+
+	tr := response.Transforms[0]
+	return TransformStats{
+		ID:                 tr.ID,
+		State:              tr.State,
+		LastCheckpoint:     tr.Checkpointing.Last.Checkpoint,
+		DocumentsProcessed: tr.Stats.DocumentsProcessed,
+		DocumentsIndexed:   tr.Stats.DocumentsIndexed,
+		Reason:             tr.Reason,
+	}
+}
+
+// WaitForTransformCheckpoint aguarda o transform concluir o checkpoint informado (ou posterior)
+// Falha imediatamente se o transform entrar em estado "failed"
+func (s *IntegrationTestSuite) WaitForTransformCheckpoint(id string, checkpoint int, timeout time.Duration) TransformStats {
+	s.t.Helper()
+
+	deadline := time.Now().Add(timeout)
+	for {
+		stats := s.GetTransformStats(id)
+		require.NotEqual(s.t, "failed", stats.State, "Transform %s failed: %s", id, stats.Reason)
+
+		if stats.LastCheckpoint >= checkpoint {
+			// Garante que o destino está visível para buscas
+			s.refreshIndex(s.transformDestination(id))
+			return stats
+		}
+
+		if time.Now().After(deadline) {
+			require.Fail(s.t, fmt.Sprintf("Timed out waiting for transform %s checkpoint %d (last %d, state %s)",
+				id, checkpoint, stats.LastCheckpoint, stats.State))
+			return stats
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+}
+
+// AssertTransformDestination verifica o conteúdo do índice de destino do transform
+// O callback recebe os documentos (até 1000) para asserções específicas
+func (s *IntegrationTestSuite) AssertTransformDestination(dest string, expectedDocs int, check func(docs []map[string]interface{})) {
+	s.t.Helper()
+
+	s.refreshIndex(s.RunScopedIndex(dest))
+
+	result := s.SearchDocuments(dest, map[string]interface{}{
+		"size":  1000,
+		"query": map[string]interface{}{"match_all": map[string]interface{}{}},
+	})
+	require.Equal(s.t, expectedDocs, result.TotalHits(), "Unexpected number of documents in transform destination %s", dest)
+
+	if check != nil {
+		check(result.Documents())
+	}
+}
+
+// runScopedTransformBody retorna uma cópia do corpo com source.index e dest.index no run scope
+func (s *IntegrationTestSuite) runScopedTransformBody(body map[string]interface{}) map[string]interface{} {
+	scoped := make(map[string]interface{}, len(body))
+	for key, value := range body {
+		scoped[key] = value
+	}
+
+	if source, ok := body["source"].(map[string]interface{}); ok {
+		copied := make(map[string]interface{}, len(source))
+		for key, value := range source {
+			copied[key] = value
+		}
+		switch indices := source["index"].(type) {
+		case string:
+			copied["index"] = s.RunScopedIndex(indices)
+		case []string:
+			names := make([]string, len(indices))
+			for i, name := range indices {
+				names[i] = s.RunScopedIndex(name)
+			}
+			copied["index"] = names
+		case []interface{}:
+			names := make([]interface{}, len(indices))
+			for i, name := range indices {
+				if text, ok := name.(string); ok {
+					name = s.RunScopedIndex(text)
+				}
+				names[i] = name
+			}
+			copied["index"] = names
+		}
+		scoped["source"] = copied
+	}
+
+	if dest, ok := body["dest"].(map[string]interface{}); ok {
+		copied := make(map[string]interface{}, len(dest))
+		for key, value := range dest {
+			copied[key] = value
+		}
+		if index, ok := dest["index"].(string); ok {
+			copied["index"] = s.RunScopedIndex(index)
+		}
+		scoped["dest"] = copied
+	}
+
+	return scoped
+}
+
+// transformDestination retorna o índice de destino configurado no transform
+func (s *IntegrationTestSuite) transformDestination(id string) string {
+	s.t.Helper()
+
+	req := esapi.TransformGetTransformRequest{TransformID: id}
+
+	res, err := req.Do(s.ctx, s.ES())
+	require.NoError(s.t, err, "Failed to get transform %s", id)
+	defer res.Body.Close()
+
+	var response struct {
+		Transforms []struct {
+			Dest struct {
+				Index string `json:"index"`
+			} `json:"dest"`
+		} `json:"transforms"`
+	}
+	err = json.NewDecoder(res.Body).Decode(&response)
+	require.NoError(s.t, err, "Failed to decode transform %s", id)
+	require.Len(s.t, response.Transforms, 1, "Transform %s not found", id)
+
+	return response.Transforms[0].Dest.Index
+}
+
+// deleteTransform remove o transform e o índice de destino (best-effort, usado no cleanup)
+func (s *IntegrationTestSuite) deleteTransform(id string) {
+	req := esapi.TransformDeleteTransformRequest{
+		TransformID:     id,
+		Force:           esapi.BoolPtr(true),
+		DeleteDestIndex: esapi.BoolPtr(true),
+	}
+
+	res, err := req.Do(s.ctx, s.ES())
+	if err != nil {
+		if isDebugEnabled() {
+			fmt.Printf("⚠️  Failed to delete transform %s: %v\n", id, err)
+		}
+		return
+	}
+	res.Body.Close()
+}
+
+// refreshIndex força o refresh de um índice específico
+func (s *IntegrationTestSuite) refreshIndex(indexName string) {
+	s.t.Helper()
+
+	req := esapi.IndicesRefreshRequest{Index: []string{indexName}}
+
+	res, err := req.Do(s.ctx, s.ES())
+	require.NoError(s.t, err, "Failed to refresh index %s", indexName)
+	defer res.Body.Close()
+
+	if res.IsError() {
+		require.Fail(s.t, fmt.Sprintf("Failed to refresh index %s: %s", indexName, res.Status()))
+	}
+}
+
+// transformSync monta a configuração de sincronização de transforms contínuos
+func transformSync(timeField string) map[string]interface{} {
+	return map[string]interface{}{
+		"time": map[string]interface{}{
+			"field": timeField,
+			"delay": "1s",
+		},
+	}
+}
@@ -0,0 +1,88 @@
+package testhelper
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+)
+
+// Helpers tipados: o _source dos hits é decodificado direto em T, sem o ciclo
+// map[string]interface{} -> json.Marshal -> json.Unmarshal de SearchResult.UnmarshalDocuments
+// São funções (e não métodos) porque Go não permite parâmetros de tipo em métodos
+
+// SearchAs executa a busca no índice e decodifica o _source de cada hit em T, na ordem dos hits
+// Diferente de SearchDocuments, erros são retornados em vez de falhar o teste
+func SearchAs[T any](s *IntegrationTestSuite, index string, query map[string]interface{}) ([]T, error) {
+	s.t.Helper()
+	defer s.trackOperation("SearchAs")()
+
+	client := s.ES()
+	if client == nil {
+		return nil, fmt.Errorf("elasticsearch not configured")
+	}
+	index = s.RunScopedIndex(index)
+
+	queryJSON, err := json.Marshal(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal query: %w", err)
+	}
+
+	res, err := esapi.SearchRequest{
+		Index: []string{index},
+		Body:  strings.NewReader(string(queryJSON)),
+	}.Do(s.ctx, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute search: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, fmt.Errorf("failed to search %s: %s", index, res.String())
+	}
+
+	var response struct {
+		Hits struct {
+			Hits []struct {
+				ID     string          `json:"_id"`
+				Source json.RawMessage `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode search response: %w", err)
+	}
+
+	documents := make([]T, 0, len(response.Hits.Hits))
+	for _, hit := range response.Hits.Hits {
+		var document T
+		if err := json.Unmarshal(hit.Source, &document); err != nil {
+			return nil, fmt.Errorf("failed to decode hit %s into %T: %w", hit.ID, document, err)
+		}
+		documents = append(documents, document)
+	}
+	return documents, nil
+}
+
+// GetDocumentAs busca o documento pelo id e decodifica o _source em T
+// Retorna (nil, false) se o documento não existe; outros erros falham o teste como em GetDocument
+func GetDocumentAs[T any](s *IntegrationTestSuite, index, docID string) (*T, bool) {
+	s.t.Helper()
+
+	var document T
+	if !s.GetDocument(index, docID, &document) {
+		return nil, false
+	}
+	return &document, true
+}
+
+// HitsAs decodifica os documentos de um SearchResult já obtido (SearchDocuments, EventuallySearchHits...) em T
+func HitsAs[T any](result *SearchResult) ([]T, error) {
+	documents := make([]T, 0, len(result.Documents()))
+	if err := result.UnmarshalDocuments(&documents); err != nil {
+		var zero T
+		return nil, fmt.Errorf("failed to decode documents into %T: %w", zero, err)
+	}
+	return documents, nil
+}
@@ -0,0 +1,217 @@
+package testhelper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// defaultVerifyStateTimeout é o tempo máximo de espera do VerifyState quando o spec não define Timeout
+const defaultVerifyStateTimeout = 10 * time.Second
+
+// StateSpec declara o estado esperado das dependências ao final de um fluxo
+type StateSpec struct {
+	Elasticsearch []ESCountExpectation
+	Postgres      []PGCountExpectation
+	Mongo         []MongoCountExpectation
+	Timeout       time.Duration // espera máxima pela consistência eventual (padrão 10s)
+}
+
+// ESCountExpectation espera Count documentos no índice casando com Query (nil = todos)
+type ESCountExpectation struct {
+	Index string
+	Query map[string]interface{} // cláusula "query" do _count
+	Count int
+}
+
+// PGCountExpectation espera Count linhas na tabela casando com Where (vazio = todas)
+type PGCountExpectation struct {
+	Table string
+	Where string // condição com placeholders $1, $2...
+	Args  []interface{}
+	Count int
+}
+
+// MongoCountExpectation espera Count documentos na coleção casando com Filter (nil = todos)
+type MongoCountExpectation struct {
+	Collection string
+	Filter     interface{}
+	Count      int64
+}
+
+// stateCheck é o resultado de uma verificação do spec em uma rodada
+type stateCheck struct {
+	store    string
+	target   string
+	filter   string
+	expected int64
+	actual   int64
+	err      error
+}
+
+// ok indica se a verificação passou
+func (c stateCheck) ok() bool {
+	return c.err == nil && c.actual == c.expected
+}
+
+// VerifyState aguarda todas as contagens do spec baterem, refazendo todas as consultas a cada rodada
+// Ao estourar o Timeout falha uma única vez com o diff consolidado (esperado x atual de cada verificação),
+// em vez de parar na primeira asserção divergente
+func (s *IntegrationTestSuite) VerifyState(spec StateSpec) {
+	s.t.Helper()
+	defer s.trackOperation("VerifyState")()
+
+	if len(spec.Elasticsearch) > 0 {
+		require.NotNil(s.t, s.ES(), "Elasticsearch not configured, use WithElasticsearch()")
+	}
+	if len(spec.Postgres) > 0 {
+		require.NotNil(s.t, s.Postgres(), "PostgreSQL not configured, use WithPostgres()")
+	}
+	if len(spec.Mongo) > 0 {
+		require.NotNil(s.t, s.Mongo(), "MongoDB not configured, use WithMongo()")
+	}
+
+	timeout := spec.Timeout
+	if timeout <= 0 {
+		timeout = defaultVerifyStateTimeout
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		checks := s.evaluateState(spec)
+
+		failed := 0
+		for _, check := range checks {
+			if !check.ok() {
+				failed++
+			}
+		}
+		if failed == 0 {
+			return
+		}
+
+		if time.Now().After(deadline) {
+			require.Fail(s.t, fmt.Sprintf("State did not converge within %s: %d of %d checks failed", timeout, failed, len(checks)),
+				formatStateDiff(checks))
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// evaluateState executa todas as verificações do spec uma vez
+func (s *IntegrationTestSuite) evaluateState(spec StateSpec) []stateCheck {
+	checks := make([]stateCheck, 0, len(spec.Elasticsearch)+len(spec.Postgres)+len(spec.Mongo))
+
+	for _, exp := range spec.Elasticsearch {
+		index := s.RunScopedIndex(exp.Index)
+		check := stateCheck{store: "elasticsearch", target: index, filter: formatStateFilter(exp.Query), expected: int64(exp.Count)}
+		check.actual, check.err = s.countESDocuments(index, exp.Query)
+		checks = append(checks, check)
+	}
+
+	for _, exp := range spec.Postgres {
+		check := stateCheck{store: "postgres", target: exp.Table, filter: exp.Where, expected: int64(exp.Count)}
+		query := "SELECT count(*) FROM " + quoteQualifiedName(exp.Table)
+		if strings.TrimSpace(exp.Where) != "" {
+			query += " WHERE " + exp.Where
+		}
+		check.err = s.Postgres().QueryRowContext(s.ctx, query, exp.Args...).Scan(&check.actual)
+		checks = append(checks, check)
+	}
+
+	for _, exp := range spec.Mongo {
+		filter := exp.Filter
+		if filter == nil {
+			filter = bson.M{}
+		}
+		check := stateCheck{store: "mongo", target: exp.Collection, filter: formatStateFilter(exp.Filter), expected: exp.Count}
+		check.actual, check.err = s.Mongo().Collection(exp.Collection).CountDocuments(s.ctx, filter)
+		checks = append(checks, check)
+	}
+
+	return checks
+}
+
+// countESDocuments faz refresh (tolerante a índice inexistente) e conta os documentos da query
+func (s *IntegrationTestSuite) countESDocuments(index string, query map[string]interface{}) (int64, error) {
+	client := s.ES()
+
+	if res, err := client.Indices.Refresh(
+		client.Indices.Refresh.WithContext(s.ctx),
+		client.Indices.Refresh.WithIndex(index),
+		client.Indices.Refresh.WithIgnoreUnavailable(true),
+	); err == nil {
+		res.Body.Close()
+	}
+
+	// Índice inexistente conta como zero: o fluxo pode ainda não tê-lo criado
+	opts := []func(*esapi.CountRequest){
+		client.Count.WithContext(s.ctx),
+		client.Count.WithIndex(index),
+		client.Count.WithIgnoreUnavailable(true),
+	}
+	if query != nil {
+		body, err := json.Marshal(map[string]interface{}{"query": query})
+		if err != nil {
+			return 0, err
+		}
+		opts = append(opts, client.Count.WithBody(bytes.NewReader(body)))
+	}
+
+	res, err := client.Count(opts...)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return 0, fmt.Errorf("count failed: %s", res.Status())
+	}
+
+	var body struct {
+		Count int64 `json:"count"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return 0, fmt.Errorf("failed to decode count response: %w", err)
+	}
+	return body.Count, nil
+}
+
+// formatStateFilter resume a query/filtro em JSON compacto para o diff
+func formatStateFilter(filter interface{}) string {
+	if filter == nil {
+		return ""
+	}
+	raw, err := json.Marshal(filter)
+	if err != nil {
+		return fmt.Sprint(filter)
+	}
+	return string(raw)
+}
+
+// formatStateDiff monta a tabela com todas as verificações, marcando as divergentes
+func formatStateDiff(checks []stateCheck) string {
+	var out strings.Builder
+	w := tabwriter.NewWriter(&out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\tstore\ttarget\tfilter\texpected\tactual")
+	for _, check := range checks {
+		mark, actual := "✓", fmt.Sprint(check.actual)
+		if !check.ok() {
+			mark = "✗"
+		}
+		if check.err != nil {
+			actual = "error: " + check.err.Error()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\n", mark, check.store, check.target, check.filter, check.expected, actual)
+	}
+	w.Flush()
+	return out.String()
+}
@@ -0,0 +1,202 @@
+package testhelper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/elastic/go-elasticsearch/v8/esutil"
+	"github.com/stretchr/testify/require"
+)
+
+// Políticas de refresh aceitas por WithRefresh
+const (
+	RefreshWaitFor = "wait_for"
+	RefreshTrue    = "true"
+	RefreshFalse   = "false"
+)
+
+// WriteOption ajusta a requisição de escrita dos helpers IndexDocument, DeleteDocument e BulkIndex
+// Sem opções, a escrita usa refresh=wait_for (o documento fica visível para busca no retorno)
+type WriteOption func(*writeOptions)
+
+// writeOptions são os parâmetros de escrita aplicados às requisições
+type writeOptions struct {
+	refresh     string
+	routing     string
+	pipeline    string
+	opType      string
+	version     *int
+	versionType string
+	workers     int
+	flushBytes  int
+}
+
+// WithRefresh define a política de refresh (RefreshWaitFor, RefreshTrue, RefreshFalse)
+// Use RefreshFalse para reproduzir a escrita da aplicação, que não força refresh
+func WithRefresh(policy string) WriteOption {
+	return func(o *writeOptions) {
+		o.refresh = policy
+	}
+}
+
+// WithRouting define o routing do documento
+func WithRouting(routing string) WriteOption {
+	return func(o *writeOptions) {
+		o.routing = routing
+	}
+}
+
+// WithPipeline define o ingest pipeline aplicado na indexação (ignorado em DeleteDocument)
+func WithPipeline(pipeline string) WriteOption {
+	return func(o *writeOptions) {
+		o.pipeline = pipeline
+	}
+}
+
+// WithOpType define o op_type da indexação: "index" (padrão) ou "create" (falha se o documento existir)
+// Ignorado em DeleteDocument
+func WithOpType(opType string) WriteOption {
+	return func(o *writeOptions) {
+		o.opType = opType
+	}
+}
+
+// WithVersion define a versão atribuída ao documento e o version_type ("external" ou "external_gte"; o
+// Elasticsearch 7+ recusa "internal" com versão explícita)
+func WithVersion(version int, versionType string) WriteOption {
+	return func(o *writeOptions) {
+		o.version = &version
+		o.versionType = versionType
+	}
+}
+
+// WithBulkWorkers define o número de workers do BulkIndex (padrão runtime.NumCPU())
+func WithBulkWorkers(workers int) WriteOption {
+	return func(o *writeOptions) {
+		o.workers = workers
+	}
+}
+
+// WithFlushBytes define o tamanho de cada requisição _bulk do BulkIndex (padrão 5MB)
+func WithFlushBytes(flushBytes int) WriteOption {
+	return func(o *writeOptions) {
+		o.flushBytes = flushBytes
+	}
+}
+
+// newWriteOptions aplica as opções sobre o padrão refresh=wait_for
+func newWriteOptions(opts []WriteOption) writeOptions {
+	options := writeOptions{refresh: RefreshWaitFor}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// BulkDocument é um documento de BulkIndex com ID explícito
+type BulkDocument struct {
+	ID     string
+	Source interface{}
+}
+
+// BulkIndex indexa os documentos com o esutil.BulkIndexer (requisições _bulk paralelas) e faz um único
+// refresh ao final, conforme a política de refresh (padrão wait_for: documentos visíveis no retorno)
+// Elementos BulkDocument usam o ID informado; os demais recebem ID gerado pelo Elasticsearch.
+// As opções de routing, pipeline, op_type e versão valem para todos os documentos; WithBulkWorkers e
+// WithFlushBytes ajustam o paralelismo. Falhas são agregadas e reportadas de uma vez ao final
+func (s *IntegrationTestSuite) BulkIndex(indexName string, docs []interface{}, opts ...WriteOption) {
+	s.t.Helper()
+	defer s.trackOperation("BulkIndex")()
+	indexName = s.RunScopedIndex(indexName)
+
+	if len(docs) == 0 {
+		return
+	}
+
+	options := newWriteOptions(opts)
+	action := "index"
+	if options.opType == "create" {
+		action = "create"
+	}
+
+	var (
+		mu      sync.Mutex
+		samples []string
+	)
+	fail := func(msg string) {
+		mu.Lock()
+		defer mu.Unlock()
+		samples = appendErrorSample(samples, msg)
+	}
+
+	indexer, err := esutil.NewBulkIndexer(esutil.BulkIndexerConfig{
+		Client:     s.ES(),
+		Index:      indexName,
+		Pipeline:   options.pipeline,
+		NumWorkers: options.workers,
+		FlushBytes: options.flushBytes,
+		// Erros de requisições inteiras (rede, 429 persistente) não passam pelo OnFailure dos itens
+		OnError: func(ctx context.Context, err error) {
+			fail(err.Error())
+		},
+	})
+	require.NoError(s.t, err, "Failed to create bulk indexer")
+
+	// Um require dentro do loop sai sem o Close abaixo: fecha aqui para não vazar os workers
+	closed := false
+	defer func() {
+		if !closed {
+			indexer.Close(context.Background())
+		}
+	}()
+
+	for i, doc := range docs {
+		item := esutil.BulkIndexerItem{
+			Action:      action,
+			Routing:     options.routing,
+			VersionType: options.versionType,
+			OnFailure: func(ctx context.Context, item esutil.BulkIndexerItem, res esutil.BulkIndexerResponseItem, err error) {
+				if err != nil {
+					fail(fmt.Sprintf("document %q: %v", item.DocumentID, err))
+					return
+				}
+				fail(fmt.Sprintf("document %q (%d): %s: %s", res.DocumentID, res.Status, res.Error.Type, res.Error.Reason))
+			},
+		}
+		if options.version != nil {
+			version := int64(*options.version)
+			item.Version = &version
+		}
+
+		source := doc
+		if bulkDoc, ok := doc.(BulkDocument); ok {
+			item.DocumentID = bulkDoc.ID
+			source = bulkDoc.Source
+		}
+		sourceJSON, err := json.Marshal(source)
+		require.NoError(s.t, err, "Failed to marshal document %d", i)
+		item.Body = bytes.NewReader(sourceJSON)
+
+		err = indexer.Add(s.ctx, item)
+		require.NoError(s.t, err, "Failed to add document %d to bulk indexer", i)
+	}
+
+	closed = true
+	err = indexer.Close(s.ctx)
+	require.NoError(s.t, err, "Failed to bulk index documents")
+
+	stats := indexer.Stats()
+	if stats.NumFailed > 0 {
+		require.Fail(s.t, fmt.Sprintf("Failed to bulk index %d of %d documents into %s", stats.NumFailed, len(docs), indexName),
+			"First errors:\n%s", strings.Join(samples, "\n"))
+		return
+	}
+
+	if options.refresh == RefreshWaitFor || options.refresh == RefreshTrue {
+		s.refreshIndex(indexName)
+	}
+}